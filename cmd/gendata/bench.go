@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/internal/bench"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/logger"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// benchConfig holds settings for an aggregation benchmark
+type benchConfig struct {
+	connectionString string
+	databaseName     string
+	collectionName   string
+	runID            string // Run whose schema the queries use ("" = latest)
+	queries          string // Comma-separated query names ("" = all that apply)
+	iterations       int
+	timeout          time.Duration
+	readPreference   *readpref.ReadPref
+	verbose          bool
+}
+
+// runAggBenchmark runs the aggregation suite against a collection from an
+// earlier load and prints the latencies of each query
+func runAggBenchmark(ctx context.Context, config benchConfig, ycsbLogger *logger.YCSBLogger, result *runSummary) error {
+	client, err := mongo.Connect(config.connectionString, 1)
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(context.Background())
+
+	db := client.Database(config.databaseName)
+	meta, err := mongo.LoadRunMetadata(ctx, db, config.collectionName, config.runID)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	if config.queries != "" {
+		for _, name := range strings.Split(config.queries, ",") {
+			names = append(names, strings.TrimSpace(name))
+		}
+	}
+	queries, err := bench.Queries(meta.Schema, meta.OrdersCollection, names)
+	if err != nil {
+		return err
+	}
+	if len(queries) == 0 {
+		return fmt.Errorf("no queries run on %s documents", meta.Schema.Template)
+	}
+
+	if config.verbose {
+		log.Printf("Using run %s: %s template, %d documents", meta.RunID, meta.Schema.Template, meta.DocumentsWritten)
+		for _, q := range queries {
+			log.Printf("Query %s: %s", q.Name, q.Description)
+		}
+	}
+
+	results, err := bench.Run(ctx, bench.Config{
+		Collection: mongo.WorkloadCollection(db, config.collectionName, config.readPreference, false),
+		Schema:     meta.Schema,
+		LookupFrom: meta.OrdersCollection,
+		Queries:    queries,
+		Iterations: config.iterations,
+		Timeout:    config.timeout,
+		YCSBLogger: ycsbLogger,
+		Progress: func(query string, iteration int) {
+			fmt.Fprintf(console, "\r[Running: %-22s %d/%d]", query, iteration, config.iterations)
+		},
+	})
+	fmt.Fprintln(console)
+	bench.Print(console, results)
+	result.setBenchmark(meta.RunID, results)
+	return err
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/internal/artifacts"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/mongo"
+)
+
+// secretFlags are omitted from the recorded configuration
+var secretFlags = map[string]bool{
+	"connection":        true,
+	"atlas-private-key": true,
+}
+
+// flagValues returns every flag's effective value, with secrets redacted
+func flagValues() map[string]string {
+	values := make(map[string]string)
+	flag.VisitAll(func(f *flag.Flag) {
+		if secretFlags[f.Name] && f.Value.String() != "" {
+			values[f.Name] = "<redacted>"
+			return
+		}
+		values[f.Name] = f.Value.String()
+	})
+	return values
+}
+
+// bundleRun writes the run's configuration, metadata, and summary and copies
+// files (YCSB log, time series) into the run's artifact folder, packs the folder (including diagnostics and
+// any other reports already there) into <run-dir>.tar.gz, and optionally
+// uploads the bundle to S3
+func bundleRun(runDir string, meta *mongo.RunMetadata, summary []byte, files []string, s3URL string) {
+	if err := os.MkdirAll(runDir, 0o755); err != nil {
+		log.Printf("Warning: failed to create artifact folder: %v", err)
+		return
+	}
+
+	if err := artifacts.WriteJSON(filepath.Join(runDir, "config.json"), flagValues()); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	if err := artifacts.WriteJSON(filepath.Join(runDir, "run.json"), meta); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(runDir, "summary.txt"), summary, 0o644); err != nil {
+		log.Printf("Warning: failed to write summary: %v", err)
+	}
+	for _, file := range files {
+		if err := artifacts.CopyFile(file, filepath.Join(runDir, filepath.Base(file))); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+
+	bundlePath := filepath.Clean(runDir) + ".tar.gz"
+	if err := artifacts.Bundle(runDir, bundlePath); err != nil {
+		log.Printf("Warning: %v", err)
+		return
+	}
+	log.Printf("Run artifacts bundled in %s", bundlePath)
+
+	if s3URL == "" {
+		return
+	}
+	creds, err := artifacts.S3CredentialsFromEnv()
+	if err != nil {
+		log.Printf("Warning: %v", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+	location, err := artifacts.UploadS3(ctx, creds, s3URL, bundlePath)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+		return
+	}
+	log.Printf("Run artifacts uploaded to %s", location)
+}
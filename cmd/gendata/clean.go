@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/meticulous-dft/mongodb-data-generator/internal/mongo"
+)
+
+// cleanConfig holds settings for removing generated data
+type cleanConfig struct {
+	connectionString string
+	databaseName     string
+	collectionName   string
+	runID            string // Delete only this run's tagged documents ("" = drop the collection)
+	dropDatabase     bool
+	confirmed        bool // Without confirmation, only report what would be removed
+}
+
+// runClean removes generated data: a whole database, a collection with its
+// orders collections and run metadata, or the tagged documents of one run
+func runClean(ctx context.Context, config cleanConfig, result *runSummary) error {
+	client, err := mongo.Connect(config.connectionString, 1)
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(context.Background())
+	db := client.Database(config.databaseName)
+
+	summary := &cleanSummary{Executed: config.confirmed}
+	result.Clean = summary
+
+	switch {
+	case config.dropDatabase:
+		if !config.confirmed {
+			fmt.Fprintf(console, "Would drop database %s\n", config.databaseName)
+			break
+		}
+		if err := db.Drop(ctx); err != nil {
+			return fmt.Errorf("failed to drop database %s: %w", config.databaseName, err)
+		}
+		summary.DroppedDatabase = config.databaseName
+		fmt.Fprintf(console, "Dropped database %s\n", config.databaseName)
+
+	case config.runID != "":
+		meta, err := mongo.LoadRunMetadata(ctx, db, config.collectionName, config.runID)
+		if err != nil {
+			return err
+		}
+		result.RunID = meta.RunID
+		if !config.confirmed {
+			if !meta.Tagged {
+				return fmt.Errorf("run %s was loaded without the run_id tag (--tag-run); drop the whole collection instead", meta.RunID)
+			}
+			fmt.Fprintf(console, "Would delete the documents of run %s (%d written) from %s.%s",
+				meta.RunID, meta.DocumentsWritten, config.databaseName, meta.Collection)
+			if meta.OrdersCollection != "" {
+				fmt.Fprintf(console, " and its orders from %s", meta.OrdersCollection)
+			}
+			if meta.Namespaces != nil {
+				fmt.Fprintf(console, ", and from %s, dropping those left empty", collectionList(meta.Namespaces.Names()))
+			}
+			fmt.Fprintln(console)
+			break
+		}
+		cleaned, err := mongo.DeleteRun(ctx, db, meta)
+		if err != nil {
+			return err
+		}
+		summary.add(cleaned)
+		fmt.Fprintf(console, "Deleted %d documents and %d orders of run %s\n",
+			cleaned.DocumentsDeleted, cleaned.OrdersDeleted, meta.RunID)
+		if len(cleaned.DroppedCollections) > 0 {
+			fmt.Fprintf(console, "Dropped the emptied collections %s\n", collectionList(cleaned.DroppedCollections))
+		}
+
+	default:
+		if !config.confirmed {
+			runs, err := mongo.ListRuns(ctx, db, config.collectionName)
+			if err != nil {
+				return err
+			}
+			collections := append([]string{config.collectionName}, mongo.OrdersCollections(runs)...)
+			collections = append(collections, mongo.SpreadCollections(runs)...)
+			fmt.Fprintf(console, "Would drop %s.%s and remove %d run records\n",
+				config.databaseName, collectionList(collections), len(runs))
+			break
+		}
+		cleaned, err := mongo.DropCollection(ctx, db, config.collectionName)
+		if err != nil {
+			return err
+		}
+		summary.add(cleaned)
+		fmt.Fprintf(console, "Dropped %s.%s and removed %d run records\n",
+			config.databaseName, collectionList(cleaned.DroppedCollections), cleaned.RunsRemoved)
+	}
+
+	if !config.confirmed {
+		fmt.Fprintf(console, "Re-run with --yes to proceed\n")
+	}
+	return nil
+}
+
+// collectionList formats collection names as {a,b,c}, eliding the middle of
+// the thousands of collections --collection-count may have spread a load over
+func collectionList(names []string) string {
+	if len(names) <= 10 {
+		return "{" + strings.Join(names, ",") + "}"
+	}
+	return fmt.Sprintf("{%s,...,%s} (%d collections)",
+		strings.Join(names[:5], ","), strings.Join(names[len(names)-2:], ","), len(names))
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// command is a gendata subcommand
+type command struct {
+	name        string
+	description string
+	mode        string            // Run summary mode(s) the command runs in, comma-separated
+	implies     map[string]string // Flags set by choosing the command
+	flags       []string          // Accepted flags in addition to commonFlags
+}
+
+// commonFlags are accepted by every command
+var commonFlags = []string{
+	"config", "connection", "database", "collection",
+	"verbose", "quiet", "summary-json", "log-file", "timeseries-file", "hgrm-dir",
+}
+
+// commands lists the subcommands in the order they are shown in the usage
+var commands = []command{
+	{
+		name:        "load",
+		description: "Generate documents and bulk load them (the default without a command)",
+		mode:        "load,dry-run",
+		flags: []string{
+			"spec", "export-spec", "schema-dir", "schema", "size", "doc-size", "template", "time-range", "time-distribution", "ordered-times", "padding-mode", "padding-sizing", "exact-size", "strict-size", "checksum", "bson-types", "orders-per-customer", "line-items-per-order", "nesting-depth", "cardinality", "sparsity", "field-values", "field-templates", "legacy-fraction", "locales", "coherent-addresses", "no-pii", "tenants", "tag-run", "tag-fields", "key-space-from", "key-seed", "instance",
+			"target-metric", "size-poll-interval",
+			"workers", "writers", "batch-size", "batch-bytes", "buffer-docs", "adaptive-buffer", "marshal-workers",
+			"auto-tune", "auto-tune-writers", "auto-tune-batches", "auto-tune-burst", "auto-tune-latency",
+			"collection-count", "collection-template", "collection-distribution",
+			"connection-mode", "max-pool-size", "min-pool-size", "write-mode", "ordered", "load-profile", "clients", "client-batch", "think-time", "think-jitter",
+			"clustered", "collation", "shard-key", "presplit-chunks", "pause-balancer", "direct-shards", "shard-stats-interval", "oplog-stats-interval", "max-replication-lag", "sympathetic", "health-poll-interval", "max-cache-dirty", "min-free-tickets", "orders-collection", "grow-steps", "sink", "sink-target", "tail-changestream", "insert-timeout", "max-retries", "retry-backoff", "exactly-once", "drain-timeout",
+			"encrypt-fields", "encryption-mode", "encrypt-equality", "kms-provider", "kms-key-file", "kms-master-key", "crypt-shared-lib",
+			"duplicate-ratio", "duplicate-mode", "churn-rate", "churn-keep",
+			"steady-state", "steady-state-duration", "steady-state-rate", "steady-state-mix", "update-fields", "push-cap", "scan-length", "op-think-time", "threads",
+			"maintain-size", "maintain-interval", "maintain-tolerance",
+			"create-views", "materialize-interval", "materialize-stage", "bench-queries", "aggregate-timeout", "op-timeout",
+			"chaos-delay", "chaos-max-delay", "chaos-duplicate", "chaos-fail", "chaos-reset", "chaos-drop", "oversize-ratio",
+			"slo", "slo-interval", "slo-abort", "statsd-addr", "statsd-prefix", "statsd-interval",
+			"dry-run", "verify", "report", "artifact-dir", "bundle", "bundle-s3",
+			"collect-diagnostics", "atlas-public-key", "atlas-private-key", "atlas-group-id", "atlas-hosts",
+		},
+	},
+	{
+		name:        "import",
+		description: "Load a previously generated, possibly hand-edited, dataset file (see --from) with the load's writers",
+		mode:        "load",
+		flags: []string{
+			"from", "size", "doc-size", "template", "tenants", "tag-run", "tag-fields",
+			"workers", "writers", "batch-size", "batch-bytes", "buffer-docs", "adaptive-buffer", "marshal-workers",
+			"auto-tune", "auto-tune-writers", "auto-tune-batches", "auto-tune-burst", "auto-tune-latency",
+			"collection-count", "collection-template", "collection-distribution",
+			"connection-mode", "max-pool-size", "min-pool-size", "write-mode", "ordered", "load-profile", "clients", "client-batch", "think-time", "think-jitter",
+			"clustered", "collation", "shard-key", "pause-balancer", "direct-shards", "shard-stats-interval", "oplog-stats-interval", "max-replication-lag", "sympathetic", "health-poll-interval", "max-cache-dirty", "min-free-tickets", "sink", "sink-target", "insert-timeout", "op-timeout", "max-retries", "retry-backoff", "drain-timeout",
+			"chaos-delay", "chaos-max-delay", "chaos-duplicate", "chaos-fail", "chaos-reset", "chaos-drop",
+			"slo", "slo-interval", "slo-abort", "statsd-addr", "statsd-prefix", "statsd-interval",
+			"verify", "report", "artifact-dir", "bundle", "bundle-s3",
+			"collect-diagnostics", "atlas-public-key", "atlas-private-key", "atlas-group-id", "atlas-hosts",
+		},
+	},
+	{
+		name:        "run-workload",
+		description: "Run an operation mix against a collection from an earlier load",
+		mode:        "workload",
+		implies:     map[string]string{"run-workload": "true"},
+		flags: []string{
+			"spec", "export-spec", "read-only", "duration", "threads", "rate", "load-profile", "workload-mix", "mix-schedule",
+			"touch-rate", "update-fields", "push-cap", "read-projection", "scan-length", "op-think-time", "think-jitter", "ryow-sample", "ryow-max-staleness", "ryow-timeout", "text-index", "search-index", "search-index-name", "key-space-from", "padding-mode", "write-mode", "read-preference", "causal-consistency", "sessions",
+			"insert-timeout", "query-timeout", "aggregate-timeout", "op-timeout", "slo", "slo-interval", "slo-abort", "statsd-addr", "statsd-prefix", "statsd-interval", "report",
+		},
+	},
+	{
+		name:        "run-scenario",
+		description: "Run the phases of a scenario file (loads, workloads, verifications) one after another",
+		mode:        "scenario",
+		flags:       []string{"scenario"},
+	},
+	{
+		name:        "verify",
+		description: "Re-read a collection and validate its document checksums",
+		mode:        "verify-checksums",
+		implies:     map[string]string{"verify-checksums": "true"},
+		flags:       []string{"checksum-sample"},
+	},
+	{
+		name:        "bench-agg",
+		description: "Run a library of aggregation pipelines against a loaded collection and report their latencies",
+		mode:        "bench-agg",
+		implies:     map[string]string{"bench-agg": "true"},
+		flags:       []string{"bench-queries", "bench-iterations", "aggregate-timeout", "op-timeout", "key-space-from", "read-preference"},
+	},
+	{
+		name:        "clean",
+		description: "Drop generated collections or databases, or delete the documents of one run",
+		mode:        "clean",
+		implies:     map[string]string{"clean": "true"},
+		flags:       []string{"clean-run", "drop-database", "yes"},
+	},
+}
+
+// findCommand returns the subcommand with the given name
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// parseCommandLine parses a subcommand and its flags into the global flag
+// set and returns the command, or nil when the arguments start with a flag
+// (the original single flag set, which runs a load)
+func parseCommandLine(args []string) *command {
+	flag.Usage = usage
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		flag.CommandLine.Parse(args)
+		return nil
+	}
+	if args[0] == "help" {
+		usage()
+		os.Exit(0)
+	}
+
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(flag.CommandLine.Output(), "Unknown command: %s\n\n", args[0])
+		usage()
+		os.Exit(2)
+	}
+
+	// The command's flag set shares the global flags' values
+	fs := flag.NewFlagSet("gendata "+cmd.name, flag.ExitOnError)
+	for _, name := range append(append([]string(nil), commonFlags...), cmd.flags...) {
+		f := flag.Lookup(name)
+		fs.Var(f.Value, f.Name, f.Usage)
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: gendata %s [flags]\n\n%s.\n\nFlags:\n", cmd.name, cmd.description)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args[1:])
+	if fs.NArg() > 0 {
+		fmt.Fprintf(fs.Output(), "Unexpected arguments: %s\n\n", strings.Join(fs.Args(), " "))
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	// Mark the flags as set on the global flag set so config files and specs
+	// do not override them
+	fs.Visit(func(f *flag.Flag) {
+		flag.CommandLine.Set(f.Name, f.Value.String())
+	})
+	for name, value := range cmd.implies {
+		flag.CommandLine.Set(name, value)
+	}
+	return cmd
+}
+
+// checkMode fails if a config file or spec switched the command to another mode
+func (c *command) checkMode(mode string) error {
+	for _, m := range strings.Split(c.mode, ",") {
+		if m == mode {
+			return nil
+		}
+	}
+	return fmt.Errorf("the configuration selects %s mode, which the %s command does not run", mode, c.name)
+}
+
+// usage prints the commands and the flags accepted without a command
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: gendata <command> [flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-14s%s\n", cmd.name, cmd.description)
+	}
+	fmt.Fprintf(out, "\nRun 'gendata <command> -h' for the flags of a command.\n")
+	fmt.Fprintf(out, "\nWithout a command, all flags below are accepted and select the mode, as in earlier versions:\n")
+	flag.PrintDefaults()
+}
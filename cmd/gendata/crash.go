@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+)
+
+// crashHooks flush what has been measured so far when the run terminates
+// abnormally; they run in reverse registration order, like deferred calls
+var (
+	crashMu    sync.Mutex
+	crashHooks []func(reason string)
+	crashOnce  sync.Once
+)
+
+// onCrash registers a hook to run on abnormal termination
+func onCrash(hook func(reason string)) {
+	crashMu.Lock()
+	defer crashMu.Unlock()
+	crashHooks = append(crashHooks, hook)
+}
+
+// runCrashHooks runs the registered hooks once
+func runCrashHooks(reason string) {
+	crashOnce.Do(func() {
+		crashMu.Lock()
+		hooks := append([]func(reason string){}, crashHooks...)
+		crashMu.Unlock()
+
+		for i := len(hooks) - 1; i >= 0; i-- {
+			hooks[i](reason)
+		}
+	})
+}
+
+// fatalf logs the error, flushes statistics through the crash hooks, and exits
+func fatalf(format string, args ...interface{}) {
+	reason := fmt.Sprintf(format, args...)
+	errorLog.Print(reason)
+	runCrashHooks(reason)
+	os.Exit(1)
+}
+
+// recoverCrash turns a panic in the calling goroutine into an orderly exit
+// that flushes statistics. Use as: defer recoverCrash()
+func recoverCrash() {
+	if r := recover(); r != nil {
+		reason := fmt.Sprintf("panic: %v", r)
+		errorLog.Printf("%s\n%s", reason, debug.Stack())
+		runCrashHooks(reason)
+		os.Exit(2)
+	}
+}
+
+// writePartialReport saves the statistics of an aborted run to its artifact folder
+func writePartialReport(runDir string, report []byte) {
+	if err := os.MkdirAll(runDir, 0o755); err != nil {
+		log.Printf("Warning: failed to create artifact folder: %v", err)
+		return
+	}
+	path := filepath.Join(runDir, "partial-report.txt")
+	if err := os.WriteFile(path, report, 0o644); err != nil {
+		log.Printf("Warning: failed to write partial report: %v", err)
+		return
+	}
+	log.Printf("Partial report saved to %s", path)
+}
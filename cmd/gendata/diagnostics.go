@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/internal/diagnostics"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// collectDiagnostics gathers server diagnostics (and Atlas logs, if
+// configured) for the run window into the run's artifact folder
+func collectDiagnostics(client *mongo.Client, runDir string, atlas diagnostics.AtlasConfig, start, end time.Time) {
+	dir := filepath.Join(runDir, "diagnostics")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	if err := diagnostics.Collect(ctx, client, dir); err != nil {
+		log.Printf("Warning: failed to collect diagnostics: %v", err)
+	}
+
+	if atlas.Enabled() {
+		atlasCtx, atlasCancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer atlasCancel()
+		if err := diagnostics.DownloadAtlasLogs(atlasCtx, atlas, dir, start, end); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+
+	log.Printf("Diagnostics collected in %s", dir)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/generator"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/mongo"
+)
+
+// drainer shuts a load down gracefully on the first signal: generation
+// stops, the writers insert the documents already generated, and the
+// context is cancelled only if that takes longer than the timeout or a
+// second signal arrives. Outside a load, a signal cancels immediately.
+type drainer struct {
+	timeout time.Duration
+	cancel  context.CancelFunc
+
+	mu        sync.Mutex
+	stop      func() // Stops generation (nil outside a load)
+	signalled bool
+	started   time.Time
+	elapsed   time.Duration
+	aborted   bool
+	done      chan struct{} // Closed by finish once the writers returned
+	finished  sync.Once
+}
+
+// newDrainer creates a drainer that cancels with cancel
+func newDrainer(timeout time.Duration, cancel context.CancelFunc) *drainer {
+	return &drainer{timeout: timeout, cancel: cancel, done: make(chan struct{})}
+}
+
+// startLoad registers the function that stops generation
+func (d *drainer) startLoad(stop func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stop = stop
+}
+
+// finish records that the writers returned, ending a drain in progress.
+// Later signals cancel immediately.
+func (d *drainer) finish() {
+	d.finished.Do(func() {
+		d.mu.Lock()
+		d.stop = nil
+		if d.signalled && !d.aborted {
+			d.elapsed = time.Since(d.started)
+		}
+		d.mu.Unlock()
+		close(d.done)
+	})
+}
+
+// handle waits for shutdown signals on sigChan
+func (d *drainer) handle(sigChan <-chan os.Signal) {
+	<-sigChan
+	log.Println("\nShutting down...")
+
+	d.mu.Lock()
+	stop := d.stop
+	if stop != nil {
+		d.signalled = true
+		d.started = time.Now()
+		d.aborted = d.timeout <= 0
+	}
+	d.mu.Unlock()
+
+	if stop == nil || d.timeout <= 0 {
+		d.cancel()
+		return
+	}
+
+	log.Printf("Draining generated documents (up to %v, signal again to abort)", d.timeout)
+	stop()
+
+	timer := time.NewTimer(d.timeout)
+	defer timer.Stop()
+	select {
+	case <-d.done:
+	case <-sigChan:
+		log.Println("Drain aborted")
+		d.abort()
+	case <-timer.C:
+		log.Printf("Drain timed out after %v", d.timeout)
+		d.abort()
+	}
+}
+
+// abort cancels the load before the drain completed
+func (d *drainer) abort() {
+	d.mu.Lock()
+	d.aborted = true
+	d.elapsed = time.Since(d.started)
+	d.mu.Unlock()
+	d.cancel()
+}
+
+// drainSummary describes the shutdown of a load interrupted by a signal
+type drainSummary struct {
+	Completed         bool    `json:"completed"`
+	Seconds           float64 `json:"seconds"`
+	BufferedLost      int     `json:"buffered_lost"`  // Never taken from the generator buffer
+	InFlightLost      int64   `json:"in_flight_lost"` // In writer batches or cancelled inserts
+	DocumentsUnloaded int64   `json:"documents_unloaded"`
+}
+
+// summary returns the drain summary, or nil if no signal was received
+func (d *drainer) summary(genService *generator.Service, mongoWriter *mongo.Writer) *drainSummary {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.signalled {
+		return nil
+	}
+
+	r := &drainSummary{
+		Completed:    !d.aborted,
+		Seconds:      d.elapsed.Seconds(),
+		BufferedLost: genService.GetStats().BufferDepth,
+		InFlightLost: mongoWriter.GetStats().DocumentsAbandoned,
+	}
+	r.DocumentsUnloaded = int64(r.BufferedLost) + r.InFlightLost
+	return r
+}
+
+// print writes the shutdown summary to out
+func (r *drainSummary) print(out io.Writer) {
+	fmt.Fprintf(out, "\n=== Shutdown ===\n")
+	if r.Completed {
+		fmt.Fprintf(out, "Drain: completed in %.1fs\n", r.Seconds)
+	} else {
+		fmt.Fprintf(out, "Drain: cancelled after %.1fs\n", r.Seconds)
+	}
+	fmt.Fprintf(out, "Documents left in the generator buffer: %d\n", r.BufferedLost)
+	fmt.Fprintf(out, "Documents in writer batches or cancelled inserts: %d\n", r.InFlightLost)
+	if r.DocumentsUnloaded > 0 {
+		fmt.Fprintf(out, "Lost: %d generated documents were not loaded\n", r.DocumentsUnloaded)
+	}
+}
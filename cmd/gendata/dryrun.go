@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/generator"
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"golang.org/x/sync/errgroup"
+)
+
+// runDryRun runs the generation pipeline without a cluster: sinks drain the
+// document channel and BSON-encode each document (the same work the writer
+// does before inserting) and then discard it. It reports pure generation and
+// encoding throughput for sizing --workers and --writers.
+func runDryRun(ctx context.Context, genService *generator.Service, sinks int, result *runSummary) error {
+	if sinks <= 0 {
+		sinks = 1
+	}
+
+	var docsEncoded, bytesEncoded int64
+	start := time.Now()
+
+	done := make(chan struct{})
+	var progress sync.WaitGroup
+	progress.Add(1)
+	go func() {
+		defer progress.Done()
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				genStats := genService.GetStats()
+				fmt.Fprintf(console, "\r[Gen: %d docs, %.2f MB/s] [Encoded: %.2f GB]",
+					genStats.DocumentsGenerated,
+					genStats.BytesPerSecond/(1024*1024),
+					float64(atomic.LoadInt64(&bytesEncoded))/(1024*1024*1024),
+				)
+				os.Stdout.Sync()
+			}
+		}
+	}()
+
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		return genService.Generate(ctx)
+	})
+	// Generate does not close the channel when it is cancelled, so the sinks
+	// stop on the context too
+	documents := genService.Documents()
+	for i := 0; i < sinks; i++ {
+		eg.Go(func() error {
+			for {
+				var doc model.Document
+				var ok bool
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case doc, ok = <-documents:
+					if !ok {
+						return nil
+					}
+				}
+				data, err := bson.Marshal(doc)
+				if err != nil {
+					return fmt.Errorf("failed to marshal document: %w", err)
+				}
+				atomic.AddInt64(&bytesEncoded, int64(len(data)))
+				atomic.AddInt64(&docsEncoded, 1)
+			}
+		})
+	}
+	err := eg.Wait()
+	close(done)
+	progress.Wait()
+
+	elapsed := time.Since(start)
+	genStats := genService.GetStats()
+	docs := atomic.LoadInt64(&docsEncoded)
+	bytes := atomic.LoadInt64(&bytesEncoded)
+
+	fmt.Fprintf(console, "\n\n=== Dry Run Statistics ===\n")
+	fmt.Fprintf(console, "Total time: %v\n", elapsed.Round(time.Second))
+	fmt.Fprintf(console, "Documents generated: %d\n", genStats.DocumentsGenerated)
+	fmt.Fprintf(console, "Bytes encoded: %.2f GB\n", float64(bytes)/(1024*1024*1024))
+	if docs > 0 {
+		fmt.Fprintf(console, "Average BSON size: %d bytes\n", bytes/docs)
+	}
+	fmt.Fprintf(console, "Generation rate: %.2f docs/sec, %.2f MB/s\n",
+		float64(docs)/elapsed.Seconds(),
+		float64(bytes)/(1024*1024)/elapsed.Seconds(),
+	)
+
+	result.DryRun = &dryRunSummary{
+		DocumentsGenerated: docs,
+		BytesEncoded:       bytes,
+		DocumentsPerSecond: float64(docs) / elapsed.Seconds(),
+		BytesPerSecond:     float64(bytes) / elapsed.Seconds(),
+	}
+
+	if err == context.Canceled {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/meticulous-dft/mongodb-data-generator/internal/mongo"
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// encryptedFieldTypes resolves the BSON types of the fields to encrypt. Fields
+// must be top-level fields of the template whose type is the same in every
+// document, so sparse fields and legacy documents are rejected.
+func encryptedFieldTypes(template string, fields []string, sparsity model.Sparsity, legacyFraction float64) (map[string]bsontype.Type, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	if legacyFraction > 0 {
+		return nil, fmt.Errorf("--encrypt-fields does not support --legacy-fraction, whose documents change field types")
+	}
+	types, err := model.FieldTypes(template)
+	if err != nil {
+		return nil, err
+	}
+	encrypted := make(map[string]bsontype.Type, len(fields))
+	for _, field := range fields {
+		typ, ok := types[field]
+		if !ok {
+			return nil, fmt.Errorf("%s is not a top-level field of the %s template", field, template)
+		}
+		if _, ok := sparsity[field]; ok {
+			return nil, fmt.Errorf("encrypted field %s cannot be sparse", field)
+		}
+		encrypted[field] = typ
+	}
+	return encrypted, nil
+}
+
+// encryptionConfig returns the writer's encryption settings for the encrypted
+// fields (nil = none), loading or creating the local master key
+func encryptionConfig(fields map[string]bsontype.Type, mode string, equality bool, kmsProvider, keyFile, masterKey, cryptSharedLib string) (*mongo.EncryptionConfig, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	if !mongo.EncryptionAvailable() {
+		return nil, fmt.Errorf("--encrypt-fields requires a build with libmongocrypt (go build -tags cse)")
+	}
+	config := &mongo.EncryptionConfig{
+		Mode:           mode,
+		Fields:         fields,
+		Equality:       equality,
+		KMSProvider:    kmsProvider,
+		CryptSharedLib: cryptSharedLib,
+	}
+	if kmsProvider == mongo.KMSLocal {
+		key, created, err := mongo.LoadLocalKey(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			log.Printf("Created local master key %s; keep it to write to the encrypted collection again", keyFile)
+		}
+		config.LocalKey = key
+		return config, nil
+	}
+	key, err := mongo.ParseMasterKey(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	config.MasterKey = key
+	return config, nil
+}
@@ -0,0 +1,23 @@
+package main
+
+import "flag"
+
+// importUnsupported are the load flags that depend on generated documents
+// (their schema, key space, sizes, or ObjectID _ids) and are rejected with
+// --from unless left at their defaults
+var importUnsupported = []string{
+	"spec", "export-spec", "exact-size", "strict-size", "key-space-from", "key-seed", "instance", "target-metric",
+	"steady-state", "update-fields", "push-cap", "scan-length", "op-think-time", "maintain-size", "create-views", "materialize-interval", "churn-rate",
+	"presplit-chunks", "tail-changestream", "exactly-once", "encrypt-fields",
+	"orders-collection", "grow-steps", "duplicate-ratio", "oversize-ratio",
+}
+
+// flagSet reports whether the flag was set on the command line or by a
+// config file or spec
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
@@ -1,41 +1,344 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"maps"
+	"math"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
-	"github.com/meticulous-dft/mongodb-data-generator/internal/generator"
+	"github.com/meticulous-dft/mongodb-data-generator/generator"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/bench"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/config"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/dataset"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/diagnostics"
 	"github.com/meticulous-dft/mongodb-data-generator/internal/logger"
-	"github.com/meticulous-dft/mongodb-data-generator/internal/model"
 	"github.com/meticulous-dft/mongodb-data-generator/internal/mongo"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/pacing"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/report"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/spec"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/verify"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/workload"
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"github.com/meticulous-dft/mongodb-data-generator/sink"
 )
 
 func main() {
 	var (
+		configFile       = flag.String("config", "", "Load flag values from a YAML or TOML (.toml) file; flags given on the command line override it")
+		scenarioFile     = flag.String("scenario", "", "Run the phases of this YAML or TOML scenario file one after another, each as a gendata command (see run-scenario)")
+		specFile         = flag.String("spec", "", "Load the experiment from a JSON spec written by --export-spec; flags given on the command line override it")
+		exportSpec       = flag.String("export-spec", "", "Write the effective workload (documents, rates, mix phases, faults) as a portable JSON spec to this file")
 		connectionString = flag.String("connection", "", "MongoDB connection string (required)")
 		databaseName     = flag.String("database", "testdb", "Database name")
 		collectionName   = flag.String("collection", "customers", "Collection name")
+		collectionCount  = flag.Int("collection-count", 0, "Spread insert batches over this many collections named by --collection-template (0 = --collection only)")
+		collectionTmpl   = flag.String("collection-template", "coll_{n}", "Name of the --collection-count collections, with {n} replaced by the collection number from 0")
+		collectionDist   = flag.String("collection-distribution", "round-robin", "How batches are spread over the --collection-count collections: round-robin or zipfian")
 		targetSize       = flag.String("size", "1TB", "Target data size (e.g., 1TB, 500GB, 32TB)")
-		docSize          = flag.String("doc-size", "auto", "Document size: 2KB, 4KB, 8KB, 16KB, 32KB, 64KB, or auto")
+		docSize          = flag.String("doc-size", "auto", "Document size: 2KB, 4KB, 8KB, 16KB, 32KB, 64KB, 256KB, 1MB, 4MB, 16MB, or auto (512B and 1KB for the events template)")
+		template         = flag.String("template", "customer", "Document model: customer (customers with addresses, payments, and orders) product (catalog products with variants, inventory, and reviews), telemetry (IoT device buckets of sensor readings), transactions (ledger transactions with double-entry postings), messages (conversations with embedded messages), or events (small append-only log events)")
+		timeRange        = flag.String("time-range", "", "Time window all generated timestamps fall in, as START..END or START/END in RFC3339 or YYYY-MM-DD (empty = relative to now; time-series templates use the 30 days before the load starts)")
+		timeDistribution = flag.String("time-distribution", "uniform", "How timestamps are spread over their range: uniform or recent (denser towards the end)")
+		targetMetric     = flag.String("target-metric", "bytes", "What --size measures: bytes (marshaled BSON written), data-size, storage-size, or total-size (storage plus indexes, from collection stats)")
+		sizePollInterval = flag.Duration("size-poll-interval", 10*time.Second, "How often collection stats are polled for a --target-metric other than bytes")
 		workers          = flag.Int("workers", 0, "Number of generator workers (0 = auto)")
 		writers          = flag.Int("writers", 0, "Number of MongoDB writer workers (0 = auto)")
 		batchSize        = flag.Int("batch-size", 0, "Batch size for MongoDB writes (0 = auto)")
+		batchBytes       = flag.String("batch-bytes", "", "Also end each batch before its BSON size exceeds this (e.g., 16MB, at most 44MB to fit the 48MB message limit), so the driver never splits it (empty = document count only)")
+		bufferDocs       = flag.Int("buffer-docs", 0, "Capacity of the generator-to-writer buffer in documents (0 = 2x batch size)")
+		marshalWorkers   = flag.Int("marshal-workers", 0, "Serialize documents to BSON in a pool of this many workers feeding the writers marshaled batches (0 = writers marshal their own batches)")
+		adaptiveBuffer   = flag.Bool("adaptive-buffer", false, "Pause generation workers while the buffer is over 90% full until it drains below 50%")
+		autoTune         = flag.Bool("auto-tune", false, "Start the load with calibration bursts of --auto-tune-writers and --auto-tune-batches and continue with the fastest configuration within --auto-tune-latency")
+		tuneWriters      = flag.String("auto-tune-writers", "2,4,8,16,32", "Comma-separated writer counts --auto-tune tries with --batch-size")
+		tuneBatches      = flag.String("auto-tune-batches", "100,500,1000,5000", "Comma-separated batch sizes --auto-tune tries with the best writer count")
+		tuneBurst        = flag.Duration("auto-tune-burst", 10*time.Second, "Length of each --auto-tune calibration burst")
+		tuneLatency      = flag.Duration("auto-tune-latency", 500*time.Millisecond, "Bound on the p95 insert batch latency of the configuration --auto-tune picks (0 = none)")
+		connectionMode   = flag.String("connection-mode", "shared", "Connection pooling of the writers: shared (one client) or per-writer (one client per writer)")
+		maxPoolSize      = flag.Int("max-pool-size", 0, "Maximum connections per client pool (0 = 10x writers for shared, 2 for per-writer)")
+		minPoolSize      = flag.Int("min-pool-size", 0, "Minimum connections kept open per client pool (0 = writers for shared, none for per-writer)")
+		writeMode        = flag.String("write-mode", "insertMany", "Driver API for writes: insertMany, bulkWrite, or insertOne (workload writes: bulkWrite or single operations)")
+		ordered          = flag.Bool("ordered", false, "Send insert batches ordered, stopping each batch at its first error")
 		verbose          = flag.Bool("verbose", false, "Verbose logging")
 		logFile          = flag.String("log-file", "ycsb.log", "YCSB-style log file path")
+		sloList          = flag.String("slo", "", "Comma-separated latency objectives as OPERATION:pNN<DURATION (e.g., insert:p99<50ms,read:p95<10ms), checked every --slo-interval")
+		sloInterval      = flag.Duration("slo-interval", 10*time.Second, "Interval over which --slo latency percentiles are checked")
+		sloAbort         = flag.Int("slo-abort", 0, "Abort the run once an --slo is violated for this many consecutive intervals (0 = never)")
+		timeSeriesFile   = flag.String("timeseries-file", "", "Export per-second throughput and p50/p99 latency per operation type to this file (.csv, or .json for JSON Lines)")
+		statsdAddr       = flag.String("statsd-addr", "", "Push throughput and latency gauges per operation type to this statsd server (host:port, e.g. localhost:8125) every --statsd-interval")
+		statsdPrefix     = flag.String("statsd-prefix", "gendata", "Prefix of the --statsd-addr metric names")
+		statsdInterval   = flag.Duration("statsd-interval", 10*time.Second, "Interval of the gauges pushed to --statsd-addr")
+		hgrmDir          = flag.String("hgrm-dir", "", "Write the final latency distribution of each operation type to <dir>/<OPERATION>.hgrm in HdrHistogram percentile format")
+		sinkName         = flag.String("sink", sink.MongoDB, "Where loads write documents: "+strings.Join(append([]string{sink.MongoDB}, sink.Names()...), ", ")+" (see --sink-target)")
+		sinkTarget       = flag.String("sink-target", "", "Destination of a --sink other than mongodb, e.g., the file path (.jsonl for Extended JSON lines, .bson for BSON), the Kafka brokers/topic[?format=avro], or bson for stdout")
+		importFrom       = flag.String("from", "", "Import this dataset instead of generating documents: Extended JSON lines, or concatenated BSON if the path ends in .bson, optionally zstd-compressed (.zst); written in full unless --size is set")
+		reportFile       = flag.String("report", "", "Write a self-contained final report (results, throughput and latency charts, percentiles, errors, configuration) to this file (.html or .md)")
+		clients          = flag.Int("clients", 0, "Number of simulated client applications, each with its own connection and session (0 = use writers)")
+		clientBatchSize  = flag.Int("client-batch", 1, "Documents per insert for each simulated client")
+		thinkTime        = flag.Duration("think-time", 100*time.Millisecond, "Think time between operations of each simulated client")
+		thinkJitter      = flag.String("think-jitter", "fixed", "Variation of --think-time and --op-think-time: fixed, uniform (between 0 and twice the mean), or exponential (mean-preserving Poisson arrivals)")
+		opThinkTime      = flag.Duration("op-think-time", 0, "Think time of each workload or steady-state thread after each of its operations, to model many slow application threads (0 = none)")
+		readOnly         = flag.Bool("read-only", false, "Skip generation and run the read workload against a collection from an earlier run")
+		runWorkloadOnly  = flag.Bool("run-workload", false, "Skip generation and run the workload mix (reads and writes) against a collection from an earlier run")
+		readPreference   = flag.String("read-preference", "primary", "Read preference of the workload: primary, primaryPreferred, secondary, secondaryPreferred, or nearest")
+		causal           = flag.Bool("causal-consistency", false, "Run each workload thread in a causally consistent session, with majority read and write concern")
+		sessions         = flag.Int("sessions", 0, "Number of causally consistent sessions the workload threads share, each carrying its causal tokens from operation to operation, to stress the server's session cache (0 = one per thread; needs --causal-consistency)")
+		textIndex        = flag.Bool("text-index", false, "Create a text index on the template's text fields (e.g., notes) before the workload, for text operations")
+		searchIndexFile  = flag.String("search-index", "", "Create (or update) an Atlas Search index from this JSON definition before the workload and wait until it is queryable, for search operations")
+		searchIndexName  = flag.String("search-index-name", "default", "Name of the Atlas Search index search operations query")
+		updateFields     = flag.String("update-fields", "", "Comma-separated fields workload and steady-state updates set, leaving the rest alone, as dotted paths with array elements by index (e.g., orders.0.status,updated_at)")
+		readProjection   = flag.String("read-projection", "full", "What workload reads and scans return: full, covered (only the field they filter on), fields:a,b.c, or slice:N (the key and N elements of the template's array)")
+		scanLength       = flag.String("scan-length", workload.DefaultScanLength.String(), "Documents a scan operation returns, as N or MIN-MAX, drawn uniformly")
+		ryowSample       = flag.Float64("ryow-sample", 0, "Fraction of workload inserts re-read from a secondary until visible, measuring the replication lag the application sees (0 = none)")
+		ryowStaleness    = flag.Duration("ryow-max-staleness", 0, "Max staleness of the secondaries --ryow-sample reads from (0 = any secondary; at least 90s)")
+		ryowTimeout      = flag.Duration("ryow-timeout", 10*time.Second, "How long a --ryow-sample check waits for an inserted document to show up on a secondary")
+		pushCap          = flag.Int("push-cap", 0, "Elements push operations of the workload or steady state keep in the array they grow, dropping the oldest (0 = 1000 orders or reviews, 2000 messages)")
+		touchRate        = flag.Int("touch-rate", 0, "Documents per second whose updated_at is touched in a background sweep during the workload (0 = none)")
+		duration         = flag.Duration("duration", 10*time.Minute, "Duration of the workload")
+		threads          = flag.Int("threads", 0, "Number of workload threads (0 = auto)")
+		workloadRate     = flag.Int("rate", 0, "Operations per second of the workload across all threads (0 = unlimited)")
+		loadProfile      = flag.String("load-profile", "", "Vary the target rate over the run: documents per second of a load, operations per second of a workload, e.g. ramp:0-10000ops/30m, step:1000-5000ops/1h,5, sine:2000-8000ops/10m, spike:1000-20000ops/15m,30s")
+		workloadMix      = flag.String("workload-mix", "read=95,aggregate=5", "Workload operation mix (read, aggregate, insert, update)")
+		mixSchedule      = flag.String("mix-schedule", "", "Drift the workload mix over the run, e.g. \"0s:insert=80,read=20;2h:insert=20,read=80\"")
+		clustered        = flag.Bool("clustered", false, "Create the collection clustered by _id (MongoDB 5.3+) if it does not exist")
+		collation        = flag.String("collation", "", "Create the collection with this default collation, as locale[,option=value...] (e.g., de,strength=2); customer names and addresses follow the locale")
+		noPII            = flag.Bool("no-pii", false, "Replace names, emails, phones, and card numbers with obviously synthetic tokens of the same size and type")
+		orderedTimes     = flag.Bool("ordered-times", false, "Spread created_at and order dates over --time-range in insertion order instead of at random (customer, product, and messages templates)")
+		coherentAddr     = flag.Bool("coherent-addresses", false, "Place each customer's addresses in one country (of its locale, or the United States), in real cities with their regions and postal codes, with a matching phone number and order currency (customer template)")
+		localeList       = flag.String("locales", "", "Comma-separated locales of customer names, addresses, and notes, picked per document: "+strings.Join(model.Locales(), ", ")+" (empty = the --collation locale, or en)")
+		shardKey         = flag.String("shard-key", "", "Shard the collection on this field before loading, as field or field:hashed (sharded clusters only)")
+		presplitChunks   = flag.Int("presplit-chunks", 0, "Split the empty collection into this many chunks and distribute them across shards before loading (requires --shard-key)")
+		encryptFields    = flag.String("encrypt-fields", "", "Comma-separated top-level fields to encrypt with automatic client-side encryption (e.g., email,payment_methods); requires a build with -tags cse")
+		encryptionMode   = flag.String("encryption-mode", mongo.EncryptionCSFLE, "Encryption of --encrypt-fields: csfle (client-side field level encryption) or qe (Queryable Encryption, MongoDB 7.0+)")
+		encryptEquality  = flag.Bool("encrypt-equality", false, "Encrypt fields for equality queries (deterministic CSFLE or QE equality indexes) instead of randomly")
+		kmsProvider      = flag.String("kms-provider", mongo.KMSLocal, "KMS provider of the master key encrypting data keys: local, aws, azure, or gcp (credentials from the environment)")
+		kmsKeyFile       = flag.String("kms-key-file", "master-key.bin", "File with the 96-byte local master key, created if missing")
+		kmsMasterKey     = flag.String("kms-master-key", "", "Master key of a cloud KMS provider, as key=value pairs (e.g., region=us-east-1,key=arn:aws:kms:...)")
+		cryptSharedLib   = flag.String("crypt-shared-lib", "", "Path of the automatic encryption shared library (empty = spawn mongocryptd from the PATH)")
+		directShards     = flag.String("direct-shards", "", "Write directly to the shards' replica sets instead of through mongos, as shard=URI;shard=URI (requires --pause-balancer)")
+		oplogStatsEvery  = flag.Duration("oplog-stats-interval", 0, "How often the oplog window and replication lag are polled for the progress output (0 = never, or 10s with --max-replication-lag; replica sets only)")
+		maxReplLag       = flag.Duration("max-replication-lag", 0, "Pause inserts while the slowest secondary lags further behind than this, or half the oplog window (0 = never)")
+		sympathetic      = flag.Bool("sympathetic", false, "Back off inserts while serverStatus shows write pressure: dirty cache, few write tickets, or flow control")
+		healthPoll       = flag.Duration("health-poll-interval", 2*time.Second, "How often --sympathetic polls serverStatus")
+		maxCacheDirty    = flag.Float64("max-cache-dirty", 10, "With --sympathetic, percent of the WiredTiger cache that may be dirty before inserts back off")
+		minFreeTickets   = flag.Float64("min-free-tickets", 0.2, "With --sympathetic, fraction (0-1) of write tickets that must stay available before inserts back off")
+		shardStatsEvery  = flag.Duration("shard-stats-interval", 30*time.Second, "How often the collection's distribution across shards is polled for the progress output (0 = never; sharded collections only)")
+		pauseBalancer    = flag.Bool("pause-balancer", false, "Stop the balancer during the load and restart it afterwards (sharded clusters only)")
+		ordersCollection = flag.String("orders-collection", "", "Also write each customer's orders to this collection, referencing existing customer_ids")
+		growSteps        = flag.Int("grow-steps", 0, "Insert documents without their arrays and padding, then grow them to full size with this many rounds of updates (0 = insert full documents)")
+		tailChangeStream = flag.Bool("tail-changestream", false, "Measure insert-to-change-notification latency as CHANGESTREAM operations")
+		insertTimeout    = flag.Duration("insert-timeout", 0, "Deadline for each insert or update (0 = none)")
+		queryTimeout     = flag.Duration("query-timeout", 0, "Deadline (and maxTimeMS) for each read and scan (0 = none)")
+		aggTimeout       = flag.Duration("aggregate-timeout", 0, "Deadline (and maxTimeMS) for each aggregation (0 = none)")
+		opTimeout        = flag.Duration("op-timeout", 0, "Deadline of every write and read whose --insert-timeout, --query-timeout, or --aggregate-timeout is unset (0 = none)")
+		maxRetries       = flag.Int("max-retries", 3, "Retries for inserts failing with network errors, timeouts, or retryable errors")
+		retryBackoff     = flag.Duration("retry-backoff", 100*time.Millisecond, "Initial backoff between insert retries (doubles per attempt)")
+		exactlyOnce      = flag.Bool("exactly-once", false, "Before retrying a batch after a timeout or network error, look up which of its documents were inserted and resend only the rest")
+		duplicateRatio   = flag.Float64("duplicate-ratio", 0, "Fraction of writes (0-1) that reuse the _id of an already written document")
+		churnRate        = flag.Int("churn-rate", 0, "Delete the oldest documents at up to this many docs/sec while inserting (0 = no churn)")
+		churnKeep        = flag.String("churn-keep", "", "Live data size to hold steady under churn (e.g., 10GB; default: half of --size)")
+		steadyState      = flag.Bool("steady-state", false, "After the load reaches its target, keep updating, pushing to, and deleting the loaded documents")
+		steadyDuration   = flag.Duration("steady-state-duration", 0, "How long the --steady-state phase runs (0 = until interrupted)")
+		steadyRate       = flag.Int("steady-state-rate", 1000, "Operations per second of the --steady-state phase (0 = unlimited)")
+		maintainSize     = flag.Bool("maintain-size", false, "After the load reaches its target, keep the collection at --size by topping it up or trimming it, until interrupted")
+		maintainInterval = flag.Duration("maintain-interval", 30*time.Second, "How often --maintain-size checks the collection size")
+		sizeTolerance    = flag.Float64("maintain-tolerance", 0.01, "Fraction of --size the collection may drift before --maintain-size corrects it")
+		createViews      = flag.Bool("create-views", false, "After the load, create a standard view over the collection for each --bench-queries query")
+		materializeEvery = flag.Duration("materialize-interval", 0, "After the load, refresh a summary collection of each --bench-queries query at this interval, until interrupted (0 = never)")
+		materializeStage = flag.String("materialize-stage", bench.StageMerge, "How --materialize-interval writes the summary collections: merge ($merge) or out ($out)")
+		steadyMix        = flag.String("steady-state-mix", "update=70,push=25,delete=5", "Operation mix of the --steady-state phase (update, push, delete, insert, read, aggregate)")
+		duplicateMode    = flag.String("duplicate-mode", "insert", "How duplicate writes are issued: insert (expect duplicate key errors) or upsert")
+		drainTimeout     = flag.Duration("drain-timeout", 20*time.Second, "On SIGTERM or interrupt, how long writers may insert the documents already generated before the load is cancelled (0 = cancel immediately)")
+		artifactDir      = flag.String("artifact-dir", "artifacts", "Directory for run artifacts (one subfolder per run)")
+		chaosDelay       = flag.Float64("chaos-delay", 0, "Fraction of insert batches (0-1) delayed by up to --chaos-max-delay before reaching the driver")
+		chaosMaxDelay    = flag.Duration("chaos-max-delay", time.Second, "Maximum injected batch delay")
+		chaosDuplicate   = flag.Float64("chaos-duplicate", 0, "Fraction of insert batches (0-1) sent a second time after succeeding")
+		chaosFail        = flag.Float64("chaos-fail", 0, "Fraction of insert batch attempts (0-1) failed with a retryable error without being sent")
+		chaosReset       = flag.Float64("chaos-reset", 0, "Fraction of insert batch attempts (0-1) failed with a network reset after being sent")
+		chaosDrop        = flag.Float64("chaos-drop", 0, "Fraction of insert batches (0-1) dropped without being sent or counted")
+		oversizeRatio    = flag.Float64("oversize-ratio", 0, "Fraction of documents (0-1) written as jumbo documents: half just over the 16MB BSON limit, half at exactly the limit")
+		checksum         = flag.Bool("checksum", false, "Embed a checksum of each document's canonical fields for later integrity verification")
+		ordersPerCust    = flag.String("orders-per-customer", "", "Orders per customer document, as N or MIN-MAX (empty = scale with --doc-size)")
+		lineItemsPerOrd  = flag.String("line-items-per-order", "", "Line items per order, as N or MIN-MAX (empty = scale with --doc-size)")
+		cardinality      = flag.String("cardinality", "", "Distinct values per field, as field=N or field=unique, comma-separated (e.g., email=1000,orders.status=50)")
+		sparsity         = flag.String("sparsity", "", "Probability of fields being missing or null, as field=P or field=P:null, comma-separated (e.g., phone=0.3,orders.notes=0.5:null)")
+		fieldValuesFile  = flag.String("field-values", "", "YAML or JSON file mapping fields to weighted value lists (e.g., status: {delivered: 70%, cancelled: 5%}), lists, or CSV files of values")
+		schemaDir        = flag.String("schema-dir", "", "Directory of named schemas: YAML, JSON, or TOML files of document flags (template, doc-size, cardinality, ...), each of which may extend another (see --schema)")
+		schemaName       = flag.String("schema", "", "Schema of --schema-dir whose document flags apply where the command line, config file, and spec leave them unset")
+		derivedFieldFile = flag.String("field-templates", "", "YAML or JSON file mapping fields to Go templates that derive their values from other fields (e.g., email: \"{{lower .FirstName}}@example.com\")")
+		legacyFraction   = flag.Float64("legacy-fraction", 0, "Fraction of documents (0-1) generated in the template's legacy schema, with fields missing, renamed, or of another type")
+		nestingDepth     = flag.Int("nesting-depth", 0, "Add a nested chain of subdocuments this many levels deep to each document (0 = none, at most 99)")
+		bsonTypes        = flag.Bool("bson-types", false, "Add a bson_types subdocument with a value of every BSON type (Decimal128, Binary, Int64, Regex, Timestamp, ...) to each document")
+		clean            = flag.Bool("clean", false, "Drop the collection, its orders collections, and its run metadata instead of generating data (see --clean-run, --drop-database)")
+		cleanRun         = flag.String("clean-run", "", "With --clean, delete only the documents of this run ID (or \"latest\"), which must have been loaded with --tag-run")
+		dropDatabase     = flag.Bool("drop-database", false, "With --clean, drop the whole database")
+		confirm          = flag.Bool("yes", false, "With --clean, remove the data instead of only reporting what would be removed")
+		tagRun           = flag.Bool("tag-run", false, "Stamp each document's metadata with the run ID and generation time (see --tag-fields) so runs can be told apart, verified, and cleaned up on their own")
+		tagFields        = flag.String("tag-fields", "run_id,generated_at", "Comma-separated stamps added by --tag-run: run_id (orders: run_id) and/or generated_at")
+		verifyChecksums  = flag.Bool("verify-checksums", false, "Re-read the collection and validate document checksums instead of generating data")
+		checksumSample   = flag.Int("checksum-sample", 0, "Documents to sample for --verify-checksums (0 = scan the whole collection)")
+		benchAgg         = flag.Bool("bench-agg", false, "Run a library of aggregation pipelines against a loaded collection and report their latencies instead of generating data")
+		benchQueries     = flag.String("bench-queries", "", "Comma-separated queries of --bench-agg, --create-views, and --materialize-interval (empty = every query that runs on the collection's template)")
+		benchIterations  = flag.Int("bench-iterations", 5, "Runs of each --bench-agg query")
+		verifyLoad       = flag.Bool("verify", false, "After the load, verify document count, average BSON size, required fields, and key presence, and exit non-zero on discrepancies")
+		keySpaceFrom     = flag.String("key-space-from", "", "Run ID (or \"latest\") whose key space to reuse: loads continue it, workloads target its documents")
+		keySeed          = flag.Int64("key-seed", 0, "Seed of the key space business keys are derived from, shared by the instances of a distributed load (0 = random)")
+		instance         = flag.String("instance", "", "Instance of a distributed load, e.g. 3/8 for the third of eight: its keys and sequential _ids come from a range of the key space no other instance uses")
+		tenants          = flag.String("tenants", "", "Comma-separated tenant IDs; each document gets a random one in metadata.tenant_id")
+		dryRun           = flag.Bool("dry-run", false, "Generate and BSON-encode documents without writing them, reporting pure generation throughput (no cluster needed)")
+		bundle           = flag.Bool("bundle", false, "Bundle the run's config, summary, YCSB log, and artifacts into <artifact-dir>/<run-id>.tar.gz at run end")
+		bundleS3         = flag.String("bundle-s3", "", "Upload the bundle to this S3 location (s3://bucket/prefix) using AWS_* environment credentials")
+		collectDiag      = flag.Bool("collect-diagnostics", false, "Collect server diagnostics (getLog, FTDC location, serverStatus, hostInfo) into the run's artifact folder at run end")
+		atlasPublicKey   = flag.String("atlas-public-key", os.Getenv("ATLAS_PUBLIC_KEY"), "Atlas API public key for downloading server logs (default $ATLAS_PUBLIC_KEY)")
+		atlasPrivateKey  = flag.String("atlas-private-key", os.Getenv("ATLAS_PRIVATE_KEY"), "Atlas API private key for downloading server logs (default $ATLAS_PRIVATE_KEY)")
+		atlasGroupID     = flag.String("atlas-group-id", "", "Atlas project ID for downloading server logs")
+		atlasHosts       = flag.String("atlas-hosts", "", "Comma-separated Atlas cluster hostnames whose logs to download")
+		quiet            = flag.Bool("quiet", false, "Suppress progress, statistics, and log output (errors are still written to stderr)")
+		summaryJSON      = flag.Bool("summary-json", false, "Print the final results as a single JSON object to stdout")
+		paddingMode      = flag.String("padding-mode", "random", "Padding content: random (compression resistant), base64 (valid UTF-8), binary (BSON binary data), or corpus (natural-language text)")
+		exactSize        = flag.Bool("exact-size", false, "Pad every document to within 16 bytes of --doc-size, calibrated for the metadata added after generation")
+		strictSize       = flag.Bool("strict-size", false, "Like --exact-size, but fail when a document's content alone exceeds --doc-size")
+		paddingSizing    = flag.String("padding-sizing", "marshal", "How padding is fitted: marshal (size each document by marshalling it) or patch (patch the padding into that encoding, which writers reuse instead of marshalling again)")
 	)
 
-	flag.Parse()
+	cmd := parseCommandLine(os.Args[1:])
 
-	if *connectionString == "" {
+	// A scenario runs each of its phases as a gendata command of its own
+	if *scenarioFile != "" || (cmd != nil && cmd.mode == "scenario") {
+		if *scenarioFile == "" {
+			log.Fatal("Error: --scenario is required")
+		}
+		if *quiet {
+			console = io.Discard
+			log.SetOutput(io.Discard)
+		}
+		result := newRunSummary("scenario")
+		code, err := runScenario(*scenarioFile, scenarioOverrides(), *summaryJSON, result)
+		if err != nil {
+			errorLog.Printf("Scenario error: %v", err)
+			result.Error = err.Error()
+		}
+		result.Success = code == 0
+		if *summaryJSON {
+			result.print()
+		}
+		os.Exit(code)
+	}
+
+	if *specFile != "" {
+		if err := applySpec(*specFile); err != nil {
+			log.Fatalf("Error loading spec: %v", err)
+		}
+	}
+	var pipelines []config.Pipeline
+	if *configFile != "" {
+		var err error
+		pipelines, err = config.ApplyFile(flag.CommandLine, *configFile)
+		if err != nil {
+			log.Fatalf("Error loading config: %v", err)
+		}
+	}
+	var schemaChain []string
+	if *schemaName != "" {
+		var err error
+		if schemaChain, err = applySchema(*schemaDir, *schemaName); err != nil {
+			log.Fatalf("Error loading schema: %v", err)
+		}
+	}
+
+	if *quiet {
+		console = io.Discard
+		log.SetOutput(io.Discard)
+	}
+
+	mode := "load"
+	switch {
+	case *clean:
+		mode = "clean"
+	case *verifyChecksums:
+		mode = "verify-checksums"
+	case *benchAgg:
+		mode = "bench-agg"
+	case *readOnly || *runWorkloadOnly:
+		mode = "workload"
+	case *dryRun:
+		mode = "dry-run"
+	}
+	if cmd != nil {
+		if err := cmd.checkMode(mode); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+	if len(pipelines) > 0 {
+		if mode != "load" {
+			log.Fatalf("Error: pipelines are only supported by loads, not %s", mode)
+		}
+		if err := checkUnsupported(pipelineUnsupported, "pipelines"); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+	if *importFrom != "" {
+		if mode != "load" {
+			log.Fatalf("Error: --from is only supported by loads, not %s", mode)
+		}
+		if err := checkUnsupported(importUnsupported, "imports"); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	} else if cmd != nil && cmd.name == "import" {
+		log.Fatal("Error: --from is required")
+	}
+	if *sinkName != sink.MongoDB {
+		if !slices.Contains(sink.Names(), *sinkName) {
+			log.Fatalf("Error: unknown sink %s (%s, %s)", *sinkName, sink.MongoDB, strings.Join(sink.Names(), ", "))
+		}
+		if mode != "load" {
+			log.Fatalf("Error: --sink %s is only supported by loads, not %s", *sinkName, mode)
+		}
+		if err := checkUnsupported(sinkUnsupported, "the "+*sinkName+" sink"); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		// stdout carries the documents, so progress and statistics go to stderr
+		if *sinkName == sink.Stdout {
+			if *summaryJSON {
+				log.Fatal("Error: --summary-json is not supported with the stdout sink")
+			}
+			if !*quiet {
+				console = os.Stderr
+			}
+		}
+	}
+	if *reportFile != "" {
+		if mode != "load" && mode != "workload" {
+			log.Fatalf("Error: --report is only supported by loads and workloads, not %s", mode)
+		}
+		if err := report.CheckPath(*reportFile); err != nil {
+			log.Fatalf("Error: --report: %v", err)
+		}
+	}
+	if *opTimeout < 0 {
+		log.Fatal("Error: --op-timeout must be non-negative")
+	}
+	// --op-timeout is the deadline of every operation kind without its own
+	for _, timeout := range []*time.Duration{insertTimeout, queryTimeout, aggTimeout} {
+		if *timeout == 0 {
+			*timeout = *opTimeout
+		}
+	}
+	result := newRunSummary(mode)
+
+	if *connectionString == "" && !*dryRun && *sinkName == sink.MongoDB {
 		log.Fatal("Error: --connection is required")
 	}
 
@@ -51,9 +354,306 @@ func main() {
 		log.Fatalf("Error determining document size: %v", err)
 	}
 
+	// An import writes the whole dataset unless --size limits it
+	importAll := *importFrom != "" && !flagSet("size")
+	if importAll {
+		targetBytes = 0
+	}
+
+	// Churn holds the collection at a steady size below the bytes written
+	var churnKeepBytes int64
+	if *churnRate > 0 {
+		churnKeepBytes = targetBytes / 2
+		if *churnKeep != "" {
+			churnKeepBytes, err = parseSize(*churnKeep)
+			if err != nil {
+				log.Fatalf("Error parsing churn keep size: %v", err)
+			}
+		}
+		if churnKeepBytes >= targetBytes {
+			log.Fatal("Error: --churn-keep must be smaller than --size")
+		}
+	}
+
+	var steadyStateMix workload.Mix
+	if *steadyState {
+		steadyStateMix, err = workload.ParseMix(*steadyMix)
+		if err != nil {
+			log.Fatalf("Error parsing steady-state mix: %v", err)
+		}
+		if *steadyRate < 0 || *steadyDuration < 0 {
+			log.Fatal("Error: --steady-state-rate and --steady-state-duration must not be negative")
+		}
+		if *encryptFields != "" {
+			log.Fatal("Error: --steady-state is not supported with --encrypt-fields")
+		}
+	}
+
+	if *collectionCount > 0 && (*verifyLoad || *steadyState || *maintainSize || *churnRate > 0 || *tailChangeStream || *shardKey != "" || *targetMetric != mongo.SizeBytes) {
+		log.Fatal("Error: --collection-count does not support --verify, --steady-state, --maintain-size, --churn-rate, --tail-changestream, --shard-key, or --target-metric")
+	}
+	if *materializeEvery < 0 || (*materializeStage != bench.StageMerge && *materializeStage != bench.StageOut) {
+		log.Fatal("Error: --materialize-interval must not be negative and --materialize-stage must be merge or out")
+	}
+	if (*createViews || *materializeEvery > 0) && (*collectionCount > 0 || *encryptFields != "") {
+		log.Fatal("Error: --create-views and --materialize-interval do not support --collection-count or --encrypt-fields")
+	}
+	if *maintainSize && (*maintainInterval <= 0 || *sizeTolerance < 0 || *sizeTolerance >= 1) {
+		log.Fatal("Error: --maintain-interval must be positive and --maintain-tolerance between 0 and 1")
+	}
+
+	if !mongo.ValidSizeMetric(*targetMetric) {
+		log.Fatalf("Error: invalid target metric: %s", *targetMetric)
+	}
+	if *targetMetric != mongo.SizeBytes {
+		if *churnRate > 0 {
+			log.Fatal("Error: --churn-rate needs --target-metric bytes")
+		}
+		if *sizePollInterval <= 0 {
+			log.Fatal("Error: --size-poll-interval must be positive")
+		}
+	}
+
+	padMode, err := model.ParsePaddingMode(*paddingMode)
+	if err != nil {
+		log.Fatalf("Error parsing padding mode: %v", err)
+	}
+	padSizing, err := model.ParsePaddingSizing(*paddingSizing)
+	if err != nil {
+		log.Fatalf("Error parsing padding sizing: %v", err)
+	}
+
+	docTemplate, err := model.ParseTemplate(*template)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if steadyStateMix[workload.OpPush] > 0 && model.PushField(docTemplate) == "" {
+		log.Fatalf("Error: --steady-state-mix push is not supported by the %s template", docTemplate)
+	}
+	if steadyStateMix[workload.OpLookup] > 0 {
+		log.Fatal("Error: --steady-state-mix does not support lookup")
+	}
+	if *sessions < 0 {
+		log.Fatal("Error: --sessions must be non-negative")
+	}
+	if *sessions > 0 && !*causal {
+		log.Fatal("Error: --sessions needs --causal-consistency")
+	}
+	if *ryowSample != 0 && !*runWorkloadOnly {
+		log.Fatal("Error: --ryow-sample needs --run-workload")
+	}
+	if *readProjection != "full" && !*readOnly && !*runWorkloadOnly {
+		log.Fatal("Error: --read-projection needs --read-only or --run-workload")
+	}
+	scanLen, err := model.ParseCountRange(*scanLength)
+	if err != nil {
+		log.Fatalf("Error parsing --scan-length: %v", err)
+	}
+	if *scanLength != workload.DefaultScanLength.String() && !*steadyState && !*readOnly && !*runWorkloadOnly {
+		log.Fatal("Error: --scan-length needs --steady-state, --read-only, or --run-workload")
+	}
+	var profile *pacing.LoadProfile
+	if *loadProfile != "" {
+		p, err := pacing.ParseLoadProfile(*loadProfile)
+		if err != nil {
+			log.Fatalf("Error parsing --load-profile: %v", err)
+		}
+		if *workloadRate != 0 {
+			log.Fatal("Error: --load-profile replaces --rate; use one of them")
+		}
+		profile = &p
+	}
+	jitter, err := pacing.ParseJitter(*thinkJitter)
+	if err != nil {
+		log.Fatalf("Error parsing --think-jitter: %v", err)
+	}
+	if *opThinkTime < 0 {
+		log.Fatal("Error: --op-think-time must be non-negative")
+	}
+	if *opThinkTime > 0 && !*steadyState && !*readOnly && !*runWorkloadOnly {
+		log.Fatal("Error: --op-think-time needs --steady-state, --read-only, or --run-workload")
+	}
+	clientThink := pacing.ThinkTime{Mean: *thinkTime, Jitter: jitter}
+	opThink := pacing.ThinkTime{Mean: *opThinkTime, Jitter: jitter}
+	if *pushCap < 0 {
+		log.Fatal("Error: --push-cap must not be negative")
+	}
+	if *pushCap > 0 && !*steadyState && !*runWorkloadOnly {
+		log.Fatal("Error: --push-cap needs --steady-state or --run-workload")
+	}
+	if *updateFields != "" {
+		if !*steadyState && !*runWorkloadOnly {
+			log.Fatal("Error: --update-fields needs --steady-state or --run-workload")
+		}
+		if *steadyState {
+			if err := model.ValidateUpdateFields(docTemplate, parseList(*updateFields)); err != nil {
+				log.Fatalf("Error: --update-fields: %v", err)
+			}
+		}
+	}
+	if docTemplate != model.TemplateCustomer {
+		if *checksum {
+			log.Fatal("Error: --checksum is only supported by the customer template")
+		}
+		if *ordersCollection != "" {
+			log.Fatal("Error: --orders-collection is only supported by the customer template")
+		}
+		if *ordersPerCust != "" || *lineItemsPerOrd != "" {
+			log.Fatal("Error: --orders-per-customer and --line-items-per-order are only supported by the customer template")
+		}
+	}
+	orderCount, err := model.ParseCountRange(*ordersPerCust)
+	if err != nil {
+		log.Fatalf("Error parsing --orders-per-customer: %v", err)
+	}
+	lineItemCount, err := model.ParseCountRange(*lineItemsPerOrd)
+	if err != nil {
+		log.Fatalf("Error parsing --line-items-per-order: %v", err)
+	}
+	if *nestingDepth < 0 || *nestingDepth > model.MaxNestingDepth {
+		log.Fatalf("Error: --nesting-depth must be between 0 and %d", model.MaxNestingDepth)
+	}
+	fieldCardinality, err := model.ParseCardinality(*cardinality)
+	if err != nil {
+		log.Fatalf("Error parsing --cardinality: %v", err)
+	}
+	if err := model.ValidateCardinality(docTemplate, fieldCardinality); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if *legacyFraction < 0 || *legacyFraction > 1 {
+		log.Fatalf("Error: --legacy-fraction must be between 0 and 1")
+	}
+	fieldSparsity, err := model.ParseSparsity(*sparsity)
+	if err != nil {
+		log.Fatalf("Error parsing --sparsity: %v", err)
+	}
+	if err := model.ValidateSparsity(docTemplate, fieldSparsity); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	var fieldValues model.FieldValues
+	if *fieldValuesFile != "" {
+		if fieldValues, err = model.ReadFieldValues(*fieldValuesFile); err != nil {
+			log.Fatalf("Error reading --field-values: %v", err)
+		}
+		if err := model.ValidateFieldValues(docTemplate, fieldValues); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+	var fieldTemplates model.FieldTemplates
+	if *derivedFieldFile != "" {
+		data, err := os.ReadFile(*derivedFieldFile)
+		if err != nil {
+			log.Fatalf("Error reading --field-templates: %v", err)
+		}
+		if fieldTemplates, err = model.ParseFieldTemplates(data); err != nil {
+			log.Fatalf("Error parsing --field-templates: %v", err)
+		}
+		if err := model.ValidateFieldTemplates(docTemplate, fieldTemplates); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+	collectionCollation, err := mongo.ParseCollation(*collation)
+	if err != nil {
+		log.Fatalf("Error parsing --collation: %v", err)
+	}
+	nameLocales, err := model.ParseLocales(*localeList)
+	if err != nil {
+		log.Fatalf("Error parsing --locales: %v", err)
+	}
+	if len(nameLocales) == 0 && collectionCollation != nil {
+		locale := model.CollationLocale(collectionCollation.Locale)
+		if err := model.ValidateLocale(locale); err != nil {
+			log.Printf("Warning: names and addresses are generated in %s: %v", model.LocaleDefault, err)
+		} else {
+			nameLocales = []string{locale}
+		}
+	}
+	encryptedFields, err := encryptedFieldTypes(docTemplate, parseList(*encryptFields), fieldSparsity, *legacyFraction)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if *encryptionMode != mongo.EncryptionCSFLE && *encryptionMode != mongo.EncryptionQE {
+		log.Fatalf("Error: invalid --encryption-mode %s (use csfle or qe)", *encryptionMode)
+	}
+	if docTemplate == model.TemplateEvents {
+		if *docSize == "auto" {
+			docSizeKB = model.Size512B
+		}
+	} else if docSizeKB < model.Size2KB {
+		log.Fatalf("Error: --doc-size %s is only supported by the events template", docSizeKB)
+	}
+	collectionShardKey, err := mongo.ParseShardKey(*shardKey)
+	if err != nil {
+		log.Fatalf("Error parsing --shard-key: %v", err)
+	}
+	if *presplitChunks < 0 {
+		log.Fatal("Error: --presplit-chunks must not be negative")
+	}
+	shardURIs, err := mongo.ParseShardMap(*directShards)
+	if err != nil {
+		log.Fatalf("Error parsing --direct-shards: %v", err)
+	}
+	if len(shardURIs) > 0 && !*pauseBalancer {
+		log.Fatal("Error: --direct-shards requires --pause-balancer, so chunks stay on the shards documents are written to")
+	}
+	if *shardStatsEvery < 0 {
+		log.Fatal("Error: --shard-stats-interval must not be negative")
+	}
+	if *oplogStatsEvery < 0 || *maxReplLag < 0 {
+		log.Fatal("Error: --oplog-stats-interval and --max-replication-lag must not be negative")
+	}
+	if *maxReplLag > 0 && *oplogStatsEvery == 0 {
+		*oplogStatsEvery = 10 * time.Second
+	}
+	if *sympathetic && (*healthPoll <= 0 || *maxCacheDirty <= 0 || *minFreeTickets < 0 || *minFreeTickets > 1) {
+		log.Fatal("Error: --sympathetic requires a positive --health-poll-interval and --max-cache-dirty, and --min-free-tickets between 0 and 1")
+	}
+	if *growSteps < 0 {
+		log.Fatal("Error: --grow-steps must not be negative")
+	}
+	if *growSteps > 0 && (*duplicateRatio > 0 || len(encryptedFields) > 0 || len(shardURIs) > 0) {
+		log.Fatal("Error: --grow-steps does not support --duplicate-ratio, --encrypt-fields, or --direct-shards")
+	}
+	readPref, err := mongo.ParseReadPreference(*readPreference)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if !mongo.ValidWriteMode(*writeMode) {
+		log.Fatalf("Error: invalid --write-mode %s (use insertMany, bulkWrite, or insertOne)", *writeMode)
+	}
+	if *presplitChunks > 0 && collectionShardKey.IsZero() {
+		log.Fatal("Error: --presplit-chunks requires --shard-key")
+	}
+	timeSeries := docTemplate == model.TemplateTelemetry || docTemplate == model.TemplateTransaction || docTemplate == model.TemplateEvents
+	if *orderedTimes && timeSeries {
+		log.Fatalf("Error: --ordered-times is not supported by the %s template, its timestamps are always ordered", docTemplate)
+	}
+	readingRange, err := model.ParseTimeRange(*timeRange)
+	if err != nil {
+		log.Fatalf("Error parsing time range: %v", err)
+	}
+	timeSpread, err := model.ParseTimeDistribution(*timeDistribution)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	partition, partitions, err := model.ParseInstance(*instance)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if *keySeed != 0 && *keySpaceFrom != "" {
+		log.Fatal("Error: --key-seed cannot be combined with --key-space-from, which continues the seed of its run")
+	}
+
 	if *verbose {
-		log.Printf("Target size: %s (%d bytes)", *targetSize, targetBytes)
-		log.Printf("Document size: %dKB", docSizeKB/1024)
+		if importAll {
+			log.Printf("Importing all of %s", *importFrom)
+		} else {
+			log.Printf("Target size: %s (%d bytes, %s)", *targetSize, targetBytes, *targetMetric)
+		}
+		log.Printf("Document size: %s (%s template)", docSizeKB, docTemplate)
+		if len(schemaChain) > 0 {
+			log.Printf("Schema: %s", strings.Join(schemaChain, " extends "))
+		}
 	}
 
 	// Auto-tune workers and batch size for performance
@@ -64,11 +664,43 @@ func main() {
 		*writers = runtime.NumCPU()
 	}
 	if *batchSize == 0 {
-		*batchSize = 2000 // Larger batches for better throughput
+		*batchSize = autoBatchSize(docSizeKB)
+	}
+	if *threads == 0 {
+		*threads = runtime.NumCPU() * 4
+	}
+
+	// Batches larger than a message are split by the driver unless bounded
+	var batchLimit int64
+	if *batchBytes != "" {
+		if batchLimit, err = parseSize(*batchBytes); err != nil || batchLimit <= 0 {
+			log.Fatalf("Error: invalid --batch-bytes %q", *batchBytes)
+		}
+	} else if int64(*batchSize)*int64(docSizeKB) > mongo.MaxBatchBytes && *clients == 0 {
+		log.Printf("Warning: batches of %d %s documents exceed the 48MB message limit and are split by the driver; --batch-bytes bounds them", *batchSize, docSizeKB)
 	}
 
 	if *verbose {
 		log.Printf("Workers: %d, Writers: %d, Batch size: %d", *workers, *writers, *batchSize)
+		if profile != nil {
+			log.Printf("Load profile: %s (documents per second)", profile)
+		}
+		if *clients > 0 {
+			log.Printf("Simulated clients: %d, Client batch: %d, Think time: %v", *clients, *clientBatchSize, clientThink)
+		}
+	}
+
+	if *exportSpec != "" {
+		s, err := buildSpec(mode, docSizeKB, targetBytes, churnKeepBytes)
+		if err != nil {
+			log.Fatalf("Error building spec: %v", err)
+		}
+		if err := spec.Write(*exportSpec, s); err != nil {
+			log.Fatalf("Error exporting spec: %v", err)
+		}
+		if *verbose {
+			log.Printf("Exported spec to: %s", *exportSpec)
+		}
 	}
 
 	// Initialize YCSB logger
@@ -77,9 +709,27 @@ func main() {
 		log.Fatalf("Failed to create YCSB logger: %v", err)
 	}
 	defer ycsbLogger.Close()
+	if *hgrmDir != "" {
+		ycsbLogger.SetHistogramDir(*hgrmDir)
+	}
 
-	// Set target bytes for completion estimation
-	ycsbLogger.SetTargetBytes(targetBytes)
+	// On a fatal error or panic, keep the statistics recorded so far. The
+	// report is written last, after the YCSB log records the abort.
+	var series *logger.TimeSeries
+	if *reportFile != "" {
+		onCrash(func(reason string) {
+			result.Error = reason
+			writeReport(*reportFile, result, ycsbLogger, series)
+		})
+	}
+	if *summaryJSON {
+		onCrash(func(reason string) {
+			result.Error = reason
+			result.print()
+		})
+	}
+	onCrash(func(reason string) { ycsbLogger.Abort(reason) })
+	defer recoverCrash()
 
 	if *verbose {
 		log.Printf("YCSB logging to: %s", *logFile)
@@ -92,38 +742,526 @@ func main() {
 	// Start periodic YCSB logging (every 10 seconds)
 	go ycsbLogger.StartPeriodicLogging(ctx)
 
+	// The report charts the time series, kept in memory without a file
+	if *timeSeriesFile != "" || *reportFile != "" {
+		series, err = logger.NewTimeSeries(*timeSeriesFile, time.Now())
+		if err != nil {
+			fatalf("Failed to create time series: %v", err)
+		}
+		if *reportFile != "" {
+			series.Retain()
+		}
+		ycsbLogger.SetTimeSeries(series)
+		go series.Run(ctx)
+	}
+
+	// Gauges are pushed to statsd per interval and once more at close
+	if *statsdAddr != "" {
+		emitter, err := logger.NewStatsdEmitter(*statsdAddr, *statsdPrefix, *statsdInterval)
+		if err != nil {
+			fatalf("Failed to create statsd emitter: %v", err)
+		}
+		ycsbLogger.SetStatsd(emitter)
+		go emitter.Run(ctx)
+	}
+
+	// Latency objectives are checked per interval; enough consecutive
+	// violations abort the run
+	var sloMonitor *logger.SLOMonitor
+	reportSLOs := func(out io.Writer) {
+		if sloMonitor != nil {
+			result.SLOs = sloMonitor.Results()
+			printSLOs(out, result.SLOs)
+		}
+	}
+	if *sloList != "" {
+		slos, err := logger.ParseSLOs(*sloList)
+		if err != nil {
+			fatalf("Error parsing --slo: %v", err)
+		}
+		if *sloInterval <= 0 || *sloAbort < 0 {
+			fatalf("Error: --slo-interval must be positive and --slo-abort not negative")
+		}
+		sloMonitor = logger.NewSLOMonitor(slos, *sloAbort)
+		ycsbLogger.SetSLOMonitor(sloMonitor)
+		go sloMonitor.Run(ctx, *sloInterval, func(reason string) {
+			reportSLOs(console)
+			fatalf("Error: %s", reason)
+		})
+	}
+
 	// Handle signals
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		log.Println("\nShutting down...")
-		cancel()
-	}()
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	drain := newDrainer(*drainTimeout, cancel)
+	go drain.handle(sigChan)
 
-	// Create generator service
-	genService := generator.NewService(generator.Config{
+	if *clean {
+		err := runClean(ctx, cleanConfig{
+			connectionString: *connectionString,
+			databaseName:     *databaseName,
+			collectionName:   *collectionName,
+			runID:            *cleanRun,
+			dropDatabase:     *dropDatabase,
+			confirmed:        *confirm,
+		}, result)
+		if err != nil {
+			fatalf("Clean error: %v", err)
+		}
+		result.Success = true
+		if *summaryJSON {
+			result.print()
+		}
+		return
+	}
+
+	if *verifyChecksums {
+		ok, err := runChecksumVerification(ctx, *connectionString, *databaseName, *collectionName, *checksumSample, result)
+		if err != nil {
+			fatalf("Checksum verification error: %v", err)
+		}
+		result.Success = ok
+		if *summaryJSON {
+			result.print()
+		}
+		if !ok {
+			ycsbLogger.Close()
+			os.Exit(2)
+		}
+		return
+	}
+
+	if *benchAgg {
+		if *benchIterations <= 0 {
+			fatalf("Error: --bench-iterations must be positive")
+		}
+		err := runAggBenchmark(ctx, benchConfig{
+			connectionString: *connectionString,
+			databaseName:     *databaseName,
+			collectionName:   *collectionName,
+			runID:            *keySpaceFrom,
+			queries:          *benchQueries,
+			iterations:       *benchIterations,
+			timeout:          *aggTimeout,
+			readPreference:   readPref,
+			verbose:          *verbose,
+		}, ycsbLogger, result)
+		if err != nil {
+			fatalf("Benchmark error: %v", err)
+		}
+		result.Success = true
+		if *summaryJSON {
+			result.print()
+		}
+		return
+	}
+
+	if *readOnly || *runWorkloadOnly {
+		mix, err := workload.ParseMix(*workloadMix)
+		if err != nil {
+			fatalf("Error parsing workload mix: %v", err)
+		}
+		if *workloadRate < 0 {
+			fatalf("Error: --rate must not be negative")
+		}
+		projection, err := workload.ParseProjection(*readProjection)
+		if err != nil {
+			fatalf("Error parsing --read-projection: %v", err)
+		}
+		var schedule workload.Schedule
+		if *mixSchedule != "" {
+			schedule, err = workload.ParseSchedule(*mixSchedule)
+			if err != nil {
+				fatalf("Error parsing mix schedule: %v", err)
+			}
+		}
+		ops := mix.Ops()
+		if len(schedule) > 0 {
+			ops = schedule.Ops()
+		}
+		if *readOnly {
+			for _, op := range ops {
+				if workload.IsWrite(op) {
+					fatalf("Error: --read-only does not allow %s operations (use --run-workload)", op)
+				}
+			}
+		}
+		ryowPref, err := mongo.SecondaryReadPreference(*ryowStaleness)
+		if err != nil {
+			fatalf("Error: --ryow-max-staleness: %v", err)
+		}
+		if *ryowSample != 0 {
+			if *ryowSample < 0 || *ryowSample > 1 {
+				fatalf("Error: --ryow-sample must be between 0 and 1")
+			}
+			if !slices.Contains(ops, workload.OpInsert) {
+				fatalf("Error: --ryow-sample checks inserts, but the workload mix has none")
+			}
+			if *ryowTimeout <= 0 {
+				fatalf("Error: --ryow-timeout must be positive")
+			}
+		}
+		err = runWorkload(ctx, workloadConfig{
+			connectionString: *connectionString,
+			databaseName:     *databaseName,
+			collectionName:   *collectionName,
+			threads:          *threads,
+			duration:         *duration,
+			mix:              mix,
+			schedule:         schedule,
+			paddingMode:      padMode,
+			insertTimeout:    *insertTimeout,
+			queryTimeout:     *queryTimeout,
+			aggregateTimeout: *aggTimeout,
+			keySpaceFrom:     *keySpaceFrom,
+			touchRate:        *touchRate,
+			updateFields:     parseList(*updateFields),
+			pushCap:          *pushCap,
+			projection:       projection,
+			scanLength:       scanLen,
+			thinkTime:        opThink,
+			ryowSample:       *ryowSample,
+			ryowPref:         ryowPref,
+			ryowTimeout:      *ryowTimeout,
+			textIndex:        *textIndex,
+			searchIndexFile:  *searchIndexFile,
+			searchIndexName:  *searchIndexName,
+			rate:             *workloadRate,
+			profile:          profile,
+			bulkWrite:        *writeMode == mongo.WriteBulkWrite,
+			readPreference:   readPref,
+			causal:           *causal,
+			sessions:         *sessions,
+			verbose:          *verbose,
+		}, ycsbLogger, result)
+		if err != nil {
+			fatalf("Workload error: %v", err)
+		}
+		reportSLOs(console)
+		result.Success = true
+		if *reportFile != "" {
+			writeReport(*reportFile, result, ycsbLogger, series)
+		}
+		if *summaryJSON {
+			result.print()
+		}
+		return
+	}
+
+	// Set target bytes for completion estimation
+	ycsbLogger.SetTargetBytes(targetBytes)
+
+	// Server-side metrics stop the load from the size watch instead of the
+	// marshaled bytes
+	loadLimit := targetBytes
+	if *targetMetric != mongo.SizeBytes || importAll {
+		loadLimit = math.MaxInt64
+	}
+
+	// Continue an earlier run's key space so incremental loads don't overlap it
+	var keySpace model.KeySpace
+	if *keySpaceFrom != "" {
+		meta, err := loadRunMetadata(*connectionString, *databaseName, *collectionName, *keySpaceFrom)
+		if err != nil {
+			fatalf("Failed to load key space: %v", err)
+		}
+		if meta.KeySpace.IsZero() {
+			fatalf("Error: run %s did not record a key space", meta.RunID)
+		}
+		keySpace = meta.KeySpace.Continue()
+		if *verbose {
+			log.Printf("Continuing key space of run %s at key %d (seed %d)", meta.RunID, keySpace.First, keySpace.Seed)
+		}
+	}
+	if *keySeed != 0 {
+		keySpace = model.KeySpace{Seed: *keySeed}
+	}
+
+	// Instances of a distributed load share the seed and split its sequence
+	if partitions > 0 {
+		if keySpace.Seed == 0 {
+			fatalf("Error: --instance needs the --key-seed or --key-space-from all instances share")
+		}
+		keySpace = keySpace.Partition(partition, partitions)
+		if *verbose {
+			log.Printf("Instance %d of %d: keys from %d (seed %d)", partition, partitions, keySpace.First, keySpace.Seed)
+		}
+	}
+
+	// Instances started together by an orchestrator need run IDs of their own
+	runID := mongo.NewRunID()
+	if partitions > 0 {
+		runID = mongo.NewRunID(fmt.Sprintf("%dof%d", partition, partitions))
+	}
+
+	genConfig := generator.Config{
 		DocumentSize: docSizeKB,
 		WorkerCount:  *workers,
 		BatchSize:    *batchSize,
-		TargetBytes:  targetBytes,
-	})
+		Writers:      *writers,
+		TargetBytes:  loadLimit,
+		PaddingMode:  padMode,
+		KeySpace:     keySpace,
+		Checksum:     *checksum,
+		BSONTypes:    *bsonTypes,
+		Orders:       orderCount,
+		LineItems:    lineItemCount,
+		NestingDepth: *nestingDepth,
+		Cardinality:  fieldCardinality,
+		Sparsity:     fieldSparsity,
 
-	// Create MongoDB writer
-	mongoWriter, err := mongo.NewWriter(mongo.Config{
+		FieldValues:    fieldValues,
+		FieldTemplates: fieldTemplates,
+		LegacyFraction: *legacyFraction,
+		Locales:        nameLocales,
+		NoPII:          *noPII,
+		Template:       docTemplate,
+		TimeRange:      readingRange,
+		OrderedTimes:   *orderedTimes,
+
+		TimeDistribution:  timeSpread,
+		CoherentAddresses: *coherentAddr,
+		PaddingSizing:     padSizing,
+
+		ExactSize:  *exactSize || *strictSize,
+		StrictSize: *strictSize,
+
+		// Spreads telemetry readings, transactions, events, and ordered
+		// created_at across the time range
+		ExpectedDocuments: targetBytes / int64(docSizeKB),
+
+		BufferDocs:     *bufferDocs,
+		AdaptiveBuffer: *adaptiveBuffer,
+	}
+	writerConfig := mongo.Config{
 		ConnectionString: *connectionString,
 		DatabaseName:     *databaseName,
 		CollectionName:   *collectionName,
 		BatchSize:        *batchSize,
+		BatchBytes:       batchLimit,
 		WriterCount:      *writers,
-		TargetBytes:      targetBytes,
+		TargetBytes:      loadLimit,
 		YCSBLogger:       ycsbLogger,
-	})
+		Clients:          *clients,
+		ClientBatchSize:  *clientBatchSize,
+		ThinkTime:        clientThink,
+		OrdersCollection: *ordersCollection,
+		GrowthSteps:      *growSteps,
+		InsertTimeout:    *insertTimeout,
+		MaxRetries:       *maxRetries,
+		RetryBackoff:     *retryBackoff,
+		ExactlyOnce:      *exactlyOnce,
+		DuplicateRatio:   *duplicateRatio,
+		DuplicateMode:    *duplicateMode,
+		ConnectionMode:   *connectionMode,
+		MaxPoolSize:      *maxPoolSize,
+		MinPoolSize:      *minPoolSize,
+		WriteMode:        *writeMode,
+		Ordered:          *ordered,
+		Clustered:        *clustered,
+		Collation:        collectionCollation,
+		DirectShards:     shardURIs,
+		Chaos: mongo.ChaosConfig{
+			DelayRatio:     *chaosDelay,
+			MaxDelay:       *chaosMaxDelay,
+			DuplicateRatio: *chaosDuplicate,
+			FailRatio:      *chaosFail,
+			ResetRatio:     *chaosReset,
+			DropRatio:      *chaosDrop,
+		},
+		OversizeRatio:  *oversizeRatio,
+		MarshalWorkers: *marshalWorkers,
+		Profile:        profile,
+		Namespaces: mongo.NamespaceConfig{
+			Count:        *collectionCount,
+			Template:     *collectionTmpl,
+			Distribution: *collectionDist,
+		},
+	}
+	if *autoTune {
+		writerConfig.AutoTune = &mongo.AutoTuneConfig{Burst: *tuneBurst, MaxLatency: *tuneLatency}
+		if writerConfig.AutoTune.Writers, err = parseCounts(*tuneWriters); err != nil {
+			fatalf("Error parsing --auto-tune-writers: %v", err)
+		}
+		if writerConfig.AutoTune.BatchSizes, err = parseCounts(*tuneBatches); err != nil {
+			fatalf("Error parsing --auto-tune-batches: %v", err)
+		}
+	}
+
+	// Several applications at once: each pipeline adapts the configurations
+	if len(pipelines) > 0 {
+		err := runPipelines(ctx, pipelines, genConfig, writerConfig, pipelineOptions{
+			logFile:   *logFile,
+			tagRun:    *tagRun,
+			tagFields: parseList(*tagFields),
+			verbose:   *verbose,
+		}, drain, console, result)
+		if err != nil {
+			fatalf("Pipeline error: %v", err)
+		}
+		result.Success = true
+		if *summaryJSON {
+			result.print()
+		}
+		return
+	}
+
+	if *importFrom != "" {
+		source, err := dataset.Open(*importFrom)
+		if err != nil {
+			fatalf("Error: %v", err)
+		}
+		defer source.Close()
+		genConfig.Source = source
+	}
+
+	// Create generator service
+	genService := generator.NewService(genConfig)
+	processors, tagged, err := loadProcessors(runID, parseList(*tenants), *tagRun, parseList(*tagFields))
+	if err != nil {
+		fatalf("Error: %v", err)
+	}
+	genService.Use(processors...)
+
+	if *dryRun {
+		if err := runDryRun(ctx, genService, *writers, result); err != nil {
+			fatalf("Dry run error: %v", err)
+		}
+		result.Success = true
+		if *summaryJSON {
+			result.print()
+		}
+		return
+	}
+
+	// Documents go to a sink other than MongoDB, e.g. a file
+	if *sinkName != sink.MongoDB {
+		to, err := sink.New(*sinkName, sink.Config{Target: *sinkTarget})
+		if err != nil {
+			fatalf("Error: %v", err)
+		}
+		result.RunID = runID
+		if err := runSinkLoad(ctx, genService, to, ycsbLogger, drain, result); err != nil {
+			fatalf("Sink error: %v", err)
+		}
+		reportSLOs(console)
+		result.Success = true
+		if *reportFile != "" {
+			writeReport(*reportFile, result, ycsbLogger, series)
+		}
+		if *summaryJSON {
+			result.print()
+		}
+		return
+	}
+
+	// Queries of the views and summary collections
+	var viewQueries []bench.Query
+	if *createViews || *materializeEvery > 0 {
+		viewQueries, err = bench.Queries(genService.Schema(), *ordersCollection, parseList(*benchQueries))
+		if err != nil {
+			fatalf("Error: %v", err)
+		}
+	}
+
+	encryption, err := encryptionConfig(encryptedFields, *encryptionMode, *encryptEquality, *kmsProvider, *kmsKeyFile, *kmsMasterKey, *cryptSharedLib)
+	if err != nil {
+		fatalf("Error: %v", err)
+	}
+
+	// Create MongoDB writer
+	writerConfig.GrowthFields = model.GrowthFields(genService.Schema())
+	if *verifyLoad {
+		writerConfig.KeyField = genService.Schema().KeyField
+		writerConfig.KeySample = verify.DefaultKeyProbes
+	}
+	writerConfig.Encryption = encryption
+	mongoWriter, err := mongo.NewWriter(writerConfig)
 	if err != nil {
-		log.Fatalf("Failed to create MongoDB writer: %v", err)
+		fatalf("Failed to create MongoDB writer: %v", err)
 	}
 	defer mongoWriter.Close()
 
+	restoreBalancer := prepareSharding(mongoWriter, genService.Schema(), collectionShardKey, *presplitChunks, *pauseBalancer, *verbose)
+
+	// Record the run so later read-only runs can discover the schema
+	runMeta := &mongo.RunMetadata{
+		RunID:        runID,
+		Schema:       genService.Schema(),
+		DocumentSize: docSizeKB,
+		KeySpace:     genService.KeySpace(),
+		TargetBytes:  targetBytes,
+		StartedAt:    time.Now(),
+		Tagged:       tagged,
+	}
+	if err := mongoWriter.SaveRunMetadata(runMeta); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	log.Printf("Run ID: %s", runMeta.RunID)
+
+	// On abnormal termination, report and record what was written so far
+	onCrash(func(reason string) {
+		var report bytes.Buffer
+		fmt.Fprintf(&report, "\n\nRun terminated abnormally: %s", reason)
+		printFinalStats(io.MultiWriter(console, &report), genService, mongoWriter)
+		writePartialReport(filepath.Join(*artifactDir, runMeta.RunID), report.Bytes())
+
+		result.RunID = runMeta.RunID
+		result.setLoad(genService, mongoWriter)
+
+		runMeta.Aborted = reason
+		if err := mongoWriter.SaveRunMetadata(runMeta); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	})
+
+	// Count existing documents so verification knows what to expect
+	var countBefore int64
+	if *verifyLoad {
+		countBefore, err = mongoWriter.CountDocuments()
+		if err != nil {
+			fatalf("Error: %v", err)
+		}
+	}
+
+	// Open the change stream before the first insert
+	if *tailChangeStream {
+		if err := mongoWriter.StartChangeStream(ctx); err != nil {
+			fatalf("Failed to tail change stream: %v", err)
+		}
+	}
+
+	if *churnRate > 0 {
+		mongoWriter.StartChurn(ctx, *churnRate, churnKeepBytes)
+	}
+
+	if *targetMetric != mongo.SizeBytes {
+		mongoWriter.StartSizeWatch(ctx, *targetMetric, targetBytes, *sizePollInterval, func() {
+			if *verbose {
+				log.Printf("Collection %s reached the target", *targetMetric)
+			}
+			genService.Stop()
+		})
+	}
+
+	if *shardStatsEvery > 0 {
+		mongoWriter.StartShardWatch(ctx, *shardStatsEvery)
+	}
+
+	if *oplogStatsEvery > 0 {
+		mongoWriter.StartOplogWatch(ctx, *oplogStatsEvery, *maxReplLag)
+	}
+
+	if *sympathetic {
+		mongoWriter.StartHealthWatch(ctx, *healthPoll, mongo.HealthLimits{
+			MaxCacheDirty:  *maxCacheDirty,
+			MinFreeTickets: *minFreeTickets,
+		})
+	}
+
+	// A shutdown signal now stops generation and drains the buffer
+	drain.startLoad(genService.Stop)
+
 	// Start progress reporter
 	progressDone := make(chan bool)
 	go reportProgress(ctx, genService, mongoWriter, progressDone)
@@ -141,28 +1279,262 @@ func main() {
 	}()
 
 	// Wait for completion or error
+	writersDone := false
 	select {
 	case err := <-genErrChan:
 		if err != nil && err != context.Canceled {
-			log.Fatalf("Generation error: %v", err)
+			fatalf("Generation error: %v", err)
+		}
+		// Let the writer drain the documents still queued
+		select {
+		case err := <-writeErrChan:
+			writersDone = true
+			if err != nil && ctx.Err() == nil {
+				fatalf("Write error: %v", err)
+			}
+		case <-ctx.Done():
 		}
 	case err := <-writeErrChan:
-		if err != nil && err != context.Canceled {
-			log.Fatalf("Write error: %v", err)
+		writersDone = true
+		if err != nil && ctx.Err() == nil {
+			fatalf("Write error: %v", err)
 		}
 	case <-ctx.Done():
 		// Shutdown requested
 	}
+	drain.finish()
+	restoreBalancer()
 
-	// Wait a bit for progress reporter to finish
-	time.Sleep(500 * time.Millisecond)
+	// After cancellation, give writers a moment to account for their batches
+	if !writersDone {
+		select {
+		case <-writeErrChan:
+		case <-time.After(5 * time.Second):
+		}
+	}
 	close(progressDone)
+	drained := drain.summary(genService, mongoWriter)
+	if drained != nil {
+		runMeta.Aborted = "interrupted by signal"
+	}
+
+	if *targetMetric != mongo.SizeBytes {
+		sizeCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if _, err := mongoWriter.RefreshCollectionSize(sizeCtx, *targetMetric); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		cancel()
+	}
+
+	finishedAt := time.Now()
+	runMeta.FinishedAt = &finishedAt
+	runMeta.KeySpace = genService.KeySpace()
+	if err := mongoWriter.SaveRunMetadata(runMeta); err != nil {
+		log.Printf("Warning: %v", err)
+	}
 
 	// Print final stats
-	printFinalStats(genService, mongoWriter)
+	var summary bytes.Buffer
+	printFinalStats(io.MultiWriter(console, &summary), genService, mongoWriter)
+	if drained != nil {
+		drained.print(io.MultiWriter(console, &summary))
+	}
+	result.RunID = runMeta.RunID
+	result.setLoad(genService, mongoWriter)
+	result.Drain = drained
+
+	verified := true
+	if *verifyLoad {
+		verified = verifyLoadResult(mongoWriter, runMeta, countBefore, *churnRate > 0 || *duplicateRatio > 0 || *oversizeRatio > 0 || *chaosDrop > 0,
+			*pushCap, io.MultiWriter(console, &summary), result)
+	}
+
+	// Keep the collection at its target size, alongside the steady state
+	var maintenance *maintainer
+	if *maintainSize && drained == nil && ctx.Err() == nil {
+		maintenance = startMaintenance(ctx, mongoWriter, genService, mongo.MaintainConfig{
+			Metric:    *targetMetric,
+			Target:    targetBytes,
+			Tolerance: *sizeTolerance,
+			Interval:  *maintainInterval,
+		}, *steadyState)
+	}
+
+	// Create views over the loaded documents and keep refreshing summary
+	// collections, alongside the steady state
+	var materialization *materializer
+	if len(viewQueries) > 0 && drained == nil && ctx.Err() == nil {
+		result.Load.Materialize = &materializeSummary{}
+		if *createViews {
+			views, err := bench.CreateViews(ctx, mongoWriter.Collection(), genService.Schema(), *ordersCollection, viewQueries)
+			if err != nil {
+				fatalf("Error: %v", err)
+			}
+			fmt.Fprintf(io.MultiWriter(console, &summary), "Views: %s\n", strings.Join(views, ", "))
+			result.Load.Materialize.Views = views
+		}
+		if *materializeEvery > 0 {
+			materialization = startMaterialization(ctx, bench.MaterializeConfig{
+				Collection: mongoWriter.Collection(),
+				Schema:     genService.Schema(),
+				LookupFrom: *ordersCollection,
+				Queries:    viewQueries,
+				Stage:      *materializeStage,
+				Interval:   *materializeEvery,
+				Timeout:    *aggTimeout,
+				YCSBLogger: ycsbLogger,
+			}, *steadyState)
+		}
+	}
+
+	// Keep mutating the loaded documents, unless the load was interrupted
+	if *steadyState && drained == nil && ctx.Err() == nil {
+		stats, err := runSteadyState(ctx, mongoWriter, runMeta, genService.Generator(), steadyStateConfig{
+			threads:       *threads,
+			duration:      *steadyDuration,
+			rate:          *steadyRate,
+			mix:           steadyStateMix,
+			insertTimeout: *insertTimeout,
+			queryTimeout:  *queryTimeout,
+			aggTimeout:    *aggTimeout,
+			bulkWrite:     *writeMode == mongo.WriteBulkWrite,
+			updateFields:  parseList(*updateFields),
+			pushCap:       *pushCap,
+			scanLength:    scanLen,
+			thinkTime:     opThink,
+		}, ycsbLogger, io.MultiWriter(console, &summary))
+		result.setWorkload(stats)
+		if err != nil {
+			fatalf("Steady state error: %v", err)
+		}
+		if *verifyLoad && steadyStateMix[workload.OpPush] > 0 {
+			reportArraySizes(mongoWriter, runMeta, *pushCap, io.MultiWriter(console, &summary), result)
+		}
+	}
+	if maintenance != nil {
+		stats, err := maintenance.wait(io.MultiWriter(console, &summary))
+		result.Load.Maintenance = &stats
+		if err != nil {
+			fatalf("Size maintenance error: %v", err)
+		}
+	}
+	if materialization != nil {
+		results, err := materialization.wait(io.MultiWriter(console, &summary))
+		result.Load.Materialize.Stage = *materializeStage
+		result.Load.Materialize.Refreshes = querySummaries(results)
+		if err != nil {
+			fatalf("Materialization error: %v", err)
+		}
+	}
+
+	reportSLOs(io.MultiWriter(console, &summary))
+
+	if *collectDiag {
+		collectDiagnostics(mongoWriter.Client(), filepath.Join(*artifactDir, runMeta.RunID), diagnostics.AtlasConfig{
+			PublicKey:  *atlasPublicKey,
+			PrivateKey: *atlasPrivateKey,
+			GroupID:    *atlasGroupID,
+			Hosts:      parseList(*atlasHosts),
+		}, runMeta.StartedAt, finishedAt)
+	}
+
+	result.Success = verified
+	if *reportFile != "" {
+		writeReport(*reportFile, result, ycsbLogger, series)
+	}
+
+	if *bundle || *bundleS3 != "" {
+		// Flush the YCSB log's final statistics before copying it
+		ycsbLogger.Close()
+		files := []string{*logFile}
+		if *exportSpec != "" {
+			files = append(files, *exportSpec)
+		}
+		if *timeSeriesFile != "" {
+			files = append(files, *timeSeriesFile)
+		}
+		if *reportFile != "" {
+			files = append(files, *reportFile)
+		}
+		files = append(files, ycsbLogger.HistogramFiles()...)
+		bundleRun(filepath.Join(*artifactDir, runMeta.RunID), runMeta, summary.Bytes(), files, *bundleS3)
+	}
+
+	if *summaryJSON {
+		result.print()
+	}
+	if !verified {
+		ycsbLogger.Close()
+		mongoWriter.Close()
+		os.Exit(2)
+	}
+}
+
+// printSLOs writes the compliance of latency objectives
+func printSLOs(out io.Writer, results []logger.SLOResult) {
+	fmt.Fprintf(out, "\n=== SLOs ===\n")
+	for _, r := range results {
+		fmt.Fprintf(out, "%s: met in %.1f%% of %d intervals (worst %.1fms)\n", r.SLO, r.CompliancePercent, r.Intervals, r.WorstMs)
+	}
+}
+
+// loadProcessors returns the post-processors stamping the documents of run
+// runID with tenants and, with tagRun, the tag fields, and whether documents
+// are tagged with the run ID
+func loadProcessors(runID string, tenants []string, tagRun bool, tagFields []string) ([]generator.PostProcessor, bool, error) {
+	var processors []generator.PostProcessor
+	if len(tenants) > 0 {
+		processors = append(processors, generator.TenantID(tenants))
+	}
+	var tagged bool
+	if tagRun {
+		for _, field := range tagFields {
+			switch field {
+			case "run_id":
+				processors = append(processors, generator.RunTag(runID))
+				tagged = true
+			case "generated_at":
+				processors = append(processors, generator.GeneratedAt())
+			default:
+				return nil, false, fmt.Errorf("unknown tag field %q (use run_id or generated_at)", field)
+			}
+		}
+	}
+	return processors, tagged, nil
+}
+
+// namedFile returns the file of a pipeline or phase: path with the name
+// appended to its base name (ycsb.log becomes ycsb-orders.log)
+func namedFile(path, name string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + name + ext
+}
+
+// parseList splits a comma-separated list, dropping empty entries
+func parseList(list string) []string {
+	var result []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
 }
 
 // parseSize parses size strings like "1TB", "500GB", etc.
+// parseCounts parses a comma-separated list of positive numbers
+func parseCounts(list string) ([]int, error) {
+	var counts []int
+	for _, item := range parseList(list) {
+		n, err := strconv.Atoi(item)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid count: %s", item)
+		}
+		counts = append(counts, n)
+	}
+	return counts, nil
+}
+
 func parseSize(sizeStr string) (int64, error) {
 	sizeStr = strings.ToUpper(strings.TrimSpace(sizeStr))
 
@@ -192,11 +1564,26 @@ func parseSize(sizeStr string) (int64, error) {
 	return int64(value * float64(multiplier)), nil
 }
 
+// maxAutoBatchBytes bounds the bytes of an automatically sized batch, which
+// 2000 documents of 64KB fill
+const maxAutoBatchBytes = 128 * 1024 * 1024
+
+// autoBatchSize returns the batch size for documents of docSize: 2000 for
+// better throughput, fewer for documents larger than 64KB so that batches
+// and the buffer of twice a batch do not hold gigabytes
+func autoBatchSize(docSize model.DocumentSize) int {
+	return max(1, min(2000, maxAutoBatchBytes/int(docSize)))
+}
+
 // determineDocumentSize determines the appropriate document size
 func determineDocumentSize(docSizeStr string, targetBytes int64) (model.DocumentSize, error) {
 	if docSizeStr != "auto" {
 		// Parse explicit size
 		switch strings.ToUpper(docSizeStr) {
+		case "512B":
+			return model.Size512B, nil
+		case "1KB":
+			return model.Size1KB, nil
 		case "2KB":
 			return model.Size2KB, nil
 		case "4KB":
@@ -209,6 +1596,14 @@ func determineDocumentSize(docSizeStr string, targetBytes int64) (model.Document
 			return model.Size32KB, nil
 		case "64KB":
 			return model.Size64KB, nil
+		case "256KB":
+			return model.Size256KB, nil
+		case "1MB":
+			return model.Size1MB, nil
+		case "4MB":
+			return model.Size4MB, nil
+		case "16MB":
+			return model.Size16MB, nil
 		default:
 			return 0, fmt.Errorf("invalid document size: %s", docSizeStr)
 		}
@@ -216,7 +1611,9 @@ func determineDocumentSize(docSizeStr string, targetBytes int64) (model.Document
 
 	// Auto-select based on target size
 	// Scale document size down a notch: use smaller documents for better granularity
-	if targetBytes >= 8*1024*1024*1024*1024 { // >= 8TB
+	if targetBytes >= 32*1024*1024*1024*1024 { // >= 32TB
+		return model.Size256KB, nil
+	} else if targetBytes >= 8*1024*1024*1024*1024 { // >= 8TB
 		return model.Size64KB, nil
 	} else if targetBytes >= 4*1024*1024*1024*1024 { // >= 4TB
 		return model.Size32KB, nil
@@ -249,37 +1646,144 @@ func reportProgress(ctx context.Context, genService *generator.Service, mongoWri
 			genMBps := genStats.BytesPerSecond / (1024 * 1024)
 			writeMBps := writeStats.BytesPerSecond / (1024 * 1024)
 
-			fmt.Printf("\r[Gen: %d docs, %.2f MB/s] [Write: %d docs, %.2f MB/s] [Total: %.2f GB]",
+			fmt.Fprintf(console, "\r[Gen: %d docs, %.2f MB/s] [Buffer: %d/%d] [Write: %d docs, %.2f MB/s] [Total: %.2f GB]",
 				genStats.DocumentsGenerated,
 				genMBps,
+				genStats.BufferDepth,
+				genStats.BufferCapacity,
 				writeStats.DocumentsWritten,
 				writeMBps,
 				float64(writeStats.BytesWritten)/(1024*1024*1024),
 			)
+			if writeStats.CollectionSize > 0 {
+				fmt.Fprintf(console, " [Collection: %.2f GB]", float64(writeStats.CollectionSize)/(1024*1024*1024))
+			}
+			if len(writeStats.Shards) > 0 {
+				fmt.Fprintf(console, " [Shards:")
+				for _, shard := range writeStats.Shards {
+					fmt.Fprintf(console, " %s %d docs %.0f/s", shard.Shard, shard.Documents, shard.DocumentsPerSecond)
+				}
+				fmt.Fprintf(console, "]")
+			}
+			if oplog := writeStats.Oplog; oplog != nil {
+				fmt.Fprintf(console, " [Oplog: window %v, lag %v]", oplog.Window.Round(time.Second), oplog.Lag.Round(time.Second))
+			}
+			if writeStats.Throttle.Paused {
+				fmt.Fprintf(console, " [Inserts paused]")
+			} else if delay := writeStats.Throttle.Delay; delay > 0 {
+				fmt.Fprintf(console, " [Backoff: %v/batch]", delay)
+			}
 			os.Stdout.Sync()
 		}
 	}
 }
 
-// printFinalStats prints final statistics
-func printFinalStats(genService *generator.Service, mongoWriter *mongo.Writer) {
+// printOversized warns on out about documents whose content exceeded
+// --doc-size with --exact-size
+func printOversized(out io.Writer, genStats generator.Stats) {
+	if genStats.OversizedDocuments > 0 {
+		fmt.Fprintf(out, "Warning: %d documents exceeded --doc-size before padding (their content alone is larger)\n", genStats.OversizedDocuments)
+	}
+}
+
+// printFinalStats writes final statistics to out
+func printFinalStats(out io.Writer, genService *generator.Service, mongoWriter *mongo.Writer) {
 	genStats := genService.GetStats()
 	writeStats := mongoWriter.GetStats()
 
 	elapsed := writeStats.LastUpdate.Sub(writeStats.StartTime)
 
-	fmt.Printf("\n\n=== Final Statistics ===\n")
-	fmt.Printf("Total time: %v\n", elapsed.Round(time.Second))
-	fmt.Printf("Documents generated: %d\n", genStats.DocumentsGenerated)
-	fmt.Printf("Documents written: %d\n", writeStats.DocumentsWritten)
-	fmt.Printf("Bytes written: %.2f GB\n", float64(writeStats.BytesWritten)/(1024*1024*1024))
-	fmt.Printf("Average generation rate: %.2f docs/sec, %.2f MB/s\n",
+	fmt.Fprintf(out, "\n\n=== Final Statistics ===\n")
+	fmt.Fprintf(out, "Total time: %v\n", elapsed.Round(time.Second))
+	fmt.Fprintf(out, "Documents generated: %d\n", genStats.DocumentsGenerated)
+	fmt.Fprintf(out, "Documents written: %d\n", writeStats.DocumentsWritten)
+	fmt.Fprintf(out, "Bytes written: %.2f GB\n", float64(writeStats.BytesWritten)/(1024*1024*1024))
+	if writeStats.CollectionSize > 0 {
+		fmt.Fprintf(out, "Collection size (server-reported): %.2f GB\n", float64(writeStats.CollectionSize)/(1024*1024*1024))
+	}
+	for _, shard := range writeStats.Shards {
+		fmt.Fprintf(out, "Shard %s: %d documents, %.2f GB\n", shard.Shard, shard.Documents, float64(shard.Bytes)/(1024*1024*1024))
+	}
+	if oplog := writeStats.Oplog; oplog != nil {
+		fmt.Fprintf(out, "Oplog window: %v, replication lag: %v (max %v)\n",
+			oplog.Window.Round(time.Second), oplog.Lag.Round(time.Second), oplog.MaxLag.Round(time.Second))
+	}
+	if throttle := writeStats.Throttle; throttle.Pauses > 0 || throttle.Backoffs > 0 {
+		fmt.Fprintf(out, "Inserts throttled: %d pauses, %d backoffs, writers waited %v\n",
+			throttle.Pauses, throttle.Backoffs, throttle.Wait.Round(time.Millisecond))
+	}
+	if writeStats.Batches > 0 {
+		fmt.Fprintf(out, "Insert batches: %d (avg %.0f documents, %.2f MB)\n", writeStats.Batches,
+			float64(writeStats.DocumentsWritten)/float64(writeStats.Batches), float64(writeStats.BytesWritten)/float64(writeStats.Batches)/(1024*1024))
+	}
+	if writeStats.Timeouts > 0 {
+		fmt.Fprintf(out, "Documents timed out: %d\n", writeStats.Timeouts)
+	}
+	printOversized(out, genStats)
+	if writeStats.OrdersWritten > 0 {
+		fmt.Fprintf(out, "Referenced orders written: %d\n", writeStats.OrdersWritten)
+	}
+	if writeStats.GrowthUpdates > 0 {
+		fmt.Fprintf(out, "Growth updates: %d\n", writeStats.GrowthUpdates)
+	}
+	fmt.Fprintf(out, "Average generation rate: %.2f docs/sec, %.2f MB/s\n",
 		genStats.DocumentsPerSecond,
 		genStats.BytesPerSecond/(1024*1024),
 	)
-	fmt.Printf("Average write rate: %.2f docs/sec, %.2f MB/s\n",
+	fmt.Fprintf(out, "Average write rate: %.2f docs/sec, %.2f MB/s\n",
 		writeStats.DocumentsPerSecond,
 		writeStats.BytesPerSecond/(1024*1024),
 	)
-	fmt.Printf("Throughput: %.2f GB/min\n", float64(writeStats.BytesWritten)/(1024*1024*1024)/elapsed.Minutes())
+	fmt.Fprintf(out, "Buffer: peak %d of %d documents", genStats.PeakBufferDepth, genStats.BufferCapacity)
+	if genStats.ThrottledTime > 0 {
+		fmt.Fprintf(out, ", workers throttled for %v", genStats.ThrottledTime.Round(time.Millisecond))
+	}
+	fmt.Fprintf(out, "\n")
+	fmt.Fprintf(out, "Throughput: %.2f GB/min\n", float64(writeStats.BytesWritten)/(1024*1024*1024)/elapsed.Minutes())
+	fmt.Fprintf(out, "Retries: %d (%v in retries/backoff)\n", writeStats.Retries, writeStats.RetryTime.Round(time.Millisecond))
+	fmt.Fprintf(out, "Retry overhead: %.2f%%\n", writeStats.RetryOverheadPercent())
+	if writeStats.Reconciled > 0 {
+		fmt.Fprintf(out, "Retried documents already inserted: %d\n", writeStats.Reconciled)
+	}
+	if tune := writeStats.AutoTune; tune != nil {
+		fmt.Fprintf(out, "Auto-tune: %d writers, batches of %d (best of %d bursts)\n", tune.Writers, tune.BatchSize, len(tune.Bursts))
+	}
+	if m := writeStats.Marshal; m != nil {
+		fmt.Fprintf(out, "Marshal pool: %d workers, %.0f docs/sec capacity, %v blocked on inserts\n",
+			m.MarshalWorkers, m.MarshalCapacity(), m.MarshalBlocked.Round(time.Millisecond))
+		fmt.Fprintf(out, "Insert pool: %d writers, %.0f docs/sec capacity, %v starved of documents\n",
+			m.InsertWorkers, m.InsertCapacity(writeStats.DocumentsWritten), m.InsertStarved.Round(time.Millisecond))
+		fmt.Fprintf(out, "Bottleneck: %s\n", m.Bottleneck(writeStats.DocumentsWritten))
+	}
+	if spread := writeStats.Namespaces; spread.Collections > 0 {
+		fmt.Fprintf(out, "Collections: %d of %d written, hottest %s with %.1f%% of the documents\n",
+			spread.Written, spread.Collections, spread.HottestName, spread.HottestShare*100)
+	}
+	if pool := writeStats.Pool; pool.Checkouts > 0 {
+		fmt.Fprintf(out, "Connection checkouts: %d (avg wait %v, max wait %v)\n",
+			pool.Checkouts, pool.AverageWait().Round(time.Microsecond), pool.MaxWait.Round(time.Microsecond))
+	}
+	if faults := writeStats.InjectedFaults; faults != (mongo.ChaosStats{}) {
+		fmt.Fprintf(out, "Injected faults: %d delayed, %d duplicated, %d failed, %d reset batch attempts\n",
+			faults.Delays, faults.Duplicates, faults.Failures, faults.Resets)
+		if faults.Drops > 0 {
+			fmt.Fprintf(out, "Dropped batches: %d (%d documents)\n", faults.Drops, faults.Dropped)
+		}
+	}
+	if writeStats.DocumentsDiscarded > 0 {
+		fmt.Fprintf(out, "Documents discarded at target: %d\n", writeStats.DocumentsDiscarded)
+	}
+	if writeStats.DocumentsDeleted > 0 {
+		fmt.Fprintf(out, "Documents deleted by churn: %d\n", writeStats.DocumentsDeleted)
+	}
+	if writeStats.DuplicatesRejected > 0 || writeStats.Upserts > 0 {
+		fmt.Fprintf(out, "Duplicates: %d rejected, %d upserted\n", writeStats.DuplicatesRejected, writeStats.Upserts)
+	}
+	if jumbo := writeStats.Oversize; jumbo.Oversized > 0 || jumbo.NearLimit > 0 || len(jumbo.Errors) > 0 {
+		fmt.Fprintf(out, "Jumbo documents: %d over the limit attempted (%d accepted), %d at the limit written\n",
+			jumbo.Oversized, jumbo.Accepted, jumbo.NearLimit)
+		for _, name := range slices.Sorted(maps.Keys(jumbo.Errors)) {
+			fmt.Fprintf(out, "  %s: %d\n", name, jumbo.Errors[name])
+		}
+	}
 }
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/generator"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/mongo"
+)
+
+// maintainer runs --maintain-size in the background
+type maintainer struct {
+	cancel context.CancelFunc
+	until  bool // Runs until interrupted rather than until stopped
+	done   chan struct{}
+	start  time.Time
+
+	stats mongo.MaintainStats
+	err   error
+}
+
+// startMaintenance keeps the loaded collection at its target size with
+// documents of genService. With a steady state, it stops when wait is
+// called; without one, it runs until ctx is cancelled.
+func startMaintenance(ctx context.Context, writer *mongo.Writer, genService *generator.Service, config mongo.MaintainConfig, steadyState bool) *maintainer {
+	if steadyState {
+		log.Printf("Maintaining the collection at %d bytes during the steady state", config.Target)
+	} else {
+		log.Printf("Maintaining the collection at %d bytes until interrupted", config.Target)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	m := &maintainer{cancel: cancel, until: !steadyState, done: make(chan struct{}), start: time.Now()}
+	go func() {
+		defer close(m.done)
+		m.stats, m.err = writer.Maintain(ctx, config, genService.Next)
+	}()
+	return m
+}
+
+// wait stops the maintenance, or waits for it to be interrupted, and prints
+// its statistics to out
+func (m *maintainer) wait(out io.Writer) (mongo.MaintainStats, error) {
+	if !m.until {
+		m.cancel()
+	}
+	<-m.done
+	m.cancel()
+
+	fmt.Fprintf(out, "\n=== Size Maintenance ===\n")
+	fmt.Fprintf(out, "Total time: %v\n", time.Since(m.start).Round(time.Second))
+	fmt.Fprintf(out, "Checks: %d (last size %d bytes)\n", m.stats.Checks, m.stats.LastSize)
+	fmt.Fprintf(out, "Top-ups: %d (%d documents added)\n", m.stats.TopUps, m.stats.DocumentsAdded)
+	fmt.Fprintf(out, "Trims: %d (%d documents deleted)\n", m.stats.Trims, m.stats.DocumentsTrimmed)
+
+	if m.err == context.Canceled {
+		return m.stats, nil
+	}
+	return m.stats, m.err
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/internal/bench"
+)
+
+// materializer runs --materialize-interval in the background
+type materializer struct {
+	cancel context.CancelFunc
+	until  bool // Runs until interrupted rather than until stopped
+	done   chan struct{}
+	start  time.Time
+	stage  string
+
+	results []bench.Result
+	err     error
+}
+
+// startMaterialization refreshes the summary collections of config's queries
+// every interval. With a steady state, it stops when wait is called; without
+// one, it runs until ctx is cancelled.
+func startMaterialization(ctx context.Context, config bench.MaterializeConfig, steadyState bool) *materializer {
+	until := "until interrupted"
+	if steadyState {
+		until = "during the steady state"
+	}
+	log.Printf("Refreshing %d summary collections with $%s every %v %s", len(config.Queries), config.Stage, config.Interval, until)
+
+	ctx, cancel := context.WithCancel(ctx)
+	m := &materializer{cancel: cancel, until: !steadyState, done: make(chan struct{}), start: time.Now(), stage: config.Stage}
+	go func() {
+		defer close(m.done)
+		m.results, m.err = bench.Materialize(ctx, config)
+	}()
+	return m
+}
+
+// wait stops the refreshes, or waits for them to be interrupted, and prints
+// their latencies to out
+func (m *materializer) wait(out io.Writer) ([]bench.Result, error) {
+	if !m.until {
+		m.cancel()
+	}
+	<-m.done
+	m.cancel()
+
+	fmt.Fprintf(out, "\n=== Materialized Views ($%s) ===\n", m.stage)
+	fmt.Fprintf(out, "Total time: %v\n", time.Since(m.start).Round(time.Second))
+	bench.Print(out, m.results)
+
+	if m.err == context.Canceled {
+		return m.results, nil
+	}
+	return m.results, m.err
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/generator"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/bench"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/logger"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/mongo"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/verify"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/workload"
+)
+
+// console receives all interactive output (progress, statistics, reports);
+// --quiet discards it so that stdout carries only the --summary-json object
+var console io.Writer = os.Stdout
+
+// errorLog reports fatal errors; unlike the standard logger it is not
+// silenced by --quiet
+var errorLog = log.New(os.Stderr, "", log.LstdFlags)
+
+// runSummary is the machine-readable result printed by --summary-json
+type runSummary struct {
+	Mode            string  `json:"mode"` // load, workload, dry-run, verify-checksums, clean, bench-agg, or scenario
+	RunID           string  `json:"run_id,omitempty"`
+	Success         bool    `json:"success"`
+	Error           string  `json:"error,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds"`
+
+	Load         *loadSummary         `json:"load,omitempty"`
+	Workload     *workloadSummary     `json:"workload,omitempty"`
+	DryRun       *dryRunSummary       `json:"dry_run,omitempty"`
+	Verification *verificationSummary `json:"verification,omitempty"`
+	Checksums    *checksumSummary     `json:"checksums,omitempty"`
+	Clean        *cleanSummary        `json:"clean,omitempty"`
+	Benchmark    *benchSummary        `json:"benchmark,omitempty"`
+	Drain        *drainSummary        `json:"drain,omitempty"` // Only when stopped by a signal
+	Pipelines    []pipelineSummary    `json:"pipelines,omitempty"`
+	Phases       []phaseSummary       `json:"phases,omitempty"` // Scenario phases that ran
+	SLOs         []logger.SLOResult   `json:"slos,omitempty"`   // With --slo
+
+	start   time.Time
+	printed bool
+}
+
+type loadSummary struct {
+	DocumentsGenerated   int64   `json:"documents_generated"`
+	BufferCapacity       int     `json:"buffer_capacity"`
+	PeakBufferDepth      int     `json:"peak_buffer_depth"`
+	ThrottledSeconds     float64 `json:"throttled_seconds"`
+	OversizedDocuments   int64   `json:"oversized_documents,omitempty"` // Content larger than --doc-size with --exact-size
+	DocumentsWritten     int64   `json:"documents_written"`
+	BytesWritten         int64   `json:"bytes_written"`
+	Batches              int64   `json:"batches"`
+	CollectionSize       int64   `json:"collection_size,omitempty"` // Server-reported size with --target-metric
+	DocumentsPerSecond   float64 `json:"documents_per_second"`
+	BytesPerSecond       float64 `json:"bytes_per_second"`
+	OrdersWritten        int64   `json:"orders_written"`
+	GrowthUpdates        int64   `json:"growth_updates"`
+	Timeouts             int64   `json:"timeouts"`
+	Retries              int64   `json:"retries"`
+	Reconciled           int64   `json:"reconciled"` // Retried documents found inserted by a failed attempt
+	RetryOverheadPercent float64 `json:"retry_overhead_percent"`
+	PoolCheckouts        int64   `json:"pool_checkouts"`
+	PoolWaitSeconds      float64 `json:"pool_wait_seconds"`
+	PoolMaxWaitSeconds   float64 `json:"pool_max_wait_seconds"`
+	DocumentsDeleted     int64   `json:"documents_deleted"`
+	DocumentsDiscarded   int64   `json:"documents_discarded"`
+	DuplicatesRejected   int64   `json:"duplicates_rejected"`
+	Upserts              int64   `json:"upserts"`
+	InjectedDelays       int64   `json:"injected_delays"`
+	InjectedDuplicates   int64   `json:"injected_duplicates"`
+	InjectedFailures     int64   `json:"injected_failures"`
+	InjectedResets       int64   `json:"injected_resets"`
+	InjectedDrops        int64   `json:"injected_drops"`
+	DroppedDocuments     int64   `json:"dropped_documents"` // Documents of dropped batches
+	InsertPauses         int64   `json:"insert_pauses"`
+	InsertBackoffs       int64   `json:"insert_backoffs"`
+	InsertPausedSeconds  float64 `json:"insert_paused_seconds"` // Summed over writers
+
+	OversizeAttempts   int64            `json:"oversize_attempts,omitempty"` // Documents over the BSON limit sent with --oversize-ratio
+	OversizeAccepted   int64            `json:"oversize_accepted,omitempty"` // Of those, stored by the server
+	NearLimitDocuments int64            `json:"near_limit_documents,omitempty"`
+	OversizeErrors     map[string]int64 `json:"oversize_errors,omitempty"` // Jumbo document failures by kind and error
+
+	OplogWindowSeconds       float64 `json:"oplog_window_seconds,omitempty"`
+	MaxReplicationLagSeconds float64 `json:"max_replication_lag_seconds,omitempty"`
+
+	Shards []mongo.ShardStats `json:"shards,omitempty"` // Last polled distribution across shards
+
+	Maintenance *mongo.MaintainStats `json:"maintenance,omitempty"` // With --maintain-size
+	Collections *mongo.SpreadStats   `json:"collections,omitempty"` // With --collection-count
+	AutoTune    *mongo.TuneResult    `json:"auto_tune,omitempty"`   // With --auto-tune
+	Marshal     *marshalSummary      `json:"marshal,omitempty"`     // With --marshal-workers
+
+	Materialize *materializeSummary `json:"materialize,omitempty"` // With --create-views or --materialize-interval
+}
+
+// marshalSummary compares the marshal and insert worker pools of a load
+// with --marshal-workers
+type marshalSummary struct {
+	MarshalWorkers        int     `json:"marshal_workers"`
+	InsertWorkers         int     `json:"insert_workers"`
+	DocumentsMarshaled    int64   `json:"documents_marshaled"`
+	MarshalDocsPerSecond  float64 `json:"marshal_docs_per_second"` // Capacity: documents per busy second, times workers
+	InsertDocsPerSecond   float64 `json:"insert_docs_per_second"`
+	MarshalBlockedSeconds float64 `json:"marshal_blocked_seconds"` // Summed over marshal workers
+	InsertStarvedSeconds  float64 `json:"insert_starved_seconds"`  // Summed over insert workers
+	Bottleneck            string  `json:"bottleneck"`              // marshal or insert
+}
+
+// materializeSummary lists the views over a load and the refreshes of its
+// summary collections
+type materializeSummary struct {
+	Views     []string            `json:"views,omitempty"`
+	Stage     string              `json:"stage,omitempty"`
+	Refreshes []benchQuerySummary `json:"refreshes,omitempty"` // Per query
+}
+
+type workloadSummary struct {
+	Operations       int64   `json:"operations"`
+	FailedOperations int64   `json:"failed_operations"`
+	TimedOut         int64   `json:"timed_out"`
+	Touched          int64   `json:"touched"`
+	EmptySearches    int64   `json:"empty_searches,omitempty"`
+	SessionWaits     int64   `json:"session_waits,omitempty"` // Operations that waited for a free --sessions session
+	OpsPerSecond     float64 `json:"ops_per_second"`
+
+	RYOW *ryowSummary `json:"ryow,omitempty"` // With --ryow-sample
+}
+
+type ryowSummary struct {
+	Checks     int64   `json:"checks"`
+	Stale      int64   `json:"stale"` // Not visible at the first read
+	StaleRatio float64 `json:"stale_ratio"`
+	Invisible  int64   `json:"invisible"` // Not visible within --ryow-timeout
+	Failed     int64   `json:"failed"`
+	Skipped    int64   `json:"skipped"`
+}
+
+type dryRunSummary struct {
+	DocumentsGenerated int64   `json:"documents_generated"`
+	BytesEncoded       int64   `json:"bytes_encoded"`
+	DocumentsPerSecond float64 `json:"documents_per_second"`
+	BytesPerSecond     float64 `json:"bytes_per_second"`
+}
+
+type verificationSummary struct {
+	OK            bool     `json:"ok"`
+	ExpectedCount int64    `json:"expected_count"`
+	ActualCount   int64    `json:"actual_count"`
+	Instance      int      `json:"instance,omitempty"` // Of a distributed load, where the count is a lower bound
+	Instances     int      `json:"instances,omitempty"`
+	AverageSize   float64  `json:"average_size"`
+	Discrepancies []string `json:"discrepancies,omitempty"`
+
+	// Sizes of the array pushes grow, after the load and after --steady-state
+	ArraySizes            *arraySizesSummary `json:"array_sizes,omitempty"`
+	SteadyStateArraySizes *arraySizesSummary `json:"steady_state_array_sizes,omitempty"`
+}
+
+type arraySizesSummary struct {
+	Field   string  `json:"field"`
+	Sampled int     `json:"sampled"`
+	Min     int     `json:"min"`
+	Max     int     `json:"max"`
+	Mean    float64 `json:"mean"`
+	Cap     int     `json:"cap"`
+	AtCap   int     `json:"at_cap"`
+}
+
+// newArraySizesSummary records sampled array sizes
+func newArraySizesSummary(sizes *verify.ArraySizes) *arraySizesSummary {
+	return &arraySizesSummary{
+		Field:   sizes.Field,
+		Sampled: sizes.Sampled,
+		Min:     sizes.Min,
+		Max:     sizes.Max,
+		Mean:    sizes.Mean,
+		Cap:     sizes.Cap,
+		AtCap:   sizes.AtCap,
+	}
+}
+
+// pipelineSummary is the result of one pipeline of a multi-pipeline load
+type pipelineSummary struct {
+	Name      string        `json:"name"`
+	Namespace string        `json:"namespace"`
+	RunID     string        `json:"run_id"`
+	Load      *loadSummary  `json:"load"`
+	Drain     *drainSummary `json:"drain,omitempty"`
+}
+
+type checksumSummary struct {
+	OK         bool  `json:"ok"`
+	Checked    int64 `json:"checked"`
+	Mismatched int64 `json:"mismatched"`
+	Missing    int64 `json:"missing"`
+}
+
+type cleanSummary struct {
+	Executed           bool     `json:"executed"` // False when only reporting what would be removed
+	DroppedDatabase    string   `json:"dropped_database,omitempty"`
+	DroppedCollections []string `json:"dropped_collections,omitempty"`
+	DocumentsDeleted   int64    `json:"documents_deleted"`
+	OrdersDeleted      int64    `json:"orders_deleted"`
+	RunsRemoved        int64    `json:"runs_removed"`
+}
+
+type benchSummary struct {
+	Queries []benchQuerySummary `json:"queries"`
+}
+
+type benchQuerySummary struct {
+	Name      string  `json:"name"`
+	Runs      int     `json:"runs"`
+	Errors    int     `json:"errors"`
+	Documents int64   `json:"documents"`
+	MinMs     float64 `json:"min_ms"`
+	MeanMs    float64 `json:"mean_ms"`
+	P50Ms     float64 `json:"p50_ms"`
+	P95Ms     float64 `json:"p95_ms"`
+	MaxMs     float64 `json:"max_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// newRunSummary starts the summary of a run in the given mode
+func newRunSummary(mode string) *runSummary {
+	return &runSummary{Mode: mode, start: time.Now()}
+}
+
+// setLoad records the generator and writer statistics
+func (s *runSummary) setLoad(genService *generator.Service, mongoWriter *mongo.Writer) {
+	s.Load = newLoadSummary(genService, mongoWriter)
+}
+
+// setPipelines records the statistics of each pipeline and how it was
+// drained (nil entries, or drained nil, when no signal was received)
+func (s *runSummary) setPipelines(pipelines []*pipeline, drained []*drainSummary) {
+	s.Pipelines = make([]pipelineSummary, len(pipelines))
+	for i, p := range pipelines {
+		s.Pipelines[i] = pipelineSummary{
+			Name:      p.name,
+			Namespace: p.namespace,
+			RunID:     p.runMeta.RunID,
+			Load:      newLoadSummary(p.genService, p.writer),
+		}
+		if drained != nil {
+			s.Pipelines[i].Drain = drained[i]
+		}
+	}
+}
+
+// newLoadSummary summarizes the generator and writer statistics of a load
+func newLoadSummary(genService *generator.Service, mongoWriter *mongo.Writer) *loadSummary {
+	genStats := genService.GetStats()
+	writeStats := mongoWriter.GetStats()
+	load := &loadSummary{
+		DocumentsGenerated:   genStats.DocumentsGenerated,
+		BufferCapacity:       genStats.BufferCapacity,
+		Batches:              writeStats.Batches,
+		PeakBufferDepth:      genStats.PeakBufferDepth,
+		ThrottledSeconds:     genStats.ThrottledTime.Seconds(),
+		OversizedDocuments:   genStats.OversizedDocuments,
+		DocumentsWritten:     writeStats.DocumentsWritten,
+		BytesWritten:         writeStats.BytesWritten,
+		CollectionSize:       writeStats.CollectionSize,
+		DocumentsPerSecond:   writeStats.DocumentsPerSecond,
+		BytesPerSecond:       writeStats.BytesPerSecond,
+		OrdersWritten:        writeStats.OrdersWritten,
+		GrowthUpdates:        writeStats.GrowthUpdates,
+		Timeouts:             writeStats.Timeouts,
+		Retries:              writeStats.Retries,
+		Reconciled:           writeStats.Reconciled,
+		RetryOverheadPercent: writeStats.RetryOverheadPercent(),
+		PoolCheckouts:        writeStats.Pool.Checkouts,
+		PoolWaitSeconds:      writeStats.Pool.TotalWait.Seconds(),
+		PoolMaxWaitSeconds:   writeStats.Pool.MaxWait.Seconds(),
+		DocumentsDeleted:     writeStats.DocumentsDeleted,
+		DocumentsDiscarded:   writeStats.DocumentsDiscarded,
+		DuplicatesRejected:   writeStats.DuplicatesRejected,
+		Upserts:              writeStats.Upserts,
+		InjectedDelays:       writeStats.InjectedFaults.Delays,
+		InjectedDuplicates:   writeStats.InjectedFaults.Duplicates,
+		InjectedFailures:     writeStats.InjectedFaults.Failures,
+		InjectedResets:       writeStats.InjectedFaults.Resets,
+		InjectedDrops:        writeStats.InjectedFaults.Drops,
+		DroppedDocuments:     writeStats.InjectedFaults.Dropped,
+		InsertPauses:         writeStats.Throttle.Pauses,
+		InsertBackoffs:       writeStats.Throttle.Backoffs,
+		InsertPausedSeconds:  writeStats.Throttle.Wait.Seconds(),
+		Shards:               writeStats.Shards,
+
+		OversizeAttempts:   writeStats.Oversize.Oversized,
+		OversizeAccepted:   writeStats.Oversize.Accepted,
+		NearLimitDocuments: writeStats.Oversize.NearLimit,
+		OversizeErrors:     writeStats.Oversize.Errors,
+	}
+	if spread := writeStats.Namespaces; spread.Collections > 0 {
+		load.Collections = &spread
+	}
+	load.AutoTune = writeStats.AutoTune
+	if m := writeStats.Marshal; m != nil {
+		load.Marshal = &marshalSummary{
+			MarshalWorkers:        m.MarshalWorkers,
+			InsertWorkers:         m.InsertWorkers,
+			DocumentsMarshaled:    m.DocumentsMarshaled,
+			MarshalDocsPerSecond:  m.MarshalCapacity(),
+			InsertDocsPerSecond:   m.InsertCapacity(writeStats.DocumentsWritten),
+			MarshalBlockedSeconds: m.MarshalBlocked.Seconds(),
+			InsertStarvedSeconds:  m.InsertStarved.Seconds(),
+			Bottleneck:            m.Bottleneck(writeStats.DocumentsWritten),
+		}
+	}
+	if oplog := writeStats.Oplog; oplog != nil {
+		load.OplogWindowSeconds = oplog.Window.Seconds()
+		load.MaxReplicationLagSeconds = oplog.MaxLag.Seconds()
+	}
+	return load
+}
+
+// setWorkload records the workload runner statistics
+func (s *runSummary) setWorkload(stats workload.Stats) {
+	s.Workload = &workloadSummary{
+		Operations:       stats.Operations,
+		FailedOperations: stats.FailedOperations,
+		TimedOut:         stats.TimedOut,
+		Touched:          stats.Touched,
+		EmptySearches:    stats.EmptySearches,
+		SessionWaits:     stats.SessionWaits,
+		OpsPerSecond:     stats.OpsPerSecond,
+	}
+	if r := stats.RYOW; r.Checks > 0 || r.Failed > 0 || r.Skipped > 0 {
+		s.Workload.RYOW = &ryowSummary{
+			Checks:     r.Checks,
+			Stale:      r.Stale,
+			StaleRatio: r.StaleRatio(),
+			Invisible:  r.Invisible,
+			Failed:     r.Failed,
+			Skipped:    r.Skipped,
+		}
+	}
+}
+
+// setVerification records a post-load verification report
+func (s *runSummary) setVerification(report *verify.Report) {
+	s.Verification = &verificationSummary{
+		OK:            report.OK(),
+		ExpectedCount: report.ExpectedCount,
+		ActualCount:   report.ActualCount,
+		Instance:      report.Instance,
+		Instances:     report.Instances,
+		AverageSize:   report.AverageSize,
+		Discrepancies: report.Discrepancies,
+	}
+	if report.ArraySizes != nil && report.ArraySizes.Sampled > 0 {
+		s.Verification.ArraySizes = newArraySizesSummary(report.ArraySizes)
+	}
+}
+
+// setChecksums records a checksum verification report
+func (s *runSummary) setChecksums(report *verify.ChecksumReport) {
+	s.Checksums = &checksumSummary{
+		OK:         report.OK(),
+		Checked:    report.Checked,
+		Mismatched: report.Mismatched,
+		Missing:    report.Missing,
+	}
+}
+
+// setBenchmark records the results of an aggregation benchmark of a run
+func (s *runSummary) setBenchmark(runID string, results []bench.Result) {
+	s.RunID = runID
+	s.Benchmark = &benchSummary{Queries: querySummaries(results)}
+}
+
+// querySummaries returns the summaries of the results of aggregation queries
+func querySummaries(results []bench.Result) []benchQuerySummary {
+	summaries := []benchQuerySummary{}
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	for _, r := range results {
+		summaries = append(summaries, benchQuerySummary{
+			Name:      r.Name,
+			Runs:      r.Runs,
+			Errors:    r.Errors,
+			Documents: r.Documents,
+			MinMs:     ms(r.Min),
+			MeanMs:    ms(r.Mean),
+			P50Ms:     ms(r.P50),
+			P95Ms:     ms(r.P95),
+			MaxMs:     ms(r.Max),
+			Error:     r.Error,
+		})
+	}
+	return summaries
+}
+
+// add records what a cleanup removed
+func (s *cleanSummary) add(result *mongo.CleanResult) {
+	s.DroppedCollections = append(s.DroppedCollections, result.DroppedCollections...)
+	s.DocumentsDeleted += result.DocumentsDeleted
+	s.OrdersDeleted += result.OrdersDeleted
+	s.RunsRemoved += result.RunsRemoved
+}
+
+// print writes the summary as a single JSON object to stdout
+func (s *runSummary) print() {
+	if s.printed {
+		return
+	}
+	s.printed = true
+	s.DurationSeconds = time.Since(s.start).Seconds()
+	json.NewEncoder(os.Stdout).Encode(s)
+}
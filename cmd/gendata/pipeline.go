@@ -0,0 +1,391 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/generator"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/config"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/logger"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/mongo"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/pacing"
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"golang.org/x/sync/errgroup"
+)
+
+// pipelineFlags are the flags each pipeline of a multi-pipeline load may set;
+// all other flags apply to every pipeline
+var pipelineFlags = []string{
+	"database", "collection", "schema", "template", "size", "doc-size", "padding-mode", "tenants",
+	"workers", "writers", "batch-size", "buffer-docs", "write-mode", "ordered",
+	"clients", "client-batch", "think-time", "think-jitter", "insert-timeout",
+}
+
+// pipelineUnsupported are the load flags that need the single-pipeline load
+// and are rejected with pipelines unless left at their defaults
+var pipelineUnsupported = []string{
+	"spec", "export-spec", "verify", "steady-state", "update-fields", "push-cap", "scan-length", "op-think-time", "load-profile", "maintain-size", "create-views", "materialize-interval", "churn-rate", "collection-count", "target-metric", "tail-changestream",
+	"shard-key", "presplit-chunks", "pause-balancer", "direct-shards", "key-space-from", "key-seed", "instance", "encrypt-fields",
+	"oplog-stats-interval", "max-replication-lag", "sympathetic", "auto-tune",
+	"timeseries-file", "hgrm-dir", "slo", "statsd-addr", "report", "from", "sink", "sink-target", "bundle", "bundle-s3", "collect-diagnostics",
+}
+
+// checkUnsupported fails if one of the flags was set to other than its
+// default, naming the feature it is not supported with
+func checkUnsupported(names []string, feature string) error {
+	for _, name := range names {
+		if f := flag.Lookup(name); f.Value.String() != f.DefValue {
+			return fmt.Errorf("--%s is not supported with %s", name, feature)
+		}
+	}
+	return nil
+}
+
+// pipelineOptions are the settings shared by all pipelines that are not part
+// of the generator and writer configurations
+type pipelineOptions struct {
+	logFile   string
+	tagRun    bool
+	tagFields []string
+	verbose   bool
+}
+
+// pipeline is one generator-to-writer pipeline of a multi-pipeline load,
+// modelling one application: it has its own generator service, writers,
+// target namespace, run, and YCSB log
+type pipeline struct {
+	name       string
+	namespace  string
+	genService *generator.Service
+	writer     *mongo.Writer
+	ycsbLogger *logger.YCSBLogger
+	runMeta    *mongo.RunMetadata
+}
+
+// pipelineFlagSet returns a flag set of the pipelineFlags, defaulting to the
+// resolved global values, with the pipeline's values applied
+func pipelineFlagSet(p config.Pipeline) (*flag.FlagSet, error) {
+	fs := flag.NewFlagSet(p.Name, flag.ContinueOnError)
+	for _, name := range pipelineFlags {
+		usage := flag.Lookup(name).Usage
+		switch value := flagValue(name).(type) {
+		case string:
+			fs.String(name, value, usage)
+		case int:
+			fs.Int(name, value, usage)
+		case bool:
+			fs.Bool(name, value, usage)
+		case time.Duration:
+			fs.Duration(name, value, usage)
+		}
+	}
+	if err := config.Apply(fs, p.Values); err != nil {
+		return nil, fmt.Errorf("pipeline %s: %w", p.Name, err)
+	}
+
+	// A schema of the pipeline's own fills what the pipeline leaves unset
+	if _, ok := p.Values["schema"]; ok {
+		if err := applyPipelineSchema(fs, flagString("schema-dir"), fs.Lookup("schema").Value.String()); err != nil {
+			return nil, fmt.Errorf("pipeline %s: %w", p.Name, err)
+		}
+	}
+	return fs, nil
+}
+
+// newPipeline creates the generator service, writer, and YCSB logger of a
+// pipeline from the shared configurations and the pipeline's flag values,
+// and records its run
+func newPipeline(p config.Pipeline, genConfig generator.Config, writerConfig mongo.Config, options pipelineOptions) (*pipeline, error) {
+	fs, err := pipelineFlagSet(p)
+	if err != nil {
+		return nil, err
+	}
+	get := func(name string) interface{} { return fs.Lookup(name).Value.(flag.Getter).Get() }
+	fail := func(format string, args ...interface{}) (*pipeline, error) {
+		return nil, fmt.Errorf("pipeline %s: %s", p.Name, fmt.Sprintf(format, args...))
+	}
+
+	template, err := model.ParseTemplate(get("template").(string))
+	if err != nil {
+		return fail("%v", err)
+	}
+	if template != model.TemplateCustomer && (genConfig.Checksum || writerConfig.OrdersCollection != "" ||
+		!genConfig.Orders.IsZero() || !genConfig.LineItems.IsZero()) {
+		return fail("--checksum, --orders-collection, --orders-per-customer, and --line-items-per-order are only supported by the customer template")
+	}
+	timeSeries := template == model.TemplateTelemetry || template == model.TemplateTransaction || template == model.TemplateEvents
+	if genConfig.OrderedTimes && timeSeries {
+		return fail("--ordered-times is not supported by the %s template", template)
+	}
+	if err := model.ValidateCardinality(template, genConfig.Cardinality); err != nil {
+		return fail("%v", err)
+	}
+	if err := model.ValidateSparsity(template, genConfig.Sparsity); err != nil {
+		return fail("%v", err)
+	}
+	if err := model.ValidateFieldValues(template, genConfig.FieldValues); err != nil {
+		return fail("%v", err)
+	}
+	if err := model.ValidateFieldTemplates(template, genConfig.FieldTemplates); err != nil {
+		return fail("%v", err)
+	}
+
+	targetBytes, err := parseSize(get("size").(string))
+	if err != nil {
+		return fail("%v", err)
+	}
+	docSize, err := determineDocumentSize(get("doc-size").(string), targetBytes)
+	if err != nil {
+		return fail("%v", err)
+	}
+	if template == model.TemplateEvents {
+		if get("doc-size") == "auto" {
+			docSize = model.Size512B
+		}
+	} else if docSize < model.Size2KB {
+		return fail("--doc-size %s is only supported by the events template", docSize)
+	}
+	padMode, err := model.ParsePaddingMode(get("padding-mode").(string))
+	if err != nil {
+		return fail("%v", err)
+	}
+	jitter, err := pacing.ParseJitter(get("think-jitter").(string))
+	if err != nil {
+		return fail("%v", err)
+	}
+	writeMode := get("write-mode").(string)
+	if !mongo.ValidWriteMode(writeMode) {
+		return fail("invalid --write-mode %s (use insertMany, bulkWrite, or insertOne)", writeMode)
+	}
+
+	workers, writers, batchSize := get("workers").(int), get("writers").(int), get("batch-size").(int)
+	if workers == 0 {
+		workers = runtime.NumCPU() * 2
+	}
+	if writers == 0 {
+		writers = runtime.NumCPU()
+	}
+	if batchSize == 0 {
+		batchSize = autoBatchSize(docSize)
+	}
+
+	runID := mongo.NewRunID(p.Name)
+	processors, tagged, err := loadProcessors(runID, parseList(get("tenants").(string)), options.tagRun, options.tagFields)
+	if err != nil {
+		return fail("%v", err)
+	}
+
+	genConfig.Template = template
+	genConfig.DocumentSize = docSize
+	genConfig.PaddingMode = padMode
+	genConfig.WorkerCount = workers
+	genConfig.BatchSize = batchSize
+	genConfig.BufferDocs = get("buffer-docs").(int)
+	genConfig.TargetBytes = targetBytes
+	genConfig.ExpectedDocuments = targetBytes / int64(docSize)
+	genConfig.PostProcessors = processors
+	genService := generator.NewService(genConfig)
+
+	ycsbLogger, err := logger.NewYCSBLogger(namedFile(options.logFile, p.Name))
+	if err != nil {
+		return fail("failed to create YCSB logger: %v", err)
+	}
+	ycsbLogger.SetTargetBytes(targetBytes)
+
+	writerConfig.DatabaseName = get("database").(string)
+	writerConfig.CollectionName = get("collection").(string)
+	writerConfig.BatchSize = batchSize
+	writerConfig.WriterCount = writers
+	writerConfig.TargetBytes = targetBytes
+	writerConfig.YCSBLogger = ycsbLogger
+	writerConfig.WriteMode = writeMode
+	writerConfig.Ordered = get("ordered").(bool)
+	writerConfig.Clients = get("clients").(int)
+	writerConfig.ClientBatchSize = get("client-batch").(int)
+	writerConfig.ThinkTime = pacing.ThinkTime{Mean: get("think-time").(time.Duration), Jitter: jitter}
+	writerConfig.InsertTimeout = get("insert-timeout").(time.Duration)
+	writerConfig.GrowthFields = model.GrowthFields(genService.Schema())
+	writer, err := mongo.NewWriter(writerConfig)
+	if err != nil {
+		ycsbLogger.Close()
+		return fail("failed to create MongoDB writer: %v", err)
+	}
+
+	runMeta := &mongo.RunMetadata{
+		RunID:        runID,
+		Schema:       genService.Schema(),
+		DocumentSize: docSize,
+		KeySpace:     genService.KeySpace(),
+		TargetBytes:  targetBytes,
+		StartedAt:    time.Now(),
+		Tagged:       tagged,
+	}
+	if err := writer.SaveRunMetadata(runMeta); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	if options.verbose {
+		log.Printf("Pipeline %s: %s of %s documents (%s template) into %s.%s, %d workers, %d writers",
+			p.Name, get("size"), docSize, template, writerConfig.DatabaseName, writerConfig.CollectionName, workers, writers)
+	}
+
+	return &pipeline{
+		name:       p.Name,
+		namespace:  writerConfig.DatabaseName + "." + writerConfig.CollectionName,
+		genService: genService,
+		writer:     writer,
+		ycsbLogger: ycsbLogger,
+		runMeta:    runMeta,
+	}, nil
+}
+
+// close flushes the pipeline's YCSB log and disconnects its writer
+func (p *pipeline) close() {
+	p.ycsbLogger.Close()
+	p.writer.Close()
+}
+
+// run generates and writes the pipeline's documents until its target is
+// reached, generation is stopped, or ctx ends
+func (p *pipeline) run(ctx context.Context) error {
+	genCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	genErr := make(chan error, 1)
+	go func() {
+		genErr <- p.genService.Generate(genCtx)
+	}()
+
+	err := p.writer.Write(ctx, p.genService.Documents())
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("pipeline %s: write error: %w", p.name, err)
+	}
+
+	// Writers also return once the target is claimed, before generation ends
+	stop()
+	if err := <-genErr; err != nil && err != context.Canceled {
+		return fmt.Errorf("pipeline %s: generation error: %w", p.name, err)
+	}
+	return nil
+}
+
+// runPipelines runs the pipelines of a multi-pipeline load concurrently,
+// each loading its own namespace, and reports their statistics
+func runPipelines(ctx context.Context, pipelines []config.Pipeline, genConfig generator.Config, writerConfig mongo.Config,
+	options pipelineOptions, drain *drainer, out io.Writer, result *runSummary) error {
+	// Check the namespaces before any pipeline touches the cluster
+	namespaces := make(map[string]string)
+	for _, spec := range pipelines {
+		fs, err := pipelineFlagSet(spec)
+		if err != nil {
+			return err
+		}
+		namespace := fs.Lookup("database").Value.String() + "." + fs.Lookup("collection").Value.String()
+		if other, ok := namespaces[namespace]; ok {
+			return fmt.Errorf("pipelines %s and %s both write to %s", other, spec.Name, namespace)
+		}
+		namespaces[namespace] = spec.Name
+	}
+
+	var running []*pipeline
+	defer func() {
+		for _, p := range running {
+			p.close()
+		}
+	}()
+	for _, spec := range pipelines {
+		p, err := newPipeline(spec, genConfig, writerConfig, options)
+		if err != nil {
+			return err
+		}
+		running = append(running, p)
+		log.Printf("Pipeline %s: run ID %s, writing to %s", p.name, p.runMeta.RunID, p.namespace)
+	}
+
+	// On abnormal termination, record what each pipeline wrote so far
+	onCrash(func(reason string) {
+		for _, p := range running {
+			printFinalStats(out, p.genService, p.writer)
+			p.runMeta.Aborted = reason
+			if err := p.writer.SaveRunMetadata(p.runMeta); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+		}
+		result.setPipelines(running, nil)
+	})
+
+	for _, p := range running {
+		go p.ycsbLogger.StartPeriodicLogging(ctx)
+	}
+
+	// A shutdown signal stops generation in every pipeline and drains them
+	drain.startLoad(func() {
+		for _, p := range running {
+			p.genService.Stop()
+		}
+	})
+
+	progressDone := make(chan bool)
+	go reportPipelineProgress(ctx, running, progressDone)
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	for _, p := range running {
+		p := p
+		eg.Go(func() error { return p.run(egCtx) })
+	}
+	err := eg.Wait()
+	drain.finish()
+	close(progressDone)
+	if err != nil {
+		return err
+	}
+
+	drained := make([]*drainSummary, len(running))
+	finishedAt := time.Now()
+	for i, p := range running {
+		drained[i] = drain.summary(p.genService, p.writer)
+		if drained[i] != nil {
+			p.runMeta.Aborted = "interrupted by signal"
+		}
+		p.runMeta.FinishedAt = &finishedAt
+		p.runMeta.KeySpace = p.genService.KeySpace()
+		if err := p.writer.SaveRunMetadata(p.runMeta); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+
+		fmt.Fprintf(out, "\n\n=== Pipeline %s (%s, run %s) ===", p.name, p.namespace, p.runMeta.RunID)
+		printFinalStats(out, p.genService, p.writer)
+		if drained[i] != nil {
+			drained[i].print(out)
+		}
+	}
+	result.setPipelines(running, drained)
+	return nil
+}
+
+// reportPipelineProgress periodically reports the progress of every pipeline
+func reportPipelineProgress(ctx context.Context, pipelines []*pipeline, done chan bool) {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+			return
+		case <-ticker.C:
+			parts := make([]string, len(pipelines))
+			for i, p := range pipelines {
+				stats := p.writer.GetStats()
+				parts[i] = fmt.Sprintf("[%s: %d docs, %.2f MB/s, %.2f GB]", p.name,
+					stats.DocumentsWritten, stats.BytesPerSecond/(1024*1024), float64(stats.BytesWritten)/(1024*1024*1024))
+			}
+			fmt.Fprintf(console, "\r%s", strings.Join(parts, " "))
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/internal/logger"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/report"
+)
+
+// writeReport writes the --report file of a run: its summary, the latencies
+// the YCSB logger recorded, and the per-second samples of series, if any.
+// It closes the YCSB logger to flush the last seconds of the series.
+func writeReport(path string, result *runSummary, ycsbLogger *logger.YCSBLogger, series *logger.TimeSeries) {
+	ycsbLogger.Close()
+	r := &report.Report{
+		Title:      fmt.Sprintf("gendata %s report", result.Mode),
+		RunID:      result.RunID,
+		Started:    result.start,
+		Duration:   time.Since(result.start),
+		Success:    result.Success,
+		Error:      result.Error,
+		Results:    resultSections(result),
+		Operations: ycsbLogger.Stats(),
+		Config:     setFlags(),
+	}
+	if series != nil {
+		r.Series = series.Rows()
+	}
+	if err := report.Write(path, r); err != nil {
+		log.Printf("Warning: %v", err)
+		return
+	}
+	log.Printf("Report written to %s", path)
+}
+
+// resultSections lists the results of each part of a run summary
+func resultSections(result *runSummary) []report.Section {
+	var sections []report.Section
+	for _, part := range []struct {
+		title   string
+		summary interface{}
+	}{
+		{"Load", result.Load},
+		{"Workload", result.Workload},
+		{"Verification", result.Verification},
+		{"Drain", result.Drain},
+	} {
+		if reflect.ValueOf(part.summary).IsNil() {
+			continue
+		}
+		sections = append(sections, report.Section{Title: part.title, Fields: summaryFields(part.summary)})
+	}
+	if len(result.SLOs) > 0 {
+		section := report.Section{Title: "SLOs"}
+		for _, r := range result.SLOs {
+			section.Fields = append(section.Fields, report.Field{Name: r.SLO,
+				Value: fmt.Sprintf("met in %.1f%% of %d intervals (worst %.1fms)", r.CompliancePercent, r.Intervals, r.WorstMs)})
+		}
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+// summaryFields returns the plain values of a summary struct under their
+// --summary-json names, leaving out empty optional ones and nested values
+func summaryFields(summary interface{}) []report.Field {
+	v := reflect.ValueOf(summary).Elem()
+	var fields []report.Field
+	for i := 0; i < v.NumField(); i++ {
+		name, options, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+		value := v.Field(i)
+		if name == "" || name == "-" || (options == "omitempty" && value.IsZero()) {
+			continue
+		}
+		switch value.Kind() {
+		case reflect.Float64:
+			fields = append(fields, report.Field{Name: name, Value: fmt.Sprintf("%.2f", value.Float())})
+		case reflect.Bool, reflect.Int, reflect.Int64, reflect.String:
+			fields = append(fields, report.Field{Name: name, Value: fmt.Sprint(value.Interface())})
+		case reflect.Slice:
+			if list, ok := value.Interface().([]string); ok {
+				fields = append(fields, report.Field{Name: name, Value: strings.Join(list, "; ")})
+			}
+		}
+	}
+	return fields
+}
+
+// setFlags returns the flags set on the command line or by a config file or
+// spec, with secrets redacted
+func setFlags() []report.Field {
+	values := flagValues()
+	var fields []report.Field
+	flag.Visit(func(f *flag.Flag) {
+		fields = append(fields, report.Field{Name: f.Name, Value: values[f.Name]})
+	})
+	return fields
+}
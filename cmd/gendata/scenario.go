@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/internal/config"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/scenario"
+)
+
+// phaseFiles are output files every phase gets its own copy of, named after
+// the phase, unless the phase sets them itself
+var phaseFiles = []string{"log-file", "timeseries-file", "hgrm-dir", "report"}
+
+// phaseSummary is the result of one phase of a scenario
+type phaseSummary struct {
+	Name            string          `json:"name"`
+	Command         string          `json:"command"`
+	Success         bool            `json:"success"`
+	ExitCode        int             `json:"exit_code"`
+	DurationSeconds float64         `json:"duration_seconds"`
+	Result          json.RawMessage `json:"result,omitempty"` // The phase's --summary-json object
+}
+
+// acceptsFlag reports whether a command a scenario phase can run accepts
+// the flag name, and whether the command exists
+func acceptsFlag(command, name string) (bool, bool) {
+	cmd := findCommand(command)
+	if cmd == nil || cmd.mode == "scenario" {
+		return false, false
+	}
+	return slices.Contains(commonFlags, name) || slices.Contains(cmd.flags, name), true
+}
+
+// scenarioOverrides returns the flags given to run-scenario on the command
+// line, which every phase receives after its own
+func scenarioOverrides() map[string]string {
+	overrides := make(map[string]string)
+	flag.CommandLine.Visit(func(f *flag.Flag) {
+		if f.Name != "scenario" && f.Name != "summary-json" {
+			overrides[f.Name] = f.Value.String()
+		}
+	})
+	return overrides
+}
+
+// runScenario runs the phases of the scenario file one after another, each
+// as a gendata command of its own, and stops at the first phase that fails.
+// It returns the exit status of the failed phase, or 0. A shutdown signal is
+// passed on to the running phase, and no further phases are started.
+func runScenario(path string, overrides map[string]string, summaryJSON bool, result *runSummary) (int, error) {
+	s, err := scenario.Load(path, acceptsFlag)
+	if err != nil {
+		return 1, err
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return 1, fmt.Errorf("failed to locate the gendata binary: %w", err)
+	}
+
+	// Signals reach the running phase once, through here (see phaseProcAttr)
+	var (
+		mu          sync.Mutex
+		running     *os.Process
+		interrupted bool
+	)
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(sigChan)
+	go func() {
+		for sig := range sigChan {
+			mu.Lock()
+			interrupted = true
+			if running != nil {
+				running.Signal(sig)
+			}
+			mu.Unlock()
+		}
+	}()
+
+	for i, phase := range s.Phases {
+		mu.Lock()
+		stop := interrupted
+		mu.Unlock()
+		if stop {
+			log.Printf("Interrupted, skipping the remaining %d phases", len(s.Phases)-i)
+			return 1, fmt.Errorf("interrupted before phase %s", phase.Name)
+		}
+
+		args, err := phaseArgs(s, phase, overrides, summaryJSON)
+		if err != nil {
+			return 1, err
+		}
+		fmt.Fprintf(console, "\n=== Phase %d/%d: %s (%s) ===\n", i+1, len(s.Phases), phase.Name, phase.Command)
+		log.Printf("Running: gendata %s", strings.Join(args, " "))
+
+		var stdout bytes.Buffer
+		cmd := exec.Command(self, args...)
+		cmd.Stdout = console
+		if summaryJSON {
+			cmd.Stdout = &stdout
+		}
+		cmd.Stderr = os.Stderr
+		cmd.SysProcAttr = phaseProcAttr()
+
+		started := time.Now()
+		mu.Lock()
+		err = cmd.Start()
+		if err == nil {
+			running = cmd.Process
+		}
+		mu.Unlock()
+		if err != nil {
+			return 1, fmt.Errorf("failed to start phase %s: %w", phase.Name, err)
+		}
+		err = cmd.Wait()
+		mu.Lock()
+		running = nil
+		mu.Unlock()
+
+		summary := phaseSummary{
+			Name:            phase.Name,
+			Command:         phase.Command,
+			Success:         err == nil,
+			DurationSeconds: time.Since(started).Seconds(),
+		}
+		if summaryJSON {
+			summary.Result = splitSummary(stdout.Bytes())
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			summary.ExitCode = exitErr.ExitCode()
+		} else if err != nil {
+			summary.ExitCode = 1
+		}
+		result.Phases = append(result.Phases, summary)
+
+		if err != nil {
+			fmt.Fprintf(console, "Phase %s failed after %v (exit status %d)\n", phase.Name, time.Since(started).Round(time.Second), summary.ExitCode)
+			if len(s.Phases) > i+1 {
+				log.Printf("Skipping the remaining %d phases", len(s.Phases)-i-1)
+			}
+			return max(summary.ExitCode, 1), fmt.Errorf("phase %s failed: %w", phase.Name, err)
+		}
+		fmt.Fprintf(console, "Phase %s finished in %v\n", phase.Name, time.Since(started).Round(time.Second))
+	}
+	return 0, nil
+}
+
+// phaseArgs returns the command line of a phase: its command, the scenario
+// settings it accepts, its own values, and the run-scenario overrides
+func phaseArgs(s *scenario.Scenario, phase scenario.Phase, overrides map[string]string, summaryJSON bool) ([]string, error) {
+	values := s.Values(phase, acceptsFlag)
+	for name, value := range overrides {
+		values[name] = value
+	}
+
+	// Keep each phase's logs apart, e.g. ycsb-1-load.log
+	for _, name := range phaseFiles {
+		if accepted, _ := acceptsFlag(phase.Command, name); !accepted {
+			continue
+		}
+		if _, own := phase.Values[name]; own {
+			if _, override := overrides[name]; !override {
+				continue
+			}
+		}
+		base := flag.Lookup(name).DefValue
+		if value, ok := values[name]; ok {
+			base = fmt.Sprint(value)
+		}
+		if base != "" {
+			values[name] = namedFile(base, phase.Name)
+		}
+	}
+	if summaryJSON {
+		values["summary-json"] = true
+		values["quiet"] = true
+	}
+
+	args, err := config.Args(values)
+	if err != nil {
+		return nil, fmt.Errorf("phase %s: %w", phase.Name, err)
+	}
+	return append([]string{phase.Command}, args...), nil
+}
+
+// splitSummary returns the --summary-json object a phase printed as its last
+// line of output, or nil if there is none
+func splitSummary(stdout []byte) json.RawMessage {
+	lines := bytes.Split(bytes.TrimSpace(stdout), []byte("\n"))
+	last := lines[len(lines)-1]
+	if !json.Valid(last) {
+		return nil
+	}
+	return last
+}
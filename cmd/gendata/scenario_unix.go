@@ -0,0 +1,11 @@
+//go:build unix
+
+package main
+
+import "syscall"
+
+// phaseProcAttr runs a phase in its own process group, so a terminal's
+// interrupt reaches it once, forwarded by runScenario, rather than twice
+func phaseProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setpgid: true}
+}
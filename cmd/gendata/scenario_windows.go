@@ -0,0 +1,10 @@
+package main
+
+import "syscall"
+
+// phaseProcAttr leaves a phase in the console's process group: Windows
+// cannot forward an interrupt to another process, so the phase receives the
+// console's Ctrl+C itself
+func phaseProcAttr() *syscall.SysProcAttr {
+	return nil
+}
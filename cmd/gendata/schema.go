@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+
+	"github.com/meticulous-dft/mongodb-data-generator/internal/config"
+)
+
+// schemaFlags are the flags describing the generated documents, which the
+// schemas of a --schema-dir may set
+var schemaFlags = []string{
+	"template", "doc-size", "time-range", "time-distribution", "ordered-times", "padding-mode", "padding-sizing", "exact-size", "strict-size",
+	"checksum", "bson-types", "orders-per-customer", "line-items-per-order", "nesting-depth", "cardinality", "sparsity",
+	"field-values", "field-templates", "legacy-fraction", "locales", "coherent-addresses", "no-pii", "tenants",
+}
+
+// resolveSchema returns the flag values of the named schema of the registry
+// in dir, and the chain of schemas it extends
+func resolveSchema(dir, name string) (map[string]interface{}, []string, error) {
+	if dir == "" {
+		return nil, nil, fmt.Errorf("--schema needs a --schema-dir")
+	}
+	registry, err := config.OpenRegistry(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	values, chain, err := registry.Resolve(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	for key := range values {
+		if !slices.Contains(schemaFlags, key) {
+			return nil, nil, fmt.Errorf("schema %s: %s does not describe documents and cannot be set by a schema", name, key)
+		}
+	}
+	return values, chain, nil
+}
+
+// applySchema sets the document flags that the command line, config file,
+// and spec left unset from the named schema, and returns the chain of
+// schemas it extends
+func applySchema(dir, name string) ([]string, error) {
+	values, chain, err := resolveSchema(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.Apply(flag.CommandLine, values); err != nil {
+		return nil, fmt.Errorf("schema %s: %w", name, err)
+	}
+	return chain, nil
+}
+
+// applyPipelineSchema sets the flags of a pipeline's flag set that the
+// pipeline left unset from its own schema, which may only set pipelineFlags
+func applyPipelineSchema(fs *flag.FlagSet, dir, name string) error {
+	values, _, err := resolveSchema(dir, name)
+	if err != nil {
+		return err
+	}
+	for key := range values {
+		if !slices.Contains(pipelineFlags, key) {
+			return fmt.Errorf("schema %s sets %s, which pipelines cannot set individually", name, key)
+		}
+	}
+	return config.Apply(fs, values)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/internal/mongo"
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+)
+
+// prepareSharding shards the target collection on key, pre-splits it into
+// chunks, and pauses the balancer, as configured. It returns a function that
+// restarts the balancer, which is also called if the run crashes.
+func prepareSharding(w *mongo.Writer, schema model.Schema, key mongo.ShardKey, chunks int, pauseBalancer, verbose bool) func() {
+	if !key.IsZero() {
+		// Ranged split points assume uniformly distributed UUID keys
+		if chunks > 0 && !key.Hashed && key.Field != schema.KeyField {
+			fatalf("Error: --presplit-chunks with a ranged shard key requires the %s key field; use --shard-key %s:hashed", schema.KeyField, key.Field)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+		if err := mongo.ShardCollection(ctx, w.Collection(), key); err != nil {
+			fatalf("Error: %v", err)
+		}
+		if chunks > 0 {
+			if verbose {
+				log.Printf("Pre-splitting into %d chunks on %s", chunks, key)
+			}
+			if err := mongo.PresplitChunks(ctx, w.Collection(), key, chunks); err != nil {
+				fatalf("Error: %v", err)
+			}
+		}
+	}
+	if !pauseBalancer {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	restart, err := mongo.PauseBalancer(ctx, w.Client())
+	if err != nil {
+		fatalf("Error: %v", err)
+	}
+	if verbose {
+		log.Printf("Balancer paused for the load")
+	}
+
+	var once sync.Once
+	restore := func() {
+		once.Do(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			if err := restart(ctx); err != nil {
+				log.Printf("Warning: %v", err)
+			} else if verbose {
+				log.Printf("Balancer restored")
+			}
+		})
+	}
+	onCrash(func(string) { restore() })
+	return restore
+}
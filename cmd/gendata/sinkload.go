@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/generator"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/logger"
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"github.com/meticulous-dft/mongodb-data-generator/sink"
+)
+
+// sinkUnsupported are the load flags that need a MongoDB deployment and are
+// rejected with other sinks unless left at their defaults
+var sinkUnsupported = []string{
+	"verify", "steady-state", "update-fields", "push-cap", "scan-length", "op-think-time", "load-profile", "maintain-size", "create-views", "materialize-interval", "churn-rate", "collection-count", "target-metric", "tail-changestream", "clustered", "collation",
+	"shard-key", "presplit-chunks", "pause-balancer", "direct-shards", "shard-stats-interval", "key-space-from",
+	"encrypt-fields", "oplog-stats-interval", "max-replication-lag", "sympathetic",
+	"auto-tune", "marshal-workers", "batch-bytes", "connection-mode", "max-pool-size", "min-pool-size", "write-mode", "ordered", "clients",
+	"orders-collection", "grow-steps", "duplicate-ratio", "insert-timeout", "op-timeout", "max-retries", "retry-backoff", "exactly-once",
+	"chaos-delay", "chaos-max-delay", "chaos-duplicate", "chaos-fail", "chaos-reset", "chaos-drop", "oversize-ratio",
+	"bundle", "bundle-s3", "collect-diagnostics",
+}
+
+// loggedSink records the latency of every batch in the YCSB log as inserts
+type loggedSink struct {
+	sink.Sink
+	ycsbLogger *logger.YCSBLogger
+}
+
+func (s loggedSink) WriteBatch(ctx context.Context, docs []model.Document) error {
+	startTime := time.Now()
+	err := s.Sink.WriteBatch(ctx, docs)
+	avgLatencyPerDoc := time.Since(startTime) / time.Duration(len(docs))
+	for range docs {
+		s.ycsbLogger.RecordOperation("INSERT", avgLatencyPerDoc, err == nil)
+	}
+	return err
+}
+
+// runSinkLoad generates documents into a sink other than MongoDB, e.g. a
+// file, with the load's writers and batch size, and records the results
+func runSinkLoad(ctx context.Context, genService *generator.Service, to sink.Sink, ycsbLogger *logger.YCSBLogger, drain *drainer, result *runSummary) error {
+	start := time.Now()
+
+	done := make(chan struct{})
+	var progress sync.WaitGroup
+	progress.Add(1)
+	go func() {
+		defer progress.Done()
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				genStats := genService.GetStats()
+				written := to.Stats()
+				fmt.Fprintf(console, "\r[Gen: %d docs, %.2f MB/s] [Buffer: %d/%d] [Write: %d docs] [Total: %.2f GB]",
+					genStats.DocumentsGenerated,
+					genStats.BytesPerSecond/(1024*1024),
+					genStats.BufferDepth,
+					genStats.BufferCapacity,
+					written.DocumentsWritten,
+					float64(written.BytesWritten)/(1024*1024*1024),
+				)
+				os.Stdout.Sync()
+			}
+		}
+	}()
+
+	// A shutdown signal stops generation; the sink writes what was generated
+	drain.startLoad(genService.Stop)
+	err := genService.Run(ctx, loggedSink{Sink: to, ycsbLogger: ycsbLogger})
+	drain.finish()
+	close(done)
+	progress.Wait()
+	if closeErr := to.Close(); err == nil {
+		err = closeErr
+	}
+
+	elapsed := time.Since(start)
+	genStats := genService.GetStats()
+	written := to.Stats()
+	fmt.Fprintf(console, "\n\n=== Final Statistics ===\n")
+	fmt.Fprintf(console, "Total time: %v\n", elapsed.Round(time.Second))
+	fmt.Fprintf(console, "Documents generated: %d\n", genStats.DocumentsGenerated)
+	fmt.Fprintf(console, "Documents written: %d\n", written.DocumentsWritten)
+	fmt.Fprintf(console, "Bytes written: %.2f GB\n", float64(written.BytesWritten)/(1024*1024*1024))
+	printOversized(console, genStats)
+	fmt.Fprintf(console, "Average write rate: %.2f docs/sec, %.2f MB/s\n",
+		float64(written.DocumentsWritten)/elapsed.Seconds(),
+		float64(written.BytesWritten)/(1024*1024)/elapsed.Seconds(),
+	)
+
+	result.Load = &loadSummary{
+		DocumentsGenerated: genStats.DocumentsGenerated,
+		BufferCapacity:     genStats.BufferCapacity,
+		PeakBufferDepth:    genStats.PeakBufferDepth,
+		ThrottledSeconds:   genStats.ThrottledTime.Seconds(),
+		OversizedDocuments: genStats.OversizedDocuments,
+		DocumentsWritten:   written.DocumentsWritten,
+		BytesWritten:       written.BytesWritten,
+		DocumentsPerSecond: float64(written.DocumentsWritten) / elapsed.Seconds(),
+		BytesPerSecond:     float64(written.BytesWritten) / elapsed.Seconds(),
+	}
+
+	if err == context.Canceled {
+		return nil
+	}
+	return err
+}
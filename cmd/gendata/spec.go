@@ -0,0 +1,669 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/internal/config"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/mongo"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/pacing"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/spec"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/workload"
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+)
+
+// flagValue returns the typed value of a registered flag
+func flagValue(name string) interface{} {
+	return flag.Lookup(name).Value.(flag.Getter).Get()
+}
+
+func flagString(name string) string          { return flagValue(name).(string) }
+func flagInt(name string) int                { return flagValue(name).(int) }
+func flagFloat(name string) float64          { return flagValue(name).(float64) }
+func flagBool(name string) bool              { return flagValue(name).(bool) }
+func flagDuration(name string) time.Duration { return flagValue(name).(time.Duration) }
+
+// buildSpec describes the effective workload from the resolved flag values
+func buildSpec(mode string, docSize model.DocumentSize, targetBytes, churnKeepBytes int64) (*spec.Spec, error) {
+	padMode, err := model.ParsePaddingMode(flagString("padding-mode"))
+	if err != nil {
+		return nil, err
+	}
+	schema := model.NewGeneratorWithOptions(docSize, model.Options{
+		PaddingMode:  padMode,
+		Checksum:     flagBool("checksum"),
+		BSONTypes:    flagBool("bson-types"),
+		NestingDepth: flagInt("nesting-depth"),
+		Template:     flagString("template"),
+	}).Schema()
+
+	s := &spec.Spec{
+		Version: spec.Version,
+		Tool:    "mongodb-data-generator",
+		Mode:    "load",
+		Target: spec.Target{
+			Database:         flagString("database"),
+			Collection:       flagString("collection"),
+			OrdersCollection: flagString("orders-collection"),
+			Clustered:        flagBool("clustered"),
+			Collation:        flagString("collation"),
+			ShardKey:         flagString("shard-key"),
+			PresplitChunks:   flagInt("presplit-chunks"),
+			PauseBalancer:    flagBool("pause-balancer"),
+		},
+		Documents: spec.Documents{
+			Template:           schema.Template,
+			TimeRange:          flagString("time-range"),
+			TimeDistribution:   flagString("time-distribution"),
+			OrderedTimes:       flagBool("ordered-times"),
+			Fields:             schema.Fields,
+			SizeBytes:          int(docSize),
+			ExactSize:          flagBool("exact-size") || flagBool("strict-size"),
+			Padding:            flagString("padding-mode"),
+			Checksum:           flagBool("checksum"),
+			BSONTypes:          flagBool("bson-types"),
+			OrdersPerCustomer:  flagString("orders-per-customer"),
+			LineItemsPerOrder:  flagString("line-items-per-order"),
+			NestingDepth:       flagInt("nesting-depth"),
+			Tenants:            parseList(flagString("tenants")),
+			KeySpaceFrom:       flagString("key-space-from"),
+			ProductCatalogSize: model.DefaultProductCount,
+		},
+	}
+
+	if flagBool("tag-run") {
+		s.Documents.RunTags = parseList(flagString("tag-fields"))
+	}
+	cardinality, err := model.ParseCardinality(flagString("cardinality"))
+	if err != nil {
+		return nil, err
+	}
+	if len(cardinality) > 0 {
+		s.Documents.Cardinality = cardinality
+	}
+	sparsity, err := model.ParseSparsity(flagString("sparsity"))
+	if err != nil {
+		return nil, err
+	}
+	s.Documents.Sparsity = sparsity.String()
+	s.Documents.FieldValues = flagString("field-values")
+	s.Documents.FieldTemplates = flagString("field-templates")
+	s.Documents.LegacyFraction = flagFloat("legacy-fraction")
+	if s.Documents.Locales, err = model.ParseLocales(flagString("locales")); err != nil {
+		return nil, err
+	}
+	s.Documents.CoherentAddresses = flagBool("coherent-addresses")
+	s.Documents.NoPII = flagBool("no-pii")
+
+	if mode == "workload" {
+		s.Mode = "workload"
+		s.Workload, err = buildWorkloadSpec()
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+
+	var batchBytes int64
+	if size := flagString("batch-bytes"); size != "" {
+		if batchBytes, err = parseSize(size); err != nil {
+			return nil, err
+		}
+	}
+	s.Load = &spec.Load{
+		TargetBytes:          targetBytes,
+		Workers:              flagInt("workers"),
+		Writers:              flagInt("writers"),
+		BatchSize:            flagInt("batch-size"),
+		BatchBytes:           batchBytes,
+		BufferDocs:           flagInt("buffer-docs"),
+		AdaptiveBuffer:       flagBool("adaptive-buffer"),
+		MarshalWorkers:       flagInt("marshal-workers"),
+		ConnectionMode:       flagString("connection-mode"),
+		MaxPoolSize:          flagInt("max-pool-size"),
+		MinPoolSize:          flagInt("min-pool-size"),
+		Ordered:              flagBool("ordered"),
+		LoadProfile:          flagString("load-profile"),
+		Clients:              flagInt("clients"),
+		ChurnRate:            flagInt("churn-rate"),
+		ChurnKeepBytes:       churnKeepBytes,
+		DuplicateRatio:       flagFloat("duplicate-ratio"),
+		GrowSteps:            flagInt("grow-steps"),
+		MaxRetries:           flagInt("max-retries"),
+		RetryBackoffSeconds:  flagDuration("retry-backoff").Seconds(),
+		InsertTimeoutSeconds: flagDuration("insert-timeout").Seconds(),
+		ExactlyOnce:          flagBool("exactly-once"),
+
+		MaxReplicationLagSeconds: flagDuration("max-replication-lag").Seconds(),
+	}
+	if metric := flagString("target-metric"); metric != mongo.SizeBytes {
+		s.Load.TargetMetric = metric
+		s.Load.SizePollSeconds = flagDuration("size-poll-interval").Seconds()
+	}
+	if mode := flagString("write-mode"); mode != mongo.WriteInsertMany {
+		s.Load.WriteMode = mode
+	}
+	if s.Load.BufferDocs == 0 {
+		s.Load.BufferDocs = s.Load.BatchSize * 2
+	}
+	if s.Load.Clients > 0 {
+		s.Load.ClientBatchSize = flagInt("client-batch")
+		s.Load.ThinkTimeSeconds = flagDuration("think-time").Seconds()
+		s.Load.ThinkJitter = thinkJitter()
+	}
+	if s.Load.DuplicateRatio > 0 {
+		s.Load.DuplicateMode = flagString("duplicate-mode")
+	}
+	if count := flagInt("collection-count"); count > 0 {
+		s.Load.Collections = &spec.Collections{
+			Count:        count,
+			Template:     flagString("collection-template"),
+			Distribution: flagString("collection-distribution"),
+		}
+	}
+	if flagBool("maintain-size") {
+		s.Load.Maintain = &spec.Maintain{
+			IntervalSeconds: flagDuration("maintain-interval").Seconds(),
+			Tolerance:       flagFloat("maintain-tolerance"),
+		}
+	}
+	if views, interval := flagBool("create-views"), flagDuration("materialize-interval"); views || interval > 0 {
+		s.Load.Materialize = &spec.Materialize{
+			Views:           views,
+			IntervalSeconds: interval.Seconds(),
+			Queries:         parseList(flagString("bench-queries")),
+		}
+		if interval > 0 {
+			s.Load.Materialize.Stage = flagString("materialize-stage")
+		}
+	}
+	if flagBool("auto-tune") {
+		writers, err := parseCounts(flagString("auto-tune-writers"))
+		if err != nil {
+			return nil, err
+		}
+		batchSizes, err := parseCounts(flagString("auto-tune-batches"))
+		if err != nil {
+			return nil, err
+		}
+		s.Load.AutoTune = &spec.AutoTune{
+			Writers:           writers,
+			BatchSizes:        batchSizes,
+			BurstSeconds:      flagDuration("auto-tune-burst").Seconds(),
+			MaxLatencySeconds: flagDuration("auto-tune-latency").Seconds(),
+		}
+	}
+	if flagBool("sympathetic") {
+		s.Load.Sympathetic = &spec.Sympathetic{
+			PollSeconds:    flagDuration("health-poll-interval").Seconds(),
+			MaxCacheDirty:  flagFloat("max-cache-dirty"),
+			MinFreeTickets: flagFloat("min-free-tickets"),
+		}
+	}
+	if flagBool("steady-state") {
+		mix, err := workload.ParseMix(flagString("steady-state-mix"))
+		if err != nil {
+			return nil, err
+		}
+		s.Load.SteadyState = &spec.SteadyState{
+			DurationSeconds: flagDuration("steady-state-duration").Seconds(),
+			Rate:            flagInt("steady-state-rate"),
+			Threads:         flagInt("threads"),
+			Mix:             mixPercentages(mix),
+			UpdateFields:    parseList(flagString("update-fields")),
+			PushCap:         flagInt("push-cap"),
+		}
+		if length := flagString("scan-length"); length != workload.DefaultScanLength.String() {
+			s.Load.SteadyState.ScanLength = length
+		}
+		if think := flagDuration("op-think-time"); think > 0 {
+			s.Load.SteadyState.OpThinkSeconds = think.Seconds()
+			s.Load.SteadyState.ThinkJitter = thinkJitter()
+		}
+	}
+	if fields := parseList(flagString("encrypt-fields")); len(fields) > 0 {
+		s.Load.Encryption = &spec.Encryption{
+			Mode:        flagString("encryption-mode"),
+			Fields:      fields,
+			Equality:    flagBool("encrypt-equality"),
+			KMSProvider: flagString("kms-provider"),
+		}
+		if s.Load.Encryption.KMSProvider != mongo.KMSLocal {
+			s.Load.Encryption.MasterKey = flagString("kms-master-key")
+		}
+	}
+
+	faults := &spec.Faults{
+		DelayRatio:      flagFloat("chaos-delay"),
+		MaxDelaySeconds: flagDuration("chaos-max-delay").Seconds(),
+		DuplicateRatio:  flagFloat("chaos-duplicate"),
+		FailRatio:       flagFloat("chaos-fail"),
+		ResetRatio:      flagFloat("chaos-reset"),
+		DropRatio:       flagFloat("chaos-drop"),
+		OversizeRatio:   flagFloat("oversize-ratio"),
+	}
+	if faults.DelayRatio > 0 || faults.DuplicateRatio > 0 || faults.FailRatio > 0 || faults.ResetRatio > 0 || faults.DropRatio > 0 || faults.OversizeRatio > 0 {
+		s.Faults = faults
+	}
+	return s, nil
+}
+
+// buildWorkloadSpec describes the operation mix and its phases
+func buildWorkloadSpec() (*spec.Workload, error) {
+	mix, err := workload.ParseMix(flagString("workload-mix"))
+	if err != nil {
+		return nil, err
+	}
+	w := &spec.Workload{
+		ReadOnly:                flagBool("read-only"),
+		DurationSeconds:         flagDuration("duration").Seconds(),
+		Threads:                 flagInt("threads"),
+		Rate:                    flagInt("rate"),
+		LoadProfile:             flagString("load-profile"),
+		Mix:                     mixPercentages(mix),
+		TouchRate:               flagInt("touch-rate"),
+		UpdateFields:            parseList(flagString("update-fields")),
+		PushCap:                 flagInt("push-cap"),
+		InsertTimeoutSeconds:    flagDuration("insert-timeout").Seconds(),
+		QueryTimeoutSeconds:     flagDuration("query-timeout").Seconds(),
+		AggregateTimeoutSeconds: flagDuration("aggregate-timeout").Seconds(),
+	}
+	if mode := flagString("write-mode"); mode != mongo.WriteInsertMany {
+		w.WriteMode = mode
+	}
+	if pref := flagString("read-preference"); pref != "primary" {
+		w.ReadPreference = pref
+	}
+	if length := flagString("scan-length"); length != workload.DefaultScanLength.String() {
+		w.ScanLength = length
+	}
+	if think := flagDuration("op-think-time"); think > 0 {
+		w.OpThinkSeconds = think.Seconds()
+		w.ThinkJitter = thinkJitter()
+	}
+	if sample := flagFloat("ryow-sample"); sample > 0 {
+		w.RYOW = &spec.RYOW{
+			Sample:              sample,
+			MaxStalenessSeconds: flagDuration("ryow-max-staleness").Seconds(),
+			TimeoutSeconds:      flagDuration("ryow-timeout").Seconds(),
+		}
+	}
+	if projection := flagString("read-projection"); projection != "full" {
+		w.ReadProjection = projection
+	}
+	w.CausalConsistency = flagBool("causal-consistency")
+	w.Sessions = flagInt("sessions")
+
+	if scheduleStr := flagString("mix-schedule"); scheduleStr != "" {
+		schedule, err := workload.ParseSchedule(scheduleStr)
+		if err != nil {
+			return nil, err
+		}
+		for _, point := range schedule {
+			w.Phases = append(w.Phases, spec.Phase{
+				OffsetSeconds: point.Offset.Seconds(),
+				Mix:           mixPercentages(point.Mix),
+			})
+		}
+	}
+	return w, nil
+}
+
+// mixPercentages converts mix weights to lower-case operation percentages
+func mixPercentages(mix workload.Mix) map[string]float64 {
+	var total float64
+	for _, w := range mix {
+		total += w
+	}
+	pct := make(map[string]float64, len(mix))
+	for op, w := range mix {
+		pct[strings.ToLower(op)] = w / total * 100
+	}
+	return pct
+}
+
+// formatMix formats spec percentages in --workload-mix syntax
+func formatMix(mix map[string]float64) string {
+	ops := make([]string, 0, len(mix))
+	for op := range mix {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+
+	parts := make([]string, len(ops))
+	for i, op := range ops {
+		parts[i] = op + "=" + strconv.FormatFloat(mix[op], 'f', -1, 64)
+	}
+	return strings.Join(parts, ",")
+}
+
+// formatCounts formats spec numbers as a comma-separated list
+func formatCounts(counts []int) string {
+	parts := make([]string, len(counts))
+	for i, n := range counts {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ",")
+}
+
+// seconds converts spec seconds to a duration in flag syntax
+func seconds(s float64) string {
+	return time.Duration(s * float64(time.Second)).String()
+}
+
+// thinkJitter returns the --think-jitter of a spec ("" = fixed)
+func thinkJitter() string {
+	if jitter := flagString("think-jitter"); jitter != string(pacing.JitterFixed) {
+		return jitter
+	}
+	return ""
+}
+
+// applySpec sets every flag described by the spec at path that was not set
+// on the command line
+func applySpec(path string) error {
+	s, err := spec.Read(path)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]interface{}{
+		"database":     s.Target.Database,
+		"collection":   s.Target.Collection,
+		"doc-size":     model.DocumentSize(s.Documents.SizeBytes).String(),
+		"padding-mode": s.Documents.Padding,
+		"checksum":     s.Documents.Checksum,
+		"bson-types":   s.Documents.BSONTypes,
+	}
+	if s.Documents.Template != "" {
+		values["template"] = s.Documents.Template
+	}
+	if s.Documents.ExactSize {
+		values["exact-size"] = true
+	}
+	if s.Documents.OrdersPerCustomer != "" {
+		values["orders-per-customer"] = s.Documents.OrdersPerCustomer
+	}
+	if s.Documents.LineItemsPerOrder != "" {
+		values["line-items-per-order"] = s.Documents.LineItemsPerOrder
+	}
+	if s.Documents.NestingDepth > 0 {
+		values["nesting-depth"] = s.Documents.NestingDepth
+	}
+	if len(s.Documents.Cardinality) > 0 {
+		values["cardinality"] = model.Cardinality(s.Documents.Cardinality).String()
+	}
+	if s.Documents.Sparsity != "" {
+		values["sparsity"] = s.Documents.Sparsity
+	}
+	if s.Documents.FieldValues != "" {
+		values["field-values"] = s.Documents.FieldValues
+	}
+	if s.Documents.FieldTemplates != "" {
+		values["field-templates"] = s.Documents.FieldTemplates
+	}
+	if s.Documents.LegacyFraction > 0 {
+		values["legacy-fraction"] = s.Documents.LegacyFraction
+	}
+	if len(s.Documents.Locales) > 0 {
+		values["locales"] = strings.Join(s.Documents.Locales, ",")
+	}
+	if s.Documents.CoherentAddresses {
+		values["coherent-addresses"] = true
+	}
+	if s.Documents.NoPII {
+		values["no-pii"] = true
+	}
+	if s.Documents.TimeRange != "" {
+		values["time-range"] = s.Documents.TimeRange
+	}
+	if s.Documents.TimeDistribution != "" {
+		values["time-distribution"] = s.Documents.TimeDistribution
+	}
+	if s.Documents.OrderedTimes {
+		values["ordered-times"] = true
+	}
+	if s.Target.OrdersCollection != "" {
+		values["orders-collection"] = s.Target.OrdersCollection
+	}
+	if s.Target.Clustered {
+		values["clustered"] = true
+	}
+	if s.Target.Collation != "" {
+		values["collation"] = s.Target.Collation
+	}
+	if s.Target.ShardKey != "" {
+		values["shard-key"] = s.Target.ShardKey
+	}
+	if s.Target.PresplitChunks > 0 {
+		values["presplit-chunks"] = s.Target.PresplitChunks
+	}
+	if s.Target.PauseBalancer {
+		values["pause-balancer"] = true
+	}
+	if len(s.Documents.Tenants) > 0 {
+		values["tenants"] = strings.Join(s.Documents.Tenants, ",")
+	}
+	if len(s.Documents.RunTags) > 0 {
+		values["tag-run"] = true
+		values["tag-fields"] = strings.Join(s.Documents.RunTags, ",")
+	}
+	if s.Documents.KeySpaceFrom != "" {
+		values["key-space-from"] = s.Documents.KeySpaceFrom
+	}
+	if n := s.Documents.ProductCatalogSize; n != 0 && n != model.DefaultProductCount {
+		return fmt.Errorf("spec product catalog size %d is not supported (only %d)", n, model.DefaultProductCount)
+	}
+
+	switch s.Mode {
+	case "load":
+		if s.Load == nil {
+			return fmt.Errorf("load spec has no load section")
+		}
+		applyLoadSpec(values, s.Load)
+		if f := s.Faults; f != nil {
+			values["chaos-delay"] = f.DelayRatio
+			values["chaos-max-delay"] = seconds(f.MaxDelaySeconds)
+			values["chaos-duplicate"] = f.DuplicateRatio
+			values["chaos-fail"] = f.FailRatio
+			values["chaos-reset"] = f.ResetRatio
+			values["chaos-drop"] = f.DropRatio
+			values["oversize-ratio"] = f.OversizeRatio
+		}
+	case "workload":
+		if s.Workload == nil {
+			return fmt.Errorf("workload spec has no workload section")
+		}
+		applyWorkloadSpec(values, s.Workload)
+	default:
+		return fmt.Errorf("unsupported spec mode: %s", s.Mode)
+	}
+
+	return config.Apply(flag.CommandLine, values)
+}
+
+// applyLoadSpec adds the flag values of a load section
+func applyLoadSpec(values map[string]interface{}, l *spec.Load) {
+	values["size"] = strconv.FormatInt(l.TargetBytes, 10) + "B"
+	if l.TargetMetric != "" {
+		values["target-metric"] = l.TargetMetric
+		values["size-poll-interval"] = seconds(l.SizePollSeconds)
+	}
+	values["workers"] = l.Workers
+	values["writers"] = l.Writers
+	values["batch-size"] = l.BatchSize
+	if l.BatchBytes > 0 {
+		values["batch-bytes"] = strconv.FormatInt(l.BatchBytes, 10) + "B"
+	}
+	values["buffer-docs"] = l.BufferDocs
+	values["adaptive-buffer"] = l.AdaptiveBuffer
+	values["marshal-workers"] = l.MarshalWorkers
+	if l.ConnectionMode != "" {
+		values["connection-mode"] = l.ConnectionMode
+	}
+	values["max-pool-size"] = l.MaxPoolSize
+	values["min-pool-size"] = l.MinPoolSize
+	if l.WriteMode != "" {
+		values["write-mode"] = l.WriteMode
+	}
+	values["ordered"] = l.Ordered
+	if l.LoadProfile != "" {
+		values["load-profile"] = l.LoadProfile
+	}
+	values["max-retries"] = l.MaxRetries
+	values["retry-backoff"] = seconds(l.RetryBackoffSeconds)
+	values["insert-timeout"] = seconds(l.InsertTimeoutSeconds)
+	values["exactly-once"] = l.ExactlyOnce
+	if l.MaxReplicationLagSeconds > 0 {
+		values["max-replication-lag"] = seconds(l.MaxReplicationLagSeconds)
+	}
+	if l.Clients > 0 {
+		values["clients"] = l.Clients
+		values["client-batch"] = l.ClientBatchSize
+		values["think-time"] = seconds(l.ThinkTimeSeconds)
+		if l.ThinkJitter != "" {
+			values["think-jitter"] = l.ThinkJitter
+		}
+	}
+	if l.ChurnRate > 0 {
+		values["churn-rate"] = l.ChurnRate
+		values["churn-keep"] = strconv.FormatInt(l.ChurnKeepBytes, 10) + "B"
+	}
+	if l.DuplicateRatio > 0 {
+		values["duplicate-ratio"] = l.DuplicateRatio
+		values["duplicate-mode"] = l.DuplicateMode
+	}
+	if l.GrowSteps > 0 {
+		values["grow-steps"] = l.GrowSteps
+	}
+	if c := l.Collections; c != nil {
+		values["collection-count"] = c.Count
+		values["collection-template"] = c.Template
+		values["collection-distribution"] = c.Distribution
+	}
+	if m := l.Maintain; m != nil {
+		values["maintain-size"] = true
+		values["maintain-interval"] = seconds(m.IntervalSeconds)
+		values["maintain-tolerance"] = m.Tolerance
+	}
+	if m := l.Materialize; m != nil {
+		values["create-views"] = m.Views
+		values["materialize-interval"] = seconds(m.IntervalSeconds)
+		if m.Stage != "" {
+			values["materialize-stage"] = m.Stage
+		}
+		values["bench-queries"] = strings.Join(m.Queries, ",")
+	}
+	if t := l.AutoTune; t != nil {
+		values["auto-tune"] = true
+		values["auto-tune-writers"] = formatCounts(t.Writers)
+		values["auto-tune-batches"] = formatCounts(t.BatchSizes)
+		values["auto-tune-burst"] = seconds(t.BurstSeconds)
+		values["auto-tune-latency"] = seconds(t.MaxLatencySeconds)
+	}
+	if h := l.Sympathetic; h != nil {
+		values["sympathetic"] = true
+		values["health-poll-interval"] = seconds(h.PollSeconds)
+		values["max-cache-dirty"] = h.MaxCacheDirty
+		values["min-free-tickets"] = h.MinFreeTickets
+	}
+	if st := l.SteadyState; st != nil {
+		values["steady-state"] = true
+		values["steady-state-duration"] = seconds(st.DurationSeconds)
+		values["steady-state-rate"] = st.Rate
+		values["threads"] = st.Threads
+		values["steady-state-mix"] = formatMix(st.Mix)
+		if len(st.UpdateFields) > 0 {
+			values["update-fields"] = strings.Join(st.UpdateFields, ",")
+		}
+		if st.PushCap > 0 {
+			values["push-cap"] = st.PushCap
+		}
+		if st.ScanLength != "" {
+			values["scan-length"] = st.ScanLength
+		}
+		if st.OpThinkSeconds > 0 {
+			values["op-think-time"] = seconds(st.OpThinkSeconds)
+		}
+		if st.ThinkJitter != "" {
+			values["think-jitter"] = st.ThinkJitter
+		}
+	}
+	if e := l.Encryption; e != nil {
+		values["encrypt-fields"] = strings.Join(e.Fields, ",")
+		values["encryption-mode"] = e.Mode
+		values["encrypt-equality"] = e.Equality
+		values["kms-provider"] = e.KMSProvider
+		if e.MasterKey != "" {
+			values["kms-master-key"] = e.MasterKey
+		}
+	}
+}
+
+// applyWorkloadSpec adds the flag values of a workload section
+func applyWorkloadSpec(values map[string]interface{}, w *spec.Workload) {
+	if w.ReadOnly {
+		values["read-only"] = true
+	} else {
+		values["run-workload"] = true
+	}
+	values["duration"] = seconds(w.DurationSeconds)
+	values["threads"] = w.Threads
+	if w.Rate > 0 {
+		values["rate"] = w.Rate
+	}
+	values["workload-mix"] = formatMix(w.Mix)
+	values["insert-timeout"] = seconds(w.InsertTimeoutSeconds)
+	values["query-timeout"] = seconds(w.QueryTimeoutSeconds)
+	values["aggregate-timeout"] = seconds(w.AggregateTimeoutSeconds)
+	if w.TouchRate > 0 {
+		values["touch-rate"] = w.TouchRate
+	}
+	if len(w.UpdateFields) > 0 {
+		values["update-fields"] = strings.Join(w.UpdateFields, ",")
+	}
+	if w.PushCap > 0 {
+		values["push-cap"] = w.PushCap
+	}
+	if w.WriteMode != "" {
+		values["write-mode"] = w.WriteMode
+	}
+	if w.ReadPreference != "" {
+		values["read-preference"] = w.ReadPreference
+	}
+	if w.ScanLength != "" {
+		values["scan-length"] = w.ScanLength
+	}
+	if w.LoadProfile != "" {
+		values["load-profile"] = w.LoadProfile
+	}
+	if w.OpThinkSeconds > 0 {
+		values["op-think-time"] = seconds(w.OpThinkSeconds)
+	}
+	if w.ThinkJitter != "" {
+		values["think-jitter"] = w.ThinkJitter
+	}
+	if c := w.RYOW; c != nil {
+		values["ryow-sample"] = c.Sample
+		values["ryow-max-staleness"] = seconds(c.MaxStalenessSeconds)
+		values["ryow-timeout"] = seconds(c.TimeoutSeconds)
+	}
+	if w.ReadProjection != "" {
+		values["read-projection"] = w.ReadProjection
+	}
+	values["causal-consistency"] = w.CausalConsistency
+	if w.Sessions > 0 {
+		values["sessions"] = w.Sessions
+	}
+	if len(w.Phases) > 0 {
+		phases := make([]string, len(w.Phases))
+		for i, phase := range w.Phases {
+			phases[i] = seconds(phase.OffsetSeconds) + ":" + formatMix(phase.Mix)
+		}
+		values["mix-schedule"] = strings.Join(phases, ";")
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/internal/mongo"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/verify"
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+)
+
+// runChecksumVerification re-reads documents of a collection and validates
+// their checksums, returning false on any mismatch
+func runChecksumVerification(ctx context.Context, connectionString, databaseName, collectionName string, sampleSize int, result *runSummary) (bool, error) {
+	client, err := mongo.Connect(connectionString, 1)
+	if err != nil {
+		return false, err
+	}
+	defer client.Disconnect(context.Background())
+
+	report, err := verify.VerifyChecksums(ctx, client.Database(databaseName).Collection(collectionName), sampleSize,
+		func(checked int64) {
+			fmt.Fprintf(console, "\r[Checked: %d documents]", checked)
+		})
+	if err != nil {
+		return false, err
+	}
+
+	report.Print(console)
+	result.setChecksums(report)
+	return report.OK(), nil
+}
+
+// verifyLoadResult checks the loaded collection against what the run wrote
+// and prints the report to out. Key probes and the tagged document count are
+// skipped when churn or duplicate collisions legitimately remove or replace
+// documents. The sizes of the array pushes grow are reported against
+// pushCap (0 = model.PushCap).
+// It returns false if verification failed or found discrepancies.
+func verifyLoadResult(mongoWriter *mongo.Writer, meta *mongo.RunMetadata, countBefore int64, skipKeyProbes bool, pushCap int, out io.Writer, result *runSummary) bool {
+	stats := mongoWriter.GetStats()
+
+	keys := mongoWriter.WrittenKeys()
+	var runID string
+	if meta.Tagged {
+		runID = meta.RunID
+	}
+	if skipKeyProbes {
+		keys = nil
+		runID = ""
+	}
+
+	// Documents are only near --doc-size with --exact-size, so the sample is
+	// compared with the average size of the documents the run actually wrote
+	var writtenSize int64
+	if stats.DocumentsWritten > 0 {
+		writtenSize = stats.BytesWritten / stats.DocumentsWritten
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+	report, err := verify.Run(ctx, verify.Config{
+		Collection:    mongoWriter.Collection(),
+		Schema:        meta.Schema,
+		KeySpace:      meta.KeySpace,
+		TargetDocSize: writtenSize,
+		ExpectedCount: countBefore + stats.DocumentsWritten - stats.DocumentsDeleted,
+		ArrayField:    model.PushField(meta.Schema.Template),
+		ArrayCap:      arrayCap(meta.Schema.Template, pushCap),
+
+		RunID:            runID,
+		ExpectedRunCount: stats.DocumentsWritten,
+
+		Keys: keys,
+	})
+	if err != nil {
+		log.Printf("Verification failed: %v", err)
+		return false
+	}
+
+	report.Print(out)
+	result.setVerification(report)
+	if !report.OK() {
+		fmt.Fprintf(out, "Verification found discrepancies\n")
+	}
+	return report.OK()
+}
+
+// reportArraySizes samples the sizes of the array pushes grow once the steady
+// state is over, to compare them with those verified after the load
+func reportArraySizes(mongoWriter *mongo.Writer, meta *mongo.RunMetadata, pushCap int, out io.Writer, result *runSummary) {
+	field := model.PushField(meta.Schema.Template)
+	if field == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	sizes, err := verify.SampleArraySizes(ctx, mongoWriter.Collection(), field, arrayCap(meta.Schema.Template, pushCap), 1000)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+		return
+	}
+	fmt.Fprintf(out, "Array sizes after steady state: %s\n", sizes)
+	if result.Verification != nil {
+		result.Verification.SteadyStateArraySizes = newArraySizesSummary(sizes)
+	}
+}
+
+// arrayCap returns the elements pushes keep in the template's array
+func arrayCap(template string, pushCap int) int {
+	if pushCap > 0 {
+		return pushCap
+	}
+	return model.PushCap(template)
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/internal/logger"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/mongo"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/pacing"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/workload"
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// workloadConfig holds settings for running a workload against an existing collection
+type workloadConfig struct {
+	connectionString string
+	databaseName     string
+	collectionName   string
+	threads          int
+	duration         time.Duration
+	mix              workload.Mix
+	schedule         workload.Schedule
+	updateFields     []string // Fields UPDATE sets (nil = updated_at and revision)
+	pushCap          int      // Elements PUSH keeps in the array (0 = model.PushCap)
+	projection       workload.Projection
+	scanLength       model.CountRange
+	thinkTime        pacing.ThinkTime
+	ryowSample       float64 // Fraction of inserts re-read from a secondary (0 = none)
+	ryowPref         *readpref.ReadPref
+	ryowTimeout      time.Duration
+	paddingMode      model.PaddingMode
+	insertTimeout    time.Duration
+	queryTimeout     time.Duration
+	aggregateTimeout time.Duration
+	keySpaceFrom     string // Run whose key space point operations target ("" = sampled _ids)
+	touchRate        int    // Background updated_at touches per second (0 = none)
+	rate             int    // Operations per second across all threads (0 = unlimited)
+	profile          *pacing.LoadProfile
+	bulkWrite        bool   // Send writes as single-operation bulkWrites
+	textIndex        bool   // Create the text index TEXT operations search
+	searchIndexFile  string // Atlas Search index definition to create ("" = use an existing index)
+	searchIndexName  string // Atlas Search index SEARCH operations query
+	readPreference   *readpref.ReadPref
+	causal           bool // Causally consistent sessions per thread
+	sessions         int  // Causally consistent sessions shared by the threads instead (0 = per thread)
+	verbose          bool
+}
+
+// runWorkload runs the configured operation mix against a collection
+// produced by an earlier load, without a bulk generation phase
+func runWorkload(ctx context.Context, config workloadConfig, ycsbLogger *logger.YCSBLogger, result *runSummary) error {
+	client, err := mongo.Connect(config.connectionString, config.threads)
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(context.Background())
+
+	db := client.Database(config.databaseName)
+	meta, err := mongo.LoadRunMetadata(ctx, db, config.collectionName, config.keySpaceFrom)
+	if err != nil {
+		return err
+	}
+
+	// Target exactly the documents an earlier load produced, by business key
+	var keySpace *model.KeySpace
+	if config.keySpaceFrom != "" {
+		if meta.KeySpace.IsZero() {
+			return fmt.Errorf("run %s did not record a key space", meta.RunID)
+		}
+		if meta.KeySpace.Count == 0 {
+			return fmt.Errorf("run %s recorded an empty key space (no keys to target)", meta.RunID)
+		}
+		keySpace = &meta.KeySpace
+		if err := mongo.EnsureKeyIndex(ctx, db.Collection(config.collectionName), meta.Schema.KeyField); err != nil {
+			return err
+		}
+		if config.verbose {
+			log.Printf("Targeting %d keys of run %s (seed %d)", meta.KeySpace.Count, meta.RunID, meta.KeySpace.Seed)
+		}
+	}
+
+	if config.verbose {
+		log.Printf("Using run %s: %s template, %d documents, %s documents",
+			meta.RunID, meta.Schema.Template, meta.DocumentsWritten, meta.DocumentSize)
+		log.Printf("Threads: %d, Duration: %v, Mix: %s", config.threads, config.duration, config.mix)
+		if config.rate > 0 {
+			log.Printf("Rate: %d ops/sec", config.rate)
+		}
+		if config.profile != nil {
+			log.Printf("Load profile: %s", config.profile)
+		}
+		log.Printf("Read preference: %s, causal consistency: %v", config.readPreference.Mode(), config.causal)
+		if config.sessions > 0 {
+			log.Printf("Sessions: %d shared by %d threads", config.sessions, config.threads)
+		}
+		if config.touchRate > 0 {
+			log.Printf("Touching updated_at on %d documents/sec", config.touchRate)
+		}
+		if len(config.schedule) > 0 {
+			log.Printf("Mix schedule: %s", config.schedule)
+		}
+		if len(config.updateFields) > 0 {
+			log.Printf("Updates set only %s", strings.Join(config.updateFields, ", "))
+		}
+		if !config.projection.IsFull() {
+			log.Printf("Read projection: %s", config.projection)
+		}
+	}
+
+	for _, op := range append(config.mix.Ops(), config.schedule.Ops()...) {
+		if op == workload.OpLookup && meta.OrdersCollection == "" {
+			return fmt.Errorf("lookup operations need a run loaded with --orders-collection")
+		}
+		if op == workload.OpPush && model.PushField(meta.Schema.Template) == "" {
+			return fmt.Errorf("push operations are not supported by the %s template", meta.Schema.Template)
+		}
+		if (op == workload.OpText || op == workload.OpSearch) && model.TextFields(meta.Schema.Template) == nil {
+			return fmt.Errorf("%s operations are not supported by the %s template", strings.ToLower(op), meta.Schema.Template)
+		}
+	}
+	if err := model.ValidateUpdateFields(meta.Schema.Template, config.updateFields); err != nil {
+		return fmt.Errorf("--update-fields: %w", err)
+	}
+	if err := config.projection.Validate(meta.Schema); err != nil {
+		return fmt.Errorf("--read-projection: %w", err)
+	}
+
+	textFields := model.TextFields(meta.Schema.Template)
+	if config.textIndex {
+		if textFields == nil {
+			return fmt.Errorf("the %s template has no text fields to index", meta.Schema.Template)
+		}
+		if config.verbose {
+			log.Printf("Creating text index on %s", strings.Join(textFields, ", "))
+		}
+		if err := mongo.EnsureTextIndex(ctx, db.Collection(config.collectionName), textFields); err != nil {
+			return err
+		}
+	}
+	if config.searchIndexFile != "" {
+		definition, err := mongo.ReadSearchIndex(config.searchIndexFile)
+		if err != nil {
+			return err
+		}
+		start := time.Now()
+		err = mongo.EnsureSearchIndex(ctx, db.Collection(config.collectionName), config.searchIndexName, definition, func(status string) {
+			fmt.Fprintf(console, "\r[Search index %s: %s, %v]", config.searchIndexName, status, time.Since(start).Round(time.Second))
+		})
+		fmt.Fprintln(console)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Inserts generate documents matching the original run's size and fields
+	generator := model.NewGeneratorWithOptions(meta.DocumentSize, model.Options{
+		PaddingMode: config.paddingMode,
+		Template:    meta.Schema.Template,
+		BSONTypes:   slices.Contains(meta.Schema.Fields, "bson_types"),
+	})
+
+	runner := workload.NewRunner(workload.Config{
+		Collection: mongo.WorkloadCollection(db, config.collectionName, config.readPreference, config.causal),
+		Schema:     meta.Schema,
+		Threads:    config.threads,
+		Duration:   config.duration,
+		Mix:        config.mix,
+		Schedule:   config.schedule,
+		Generator:  generator,
+		LookupFrom: meta.OrdersCollection,
+		KeySpace:   keySpace,
+		TouchRate:  config.touchRate,
+		Rate:       config.rate,
+		BulkWrite:  config.bulkWrite,
+		Causal:     config.causal,
+		Sessions:   config.sessions,
+		Profile:    config.profile,
+		YCSBLogger: ycsbLogger,
+
+		TextFields:   textFields,
+		SearchIndex:  config.searchIndexName,
+		UpdateFields: config.updateFields,
+		PushCap:      config.pushCap,
+		Projection:   config.projection,
+		ScanLength:   config.scanLength,
+		ThinkTime:    config.thinkTime,
+
+		InsertTimeout:    config.insertTimeout,
+		QueryTimeout:     config.queryTimeout,
+		AggregateTimeout: config.aggregateTimeout,
+
+		RYOWSample:     config.ryowSample,
+		RYOWCollection: mongo.WorkloadCollection(db, config.collectionName, config.ryowPref, false),
+		RYOWTimeout:    config.ryowTimeout,
+	})
+
+	done := make(chan struct{})
+	go reportWorkloadProgress(runner, done)
+	err = runner.Run(ctx)
+	close(done)
+
+	stats := runner.GetStats()
+	elapsed := time.Since(stats.StartTime)
+	fmt.Fprintf(console, "\n\n=== Final Statistics ===\n")
+	fmt.Fprintf(console, "Total time: %v\n", elapsed.Round(time.Second))
+	fmt.Fprintf(console, "Operations: %d\n", stats.Operations)
+	fmt.Fprintf(console, "Failed operations: %d\n", stats.FailedOperations)
+	fmt.Fprintf(console, "Timed out operations: %d\n", stats.TimedOut)
+	if config.touchRate > 0 {
+		fmt.Fprintf(console, "Documents touched: %d\n", stats.Touched)
+	}
+	if stats.EmptySearches > 0 {
+		fmt.Fprintf(console, "Searches without results: %d\n", stats.EmptySearches)
+	}
+	if config.sessions > 0 {
+		fmt.Fprintf(console, "Sessions: %d, operations that waited for a free session: %d\n", config.sessions, stats.SessionWaits)
+	}
+	if config.ryowSample > 0 {
+		printRYOW(console, stats.RYOW, config.ryowTimeout)
+	}
+	fmt.Fprintf(console, "Average rate: %.2f ops/sec\n", stats.OpsPerSecond)
+	result.RunID = meta.RunID
+	result.setWorkload(stats)
+
+	if err == context.Canceled {
+		return nil
+	}
+	return err
+}
+
+// reportWorkloadProgress periodically reports workload progress
+func reportWorkloadProgress(runner *workload.Runner, done chan struct{}) {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			stats := runner.GetStats()
+			fmt.Fprintf(console, "\r[Ops: %d, %.2f ops/sec] [Failed: %d, Timed out: %d] [Mix: %s]",
+				stats.Operations, stats.OpsPerSecond, stats.FailedOperations, stats.TimedOut, runner.CurrentMix())
+		}
+	}
+}
+
+// loadRunMetadata loads the metadata of an earlier run with a short-lived client
+func loadRunMetadata(connectionString, databaseName, collectionName, runID string) (*mongo.RunMetadata, error) {
+	client, err := mongo.Connect(connectionString, 1)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Disconnect(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return mongo.LoadRunMetadata(ctx, client.Database(databaseName), collectionName, runID)
+}
+
+// steadyStateConfig holds settings for the mutation phase that follows a load
+type steadyStateConfig struct {
+	threads       int
+	duration      time.Duration // 0 = until interrupted
+	rate          int           // Operations per second (0 = unlimited)
+	mix           workload.Mix
+	insertTimeout time.Duration
+	queryTimeout  time.Duration
+	aggTimeout    time.Duration
+	bulkWrite     bool
+	updateFields  []string // Fields updates set (nil = template fields, see Generator.FieldUpdate)
+	pushCap       int      // Elements pushes keep in the array (0 = model.PushCap)
+	scanLength    model.CountRange
+	thinkTime     pacing.ThinkTime // Pause of each thread after each operation
+}
+
+// runSteadyState keeps mutating the documents of a finished load, targeting
+// them by the keys of its key space, until the duration elapses or the run is
+// interrupted. Updates and pushes come from the load's generator, so they
+// match the documents' template and options.
+func runSteadyState(ctx context.Context, writer *mongo.Writer, meta *mongo.RunMetadata, generator *model.Generator,
+	config steadyStateConfig, ycsbLogger *logger.YCSBLogger, out io.Writer) (workload.Stats, error) {
+	if err := mongo.EnsureKeyIndex(ctx, writer.Collection(), meta.Schema.KeyField); err != nil {
+		return workload.Stats{}, err
+	}
+
+	log.Printf("Steady state: mutating %d documents at %s, mix %s", meta.KeySpace.Count, steadyStateRate(config.rate), config.mix)
+	if config.duration > 0 {
+		log.Printf("Steady state ends after %v", config.duration)
+	} else {
+		log.Printf("Steady state runs until interrupted")
+	}
+
+	keySpace := meta.KeySpace
+	runner := workload.NewRunner(workload.Config{
+		Collection:    writer.Collection(),
+		Schema:        meta.Schema,
+		Threads:       config.threads,
+		Duration:      config.duration,
+		Mix:           config.mix,
+		Generator:     generator,
+		KeySpace:      &keySpace,
+		Rate:          config.rate,
+		FieldUpdates:  true,
+		UpdateFields:  config.updateFields,
+		PushCap:       config.pushCap,
+		ScanLength:    config.scanLength,
+		ThinkTime:     config.thinkTime,
+		BulkWrite:     config.bulkWrite,
+		YCSBLogger:    ycsbLogger,
+		InsertTimeout: config.insertTimeout,
+
+		QueryTimeout:     config.queryTimeout,
+		AggregateTimeout: config.aggTimeout,
+	})
+
+	done := make(chan struct{})
+	go reportWorkloadProgress(runner, done)
+	err := runner.Run(ctx)
+	close(done)
+
+	stats := runner.GetStats()
+	fmt.Fprintf(out, "\n\n=== Steady-State Statistics ===\n")
+	fmt.Fprintf(out, "Total time: %v\n", time.Since(stats.StartTime).Round(time.Second))
+	fmt.Fprintf(out, "Operations: %d\n", stats.Operations)
+	fmt.Fprintf(out, "Failed operations: %d\n", stats.FailedOperations)
+	fmt.Fprintf(out, "Timed out operations: %d\n", stats.TimedOut)
+	fmt.Fprintf(out, "Average rate: %.2f ops/sec\n", stats.OpsPerSecond)
+
+	if err == context.Canceled {
+		return stats, nil
+	}
+	return stats, err
+}
+
+// steadyStateRate formats a steady-state operation rate
+func steadyStateRate(rate int) string {
+	if rate <= 0 {
+		return "full speed"
+	}
+	return fmt.Sprintf("%d ops/sec", rate)
+}
+
+// printRYOW reports how often sampled inserts were not yet visible on a
+// secondary; their lag is recorded as RYOW in the YCSB log
+func printRYOW(out io.Writer, stats workload.RYOWStats, timeout time.Duration) {
+	fmt.Fprintf(out, "Read-your-own-write checks: %d, %d (%.2f%%) not visible at first read, %d not visible within %v\n",
+		stats.Checks, stats.Stale, stats.StaleRatio()*100, stats.Invisible, timeout)
+	if stats.Failed > 0 || stats.Skipped > 0 {
+		fmt.Fprintf(out, "Read-your-own-write checks failed: %d, skipped while behind: %d\n", stats.Failed, stats.Skipped)
+	}
+}
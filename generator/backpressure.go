@@ -0,0 +1,49 @@
+package generator
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Adaptive throttling watermarks, as fractions of the buffer capacity
+const (
+	throttleHighWatermark = 0.9
+	throttleLowWatermark  = 0.5
+	throttlePollInterval  = 10 * time.Millisecond
+)
+
+// throttle pauses a worker while the writers fall behind: once the buffer
+// reaches the high watermark, the worker waits until it drains below the low
+// watermark instead of generating documents that would only queue up
+func (s *Service) throttle(ctx context.Context) error {
+	capacity := cap(s.docChan)
+	if !s.adaptive || float64(len(s.docChan)) < throttleHighWatermark*float64(capacity) {
+		return nil
+	}
+
+	start := time.Now()
+	defer func() { atomic.AddInt64(&s.throttledNanos, int64(time.Since(start))) }()
+
+	ticker := time.NewTicker(throttlePollInterval)
+	defer ticker.Stop()
+	for float64(len(s.docChan)) > throttleLowWatermark*float64(capacity) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// recordBufferDepth tracks the peak number of documents waiting in the buffer
+func (s *Service) recordBufferDepth() {
+	depth := int64(len(s.docChan))
+	for {
+		peak := atomic.LoadInt64(&s.peakBufferDepth)
+		if depth <= peak || atomic.CompareAndSwapInt64(&s.peakBufferDepth, peak, depth) {
+			return
+		}
+	}
+}
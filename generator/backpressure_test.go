@@ -0,0 +1,68 @@
+package generator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+)
+
+func TestAdaptiveBufferThrottlesWorkers(t *testing.T) {
+	service := NewService(Config{
+		DocumentSize:   model.Size2KB,
+		WorkerCount:    2,
+		BufferDocs:     10,
+		AdaptiveBuffer: true,
+		TargetBytes:    int64(model.Size2KB) * 1000,
+	})
+
+	// Nothing consumes the buffer, as if the writers had stalled
+	ctx, cancel := context.WithCancel(context.Background())
+	errChan := make(chan error, 1)
+	go func() { errChan <- service.Generate(ctx) }()
+	time.Sleep(300 * time.Millisecond)
+	cancel()
+	<-errChan
+
+	stats := service.GetStats()
+	if stats.BufferCapacity != 10 {
+		t.Errorf("expected buffer capacity 10, got %d", stats.BufferCapacity)
+	}
+	if stats.PeakBufferDepth < 9 {
+		t.Errorf("expected the buffer to reach the high watermark, peak depth %d", stats.PeakBufferDepth)
+	}
+	if stats.ThrottledTime <= 0 {
+		t.Error("expected workers to be throttled while the buffer was full")
+	}
+	if stats.DocumentsGenerated > 10 {
+		t.Errorf("expected generation to stop at the buffer capacity, generated %d", stats.DocumentsGenerated)
+	}
+}
+
+func TestStopClosesChannelAfterWorkers(t *testing.T) {
+	service := NewService(Config{
+		DocumentSize: model.Size2KB,
+		WorkerCount:  4,
+		BufferDocs:   4,
+		TargetBytes:  int64(model.Size2KB) * 1000000,
+	})
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- service.Generate(context.Background()) }()
+
+	// Stop while workers are blocked on the full buffer, then drain it
+	time.Sleep(100 * time.Millisecond)
+	service.Stop()
+	received := 0
+	for range service.Documents() {
+		received++
+	}
+
+	if err := <-errChan; err != nil {
+		t.Fatalf("Generate returned %v", err)
+	}
+	if generated := service.GetStats().DocumentsGenerated; int64(received) != generated {
+		t.Errorf("received %d documents, generated %d", received, generated)
+	}
+}
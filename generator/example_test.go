@@ -0,0 +1,66 @@
+package generator_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/meticulous-dft/mongodb-data-generator/generator"
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"github.com/meticulous-dft/mongodb-data-generator/sink"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// A model.Generator produces single documents, e.g. fixtures for a test
+func Example() {
+	g := model.NewGeneratorWithOptions(model.Size4KB, model.Options{Template: model.TemplateProduct})
+	doc, err := g.GenerateDocument()
+	if err != nil {
+		log.Fatal(err)
+	}
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var fields bson.M
+	if err := bson.Unmarshal(data, &fields); err != nil {
+		log.Fatal(err)
+	}
+	key := g.Schema().KeyField
+	fmt.Println(key, fields[key] != nil)
+	// Output: sku true
+}
+
+// A Service generates documents concurrently into any sink.Sink, here one
+// that encodes them as a writer would
+func ExampleService_Run() {
+	service := generator.NewService(generator.Config{
+		DocumentSize: model.Size2KB,
+		WorkerCount:  4,
+		TargetBytes:  1 << 20, // About 512 documents
+		Template:     model.TemplateTelemetry,
+	})
+	service.Use(generator.RunTag("example"))
+
+	var mu sync.Mutex
+	var bytes int
+	err := service.Run(context.Background(), sink.Func(func(ctx context.Context, docs []model.Document) error {
+		for _, doc := range docs {
+			data, err := bson.Marshal(doc)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			bytes += len(data)
+			mu.Unlock()
+		}
+		return nil
+	}))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(bytes > 0)
+	// Output: true
+}
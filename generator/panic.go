@@ -0,0 +1,15 @@
+package generator
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// recoverPanic converts a panic in a worker goroutine into an error, so the
+// run can stop in an orderly way and flush its statistics.
+// Use as: defer recoverPanic(&err)
+func recoverPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+	}
+}
@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+)
+
+// PostProcessor modifies each generated document before it is handed to the
+// writer, e.g. to inject tenant IDs, sign documents, or mask fields.
+// Processors run concurrently on the generator workers and must be safe for
+// concurrent use. Returning an error stops generation.
+type PostProcessor interface {
+	Process(doc model.Document) error
+}
+
+// PostProcessorFunc adapts an ordinary function to a PostProcessor
+type PostProcessorFunc func(doc model.Document) error
+
+// Process calls f(doc)
+func (f PostProcessorFunc) Process(doc model.Document) error {
+	return f(doc)
+}
+
+// TenantID returns a post-processor that sets metadata.tenant_id to one of
+// tenants, chosen uniformly at random
+func TenantID(tenants []string) PostProcessor {
+	var mu sync.Mutex
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	return PostProcessorFunc(func(doc model.Document) error {
+		mu.Lock()
+		tenant := tenants[rng.Intn(len(tenants))]
+		mu.Unlock()
+
+		doc.SetMetadata("tenant_id", tenant)
+		return nil
+	})
+}
+
+// RunTag returns a post-processor that sets metadata.run_id, so that the
+// documents of one run can later be removed without dropping the collection
+func RunTag(runID string) PostProcessor {
+	return PostProcessorFunc(func(doc model.Document) error {
+		doc.SetMetadata("run_id", runID)
+		return nil
+	})
+}
+
+// GeneratedAt returns a post-processor that sets metadata.generated_at to
+// the time each document was generated
+func GeneratedAt() PostProcessor {
+	return PostProcessorFunc(func(doc model.Document) error {
+		doc.SetMetadata("generated_at", time.Now())
+		return nil
+	})
+}
@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestPostProcessorsApplied(t *testing.T) {
+	service := NewService(Config{
+		DocumentSize: model.Size2KB,
+		WorkerCount:  2,
+		BatchSize:    10,
+		TargetBytes:  int64(model.Size2KB) * 20,
+	})
+	service.Use(
+		TenantID([]string{"acme"}),
+		PostProcessorFunc(func(doc model.Document) error {
+			customer := doc.(*model.CustomerDocument)
+			customer.Tags = append(customer.Tags, "processed")
+			return nil
+		}),
+	)
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- service.Generate(context.Background()) }()
+
+	count := 0
+	for generated := range service.Documents() {
+		count++
+		doc := generated.(*model.CustomerDocument)
+		if doc.Metadata["tenant_id"] != "acme" {
+			t.Errorf("expected tenant_id acme, got %v", doc.Metadata["tenant_id"])
+		}
+		if len(doc.Tags) == 0 || doc.Tags[len(doc.Tags)-1] != "processed" {
+			t.Errorf("expected processors to run in order, got tags %v", doc.Tags)
+		}
+	}
+	if err := <-errChan; err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if count == 0 {
+		t.Fatal("expected documents to be generated")
+	}
+}
+
+func TestPostProcessorErrorStopsGeneration(t *testing.T) {
+	service := NewService(Config{
+		DocumentSize: model.Size2KB,
+		WorkerCount:  1,
+		TargetBytes:  int64(model.Size2KB) * 20,
+	})
+	errSigning := errors.New("signing key unavailable")
+	service.Use(PostProcessorFunc(func(doc model.Document) error {
+		return errSigning
+	}))
+
+	if err := service.Generate(context.Background()); !errors.Is(err, errSigning) {
+		t.Fatalf("expected post-processor error, got %v", err)
+	}
+}
+
+func TestRunTagPropagatesToOrders(t *testing.T) {
+	doc, err := model.NewGenerator(model.Size4KB).Generate()
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if err := RunTag("20250101-120000").Process(doc); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if doc.Metadata["run_id"] != "20250101-120000" {
+		t.Errorf("expected metadata.run_id to be set, got %v", doc.Metadata["run_id"])
+	}
+	for _, order := range model.OrderDocuments(doc) {
+		if runID := order.(*model.OrderDocument).RunID; runID != "20250101-120000" {
+			t.Errorf("expected order run_id 20250101-120000, got %q", runID)
+		}
+	}
+}
+
+func TestPostProcessorsTagProducts(t *testing.T) {
+	doc, err := model.NewGeneratorWithOptions(model.Size4KB, model.Options{Template: model.TemplateProduct}).GenerateDocument()
+	if err != nil {
+		t.Fatalf("GenerateDocument failed: %v", err)
+	}
+	if err := RunTag("20250101-120000").Process(doc); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if runID := doc.(*model.ProductDocument).Metadata["run_id"]; runID != "20250101-120000" {
+		t.Errorf("expected metadata.run_id to be set, got %v", runID)
+	}
+}
+
+func TestRunTagWithPatchSizing(t *testing.T) {
+	doc, err := model.NewGeneratorWithOptions(model.Size4KB, model.Options{PaddingSizing: model.PaddingSizingPatch}).GenerateDocument()
+	if err != nil {
+		t.Fatalf("GenerateDocument failed: %v", err)
+	}
+	for _, processor := range []PostProcessor{RunTag("20250101-120000"), GeneratedAt()} {
+		if err := processor.Process(doc); err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+	}
+
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if runID, _ := bson.Raw(data).LookupErr("metadata", "run_id"); runID.StringValue() != "20250101-120000" {
+		t.Errorf("expected metadata.run_id in the encoding, got %v", runID)
+	}
+	if _, err := bson.Raw(data).LookupErr("metadata", "generated_at"); err != nil {
+		t.Errorf("expected metadata.generated_at in the encoding: %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+package generator
+
+import (
+	"context"
+
+	"github.com/meticulous-dft/mongodb-data-generator/sink"
+	"golang.org/x/sync/errgroup"
+)
+
+// Run generates documents into s until the target is reached and s has
+// written them, or either fails. Documents are written in batches of
+// BatchSize by Writers concurrent writers, see sink.Run. A sink that returns
+// early without an error stops generation. Run does not close s.
+func (s *Service) Run(ctx context.Context, to sink.Sink) error {
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		return s.Generate(ctx)
+	})
+	eg.Go(func() error {
+		if err := sink.Run(ctx, to, s.Documents(), s.writers, s.batchSize); err != nil {
+			return err
+		}
+		// Unblock workers still sending until they see the stop
+		s.Stop()
+		for range s.Documents() {
+		}
+		return nil
+	})
+	return eg.Wait()
+}
@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"github.com/meticulous-dft/mongodb-data-generator/sink"
+)
+
+func TestRun(t *testing.T) {
+	service := NewService(Config{
+		DocumentSize: model.Size2KB,
+		WorkerCount:  4,
+		BatchSize:    10,
+		Writers:      3,
+		TargetBytes:  int64(model.Size2KB) * 50,
+	})
+
+	var largest int64
+	to := sink.Func(func(ctx context.Context, docs []model.Document) error {
+		for {
+			seen := atomic.LoadInt64(&largest)
+			if int64(len(docs)) <= seen || atomic.CompareAndSwapInt64(&largest, seen, int64(len(docs))) {
+				return nil
+			}
+		}
+	})
+	if err := service.Run(context.Background(), to); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	written := to.Stats().DocumentsWritten
+	if written == 0 || written != service.GetStats().DocumentsGenerated {
+		t.Errorf("wrote %d documents, generated %d", written, service.GetStats().DocumentsGenerated)
+	}
+	if largest > 10 {
+		t.Errorf("expected batches of at most 10 documents, got %d", largest)
+	}
+}
+
+func TestRunStopsWithSink(t *testing.T) {
+	service := NewService(Config{
+		DocumentSize: model.Size2KB,
+		WorkerCount:  4,
+		BatchSize:    5,
+		TargetBytes:  1 << 40,
+	})
+
+	// A sink that wants only a few documents ends an unbounded run
+	var batches int
+	err := service.Run(context.Background(), streamer{sink.Func(func(ctx context.Context, docs []model.Document) error {
+		batches++
+		return nil
+	})})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if batches != 0 {
+		t.Errorf("expected the streamer to take over batching, got %d batches", batches)
+	}
+}
+
+// streamer reads five documents itself, then returns
+type streamer struct {
+	sink.Sink
+}
+
+func (s streamer) Write(ctx context.Context, docs <-chan model.Document) error {
+	for i := 0; i < 5; i++ {
+		<-docs
+	}
+	return nil
+}
@@ -0,0 +1,399 @@
+// Package generator runs the document generator concurrently: a Service
+// generates documents of a model.Generator on several workers, applies
+// post-processors, and hands them through a buffered channel to a sink.Sink,
+// such as the gendata MongoDB writer, until a target size is reached. Other
+// Go programs can use it to produce realistic data in their own tests
+// without running the gendata command.
+package generator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"golang.org/x/sync/errgroup"
+)
+
+// Service handles document generation with high concurrency
+type Service struct {
+	docGenerator *model.Generator
+	workerCount  int
+	batchSize    int
+	writers      int
+	docChan      chan model.Document
+	targetBytes  int64
+	bytesGenerated int64
+	docsGenerated   int64
+	mu              sync.RWMutex
+	startTime       time.Time
+	postProcessors  []PostProcessor
+	source          Source
+	adaptive        bool
+	exactSize       bool
+	throttledNanos  int64
+	peakBufferDepth int64
+	stopped         int32
+}
+
+// Config holds generator service configuration
+type Config struct {
+	DocumentSize DocumentSize
+	WorkerCount  int
+	BatchSize    int
+	TargetBytes  int64
+	PaddingMode  model.PaddingMode
+
+	// PaddingSizing selects how padding is fitted, see model.PaddingSizing
+	PaddingSizing model.PaddingSizing
+
+	// ExactSize pads documents to within model.SizeTolerance bytes of
+	// DocumentSize, calibrated for the bytes PostProcessors add, and
+	// StrictSize fails documents whose content exceeds it
+	ExactSize  bool
+	StrictSize bool
+
+	// Writers is the number of concurrent writers of Run (default 1)
+	Writers int
+
+	// BufferDocs is the capacity of the channel to the writers (0 = 2x BatchSize)
+	BufferDocs int
+
+	// AdaptiveBuffer throttles generation workers while the buffer is nearly full
+	AdaptiveBuffer bool
+
+	// KeySpace determines customer and product keys (zero = new random seed)
+	KeySpace model.KeySpace
+
+	// Checksum embeds a checksum of the canonical fields in every document
+	Checksum bool
+
+	// BSONTypes adds a value of every BSON type to every document
+	BSONTypes bool
+
+	// Orders, LineItems, and NestingDepth shape documents independently of
+	// their size, see model.Options
+	Orders       model.CountRange
+	LineItems    model.CountRange
+	NestingDepth int
+
+	// Cardinality limits the distinct values of fields
+	Cardinality model.Cardinality
+
+	// Sparsity makes fields missing or null in some documents
+	Sparsity model.Sparsity
+
+	// FieldValues draw fields from weighted lists of values
+	FieldValues model.FieldValues
+
+	// FieldTemplates derive fields from other fields
+	FieldTemplates model.FieldTemplates
+
+	// LegacyFraction of documents is generated in the legacy schema
+	LegacyFraction float64
+
+	// Locales of customer names, addresses, and notes, picked per document
+	// (model.LocaleDefault by default)
+	Locales []string
+
+	// CoherentAddresses places each customer's addresses in one country, in
+	// cities with their regions and postal codes, with a matching phone
+	// number and order currency
+	CoherentAddresses bool
+
+	// NoPII replaces names, emails, phones, and card numbers with synthetic
+	// tokens of the same size
+	NoPII bool
+
+	// Template selects the document model (model.TemplateCustomer by default)
+	Template string
+
+	// TimeRange is the time range timestamps are spread over (zero = the
+	// 30 days before now) across ExpectedDocuments documents
+	TimeRange         model.TimeRange
+	ExpectedDocuments int64
+
+	// OrderedTimes spreads created_at over TimeRange in generation order
+	OrderedTimes bool
+
+	// TimeDistribution spreads timestamps over their range
+	TimeDistribution model.TimeDistribution
+
+	// PostProcessors are applied in order to every generated document
+	PostProcessors []PostProcessor
+
+	// Source, if set, supplies the documents instead of the generator, e.g.
+	// a dataset to import; generation ends when it runs out
+	Source Source
+}
+
+// DocumentSize is an alias for model.DocumentSize
+type DocumentSize = model.DocumentSize
+
+// NewService creates a new generator service
+func NewService(config Config) *Service {
+	if config.WorkerCount <= 0 {
+		config.WorkerCount = 10 // Default to 10 workers
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 1000 // Default batch size
+	}
+	if config.BufferDocs <= 0 {
+		config.BufferDocs = config.BatchSize * 2
+	}
+	
+	docGenerator := model.NewGeneratorWithOptions(config.DocumentSize, model.Options{
+		PaddingMode: config.PaddingMode,
+		KeySpace:    config.KeySpace,
+		Checksum:    config.Checksum,
+		BSONTypes:   config.BSONTypes,
+
+		Orders:       config.Orders,
+		LineItems:    config.LineItems,
+		NestingDepth: config.NestingDepth,
+		Cardinality:  config.Cardinality,
+		Sparsity:     config.Sparsity,
+
+		FieldValues:    config.FieldValues,
+		FieldTemplates: config.FieldTemplates,
+		LegacyFraction: config.LegacyFraction,
+		Locales:        config.Locales,
+		NoPII:          config.NoPII,
+		Template:    config.Template,
+
+		CoherentAddresses: config.CoherentAddresses,
+		PaddingSizing:     config.PaddingSizing,
+
+		ExactSize:  config.ExactSize,
+		StrictSize: config.StrictSize,
+
+		TimeRange:         config.TimeRange,
+		ExpectedDocuments: config.ExpectedDocuments,
+		OrderedTimes:      config.OrderedTimes,
+		TimeDistribution:  config.TimeDistribution,
+	})
+	
+	return &Service{
+		docGenerator: docGenerator,
+		workerCount:  config.WorkerCount,
+		batchSize:    config.BatchSize,
+		writers:      config.Writers,
+		docChan:      make(chan model.Document, config.BufferDocs),
+		targetBytes:  config.TargetBytes,
+		startTime:    time.Now(),
+		postProcessors: config.PostProcessors,
+		source:         config.Source,
+		adaptive:       config.AdaptiveBuffer,
+		exactSize:      config.ExactSize,
+	}
+}
+
+// Use registers post-processors applied (after those already registered) to
+// every generated document. It must be called before Generate.
+func (s *Service) Use(processors ...PostProcessor) {
+	s.postProcessors = append(s.postProcessors, processors...)
+}
+
+// Stop ends generation as if the target was reached: workers stop and the
+// channel is closed, so writers drain the buffered documents and finish
+func (s *Service) Stop() {
+	atomic.StoreInt32(&s.stopped, 1)
+}
+
+// done reports whether the target was reached or generation was stopped
+func (s *Service) done() bool {
+	return atomic.LoadInt32(&s.stopped) == 1 || atomic.LoadInt64(&s.bytesGenerated) >= s.targetBytes
+}
+
+// Generate starts generating documents and sends them to the channel
+func (s *Service) Generate(ctx context.Context) error {
+	if s.exactSize {
+		if err := s.docGenerator.Calibrate(s.process); err != nil {
+			return fmt.Errorf("failed to calibrate document size: %w", err)
+		}
+	}
+	eg, ctx := errgroup.WithContext(ctx)
+	
+	// Start worker goroutines
+	var workers sync.WaitGroup
+	for i := 0; i < s.workerCount; i++ {
+		workerID := i
+		workers.Add(1)
+		eg.Go(func() (err error) {
+			defer workers.Done()
+			defer recoverPanic(&err)
+			return s.worker(ctx, workerID)
+		})
+	}
+	workersDone := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(workersDone)
+	}()
+	
+	// Monitor the buffer and close the channel once every worker stopped at
+	// the target, so that no worker is still sending on it
+	eg.Go(func() error {
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-workersDone:
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				close(s.docChan)
+				return nil
+			case <-ticker.C:
+				s.recordBufferDepth()
+			}
+		}
+	})
+	
+	// Wait for all workers to complete
+	return eg.Wait()
+}
+
+// worker generates documents and sends them to the channel
+func (s *Service) worker(ctx context.Context, workerID int) error {
+	for {
+		// Check if we've reached target
+		if s.done() {
+			return nil
+		}
+		
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			if err := s.throttle(ctx); err != nil {
+				return err
+			}
+
+			// Generate document, or read it from the source
+			doc, docSize, err := s.nextDocument()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			
+			// Check again before sending
+			currentBytes := atomic.LoadInt64(&s.bytesGenerated)
+			if currentBytes+docSize > s.targetBytes {
+				// We're close to target, don't send this one
+				return nil
+			}
+			
+			// Send document to channel (non-blocking check first)
+			select {
+			case s.docChan <- doc:
+				atomic.AddInt64(&s.bytesGenerated, docSize)
+				atomic.AddInt64(&s.docsGenerated, 1)
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// process applies the post-processors to doc
+func (s *Service) process(doc model.Document) error {
+	for _, p := range s.postProcessors {
+		if err := p.Process(doc); err != nil {
+			return fmt.Errorf("post-processor failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// Next generates one more document outside of Generate, processed like the
+// generated ones, e.g. to replace deleted documents after the load
+func (s *Service) Next() (model.Document, error) {
+	doc, err := s.docGenerator.GenerateDocument()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.process(doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Schema returns the schema of the generated documents
+func (s *Service) Schema() model.Schema {
+	return s.docGenerator.Schema()
+}
+
+// Generator returns the document generator, for updates matching the
+// generated documents
+func (s *Service) Generator() *model.Generator {
+	return s.docGenerator
+}
+
+// KeySpace returns the key space with the customer keys issued so far, empty
+// when the documents come from a Source
+func (s *Service) KeySpace() model.KeySpace {
+	if s.source != nil {
+		return model.KeySpace{}
+	}
+	return s.docGenerator.KeySpace()
+}
+
+// Documents returns the channel for consuming generated documents
+func (s *Service) Documents() <-chan model.Document {
+	return s.docChan
+}
+
+// GetStats returns current statistics
+func (s *Service) GetStats() Stats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	now := time.Now()
+	docs := atomic.LoadInt64(&s.docsGenerated)
+	bytes := atomic.LoadInt64(&s.bytesGenerated)
+	
+	elapsed := now.Sub(s.startTime).Seconds()
+	var docsPerSec, bytesPerSec float64
+	if elapsed > 0 {
+		docsPerSec = float64(docs) / elapsed
+		bytesPerSec = float64(bytes) / elapsed
+	}
+	
+	return Stats{
+		DocumentsGenerated: docs,
+		BytesGenerated:     bytes,
+		DocumentsPerSecond: docsPerSec,
+		BytesPerSecond:     bytesPerSec,
+		BufferDepth:        len(s.docChan),
+		BufferCapacity:     cap(s.docChan),
+		PeakBufferDepth:    int(atomic.LoadInt64(&s.peakBufferDepth)),
+		ThrottledTime:      time.Duration(atomic.LoadInt64(&s.throttledNanos)),
+		OversizedDocuments: s.docGenerator.Oversized(),
+		StartTime:          s.startTime,
+		LastUpdate:         now,
+	}
+}
+
+// Stats represents generation statistics
+type Stats struct {
+	DocumentsGenerated int64
+	BytesGenerated     int64
+	DocumentsPerSecond float64
+	BytesPerSecond     float64
+	BufferDepth        int           // Documents waiting for the writers
+	BufferCapacity     int
+	PeakBufferDepth    int           // Highest depth sampled (every 100ms)
+	ThrottledTime      time.Duration // Worker time paused by adaptive throttling
+	OversizedDocuments int64         // Larger than DocumentSize before padding (ExactSize)
+	StartTime          time.Time
+	LastUpdate         time.Time
+}
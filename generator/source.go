@@ -0,0 +1,33 @@
+package generator
+
+import "github.com/meticulous-dft/mongodb-data-generator/model"
+
+// Source supplies documents to a Service in place of its generator, e.g. a
+// previously generated dataset. Next returns a document with its size in
+// bytes, or io.EOF when there are no more; it is called concurrently by the
+// workers.
+type Source interface {
+	Next() (model.Document, int64, error)
+}
+
+// nextDocument returns the next document, processed, and its size: read
+// from the source, or generated and estimated at the target size (the actual
+// size is only known once it is marshaled)
+func (s *Service) nextDocument() (model.Document, int64, error) {
+	var doc model.Document
+	var size int64
+	var err error
+	if s.source != nil {
+		doc, size, err = s.source.Next()
+	} else {
+		doc, err = s.docGenerator.GenerateDocument()
+		size = int64(s.docGenerator.TargetSize())
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := s.process(doc); err != nil {
+		return nil, 0, err
+	}
+	return doc, size, nil
+}
@@ -0,0 +1,53 @@
+package generator
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"github.com/meticulous-dft/mongodb-data-generator/sink"
+)
+
+// countedSource supplies n documents of a generator, then io.EOF
+type countedSource struct {
+	mu        sync.Mutex
+	generator *model.Generator
+	n         int
+}
+
+func (s *countedSource) Next() (model.Document, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.n == 0 {
+		return nil, 0, io.EOF
+	}
+	s.n--
+	doc, err := s.generator.GenerateDocument()
+	return doc, 100, err
+}
+
+func TestRunFromSource(t *testing.T) {
+	service := NewService(Config{
+		DocumentSize: model.Size2KB,
+		WorkerCount:  4,
+		BatchSize:    10,
+		TargetBytes:  1 << 40,
+		Source:       &countedSource{generator: model.NewGenerator(model.Size1KB), n: 25},
+	})
+
+	to := sink.Func(func(ctx context.Context, docs []model.Document) error { return nil })
+	if err := service.Run(context.Background(), to); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if stats := service.GetStats(); stats.DocumentsGenerated != 25 || stats.BytesGenerated != 2500 {
+		t.Errorf("expected the 25 documents of the source, got %d (%d bytes)", stats.DocumentsGenerated, stats.BytesGenerated)
+	}
+	if written := to.Stats().DocumentsWritten; written != 25 {
+		t.Errorf("wrote %d documents, expected 25", written)
+	}
+	if !service.KeySpace().IsZero() {
+		t.Errorf("expected no key space for documents from a source")
+	}
+}
@@ -0,0 +1,79 @@
+package artifacts
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBundle(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "20250101-120000")
+	if err := os.MkdirAll(filepath.Join(dir, "diagnostics"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"summary.txt":                   "=== Final Statistics ===\n",
+		"diagnostics/serverStatus.json": "{}",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := dir + ".tar.gz"
+	if err := Bundle(dir, out); err != nil {
+		t.Fatalf("Bundle failed: %v", err)
+	}
+
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+
+	found := make(map[string]string)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			content, _ := io.ReadAll(tr)
+			found[header.Name] = string(content)
+		}
+	}
+
+	for name, content := range files {
+		if got := found["20250101-120000/"+name]; got != content {
+			t.Errorf("%s: expected %q, got %q", name, content, got)
+		}
+	}
+}
+
+func TestParseS3URL(t *testing.T) {
+	bucket, prefix, err := ParseS3URL("s3://benchmarks/runs/gendata/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bucket != "benchmarks" || prefix != "runs/gendata" {
+		t.Errorf("expected benchmarks, runs/gendata; got %s, %s", bucket, prefix)
+	}
+
+	for _, invalid := range []string{"benchmarks/runs", "s3://", "s3:///runs"} {
+		if _, _, err := ParseS3URL(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Package bench runs a suite of analytical aggregation pipelines against a
+// generated collection and reports their latencies
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/internal/logger"
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Config holds aggregation benchmark settings
+type Config struct {
+	Collection *mongo.Collection
+	Schema     model.Schema
+	LookupFrom string        // Referenced orders collection of lookup queries ("" = none)
+	Queries    []Query       // See Queries
+	Iterations int           // Runs of each query (default 5)
+	Timeout    time.Duration // Deadline of each run, also sent as maxTimeMS (0 = none)
+	YCSBLogger *logger.YCSBLogger
+
+	// Progress, if set, is called before each run
+	Progress func(query string, iteration int)
+}
+
+// Result holds the latencies of one query of the suite
+type Result struct {
+	Name      string
+	Runs      int
+	Errors    int   // Failed runs, timeouts included
+	Documents int64 // Documents returned by a successful run
+	Min       time.Duration
+	Mean      time.Duration
+	P50       time.Duration
+	P95       time.Duration
+	Max       time.Duration
+	Error     string // Last error
+}
+
+// Run runs each query of the suite Iterations times, one run at a time, and
+// returns their latencies. Runs are recorded in the YCSB log as AGG_<QUERY>
+// operations. It stops early, with the results so far, if ctx is cancelled.
+func Run(ctx context.Context, config Config) ([]Result, error) {
+	if config.Iterations <= 0 {
+		config.Iterations = 5
+	}
+
+	var results []Result
+	for _, q := range config.Queries {
+		pipeline := q.Pipeline(config.Schema, config.LookupFrom)
+		result := Result{Name: q.Name}
+		var latencies []time.Duration
+		for i := 0; i < config.Iterations; i++ {
+			if ctx.Err() != nil {
+				break
+			}
+			if config.Progress != nil {
+				config.Progress(q.Name, i+1)
+			}
+
+			start := time.Now()
+			documents, err := runPipeline(ctx, config.Collection, pipeline, config.Timeout)
+			latency := time.Since(start)
+			if err != nil && ctx.Err() != nil {
+				break
+			}
+			result.Runs++
+			if config.YCSBLogger != nil {
+				op := OperationName(q.Name)
+				if err != nil && mongo.IsTimeout(err) {
+					config.YCSBLogger.RecordTimeout(op, latency)
+				} else {
+					config.YCSBLogger.RecordOperation(op, latency, err == nil)
+				}
+			}
+			if err != nil {
+				result.Errors++
+				result.Error = err.Error()
+				continue
+			}
+			result.Documents = documents
+			latencies = append(latencies, latency)
+		}
+		result.setLatencies(latencies)
+		results = append(results, result)
+	}
+	return results, ctx.Err()
+}
+
+// runPipeline runs an aggregation and returns the number of documents it
+// returned
+func runPipeline(ctx context.Context, collection *mongo.Collection, pipeline mongo.Pipeline, timeout time.Duration) (int64, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline,
+		options.Aggregate().SetAllowDiskUse(true).SetBatchSize(1000).SetMaxTime(timeout))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var documents int64
+	for cursor.Next(ctx) {
+		documents++
+	}
+	return documents, cursor.Err()
+}
+
+// setLatencies sets the latency statistics of the successful runs
+func (r *Result) setLatencies(latencies []time.Duration) {
+	if len(latencies) == 0 {
+		return
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	var total time.Duration
+	for _, latency := range latencies {
+		total += latency
+	}
+	percentile := func(p float64) time.Duration {
+		return latencies[min(int(float64(len(latencies))*p), len(latencies)-1)]
+	}
+	r.Min = latencies[0]
+	r.Mean = total / time.Duration(len(latencies))
+	r.P50 = percentile(0.50)
+	r.P95 = percentile(0.95)
+	r.Max = latencies[len(latencies)-1]
+}
+
+// OperationName returns the YCSB operation type of a query's runs
+func OperationName(query string) string {
+	return "AGG_" + strings.ToUpper(strings.ReplaceAll(query, "-", "_"))
+}
+
+// Print writes the results as a table
+func Print(w io.Writer, results []Result) {
+	fmt.Fprintf(w, "%-22s %5s %6s %10s %10s %10s %10s %10s %9s\n",
+		"Query", "Runs", "Errors", "Min", "Mean", "P50", "P95", "Max", "Documents")
+	ms := func(d time.Duration) string {
+		return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+	}
+	for _, r := range results {
+		fmt.Fprintf(w, "%-22s %5d %6d %10s %10s %10s %10s %10s %9d\n",
+			r.Name, r.Runs, r.Errors, ms(r.Min), ms(r.Mean), ms(r.P50), ms(r.P95), ms(r.Max), r.Documents)
+	}
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Fprintf(w, "%s: %s\n", r.Name, r.Error)
+		}
+	}
+}
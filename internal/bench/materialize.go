@@ -0,0 +1,145 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/internal/logger"
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Stages that write the results of a query to its summary collection
+const (
+	StageMerge = "merge" // $merge, replacing the query's groups by _id
+	StageOut   = "out"   // $out, replacing the whole collection
+)
+
+// ViewName returns the name of the standard view of query over collection
+func ViewName(collection, query string) string {
+	return collection + "_" + strings.ReplaceAll(query, "-", "_") + "_view"
+}
+
+// SummaryName returns the name of the collection query over collection is
+// materialized into
+func SummaryName(collection, query string) string {
+	return collection + "_" + strings.ReplaceAll(query, "-", "_") + "_summary"
+}
+
+// CreateViews creates a standard view over collection for each query,
+// keeping existing ones, and returns the names of the views
+func CreateViews(ctx context.Context, collection *mongo.Collection, schema model.Schema, lookupFrom string, queries []Query) ([]string, error) {
+	var views []string
+	for _, q := range queries {
+		name := ViewName(collection.Name(), q.Name)
+		err := collection.Database().CreateView(ctx, name, collection.Name(), q.Pipeline(schema, lookupFrom))
+		if err != nil && !strings.Contains(err.Error(), "already exists") && !strings.Contains(err.Error(), "NamespaceExists") {
+			return views, fmt.Errorf("failed to create view %s: %w", name, err)
+		}
+		views = append(views, name)
+	}
+	return views, nil
+}
+
+// MaterializeConfig holds settings of Materialize
+type MaterializeConfig struct {
+	Collection *mongo.Collection
+	Schema     model.Schema
+	LookupFrom string        // Referenced orders collection of lookup queries ("" = none)
+	Queries    []Query       // See Queries
+	Stage      string        // StageMerge (default) or StageOut
+	Interval   time.Duration // Between the starts of two refreshes
+	Timeout    time.Duration // Deadline of each query's refresh, also sent as maxTimeMS (0 = none)
+	YCSBLogger *logger.YCSBLogger
+}
+
+// Materialize refreshes the summary collection of each query (see
+// SummaryName) at once and then every interval until ctx is done, and returns
+// the latencies of the refreshes. A result's Documents are those in its
+// summary collection after the last successful refresh. Refreshes are
+// recorded in the YCSB log as MERGE_<QUERY> or OUT_<QUERY> operations.
+func Materialize(ctx context.Context, config MaterializeConfig) ([]Result, error) {
+	if config.Stage == "" {
+		config.Stage = StageMerge
+	}
+	database := config.Collection.Database()
+	results := make([]Result, len(config.Queries))
+	latencies := make([][]time.Duration, len(config.Queries))
+	pipelines := make([]mongo.Pipeline, len(config.Queries))
+	for i, q := range config.Queries {
+		results[i].Name = q.Name
+		pipelines[i] = materializePipeline(q.Pipeline(config.Schema, config.LookupFrom), config.Stage,
+			SummaryName(config.Collection.Name(), q.Name))
+	}
+
+	ticker := time.NewTicker(config.Interval)
+	defer ticker.Stop()
+	for {
+		for i, q := range config.Queries {
+			if ctx.Err() != nil {
+				break
+			}
+			start := time.Now()
+			_, err := runPipeline(ctx, config.Collection, pipelines[i], config.Timeout)
+			latency := time.Since(start)
+			if err != nil && ctx.Err() != nil {
+				break
+			}
+			results[i].Runs++
+			if config.YCSBLogger != nil {
+				op := MaterializeOperationName(config.Stage, q.Name)
+				if err != nil && mongo.IsTimeout(err) {
+					config.YCSBLogger.RecordTimeout(op, latency)
+				} else {
+					config.YCSBLogger.RecordOperation(op, latency, err == nil)
+				}
+			}
+			if err != nil {
+				results[i].Errors++
+				results[i].Error = err.Error()
+				continue
+			}
+			latencies[i] = append(latencies[i], latency)
+			summary := database.Collection(SummaryName(config.Collection.Name(), q.Name))
+			if documents, err := summary.EstimatedDocumentCount(ctx); err == nil {
+				results[i].Documents = documents
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			for i := range results {
+				results[i].setLatencies(latencies[i])
+			}
+			return results, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// MaterializeOperationName returns the YCSB operation type of a query's
+// refreshes with stage
+func MaterializeOperationName(stage, query string) string {
+	return strings.ToUpper(stage) + "_" + strings.ToUpper(strings.ReplaceAll(query, "-", "_"))
+}
+
+// materializePipeline returns pipeline writing its results into the
+// collection named into with stage. $merge matches the results on _id, which
+// must not be null, so a null _id (e.g. a single group) becomes "all".
+func materializePipeline(pipeline mongo.Pipeline, stage, into string) mongo.Pipeline {
+	if stage == StageOut {
+		return append(pipeline, bson.D{{Key: "$out", Value: into}})
+	}
+	return append(pipeline,
+		bson.D{{Key: "$set", Value: bson.D{{Key: "_id", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$_id", "all"}}}}}}},
+		bson.D{{Key: "$merge", Value: bson.D{
+			{Key: "into", Value: into},
+			{Key: "on", Value: "_id"},
+			{Key: "whenMatched", Value: "replace"},
+			{Key: "whenNotMatched", Value: "insert"},
+		}}},
+	)
+}
@@ -0,0 +1,39 @@
+package bench
+
+import (
+	"testing"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestMaterializePipeline(t *testing.T) {
+	customer := model.NewGeneratorWithOptions(model.Size4KB, model.Options{}).Schema()
+	queries, err := Queries(customer, "", []string{"orders-by-status"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	into := SummaryName("customers", queries[0].Name)
+	if into != "customers_orders_by_status_summary" || ViewName("customers", queries[0].Name) != "customers_orders_by_status_view" {
+		t.Errorf("Unexpected names %s and %s", into, ViewName("customers", queries[0].Name))
+	}
+
+	base := len(queries[0].Pipeline(customer, ""))
+	merge := materializePipeline(queries[0].Pipeline(customer, ""), StageMerge, into)
+	if len(merge) != base+2 || lastStage(merge) != "$merge" || merge[base][0].Key != "$set" {
+		t.Errorf("Unexpected $merge pipeline %v", merge)
+	}
+	out := materializePipeline(queries[0].Pipeline(customer, ""), StageOut, into)
+	if len(out) != base+1 || lastStage(out) != "$out" || out[base][0].Value != into {
+		t.Errorf("Unexpected $out pipeline %v", out)
+	}
+
+	if op := MaterializeOperationName(StageMerge, "orders-by-status"); op != "MERGE_ORDERS_BY_STATUS" {
+		t.Errorf("Unexpected operation name %s", op)
+	}
+}
+
+// lastStage returns the name of the last stage of pipeline
+func lastStage(pipeline mongo.Pipeline) string {
+	return pipeline[len(pipeline)-1][0].Key
+}
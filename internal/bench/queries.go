@@ -0,0 +1,244 @@
+package bench
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// lookupCustomers is the number of customers the lookup query joins with
+// their orders
+const lookupCustomers = 1000
+
+// Query is an aggregation pipeline of the benchmark suite
+type Query struct {
+	Name        string
+	Description string
+	Templates   []string // Templates whose documents the query runs on
+	Lookup      bool     // Joins the referenced orders collection
+	Array       bool     // Needs the schema's main array
+
+	// Pipeline builds the pipeline for documents of schema, joining
+	// lookupFrom for lookup queries
+	Pipeline func(schema model.Schema, lookupFrom string) mongo.Pipeline
+}
+
+// otherTemplates are the templates without queries of their own, which run
+// the queries built from their schema
+var otherTemplates = []string{model.TemplateProduct, model.TemplateTelemetry, model.TemplateTransaction, model.TemplateMessages, model.TemplateEvents}
+
+// queries is the library of the suite, in the order they run
+var queries = []Query{
+	{
+		Name:        "orders-by-status",
+		Description: "Orders and revenue per order status",
+		Templates:   []string{model.TemplateCustomer},
+		Pipeline: func(model.Schema, string) mongo.Pipeline {
+			return mongo.Pipeline{
+				{{Key: "$unwind", Value: "$orders"}},
+				{{Key: "$group", Value: bson.D{
+					{Key: "_id", Value: "$orders.status"},
+					{Key: "orders", Value: bson.D{{Key: "$sum", Value: 1}}},
+					{Key: "revenue", Value: bson.D{{Key: "$sum", Value: "$orders.total_amount"}}},
+				}}},
+				{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+			}
+		},
+	},
+	{
+		Name:        "revenue-by-month",
+		Description: "Revenue and average order value per month, without cancelled orders",
+		Templates:   []string{model.TemplateCustomer},
+		Pipeline: func(model.Schema, string) mongo.Pipeline {
+			return mongo.Pipeline{
+				{{Key: "$unwind", Value: "$orders"}},
+				{{Key: "$match", Value: bson.D{{Key: "orders.status", Value: bson.D{{Key: "$ne", Value: "cancelled"}}}}}},
+				{{Key: "$group", Value: bson.D{
+					{Key: "_id", Value: bson.D{{Key: "$dateToString", Value: bson.D{
+						{Key: "format", Value: "%Y-%m"},
+						{Key: "date", Value: "$orders.order_date"},
+					}}}},
+					{Key: "revenue", Value: bson.D{{Key: "$sum", Value: "$orders.total_amount"}}},
+					{Key: "average_order", Value: bson.D{{Key: "$avg", Value: "$orders.total_amount"}}},
+				}}},
+				{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+			}
+		},
+	},
+	{
+		Name:        "top-products",
+		Description: "The 10 products with the highest revenue, over all line items",
+		Templates:   []string{model.TemplateCustomer},
+		Pipeline: func(model.Schema, string) mongo.Pipeline {
+			return mongo.Pipeline{
+				{{Key: "$unwind", Value: "$orders"}},
+				{{Key: "$unwind", Value: "$orders.line_items"}},
+				{{Key: "$group", Value: bson.D{
+					{Key: "_id", Value: "$orders.line_items.product_id"},
+					{Key: "quantity", Value: bson.D{{Key: "$sum", Value: "$orders.line_items.quantity"}}},
+					{Key: "revenue", Value: bson.D{{Key: "$sum", Value: "$orders.line_items.total_price"}}},
+				}}},
+				{{Key: "$sort", Value: bson.D{{Key: "revenue", Value: -1}}}},
+				{{Key: "$limit", Value: 10}},
+			}
+		},
+	},
+	{
+		Name:        "customers-by-region",
+		Description: "Customers per country and state of their default address",
+		Templates:   []string{model.TemplateCustomer},
+		Pipeline: func(model.Schema, string) mongo.Pipeline {
+			return mongo.Pipeline{
+				{{Key: "$unwind", Value: "$addresses"}},
+				{{Key: "$match", Value: bson.D{{Key: "addresses.is_default", Value: true}}}},
+				{{Key: "$group", Value: bson.D{
+					{Key: "_id", Value: bson.D{
+						{Key: "country", Value: "$addresses.country"},
+						{Key: "state", Value: "$addresses.state"},
+					}},
+					{Key: "customers", Value: bson.D{{Key: "$sum", Value: 1}}},
+				}}},
+				{{Key: "$sort", Value: bson.D{{Key: "customers", Value: -1}}}},
+				{{Key: "$limit", Value: 20}},
+			}
+		},
+	},
+	{
+		Name:        "lifetime-value-tiers",
+		Description: "Customers and their average order count per lifetime value tier",
+		Templates:   []string{model.TemplateCustomer},
+		Pipeline: func(model.Schema, string) mongo.Pipeline {
+			return mongo.Pipeline{
+				{{Key: "$bucket", Value: bson.D{
+					{Key: "groupBy", Value: "$order_stats.lifetime_value"},
+					{Key: "boundaries", Value: bson.A{0, 100, 1000, 5000, 10000, 50000}},
+					{Key: "default", Value: "other"},
+					{Key: "output", Value: bson.D{
+						{Key: "customers", Value: bson.D{{Key: "$sum", Value: 1}}},
+						{Key: "average_orders", Value: bson.D{{Key: "$avg", Value: "$order_stats.order_count"}}},
+					}},
+				}}},
+			}
+		},
+	},
+	{
+		Name:        "orders-lookup",
+		Description: fmt.Sprintf("Orders and revenue of %d customers, joined from the referenced orders collection", lookupCustomers),
+		Templates:   []string{model.TemplateCustomer},
+		Lookup:      true,
+		Pipeline: func(schema model.Schema, lookupFrom string) mongo.Pipeline {
+			return mongo.Pipeline{
+				{{Key: "$limit", Value: lookupCustomers}},
+				{{Key: "$project", Value: bson.D{{Key: schema.KeyField, Value: 1}}}},
+				{{Key: "$lookup", Value: bson.D{
+					{Key: "from", Value: lookupFrom},
+					{Key: "localField", Value: schema.KeyField},
+					{Key: "foreignField", Value: schema.KeyField},
+					{Key: "as", Value: "orders"},
+				}}},
+				{{Key: "$group", Value: bson.D{
+					{Key: "_id", Value: nil},
+					{Key: "customers", Value: bson.D{{Key: "$sum", Value: 1}}},
+					{Key: "orders", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$size", Value: "$orders"}}}}},
+					{Key: "revenue", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$sum", Value: "$orders.total_amount"}}}}},
+				}}},
+			}
+		},
+	},
+	{
+		Name:        "group-count",
+		Description: "Documents (elements of the main array, if any) per value of the group field",
+		Templates:   otherTemplates,
+		Pipeline: func(schema model.Schema, _ string) mongo.Pipeline {
+			var pipeline mongo.Pipeline
+			if schema.ArrayField != "" && strings.HasPrefix(schema.GroupField, schema.ArrayField+".") {
+				pipeline = append(pipeline, bson.D{{Key: "$unwind", Value: "$" + schema.ArrayField}})
+			}
+			return append(pipeline,
+				bson.D{{Key: "$group", Value: bson.D{
+					{Key: "_id", Value: "$" + schema.GroupField},
+					{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+				}}},
+				bson.D{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}}}},
+			)
+		},
+	},
+	{
+		Name:        "array-sizes",
+		Description: "Average and largest size of the main array",
+		Templates:   otherTemplates,
+		Array:       true,
+		Pipeline: func(schema model.Schema, _ string) mongo.Pipeline {
+			return mongo.Pipeline{
+				{{Key: "$project", Value: bson.D{{Key: "size", Value: bson.D{{Key: "$size", Value: bson.D{
+					{Key: "$ifNull", Value: bson.A{"$" + schema.ArrayField, bson.A{}}},
+				}}}}}}},
+				{{Key: "$group", Value: bson.D{
+					{Key: "_id", Value: nil},
+					{Key: "documents", Value: bson.D{{Key: "$sum", Value: 1}}},
+					{Key: "average", Value: bson.D{{Key: "$avg", Value: "$size"}}},
+					{Key: "max", Value: bson.D{{Key: "$max", Value: "$size"}}},
+				}}},
+			}
+		},
+	},
+}
+
+// Queries returns the queries of the suite that run on documents of schema,
+// with lookups only if there is a referenced orders collection (lookupFrom).
+// names, if not empty, selects queries by name; a name of a query that
+// cannot run is an error.
+func Queries(schema model.Schema, lookupFrom string, names []string) ([]Query, error) {
+	template := schema.Template
+	if template == "" {
+		template = model.TemplateCustomer
+	}
+
+	var selected []Query
+	for _, name := range names {
+		i := slices.IndexFunc(queries, func(q Query) bool { return q.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown query %q (queries: %s)", name, strings.Join(Names(), ", "))
+		}
+		if err := queries[i].check(template, schema, lookupFrom); err != nil {
+			return nil, err
+		}
+		selected = append(selected, queries[i])
+	}
+	if len(names) > 0 {
+		return selected, nil
+	}
+
+	for _, q := range queries {
+		if q.check(template, schema, lookupFrom) == nil {
+			selected = append(selected, q)
+		}
+	}
+	return selected, nil
+}
+
+// check returns why q does not run on documents of template
+func (q *Query) check(template string, schema model.Schema, lookupFrom string) error {
+	switch {
+	case !slices.Contains(q.Templates, template):
+		return fmt.Errorf("query %s does not run on %s documents", q.Name, template)
+	case q.Lookup && lookupFrom == "":
+		return fmt.Errorf("query %s needs a run loaded with --orders-collection", q.Name)
+	case q.Array && schema.ArrayField == "":
+		return fmt.Errorf("query %s needs an array, which %s documents do not have", q.Name, template)
+	}
+	return nil
+}
+
+// Names returns the names of all queries of the suite
+func Names() []string {
+	names := make([]string, len(queries))
+	for i, q := range queries {
+		names[i] = q.Name
+	}
+	return names
+}
@@ -0,0 +1,81 @@
+package bench
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// names returns the names of queries
+func names(queries []Query) []string {
+	var names []string
+	for _, q := range queries {
+		names = append(names, q.Name)
+	}
+	return names
+}
+
+func TestQueriesForTemplates(t *testing.T) {
+	customer := model.NewGeneratorWithOptions(model.Size4KB, model.Options{}).Schema()
+	queries, err := Queries(customer, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(queries); slices.Contains(got, "orders-lookup") || !slices.Contains(got, "orders-by-status") || slices.Contains(got, "group-count") {
+		t.Errorf("Customer queries without an orders collection: %v", got)
+	}
+	queries, err = Queries(customer, "customers_orders", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(names(queries), "orders-lookup") {
+		t.Errorf("Customer queries with an orders collection lack orders-lookup: %v", names(queries))
+	}
+
+	for _, template := range []string{model.TemplateProduct, model.TemplateTelemetry, model.TemplateTransaction, model.TemplateMessages, model.TemplateEvents} {
+		schema := model.NewGeneratorWithOptions(model.Size4KB, model.Options{Template: template}).Schema()
+		queries, err := Queries(schema, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(queries) == 0 || queries[0].Name != "group-count" {
+			t.Errorf("%s queries: %v", template, names(queries))
+		}
+		for _, q := range queries {
+			if _, _, err := bson.MarshalValue(q.Pipeline(schema, "")); err != nil {
+				t.Errorf("%s pipeline %s does not marshal: %v", template, q.Name, err)
+			}
+		}
+	}
+}
+
+func TestQueriesByName(t *testing.T) {
+	customer := model.NewGeneratorWithOptions(model.Size4KB, model.Options{}).Schema()
+	queries, err := Queries(customer, "", []string{"top-products", "orders-by-status"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(queries); !slices.Equal(got, []string{"top-products", "orders-by-status"}) {
+		t.Errorf("Selected queries %v", got)
+	}
+
+	for _, selected := range [][]string{{"no-such-query"}, {"orders-lookup"}, {"group-count"}} {
+		if _, err := Queries(customer, "", selected); err == nil {
+			t.Errorf("Expected an error selecting %v", selected)
+		}
+	}
+}
+
+func TestResultLatencies(t *testing.T) {
+	var r Result
+	r.setLatencies([]time.Duration{40 * time.Millisecond, 10 * time.Millisecond, 30 * time.Millisecond, 20 * time.Millisecond})
+	if r.Min != 10*time.Millisecond || r.Max != 40*time.Millisecond || r.Mean != 25*time.Millisecond || r.P50 != 30*time.Millisecond {
+		t.Errorf("Unexpected latencies %+v", r)
+	}
+	if OperationName("orders-by-status") != "AGG_ORDERS_BY_STATUS" {
+		t.Errorf("Unexpected operation name %s", OperationName("orders-by-status"))
+	}
+}
@@ -0,0 +1,195 @@
+// Package config loads command-line flag values from YAML or TOML files.
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// PipelinesKey is the config file key listing the pipelines of a
+// multi-pipeline load
+const PipelinesKey = "pipelines"
+
+// Pipeline is one generator-to-writer pipeline of a multi-pipeline load: its
+// name and the flag values it sets on top of the file's and command line's
+type Pipeline struct {
+	Name   string
+	Values map[string]interface{}
+}
+
+// ApplyFile sets the flags of fs from the config file at path and returns
+// the pipelines it lists, if any. Keys are flag names without the leading
+// dashes (e.g. "size: 10GB"); lists are joined with commas. Flags already set
+// on the command line are left untouched, so CLI flags override the file.
+// The format is chosen by the file extension: .toml for TOML, anything else
+// is parsed as YAML.
+func ApplyFile(fs *flag.FlagSet, path string) ([]Pipeline, error) {
+	values, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pipelines, err := parsePipelines(values[PipelinesKey])
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s in config file %s: %w", PipelinesKey, path, err)
+	}
+	delete(values, PipelinesKey)
+	if err := Apply(fs, values); err != nil {
+		return nil, err
+	}
+	return pipelines, nil
+}
+
+// ReadFile decodes the YAML or TOML file at path (TOML by the .toml
+// extension) into its top-level keys
+func ReadFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values := make(map[string]interface{})
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		err = toml.Unmarshal(data, &values)
+	} else {
+		err = yaml.Unmarshal(data, &values)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// Args renders values as command-line flags (--name=value), sorted by name
+func Args(values map[string]interface{}) ([]string, error) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	args := make([]string, 0, len(names))
+	for _, name := range names {
+		value, err := formatValue(values[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %q: %w", name, err)
+		}
+		args = append(args, "--"+name+"="+value)
+	}
+	return args, nil
+}
+
+// Tables returns a decoded list of tables (YAML mappings or TOML [[tables]])
+func Tables(v interface{}) ([]map[string]interface{}, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case []map[string]interface{}:
+		return v, nil
+	case []interface{}:
+		tables := make([]map[string]interface{}, 0, len(v))
+		for _, item := range v {
+			table, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("expected a list of tables, got %v", item)
+			}
+			tables = append(tables, table)
+		}
+		return tables, nil
+	}
+	return nil, fmt.Errorf("expected a list of tables")
+}
+
+// parsePipelines decodes the list of pipeline tables, each with a unique name
+func parsePipelines(v interface{}) ([]Pipeline, error) {
+	tables, err := Tables(v)
+	if err != nil {
+		return nil, err
+	}
+
+	pipelines := make([]Pipeline, 0, len(tables))
+	seen := make(map[string]bool)
+	for _, table := range tables {
+		name, _ := table["name"].(string)
+		if name == "" {
+			return nil, fmt.Errorf("every pipeline needs a name")
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate pipeline name %q", name)
+		}
+		seen[name] = true
+
+		values := make(map[string]interface{}, len(table))
+		for key, value := range table {
+			if key != "name" {
+				values[key] = value
+			}
+		}
+		pipelines = append(pipelines, Pipeline{Name: name, Values: values})
+	}
+	return pipelines, nil
+}
+
+// Apply sets the flags of fs that were not set on the command line from values
+func Apply(fs *flag.FlagSet, values map[string]interface{}) error {
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	// Apply in a stable order so errors are reproducible
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if fs.Lookup(name) == nil {
+			return fmt.Errorf("unknown config key %q", name)
+		}
+		if explicit[name] {
+			continue
+		}
+		value, err := formatValue(values[name])
+		if err != nil {
+			return fmt.Errorf("invalid value for %q: %w", name, err)
+		}
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("invalid value for %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// formatValue renders a decoded config value in command-line form
+func formatValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			s, err := formatValue(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ","), nil
+	case map[string]interface{}:
+		return "", fmt.Errorf("expected a value or a list, got a table")
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newFlagSet() (*flag.FlagSet, *string, *int, *float64, *bool, *time.Duration, *string) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	size := fs.String("size", "1TB", "")
+	writers := fs.Int("writers", 0, "")
+	ratio := fs.Float64("duplicate-ratio", 0, "")
+	verify := fs.Bool("verify", false, "")
+	duration := fs.Duration("duration", time.Minute, "")
+	tenants := fs.String("tenants", "", "")
+	return fs, size, writers, ratio, verify, duration, tenants
+}
+
+func TestApplyFileFormats(t *testing.T) {
+	files := map[string]string{
+		"gendata.yaml": "size: 10GB\nwriters: 8\nduplicate-ratio: 0.25\nverify: true\nduration: 2h\ntenants: [acme, globex]\n",
+		"gendata.toml": "size = \"10GB\"\nwriters = 8\nduplicate-ratio = 0.25\nverify = true\nduration = \"2h\"\ntenants = [\"acme\", \"globex\"]\n",
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			fs, size, writers, ratio, verify, duration, tenants := newFlagSet()
+			if err := fs.Parse(nil); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ApplyFile(fs, path); err != nil {
+				t.Fatalf("ApplyFile: %v", err)
+			}
+
+			if *size != "10GB" || *writers != 8 || *ratio != 0.25 || !*verify || *duration != 2*time.Hour || *tenants != "acme,globex" {
+				t.Errorf("got size=%s writers=%d ratio=%v verify=%v duration=%v tenants=%s",
+					*size, *writers, *ratio, *verify, *duration, *tenants)
+			}
+		})
+	}
+}
+
+func TestCommandLineOverridesFile(t *testing.T) {
+	fs, size, writers, _, _, _, _ := newFlagSet()
+	if err := fs.Parse([]string{"--size", "5GB"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Apply(fs, map[string]interface{}{"size": "10GB", "writers": 4}); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if *size != "5GB" {
+		t.Errorf("size = %s, want the command-line value 5GB", *size)
+	}
+	if *writers != 4 {
+		t.Errorf("writers = %d, want 4 from the config file", *writers)
+	}
+}
+
+func TestApplyRejectsUnknownKeys(t *testing.T) {
+	fs, _, _, _, _, _, _ := newFlagSet()
+	if err := Apply(fs, map[string]interface{}{"sise": "10GB"}); err == nil {
+		t.Error("expected an error for an unknown key")
+	}
+}
+
+func TestApplyFilePipelines(t *testing.T) {
+	files := map[string]string{
+		"gendata.yaml": "size: 10GB\npipelines:\n  - name: orders\n    collection: orders\n    writers: 4\n  - name: catalog\n    collection: products\n    template: product\n",
+		"gendata.toml": "size = \"10GB\"\n[[pipelines]]\nname = \"orders\"\ncollection = \"orders\"\nwriters = 4\n[[pipelines]]\nname = \"catalog\"\ncollection = \"products\"\ntemplate = \"product\"\n",
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			fs, size, _, _, _, _, _ := newFlagSet()
+			pipelines, err := ApplyFile(fs, path)
+			if err != nil {
+				t.Fatalf("ApplyFile: %v", err)
+			}
+			if *size != "10GB" {
+				t.Errorf("size = %s, want 10GB", *size)
+			}
+			if len(pipelines) != 2 || pipelines[0].Name != "orders" || pipelines[1].Name != "catalog" {
+				t.Fatalf("got pipelines %+v", pipelines)
+			}
+			if pipelines[0].Values["collection"] != "orders" || pipelines[1].Values["template"] != "product" {
+				t.Errorf("got pipeline values %+v", pipelines)
+			}
+			if _, ok := pipelines[0].Values["name"]; ok {
+				t.Error("expected the name to be removed from the pipeline's values")
+			}
+		})
+	}
+}
+
+func TestApplyFileRejectsUnnamedPipelines(t *testing.T) {
+	for _, content := range []string{
+		"pipelines:\n  - collection: orders\n",
+		"pipelines:\n  - name: a\n  - name: a\n",
+		"pipelines: orders\n",
+	} {
+		path := filepath.Join(t.TempDir(), "gendata.yaml")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		fs, _, _, _, _, _, _ := newFlagSet()
+		if _, err := ApplyFile(fs, path); err == nil {
+			t.Errorf("expected an error for %q", content)
+		}
+	}
+}
+
+func TestArgs(t *testing.T) {
+	args, err := Args(map[string]interface{}{"size": "10GB", "writers": 8, "verify": true, "tenants": []interface{}{"acme", "globex"}})
+	if err != nil {
+		t.Fatalf("Args: %v", err)
+	}
+	want := []string{"--size=10GB", "--tenants=acme,globex", "--verify=true", "--writers=8"}
+	if strings.Join(args, " ") != strings.Join(want, " ") {
+		t.Errorf("got %v, want %v", args, want)
+	}
+
+	if _, err := Args(map[string]interface{}{"size": map[string]interface{}{"a": 1}}); err == nil {
+		t.Error("expected an error for a table value")
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ExtendsKey is the schema file key naming the schema it extends
+const ExtendsKey = "extends"
+
+// Registry is a directory of named schemas: YAML, JSON, or TOML files of flag
+// values describing documents, each named after its file without the
+// extension. A schema may extend another, overriding some of its values.
+type Registry struct {
+	files map[string]string // Schema name -> file
+}
+
+// OpenRegistry lists the schemas in dir
+func OpenRegistry(dir string) (*Registry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema directory: %w", err)
+	}
+	r := &Registry{files: make(map[string]string)}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		switch strings.ToLower(ext) {
+		case ".yaml", ".yml", ".json", ".toml":
+		default:
+			continue
+		}
+		if entry.IsDir() {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), ext)
+		if other, ok := r.files[name]; ok {
+			return nil, fmt.Errorf("schema %s is defined by both %s and %s", name, filepath.Base(other), entry.Name())
+		}
+		r.files[name] = filepath.Join(dir, entry.Name())
+	}
+	return r, nil
+}
+
+// Names returns the names of the schemas, sorted
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.files))
+	for name := range r.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve returns the flag values of the named schema, those of the schemas
+// it extends overridden by its own, and the chain of schemas from the named
+// one to the one extending no other
+func (r *Registry) Resolve(name string) (map[string]interface{}, []string, error) {
+	if _, ok := r.files[name]; !ok {
+		return nil, nil, fmt.Errorf("unknown schema %q (available: %s)", name, strings.Join(r.Names(), ", "))
+	}
+
+	values := make(map[string]interface{})
+	var chain []string
+	for name != "" {
+		for _, seen := range chain {
+			if seen == name {
+				return nil, nil, fmt.Errorf("schema %s extends itself: %s -> %s", name, strings.Join(chain, " -> "), name)
+			}
+		}
+		path, ok := r.files[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("schema %s extends unknown schema %q", chain[len(chain)-1], name)
+		}
+		chain = append(chain, name)
+
+		own, err := ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+		parent, ok := own[ExtendsKey].(string)
+		if _, set := own[ExtendsKey]; set && !ok {
+			return nil, nil, fmt.Errorf("schema %s: %s must name a schema", name, ExtendsKey)
+		}
+		delete(own, ExtendsKey)
+
+		// Schemas nearer the named one win
+		for key, value := range own {
+			if _, set := values[key]; !set {
+				values[key] = value
+			}
+		}
+		name = parent
+	}
+	return values, chain, nil
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeSchemas(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestRegistryResolvesExtends(t *testing.T) {
+	dir := writeSchemas(t, map[string]string{
+		"customer.yaml": "template: customer\ndoc-size: 4KB\nlocales: [en_US]\n",
+		"eu.toml":       "extends = \"customer\"\nlocales = [\"de_DE\", \"fr_FR\"]\ncoherent-addresses = true\n",
+		"eu-large.yml":  "extends: eu\ndoc-size: 16KB\n",
+		"README.md":     "not a schema\n",
+	})
+	r, err := OpenRegistry(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := r.Names(); !reflect.DeepEqual(names, []string{"customer", "eu", "eu-large"}) {
+		t.Errorf("Names() = %v", names)
+	}
+
+	values, chain, err := r.Resolve("eu-large")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(chain, []string{"eu-large", "eu", "customer"}) {
+		t.Errorf("chain = %v", chain)
+	}
+	fs, _, _, _, _, _, _ := newFlagSet()
+	fs.String("template", "", "")
+	fs.String("doc-size", "auto", "")
+	fs.String("locales", "", "")
+	fs.Bool("coherent-addresses", false, "")
+	if err := Apply(fs, values); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"template": "customer", "doc-size": "16KB", "locales": "de_DE,fr_FR", "coherent-addresses": "true"} {
+		if got := fs.Lookup(name).Value.String(); got != want {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestRegistryRejectsBadExtends(t *testing.T) {
+	dir := writeSchemas(t, map[string]string{
+		"a.yaml":      "extends: b\n",
+		"b.yaml":      "extends: a\n",
+		"orphan.yaml": "extends: missing\n",
+	})
+	r, err := OpenRegistry(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"a": "extends itself", "orphan": "unknown schema", "nope": "available: a, b, orphan"} {
+		if _, _, err := r.Resolve(name); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Resolve(%s) = %v, want an error containing %q", name, err, want)
+		}
+	}
+}
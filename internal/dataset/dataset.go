@@ -0,0 +1,214 @@
+// Package dataset reads previously generated datasets, as the file sink
+// writes them (Extended JSON lines or concatenated BSON), optionally
+// zstd-compressed, to import them into MongoDB.
+package dataset
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxDocumentBytes bounds the length prefix of a BSON document, so a corrupt
+// file fails instead of allocating; the server accepts up to 16MB
+const maxDocumentBytes = 16*1024*1024 + 16*1024
+
+// Reader reads the documents of a dataset file. It is safe for concurrent
+// use: documents are read in turn but parsed concurrently.
+type Reader struct {
+	path    string
+	mu      sync.Mutex
+	file    *os.File
+	decoder *zstd.Decoder // nil for an uncompressed file
+	in      *bufio.Reader
+	bson    bool  // Concatenated BSON instead of Extended JSON lines
+	read    int64 // Lines or BSON documents read, for error messages
+}
+
+// Open opens the dataset at path: concatenated BSON if the path ends in
+// .bson, Extended JSON (canonical or relaxed) one document per line
+// otherwise, each zstd-compressed if the path also ends in .zst or .zstd
+func Open(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dataset: %w", err)
+	}
+	r := &Reader{path: path, file: file}
+
+	var in io.Reader = file
+	name := strings.ToLower(path)
+	if ext := filepath.Ext(name); ext == ".zst" || ext == ".zstd" {
+		if r.decoder, err = zstd.NewReader(file); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to read zstd stream: %w", err)
+		}
+		in = r.decoder
+		name = strings.TrimSuffix(name, ext)
+	}
+	r.in = bufio.NewReaderSize(in, 1<<20)
+	r.bson = filepath.Ext(name) == ".bson"
+	return r, nil
+}
+
+// Next returns the next document of the dataset and its BSON size, or io.EOF
+// after the last one
+func (r *Reader) Next() (model.Document, int64, error) {
+	data, n, err := r.next()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	raw := bson.Raw(data)
+	if !r.bson {
+		if err := bson.UnmarshalExtJSON(data, false, &raw); err != nil {
+			return nil, 0, fmt.Errorf("failed to parse line %d of %s: %w", n, r.path, err)
+		}
+	}
+	doc, err := NewDocument(raw)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid document %d of %s: %w", n, r.path, err)
+	}
+	return doc, int64(len(doc.raw)), nil
+}
+
+// next reads the next non-empty line or BSON document and returns it with
+// its number in the file
+func (r *Reader) next() ([]byte, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.bson {
+		var prefix [4]byte
+		if _, err := io.ReadFull(r.in, prefix[:]); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				return nil, 0, fmt.Errorf("truncated document %d of %s", r.read+1, r.path)
+			}
+			if err != io.EOF {
+				err = fmt.Errorf("failed to read %s: %w", r.path, err)
+			}
+			return nil, 0, err
+		}
+		r.read++
+		length := int64(binary.LittleEndian.Uint32(prefix[:]))
+		if length < 5 || length > maxDocumentBytes {
+			return nil, 0, fmt.Errorf("invalid length %d of document %d of %s", length, r.read, r.path)
+		}
+		data := make([]byte, length)
+		copy(data, prefix[:])
+		if _, err := io.ReadFull(r.in, data[4:]); err != nil {
+			return nil, 0, fmt.Errorf("truncated document %d of %s", r.read, r.path)
+		}
+		return data, r.read, nil
+	}
+
+	for {
+		line, err := r.in.ReadBytes('\n')
+		if len(line) > 0 {
+			r.read++
+			// Skip blank lines, e.g. a hand-edited file's trailing ones
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				return line, r.read, nil
+			}
+		}
+		if err != nil {
+			if err != io.EOF {
+				err = fmt.Errorf("failed to read %s: %w", r.path, err)
+			}
+			return nil, 0, err
+		}
+	}
+}
+
+// Close closes the dataset file
+func (r *Reader) Close() error {
+	if r.decoder != nil {
+		r.decoder.Close()
+	}
+	return r.file.Close()
+}
+
+// Document is a document read from a dataset. It marshals to the BSON it was
+// read as, so imported documents keep their fields, order, and types.
+type Document struct {
+	raw bson.Raw
+}
+
+// NewDocument wraps raw, adding an ObjectID _id first if it has none
+func NewDocument(raw bson.Raw) (*Document, error) {
+	if err := raw.Validate(); err != nil {
+		return nil, err
+	}
+	d := &Document{raw: raw}
+	if _, err := raw.LookupErr("_id"); err != nil {
+		d.update(func(fields bson.D) bson.D {
+			return append(bson.D{{Key: "_id", Value: primitive.NewObjectID()}}, fields...)
+		})
+	}
+	return d, nil
+}
+
+// MarshalBSON returns the document's BSON
+func (d *Document) MarshalBSON() ([]byte, error) {
+	return d.raw, nil
+}
+
+// DocumentID returns the document's _id, zero if it is not an ObjectID
+func (d *Document) DocumentID() primitive.ObjectID {
+	id, _ := d.raw.Lookup("_id").ObjectIDOK()
+	return id
+}
+
+// SetDocumentID replaces the document's _id
+func (d *Document) SetDocumentID(id primitive.ObjectID) {
+	d.update(func(fields bson.D) bson.D {
+		return setField(fields, "_id", id)
+	})
+}
+
+// SetMetadata sets a field of the document's metadata subdocument, creating
+// it if needed
+func (d *Document) SetMetadata(key string, value interface{}) {
+	d.update(func(fields bson.D) bson.D {
+		var metadata bson.D
+		for _, f := range fields {
+			if f.Key == "metadata" {
+				metadata, _ = f.Value.(bson.D)
+			}
+		}
+		return setField(fields, "metadata", setField(metadata, key, value))
+	})
+}
+
+// update rewrites the document's BSON with change applied to its fields. The
+// BSON was validated, so decoding and encoding it again does not fail.
+func (d *Document) update(change func(bson.D) bson.D) {
+	var fields bson.D
+	if err := bson.Unmarshal(d.raw, &fields); err != nil {
+		return
+	}
+	if raw, err := bson.Marshal(change(fields)); err == nil {
+		d.raw = raw
+	}
+}
+
+// setField sets key to value in fields, appending it if missing
+func setField(fields bson.D, key string, value interface{}) bson.D {
+	for i := range fields {
+		if fields[i].Key == key {
+			fields[i].Value = value
+			return fields
+		}
+	}
+	return append(fields, bson.E{Key: key, Value: value})
+}
@@ -0,0 +1,115 @@
+package dataset
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestReaderCompressedJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "customers.ndjson.zst")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := zstd.NewWriter(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A canonical line as the file sink writes it, a hand-edited relaxed
+	// one without an _id, and blank lines
+	enc.Write([]byte(`{"_id":{"$oid":"65a1b2c3d4e5f60718293a4b"},"n":{"$numberInt":"1"},"at":{"$date":{"$numberLong":"0"}}}` + "\n\n"))
+	enc.Write([]byte(`{"name": "edited", "n": 2}` + "\n"))
+	enc.Close()
+	file.Close()
+
+	r, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	doc, size, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := primitive.ObjectIDFromHex("65a1b2c3d4e5f60718293a4b")
+	raw, _ := bson.Marshal(doc)
+	if doc.DocumentID() != want || size != int64(len(raw)) {
+		t.Errorf("Unexpected document %v of size %d", bson.Raw(raw), size)
+	}
+	if _, ok := bson.Raw(raw).Lookup("at").DateTimeOK(); !ok {
+		t.Errorf("Expected the date to keep its type in %v", bson.Raw(raw))
+	}
+
+	doc, _, err = r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ = bson.Marshal(doc)
+	if doc.DocumentID().IsZero() || bson.Raw(raw).Index(0).Key() != "_id" {
+		t.Errorf("Expected an _id to be added first to %v", bson.Raw(raw))
+	}
+	if _, _, err := r.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+}
+
+func TestReaderBSON(t *testing.T) {
+	var data []byte
+	for i := 0; i < 3; i++ {
+		raw, _ := bson.Marshal(bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "i", Value: i}})
+		data = append(data, raw...)
+	}
+	path := filepath.Join(t.TempDir(), "customers.bson")
+	if err := os.WriteFile(path, append(data, 1, 2), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	for i := 0; i < 3; i++ {
+		if _, _, err := r.Next(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := r.Next(); err == nil || err == io.EOF {
+		t.Errorf("Expected an error for the truncated document, got %v", err)
+	}
+}
+
+func TestDocumentMetadata(t *testing.T) {
+	doc, err := NewDocument(bson.Raw(mustMarshal(t, bson.D{{Key: "metadata", Value: bson.D{{Key: "source", Value: "edited"}}}})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.SetMetadata("run_id", "r1")
+	id := primitive.NewObjectID()
+	doc.SetDocumentID(id)
+
+	raw, _ := bson.Marshal(doc)
+	metadata := bson.Raw(raw).Lookup("metadata").Document()
+	if metadata.Lookup("source").StringValue() != "edited" || metadata.Lookup("run_id").StringValue() != "r1" {
+		t.Errorf("Unexpected metadata %v", metadata)
+	}
+	if doc.DocumentID() != id {
+		t.Errorf("Expected _id %v, got %v", id, doc.DocumentID())
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	data, err := bson.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
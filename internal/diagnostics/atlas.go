@@ -0,0 +1,133 @@
+package diagnostics
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// atlasBaseURL is the Atlas Administration API endpoint
+const atlasBaseURL = "https://cloud.mongodb.com/api/atlas/v1.0"
+
+// AtlasConfig identifies the Atlas project and hosts whose logs to download
+type AtlasConfig struct {
+	PublicKey  string
+	PrivateKey string
+	GroupID    string   // Atlas project ID
+	Hosts      []string // Cluster member hostnames, e.g. cluster0-shard-00-00.abcde.mongodb.net
+}
+
+// Enabled reports whether Atlas log download is configured
+func (c AtlasConfig) Enabled() bool {
+	return c.PublicKey != "" && c.PrivateKey != "" && c.GroupID != "" && len(c.Hosts) > 0
+}
+
+// DownloadAtlasLogs downloads the mongod log of each host for the window
+// [start, end] through the Atlas Administration API into dir
+func DownloadAtlasLogs(ctx context.Context, config AtlasConfig, dir string, start, end time.Time) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create diagnostics directory: %w", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Minute}
+	for _, host := range config.Hosts {
+		url := fmt.Sprintf("%s/groups/%s/clusters/%s/logs/mongodb.gz?startDate=%d&endDate=%d",
+			atlasBaseURL, config.GroupID, host, start.Unix(), end.Unix())
+
+		path := filepath.Join(dir, fmt.Sprintf("atlas-%s-mongodb.log.gz", host))
+		if err := downloadWithDigest(ctx, client, url, config.PublicKey, config.PrivateKey, path); err != nil {
+			return fmt.Errorf("failed to download Atlas log for %s: %w", host, err)
+		}
+	}
+	return nil
+}
+
+// downloadWithDigest performs a GET with HTTP digest authentication, which
+// the Atlas Administration API requires for API keys
+func downloadWithDigest(ctx context.Context, client *http.Client, url, username, password, path string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/gzip")
+
+	// First request obtains the digest challenge
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		challenge := parseDigestChallenge(resp.Header.Get("WWW-Authenticate"))
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/gzip")
+		req.Header.Set("Authorization", digestAuthorization(challenge, http.MethodGet, req.URL.RequestURI(), username, password))
+
+		resp, err = client.Do(req)
+		if err != nil {
+			return err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, resp.Body)
+	return err
+}
+
+// parseDigestChallenge parses the key="value" pairs of a Digest WWW-Authenticate header
+func parseDigestChallenge(header string) map[string]string {
+	params := make(map[string]string)
+	header = strings.TrimSpace(strings.TrimPrefix(header, "Digest"))
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) == 2 {
+			params[kv[0]] = strings.Trim(kv[1], `"`)
+		}
+	}
+	return params
+}
+
+// digestAuthorization builds the Authorization header answering a digest challenge (RFC 2617, qop=auth)
+func digestAuthorization(challenge map[string]string, method, uri, username, password string) string {
+	md5hex := func(s string) string {
+		sum := md5.Sum([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+
+	cnonceBytes := make([]byte, 8)
+	rand.Read(cnonceBytes)
+	cnonce := hex.EncodeToString(cnonceBytes)
+	nc := "00000001"
+
+	ha1 := md5hex(username + ":" + challenge["realm"] + ":" + password)
+	ha2 := md5hex(method + ":" + uri)
+	response := md5hex(strings.Join([]string{ha1, challenge["nonce"], nc, cnonce, "auth", ha2}, ":"))
+
+	return fmt.Sprintf(`Digest username="%s", realm="%s", nonce="%s", uri="%s", qop=auth, nc=%s, cnonce="%s", response="%s", algorithm=MD5`,
+		username, challenge["realm"], challenge["nonce"], uri, nc, cnonce, response)
+}
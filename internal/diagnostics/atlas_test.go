@@ -0,0 +1,80 @@
+package diagnostics
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestAtlasConfigEnabled(t *testing.T) {
+	full := AtlasConfig{PublicKey: "pub", PrivateKey: "priv", GroupID: "group", Hosts: []string{"host"}}
+	for _, tc := range []struct {
+		config AtlasConfig
+		want   bool
+	}{
+		{full, true},
+		{AtlasConfig{}, false},
+		{AtlasConfig{PublicKey: "pub", PrivateKey: "priv", GroupID: "group"}, false},
+		{AtlasConfig{PublicKey: "pub", GroupID: "group", Hosts: []string{"host"}}, false},
+	} {
+		if got := tc.config.Enabled(); got != tc.want {
+			t.Errorf("%+v: expected Enabled() = %v", tc.config, tc.want)
+		}
+	}
+}
+
+func TestParseDigestChallenge(t *testing.T) {
+	for _, tc := range []struct {
+		header string
+		want   map[string]string
+	}{
+		{
+			`Digest realm="MMS Public API", domain="", nonce="abc123", algorithm=MD5, qop="auth", stale=false`,
+			map[string]string{"realm": "MMS Public API", "domain": "", "nonce": "abc123", "algorithm": "MD5", "qop": "auth", "stale": "false"},
+		},
+		{`Digest nonce="n=1"`, map[string]string{"nonce": "n=1"}},
+		{"Digest", map[string]string{}},
+		{`Digest realm`, map[string]string{}},
+	} {
+		got := parseDigestChallenge(tc.header)
+		if len(got) != len(tc.want) {
+			t.Errorf("%q: expected %v, got %v", tc.header, tc.want, got)
+			continue
+		}
+		for key, value := range tc.want {
+			if got[key] != value {
+				t.Errorf("%q: expected %s=%q, got %q", tc.header, key, value, got[key])
+			}
+		}
+	}
+}
+
+func TestDigestAuthorization(t *testing.T) {
+	challenge := map[string]string{"realm": "MMS Public API", "nonce": "abc123"}
+	header := digestAuthorization(challenge, "GET", "/api/atlas/v1.0/groups/g/clusters/h/logs/mongodb.gz", "pub", "priv")
+	if !strings.HasPrefix(header, "Digest ") {
+		t.Fatalf("expected a Digest header, got %q", header)
+	}
+	params := parseDigestChallenge(header)
+	for key, want := range map[string]string{
+		"username": "pub", "realm": "MMS Public API", "nonce": "abc123", "qop": "auth", "nc": "00000001", "algorithm": "MD5",
+		"uri": "/api/atlas/v1.0/groups/g/clusters/h/logs/mongodb.gz",
+	} {
+		if params[key] != want {
+			t.Errorf("expected %s=%q, got %q", key, want, params[key])
+		}
+	}
+
+	// The response must answer the challenge with the client nonce it sent
+	md5hex := func(s string) string {
+		sum := md5.Sum([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+	ha1 := md5hex("pub:MMS Public API:priv")
+	ha2 := md5hex("GET:" + params["uri"])
+	want := md5hex(strings.Join([]string{ha1, "abc123", "00000001", params["cnonce"], "auth", ha2}, ":"))
+	if params["response"] != want {
+		t.Errorf("expected response %s, got %s", want, params["response"])
+	}
+}
@@ -0,0 +1,120 @@
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Collect gathers server diagnostic artifacts reachable through the driver
+// connection (recent log lines, startup options with the FTDC directory,
+// serverStatus, hostInfo) into dir. Failures of individual commands are
+// recorded in errors.txt rather than aborting the collection.
+func Collect(ctx context.Context, client *mongo.Client, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create diagnostics directory: %w", err)
+	}
+
+	admin := client.Database("admin")
+	var failures []string
+
+	commands := []struct {
+		file    string
+		command bson.D
+	}{
+		{"getLog.json", bson.D{{Key: "getLog", Value: "global"}}},
+		{"getCmdLineOpts.json", bson.D{{Key: "getCmdLineOpts", Value: 1}}},
+		{"serverStatus.json", bson.D{{Key: "serverStatus", Value: 1}}},
+		{"hostInfo.json", bson.D{{Key: "hostInfo", Value: 1}}},
+	}
+
+	results := make(map[string]bson.M)
+	for _, c := range commands {
+		var result bson.M
+		if err := admin.RunCommand(ctx, c.command).Decode(&result); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", c.command[0].Key, err))
+			continue
+		}
+		results[c.file] = result
+		if err := writeJSON(filepath.Join(dir, c.file), result); err != nil {
+			return err
+		}
+	}
+
+	if opts, ok := results["getCmdLineOpts.json"]; ok {
+		if err := writeFTDCNote(filepath.Join(dir, "ftdc.txt"), opts); err != nil {
+			return err
+		}
+	}
+
+	if len(failures) > 0 {
+		content := strings.Join(failures, "\n") + "\n"
+		if err := os.WriteFile(filepath.Join(dir, "errors.txt"), []byte(content), 0o644); err != nil {
+			return fmt.Errorf("failed to write diagnostics errors: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeFTDCNote records where the server keeps its FTDC (diagnostic.data)
+// files, since they can't be downloaded through the driver
+func writeFTDCNote(path string, cmdLineOpts bson.M) error {
+	parsed, _ := cmdLineOpts["parsed"].(bson.M)
+
+	ftdcPath := lookupString(parsed, "setParameter", "diagnosticDataCollectionDirectoryPath")
+	if ftdcPath == "" {
+		if dbPath := lookupString(parsed, "storage", "dbPath"); dbPath != "" {
+			ftdcPath = filepath.Join(dbPath, "diagnostic.data")
+		}
+	}
+	if ftdcPath == "" {
+		ftdcPath = "unknown (not reported by getCmdLineOpts; for Atlas, download FTDC from the cluster's monitoring page)"
+	}
+
+	content := fmt.Sprintf("FTDC directory on the server host: %s\n", ftdcPath)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("failed to write FTDC note: %w", err)
+	}
+	return nil
+}
+
+// lookupString reads a nested string value from a BSON map
+func lookupString(m bson.M, path ...string) string {
+	for i, key := range path {
+		if m == nil {
+			return ""
+		}
+		if i == len(path)-1 {
+			s, _ := m[key].(string)
+			return s
+		}
+		m, _ = m[key].(bson.M)
+	}
+	return ""
+}
+
+// writeJSON writes a command result as indented Extended JSON
+func writeJSON(path string, result bson.M) error {
+	data, err := bson.MarshalExtJSON(result, false, false)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filepath.Base(path), err)
+	}
+
+	var pretty interface{}
+	if err := json.Unmarshal(data, &pretty); err == nil {
+		if indented, err := json.MarshalIndent(pretty, "", "  "); err == nil {
+			data = indented
+		}
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package diagnostics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestLookupString(t *testing.T) {
+	m := bson.M{
+		"storage":      bson.M{"dbPath": "/data/db", "engine": 1},
+		"setParameter": bson.M{},
+		"net":          "not a document",
+	}
+	for _, tc := range []struct {
+		path []string
+		want string
+	}{
+		{[]string{"storage", "dbPath"}, "/data/db"},
+		{[]string{"storage", "engine"}, ""},
+		{[]string{"setParameter", "diagnosticDataCollectionDirectoryPath"}, ""},
+		{[]string{"net", "port"}, ""},
+		{[]string{"missing", "key"}, ""},
+		{[]string{"net"}, "not a document"},
+	} {
+		if got := lookupString(m, tc.path...); got != tc.want {
+			t.Errorf("%v: expected %q, got %q", tc.path, tc.want, got)
+		}
+	}
+	if got := lookupString(nil, "storage", "dbPath"); got != "" {
+		t.Errorf("expected nothing from a nil map, got %q", got)
+	}
+}
+
+func TestWriteFTDCNote(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts bson.M
+		want string
+	}{
+		{
+			"explicit",
+			bson.M{"parsed": bson.M{
+				"setParameter": bson.M{"diagnosticDataCollectionDirectoryPath": "/var/ftdc"},
+				"storage":      bson.M{"dbPath": "/data/db"},
+			}},
+			"FTDC directory on the server host: /var/ftdc\n",
+		},
+		{
+			"dbpath",
+			bson.M{"parsed": bson.M{"storage": bson.M{"dbPath": "/data/db"}}},
+			"FTDC directory on the server host: " + filepath.Join("/data/db", "diagnostic.data") + "\n",
+		},
+		{
+			"unknown",
+			bson.M{},
+			"FTDC directory on the server host: unknown (not reported by getCmdLineOpts; for Atlas, download FTDC from the cluster's monitoring page)\n",
+		},
+	} {
+		path := filepath.Join(t.TempDir(), "ftdc.txt")
+		if err := writeFTDCNote(path, tc.opts); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != tc.want {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.want, content)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// hgrmTicksPerHalfDistance is the number of percentile lines written per
+// halving of the distance to 100%, as in HdrHistogram's default output
+const hgrmTicksPerHalfDistance = 5
+
+// SetHistogramDir additionally writes the latency distribution of each
+// operation type to dir/<TYPE>.hgrm when the logger is closed
+func (l *YCSBLogger) SetHistogramDir(dir string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.histogramDir = dir
+}
+
+// HistogramFiles returns the .hgrm files written when the logger was closed
+func (l *YCSBLogger) HistogramFiles() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.histogramFiles...)
+}
+
+// writeHistograms writes one .hgrm file per recorded operation type to the
+// histogram directory
+func (l *YCSBLogger) writeHistograms() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.histogramDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(l.histogramDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create histogram directory: %w", err)
+	}
+
+	latenciesByType := make(map[string][]int64)
+	for _, op := range l.operations {
+		latenciesByType[op.Type] = append(latenciesByType[op.Type], op.LatencyUs)
+	}
+	types := make([]string, 0, len(latenciesByType))
+	for opType := range latenciesByType {
+		types = append(types, opType)
+	}
+	sort.Strings(types)
+
+	for _, opType := range types {
+		path := filepath.Join(l.histogramDir, opType+".hgrm")
+		file, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create histogram file: %w", err)
+		}
+		err = WriteHgrm(file, latenciesByType[opType])
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write histogram file %s: %w", path, err)
+		}
+		l.histogramFiles = append(l.histogramFiles, path)
+	}
+	return nil
+}
+
+// WriteHgrm writes the distribution of latencies, in microseconds, as an
+// HdrHistogram percentile distribution in milliseconds, the .hgrm format read
+// by HdrHistogram's plotting tools. Values are exact rather than bucketed.
+func WriteHgrm(w io.Writer, latenciesUs []int64) error {
+	latencies := append([]int64(nil), latenciesUs...)
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	n := len(latencies)
+	ms := func(us int64) float64 { return float64(us) / 1000 }
+
+	if _, err := fmt.Fprintf(w, "%12s %14s %10s %14s\n\n", "Value", "Percentile", "TotalCount", "1/(1-Percentile)"); err != nil {
+		return err
+	}
+	if n == 0 {
+		return writeHgrmFooter(w, 0, 0, 0, 0)
+	}
+
+	// Step through percentiles like HdrHistogram does, with finer steps the
+	// closer they get to 100%, until the maximum is reached
+	for percentile := 0.0; ; {
+		value := latencies[max(int(math.Ceil(percentile/100*float64(n)))-1, 0)]
+		count := sort.Search(n, func(i int) bool { return latencies[i] > value })
+		if count == n {
+			break
+		}
+		if _, err := fmt.Fprintf(w, "%12.3f %2.12f %10d %14.2f\n", ms(value), percentile/100, count, 1/(1-percentile/100)); err != nil {
+			return err
+		}
+		halvings := math.Floor(math.Log2(100/(100-percentile))) + 1
+		percentile += 100 / (hgrmTicksPerHalfDistance * math.Pow(2, halvings))
+	}
+	if _, err := fmt.Fprintf(w, "%12.3f %2.12f %10d\n", ms(latencies[n-1]), 1.0, n); err != nil {
+		return err
+	}
+
+	var sum float64
+	for _, us := range latencies {
+		sum += ms(us)
+	}
+	mean := sum / float64(n)
+	var squares float64
+	for _, us := range latencies {
+		squares += (ms(us) - mean) * (ms(us) - mean)
+	}
+	return writeHgrmFooter(w, mean, math.Sqrt(squares/float64(n)), ms(latencies[n-1]), n)
+}
+
+// writeHgrmFooter writes the summary lines that end an .hgrm file. The
+// bucket line describes a histogram with 3 significant digits of precision
+// covering microsecond latencies up to maxMs.
+func writeHgrmFooter(w io.Writer, mean, stdDev, maxMs float64, count int) error {
+	const subBuckets = 2048
+	buckets := 1
+	for limit := int64(subBuckets); limit <= int64(maxMs*1000); limit <<= 1 {
+		buckets++
+	}
+	_, err := fmt.Fprintf(w, "#[Mean    = %12.3f, StdDeviation   = %12.3f]\n"+
+		"#[Max     = %12.3f, Total count    = %12d]\n"+
+		"#[Buckets = %12d, SubBuckets     = %12d]\n",
+		mean, stdDev, maxMs, count, buckets, subBuckets)
+	return err
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteHgrm(t *testing.T) {
+	latencies := make([]int64, 1000)
+	for i := range latencies {
+		latencies[i] = int64(i+1) * 100 // 0.1ms to 100ms
+	}
+	var buf bytes.Buffer
+	if err := WriteHgrm(&buf, latencies); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if !strings.Contains(lines[0], "1/(1-Percentile)") {
+		t.Errorf("Unexpected header %q", lines[0])
+	}
+	if fields := strings.Fields(lines[2]); fields[0] != "0.100" || fields[2] != "1" {
+		t.Errorf("Unexpected first line %q", lines[2])
+	}
+	if fields := strings.Fields(lines[len(lines)-4]); len(fields) != 3 || fields[0] != "100.000" || fields[1] != "1.000000000000" || fields[2] != "1000" {
+		t.Errorf("Unexpected last line %q", lines[len(lines)-4])
+	}
+	if !strings.Contains(buf.String(), "#[Max     =      100.000, Total count    =         1000]") {
+		t.Errorf("Unexpected footer:\n%s", buf.String())
+	}
+}
+
+func TestYCSBLoggerHistogramFiles(t *testing.T) {
+	dir := t.TempDir()
+	l, err := NewYCSBLogger(filepath.Join(dir, "ycsb.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.SetHistogramDir(filepath.Join(dir, "hgrm"))
+	l.RecordOperation("INSERT", 2*time.Millisecond, true)
+	l.RecordOperation("READ", time.Millisecond, true)
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files := l.HistogramFiles()
+	if len(files) != 2 || filepath.Base(files[0]) != "INSERT.hgrm" || filepath.Base(files[1]) != "READ.hgrm" {
+		t.Fatalf("Unexpected histogram files %v", files)
+	}
+	if _, err := os.Stat(files[0]); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,162 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SLO is a latency objective of one operation type, e.g. INSERT p99 < 50ms
+type SLO struct {
+	Operation  string // YCSB operation type
+	Percentile float64
+	Max        time.Duration
+}
+
+// String formats the objective as "INSERT p99 < 50ms"
+func (s SLO) String() string {
+	return fmt.Sprintf("%s p%s < %v", s.Operation, strconv.FormatFloat(s.Percentile, 'f', -1, 64), s.Max)
+}
+
+// ParseSLOs parses comma-separated objectives as OPERATION:pNN<DURATION,
+// e.g. "insert:p99<50ms,read:p95<10ms"
+func ParseSLOs(list string) ([]SLO, error) {
+	var slos []SLO
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		op, objective, ok := strings.Cut(item, ":")
+		percentile, bound, ok2 := strings.Cut(objective, "<")
+		if !ok || !ok2 || op == "" || !strings.HasPrefix(percentile, "p") {
+			return nil, fmt.Errorf("invalid SLO %q (use OPERATION:pNN<DURATION, e.g. insert:p99<50ms)", item)
+		}
+		p, err := strconv.ParseFloat(strings.TrimPrefix(percentile, "p"), 64)
+		if err != nil || p <= 0 || p > 100 {
+			return nil, fmt.Errorf("invalid SLO percentile %q in %q", percentile, item)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(bound))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid SLO latency %q in %q", bound, item)
+		}
+		slos = append(slos, SLO{Operation: strings.ToUpper(strings.TrimSpace(op)), Percentile: p, Max: d})
+	}
+	return slos, nil
+}
+
+// SLOResult is the compliance of one objective over the intervals of a run
+type SLOResult struct {
+	SLO               string  `json:"slo"`
+	Intervals         int     `json:"intervals"` // Intervals with operations of the type
+	Met               int     `json:"met"`
+	CompliancePercent float64 `json:"compliance_percent"`
+	WorstMs           float64 `json:"worst_ms"` // Highest percentile latency of an interval
+}
+
+// SLOMonitor checks latency objectives over fixed intervals: an objective is
+// met in an interval if the percentile latency of the operations of its type
+// completed in the interval is below its maximum
+type SLOMonitor struct {
+	mu          sync.Mutex
+	slos        []SLO
+	latencies   map[string][]int64 // Operation type -> latencies in the current interval
+	results     []SLOResult
+	abortAfter  int // Consecutive violating intervals that abort the run (0 = never)
+	consecutive []int
+}
+
+// NewSLOMonitor creates a monitor of slos that aborts the run once an
+// objective is violated for abortAfter consecutive intervals (0 = never)
+func NewSLOMonitor(slos []SLO, abortAfter int) *SLOMonitor {
+	m := &SLOMonitor{
+		slos:        slos,
+		latencies:   make(map[string][]int64),
+		results:     make([]SLOResult, len(slos)),
+		abortAfter:  abortAfter,
+		consecutive: make([]int, len(slos)),
+	}
+	for i, s := range slos {
+		m.results[i].SLO = s.String()
+	}
+	return m
+}
+
+// Record adds an operation completed in the current interval
+func (m *SLOMonitor) Record(opType string, latencyUs int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range m.slos {
+		if s.Operation == opType {
+			m.latencies[opType] = append(m.latencies[opType], latencyUs)
+			return
+		}
+	}
+}
+
+// Run checks the objectives every interval until ctx ends. Once an objective
+// is violated for abortAfter consecutive intervals, abort is called with the
+// reason and the monitor stops.
+func (m *SLOMonitor) Run(ctx context.Context, interval time.Duration, abort func(reason string)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if reason := m.check(); reason != "" {
+				abort(reason)
+				return
+			}
+		}
+	}
+}
+
+// check evaluates the objectives over the interval that just ended and
+// returns why the run must abort, if it must
+func (m *SLOMonitor) check() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var reason string
+	for i, s := range m.slos {
+		latencies := m.latencies[s.Operation]
+		if len(latencies) == 0 {
+			continue
+		}
+		sort.Slice(latencies, func(a, b int) bool { return latencies[a] < latencies[b] })
+		index := min(int(float64(len(latencies))*s.Percentile/100), len(latencies)-1)
+		latency := time.Duration(latencies[index]) * time.Microsecond
+
+		r := &m.results[i]
+		r.Intervals++
+		r.WorstMs = max(r.WorstMs, float64(latency)/float64(time.Millisecond))
+		if latency < s.Max {
+			r.Met++
+			m.consecutive[i] = 0
+		} else {
+			m.consecutive[i]++
+		}
+		r.CompliancePercent = float64(r.Met) / float64(r.Intervals) * 100
+		if m.abortAfter > 0 && m.consecutive[i] >= m.abortAfter && reason == "" {
+			reason = fmt.Sprintf("SLO %s violated for %d consecutive intervals (last %v)", s, m.consecutive[i], latency)
+		}
+	}
+	m.latencies = make(map[string][]int64)
+	return reason
+}
+
+// Results checks the interval in progress and returns the compliance of each
+// objective, in the order they were given
+func (m *SLOMonitor) Results() []SLOResult {
+	m.check()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]SLOResult(nil), m.results...)
+}
@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSLOs(t *testing.T) {
+	slos, err := ParseSLOs("insert:p99<50ms, read:p99.9<1s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(slos) != 2 || slos[0] != (SLO{Operation: "INSERT", Percentile: 99, Max: 50 * time.Millisecond}) || slos[1].Percentile != 99.9 {
+		t.Errorf("Unexpected SLOs %+v", slos)
+	}
+	if slos[0].String() != "INSERT p99 < 50ms" {
+		t.Errorf("Unexpected format %s", slos[0])
+	}
+
+	for _, list := range []string{"insert<50ms", "insert:99<50ms", "insert:p0<50ms", "insert:p101<50ms", "insert:p99<fast", "insert:p99<0s"} {
+		if _, err := ParseSLOs(list); err == nil {
+			t.Errorf("Expected an error parsing %q", list)
+		}
+	}
+}
+
+func TestSLOMonitorCompliance(t *testing.T) {
+	m := NewSLOMonitor([]SLO{{Operation: "INSERT", Percentile: 99, Max: 50 * time.Millisecond}}, 2)
+
+	// Met, violated, no inserts, then violated again: two consecutive violations
+	intervals := [][]int64{{10000, 20000}, {10000, 90000}, nil, {60000}}
+	var reason string
+	for _, latencies := range intervals {
+		for _, us := range latencies {
+			m.Record("INSERT", us)
+			m.Record("READ", 500000)
+		}
+		reason = m.check()
+	}
+	if !strings.Contains(reason, "2 consecutive intervals") {
+		t.Errorf("Expected an abort after 2 violations, got %q", reason)
+	}
+
+	results := m.Results()
+	if r := results[0]; r.Intervals != 3 || r.Met != 1 || r.WorstMs != 90 {
+		t.Errorf("Unexpected results %+v", r)
+	}
+}
@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// statsdPacketSize bounds the size of one UDP packet of newline-separated
+// metrics, so it is not fragmented on a typical network
+const statsdPacketSize = 1432
+
+// StatsdEmitter pushes throughput and latency gauges per operation type to a
+// statsd server over UDP every interval, for Graphite-based dashboards.
+// Sends are fire-and-forget, so an unreachable server never slows a run.
+type StatsdEmitter struct {
+	mu          sync.Mutex
+	conn        net.Conn
+	prefix      string
+	interval    time.Duration
+	last        time.Time
+	latencies   map[string][]int64 // Operation type -> latencies in the current interval
+	errors      map[string]int64
+	bytes       int64 // Bytes written so far
+	bytesAtLast int64
+
+	seen map[string]bool // Operation types recorded so far, reported as idle once they stop
+}
+
+// NewStatsdEmitter creates an emitter sending to the statsd server at addr
+// (host:port) every interval, with metric names under prefix
+func NewStatsdEmitter(addr, prefix string, interval time.Duration) (*StatsdEmitter, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("statsd interval must be positive")
+	}
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to statsd at %s: %w", addr, err)
+	}
+	return &StatsdEmitter{
+		conn:      conn,
+		prefix:    strings.TrimSuffix(prefix, "."),
+		interval:  interval,
+		last:      time.Now(),
+		latencies: make(map[string][]int64),
+		errors:    make(map[string]int64),
+		seen:      make(map[string]bool),
+	}, nil
+}
+
+// Record adds an operation completed in the current interval
+func (s *StatsdEmitter) Record(opType string, latencyUs int64, failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latencies[opType] = append(s.latencies[opType], latencyUs)
+	s.seen[opType] = true
+	if failed {
+		s.errors[opType]++
+	}
+}
+
+// SetBytesWritten updates the bytes written so far, for the MB/s gauge
+func (s *StatsdEmitter) SetBytesWritten(bytes int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bytes = bytes
+}
+
+// Run sends the gauges of each interval until ctx ends
+func (s *StatsdEmitter) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.flush(now)
+		}
+	}
+}
+
+// flush sends the gauges of the interval ending at now and starts the next
+func (s *StatsdEmitter) flush(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elapsed := now.Sub(s.last).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	var metrics []string
+	gauge := func(name string, value float64) {
+		metrics = append(metrics, fmt.Sprintf("%s.%s:%.3f|g", s.prefix, name, value))
+	}
+
+	// Operation types idle in the interval report zeros, so their gauges
+	// don't keep showing the last busy interval
+	opTypes := make([]string, 0, len(s.seen))
+	for opType := range s.seen {
+		opTypes = append(opTypes, opType)
+	}
+	sort.Strings(opTypes)
+	for _, opType := range opTypes {
+		latencies := s.latencies[opType]
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		n := len(latencies)
+		name := strings.ToLower(opType)
+		gauge(name+".ops_per_sec", float64(n)/elapsed)
+		if n == 0 {
+			gauge(name+".errors_per_sec", 0)
+			gauge(name+".p50_ms", 0)
+			gauge(name+".p99_ms", 0)
+			gauge(name+".max_ms", 0)
+			continue
+		}
+		gauge(name+".errors_per_sec", float64(s.errors[opType])/elapsed)
+		gauge(name+".p50_ms", float64(latencies[(n-1)*50/100])/1000)
+		gauge(name+".p99_ms", float64(latencies[(n-1)*99/100])/1000)
+		gauge(name+".max_ms", float64(latencies[n-1])/1000)
+	}
+	if s.bytes > 0 {
+		gauge("mb_per_sec", float64(s.bytes-s.bytesAtLast)/elapsed/(1024*1024))
+		gauge("bytes_written", float64(s.bytes))
+	}
+
+	s.send(metrics)
+	s.last, s.bytesAtLast = now, s.bytes
+	s.latencies = make(map[string][]int64)
+	s.errors = make(map[string]int64)
+}
+
+// send writes metrics in as few packets as fit statsdPacketSize. Write
+// errors (e.g. nothing listening) are ignored, as statsd is lossy by design.
+func (s *StatsdEmitter) send(metrics []string) {
+	var packet strings.Builder
+	for _, m := range metrics {
+		if packet.Len() > 0 && packet.Len()+1+len(m) > statsdPacketSize {
+			s.conn.Write([]byte(packet.String()))
+			packet.Reset()
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(m)
+	}
+	if packet.Len() > 0 {
+		s.conn.Write([]byte(packet.String()))
+	}
+}
+
+// Close sends the gauges of the interval in progress and closes the connection
+func (s *StatsdEmitter) Close() error {
+	s.flush(time.Now())
+	return s.conn.Close()
+}
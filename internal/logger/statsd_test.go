@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatsdEmitterGauges(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	s, err := NewStatsdEmitter(server.LocalAddr().String(), "gendata.", 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, us := range []int64{1000, 2000, 3000, 4000} {
+		s.Record("INSERT", us, us == 4000)
+	}
+	s.SetBytesWritten(2 * 1024 * 1024)
+	s.flush(s.last.Add(2 * time.Second))
+
+	buf := make([]byte, statsdPacketSize)
+	server.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := server.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := strings.Split(string(buf[:n]), "\n")
+	for _, want := range []string{
+		"gendata.insert.ops_per_sec:2.000|g",
+		"gendata.insert.errors_per_sec:0.500|g",
+		"gendata.insert.p50_ms:2.000|g",
+		"gendata.insert.max_ms:4.000|g",
+		"gendata.mb_per_sec:1.000|g",
+	} {
+		found := false
+		for _, m := range metrics {
+			found = found || m == want
+		}
+		if !found {
+			t.Errorf("Expected %s in %v", want, metrics)
+		}
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStatsdEmitterIdle(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	s, err := NewStatsdEmitter(server.LocalAddr().String(), "gendata", 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	read := func() []string {
+		buf := make([]byte, statsdPacketSize)
+		server.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := server.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Split(string(buf[:n]), "\n")
+	}
+
+	s.Record("READ", 5000, false)
+	s.flush(s.last.Add(time.Second))
+	if busy := read(); len(busy) != 5 || busy[0] != "gendata.read.ops_per_sec:1.000|g" {
+		t.Fatalf("Unexpected busy interval %v", busy)
+	}
+
+	// A stalled operation type reports zeros instead of going silent
+	s.flush(s.last.Add(time.Second))
+	idle := read()
+	for _, want := range []string{
+		"gendata.read.ops_per_sec:0.000|g",
+		"gendata.read.errors_per_sec:0.000|g",
+		"gendata.read.p99_ms:0.000|g",
+	} {
+		found := false
+		for _, m := range idle {
+			found = found || m == want
+		}
+		if !found {
+			t.Errorf("Expected %s in %v", want, idle)
+		}
+	}
+}
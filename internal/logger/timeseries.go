@@ -0,0 +1,217 @@
+package logger
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TimeSeries records per-second throughput and latency percentiles per
+// operation type and streams each completed second to a CSV or JSON Lines
+// file, so latency spikes (checkpoints, chunk migrations) are visible after
+// the run and survive a crash
+type TimeSeries struct {
+	mu      sync.Mutex
+	file    *os.File
+	csv     *csv.Writer   // CSV output
+	json    *json.Encoder // JSON Lines output
+	start   time.Time
+	buckets map[int64]map[string]*secondBucket // Second since start -> operation type -> bucket
+	flushed int64                              // Seconds before this one have been written
+	retain  bool                               // Keep written rows for Rows
+	rows    []TimeSeriesRow
+}
+
+// secondBucket collects the operations of one type completed in one second
+type secondBucket struct {
+	latenciesUs []int64
+	errors      int64
+}
+
+// TimeSeriesRow is one exported sample
+type TimeSeriesRow struct {
+	Time      time.Time `json:"time"`
+	Second    int64     `json:"second"` // Seconds since the run started
+	Operation string    `json:"operation"`
+	Ops       int       `json:"ops"` // Operations completed in this second, i.e. ops/sec
+	Errors    int64     `json:"errors"`
+	P50Us     int64     `json:"p50_us"`
+	P99Us     int64     `json:"p99_us"`
+	MaxUs     int64     `json:"max_us"`
+}
+
+// NewTimeSeries creates a time series file. The format is JSON Lines if the
+// path ends in .json or .jsonl, CSV otherwise. An empty path writes no file,
+// for a time series only read back with Rows.
+func NewTimeSeries(path string, start time.Time) (*TimeSeries, error) {
+	t := &TimeSeries{
+		start:   start,
+		buckets: make(map[int64]map[string]*secondBucket),
+	}
+	if path == "" {
+		return t, nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create time series file: %w", err)
+	}
+	t.file = file
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".jsonl":
+		t.json = json.NewEncoder(file)
+	default:
+		t.csv = csv.NewWriter(file)
+		t.csv.Write([]string{"time", "second", "operation", "ops", "errors", "p50_us", "p99_us", "max_us"})
+		t.csv.Flush()
+	}
+	return t, nil
+}
+
+// Retain keeps every row written from now on in memory, for Rows
+func (t *TimeSeries) Retain() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.retain = true
+}
+
+// Rows returns the retained rows, in order of time then operation type
+func (t *TimeSeries) Rows() []TimeSeriesRow {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]TimeSeriesRow(nil), t.rows...)
+}
+
+// Record adds an operation that completed at the given time
+func (t *TimeSeries) Record(opType string, completedAt time.Time, latencyUs int64, failed bool) {
+	second := int64(completedAt.Sub(t.start) / time.Second)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if second < t.flushed {
+		// Second already written; count it in the oldest open second
+		second = t.flushed
+	}
+	ops, ok := t.buckets[second]
+	if !ok {
+		ops = make(map[string]*secondBucket)
+		t.buckets[second] = ops
+	}
+	b, ok := ops[opType]
+	if !ok {
+		b = &secondBucket{}
+		ops[opType] = b
+	}
+	b.latenciesUs = append(b.latenciesUs, latencyUs)
+	if failed {
+		b.errors++
+	}
+}
+
+// Run writes each completed second until ctx ends
+func (t *TimeSeries) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			t.flush(int64(now.Sub(t.start) / time.Second))
+		}
+	}
+}
+
+// flush writes all seconds before until
+func (t *TimeSeries) flush(until int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for ; t.flushed < until; t.flushed++ {
+		ops, ok := t.buckets[t.flushed]
+		if !ok {
+			continue
+		}
+		delete(t.buckets, t.flushed)
+
+		opTypes := make([]string, 0, len(ops))
+		for opType := range ops {
+			opTypes = append(opTypes, opType)
+		}
+		sort.Strings(opTypes)
+		for _, opType := range opTypes {
+			t.write(t.row(t.flushed, opType, ops[opType]))
+		}
+	}
+	if t.csv != nil {
+		t.csv.Flush()
+	}
+}
+
+// row summarizes a bucket
+func (t *TimeSeries) row(second int64, opType string, b *secondBucket) TimeSeriesRow {
+	sort.Slice(b.latenciesUs, func(i, j int) bool { return b.latenciesUs[i] < b.latenciesUs[j] })
+	n := len(b.latenciesUs)
+	return TimeSeriesRow{
+		Time:      t.start.Add(time.Duration(second) * time.Second),
+		Second:    second,
+		Operation: opType,
+		Ops:       n,
+		Errors:    b.errors,
+		P50Us:     b.latenciesUs[(n-1)*50/100],
+		P99Us:     b.latenciesUs[(n-1)*99/100],
+		MaxUs:     b.latenciesUs[n-1],
+	}
+}
+
+// write outputs one row
+func (t *TimeSeries) write(row TimeSeriesRow) {
+	if t.retain {
+		t.rows = append(t.rows, row)
+	}
+	if t.json != nil {
+		t.json.Encode(row)
+		return
+	}
+	if t.csv == nil {
+		return
+	}
+	t.csv.Write([]string{
+		row.Time.Format(time.RFC3339),
+		strconv.FormatInt(row.Second, 10),
+		row.Operation,
+		strconv.Itoa(row.Ops),
+		strconv.FormatInt(row.Errors, 10),
+		strconv.FormatInt(row.P50Us, 10),
+		strconv.FormatInt(row.P99Us, 10),
+		strconv.FormatInt(row.MaxUs, 10),
+	})
+}
+
+// Close writes all remaining seconds and closes the file
+func (t *TimeSeries) Close() error {
+	t.mu.Lock()
+	last := t.flushed
+	for second := range t.buckets {
+		if second >= last {
+			last = second + 1
+		}
+	}
+	t.mu.Unlock()
+
+	t.flush(last)
+	if t.file == nil {
+		return nil
+	}
+	return t.file.Close()
+}
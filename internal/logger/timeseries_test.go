@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTimeSeriesCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "timeseries.csv")
+	start := time.Now()
+	ts, err := NewTimeSeries(path, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Second 0: 100 inserts at 1..100us; second 2: one failed read
+	for i := int64(1); i <= 100; i++ {
+		ts.Record("INSERT", start.Add(500*time.Millisecond), i, false)
+	}
+	ts.Record("READ", start.Add(2500*time.Millisecond), 7000, true)
+	if err := ts.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("Expected header and 2 rows, got %d records", len(records))
+	}
+	insert := records[1]
+	if insert[1] != "0" || insert[2] != "INSERT" || insert[3] != "100" || insert[5] != "50" || insert[6] != "99" || insert[7] != "100" {
+		t.Errorf("Unexpected INSERT row: %v", insert)
+	}
+	read := records[2]
+	if read[1] != "2" || read[2] != "READ" || read[4] != "1" {
+		t.Errorf("Unexpected READ row: %v", read)
+	}
+}
+
+func TestTimeSeriesJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "timeseries.json")
+	start := time.Now()
+	ts, err := NewTimeSeries(path, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.Record("UPDATE", start, 1500, false)
+	ts.flush(1)
+	// Late arrivals for a written second go into the next one
+	ts.Record("UPDATE", start, 2500, false)
+	if err := ts.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var rows []TimeSeriesRow
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var row TimeSeriesRow
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) != 2 || rows[0].Second != 0 || rows[1].Second != 1 || rows[1].P50Us != 2500 {
+		t.Errorf("Unexpected rows: %+v", rows)
+	}
+}
+
+func TestTimeSeriesInMemory(t *testing.T) {
+	start := time.Now()
+	ts, err := NewTimeSeries("", start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.Retain()
+	ts.Record("INSERT", start, 100, false)
+	ts.Record("INSERT", start.Add(1500*time.Millisecond), 300, true)
+	if err := ts.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := ts.Rows()
+	if len(rows) != 2 || rows[0].Second != 0 || rows[1].Second != 1 || rows[1].Errors != 1 || rows[1].MaxUs != 300 {
+		t.Errorf("Unexpected rows: %+v", rows)
+	}
+}
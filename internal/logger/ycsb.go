@@ -18,11 +18,19 @@ type YCSBLogger struct {
 	startTime       time.Time
 	errorCount      int64
 	successCount    int64
+	timeoutCount    int64
 	lastLogTime     time.Time
 	lastOpCount     int64
 	targetBytes     int64
 	bytesWritten    int64
 	workloadName    string
+	closeOnce       sync.Once
+	closeErr        error
+	timeSeries      *TimeSeries // Optional per-second export
+	sloMonitor      *SLOMonitor // Optional latency objectives
+	statsd          *StatsdEmitter // Optional statsd gauges
+	histogramDir    string      // Optional .hgrm export on close
+	histogramFiles  []string
 }
 
 // Operation represents a single operation with timing
@@ -30,6 +38,7 @@ type Operation struct {
 	Type      string
 	LatencyUs int64 // Latency in microseconds
 	Success   bool
+	Timeout   bool // Failed because the operation deadline expired
 }
 
 // NewYCSBLogger creates a new YCSB logger that writes to a file
@@ -65,6 +74,33 @@ func (l *YCSBLogger) UpdateBytesWritten(bytes int64) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 	l.bytesWritten = bytes
+	if l.statsd != nil {
+		l.statsd.SetBytesWritten(bytes)
+	}
+}
+
+// SetTimeSeries additionally records every operation into a per-second
+// time series, which is closed together with the logger
+func (l *YCSBLogger) SetTimeSeries(ts *TimeSeries) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.timeSeries = ts
+}
+
+// SetSLOMonitor additionally records every operation into a monitor of
+// latency objectives
+func (l *YCSBLogger) SetSLOMonitor(m *SLOMonitor) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sloMonitor = m
+}
+
+// SetStatsd additionally records every operation into a statsd emitter,
+// which is closed together with the logger
+func (l *YCSBLogger) SetStatsd(s *StatsdEmitter) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.statsd = s
 }
 
 // writeHeader writes the YCSB log header
@@ -92,6 +128,38 @@ func (l *YCSBLogger) RecordOperation(opType string, latency time.Duration, succe
 	} else {
 		l.errorCount++
 	}
+	if l.timeSeries != nil {
+		l.timeSeries.Record(opType, time.Now(), latencyUs, !success)
+	}
+	if l.sloMonitor != nil {
+		l.sloMonitor.Record(opType, latencyUs)
+	}
+	if l.statsd != nil {
+		l.statsd.Record(opType, latencyUs, !success)
+	}
+}
+
+// RecordTimeout records an operation that failed because its deadline expired.
+// Timeouts are counted separately from other errors.
+func (l *YCSBLogger) RecordTimeout(opType string, latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.operations = append(l.operations, Operation{
+		Type:      opType,
+		LatencyUs: latency.Microseconds(),
+		Timeout:   true,
+	})
+	l.timeoutCount++
+	if l.timeSeries != nil {
+		l.timeSeries.Record(opType, time.Now(), latency.Microseconds(), true)
+	}
+	if l.sloMonitor != nil {
+		l.sloMonitor.Record(opType, latency.Microseconds())
+	}
+	if l.statsd != nil {
+		l.statsd.Record(opType, latency.Microseconds(), true)
+	}
 }
 
 // StartPeriodicLogging starts a goroutine that logs statistics every 10 seconds
@@ -279,11 +347,38 @@ func formatDuration(d time.Duration) string {
 	return strings.Join(parts, " ")
 }
 
-// Close closes the log file and writes final statistics
+// Close closes the log file and writes final statistics. It is safe to call
+// more than once.
 func (l *YCSBLogger) Close() error {
-	// Write final statistics summary in multi-line format
-	l.WriteFinalStats()
-	return l.file.Close()
+	l.closeOnce.Do(func() {
+		// Write final statistics summary in multi-line format
+		l.WriteFinalStats()
+		l.closeErr = l.file.Close()
+		if err := l.writeHistograms(); err != nil && l.closeErr == nil {
+			l.closeErr = err
+		}
+		if l.timeSeries != nil {
+			if err := l.timeSeries.Close(); err != nil && l.closeErr == nil {
+				l.closeErr = err
+			}
+		}
+		if l.statsd != nil {
+			if err := l.statsd.Close(); err != nil && l.closeErr == nil {
+				l.closeErr = err
+			}
+		}
+	})
+	return l.closeErr
+}
+
+// Abort records that the run terminated abnormally, then closes the log with
+// the final statistics of the operations recorded so far
+func (l *YCSBLogger) Abort(reason string) error {
+	l.mu.Lock()
+	l.file.WriteString(fmt.Sprintf("%s [error  ] [%s] [ABORTED], %s\n",
+		time.Now().Format("[2006/01/02 15:04:05.000]"), l.workloadName, reason))
+	l.mu.Unlock()
+	return l.Close()
 }
 
 // WriteFinalStats writes comprehensive final statistics in multi-line YCSB format
@@ -336,12 +431,15 @@ func (l *YCSBLogger) writeFinalOperationStats(opType string, ops []Operation, ti
 	var totalLatency int64
 	successCount := int64(0)
 	errorCount := int64(0)
+	timeoutCount := int64(0)
 
 	for i, op := range ops {
 		latencies[i] = op.LatencyUs
 		totalLatency += op.LatencyUs
 		if op.Success {
 			successCount++
+		} else if op.Timeout {
+			timeoutCount++
 		} else {
 			errorCount++
 		}
@@ -420,4 +518,66 @@ func (l *YCSBLogger) writeFinalOperationStats(opType string, ops []Operation, ti
 		l.file.WriteString(fmt.Sprintf("%s [info   ] [%s] [%s], Return=ERROR, Count, %d\n",
 			timestamp, l.workloadName, opType, errorCount))
 	}
+	if timeoutCount > 0 {
+		l.file.WriteString(fmt.Sprintf("%s [info   ] [%s] [%s], Return=TIMEOUT, Count, %d\n",
+			timestamp, l.workloadName, opType, timeoutCount))
+	}
+}
+
+// OperationStats summarizes the recorded operations of one type
+type OperationStats struct {
+	Type     string
+	Count    int64
+	Errors   int64 // Failed, not counting timeouts
+	Timeouts int64
+	AvgUs    float64
+	MinUs    int64
+	P50Us    int64
+	P95Us    int64
+	P99Us    int64
+	P999Us   int64
+	MaxUs    int64
+}
+
+// Stats returns the statistics of each operation type recorded so far,
+// sorted by type
+func (l *YCSBLogger) Stats() []OperationStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	opsByType := make(map[string][]Operation)
+	for _, op := range l.operations {
+		opsByType[op.Type] = append(opsByType[op.Type], op)
+	}
+
+	stats := make([]OperationStats, 0, len(opsByType))
+	for opType, ops := range opsByType {
+		s := OperationStats{Type: opType, Count: int64(len(ops))}
+		latencies := make([]int64, len(ops))
+		var totalLatency int64
+		for i, op := range ops {
+			latencies[i] = op.LatencyUs
+			totalLatency += op.LatencyUs
+			if op.Timeout {
+				s.Timeouts++
+			} else if !op.Success {
+				s.Errors++
+			}
+		}
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+		percentile := func(p float64) int64 {
+			return latencies[min(int(float64(len(latencies))*p), len(latencies)-1)]
+		}
+		s.AvgUs = float64(totalLatency) / float64(len(ops))
+		s.MinUs = latencies[0]
+		s.P50Us = percentile(0.50)
+		s.P95Us = percentile(0.95)
+		s.P99Us = percentile(0.99)
+		s.P999Us = percentile(0.999)
+		s.MaxUs = latencies[len(latencies)-1]
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Type < stats[j].Type })
+	return stats
 }
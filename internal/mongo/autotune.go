@@ -0,0 +1,217 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"golang.org/x/sync/errgroup"
+)
+
+// AutoTuneConfig configures the calibration bursts a writer runs before it
+// loads with the best writer count and batch size
+type AutoTuneConfig struct {
+	Writers    []int         // Writer counts tried with the configured batch size
+	BatchSizes []int         // Batch sizes tried with the best writer count
+	Burst      time.Duration // Length of each burst
+	MaxLatency time.Duration // Bound on the p95 batch latency (0 = none)
+}
+
+// validate checks the candidates and burst length
+func (c *AutoTuneConfig) validate() error {
+	if len(c.Writers) == 0 || len(c.BatchSizes) == 0 {
+		return fmt.Errorf("auto-tuning needs writer counts and batch sizes to try")
+	}
+	if slices.Min(c.Writers) <= 0 || slices.Min(c.BatchSizes) <= 0 {
+		return fmt.Errorf("auto-tuning writer counts and batch sizes must be positive")
+	}
+	if c.Burst <= 0 || c.MaxLatency < 0 {
+		return fmt.Errorf("auto-tuning bursts must be positive and the latency bound not negative")
+	}
+	return nil
+}
+
+// TuneBurst is the outcome of one calibration burst
+type TuneBurst struct {
+	Writers     int     `json:"writers"`
+	BatchSize   int     `json:"batch_size"`
+	MBPerSecond float64 `json:"mb_per_second"` // After the first fifth of the burst
+	P95Ms       float64 `json:"p95_ms"`        // Of the batches sent after the first fifth
+	WithinBound bool    `json:"within_bound"`  // P95Ms is within the latency bound
+}
+
+// TuneResult lists the calibration bursts and the configuration the load
+// continued with
+type TuneResult struct {
+	Bursts    []TuneBurst `json:"bursts"`
+	Writers   int         `json:"writers"`
+	BatchSize int         `json:"batch_size"`
+}
+
+// calibrate runs calibration bursts with documents from docChan, first over
+// the writer counts and then over the batch sizes, and switches the writer to
+// the burst with the highest throughput within the latency bound, or the
+// lowest latency if none is. Documents written in bursts count toward the
+// target. It stops early once docChan is closed or the target is claimed.
+func (w *Writer) calibrate(ctx context.Context, docChan <-chan model.Document, config *AutoTuneConfig) error {
+	result := &TuneResult{}
+	run := func(writers, batchSize int) (bool, error) {
+		burst, done, err := w.burst(ctx, docChan, writers, batchSize, config)
+		if err != nil || done {
+			return true, err
+		}
+		log.Printf("Auto-tune: %d writers, batches of %d: %.1f MB/s, p95 %.1fms",
+			writers, batchSize, burst.MBPerSecond, burst.P95Ms)
+		result.Bursts = append(result.Bursts, burst)
+		return false, nil
+	}
+
+	for _, writers := range config.Writers {
+		if done, err := run(writers, w.batchSize); done {
+			return w.tuned(result, err)
+		}
+	}
+	bestWriters := bestBurst(result.Bursts).Writers
+	for _, batchSize := range config.BatchSizes {
+		if batchSize == w.batchSize {
+			continue
+		}
+		if done, err := run(bestWriters, batchSize); done {
+			return w.tuned(result, err)
+		}
+	}
+	return w.tuned(result, nil)
+}
+
+// tuned switches the writer to the best burst of result and records it
+func (w *Writer) tuned(result *TuneResult, err error) error {
+	if len(result.Bursts) > 0 {
+		best := bestBurst(result.Bursts)
+		w.writerCount, w.batchSize = best.Writers, best.BatchSize
+		log.Printf("Auto-tune: continuing with %d writers and batches of %d", w.writerCount, w.batchSize)
+	}
+	result.Writers, result.BatchSize = w.writerCount, w.batchSize
+	w.mu.Lock()
+	w.tuneResult = result
+	w.mu.Unlock()
+	return err
+}
+
+// burst writes batches of batchSize documents from docChan with writers
+// writers for config.Burst and measures them. It returns done once docChan is
+// closed or the target is claimed, leaving the burst unmeasured.
+func (w *Writer) burst(ctx context.Context, docChan <-chan model.Document, writers, batchSize int, config *AutoTuneConfig) (TuneBurst, bool, error) {
+	start := time.Now()
+	warm, stop := start.Add(config.Burst/5), start.Add(config.Burst)
+	var (
+		mu          sync.Mutex
+		latencies   []time.Duration
+		warmBytes   = atomic.LoadInt64(&w.bytesWritten)
+		warmedAt    = start
+		done        atomic.Bool
+		warmupTimer = time.AfterFunc(config.Burst/5, func() {
+			mu.Lock()
+			warmBytes, warmedAt = atomic.LoadInt64(&w.bytesWritten), time.Now()
+			mu.Unlock()
+		})
+	)
+	defer warmupTimer.Stop()
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	for i := 0; i < writers; i++ {
+		writerID := i
+		eg.Go(func() (err error) {
+			defer recoverPanic(&err)
+			collection, release, err := w.writerCollection(writerID)
+			if err != nil {
+				return err
+			}
+			defer release()
+			for time.Now().Before(stop) && !done.Load() {
+				batch, open := w.nextBatch(egCtx, docChan, batchSize)
+				if egCtx.Err() != nil {
+					atomic.AddInt64(&w.docsAbandoned, int64(len(batch)))
+					return egCtx.Err()
+				}
+				if !open {
+					done.Store(true)
+				}
+				sent := time.Now()
+				if err := w.flushBatchTo(egCtx, collection, batch); err != nil {
+					return err
+				}
+				if sent.After(warm) && len(batch) == batchSize {
+					mu.Lock()
+					latencies = append(latencies, time.Since(sent))
+					mu.Unlock()
+				}
+				if w.budget.exhausted() {
+					done.Store(true)
+				}
+			}
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil || done.Load() {
+		return TuneBurst{}, true, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	burst := TuneBurst{Writers: writers, BatchSize: batchSize}
+	if elapsed := time.Since(warmedAt).Seconds(); elapsed > 0 {
+		burst.MBPerSecond = float64(atomic.LoadInt64(&w.bytesWritten)-warmBytes) / elapsed / (1024 * 1024)
+	}
+	// A burst without a full batch after its warm-up counts as its length
+	burst.P95Ms = float64(config.Burst) / float64(time.Millisecond)
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		p95 := latencies[min(len(latencies)*95/100, len(latencies)-1)]
+		burst.P95Ms = float64(p95) / float64(time.Millisecond)
+		burst.WithinBound = config.MaxLatency == 0 || p95 <= config.MaxLatency
+	}
+	return burst, false, nil
+}
+
+// nextBatch collects up to batchSize documents from docChan, and reports
+// whether docChan is still open
+func (w *Writer) nextBatch(ctx context.Context, docChan <-chan model.Document, batchSize int) ([]interface{}, bool) {
+	batch := make([]interface{}, 0, batchSize)
+	for len(batch) < batchSize {
+		select {
+		case <-ctx.Done():
+			return batch, true
+		case doc, ok := <-docChan:
+			if !ok {
+				return batch, false
+			}
+			batch = append(batch, doc)
+		}
+	}
+	return batch, true
+}
+
+// bestBurst returns the burst with the highest throughput within the latency
+// bound, or the one with the lowest latency if none is within it
+func bestBurst(bursts []TuneBurst) TuneBurst {
+	best := bursts[0]
+	for _, b := range bursts[1:] {
+		switch {
+		case b.WithinBound != best.WithinBound:
+			if b.WithinBound {
+				best = b
+			}
+		case b.WithinBound && b.MBPerSecond > best.MBPerSecond:
+			best = b
+		case !b.WithinBound && b.P95Ms < best.P95Ms:
+			best = b
+		}
+	}
+	return best
+}
@@ -0,0 +1,44 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBestBurst(t *testing.T) {
+	bursts := []TuneBurst{
+		{Writers: 2, MBPerSecond: 80, P95Ms: 40, WithinBound: true},
+		{Writers: 8, MBPerSecond: 150, P95Ms: 90, WithinBound: true},
+		{Writers: 32, MBPerSecond: 170, P95Ms: 900},
+	}
+	if best := bestBurst(bursts); best.Writers != 8 {
+		t.Errorf("Expected the fastest burst within the bound, got %+v", best)
+	}
+
+	// Without a burst within the bound, the lowest latency wins
+	bursts = []TuneBurst{
+		{Writers: 2, MBPerSecond: 80, P95Ms: 700},
+		{Writers: 8, MBPerSecond: 150, P95Ms: 600},
+		{Writers: 32, MBPerSecond: 170, P95Ms: 900},
+	}
+	if best := bestBurst(bursts); best.Writers != 8 {
+		t.Errorf("Expected the burst with the lowest latency, got %+v", best)
+	}
+}
+
+func TestAutoTuneConfigValidation(t *testing.T) {
+	valid := AutoTuneConfig{Writers: []int{2, 4}, BatchSizes: []int{500, 1000}, Burst: 10 * time.Second}
+	if err := valid.validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	for _, config := range []AutoTuneConfig{
+		{BatchSizes: []int{500}, Burst: time.Second},
+		{Writers: []int{0, 4}, BatchSizes: []int{500}, Burst: time.Second},
+		{Writers: []int{4}, BatchSizes: []int{500}},
+		{Writers: []int{4}, BatchSizes: []int{500}, Burst: time.Second, MaxLatency: -time.Second},
+	} {
+		if config.validate() == nil {
+			t.Errorf("Expected %+v to be invalid", config)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package mongo
+
+// maxMessageBytes is the server's limit on one OP_MSG message; the driver
+// splits insert batches that do not fit into several commands
+const maxMessageBytes = 48000000
+
+// MaxBatchBytes is the largest byte bound of a batch, which leaves room in a
+// message for the insert command around the documents
+const MaxBatchBytes = maxMessageBytes - 1024*1024
+
+// measure returns the BSON size of doc when batches are bounded by bytes,
+// and 0 when its size is not needed
+func (w *Writer) measure(doc interface{}) (int64, error) {
+	if w.batchBytes <= 0 {
+		return 0, nil
+	}
+	return documentSize(doc)
+}
+
+// overflows reports whether a document of size bytes would take a batch of
+// docs documents and batchBytes bytes over the byte bound, so the batch must
+// be sent first. A document always fits an empty batch.
+func (w *Writer) overflows(docs int, batchBytes, size int64) bool {
+	return w.batchBytes > 0 && docs > 0 && batchBytes+size > w.batchBytes
+}
+
+// full reports whether a batch of docs documents and batchBytes bytes has
+// reached the batch size or the byte bound
+func (w *Writer) full(docs int, batchBytes int64) bool {
+	return docs >= w.batchSize || (w.batchBytes > 0 && batchBytes >= w.batchBytes)
+}
@@ -0,0 +1,57 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+)
+
+func TestBatchByteBound(t *testing.T) {
+	w := &Writer{batchSize: 1000, batchBytes: 100}
+	if w.overflows(0, 0, 500) {
+		t.Error("A document must always fit an empty batch")
+	}
+	if !w.overflows(1, 60, 50) || w.overflows(1, 50, 50) {
+		t.Error("Expected batches to end at the byte bound")
+	}
+	if !w.full(2, 100) || w.full(2, 99) {
+		t.Error("Expected a batch at the byte bound to be full")
+	}
+
+	w.batchBytes = 0
+	if w.overflows(999, 1<<30, 1<<30) || !w.full(1000, 0) {
+		t.Error("Expected only the document count to bound batches without a byte bound")
+	}
+}
+
+func TestMarshalWorkerBatchBytes(t *testing.T) {
+	generated := ledgerBatch(t, 7)
+	size, err := documentSize(generated[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Room for two and a half documents of about the same size
+	w := &Writer{batchSize: 1000, batchBytes: size * 5 / 2, budget: newByteBudget(0), pipeline: newMarshalPipeline(1)}
+	docChan := make(chan model.Document, len(generated))
+	for _, doc := range generated {
+		docChan <- doc.(model.Document)
+	}
+	close(docChan)
+
+	batches := make(chan []interface{}, len(generated))
+	if err := w.marshalWorker(context.Background(), docChan, batches); err != nil {
+		t.Fatal(err)
+	}
+	close(batches)
+	for batch := range batches {
+		var bytes int64
+		for _, doc := range batch {
+			size, _ := documentSize(doc)
+			bytes += size
+		}
+		if len(batch) > 2 || (len(batch) > 1 && bytes > w.batchBytes) {
+			t.Errorf("Batch of %d documents and %d bytes crosses the bound of %d", len(batch), bytes, w.batchBytes)
+		}
+	}
+}
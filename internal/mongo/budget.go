@@ -0,0 +1,56 @@
+package mongo
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// byteBudget shares the target bytes between concurrent writers. Each batch
+// claims its marshaled size before it is inserted, so writers stop together
+// and the load lands within one batch of the target instead of overshooting
+// by a batch per writer.
+type byteBudget struct {
+	limit   int64
+	claimed int64
+}
+
+// newByteBudget creates a budget of limit bytes (<= 0 = unlimited)
+func newByteBudget(limit int64) *byteBudget {
+	if limit <= 0 {
+		limit = 1<<63 - 1
+	}
+	return &byteBudget{limit: limit}
+}
+
+// claim reserves n bytes and reports whether the batch may be written.
+// Claims are granted while any budget remains, so only the last batch
+// crosses the limit, by less than its own size.
+func (b *byteBudget) claim(n int64) bool {
+	claimed := atomic.AddInt64(&b.claimed, n)
+	if claimed-n >= atomic.LoadInt64(&b.limit) {
+		atomic.AddInt64(&b.claimed, -n)
+		return false
+	}
+	return true
+}
+
+// release returns n claimed bytes that were not written
+func (b *byteBudget) release(n int64) {
+	atomic.AddInt64(&b.claimed, -n)
+}
+
+// exhausted reports whether the whole budget has been claimed
+func (b *byteBudget) exhausted() bool {
+	return atomic.LoadInt64(&b.claimed) >= atomic.LoadInt64(&b.limit)
+}
+
+// extend raises the limit by n bytes, e.g. to write more after the target
+// was reached. An unlimited budget stays unlimited.
+func (b *byteBudget) extend(n int64) {
+	for {
+		limit := atomic.LoadInt64(&b.limit)
+		if limit > math.MaxInt64-n || atomic.CompareAndSwapInt64(&b.limit, limit, limit+n) {
+			return
+		}
+	}
+}
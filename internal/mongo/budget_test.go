@@ -0,0 +1,80 @@
+package mongo
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestByteBudgetStopsWithinOneBatch(t *testing.T) {
+	const (
+		limit     = 1000000
+		batchSize = 4096
+	)
+	budget := newByteBudget(limit)
+
+	var granted int64
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for budget.claim(batchSize) {
+				atomic.AddInt64(&granted, batchSize)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if granted < limit || granted >= limit+batchSize {
+		t.Errorf("granted %d bytes, want within one batch of %d", granted, limit)
+	}
+	if !budget.exhausted() {
+		t.Error("expected the budget to be exhausted")
+	}
+}
+
+func TestByteBudgetRelease(t *testing.T) {
+	budget := newByteBudget(100)
+	if !budget.claim(100) {
+		t.Fatal("expected the first claim to be granted")
+	}
+	if budget.claim(1) {
+		t.Fatal("expected a claim past the limit to be refused")
+	}
+
+	// Bytes that were not written go back to the budget
+	budget.release(40)
+	if budget.exhausted() {
+		t.Error("expected released bytes to be available")
+	}
+	if !budget.claim(40) {
+		t.Error("expected released bytes to be claimable")
+	}
+}
+
+func TestByteBudgetUnlimited(t *testing.T) {
+	budget := newByteBudget(0)
+	for i := 0; i < 1000; i++ {
+		if !budget.claim(1 << 40) {
+			t.Fatal("expected an unlimited budget to grant every claim")
+		}
+	}
+}
+
+func TestByteBudgetExtend(t *testing.T) {
+	budget := newByteBudget(100)
+	if !budget.claim(150) || budget.claim(1) {
+		t.Fatal("expected only the first claim to be granted")
+	}
+	budget.extend(100)
+	if !budget.claim(10) {
+		t.Error("expected a claim to be granted after extending the budget")
+	}
+
+	unlimited := newByteBudget(0)
+	unlimited.extend(100)
+	if !unlimited.claim(1 << 40) {
+		t.Error("expected an unlimited budget to stay unlimited")
+	}
+}
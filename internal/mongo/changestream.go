@@ -0,0 +1,67 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// trackPendingInserts remembers when each document of a batch was sent, so
+// the change stream tailer can compute insert-to-notification latency
+func (w *Writer) trackPendingInserts(batch []interface{}, sentAt time.Time) {
+	for _, doc := range batch {
+		if id, ok := documentID(doc); ok {
+			w.pendingInserts.Store(id, sentAt)
+		}
+	}
+}
+
+// StartChangeStream opens a change stream on the target collection and
+// records the latency between sending each insert and receiving its change
+// notification as CHANGESTREAM operations in the YCSB log. The stream is
+// opened before returning so no inserts are missed; it is tailed until ctx ends.
+func (w *Writer) StartChangeStream(ctx context.Context) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "operationType", Value: "insert"}}}},
+		// Only the _id is needed; don't ship full documents back to the client
+		{{Key: "$project", Value: bson.D{{Key: "documentKey", Value: 1}}}},
+	}
+
+	stream, err := w.collection.Watch(ctx, pipeline)
+	if err != nil {
+		return fmt.Errorf("failed to open change stream: %w", err)
+	}
+	w.tailChangeStream = true
+
+	go func() {
+		defer stream.Close(context.Background())
+
+		var event struct {
+			DocumentKey struct {
+				ID interface{} `bson:"_id"`
+			} `bson:"documentKey"`
+		}
+		for stream.Next(ctx) {
+			receivedAt := time.Now()
+			if err := stream.Decode(&event); err != nil {
+				continue
+			}
+
+			sentAt, ok := w.pendingInserts.LoadAndDelete(event.DocumentKey.ID)
+			if !ok || w.ycsbLogger == nil {
+				continue
+			}
+			w.ycsbLogger.RecordOperation("CHANGESTREAM", receivedAt.Sub(sentAt.(time.Time)), true)
+		}
+
+		if err := stream.Err(); err != nil && ctx.Err() == nil {
+			log.Printf("Change stream stopped: %v", err)
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package mongo
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ChaosConfig configures client-side fault injection on insert batches. Each
+// ratio is the fraction (0-1) of batch attempts the fault is applied to.
+type ChaosConfig struct {
+	DelayRatio     float64       // Delay the batch before it reaches the driver
+	MaxDelay       time.Duration // Upper bound of an injected delay (default 1s)
+	DuplicateRatio float64       // Send a successfully inserted batch a second time
+	FailRatio      float64       // Fail the batch with a retryable error without sending it
+	ResetRatio     float64       // Fail the batch with a network reset after sending it
+	DropRatio      float64       // Drop the batch before it reaches the driver (per batch, not per attempt)
+}
+
+// Enabled reports whether any fault is configured
+func (c ChaosConfig) Enabled() bool {
+	return c.DelayRatio > 0 || c.DuplicateRatio > 0 || c.FailRatio > 0 || c.ResetRatio > 0 || c.DropRatio > 0
+}
+
+// errInjectedFault is returned for batches failed by fault injection. It
+// carries the RetryableWriteError label so it exercises the retry path.
+var errInjectedFault = mongo.CommandError{
+	Name:    "InjectedFault",
+	Message: "batch failed by client-side fault injection",
+	Labels:  []string{"RetryableWriteError"},
+}
+
+// errInjectedReset is returned for batches whose connection is reset by fault
+// injection after they were sent. Like a real reset, it is a network error,
+// and the server may have applied the batch although its reply was lost.
+var errInjectedReset = mongo.CommandError{
+	Name:    "InjectedNetworkReset",
+	Message: "connection reset by client-side fault injection",
+	Labels:  []string{"NetworkError", "RetryableWriteError"},
+}
+
+// chaos injects faults into insert attempts; a nil *chaos injects nothing
+type chaos struct {
+	config ChaosConfig
+
+	mu  sync.Mutex
+	rng *rand.Rand
+
+	delays     int64
+	duplicates int64
+	failures   int64
+	resets     int64
+	drops      int64
+	dropped    int64 // Documents of dropped batches
+}
+
+// newChaos returns a fault injector, or nil if no fault is configured
+func newChaos(config ChaosConfig) *chaos {
+	if !config.Enabled() {
+		return nil
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = time.Second
+	}
+	return &chaos{
+		config: config,
+		rng:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// roll returns true with probability ratio
+func (c *chaos) roll(ratio float64) bool {
+	if ratio <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rng.Float64() < ratio
+}
+
+// randomDelay returns a delay in (0, MaxDelay]
+func (c *chaos) randomDelay() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Duration(c.rng.Int63n(int64(c.config.MaxDelay))) + 1
+}
+
+// insert runs an insert attempt with faults injected around it
+func (c *chaos) insert(ctx context.Context, insert func(ctx context.Context) error) error {
+	if c == nil {
+		return insert(ctx)
+	}
+
+	if c.roll(c.config.DelayRatio) {
+		atomic.AddInt64(&c.delays, 1)
+		if !sleepContext(ctx, c.randomDelay()) {
+			return ctx.Err()
+		}
+	}
+	if c.roll(c.config.FailRatio) {
+		atomic.AddInt64(&c.failures, 1)
+		return errInjectedFault
+	}
+
+	if err := insert(ctx); err != nil {
+		return err
+	}
+	if c.roll(c.config.ResetRatio) {
+		atomic.AddInt64(&c.resets, 1)
+		return errInjectedReset
+	}
+
+	if c.roll(c.config.DuplicateRatio) {
+		atomic.AddInt64(&c.duplicates, 1)
+		// The resend must fail with duplicate key errors only
+		if err := insert(ctx); err != nil && !isDuplicateKeyOnly(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// drop reports whether to drop a batch of docs documents, counting it if so
+func (c *chaos) drop(docs int) bool {
+	if c == nil || !c.roll(c.config.DropRatio) {
+		return false
+	}
+	atomic.AddInt64(&c.drops, 1)
+	atomic.AddInt64(&c.dropped, int64(docs))
+	return true
+}
+
+// ChaosStats counts the faults injected
+type ChaosStats struct {
+	Delays     int64
+	Duplicates int64
+	Failures   int64
+	Resets     int64
+	Drops      int64
+	Dropped    int64 // Documents of dropped batches
+}
+
+// stats returns the faults injected so far
+func (c *chaos) stats() ChaosStats {
+	if c == nil {
+		return ChaosStats{}
+	}
+	return ChaosStats{
+		Delays:     atomic.LoadInt64(&c.delays),
+		Duplicates: atomic.LoadInt64(&c.duplicates),
+		Failures:   atomic.LoadInt64(&c.failures),
+		Resets:     atomic.LoadInt64(&c.resets),
+		Drops:      atomic.LoadInt64(&c.drops),
+		Dropped:    atomic.LoadInt64(&c.dropped),
+	}
+}
@@ -0,0 +1,97 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestChaosFailIsRetryable(t *testing.T) {
+	c := newChaos(ChaosConfig{FailRatio: 1})
+
+	calls := 0
+	err := c.insert(context.Background(), func(ctx context.Context) error {
+		calls++
+		return nil
+	})
+	if calls != 0 {
+		t.Errorf("Failed batch reached the driver %d times", calls)
+	}
+	if !isRetryable(err) {
+		t.Errorf("Injected failure should be retryable, got %v", err)
+	}
+	if got := c.stats().Failures; got != 1 {
+		t.Errorf("Expected 1 injected failure, got %d", got)
+	}
+}
+
+func TestChaosDuplicateResendsBatch(t *testing.T) {
+	c := newChaos(ChaosConfig{DuplicateRatio: 1, DelayRatio: 1, MaxDelay: time.Millisecond})
+
+	calls := 0
+	err := c.insert(context.Background(), func(ctx context.Context) error {
+		calls++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected the batch to be sent twice, got %d", calls)
+	}
+	if stats := c.stats(); stats.Duplicates != 1 || stats.Delays != 1 {
+		t.Errorf("Expected 1 duplicate and 1 delay, got %+v", stats)
+	}
+}
+
+func TestChaosDisabled(t *testing.T) {
+	if c := newChaos(ChaosConfig{MaxDelay: time.Second}); c != nil {
+		t.Fatal("Expected no fault injector without fault ratios")
+	}
+
+	var c *chaos
+	calls := 0
+	c.insert(context.Background(), func(ctx context.Context) error {
+		calls++
+		return nil
+	})
+	if calls != 1 {
+		t.Errorf("Expected a single send, got %d", calls)
+	}
+}
+
+func TestChaosResetAfterSend(t *testing.T) {
+	c := newChaos(ChaosConfig{ResetRatio: 1})
+
+	calls := 0
+	err := c.insert(context.Background(), func(ctx context.Context) error {
+		calls++
+		return nil
+	})
+	if calls != 1 {
+		t.Errorf("Expected the batch to be sent once before the reset, got %d", calls)
+	}
+	if !mongo.IsNetworkError(err) || !isRetryable(err) {
+		t.Errorf("Injected reset should be a retryable network error, got %v", err)
+	}
+	if got := c.stats().Resets; got != 1 {
+		t.Errorf("Expected 1 injected reset, got %d", got)
+	}
+}
+
+func TestChaosDrop(t *testing.T) {
+	c := newChaos(ChaosConfig{DropRatio: 1})
+	if !c.drop(10) || !c.drop(5) {
+		t.Fatal("Ratio 1 should drop every batch")
+	}
+	if stats := c.stats(); stats.Drops != 2 || stats.Dropped != 15 {
+		t.Errorf("Expected 2 dropped batches of 15 documents, got %+v", stats)
+	}
+
+	var disabled *chaos
+	if disabled.drop(10) {
+		t.Error("A disabled fault injector dropped a batch")
+	}
+}
@@ -0,0 +1,120 @@
+package mongo
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// churnTicksPerSecond is how often the deleter issues a delete batch
+const churnTicksPerSecond = 10
+
+// StartChurn starts a background deleter that removes the oldest documents
+// (lowest _id) at up to rate documents per second while inserts continue.
+// Deletion only happens while the live data (bytes written minus bytes
+// deleted) exceeds keepBytes, so the collection settles at a steady size.
+// Deletes are recorded as DELETE operations in the YCSB log.
+func (w *Writer) StartChurn(ctx context.Context, rate int, keepBytes int64) {
+	perTick := rate / churnTicksPerSecond
+	if perTick < 1 {
+		perTick = 1
+	}
+	interval := time.Second / churnTicksPerSecond
+	if rate < churnTicksPerSecond {
+		interval = time.Second / time.Duration(rate)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			excess := w.liveBytes() - keepBytes
+			if excess <= 0 {
+				continue
+			}
+			n := perTick
+			if avg := w.averageDocumentSize(); avg > 0 && excess/avg < int64(n) {
+				n = int(excess/avg) + 1
+			}
+
+			if err := w.deleteOldest(ctx, n); err != nil && ctx.Err() == nil {
+				log.Printf("Churn delete failed: %v", err)
+			}
+		}
+	}()
+}
+
+// liveBytes returns the bytes written that have not been deleted by churn
+func (w *Writer) liveBytes() int64 {
+	return atomic.LoadInt64(&w.bytesWritten) - atomic.LoadInt64(&w.bytesDeleted)
+}
+
+// averageDocumentSize returns the average size of written documents
+func (w *Writer) averageDocumentSize() int64 {
+	docs := atomic.LoadInt64(&w.docsWritten)
+	if docs == 0 {
+		return 0
+	}
+	return atomic.LoadInt64(&w.bytesWritten) / docs
+}
+
+// deleteOldest removes up to n documents with the lowest _ids
+func (w *Writer) deleteOldest(ctx context.Context, n int) error {
+	cursor, err := w.collection.Find(ctx, bson.D{},
+		options.Find().
+			SetSort(bson.D{{Key: "_id", Value: 1}}).
+			SetLimit(int64(n)).
+			SetProjection(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return err
+	}
+
+	var ids []interface{}
+	for cursor.Next(ctx) {
+		ids = append(ids, cursor.Current.Lookup("_id"))
+	}
+	cursor.Close(ctx)
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	startTime := time.Now()
+	var result *mongo.DeleteResult
+	deleteCtx, cancel := withTimeout(ctx, w.insertTimeout)
+	result, err = w.collection.DeleteMany(deleteCtx, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}})
+	cancel()
+	latency := time.Since(startTime)
+
+	if w.ycsbLogger != nil {
+		avgLatencyPerDoc := latency / time.Duration(len(ids))
+		for i := 0; i < len(ids); i++ {
+			if isTimeout(err) {
+				w.ycsbLogger.RecordTimeout("DELETE", avgLatencyPerDoc)
+			} else {
+				w.ycsbLogger.RecordOperation("DELETE", avgLatencyPerDoc, err == nil)
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	atomic.AddInt64(&w.docsDeleted, result.DeletedCount)
+	atomic.AddInt64(&w.bytesDeleted, result.DeletedCount*w.averageDocumentSize())
+	return nil
+}
@@ -0,0 +1,145 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CleanResult records what a cleanup removed
+type CleanResult struct {
+	DroppedCollections []string
+	DocumentsDeleted   int64
+	OrdersDeleted      int64
+	RunsRemoved        int64
+}
+
+// ListRuns returns the metadata of every run into collection, oldest first
+func ListRuns(ctx context.Context, db *mongo.Database, collection string) ([]RunMetadata, error) {
+	cursor, err := db.Collection(RunMetadataCollection).Find(ctx, bson.D{{Key: "collection", Value: collection}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+	var runs []RunMetadata
+	if err := cursor.All(ctx, &runs); err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+	return runs, nil
+}
+
+// OrdersCollections returns the distinct orders collections written by runs
+func OrdersCollections(runs []RunMetadata) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, run := range runs {
+		if run.OrdersCollection != "" && !seen[run.OrdersCollection] {
+			seen[run.OrdersCollection] = true
+			names = append(names, run.OrdersCollection)
+		}
+	}
+	return names
+}
+
+// SpreadCollections returns the distinct collections runs spread their
+// documents over (NamespaceConfig), in the order the runs created them
+func SpreadCollections(runs []RunMetadata) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, run := range runs {
+		if run.Namespaces == nil {
+			continue
+		}
+		for _, name := range run.Namespaces.Names() {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// DropCollection drops a generated collection together with the orders
+// collections and spread collections its runs wrote, and removes the runs'
+// metadata
+func DropCollection(ctx context.Context, db *mongo.Database, collection string) (*CleanResult, error) {
+	runs, err := ListRuns(ctx, db, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CleanResult{}
+	names := append([]string{collection}, OrdersCollections(runs)...)
+	for _, name := range append(names, SpreadCollections(runs)...) {
+		if err := db.Collection(name).Drop(ctx); err != nil {
+			return result, fmt.Errorf("failed to drop %s: %w", name, err)
+		}
+		result.DroppedCollections = append(result.DroppedCollections, name)
+	}
+
+	deleted, err := db.Collection(RunMetadataCollection).DeleteMany(ctx, bson.D{{Key: "collection", Value: collection}})
+	if err != nil {
+		return result, fmt.Errorf("failed to remove run metadata: %w", err)
+	}
+	result.RunsRemoved = deleted.DeletedCount
+	return result, nil
+}
+
+// DeleteRun deletes the documents of a single run, identified by the run tag
+// they carry, and removes the run's metadata. The run must have been loaded
+// with tagging enabled.
+func DeleteRun(ctx context.Context, db *mongo.Database, meta *RunMetadata) (*CleanResult, error) {
+	if !meta.Tagged {
+		return nil, fmt.Errorf("run %s was loaded without run tags; drop the whole collection instead", meta.RunID)
+	}
+
+	result := &CleanResult{}
+	deleted, err := db.Collection(meta.Collection).DeleteMany(ctx, bson.D{{Key: "metadata.run_id", Value: meta.RunID}})
+	if err != nil {
+		return result, fmt.Errorf("failed to delete documents of run %s: %w", meta.RunID, err)
+	}
+	result.DocumentsDeleted = deleted.DeletedCount
+
+	if meta.OrdersCollection != "" {
+		deleted, err := db.Collection(meta.OrdersCollection).DeleteMany(ctx, bson.D{{Key: "run_id", Value: meta.RunID}})
+		if err != nil {
+			return result, fmt.Errorf("failed to delete orders of run %s: %w", meta.RunID, err)
+		}
+		result.OrdersDeleted = deleted.DeletedCount
+	}
+
+	// The run created its spread collections, so those it leaves empty go
+	// too; collections still holding other runs' documents are kept
+	if meta.Namespaces != nil {
+		for _, name := range meta.Namespaces.Names() {
+			collection := db.Collection(name)
+			deleted, err := collection.DeleteMany(ctx, bson.D{{Key: "metadata.run_id", Value: meta.RunID}})
+			if err != nil {
+				return result, fmt.Errorf("failed to delete documents of run %s from %s: %w", meta.RunID, name, err)
+			}
+			result.DocumentsDeleted += deleted.DeletedCount
+
+			left, err := collection.CountDocuments(ctx, bson.D{}, options.Count().SetLimit(1))
+			if err != nil {
+				return result, fmt.Errorf("failed to count documents of %s: %w", name, err)
+			}
+			if left > 0 {
+				continue
+			}
+			if err := collection.Drop(ctx); err != nil {
+				return result, fmt.Errorf("failed to drop %s: %w", name, err)
+			}
+			result.DroppedCollections = append(result.DroppedCollections, name)
+		}
+	}
+
+	removed, err := db.Collection(RunMetadataCollection).DeleteOne(ctx, bson.D{{Key: "_id", Value: meta.RunID}})
+	if err != nil {
+		return result, fmt.Errorf("failed to remove run metadata: %w", err)
+	}
+	result.RunsRemoved = removed.DeletedCount
+	return result, nil
+}
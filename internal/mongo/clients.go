@@ -0,0 +1,110 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/sync/errgroup"
+)
+
+// runClients models N independent logical clients, each with its own
+// connection and session, issuing small inserts separated by think time.
+// This reproduces how many application instances load a cluster, as opposed
+// to a few firehose writers sharing one large pool.
+func (w *Writer) runClients(ctx context.Context, docChan <-chan model.Document) error {
+	eg, ctx := errgroup.WithContext(ctx)
+
+	for i := 0; i < w.clients; i++ {
+		clientID := i
+		eg.Go(func() (err error) {
+			defer recoverPanic(&err)
+			return w.clientWorker(ctx, clientID, docChan)
+		})
+	}
+
+	return eg.Wait()
+}
+
+// clientWorker is a single logical client with a dedicated connection and session
+func (w *Writer) clientWorker(ctx context.Context, clientID int, docChan <-chan model.Document) error {
+	// One connection per logical client, like a small application instance
+	client, err := connectMonitored(w.connectionString, 1, 0, nil, w.autoEncryption)
+	if err != nil {
+		return fmt.Errorf("client %d: %w", clientID, err)
+	}
+	defer func() {
+		disconnectCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		client.Disconnect(disconnectCtx)
+	}()
+
+	session, err := client.StartSession()
+	if err != nil {
+		return fmt.Errorf("client %d: failed to start session: %w", clientID, err)
+	}
+	defer session.EndSession(context.Background())
+
+	sessCtx := mongo.NewSessionContext(ctx, session)
+	collection := client.Database(w.databaseName).Collection(w.collectionName)
+
+	// Stagger client start so thousands of clients don't fire in lockstep
+	rng := rand.New(rand.NewSource(time.Now().UnixNano() + int64(clientID)))
+	if w.thinkTime.Mean > 0 {
+		if !sleepContext(ctx, time.Duration(rng.Int63n(int64(w.thinkTime.Mean)))) {
+			return ctx.Err()
+		}
+	}
+
+	batch := make([]interface{}, 0, w.clientBatchSize)
+	for {
+		batch = batch[:0]
+
+		// Block for the first document, then take whatever else is ready
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case doc, ok := <-docChan:
+			if !ok {
+				return nil
+			}
+			batch = append(batch, doc)
+		}
+	fill:
+		for len(batch) < w.clientBatchSize {
+			select {
+			case doc, ok := <-docChan:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, doc)
+			default:
+				break fill
+			}
+		}
+
+		if err := w.flushBatchTo(sessCtx, collection, batch); err != nil {
+			return err
+		}
+
+		if pause := w.thinkTime.Next(rng); pause > 0 && !sleepContext(ctx, pause) {
+			return ctx.Err()
+		}
+	}
+}
+
+// sleepContext sleeps for d and reports false if the context ended first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,69 @@
+package mongo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ParseCollation parses "locale[,option=value...]" into the collation of a
+// new collection ("" = none). Options are the collation document's fields:
+// strength (1-5), caseLevel, caseFirst (upper, lower, off), numericOrdering,
+// alternate (non-ignorable, shifted), maxVariable (punct, space), and
+// backwards.
+func ParseCollation(s string) (*options.Collation, error) {
+	parts := strings.Split(s, ",")
+	locale := strings.TrimSpace(parts[0])
+	if locale == "" {
+		if strings.TrimSpace(s) != "" {
+			return nil, fmt.Errorf("invalid collation %q: want locale[,option=value...]", s)
+		}
+		return nil, nil
+	}
+
+	collation := &options.Collation{Locale: locale}
+	for _, part := range parts[1:] {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid collation option %q: want option=value", part)
+		}
+		var err error
+		switch name {
+		case "strength":
+			collation.Strength, err = strconv.Atoi(value)
+			if err == nil && (collation.Strength < 1 || collation.Strength > 5) {
+				err = fmt.Errorf("must be between 1 and 5")
+			}
+		case "caseLevel":
+			collation.CaseLevel, err = strconv.ParseBool(value)
+		case "caseFirst":
+			collation.CaseFirst, err = oneOf(value, "upper", "lower", "off")
+		case "numericOrdering":
+			collation.NumericOrdering, err = strconv.ParseBool(value)
+		case "alternate":
+			collation.Alternate, err = oneOf(value, "non-ignorable", "shifted")
+		case "maxVariable":
+			collation.MaxVariable, err = oneOf(value, "punct", "space")
+		case "backwards":
+			collation.Backwards, err = strconv.ParseBool(value)
+		default:
+			return nil, fmt.Errorf("unknown collation option %q", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid collation option %q: %w", part, err)
+		}
+	}
+	return collation, nil
+}
+
+// oneOf returns value if it is one of allowed
+func oneOf(value string, allowed ...string) (string, error) {
+	for _, a := range allowed {
+		if value == a {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("want one of %s", strings.Join(allowed, ", "))
+}
@@ -0,0 +1,23 @@
+package mongo
+
+import "testing"
+
+func TestParseCollation(t *testing.T) {
+	if c, err := ParseCollation(""); c != nil || err != nil {
+		t.Errorf("ParseCollation(\"\") = %v, %v, want none", c, err)
+	}
+
+	c, err := ParseCollation("de@collation=phonebook, strength=2,numericOrdering=true,caseFirst=upper")
+	if err != nil {
+		t.Fatalf("Failed to parse collation: %v", err)
+	}
+	if c.Locale != "de@collation=phonebook" || c.Strength != 2 || !c.NumericOrdering || c.CaseFirst != "upper" {
+		t.Errorf("Unexpected collation: %+v", c)
+	}
+
+	for _, invalid := range []string{",strength=2", "de,strength=6", "de,strength", "de,caseFirst=title", "de,color=blue"} {
+		if _, err := ParseCollation(invalid); err == nil {
+			t.Errorf("Expected error for collation %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+// prepareConnectionString appends compressors=disabled to the connection string
+// to disable network compression
+func prepareConnectionString(connectionString string) string {
+	if strings.Contains(connectionString, "compressors=") {
+		return connectionString
+	}
+
+	// Ensure connection string has / before adding query parameters
+	// MongoDB connection strings require / before ? (e.g., mongodb+srv://host/?options)
+	hasQueryParams := strings.Contains(connectionString, "?")
+
+	if !hasQueryParams {
+		// If no query params exist, ensure connection string ends with /
+		if !strings.HasSuffix(connectionString, "/") {
+			connectionString = connectionString + "/"
+		}
+		// Add ?compressors=disabled
+		return connectionString + "?compressors=disabled"
+	}
+
+	// Query params already exist, just append &compressors=disabled
+	return connectionString + "&compressors=disabled"
+}
+
+// clientOptions returns the client options shared by every connection the tool opens
+func clientOptions(connectionString string, maxPoolSize, minPoolSize uint64) *options.ClientOptions {
+	// Use W:1, J:false for maximum throughput
+	wc := writeconcern.New(writeconcern.W(1), writeconcern.J(false))
+
+	return options.Client().
+		ApplyURI(prepareConnectionString(connectionString)).
+		SetMaxPoolSize(maxPoolSize).
+		SetMinPoolSize(minPoolSize).
+		SetWriteConcern(wc).
+		SetRetryWrites(false).
+		SetServerSelectionTimeout(30 * time.Second).
+		SetSocketTimeout(60 * time.Second)
+}
+
+// connect creates a MongoDB client with optimized settings
+func connect(connectionString string, maxPoolSize, minPoolSize uint64) (*mongo.Client, error) {
+	return connectMonitored(connectionString, maxPoolSize, minPoolSize, nil, nil)
+}
+
+// connectMonitored creates a MongoDB client that reports connection pool
+// events to monitor (nil = none) and encrypts fields with autoEncryption
+// (nil = none)
+func connectMonitored(connectionString string, maxPoolSize, minPoolSize uint64, monitor *event.PoolMonitor, autoEncryption *options.AutoEncryptionOptions) (*mongo.Client, error) {
+	opts := clientOptions(connectionString, maxPoolSize, minPoolSize)
+	if monitor != nil {
+		opts.SetPoolMonitor(monitor)
+	}
+	if autoEncryption != nil {
+		opts.SetAutoEncryptionOptions(autoEncryption)
+	}
+	client, err := mongo.Connect(context.Background(), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+	return client, nil
+}
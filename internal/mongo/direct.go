@@ -0,0 +1,310 @@
+package mongo
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/sync/errgroup"
+)
+
+// ParseShardMap parses "shard=URI;shard=URI;..." into connection strings of
+// the shards' replica sets by shard ID ("" = none). Entries are separated
+// by semicolons because connection strings contain commas.
+func ParseShardMap(s string) (map[string]string, error) {
+	shards := make(map[string]string)
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		shard, uri, ok := strings.Cut(part, "=")
+		shard, uri = strings.TrimSpace(shard), strings.TrimSpace(uri)
+		if !ok || shard == "" || uri == "" {
+			return nil, fmt.Errorf("invalid shard mapping %q: want shard=URI", part)
+		}
+		if _, dup := shards[shard]; dup {
+			return nil, fmt.Errorf("shard %s is mapped twice", shard)
+		}
+		shards[shard] = uri
+	}
+	return shards, nil
+}
+
+// chunkRouter maps shard key values to the shard owning their chunk
+type chunkRouter struct {
+	key    ShardKey
+	mins   []interface{} // Lower bound of every chunk, ascending: string or int64; MinKey first
+	shards []string      // Owner of every chunk
+}
+
+// loadChunkRouter reads the collection's shard key and chunks from the
+// config database through mongos
+func loadChunkRouter(ctx context.Context, collection *mongo.Collection) (*chunkRouter, error) {
+	ns := namespace(collection)
+	config := collection.Database().Client().Database("config")
+
+	var meta struct {
+		UUID primitive.Binary `bson:"uuid"`
+		Key  bson.D           `bson:"key"`
+	}
+	if err := config.Collection("collections").FindOne(ctx, bson.D{{Key: "_id", Value: ns}}).Decode(&meta); err != nil {
+		return nil, fmt.Errorf("failed to read shard key of %s (is it sharded?): %w", ns, err)
+	}
+	if len(meta.Key) != 1 {
+		return nil, fmt.Errorf("direct shard writes need a single-field shard key, %s has %d fields", ns, len(meta.Key))
+	}
+	router := &chunkRouter{key: ShardKey{Field: meta.Key[0].Key, Hashed: meta.Key[0].Value == "hashed"}}
+
+	// Chunks refer to their collection by UUID since MongoDB 5.0, by namespace before
+	filter := bson.D{{Key: "ns", Value: ns}}
+	if len(meta.UUID.Data) > 0 {
+		filter = bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: "uuid", Value: meta.UUID}},
+			bson.D{{Key: "ns", Value: ns}},
+		}}}
+	}
+	cursor, err := config.Collection("chunks").Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chunks of %s: %w", ns, err)
+	}
+	defer cursor.Close(ctx)
+
+	type chunk struct {
+		min   interface{}
+		shard string
+	}
+	var chunks []chunk
+	for cursor.Next(ctx) {
+		var doc struct {
+			Min   bson.Raw `bson:"min"`
+			Shard string   `bson:"shard"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to decode chunk of %s: %w", ns, err)
+		}
+		min, err := router.bound(doc.Min.Lookup(router.key.Field))
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk{min: min, shard: doc.Shard})
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read chunks of %s: %w", ns, err)
+	}
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("no chunks found for %s", ns)
+	}
+
+	sort.Slice(chunks, func(i, j int) bool { return lessBound(chunks[i].min, chunks[j].min) })
+	for _, c := range chunks {
+		router.mins = append(router.mins, c.min)
+		router.shards = append(router.shards, c.shard)
+	}
+	return router, nil
+}
+
+// bound converts a chunk bound to MinKey, a string, or a hash value
+func (r *chunkRouter) bound(v bson.RawValue) (interface{}, error) {
+	switch v.Type {
+	case bsontype.MinKey:
+		return primitive.MinKey{}, nil
+	case bsontype.MaxKey:
+		return primitive.MaxKey{}, nil
+	case bsontype.String:
+		if !r.key.Hashed {
+			return v.StringValue(), nil
+		}
+	case bsontype.Int64:
+		if r.key.Hashed {
+			return v.Int64(), nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported chunk bound %v for shard key %s (ranged keys must be strings)", v, r.key)
+}
+
+// lessBound orders chunk bounds: MinKey, then strings or hash values, then MaxKey
+func lessBound(a, b interface{}) bool {
+	rank := func(v interface{}) int {
+		switch v.(type) {
+		case primitive.MinKey:
+			return 0
+		case primitive.MaxKey:
+			return 2
+		}
+		return 1
+	}
+	if rank(a) != rank(b) {
+		return rank(a) < rank(b)
+	}
+	switch a := a.(type) {
+	case string:
+		return a < b.(string)
+	case int64:
+		return a < b.(int64)
+	}
+	return false
+}
+
+// route returns the shard owning the chunk of an encoded document
+func (r *chunkRouter) route(doc bson.Raw) (string, error) {
+	v, err := doc.LookupErr(strings.Split(r.key.Field, ".")...)
+	if err != nil {
+		return "", fmt.Errorf("document has no shard key %s", r.key.Field)
+	}
+	var key interface{}
+	switch {
+	case r.key.Hashed:
+		h, err := hashedValue(v)
+		if err != nil {
+			return "", err
+		}
+		key = h
+	case v.Type == bsontype.String:
+		key = v.StringValue()
+	default:
+		return "", fmt.Errorf("unsupported value of ranged shard key %s: %s", r.key.Field, v.Type)
+	}
+
+	// The last chunk whose lower bound is at most key
+	i := sort.Search(len(r.mins), func(i int) bool { return lessBound(key, r.mins[i]) })
+	if i == 0 {
+		return "", fmt.Errorf("shard key %v is below the first chunk", key)
+	}
+	return r.shards[i-1], nil
+}
+
+// hashedValue computes MongoDB's hashed index value of a string or
+// ObjectId: the first 8 bytes, little-endian, of the MD5 of the hash seed
+// (0), the canonical BSON type, and the encoded value
+func hashedValue(v bson.RawValue) (int64, error) {
+	var canonicalType int32
+	switch v.Type {
+	case bsontype.String:
+		canonicalType = 15
+	case bsontype.ObjectID:
+		canonicalType = 35
+	default:
+		return 0, fmt.Errorf("unsupported value of hashed shard key: %s", v.Type)
+	}
+
+	var buf []byte
+	buf = binary.LittleEndian.AppendUint32(buf, 0)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(canonicalType))
+	buf = append(buf, v.Value...)
+	sum := md5.Sum(buf)
+	return int64(binary.LittleEndian.Uint64(sum[:8])), nil
+}
+
+// directShard is a shard written to directly and the documents routed to it
+type directShard struct {
+	collection *mongo.Collection
+	docs       chan model.Document
+	done       chan struct{} // Closed once the shard's writers stopped
+}
+
+// writeDirect writes every document straight to the replica set of the
+// shard owning its chunk, bypassing mongos. Each shard gets its own client
+// and writerCount writers; routers partition the document channel.
+func (w *Writer) writeDirect(ctx context.Context, docChan <-chan model.Document) error {
+	setupCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	router, err := loadChunkRouter(setupCtx, w.collection)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	shards := make(map[string]*directShard)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		for _, shard := range shards {
+			shard.collection.Database().Client().Disconnect(ctx)
+		}
+	}()
+	for _, id := range router.shards {
+		if _, ok := shards[id]; ok {
+			continue
+		}
+		uri, ok := w.directShards[id]
+		if !ok {
+			return fmt.Errorf("no connection string for shard %s", id)
+		}
+		client, err := connectMonitored(uri, uint64(w.writerCount*10), uint64(w.writerCount), w.pool.monitor(), nil)
+		if err != nil {
+			return fmt.Errorf("shard %s: %w", id, err)
+		}
+		shards[id] = &directShard{
+			collection: client.Database(w.databaseName).Collection(w.collectionName),
+			docs:       make(chan model.Document, w.batchSize),
+			done:       make(chan struct{}),
+		}
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+	for _, shard := range shards {
+		var writers sync.WaitGroup
+		for i := 0; i < w.writerCount; i++ {
+			writers.Add(1)
+			eg.Go(func() (err error) {
+				defer writers.Done()
+				defer recoverPanic(&err)
+				return w.writeWorker(ctx, shard.collection, shard.docs)
+			})
+		}
+		go func() {
+			writers.Wait()
+			close(shard.done)
+		}()
+	}
+
+	// Routers stop feeding the shards once the document channel closes
+	routers, routerCtx := errgroup.WithContext(ctx)
+	for i := 0; i < w.writerCount; i++ {
+		routers.Go(func() (err error) {
+			defer recoverPanic(&err)
+			for doc := range docChan {
+				data, err := bson.Marshal(doc)
+				if err != nil {
+					return fmt.Errorf("failed to marshal document: %w", err)
+				}
+				id, err := router.route(data)
+				if err != nil {
+					return err
+				}
+				select {
+				case shards[id].docs <- doc:
+				case <-shards[id].done:
+					// The shard's writers stopped at the target
+					atomic.AddInt64(&w.docsDiscarded, 1)
+				case <-routerCtx.Done():
+					return routerCtx.Err()
+				}
+				if w.budget.exhausted() {
+					return nil
+				}
+			}
+			return nil
+		})
+	}
+	eg.Go(func() error {
+		err := routers.Wait()
+		for _, shard := range shards {
+			close(shard.docs)
+		}
+		return err
+	})
+
+	return eg.Wait()
+}
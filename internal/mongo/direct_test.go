@@ -0,0 +1,104 @@
+package mongo
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseShardMap(t *testing.T) {
+	shards, err := ParseShardMap("shard-0=mongodb://a:27017,b:27017/?replicaSet=rs0; shard-1=mongodb://c:27017/")
+	if err != nil {
+		t.Fatalf("ParseShardMap: %v", err)
+	}
+	if shards["shard-0"] != "mongodb://a:27017,b:27017/?replicaSet=rs0" || shards["shard-1"] != "mongodb://c:27017/" {
+		t.Errorf("Unexpected shard map: %v", shards)
+	}
+
+	for _, invalid := range []string{"shard-0", "=mongodb://a", "shard-0=", "s=mongodb://a;s=mongodb://b"} {
+		if _, err := ParseShardMap(invalid); err == nil {
+			t.Errorf("Expected error for shard map %q", invalid)
+		}
+	}
+}
+
+func TestChunkRouterRanged(t *testing.T) {
+	router := &chunkRouter{
+		key:    ShardKey{Field: "customer_id"},
+		mins:   []interface{}{primitive.MinKey{}, "40000000", "80000000"},
+		shards: []string{"a", "b", "c"},
+	}
+	tests := map[string]string{
+		"0f3e2a1c-0000-4000-8000-000000000000": "a",
+		"40000000-0000-4000-8000-000000000000": "b",
+		"7fffffff-0000-4000-8000-000000000000": "b",
+		"ff000000-0000-4000-8000-000000000000": "c",
+	}
+	for key, want := range tests {
+		doc, _ := bson.Marshal(bson.D{{Key: "customer_id", Value: key}})
+		got, err := router.route(doc)
+		if err != nil || got != want {
+			t.Errorf("route(%s) = %s, %v, want %s", key, got, err, want)
+		}
+	}
+
+	doc, _ := bson.Marshal(bson.D{{Key: "email", Value: "x"}})
+	if _, err := router.route(doc); err == nil {
+		t.Error("Expected error for a document without the shard key")
+	}
+}
+
+func TestChunkRouterHashed(t *testing.T) {
+	router := &chunkRouter{
+		key:    ShardKey{Field: "_id", Hashed: true},
+		mins:   []interface{}{primitive.MinKey{}, int64(0)},
+		shards: []string{"negative", "positive"},
+	}
+
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		doc, _ := bson.Marshal(bson.D{{Key: "_id", Value: primitive.NewObjectID()}})
+		shard, err := router.route(doc)
+		if err != nil {
+			t.Fatalf("route: %v", err)
+		}
+		counts[shard]++
+	}
+	if counts["negative"] < 400 || counts["positive"] < 400 {
+		t.Errorf("Hashed keys are not spread evenly: %v", counts)
+	}
+}
+
+func TestHashedValue(t *testing.T) {
+	raw := func(v interface{}) bson.RawValue {
+		doc, _ := bson.Marshal(bson.D{{Key: "k", Value: v}})
+		return bson.Raw(doc).Lookup("k")
+	}
+
+	a, err := hashedValue(raw("customer-1"))
+	if err != nil {
+		t.Fatalf("hashedValue: %v", err)
+	}
+	if b, _ := hashedValue(raw("customer-1")); a != b {
+		t.Error("Hashes of equal values differ")
+	}
+	if b, _ := hashedValue(raw("customer-2")); a == b {
+		t.Error("Hashes of different values are equal")
+	}
+	if _, err := hashedValue(raw(1.5)); err == nil {
+		t.Error("Expected error for an unsupported type")
+	}
+}
+
+func TestLessBound(t *testing.T) {
+	ordered := []interface{}{primitive.MinKey{}, "a", "b", primitive.MaxKey{}}
+	for i := 0; i+1 < len(ordered); i++ {
+		if !lessBound(ordered[i], ordered[i+1]) || lessBound(ordered[i+1], ordered[i]) {
+			t.Errorf("Expected %v < %v", ordered[i], ordered[i+1])
+		}
+	}
+	if lessBound(int64(5), int64(5)) {
+		t.Error("Equal bounds should not be less")
+	}
+}
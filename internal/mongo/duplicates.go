@@ -0,0 +1,152 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Duplicate modes for DuplicateRatio
+const (
+	DuplicateInsert = "insert" // Plain inserts, expected to fail with duplicate key errors
+	DuplicateUpsert = "upsert" // Upserts replacing the existing document
+)
+
+// duplicateIDPoolSize bounds how many written _ids are remembered for reuse
+const duplicateIDPoolSize = 100000
+
+// duplicateTracker remembers recently written _ids so a fraction of new
+// writes can intentionally collide with existing documents
+type duplicateTracker struct {
+	mu   sync.Mutex
+	ids  []primitive.ObjectID
+	next int
+	rng  *rand.Rand
+}
+
+// newDuplicateTracker creates an empty tracker
+func newDuplicateTracker() *duplicateTracker {
+	return &duplicateTracker{
+		ids: make([]primitive.ObjectID, 0, duplicateIDPoolSize),
+		rng: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// remember records the _ids of documents that were written
+func (t *duplicateTracker) remember(batch []interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, doc := range batch {
+		generated, ok := doc.(model.Document)
+		if !ok {
+			continue
+		}
+		if len(t.ids) < duplicateIDPoolSize {
+			t.ids = append(t.ids, generated.DocumentID())
+		} else {
+			t.ids[t.next] = generated.DocumentID()
+			t.next = (t.next + 1) % duplicateIDPoolSize
+		}
+	}
+}
+
+// pick returns an existing _id with probability ratio
+func (t *duplicateTracker) pick(ratio float64) (primitive.ObjectID, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.ids) == 0 || t.rng.Float64() >= ratio {
+		return primitive.ObjectID{}, false
+	}
+	return t.ids[t.rng.Intn(len(t.ids))], true
+}
+
+// injectDuplicates reassigns existing _ids to a fraction of the batch. In
+// upsert mode the colliding documents are returned separately to be upserted;
+// in insert mode they stay in the batch and are expected to be rejected, and
+// their _ids are returned as collisions.
+func (w *Writer) injectDuplicates(batch []interface{}) (inserts, upserts []interface{}, collisions map[primitive.ObjectID]bool) {
+	if w.duplicateMode != DuplicateUpsert {
+		collisions = make(map[primitive.ObjectID]bool)
+		for _, doc := range batch {
+			if generated, ok := doc.(model.Document); ok {
+				if id, ok := w.duplicates.pick(w.duplicateRatio); ok {
+					generated.SetDocumentID(id)
+					collisions[id] = true
+				}
+			}
+		}
+		return batch, nil, collisions
+	}
+
+	inserts = make([]interface{}, 0, len(batch))
+	for _, doc := range batch {
+		if generated, ok := doc.(model.Document); ok {
+			if id, ok := w.duplicates.pick(w.duplicateRatio); ok {
+				generated.SetDocumentID(id)
+				upserts = append(upserts, doc)
+				continue
+			}
+		}
+		inserts = append(inserts, doc)
+	}
+	return inserts, upserts, nil
+}
+
+// duplicateKeyIndexes returns the batch indexes rejected with duplicate key
+// errors, if those are the only errors
+func duplicateKeyIndexes(err error) ([]int, bool) {
+	if !isDuplicateKeyOnly(err) {
+		return nil, false
+	}
+
+	var bwe mongo.BulkWriteException
+	errors.As(err, &bwe)
+	indexes := make([]int, len(bwe.WriteErrors))
+	for i, we := range bwe.WriteErrors {
+		indexes[i] = we.Index
+	}
+	return indexes, true
+}
+
+// writeUpserts replaces (or inserts) documents that reuse existing _ids
+func (w *Writer) writeUpserts(ctx context.Context, collection *mongo.Collection, docs []interface{}) error {
+	models := make([]mongo.WriteModel, len(docs))
+	for i, doc := range docs {
+		models[i] = mongo.NewReplaceOneModel().
+			SetFilter(bson.D{{Key: "_id", Value: doc.(model.Document).DocumentID()}}).
+			SetReplacement(doc).
+			SetUpsert(true)
+	}
+
+	startTime := time.Now()
+	err := w.insertWithRetry(ctx, func(ctx context.Context) error {
+		_, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+		return err
+	})
+	latency := time.Since(startTime)
+
+	if w.ycsbLogger != nil {
+		avgLatencyPerDoc := latency / time.Duration(len(docs))
+		for i := 0; i < len(docs); i++ {
+			w.ycsbLogger.RecordOperation("UPSERT", avgLatencyPerDoc, err == nil)
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to upsert duplicates: %w", err)
+	}
+	atomic.AddInt64(&w.upserts, int64(len(docs)))
+	return nil
+}
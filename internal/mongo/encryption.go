@@ -0,0 +1,295 @@
+package mongo
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/mongocrypt"
+)
+
+// Encryption modes: how encrypted fields are declared to the driver
+const (
+	// EncryptionCSFLE encrypts fields with client-side field level encryption,
+	// declared in a JSON schema
+	EncryptionCSFLE = "csfle"
+	// EncryptionQE encrypts fields with Queryable Encryption (MongoDB 7.0+),
+	// declared in the encrypted fields of the collection
+	EncryptionQE = "qe"
+)
+
+// KMS providers holding the master key that data keys are encrypted with
+const (
+	KMSLocal = "local" // 96-byte key kept in a file, see LoadLocalKey
+	KMSAWS   = "aws"
+	KMSAzure = "azure"
+	KMSGCP   = "gcp"
+)
+
+// KeyVaultNamespace is the collection data keys are stored in
+const KeyVaultNamespace = "encryption.__keyVault"
+
+// localKeySize is the size of a local KMS master key
+const localKeySize = 96
+
+// bsonTypeAliases are the names of BSON types in encryption schemas
+var bsonTypeAliases = map[bsontype.Type]string{
+	bsontype.Double:           "double",
+	bsontype.String:           "string",
+	bsontype.EmbeddedDocument: "object",
+	bsontype.Array:            "array",
+	bsontype.Binary:           "binData",
+	bsontype.ObjectID:         "objectId",
+	bsontype.Boolean:          "bool",
+	bsontype.DateTime:         "date",
+	bsontype.Regex:            "regex",
+	bsontype.JavaScript:       "javascript",
+	bsontype.Int32:            "int",
+	bsontype.Timestamp:        "timestamp",
+	bsontype.Int64:            "long",
+	bsontype.Decimal128:       "decimal",
+}
+
+// EncryptionConfig enables automatic encryption of fields of the target
+// collection. Inserts go through a client that encrypts them; the writer's
+// other operations, such as size polling and churn, see the stored ciphertext.
+type EncryptionConfig struct {
+	// Mode is EncryptionCSFLE (default) or EncryptionQE
+	Mode string
+
+	// Fields are the top-level fields to encrypt, with their BSON types
+	Fields map[string]bsontype.Type
+
+	// Equality encrypts fields so they can be queried for equality:
+	// deterministically (CSFLE) or with equality indexes (QE), instead of
+	// with the randomized algorithm
+	Equality bool
+
+	// KMSProvider holds the master key: KMSLocal (default) with LocalKey, or
+	// a cloud provider with MasterKey locating the key. Cloud credentials are
+	// read from the environment.
+	KMSProvider string
+	LocalKey    []byte
+	MasterKey   bson.D
+
+	// CryptSharedLib is the path of the automatic encryption shared library
+	// ("" = spawn mongocryptd from the PATH)
+	CryptSharedLib string
+}
+
+// validate checks the settings before connecting
+func (c *EncryptionConfig) validate() error {
+	switch c.Mode {
+	case EncryptionCSFLE, EncryptionQE:
+	default:
+		return fmt.Errorf("invalid encryption mode: %s", c.Mode)
+	}
+	if len(c.Fields) == 0 {
+		return fmt.Errorf("no fields to encrypt")
+	}
+	for _, field := range c.fieldNames() {
+		typ := c.Fields[field]
+		if field == "_id" {
+			return fmt.Errorf("_id cannot be encrypted")
+		}
+		if _, ok := bsonTypeAliases[typ]; !ok {
+			return fmt.Errorf("field %s of type %v cannot be encrypted", field, typ)
+		}
+		if c.Equality {
+			switch typ {
+			case bsontype.Double, bsontype.Decimal128, bsontype.Boolean, bsontype.EmbeddedDocument, bsontype.Array:
+				return fmt.Errorf("field %s of type %s cannot be encrypted for equality queries", field, bsonTypeAliases[typ])
+			}
+		}
+	}
+	switch c.KMSProvider {
+	case KMSLocal:
+		if len(c.LocalKey) != localKeySize {
+			return fmt.Errorf("local master key must be %d bytes, got %d", localKeySize, len(c.LocalKey))
+		}
+	case KMSAWS, KMSAzure, KMSGCP:
+		if len(c.MasterKey) == 0 {
+			return fmt.Errorf("the %s KMS provider requires a master key", c.KMSProvider)
+		}
+	default:
+		return fmt.Errorf("invalid KMS provider: %s", c.KMSProvider)
+	}
+	if !EncryptionAvailable() {
+		return fmt.Errorf("client-side encryption requires a build with libmongocrypt (go build -tags cse)")
+	}
+	return nil
+}
+
+// EncryptionAvailable reports whether the binary was built with libmongocrypt,
+// which automatic encryption requires
+func EncryptionAvailable() bool {
+	return mongocrypt.Version() != ""
+}
+
+// fieldNames returns the encrypted fields in a stable order
+func (c *EncryptionConfig) fieldNames() []string {
+	fields := make([]string, 0, len(c.Fields))
+	for field := range c.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// kmsProviders returns the KMS provider credentials, empty for cloud
+// providers so the driver fetches them from the environment on demand
+func (c *EncryptionConfig) kmsProviders() map[string]map[string]interface{} {
+	if c.KMSProvider == KMSLocal {
+		return map[string]map[string]interface{}{KMSLocal: {"key": c.LocalKey}}
+	}
+	return map[string]map[string]interface{}{c.KMSProvider: {}}
+}
+
+// ParseMasterKey parses the location of a cloud KMS master key, given as
+// comma-separated key=value pairs (e.g. region=us-east-1,key=arn:...)
+func ParseMasterKey(s string) (bson.D, error) {
+	var masterKey bson.D
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" || value == "" {
+			return nil, fmt.Errorf("invalid master key setting %q (use key=value)", pair)
+		}
+		masterKey = append(masterKey, bson.E{Key: key, Value: value})
+	}
+	return masterKey, nil
+}
+
+// LoadLocalKey reads the local KMS master key from path, creating a random
+// key there if the file does not exist. Data keys are encrypted with it, so
+// later runs need the same file to write to the collection again.
+func LoadLocalKey(path string) (key []byte, created bool, err error) {
+	key, err = os.ReadFile(path)
+	if err == nil {
+		if len(key) != localKeySize {
+			return nil, false, fmt.Errorf("local master key %s must be %d bytes, got %d", path, localKeySize, len(key))
+		}
+		return key, false, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, false, fmt.Errorf("failed to read local master key: %w", err)
+	}
+
+	key = make([]byte, localKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, false, fmt.Errorf("failed to generate local master key: %w", err)
+	}
+	if err := os.WriteFile(path, key, 0600); err != nil {
+		return nil, false, fmt.Errorf("failed to write local master key: %w", err)
+	}
+	return key, true, nil
+}
+
+// setupEncryption creates or reuses one data key per encrypted field of the
+// namespace and returns the options of clients that encrypt its inserts. In
+// EncryptionQE mode it also returns the encrypted fields the collection must
+// be created with.
+func setupEncryption(ctx context.Context, keyVault *mongo.Client, databaseName, collectionName string, config *EncryptionConfig) (*options.AutoEncryptionOptions, bson.M, error) {
+	vaultDB, vaultColl, _ := strings.Cut(KeyVaultNamespace, ".")
+	_, err := keyVault.Database(vaultDB).Collection(vaultColl).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "keyAltNames", Value: 1}},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.D{{Key: "keyAltNames", Value: bson.D{{Key: "$exists", Value: true}}}}),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create key vault index: %w", err)
+	}
+
+	encryption, err := mongo.NewClientEncryption(keyVault, options.ClientEncryption().
+		SetKeyVaultNamespace(KeyVaultNamespace).
+		SetKmsProviders(config.kmsProviders()))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to set up client encryption: %w", err)
+	}
+	defer encryption.Close(ctx)
+
+	namespace := databaseName + "." + collectionName
+	properties := bson.M{}
+	var fields bson.A
+	for _, field := range config.fieldNames() {
+		keyID, err := dataKey(ctx, encryption, config, namespace+"."+field)
+		if err != nil {
+			return nil, nil, err
+		}
+		bsonType := bsonTypeAliases[config.Fields[field]]
+
+		if config.Mode == EncryptionQE {
+			encrypted := bson.M{"path": field, "bsonType": bsonType, "keyId": keyID}
+			if config.Equality {
+				encrypted["queries"] = bson.M{"queryType": "equality"}
+			}
+			fields = append(fields, encrypted)
+			continue
+		}
+		algorithm := "AEAD_AES_256_CBC_HMAC_SHA_512-Random"
+		if config.Equality {
+			algorithm = "AEAD_AES_256_CBC_HMAC_SHA_512-Deterministic"
+		}
+		properties[field] = bson.M{"encrypt": bson.M{
+			"bsonType":  bsonType,
+			"algorithm": algorithm,
+			"keyId":     bson.A{keyID},
+		}}
+	}
+
+	opts := options.AutoEncryption().
+		SetKeyVaultNamespace(KeyVaultNamespace).
+		SetKmsProviders(config.kmsProviders())
+	if config.CryptSharedLib != "" {
+		opts.SetExtraOptions(map[string]interface{}{
+			"cryptSharedLibPath":     config.CryptSharedLib,
+			"cryptSharedLibRequired": true,
+		})
+	}
+	if config.Mode == EncryptionQE {
+		encryptedFields := bson.M{"fields": fields}
+		opts.SetEncryptedFieldsMap(map[string]interface{}{namespace: encryptedFields})
+		return opts, encryptedFields, nil
+	}
+	opts.SetSchemaMap(map[string]interface{}{namespace: bson.M{
+		"bsonType":   "object",
+		"properties": properties,
+	}})
+	return opts, nil, nil
+}
+
+// dataKey returns the ID of the data key named altName, creating it if it
+// does not exist yet, so repeated loads into a collection share its keys
+func dataKey(ctx context.Context, encryption *mongo.ClientEncryption, config *EncryptionConfig, altName string) (primitive.Binary, error) {
+	var key struct {
+		ID primitive.Binary `bson:"_id"`
+	}
+	err := encryption.GetKeyByAltName(ctx, altName).Decode(&key)
+	if err == nil {
+		return key.ID, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return primitive.Binary{}, fmt.Errorf("failed to look up data key %s: %w", altName, err)
+	}
+
+	opts := options.DataKey().SetKeyAltNames([]string{altName})
+	if config.KMSProvider != KMSLocal {
+		opts.SetMasterKey(config.MasterKey)
+	}
+	id, err := encryption.CreateDataKey(ctx, config.KMSProvider, opts)
+	if err != nil {
+		return primitive.Binary{}, fmt.Errorf("failed to create data key %s: %w", altName, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,67 @@
+package mongo
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+func TestParseMasterKey(t *testing.T) {
+	key, err := ParseMasterKey("region=us-east-1, key=arn:aws:kms:us-east-1:123:key/abc")
+	if err != nil {
+		t.Fatalf("Failed to parse master key: %v", err)
+	}
+	want := bson.D{{Key: "region", Value: "us-east-1"}, {Key: "key", Value: "arn:aws:kms:us-east-1:123:key/abc"}}
+	if len(key) != len(want) || key[0] != want[0] || key[1] != want[1] {
+		t.Errorf("Expected %v, got %v", want, key)
+	}
+
+	for _, s := range []string{"region", "=x", "region="} {
+		if _, err := ParseMasterKey(s); err == nil {
+			t.Errorf("Expected error for %q", s)
+		}
+	}
+}
+
+func TestLoadLocalKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "master.key")
+	key, created, err := LoadLocalKey(path)
+	if err != nil || !created || len(key) != localKeySize {
+		t.Fatalf("Expected a new %d-byte key, got %d bytes (created %v): %v", localKeySize, len(key), created, err)
+	}
+	again, created, err := LoadLocalKey(path)
+	if err != nil || created || !bytes.Equal(key, again) {
+		t.Errorf("Expected the existing key to be read back (created %v): %v", created, err)
+	}
+
+	if err := os.WriteFile(path, []byte("short"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := LoadLocalKey(path); err == nil {
+		t.Error("Expected error for a key of the wrong size")
+	}
+}
+
+func TestNewWriterRejectsEncryption(t *testing.T) {
+	localKey := make([]byte, localKeySize)
+	fields := map[string]bsontype.Type{"email": bsontype.String}
+	configs := []*EncryptionConfig{
+		{Mode: "fle2", Fields: fields, LocalKey: localKey},
+		{LocalKey: localKey},
+		{Fields: map[string]bsontype.Type{"_id": bsontype.ObjectID}, LocalKey: localKey},
+		{Fields: map[string]bsontype.Type{"orders": bsontype.Array}, Equality: true, LocalKey: localKey},
+		{Fields: fields, LocalKey: localKey[:32]},
+		{Fields: fields, KMSProvider: KMSAWS},
+		{Fields: fields, KMSProvider: "vault"},
+	}
+	// Settings are validated before connecting
+	for _, encryption := range configs {
+		if _, err := NewWriter(Config{Encryption: encryption}); err == nil {
+			t.Errorf("Expected error for %+v", encryption)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// growthPlans splits a batch into the small documents to insert and the
+// updates growing each of them to full size, see model.GrowthPlan
+func (w *Writer) growthPlans(batch []interface{}) ([]interface{}, [][]bson.D, error) {
+	initial := make([]interface{}, len(batch))
+	plans := make([][]bson.D, len(batch))
+	for i, doc := range batch {
+		generated, ok := doc.(model.Document)
+		if !ok {
+			return nil, nil, fmt.Errorf("failed to plan growth: unexpected document type %T", doc)
+		}
+		small, updates, err := model.GrowthPlan(generated, w.growthFields, w.growthSteps)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to plan growth: %w", err)
+		}
+		initial[i], plans[i] = small, updates
+	}
+	return initial, plans, nil
+}
+
+// grow applies the growth updates of a batch that was just inserted, one
+// round per step so every document grows a little at a time. Updates are not
+// retried, as a $push applied twice would grow a document past its size.
+func (w *Writer) grow(ctx context.Context, collection *mongo.Collection, batch []interface{}, plans [][]bson.D) error {
+	for step := 0; ; step++ {
+		var models []mongo.WriteModel
+		for i, updates := range plans {
+			if step < len(updates) {
+				id := batch[i].(model.Document).DocumentID()
+				models = append(models, mongo.NewUpdateOneModel().
+					SetFilter(bson.D{{Key: "_id", Value: id}}).
+					SetUpdate(updates[step]))
+			}
+		}
+		if len(models) == 0 {
+			return nil
+		}
+
+		startTime := time.Now()
+		growCtx, cancel := withTimeout(ctx, w.insertTimeout)
+		_, err := collection.BulkWrite(growCtx, models, options.BulkWrite().SetOrdered(false))
+		cancel()
+		latency := time.Since(startTime)
+
+		if w.ycsbLogger != nil {
+			avgLatencyPerDoc := latency / time.Duration(len(models))
+			for i := 0; i < len(models); i++ {
+				if isTimeout(err) {
+					w.ycsbLogger.RecordTimeout("GROW", avgLatencyPerDoc)
+				} else {
+					w.ycsbLogger.RecordOperation("GROW", avgLatencyPerDoc, err == nil)
+				}
+			}
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to grow documents: %w", err)
+		}
+		atomic.AddInt64(&w.growthUpdates, int64(len(models)))
+	}
+}
@@ -0,0 +1,162 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Backoff of batches while the server is under pressure: the delay starts at
+// minHealthDelay, doubles on every poll that finds pressure up to
+// maxHealthDelay, and halves on every poll that does not
+const (
+	minHealthDelay = 10 * time.Millisecond
+	maxHealthDelay = 2 * time.Second
+)
+
+// HealthLimits are the serverStatus readings beyond which a server counts as
+// under write pressure
+type HealthLimits struct {
+	MaxCacheDirty  float64 // Percent of the WiredTiger cache holding dirty data
+	MinFreeTickets float64 // Fraction of write tickets available (0-1)
+}
+
+// ServerHealth is the write pressure a server reports in serverStatus
+type ServerHealth struct {
+	CacheDirty        float64 // Percent of the WiredTiger cache holding dirty data
+	FreeTickets       float64 // Fraction of write tickets available (1 when unknown)
+	FlowControlLagged bool    // Flow control is throttling writes to protect majority commit
+}
+
+// pressure returns why h shows write pressure beyond limits ("" = none)
+func (h ServerHealth) pressure(limits HealthLimits) string {
+	switch {
+	case h.FlowControlLagged:
+		return "flow control engaged"
+	case limits.MaxCacheDirty > 0 && h.CacheDirty > limits.MaxCacheDirty:
+		return fmt.Sprintf("cache %.1f%% dirty", h.CacheDirty)
+	case h.FreeTickets < limits.MinFreeTickets:
+		return fmt.Sprintf("%.0f%% of write tickets available", h.FreeTickets*100)
+	}
+	return ""
+}
+
+// ticketStats are the write tickets of a storage engine ticket pool
+type ticketStats struct {
+	Available    float64 `bson:"available"`
+	TotalTickets float64 `bson:"totalTickets"`
+}
+
+// ReadServerHealth returns the write pressure of the server client is
+// connected to. It fails on servers without the WiredTiger storage engine,
+// including mongos.
+func ReadServerHealth(ctx context.Context, client *mongo.Client) (ServerHealth, error) {
+	var status struct {
+		WiredTiger *struct {
+			Cache struct {
+				Dirty float64 `bson:"tracked dirty bytes in the cache"`
+				Max   float64 `bson:"maximum bytes configured"`
+			} `bson:"cache"`
+			ConcurrentTransactions struct {
+				Write ticketStats `bson:"write"`
+			} `bson:"concurrentTransactions"`
+		} `bson:"wiredTiger"`
+		// MongoDB 7.0+ reports tickets here
+		Queues struct {
+			Execution struct {
+				Write ticketStats `bson:"write"`
+			} `bson:"execution"`
+		} `bson:"queues"`
+		FlowControl struct {
+			IsLagged bool `bson:"isLagged"`
+		} `bson:"flowControl"`
+	}
+	cmd := bson.D{{Key: "serverStatus", Value: 1}, {Key: "repl", Value: 0}, {Key: "metrics", Value: 0}, {Key: "locks", Value: 0}}
+	if err := client.Database("admin").RunCommand(ctx, cmd).Decode(&status); err != nil {
+		return ServerHealth{}, fmt.Errorf("failed to read server status: %w", err)
+	}
+	if status.WiredTiger == nil {
+		return ServerHealth{}, fmt.Errorf("server status has no WiredTiger statistics (mongos or another storage engine)")
+	}
+
+	health := ServerHealth{FreeTickets: 1, FlowControlLagged: status.FlowControl.IsLagged}
+	if cache := status.WiredTiger.Cache; cache.Max > 0 {
+		health.CacheDirty = cache.Dirty / cache.Max * 100
+	}
+	tickets := status.Queues.Execution.Write
+	if tickets.TotalTickets == 0 {
+		tickets = status.WiredTiger.ConcurrentTransactions.Write
+	}
+	if tickets.TotalTickets > 0 {
+		health.FreeTickets = tickets.Available / tickets.TotalTickets
+	}
+	return health, nil
+}
+
+// nextHealthDelay returns the batch delay after a poll that found pressure
+// or not
+func nextHealthDelay(delay time.Duration, pressure bool) time.Duration {
+	if pressure {
+		return min(max(delay*2, minHealthDelay), maxHealthDelay)
+	}
+	if delay /= 2; delay < minHealthDelay {
+		return 0
+	}
+	return delay
+}
+
+// StartHealthWatch polls serverStatus every interval and backs off inserts
+// while the server shows write pressure beyond limits or flow control is
+// engaged, delaying every batch longer the longer the pressure lasts. Once
+// the pressure is gone, the delay shrinks back to full speed. Throttle events
+// are logged and counted in Stats.Throttle. It stops after the first poll if
+// the server does not report WiredTiger statistics.
+func (w *Writer) StartHealthWatch(ctx context.Context, interval time.Duration, limits HealthLimits) {
+	go func() {
+		defer w.gate.setDelay("health", 0)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var delay time.Duration
+		for first := true; ; first = false {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			statsCtx, cancel := context.WithTimeout(ctx, interval)
+			health, err := ReadServerHealth(statsCtx, w.client)
+			cancel()
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				if first {
+					log.Printf("Warning: server health watch disabled: %v", err)
+					return
+				}
+				log.Printf("Server health poll failed: %v", err)
+				continue
+			}
+
+			reason := health.pressure(limits)
+			next := nextHealthDelay(delay, reason != "")
+			switch {
+			case delay == 0 && next > 0:
+				log.Printf("Server under pressure (%s), delaying insert batches by %v", reason, next)
+			case next > delay:
+				log.Printf("Server still under pressure (%s), delaying insert batches by %v", reason, next)
+			case delay > 0 && next == 0:
+				log.Printf("Server pressure relieved, inserting at full speed")
+			}
+			delay = next
+			w.gate.setDelay("health", delay)
+		}
+	}()
+}
@@ -0,0 +1,58 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestServerHealthPressure(t *testing.T) {
+	limits := HealthLimits{MaxCacheDirty: 10, MinFreeTickets: 0.2}
+	tests := []struct {
+		health   ServerHealth
+		pressure bool
+	}{
+		{ServerHealth{CacheDirty: 4, FreeTickets: 0.9}, false},
+		{ServerHealth{CacheDirty: 12, FreeTickets: 0.9}, true},
+		{ServerHealth{CacheDirty: 4, FreeTickets: 0.1}, true},
+		{ServerHealth{CacheDirty: 4, FreeTickets: 1, FlowControlLagged: true}, true},
+	}
+	for _, tt := range tests {
+		if reason := tt.health.pressure(limits); (reason != "") != tt.pressure {
+			t.Errorf("%+v: expected pressure %v, got %q", tt.health, tt.pressure, reason)
+		}
+	}
+}
+
+func TestNextHealthDelay(t *testing.T) {
+	var delay time.Duration
+	for i := 0; i < 20; i++ {
+		delay = nextHealthDelay(delay, true)
+	}
+	if delay != maxHealthDelay {
+		t.Errorf("Expected the delay to stop at %v, got %v", maxHealthDelay, delay)
+	}
+	for i := 0; i < 20 && delay > 0; i++ {
+		delay = nextHealthDelay(delay, false)
+	}
+	if delay != 0 {
+		t.Errorf("Expected the delay to return to 0, got %v", delay)
+	}
+	if delay = nextHealthDelay(0, true); delay != minHealthDelay {
+		t.Errorf("Expected the first backoff to be %v, got %v", minHealthDelay, delay)
+	}
+}
+
+func TestWriteGateDelay(t *testing.T) {
+	var g writeGate
+	g.setDelay("health", 20*time.Millisecond)
+	g.setDelay("health", 30*time.Millisecond)
+	start := time.Now()
+	if err := g.wait(context.Background()); err != nil || time.Since(start) < 30*time.Millisecond {
+		t.Errorf("Expected the batch to be delayed by 30ms, waited %v: %v", time.Since(start), err)
+	}
+	g.setDelay("health", 0)
+	if stats := g.stats(); stats.Delay != 0 || stats.Backoffs != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
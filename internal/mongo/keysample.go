@@ -0,0 +1,91 @@
+package mongo
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// keySample keeps a uniform random sample of the business keys of the
+// documents actually written (reservoir sampling). Keys the generator issued
+// for documents that were buffered, dropped near the target, or discarded
+// never reach it, so verification can probe the sample and expect every key.
+type keySample struct {
+	field string
+	size  int
+
+	mu   sync.Mutex
+	rng  *rand.Rand
+	seen int64
+	keys []string
+}
+
+// newKeySample returns a sample of up to size keys of field, or nil if
+// either is unset
+func newKeySample(field string, size int) *keySample {
+	if field == "" || size <= 0 {
+		return nil
+	}
+	return &keySample{
+		field: field,
+		size:  size,
+		rng:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// add offers the keys of written documents to the sample. Only the documents
+// that take a slot are marshaled to look up their key.
+func (s *keySample) add(docs []interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, doc := range docs {
+		s.seen++
+		slot := len(s.keys)
+		if slot >= s.size {
+			if slot = int(s.rng.Int63n(s.seen)); slot >= s.size {
+				continue
+			}
+		}
+		key, ok := documentKey(doc, s.field)
+		if !ok {
+			continue
+		}
+		if slot == len(s.keys) {
+			s.keys = append(s.keys, key)
+		} else {
+			s.keys[slot] = key
+		}
+	}
+}
+
+// sample returns a copy of the sampled keys
+func (s *keySample) sample() []string {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.keys...)
+}
+
+// documentKey returns the string value of a document's key field
+func documentKey(doc interface{}, field string) (string, bool) {
+	raw, ok := doc.(bson.Raw)
+	if !ok {
+		data, err := bson.Marshal(doc)
+		if err != nil {
+			return "", false
+		}
+		raw = data
+	}
+	value, err := raw.LookupErr(field)
+	if err != nil {
+		return "", false
+	}
+	return value.StringValueOK()
+}
@@ -0,0 +1,57 @@
+package mongo
+
+import (
+	"fmt"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestKeySample(t *testing.T) {
+	if newKeySample("", 10) != nil || newKeySample("customer_id", 0) != nil {
+		t.Error("Expected no sample without a field and size")
+	}
+	var none *keySample
+	none.add([]interface{}{bson.D{{Key: "customer_id", Value: "a"}}})
+	if keys := none.sample(); keys != nil {
+		t.Errorf("Unexpected keys of a disabled sample: %v", keys)
+	}
+
+	s := newKeySample("customer_id", 10)
+	written := make(map[string]bool)
+	for batch := 0; batch < 100; batch++ {
+		docs := make([]interface{}, 0, 10)
+		for i := 0; i < 10; i++ {
+			key := fmt.Sprintf("key-%d-%d", batch, i)
+			written[key] = true
+			if i%2 == 0 {
+				raw, err := bson.Marshal(bson.D{{Key: "customer_id", Value: key}})
+				if err != nil {
+					t.Fatal(err)
+				}
+				docs = append(docs, bson.Raw(raw))
+			} else {
+				docs = append(docs, bson.D{{Key: "customer_id", Value: key}})
+			}
+		}
+		s.add(docs)
+	}
+	keys := s.sample()
+	if len(keys) != 10 {
+		t.Fatalf("Sampled %d keys, want 10", len(keys))
+	}
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		if !written[key] || seen[key] {
+			t.Errorf("Unexpected sampled key %s", key)
+		}
+		seen[key] = true
+	}
+
+	// Documents without a string key are never sampled
+	s = newKeySample("customer_id", 10)
+	s.add([]interface{}{bson.D{{Key: "sku", Value: "a"}}, bson.D{{Key: "customer_id", Value: 1}}})
+	if keys := s.sample(); len(keys) != 0 {
+		t.Errorf("Unexpected keys %v", keys)
+	}
+}
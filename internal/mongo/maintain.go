@@ -0,0 +1,161 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// MaintainConfig configures Maintain
+type MaintainConfig struct {
+	Metric    string        // Server-side size metric kept at Target; SizeBytes uses SizeData
+	Target    int64         // Bytes of Metric
+	Tolerance float64       // Fraction of Target the size may drift before it is corrected
+	Interval  time.Duration // How often the size is checked
+}
+
+// MaintainStats counts the corrections of Maintain
+type MaintainStats struct {
+	Checks           int64 `json:"checks"`
+	TopUps           int64 `json:"top_ups"`
+	Trims            int64 `json:"trims"`
+	DocumentsAdded   int64 `json:"documents_added"`
+	DocumentsTrimmed int64 `json:"documents_trimmed"`
+	LastSize         int64 `json:"last_size"` // Last size of the metric checked
+}
+
+// Maintain keeps the collection at config.Target bytes until ctx is done,
+// e.g. while churn or a TTL index deletes documents: every interval, a size
+// below the tolerance is topped up with documents from next, written like
+// the loaded ones, and a size above it is trimmed by deleting the oldest
+// documents. Storage metrics are only topped up, as storage the server
+// already allocated does not shrink when documents are deleted.
+func (w *Writer) Maintain(ctx context.Context, config MaintainConfig, next func() (model.Document, error)) (MaintainStats, error) {
+	if config.Metric == SizeBytes {
+		config.Metric = SizeData
+	}
+	var stats MaintainStats
+	ticker := time.NewTicker(config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return stats, ctx.Err()
+		case <-ticker.C:
+		}
+
+		statsCtx, cancel := context.WithTimeout(ctx, config.Interval)
+		size, err := w.RefreshCollectionSize(statsCtx, config.Metric)
+		var count int64
+		if err == nil {
+			count, err = w.collection.EstimatedDocumentCount(statsCtx)
+		}
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return stats, ctx.Err()
+			}
+			log.Printf("Size check failed: %v", err)
+			continue
+		}
+		stats.Checks++
+		stats.LastSize = size
+
+		drift := size - config.Target
+		if abs(drift) <= int64(float64(config.Target)*config.Tolerance) {
+			continue
+		}
+		// The metric's bytes per document convert the drift to documents
+		perDoc := w.averageDocumentSize()
+		if count > 0 {
+			perDoc = size / count
+		}
+		if perDoc <= 0 {
+			perDoc = 1
+		}
+
+		switch {
+		case drift < 0:
+			bytes := -drift
+			if avg := w.averageDocumentSize(); avg > 0 {
+				bytes = -drift * avg / perDoc
+			}
+			log.Printf("Size maintenance: %s of %d bytes is %d below the target, adding about %d bytes of documents",
+				config.Metric, size, -drift, bytes)
+			added, err := w.topUp(ctx, bytes, next)
+			stats.TopUps++
+			stats.DocumentsAdded += added
+			if err != nil {
+				return stats, err
+			}
+
+		case config.Metric == SizeData:
+			n := (drift + perDoc - 1) / perDoc
+			log.Printf("Size maintenance: %s of %d bytes is %d above the target, deleting the %d oldest documents",
+				config.Metric, size, drift, n)
+			deleted, err := w.trim(ctx, n)
+			stats.Trims++
+			stats.DocumentsTrimmed += deleted
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Size maintenance delete failed: %v", err)
+			}
+		}
+	}
+}
+
+// topUp writes documents from next totalling about bytes of BSON, in batches
+// the budget is extended for, and returns the documents written
+func (w *Writer) topUp(ctx context.Context, bytes int64, next func() (model.Document, error)) (int64, error) {
+	written := atomic.LoadInt64(&w.docsWritten)
+	for generated := int64(0); generated < bytes; {
+		batch := make([]interface{}, 0, w.batchSize)
+		var size int64
+		for len(batch) < w.batchSize && generated+size < bytes {
+			doc, err := next()
+			if err != nil {
+				return atomic.LoadInt64(&w.docsWritten) - written, err
+			}
+			data, err := bson.Marshal(doc)
+			if err != nil {
+				return atomic.LoadInt64(&w.docsWritten) - written, fmt.Errorf("failed to marshal document: %w", err)
+			}
+			batch = append(batch, doc)
+			size += int64(len(data))
+		}
+		generated += size
+
+		w.budget.extend(size)
+		if err := w.flushBatchTo(ctx, w.inserts, batch); err != nil {
+			return atomic.LoadInt64(&w.docsWritten) - written, err
+		}
+	}
+	return atomic.LoadInt64(&w.docsWritten) - written, nil
+}
+
+// trim deletes the n oldest documents in batches and returns the documents
+// deleted
+func (w *Writer) trim(ctx context.Context, n int64) (int64, error) {
+	deleted := atomic.LoadInt64(&w.docsDeleted)
+	for remaining := n; remaining > 0; {
+		chunk := min(remaining, int64(w.batchSize))
+		if err := w.deleteOldest(ctx, int(chunk)); err != nil {
+			return atomic.LoadInt64(&w.docsDeleted) - deleted, err
+		}
+		remaining -= chunk
+	}
+	return atomic.LoadInt64(&w.docsDeleted) - deleted, nil
+}
+
+// abs returns the absolute value of n
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,254 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/sync/errgroup"
+)
+
+// marshalPipeline accounts for the marshal and insert worker pools of a
+// writer that serializes documents off the insert path
+type marshalPipeline struct {
+	workers       int // Marshal workers
+	insertWorkers int
+	docs          int64
+	bytes         int64
+	marshalNanos  int64 // Serializing documents, summed over marshal workers
+	blockedNanos  int64 // Marshal workers waiting for insert workers to take a batch
+	insertNanos   int64 // Inserting batches, summed over insert workers
+	starvedNanos  int64 // Insert workers waiting for a marshaled batch
+}
+
+// MarshalStats compares the marshal and insert worker pools, to tell which
+// of CPU-bound serialization and insert round trips limits a load
+type MarshalStats struct {
+	MarshalWorkers     int
+	InsertWorkers      int
+	DocumentsMarshaled int64
+	BytesMarshaled     int64
+	MarshalTime        time.Duration // Summed over marshal workers
+	MarshalBlocked     time.Duration // Marshal workers waiting for insert workers
+	InsertTime         time.Duration // Summed over insert workers
+	InsertStarved      time.Duration // Insert workers waiting for marshaled batches
+}
+
+// MarshalCapacity is the documents per second the marshal pool could
+// serialize if it never waited
+func (s MarshalStats) MarshalCapacity() float64 {
+	if s.MarshalTime <= 0 {
+		return 0
+	}
+	return float64(s.DocumentsMarshaled) / s.MarshalTime.Seconds() * float64(s.MarshalWorkers)
+}
+
+// InsertCapacity is the documents per second the insert pool could insert
+// if it never waited, given the documents it inserted
+func (s MarshalStats) InsertCapacity(docs int64) float64 {
+	if s.InsertTime <= 0 {
+		return 0
+	}
+	return float64(docs) / s.InsertTime.Seconds() * float64(s.InsertWorkers)
+}
+
+// Bottleneck names the pool with the lower capacity, "marshal" or "insert",
+// given the documents inserted
+func (s MarshalStats) Bottleneck(docs int64) string {
+	if s.MarshalCapacity() < s.InsertCapacity(docs) {
+		return "marshal"
+	}
+	return "insert"
+}
+
+// newMarshalPipeline returns the accounting of a pool of the given number of
+// marshal workers, or nil for none
+func newMarshalPipeline(workers int) *marshalPipeline {
+	if workers <= 0 {
+		return nil
+	}
+	return &marshalPipeline{workers: workers}
+}
+
+// stats returns the pools' statistics, nil when marshaling on the insert path
+func (p *marshalPipeline) stats() *MarshalStats {
+	if p == nil {
+		return nil
+	}
+	return &MarshalStats{
+		MarshalWorkers:     p.workers,
+		InsertWorkers:      p.insertWorkers,
+		DocumentsMarshaled: atomic.LoadInt64(&p.docs),
+		BytesMarshaled:     atomic.LoadInt64(&p.bytes),
+		MarshalTime:        time.Duration(atomic.LoadInt64(&p.marshalNanos)),
+		MarshalBlocked:     time.Duration(atomic.LoadInt64(&p.blockedNanos)),
+		InsertTime:         time.Duration(atomic.LoadInt64(&p.insertNanos)),
+		InsertStarved:      time.Duration(atomic.LoadInt64(&p.starvedNanos)),
+	}
+}
+
+// writePipelined writes documents from docChan with a pool of marshal workers
+// that batch and serialize them, feeding the insert workers raw batches
+func (w *Writer) writePipelined(ctx context.Context, docChan <-chan model.Document) error {
+	w.pipeline.insertWorkers = w.writerCount
+	batches := make(chan []interface{}, w.writerCount)
+
+	eg, ctx := errgroup.WithContext(ctx)
+	marshalers, marshalCtx := errgroup.WithContext(ctx)
+	for i := 0; i < w.pipeline.workers; i++ {
+		marshalers.Go(func() (err error) {
+			defer recoverPanic(&err)
+			return w.marshalWorker(marshalCtx, docChan, batches)
+		})
+	}
+	eg.Go(func() error {
+		defer close(batches)
+		return marshalers.Wait()
+	})
+
+	for i := 0; i < w.writerCount; i++ {
+		writerID := i
+		eg.Go(func() (err error) {
+			defer recoverPanic(&err)
+			collection, release, err := w.writerCollection(writerID)
+			if err != nil {
+				return err
+			}
+			defer release()
+			return w.insertWorker(ctx, collection, batches)
+		})
+	}
+
+	err := eg.Wait()
+	// Batches marshaled but never taken can no longer be inserted
+	for batch := range batches {
+		atomic.AddInt64(&w.docsAbandoned, int64(len(batch)))
+	}
+	return err
+}
+
+// marshalWorker batches documents from docChan, serializing each to
+// bson.Raw, and sends full batches, or partial ones every 100ms, to batches
+func (w *Writer) marshalWorker(ctx context.Context, docChan <-chan model.Document, batches chan<- []interface{}) error {
+	batch := make([]interface{}, 0, w.batchSize)
+	var batchBytes int64
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	send := func() error {
+		start := time.Now()
+		select {
+		case <-ctx.Done():
+			atomic.AddInt64(&w.docsAbandoned, int64(len(batch)))
+			return ctx.Err()
+		case batches <- batch:
+		}
+		atomic.AddInt64(&w.pipeline.blockedNanos, int64(time.Since(start)))
+		batch, batchBytes = make([]interface{}, 0, w.batchSize), 0
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			atomic.AddInt64(&w.docsAbandoned, int64(len(batch)))
+			return ctx.Err()
+
+		case doc, ok := <-docChan:
+			if !ok {
+				if len(batch) > 0 {
+					return send()
+				}
+				return nil
+			}
+			start := time.Now()
+			raw, err := bson.Marshal(doc)
+			if err != nil {
+				return fmt.Errorf("failed to marshal document: %w", err)
+			}
+			atomic.AddInt64(&w.pipeline.marshalNanos, int64(time.Since(start)))
+			atomic.AddInt64(&w.pipeline.docs, 1)
+			atomic.AddInt64(&w.pipeline.bytes, int64(len(raw)))
+			if w.overflows(len(batch), batchBytes, int64(len(raw))) {
+				if err := send(); err != nil {
+					return err
+				}
+			}
+			batch = append(batch, bson.Raw(raw))
+			batchBytes += int64(len(raw))
+			if w.full(len(batch), batchBytes) {
+				if err := send(); err != nil {
+					return err
+				}
+			}
+
+		case <-ticker.C:
+			if len(batch) > 0 {
+				if err := send(); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Stop once the writers have claimed the rest of the target
+		if w.budget.exhausted() {
+			atomic.AddInt64(&w.docsDiscarded, int64(len(batch)))
+			return nil
+		}
+	}
+}
+
+// insertWorker inserts the marshaled batches into collection until batches
+// is closed
+func (w *Writer) insertWorker(ctx context.Context, collection *mongo.Collection, batches <-chan []interface{}) error {
+	for {
+		start := time.Now()
+		var batch []interface{}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case b, ok := <-batches:
+			if !ok {
+				return nil
+			}
+			batch = b
+		}
+		atomic.AddInt64(&w.pipeline.starvedNanos, int64(time.Since(start)))
+
+		start = time.Now()
+		err := w.flushBatchTo(ctx, collection, batch)
+		atomic.AddInt64(&w.pipeline.insertNanos, int64(time.Since(start)))
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// documentID returns the _id of a generated or marshaled document
+func documentID(doc interface{}) (primitive.ObjectID, bool) {
+	switch d := doc.(type) {
+	case model.Document:
+		return d.DocumentID(), true
+	case bson.Raw:
+		return d.Lookup("_id").ObjectIDOK()
+	}
+	return primitive.ObjectID{}, false
+}
+
+// documentSize returns the BSON size of a document, marshaling it unless
+// it already is
+func documentSize(doc interface{}) (int64, error) {
+	if raw, ok := doc.(bson.Raw); ok {
+		return int64(len(raw)), nil
+	}
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal document: %w", err)
+	}
+	return int64(len(data)), nil
+}
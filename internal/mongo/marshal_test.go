@@ -0,0 +1,68 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMarshalWorkerBatches(t *testing.T) {
+	w := &Writer{batchSize: 3, budget: newByteBudget(0), pipeline: newMarshalPipeline(1)}
+	generated := ledgerBatch(t, 7)
+	docChan := make(chan model.Document, len(generated))
+	for _, doc := range generated {
+		docChan <- doc.(model.Document)
+	}
+	close(docChan)
+
+	batches := make(chan []interface{}, 3)
+	if err := w.marshalWorker(context.Background(), docChan, batches); err != nil {
+		t.Fatal(err)
+	}
+	close(batches)
+
+	var sizes []int
+	var marshaled []interface{}
+	for batch := range batches {
+		sizes = append(sizes, len(batch))
+		marshaled = append(marshaled, batch...)
+	}
+	if len(sizes) != 3 || sizes[0] != 3 || sizes[2] != 1 {
+		t.Fatalf("Unexpected batch sizes %v", sizes)
+	}
+	var bytes int64
+	for i, doc := range marshaled {
+		if _, ok := doc.(bson.Raw); !ok {
+			t.Fatalf("Expected marshaled documents, got %T", doc)
+		}
+		id, ok := documentID(doc)
+		if want, _ := documentID(generated[i]); !ok || id != want {
+			t.Errorf("Document %d has _id %v, want %v", i, id, want)
+		}
+		size, _ := documentSize(doc)
+		if want, _ := documentSize(generated[i]); size != want {
+			t.Errorf("Document %d has size %d, want %d", i, size, want)
+		}
+		bytes += size
+	}
+	if stats := w.pipeline.stats(); stats.DocumentsMarshaled != 7 || stats.BytesMarshaled != bytes {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestNewWriterRejectsMarshalWorkers(t *testing.T) {
+	configs := []Config{
+		{MarshalWorkers: -1},
+		{MarshalWorkers: 2, Clients: 10},
+		{MarshalWorkers: 2, DuplicateRatio: 0.1},
+		{MarshalWorkers: 2, OrdersCollection: "orders"},
+	}
+	// Settings are validated before connecting
+	for _, config := range configs {
+		if _, err := NewWriter(config); err == nil {
+			t.Errorf("Expected error for %+v", config)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RunMetadataCollection is the collection (in the target database) that
+// stores one metadata document per generator run
+const RunMetadataCollection = "gendata_runs"
+
+// RunMetadata records what a generator run produced, so that later runs can
+// benchmark, verify, or clean up the data without regenerating it
+type RunMetadata struct {
+	RunID            string             `bson:"_id" json:"run_id"`
+	Collection       string             `bson:"collection" json:"collection"`
+	OrdersCollection string             `bson:"orders_collection,omitempty" json:"orders_collection,omitempty"`
+	Namespaces       *NamespaceConfig   `bson:"namespaces,omitempty" json:"namespaces,omitempty"` // Collections the documents were spread over instead of Collection
+	Schema           model.Schema       `bson:"schema" json:"schema"`
+	DocumentSize     model.DocumentSize `bson:"document_size" json:"document_size"`
+	KeySpace         model.KeySpace     `bson:"key_space" json:"key_space"`
+	TargetBytes      int64              `bson:"target_bytes" json:"target_bytes"`
+	DocumentsWritten int64              `bson:"documents_written" json:"documents_written"`
+	BytesWritten     int64              `bson:"bytes_written" json:"bytes_written"`
+	StartedAt        time.Time          `bson:"started_at" json:"started_at"`
+	FinishedAt       *time.Time         `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+	Aborted          string             `bson:"aborted,omitempty" json:"aborted,omitempty"` // Reason the run terminated abnormally
+	Tagged           bool               `bson:"tagged,omitempty" json:"tagged,omitempty"`   // Documents carry metadata.run_id (orders: run_id)
+}
+
+// NewRunID returns a sortable identifier for a new run: its start time,
+// followed by the qualifiers that tell apart runs started in the same second,
+// such as the names of pipelines loaded together
+func NewRunID(qualifiers ...string) string {
+	return strings.Join(append([]string{time.Now().UTC().Format("20060102-150405")}, qualifiers...), "-")
+}
+
+// Connect creates a client for phases that don't write generated documents
+// (read workloads, verification) and verifies the connection
+func Connect(connectionString string, poolSize int) (*mongo.Client, error) {
+	client, err := connect(connectionString, uint64(poolSize), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
+		return nil, fmt.Errorf("failed to ping MongoDB: %w", err)
+	}
+
+	return client, nil
+}
+
+// SaveRunMetadata upserts the metadata document for a run
+func SaveRunMetadata(ctx context.Context, db *mongo.Database, meta *RunMetadata) error {
+	_, err := db.Collection(RunMetadataCollection).ReplaceOne(ctx,
+		bson.D{{Key: "_id", Value: meta.RunID}},
+		meta,
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save run metadata: %w", err)
+	}
+	return nil
+}
+
+// LoadRunMetadata returns the metadata of run runID into collection, or of
+// the most recent run if runID is empty or "latest"
+func LoadRunMetadata(ctx context.Context, db *mongo.Database, collection, runID string) (*RunMetadata, error) {
+	filter := bson.D{{Key: "collection", Value: collection}}
+	if runID != "" && runID != "latest" {
+		filter = append(filter, bson.E{Key: "_id", Value: runID})
+	}
+
+	var meta RunMetadata
+	err := db.Collection(RunMetadataCollection).FindOne(ctx, filter,
+		options.FindOne().SetSort(bson.D{{Key: "started_at", Value: -1}}),
+	).Decode(&meta)
+	if err == mongo.ErrNoDocuments {
+		if len(filter) > 1 {
+			return nil, fmt.Errorf("no run %s found for collection %s.%s", runID, db.Name(), collection)
+		}
+		return nil, fmt.Errorf("no run metadata found for collection %s.%s", db.Name(), collection)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load run metadata: %w", err)
+	}
+	return &meta, nil
+}
+
+// SaveRunMetadata upserts the run metadata into the writer's database,
+// filling in the current write statistics
+func (w *Writer) SaveRunMetadata(meta *RunMetadata) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	meta.Collection = w.collectionName
+	meta.OrdersCollection = w.ordersCollectionName
+	if w.namespaces != nil {
+		meta.Namespaces = &w.namespaces.config
+	}
+	meta.DocumentsWritten = atomic.LoadInt64(&w.docsWritten)
+	meta.BytesWritten = atomic.LoadInt64(&w.bytesWritten)
+	return SaveRunMetadata(ctx, w.collection.Database(), meta)
+}
+
+// EnsureKeyIndex creates an index on the schema's business key field, which
+// operations targeting a run's key space filter on
+func EnsureKeyIndex(ctx context.Context, collection *mongo.Collection, keyField string) error {
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: keyField, Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create %s index on %s: %w", keyField, collection.Name(), err)
+	}
+	return nil
+}
+
+// EnsureTextIndex creates a text index on fields, which text searches
+// query. A collection has at most one text index, so an existing text index
+// on other fields is an error.
+func EnsureTextIndex(ctx context.Context, collection *mongo.Collection, fields []string) error {
+	keys := bson.D{}
+	for _, field := range fields {
+		keys = append(keys, bson.E{Key: field, Value: "text"})
+	}
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    keys,
+		Options: options.Index().SetName("gendata_text"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create text index on %s: %w", collection.Name(), err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package mongo
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewRunID(t *testing.T) {
+	if _, err := time.Parse("20060102-150405", NewRunID()); err != nil {
+		t.Errorf("Unexpected run ID: %v", err)
+	}
+
+	// Pipelines started in the same second get run IDs of their own
+	storefront, catalog := NewRunID("storefront"), NewRunID("catalog")
+	if storefront == catalog {
+		t.Fatalf("Pipelines share the run ID %s", storefront)
+	}
+	for id, name := range map[string]string{storefront: "storefront", catalog: "catalog"} {
+		stamp, ok := strings.CutSuffix(id, "-"+name)
+		if !ok {
+			t.Errorf("Run ID %s does not name pipeline %s", id, name)
+			continue
+		}
+		if _, err := time.Parse("20060102-150405", stamp); err != nil {
+			t.Errorf("Run ID %s does not start with its time: %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Distributions of insert batches over the collections of a NamespaceConfig
+const (
+	NamespaceRoundRobin = "round-robin" // Every collection in turn
+	NamespaceZipfian    = "zipfian"     // Few hot collections, a long tail of cold ones
+)
+
+// namespaceZipfS is the exponent of the Zipfian distribution: of 10,000
+// collections, the first receives about 15% of the batches
+const namespaceZipfS = 1.1
+
+// NamespaceConfig spreads insert batches over many collections, to grow the
+// catalog the way multi-tenant applications do
+type NamespaceConfig struct {
+	Count        int    `bson:"count" json:"count"`                                   // Collections (0 = the target collection only)
+	Template     string `bson:"template" json:"template"`                             // Collection name, with {n} replaced by the collection number from 0
+	Distribution string `bson:"distribution,omitempty" json:"distribution,omitempty"` // NamespaceRoundRobin (default) or NamespaceZipfian
+}
+
+// Enabled reports whether batches are spread over collections
+func (c NamespaceConfig) Enabled() bool {
+	return c.Count > 0
+}
+
+// Names returns the names of the collections, in order
+func (c NamespaceConfig) Names() []string {
+	names := make([]string, c.Count)
+	for n := range names {
+		names[n] = strings.ReplaceAll(c.Template, "{n}", strconv.Itoa(n))
+	}
+	return names
+}
+
+// validate checks the template and distribution
+func (c NamespaceConfig) validate() error {
+	if c.Count < 0 {
+		return fmt.Errorf("collection count must not be negative: %d", c.Count)
+	}
+	if !c.Enabled() {
+		return nil
+	}
+	if !strings.Contains(c.Template, "{n}") {
+		return fmt.Errorf("collection template %q has no {n}", c.Template)
+	}
+	switch c.Distribution {
+	case "", NamespaceRoundRobin, NamespaceZipfian:
+		return nil
+	}
+	return fmt.Errorf("invalid collection distribution: %s (use %s or %s)", c.Distribution, NamespaceRoundRobin, NamespaceZipfian)
+}
+
+// namespaceSpread picks the collection of each insert batch; a nil
+// *namespaceSpread leaves batches in the target collection
+type namespaceSpread struct {
+	names []string
+	next  uint64 // Round-robin position
+
+	mu   sync.Mutex
+	zipf *rand.Zipf // nil = round-robin
+
+	documents []int64 // Written per collection
+
+	config NamespaceConfig // Recorded in the run metadata
+}
+
+// newNamespaceSpread returns the spread of config, or nil if it is disabled
+func newNamespaceSpread(config NamespaceConfig) *namespaceSpread {
+	if !config.Enabled() {
+		return nil
+	}
+	s := &namespaceSpread{
+		config:    config,
+		names:     config.Names(),
+		documents: make([]int64, config.Count),
+	}
+	if config.Distribution == NamespaceZipfian && config.Count > 1 {
+		rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+		s.zipf = rand.NewZipf(rng, namespaceZipfS, 1, uint64(config.Count-1))
+	}
+	return s
+}
+
+// pick returns the index of the collection of the next batch
+func (s *namespaceSpread) pick() int {
+	if s.zipf == nil {
+		return int((atomic.AddUint64(&s.next, 1) - 1) % uint64(len(s.names)))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int(s.zipf.Uint64())
+}
+
+// collection returns the collection of the next batch, in the database of
+// collection, and its index
+func (s *namespaceSpread) collection(collection *mongo.Collection) (*mongo.Collection, int) {
+	n := s.pick()
+	return collection.Database().Collection(s.names[n]), n
+}
+
+// record counts documents written to collection n
+func (s *namespaceSpread) record(n int, documents int) {
+	if s == nil {
+		return
+	}
+	atomic.AddInt64(&s.documents[n], int64(documents))
+}
+
+// create creates every collection with opts, skipping existing ones
+func (s *namespaceSpread) create(database *mongo.Database, opts *options.CreateCollectionOptions) error {
+	log.Printf("Creating %d collections (%s ... %s)", len(s.names), s.names[0], s.names[len(s.names)-1])
+	for n, name := range s.names {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err := database.CreateCollection(ctx, name, opts)
+		cancel()
+		if err != nil && !isNamespaceExists(err) {
+			return fmt.Errorf("failed to create collection %s: %w", name, err)
+		}
+		if (n+1)%1000 == 0 {
+			log.Printf("Created %d of %d collections", n+1, len(s.names))
+		}
+	}
+	return nil
+}
+
+// isNamespaceExists reports whether creating a collection failed because it
+// already exists
+func isNamespaceExists(err error) bool {
+	return strings.Contains(err.Error(), "already exists") || strings.Contains(err.Error(), "NamespaceExists")
+}
+
+// SpreadStats describes how the written documents spread over the
+// collections of a NamespaceConfig
+type SpreadStats struct {
+	Collections  int     `json:"collections"`
+	Written      int     `json:"written"`       // Collections holding at least one document
+	HottestName  string  `json:"hottest_name"`  // Collection holding the most documents
+	HottestShare float64 `json:"hottest_share"` // Its fraction of the documents
+}
+
+// stats returns the spread of the documents written so far
+func (s *namespaceSpread) stats() SpreadStats {
+	if s == nil {
+		return SpreadStats{}
+	}
+	stats := SpreadStats{Collections: len(s.names)}
+	var total, hottest int64
+	for n := range s.documents {
+		documents := atomic.LoadInt64(&s.documents[n])
+		if documents > 0 {
+			stats.Written++
+		}
+		if documents > hottest {
+			hottest = documents
+			stats.HottestName = s.names[n]
+		}
+		total += documents
+	}
+	if total > 0 {
+		stats.HottestShare = float64(hottest) / float64(total)
+	}
+	return stats
+}
@@ -0,0 +1,67 @@
+package mongo
+
+import "testing"
+
+func TestNamespaceConfigValidation(t *testing.T) {
+	for _, config := range []NamespaceConfig{
+		{Count: -1},
+		{Count: 10, Template: "coll"},
+		{Count: 10, Template: "coll_{n}", Distribution: "uniform"},
+	} {
+		if config.validate() == nil {
+			t.Errorf("Expected %+v to be invalid", config)
+		}
+	}
+	if err := (NamespaceConfig{Count: 10, Template: "tenant_{n}_orders"}).validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if newNamespaceSpread(NamespaceConfig{}) != nil {
+		t.Error("Expected no spread without a collection count")
+	}
+}
+
+func TestNamespaceRoundRobin(t *testing.T) {
+	s := newNamespaceSpread(NamespaceConfig{Count: 3, Template: "tenant_{n}_orders"})
+	if s.names[2] != "tenant_2_orders" {
+		t.Errorf("Unexpected collection name %s", s.names[2])
+	}
+	for i := 0; i < 6; i++ {
+		n := s.pick()
+		if n != i%3 {
+			t.Fatalf("Pick %d returned collection %d, want %d", i, n, i%3)
+		}
+		s.record(n, 10)
+	}
+	if stats := s.stats(); stats.Collections != 3 || stats.Written != 3 || stats.HottestShare > 0.34 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestNamespaceZipfian(t *testing.T) {
+	s := newNamespaceSpread(NamespaceConfig{Count: 1000, Template: "coll_{n}", Distribution: NamespaceZipfian})
+	for i := 0; i < 10000; i++ {
+		s.record(s.pick(), 1)
+	}
+	stats := s.stats()
+	if stats.HottestName != "coll_0" || stats.HottestShare < 0.1 {
+		t.Errorf("Expected coll_0 to be hot, got %+v", stats)
+	}
+	if stats.Written < 100 || stats.Written == 1000 {
+		t.Errorf("Expected a long tail of cold collections, got %d of 1000 written", stats.Written)
+	}
+}
+
+func TestSpreadCollections(t *testing.T) {
+	runs := []RunMetadata{
+		{RunID: "a", Namespaces: &NamespaceConfig{Count: 2, Template: "coll_{n}"}},
+		{RunID: "b"},
+		{RunID: "c", Namespaces: &NamespaceConfig{Count: 3, Template: "coll_{n}"}},
+	}
+	names := SpreadCollections(runs)
+	if len(names) != 3 || names[0] != "coll_0" || names[2] != "coll_2" {
+		t.Errorf("SpreadCollections() = %v", names)
+	}
+	if names := SpreadCollections(runs[1:2]); names != nil {
+		t.Errorf("Unexpected spread collections %v", names)
+	}
+}
@@ -0,0 +1,189 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OplogStats describes how close the secondaries of a replica set are to
+// falling off the primary's oplog
+type OplogStats struct {
+	Window time.Duration // Time between the first and last oplog entries
+	Lag    time.Duration // Replication lag of the slowest secondary
+	MaxLag time.Duration // Highest lag observed during the load
+}
+
+// Headroom returns how much further the slowest secondary can fall behind
+// before it needs entries the oplog no longer holds
+func (s OplogStats) Headroom() time.Duration {
+	return s.Window - s.Lag
+}
+
+// oplogLagLimit returns the lag at which inserts are paused: maxLag, or half
+// the oplog window if that is smaller, so secondaries keep headroom
+func oplogLagLimit(maxLag, window time.Duration) time.Duration {
+	if window > 0 && window/2 < maxLag {
+		return window / 2
+	}
+	return maxLag
+}
+
+// replicationLag returns how far the slowest secondary is behind the
+// primary, from the members of replSetGetStatus
+func replicationLag(members []replSetMember) time.Duration {
+	var primary time.Time
+	var slowest time.Time
+	for _, m := range members {
+		switch m.State {
+		case "PRIMARY":
+			primary = m.OptimeDate
+		case "SECONDARY":
+			if slowest.IsZero() || m.OptimeDate.Before(slowest) {
+				slowest = m.OptimeDate
+			}
+		}
+	}
+	if primary.IsZero() || slowest.IsZero() || !slowest.Before(primary) {
+		return 0
+	}
+	return primary.Sub(slowest)
+}
+
+// replSetMember is a member in the output of replSetGetStatus
+type replSetMember struct {
+	State      string    `bson:"stateStr"`
+	OptimeDate time.Time `bson:"optimeDate"`
+}
+
+// ReadOplogStats returns the oplog window of the primary and the replication
+// lag of the slowest secondary. It requires a replica set connection.
+func ReadOplogStats(ctx context.Context, client *mongo.Client) (OplogStats, error) {
+	var status struct {
+		Members []replSetMember `bson:"members"`
+	}
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "replSetGetStatus", Value: 1}}).Decode(&status); err != nil {
+		return OplogStats{}, fmt.Errorf("failed to read replica set status: %w", err)
+	}
+
+	oplog := client.Database("local").Collection("oplog.rs")
+	var first, last struct {
+		TS primitive.Timestamp `bson:"ts"`
+	}
+	opts := options.FindOne().SetProjection(bson.D{{Key: "ts", Value: 1}})
+	if err := oplog.FindOne(ctx, bson.D{}, opts.SetSort(bson.D{{Key: "$natural", Value: 1}})).Decode(&first); err != nil {
+		return OplogStats{}, fmt.Errorf("failed to read oplog: %w", err)
+	}
+	if err := oplog.FindOne(ctx, bson.D{}, opts.SetSort(bson.D{{Key: "$natural", Value: -1}})).Decode(&last); err != nil {
+		return OplogStats{}, fmt.Errorf("failed to read oplog: %w", err)
+	}
+
+	return OplogStats{
+		Window: time.Duration(last.TS.T-first.TS.T) * time.Second,
+		Lag:    replicationLag(status.Members),
+	}, nil
+}
+
+// isReplicaSet reports whether client is connected to a replica set member
+// rather than mongos or a standalone server
+func isReplicaSet(ctx context.Context, client *mongo.Client) (bool, error) {
+	var hello struct {
+		SetName string `bson:"setName"`
+	}
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return false, fmt.Errorf("failed to run hello: %w", err)
+	}
+	return hello.SetName != "", nil
+}
+
+// oplogWatch holds the last oplog stats polled by StartOplogWatch
+type oplogWatch struct {
+	mu    sync.Mutex
+	stats *OplogStats
+}
+
+// StartOplogWatch polls the oplog window and replication lag every interval
+// and reports them in Stats.Oplog. With maxLag set, it pauses inserts while
+// the slowest secondary is further behind than maxLag, or half the oplog
+// window, and resumes them once it caught up to half that limit or the lag
+// can't be read. It stops after the first poll if the writer is not
+// connected to a replica set.
+func (w *Writer) StartOplogWatch(ctx context.Context, interval, maxLag time.Duration) {
+	go func() {
+		defer w.gate.resume("oplog")
+
+		setupCtx, cancel := context.WithTimeout(ctx, interval)
+		ok, err := isReplicaSet(setupCtx, w.client)
+		cancel()
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Warning: oplog watch disabled: %v", err)
+			}
+			return
+		}
+		if !ok {
+			log.Printf("Warning: oplog watch disabled, it requires a replica set connection")
+			return
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			statsCtx, cancel := context.WithTimeout(ctx, interval)
+			stats, err := ReadOplogStats(statsCtx, w.client)
+			cancel()
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("Oplog poll failed: %v", err)
+				}
+				// Inserts are not held back on lag that can no longer be observed
+				if w.gate.resume("oplog") {
+					log.Printf("Resuming inserts")
+				}
+			} else {
+				w.oplog.mu.Lock()
+				if w.oplog.stats != nil && w.oplog.stats.MaxLag > stats.Lag {
+					stats.MaxLag = w.oplog.stats.MaxLag
+				} else {
+					stats.MaxLag = stats.Lag
+				}
+				w.oplog.stats = &stats
+				w.oplog.mu.Unlock()
+
+				if maxLag > 0 {
+					limit := oplogLagLimit(maxLag, stats.Window)
+					if stats.Lag > limit && w.gate.pause("oplog") {
+						log.Printf("Replication lag %v exceeds %v (oplog window %v), pausing inserts",
+							stats.Lag.Round(time.Second), limit.Round(time.Second), stats.Window.Round(time.Second))
+					} else if stats.Lag <= limit/2 && w.gate.resume("oplog") {
+						log.Printf("Replication lag down to %v, resuming inserts", stats.Lag.Round(time.Second))
+					}
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// last returns the last polled oplog stats (nil = none)
+func (o *oplogWatch) last() *OplogStats {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.stats == nil {
+		return nil
+	}
+	stats := *o.stats
+	return &stats
+}
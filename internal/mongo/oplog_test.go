@@ -0,0 +1,67 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestReplicationLag(t *testing.T) {
+	now := time.Now()
+	members := []replSetMember{
+		{State: "SECONDARY", OptimeDate: now.Add(-3 * time.Second)},
+		{State: "PRIMARY", OptimeDate: now},
+		{State: "SECONDARY", OptimeDate: now.Add(-40 * time.Second)},
+		{State: "ARBITER"},
+	}
+	if lag := replicationLag(members); lag != 40*time.Second {
+		t.Errorf("Expected 40s lag, got %v", lag)
+	}
+	if lag := replicationLag(members[1:2]); lag != 0 {
+		t.Errorf("Expected no lag without secondaries, got %v", lag)
+	}
+}
+
+func TestOplogLagLimit(t *testing.T) {
+	if limit := oplogLagLimit(time.Minute, 24*time.Hour); limit != time.Minute {
+		t.Errorf("Expected the maximum lag, got %v", limit)
+	}
+	if limit := oplogLagLimit(time.Hour, time.Hour); limit != 30*time.Minute {
+		t.Errorf("Expected half the oplog window, got %v", limit)
+	}
+}
+
+func TestWriteGate(t *testing.T) {
+	var g writeGate
+	if err := g.wait(context.Background()); err != nil {
+		t.Fatalf("Expected an open gate, got %v", err)
+	}
+	if !g.pause("oplog") || g.pause("oplog") || !g.pause("server") {
+		t.Fatal("Expected each source to pause once")
+	}
+
+	done := make(chan error)
+	go func() { done <- g.wait(context.Background()) }()
+	g.resume("oplog")
+	select {
+	case <-done:
+		t.Fatal("Expected batches to wait for every source to resume")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if !g.resume("server") || g.resume("server") {
+		t.Fatal("Expected each source to resume once")
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Expected the wait to end on resume, got %v", err)
+	}
+
+	g.pause("oplog")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := g.wait(ctx); err == nil {
+		t.Error("Expected the wait to end on cancellation")
+	}
+	if stats := g.stats(); !stats.Paused || stats.Pauses != 3 || stats.Wait <= 0 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,55 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ensureOrdersIndex creates the customer_id index used by $lookup from customers to orders
+func ensureOrdersIndex(ctx context.Context, orders *mongo.Collection) error {
+	_, err := orders.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "customer_id", Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create customer_id index on %s: %w", orders.Name(), err)
+	}
+	return nil
+}
+
+// writeOrders inserts the orders of a batch of customers that were just
+// written, so every order references a customer_id that exists
+func (w *Writer) writeOrders(ctx context.Context, orders *mongo.Collection, customers []interface{}) error {
+	var docs []interface{}
+	for _, doc := range customers {
+		if customer, ok := doc.(*model.CustomerDocument); ok {
+			docs = append(docs, model.OrderDocuments(customer)...)
+		}
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+
+	startTime := time.Now()
+	_, err := orders.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	latency := time.Since(startTime)
+
+	if w.ycsbLogger != nil {
+		avgLatencyPerDoc := latency / time.Duration(len(docs))
+		for i := 0; i < len(docs); i++ {
+			w.ycsbLogger.RecordOperation("INSERT_ORDER", avgLatencyPerDoc, err == nil)
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to insert orders: %w", err)
+	}
+	atomic.AddInt64(&w.ordersWritten, int64(len(docs)))
+	return nil
+}
@@ -0,0 +1,224 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver"
+)
+
+// oversizeExcess is how far injected oversized documents exceed
+// model.MaxDocumentSize. It keeps the insert command within the 16KB the
+// server allows commands beyond the limit, so that the insert itself, not
+// the command, is rejected.
+const oversizeExcess = 1024
+
+// oversizeInjector turns a fraction of the documents into jumbo documents;
+// a nil *oversizeInjector injects nothing
+type oversizeInjector struct {
+	ratio float64
+
+	mu     sync.Mutex
+	rng    *rand.Rand
+	errors map[string]int64 // By kind and error
+
+	oversized int64
+	accepted  int64
+	nearLimit int64
+}
+
+// newOversizeInjector returns an injector of ratio, or nil if ratio is 0
+func newOversizeInjector(ratio float64) *oversizeInjector {
+	if ratio <= 0 {
+		return nil
+	}
+	return &oversizeInjector{
+		ratio:  ratio,
+		rng:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		errors: make(map[string]int64),
+	}
+}
+
+// pick reports whether to inject a document, and whether it is to exceed
+// the limit rather than reach it
+func (o *oversizeInjector) pick() (inject, over bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.rng.Float64() >= o.ratio {
+		return false, false
+	}
+	return true, o.rng.Intn(2) == 0
+}
+
+// record counts an error of an injected document of kind
+func (o *oversizeInjector) record(kind, name string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.errors[kind+": "+name]++
+}
+
+// OversizeStats counts the injected jumbo documents
+type OversizeStats struct {
+	Oversized int64            // Documents over the BSON limit attempted
+	Accepted  int64            // Oversized documents the server stored instead of rejecting
+	NearLimit int64            // Documents of exactly the BSON limit written
+	Errors    map[string]int64 // Rejections and failures by kind and error
+}
+
+// stats returns the documents injected so far
+func (o *oversizeInjector) stats() OversizeStats {
+	if o == nil {
+		return OversizeStats{}
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	byError := make(map[string]int64, len(o.errors))
+	for name, count := range o.errors {
+		byError[name] = count
+	}
+	return OversizeStats{
+		Oversized: atomic.LoadInt64(&o.oversized),
+		Accepted:  atomic.LoadInt64(&o.accepted),
+		NearLimit: atomic.LoadInt64(&o.nearLimit),
+		Errors:    byError,
+	}
+}
+
+// injectOversize takes a fraction of the batch out and writes each of those
+// documents resized: half just over model.MaxDocumentSize, sent past the
+// driver's size check so that the server rejects them, and half at exactly
+// the limit. It returns the rest of the batch.
+func (w *Writer) injectOversize(ctx context.Context, collection *mongo.Collection, batch []interface{}) ([]interface{}, error) {
+	rest := make([]interface{}, 0, len(batch))
+	for _, doc := range batch {
+		generated, ok := doc.(model.Document)
+		inject, over := w.oversize.pick()
+		if !ok || !inject {
+			rest = append(rest, doc)
+			continue
+		}
+		var err error
+		if over {
+			err = w.insertOversized(ctx, collection, generated)
+		} else {
+			err = w.insertNearLimit(ctx, collection, generated)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rest, nil
+}
+
+// insertOversized sends doc, resized over the limit, in an insert command of
+// its own, which the driver does not check the size of
+func (w *Writer) insertOversized(ctx context.Context, collection *mongo.Collection, doc model.Document) error {
+	if err := model.Resize(doc, model.MaxDocumentSize+oversizeExcess); err != nil {
+		return err
+	}
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal document: %w", err)
+	}
+
+	var reply struct {
+		N           int64 `bson:"n"`
+		WriteErrors []struct {
+			Code int `bson:"code"`
+		} `bson:"writeErrors"`
+	}
+	startTime := time.Now()
+	err = collection.Database().RunCommand(ctx, bson.D{
+		{Key: "insert", Value: collection.Name()},
+		{Key: "documents", Value: bson.A{bson.Raw(data)}},
+	}).Decode(&reply)
+	latency := time.Since(startTime)
+	if err != nil && ctx.Err() != nil {
+		return fmt.Errorf("failed to insert oversized document: %w", err)
+	}
+
+	atomic.AddInt64(&w.oversize.oversized, 1)
+	switch {
+	case err != nil:
+		w.oversize.record("oversized", errorName(err))
+	case len(reply.WriteErrors) > 0:
+		w.oversize.record("oversized", codeName(reply.WriteErrors[0].Code))
+	case reply.N > 0:
+		atomic.AddInt64(&w.oversize.accepted, 1)
+	}
+	if w.ycsbLogger != nil {
+		w.ycsbLogger.RecordOperation("INSERT_OVERSIZE", latency, reply.N > 0)
+	}
+	return nil
+}
+
+// insertNearLimit inserts doc resized to exactly the limit, counting it
+// towards the target like any other document
+func (w *Writer) insertNearLimit(ctx context.Context, collection *mongo.Collection, doc model.Document) error {
+	if err := model.Resize(doc, model.MaxDocumentSize); err != nil {
+		return err
+	}
+	if !w.budget.claim(model.MaxDocumentSize) {
+		atomic.AddInt64(&w.docsDiscarded, 1)
+		return nil
+	}
+
+	startTime := time.Now()
+	err := w.insertWithRetry(ctx, func(ctx context.Context) error {
+		_, err := collection.InsertOne(ctx, doc)
+		return err
+	})
+	latency := time.Since(startTime)
+	if w.ycsbLogger != nil {
+		w.ycsbLogger.RecordOperation("INSERT_NEAR_LIMIT", latency, err == nil)
+	}
+	if err != nil {
+		w.budget.release(model.MaxDocumentSize)
+		if ctx.Err() != nil {
+			return fmt.Errorf("failed to insert near-limit document: %w", err)
+		}
+		w.oversize.record("near-limit", errorName(err))
+		return nil
+	}
+
+	atomic.AddInt64(&w.oversize.nearLimit, 1)
+	atomic.AddInt64(&w.docsWritten, 1)
+	atomic.AddInt64(&w.bytesWritten, model.MaxDocumentSize)
+	return nil
+}
+
+// errorName names the error an injected document failed with
+func errorName(err error) string {
+	var we mongo.WriteException
+	var ce mongo.CommandError
+	switch {
+	case errors.Is(err, driver.ErrDocumentTooLarge):
+		return "DocumentTooLarge (driver)"
+	case errors.As(err, &we) && len(we.WriteErrors) > 0:
+		return codeName(we.WriteErrors[0].Code)
+	case errors.As(err, &ce) && ce.Name != "":
+		return ce.Name
+	case isTimeout(err):
+		return "timeout"
+	}
+	return err.Error()
+}
+
+// codeName names the server error codes of documents over the limit
+func codeName(code int) string {
+	switch code {
+	case 2:
+		return "BadValue"
+	case 10334:
+		return "BSONObjectTooLarge"
+	}
+	return fmt.Sprintf("code %d", code)
+}
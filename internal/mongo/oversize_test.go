@@ -0,0 +1,51 @@
+package mongo
+
+import (
+	"fmt"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver"
+)
+
+func TestOversizeErrorNames(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("insert: %w", driver.ErrDocumentTooLarge), "DocumentTooLarge (driver)"},
+		{mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 10334}}}, "BSONObjectTooLarge"},
+		{mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 2}}}, "BadValue"},
+		{mongo.CommandError{Code: 10334, Name: "BSONObjectTooLarge"}, "BSONObjectTooLarge"},
+		{mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 17280}}}, "code 17280"},
+	} {
+		if got := errorName(tc.err); got != tc.want {
+			t.Errorf("errorName(%v) = %q, want %q", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestOversizeInjectorRatio(t *testing.T) {
+	if newOversizeInjector(0) != nil {
+		t.Error("Expected no injector without a ratio")
+	}
+	var none *oversizeInjector
+	if stats := none.stats(); stats.Oversized != 0 || stats.Errors != nil {
+		t.Errorf("Unexpected stats of a disabled injector: %+v", stats)
+	}
+
+	o := newOversizeInjector(1)
+	var over int
+	for i := 0; i < 1000; i++ {
+		inject, isOver := o.pick()
+		if !inject {
+			t.Fatal("Ratio 1 should inject every document")
+		}
+		if isOver {
+			over++
+		}
+	}
+	if over < 400 || over > 600 {
+		t.Errorf("Expected about half of the documents over the limit, got %d of 1000", over)
+	}
+}
@@ -0,0 +1,95 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Connection modes for firehose writers
+const (
+	// SharedPool runs every writer on one client and connection pool
+	SharedPool = "shared"
+	// PerWriterPool gives each writer its own client and connection pool, so
+	// writers never wait on each other for a connection checkout
+	PerWriterPool = "per-writer"
+)
+
+// perWriterPoolSize is the default pool size of each writer's own client:
+// one connection for inserts and one for referenced orders
+const perWriterPoolSize = 2
+
+// poolStats accumulates connection checkout waits across the writer's clients
+type poolStats struct {
+	checkouts    int64
+	waitNanos    int64
+	maxWaitNanos int64
+}
+
+// PoolStats summarizes connection checkouts
+type PoolStats struct {
+	Checkouts int64
+	TotalWait time.Duration
+	MaxWait   time.Duration
+}
+
+// AverageWait returns the mean time a checkout waited for a connection
+func (s PoolStats) AverageWait() time.Duration {
+	if s.Checkouts == 0 {
+		return 0
+	}
+	return s.TotalWait / time.Duration(s.Checkouts)
+}
+
+// monitor returns a pool monitor that records checkout waits
+func (p *poolStats) monitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			if e.Type != event.GetSucceeded {
+				return
+			}
+			wait := int64(e.Duration)
+			atomic.AddInt64(&p.checkouts, 1)
+			atomic.AddInt64(&p.waitNanos, wait)
+			for {
+				max := atomic.LoadInt64(&p.maxWaitNanos)
+				if wait <= max || atomic.CompareAndSwapInt64(&p.maxWaitNanos, max, wait) {
+					break
+				}
+			}
+		},
+	}
+}
+
+// stats returns the checkout statistics so far
+func (p *poolStats) stats() PoolStats {
+	return PoolStats{
+		Checkouts: atomic.LoadInt64(&p.checkouts),
+		TotalWait: time.Duration(atomic.LoadInt64(&p.waitNanos)),
+		MaxWait:   time.Duration(atomic.LoadInt64(&p.maxWaitNanos)),
+	}
+}
+
+// writerCollection returns the collection a writer worker inserts into: the
+// shared insert collection, or one on a dedicated client in PerWriterPool
+// mode. The returned function releases the dedicated client.
+func (w *Writer) writerCollection(writerID int) (*mongo.Collection, func(), error) {
+	if w.connectionMode != PerWriterPool {
+		return w.inserts, func() {}, nil
+	}
+
+	client, err := connectMonitored(w.connectionString, w.maxPoolSize, w.minPoolSize, w.pool.monitor(), w.autoEncryption)
+	if err != nil {
+		return nil, nil, fmt.Errorf("writer %d: %w", writerID, err)
+	}
+	release := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		client.Disconnect(ctx)
+	}
+	return client.Database(w.databaseName).Collection(w.collectionName), release, nil
+}
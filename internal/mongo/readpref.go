@@ -0,0 +1,49 @@
+package mongo
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+// ParseReadPreference parses a read preference mode: primary,
+// primaryPreferred, secondary, secondaryPreferred, or nearest
+func ParseReadPreference(s string) (*readpref.ReadPref, error) {
+	mode, err := readpref.ModeFromString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid read preference %q: want primary, primaryPreferred, secondary, secondaryPreferred, or nearest", s)
+	}
+	return readpref.New(mode)
+}
+
+// MinMaxStaleness is the smallest max staleness servers accept
+const MinMaxStaleness = 90 * time.Second
+
+// SecondaryReadPreference returns the read preference of reads from a
+// secondary at most maxStaleness behind the primary (0 = any secondary)
+func SecondaryReadPreference(maxStaleness time.Duration) (*readpref.ReadPref, error) {
+	if maxStaleness == 0 {
+		return readpref.Secondary(), nil
+	}
+	if maxStaleness < MinMaxStaleness {
+		return nil, fmt.Errorf("max staleness %v is below the minimum of %v", maxStaleness, MinMaxStaleness)
+	}
+	return readpref.New(readpref.SecondaryMode, readpref.WithMaxStaleness(maxStaleness))
+}
+
+// WorkloadCollection returns the collection with the read preference of a
+// workload. Causally consistent workloads also read and write with majority
+// concern, without which sessions do not guarantee reading their own writes
+// from secondaries.
+func WorkloadCollection(db *mongo.Database, name string, pref *readpref.ReadPref, causal bool) *mongo.Collection {
+	opts := options.Collection().SetReadPreference(pref)
+	if causal {
+		opts.SetReadConcern(readconcern.Majority()).SetWriteConcern(writeconcern.Majority())
+	}
+	return db.Collection(name, opts)
+}
@@ -0,0 +1,41 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func TestParseReadPreference(t *testing.T) {
+	tests := map[string]readpref.Mode{
+		"primary":            readpref.PrimaryMode,
+		"secondaryPreferred": readpref.SecondaryPreferredMode,
+		"nearest":            readpref.NearestMode,
+	}
+	for in, want := range tests {
+		pref, err := ParseReadPreference(in)
+		if err != nil || pref.Mode() != want {
+			t.Errorf("ParseReadPreference(%q) = %v, %v, want %v", in, pref, err, want)
+		}
+	}
+	if _, err := ParseReadPreference("closest"); err == nil {
+		t.Error("Expected error for read preference closest")
+	}
+}
+
+func TestSecondaryReadPreference(t *testing.T) {
+	pref, err := SecondaryReadPreference(2 * time.Minute)
+	if err != nil || pref.Mode() != readpref.SecondaryMode {
+		t.Fatalf("SecondaryReadPreference(2m) = %v, %v", pref, err)
+	}
+	if staleness, ok := pref.MaxStaleness(); !ok || staleness != 2*time.Minute {
+		t.Errorf("Max staleness %v, %v", staleness, ok)
+	}
+	if pref, err := SecondaryReadPreference(0); err != nil || pref.Mode() != readpref.SecondaryMode {
+		t.Errorf("SecondaryReadPreference(0) = %v, %v", pref, err)
+	}
+	if _, err := SecondaryReadPreference(10 * time.Second); err == nil {
+		t.Error("Expected an error for a max staleness below 90s")
+	}
+}
@@ -0,0 +1,168 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// docState is what became of a document of an insert batch
+type docState uint8
+
+const (
+	docPending  docState = iota // Not known to be inserted yet
+	docInserted                 // Inserted by some attempt
+	docRejected                 // Rejected as a duplicate of another document
+)
+
+// batchLedger tracks the documents of an insert batch across its attempts.
+// An attempt that fails without reporting which documents it inserted, such
+// as a timeout or a network error, leaves the batch ambiguous: duplicate key
+// errors of later attempts then mean that the failed attempt inserted the
+// document, unless the document collides on purpose (DuplicateRatio). This
+// counts the written documents of retried batches exactly.
+type batchLedger struct {
+	ids        []primitive.ObjectID // _id of each document
+	collisions map[primitive.ObjectID]bool
+	state      []docState
+	ambiguous  bool
+
+	reconciled int64 // Documents found inserted by an ambiguous attempt
+}
+
+// newBatchLedger returns a ledger of batch with every document pending.
+// collisions are the _ids given to documents on purpose to collide.
+func newBatchLedger(batch []interface{}, collisions map[primitive.ObjectID]bool) *batchLedger {
+	l := &batchLedger{
+		ids:        make([]primitive.ObjectID, len(batch)),
+		collisions: collisions,
+		state:      make([]docState, len(batch)),
+	}
+	for i, doc := range batch {
+		if id, ok := documentID(doc); ok {
+			l.ids[i] = id
+		}
+	}
+	return l
+}
+
+// settle records the outcome err of an attempt that sent the documents at
+// sent (nil = the whole batch), in order if ordered
+func (l *batchLedger) settle(err error, sent []int, ordered bool) {
+	index := func(i int) int {
+		if sent == nil {
+			return i
+		}
+		return sent[i]
+	}
+	count := len(l.state)
+	if sent != nil {
+		count = len(sent)
+	}
+
+	var bwe mongo.BulkWriteException
+	if err != nil && (!errors.As(err, &bwe) || bwe.WriteConcernError != nil) {
+		l.ambiguous = true
+		return
+	}
+
+	// Ordered attempts stop at their first error
+	attempted := count
+	failed := make(map[int]bool, len(bwe.WriteErrors))
+	for _, we := range bwe.WriteErrors {
+		if we.Index < 0 || we.Index >= count {
+			continue
+		}
+		failed[we.Index] = true
+		if ordered {
+			attempted = min(attempted, we.Index)
+		}
+		i := index(we.Index)
+		if we.Code != 11000 || l.state[i] != docPending {
+			continue
+		}
+		if l.ambiguous && !l.collisions[l.ids[i]] {
+			l.state[i] = docInserted
+			l.reconciled++
+		} else {
+			l.state[i] = docRejected
+		}
+	}
+	for j := 0; j < attempted; j++ {
+		if i := index(j); !failed[j] && l.state[i] == docPending {
+			l.state[i] = docInserted
+		}
+	}
+}
+
+// pending returns the documents of batch not known to be inserted or
+// rejected, and their indexes
+func (l *batchLedger) pending(batch []interface{}) ([]interface{}, []int) {
+	var docs []interface{}
+	var indexes []int
+	for i, state := range l.state {
+		if state == docPending {
+			docs = append(docs, batch[i])
+			indexes = append(indexes, i)
+		}
+	}
+	return docs, indexes
+}
+
+// count returns the number of documents in state
+func (l *batchLedger) count(state docState) int {
+	n := 0
+	for _, s := range l.state {
+		if s == state {
+			n++
+		}
+	}
+	return n
+}
+
+// resolve looks up which pending documents an ambiguous attempt inserted.
+// Intentional collisions are left pending, as their _ids exist anyway.
+func (l *batchLedger) resolve(ctx context.Context, collection *mongo.Collection) error {
+	var ids []primitive.ObjectID
+	for i, state := range l.state {
+		if state == docPending && !l.ids[i].IsZero() && !l.collisions[l.ids[i]] {
+			ids = append(ids, l.ids[i])
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	cursor, err := collection.Find(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}},
+		options.Find().SetProjection(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return fmt.Errorf("failed to look up inserted documents: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	found := make(map[primitive.ObjectID]bool, len(ids))
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return fmt.Errorf("failed to look up inserted documents: %w", err)
+		}
+		found[doc.ID] = true
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("failed to look up inserted documents: %w", err)
+	}
+	for i, state := range l.state {
+		if state == docPending && found[l.ids[i]] {
+			l.state[i] = docInserted
+			l.reconciled++
+		}
+	}
+	return nil
+}
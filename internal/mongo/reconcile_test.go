@@ -0,0 +1,87 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ledgerBatch returns a batch of n generated documents
+func ledgerBatch(t *testing.T, n int) []interface{} {
+	gen := model.NewGeneratorWithOptions(model.Size2KB, model.Options{Template: model.TemplateTelemetry})
+	batch := make([]interface{}, n)
+	for i := range batch {
+		doc, err := gen.GenerateDocument()
+		if err != nil {
+			t.Fatal(err)
+		}
+		batch[i] = doc
+	}
+	return batch
+}
+
+// writeErrors returns a bulk write exception of the given codes by index
+func writeErrors(codes map[int]int) error {
+	var bwe mongo.BulkWriteException
+	for index, code := range codes {
+		bwe.WriteErrors = append(bwe.WriteErrors, mongo.BulkWriteError{WriteError: mongo.WriteError{Index: index, Code: code}})
+	}
+	return bwe
+}
+
+func TestLedgerReconcilesRetries(t *testing.T) {
+	batch := ledgerBatch(t, 4)
+	collision := batch[3].(model.Document).DocumentID()
+	l := newBatchLedger(batch, map[primitive.ObjectID]bool{collision: true})
+
+	// A timeout tells nothing, so the retry's duplicate key errors mean the
+	// timed out attempt inserted those documents, except the collision
+	l.settle(errInjectedReset, nil, false)
+	if !l.ambiguous || l.count(docPending) != 4 {
+		t.Fatalf("Expected an ambiguous batch with every document pending, got %v", l.state)
+	}
+	l.settle(writeErrors(map[int]int{0: 11000, 1: 11000, 3: 11000}), nil, false)
+	if got := l.count(docInserted); got != 3 || l.reconciled != 2 {
+		t.Errorf("Expected 3 inserted documents, 2 of them reconciled, got %d and %d", got, l.reconciled)
+	}
+	if l.state[3] != docRejected {
+		t.Errorf("Expected the intentional collision to be rejected, got %v", l.state)
+	}
+}
+
+func TestLedgerDuplicatesWithoutAmbiguity(t *testing.T) {
+	batch := ledgerBatch(t, 3)
+	l := newBatchLedger(batch, nil)
+
+	// Without an ambiguous attempt, duplicate key errors are real rejections,
+	// and documents inserted earlier stay inserted
+	l.settle(writeErrors(map[int]int{1: 11000, 2: 91}), nil, false)
+	l.settle(writeErrors(map[int]int{0: 11000, 1: 11000}), nil, false)
+	if l.state[0] != docInserted || l.state[1] != docRejected || l.state[2] != docInserted || l.reconciled != 0 {
+		t.Errorf("Unexpected states %v, %d reconciled", l.state, l.reconciled)
+	}
+}
+
+func TestLedgerOrderedSubset(t *testing.T) {
+	batch := ledgerBatch(t, 5)
+	l := newBatchLedger(batch, nil)
+
+	// An ordered attempt of documents 1, 3, and 4 stopped at document 3
+	l.settle(writeErrors(map[int]int{1: 91}), []int{1, 3, 4}, true)
+	if l.state[1] != docInserted || l.state[3] != docPending || l.state[4] != docPending || l.state[0] != docPending {
+		t.Errorf("Unexpected states %v", l.state)
+	}
+	docs, indexes := l.pending(batch)
+	if len(docs) != 4 || indexes[1] != 2 {
+		t.Errorf("Unexpected pending documents %v", indexes)
+	}
+}
+
+func TestRebaseWriteErrors(t *testing.T) {
+	err := rebaseWriteErrors(writeErrors(map[int]int{2: 11000}), 3)
+	if indexes, ok := duplicateKeyIndexes(err); !ok || len(indexes) != 1 || indexes[0] != 5 {
+		t.Errorf("Expected the error at index 5, got %v", indexes)
+	}
+}
@@ -0,0 +1,96 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxRetryBackoff caps the exponential backoff between retries
+const maxRetryBackoff = 10 * time.Second
+
+// isRetryable reports whether a failed insert is worth retrying: network
+// errors, timeouts, and errors the server labels as retryable
+func isRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if mongo.IsNetworkError(err) || isTimeout(err) {
+		return true
+	}
+
+	var labeled mongo.LabeledError
+	if errors.As(err, &labeled) {
+		return labeled.HasErrorLabel("RetryableWriteError") || labeled.HasErrorLabel("TransientTransactionError")
+	}
+	return false
+}
+
+// isDuplicateKeyOnly reports whether every write error is a duplicate key
+// error. After a retry this means an earlier attempt already inserted those
+// documents, so the batch as a whole succeeded.
+func isDuplicateKeyOnly(err error) bool {
+	var bwe mongo.BulkWriteException
+	if !errors.As(err, &bwe) || bwe.WriteConcernError != nil || len(bwe.WriteErrors) == 0 {
+		return false
+	}
+	for _, we := range bwe.WriteErrors {
+		if we.Code != 11000 {
+			return false
+		}
+	}
+	return true
+}
+
+// retryBackoff returns the exponential backoff before the given retry attempt
+func (w *Writer) retryBackoff(attempt int) time.Duration {
+	backoff := w.retryBackoffBase << uint(attempt)
+	if backoff <= 0 || backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	return backoff
+}
+
+// insertWithRetry runs insert, retrying retryable failures with exponential
+// backoff. Time spent in failed attempts and backoff is accounted as retry
+// overhead; the successful attempt is accounted as productive work.
+func (w *Writer) insertWithRetry(ctx context.Context, insert func(ctx context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		attemptStart := time.Now()
+		insertCtx, cancel := withTimeout(ctx, w.insertTimeout)
+		err := insert(insertCtx)
+		cancel()
+		attemptTime := time.Since(attemptStart)
+
+		if attempt > 0 && isDuplicateKeyOnly(err) {
+			err = nil
+		}
+		if err == nil {
+			atomic.AddInt64(&w.productiveNanos, int64(attemptTime))
+			return nil
+		}
+		if attempt >= w.maxRetries || !isRetryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		backoff := w.retryBackoff(attempt)
+		atomic.AddInt64(&w.retries, 1)
+		atomic.AddInt64(&w.retryNanos, int64(attemptTime+backoff))
+		if !sleepContext(ctx, backoff) {
+			return err
+		}
+	}
+}
+
+// RetryOverheadPercent returns the share of insert time spent in failed
+// attempts and backoff rather than productive work
+func (s Stats) RetryOverheadPercent() float64 {
+	total := s.RetryTime + s.ProductiveTime
+	if total <= 0 {
+		return 0
+	}
+	return float64(s.RetryTime) / float64(total) * 100
+}
@@ -0,0 +1,98 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// searchIndexPollInterval is how often EnsureSearchIndex checks whether the
+// index has been built
+const searchIndexPollInterval = 5 * time.Second
+
+// ReadSearchIndex reads an Atlas Search index definition (e.g.
+// {"mappings": {"dynamic": true}}) from a JSON file
+func ReadSearchIndex(path string) (bson.D, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read search index definition: %w", err)
+	}
+	var definition bson.D
+	if err := bson.UnmarshalExtJSON(data, false, &definition); err != nil {
+		return nil, fmt.Errorf("failed to parse search index definition %s: %w", path, err)
+	}
+	return definition, nil
+}
+
+// EnsureSearchIndex creates the Atlas Search index name with definition, or
+// updates an existing index of that name, and waits until it is queryable.
+// progress, if set, is called with the index status on every poll.
+func EnsureSearchIndex(ctx context.Context, collection *mongo.Collection, name string, definition bson.D, progress func(status string)) error {
+	indexes := collection.SearchIndexes()
+	status, _, err := searchIndexStatus(ctx, collection, name)
+	if err != nil {
+		return err
+	}
+	if status == "" {
+		_, err = indexes.CreateOne(ctx, mongo.SearchIndexModel{
+			Definition: definition,
+			Options:    options.SearchIndexes().SetName(name),
+		})
+	} else {
+		err = indexes.UpdateOne(ctx, name, definition)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create search index %s on %s: %w", name, collection.Name(), err)
+	}
+
+	ticker := time.NewTicker(searchIndexPollInterval)
+	defer ticker.Stop()
+	for {
+		status, queryable, err := searchIndexStatus(ctx, collection, name)
+		if err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(status)
+		}
+		switch {
+		case status == "FAILED":
+			return fmt.Errorf("search index %s on %s failed to build", name, collection.Name())
+		case queryable && status == "READY":
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// searchIndexStatus returns the status of the Atlas Search index name ("" =
+// no such index) and whether it can be queried
+func searchIndexStatus(ctx context.Context, collection *mongo.Collection, name string) (string, bool, error) {
+	cursor, err := collection.SearchIndexes().List(ctx, options.SearchIndexes().SetName(name))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to list search indexes of %s: %w", collection.Name(), err)
+	}
+	defer cursor.Close(ctx)
+
+	var index struct {
+		Status    string `bson:"status"`
+		Queryable bool   `bson:"queryable"`
+	}
+	if !cursor.Next(ctx) {
+		return "", false, cursor.Err()
+	}
+	if err := cursor.Decode(&index); err != nil {
+		return "", false, fmt.Errorf("failed to decode search index %s: %w", name, err)
+	}
+	return index.Status, index.Queryable, nil
+}
@@ -0,0 +1,32 @@
+package mongo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadSearchIndex(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "search.json")
+	if err := os.WriteFile(path, []byte(`{"mappings": {"dynamic": false, "fields": {"notes": {"type": "string"}}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	definition, err := ReadSearchIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(definition) != 1 || definition[0].Key != "mappings" {
+		t.Errorf("Unexpected definition %v", definition)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"mappings":`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadSearchIndex(path); err == nil {
+		t.Error("Expected an error for an invalid definition")
+	}
+	if _, err := ReadSearchIndex(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
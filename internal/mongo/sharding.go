@@ -0,0 +1,186 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ShardKey is a single-field shard key, ranged or hashed
+type ShardKey struct {
+	Field  string
+	Hashed bool
+}
+
+// ParseShardKey parses "field" (ranged) or "field:hashed" ("" = none)
+func ParseShardKey(s string) (ShardKey, error) {
+	field, kind, hasKind := strings.Cut(strings.TrimSpace(s), ":")
+	if field == "" {
+		if s != "" {
+			return ShardKey{}, fmt.Errorf("invalid shard key %q: want field or field:hashed", s)
+		}
+		return ShardKey{}, nil
+	}
+	if hasKind && kind != "hashed" {
+		return ShardKey{}, fmt.Errorf("invalid shard key %q: want field or field:hashed", s)
+	}
+	return ShardKey{Field: field, Hashed: hasKind}, nil
+}
+
+// IsZero reports whether no shard key was given
+func (k ShardKey) IsZero() bool {
+	return k.Field == ""
+}
+
+// String formats the key as ParseShardKey accepts it
+func (k ShardKey) String() string {
+	if k.Hashed {
+		return k.Field + ":hashed"
+	}
+	return k.Field
+}
+
+// keyPattern returns the key as shardCollection expects it
+func (k ShardKey) keyPattern() bson.D {
+	if k.Hashed {
+		return bson.D{{Key: k.Field, Value: "hashed"}}
+	}
+	return bson.D{{Key: k.Field, Value: 1}}
+}
+
+// ShardCollection shards collection on key, unless it is sharded already
+func ShardCollection(ctx context.Context, collection *mongo.Collection, key ShardKey) error {
+	admin := collection.Database().Client().Database("admin")
+
+	// Needed before MongoDB 6.0, and accepted since
+	err := admin.RunCommand(ctx, bson.D{{Key: "enableSharding", Value: collection.Database().Name()}}).Err()
+	if err != nil && !strings.Contains(err.Error(), "already enabled") {
+		return fmt.Errorf("failed to enable sharding on %s: %w", collection.Database().Name(), err)
+	}
+
+	err = admin.RunCommand(ctx, bson.D{
+		{Key: "shardCollection", Value: namespace(collection)},
+		{Key: "key", Value: key.keyPattern()},
+	}).Err()
+	if err != nil && !strings.Contains(err.Error(), "already sharded") {
+		return fmt.Errorf("failed to shard %s on %s: %w", namespace(collection), key, err)
+	}
+	return nil
+}
+
+// PresplitChunks splits the empty collection, sharded on key, into chunks
+// of an even share of the key range and distributes them across the shards
+// round-robin, so a load does not wait for the balancer to migrate them.
+// Ranged keys must be the template's UUID key field.
+func PresplitChunks(ctx context.Context, collection *mongo.Collection, key ShardKey, chunks int) error {
+	ns := namespace(collection)
+	if n, err := collection.EstimatedDocumentCount(ctx); err != nil {
+		return fmt.Errorf("failed to count documents in %s: %w", ns, err)
+	} else if n > 0 {
+		return fmt.Errorf("cannot pre-split %s: collection is not empty", ns)
+	}
+	shards, err := listShards(ctx, collection.Database().Client())
+	if err != nil {
+		return err
+	}
+
+	admin := collection.Database().Client().Database("admin")
+	points := splitPoints(key, chunks)
+	for _, point := range points {
+		err := admin.RunCommand(ctx, bson.D{
+			{Key: "split", Value: ns},
+			{Key: "middle", Value: bson.D{{Key: key.Field, Value: point}}},
+		}).Err()
+		// Hashed collections start out with chunks of their own
+		if err != nil && !strings.Contains(err.Error(), "boundary") {
+			return fmt.Errorf("failed to split %s at %v: %w", ns, point, err)
+		}
+	}
+
+	bounds := append(append([]interface{}{primitive.MinKey{}}, points...), primitive.MaxKey{})
+	for i := 0; i+1 < len(bounds); i++ {
+		err := admin.RunCommand(ctx, bson.D{
+			{Key: "moveChunk", Value: ns},
+			{Key: "bounds", Value: bson.A{
+				bson.D{{Key: key.Field, Value: bounds[i]}},
+				bson.D{{Key: key.Field, Value: bounds[i+1]}},
+			}},
+			{Key: "to", Value: shards[i%len(shards)]},
+		}).Err()
+		if err != nil && !strings.Contains(err.Error(), "already") {
+			return fmt.Errorf("failed to move chunk %d of %s to %s: %w", i, ns, shards[i%len(shards)], err)
+		}
+	}
+	return nil
+}
+
+// splitPoints returns the chunks-1 key values that divide the key range
+// evenly: 64-bit hash values for hashed keys, or the leading hex digits of
+// UUID keys
+func splitPoints(key ShardKey, chunks int) []interface{} {
+	points := make([]interface{}, 0, chunks)
+	for i := 1; i < chunks; i++ {
+		if key.Hashed {
+			step := math.MaxUint64 / uint64(chunks)
+			points = append(points, int64(uint64(i)*step-1<<63))
+		} else {
+			points = append(points, fmt.Sprintf("%08x", uint64(i)<<32/uint64(chunks)))
+		}
+	}
+	return points
+}
+
+// listShards returns the IDs of the cluster's shards
+func listShards(ctx context.Context, client *mongo.Client) ([]string, error) {
+	var result struct {
+		Shards []struct {
+			ID string `bson:"_id"`
+		} `bson:"shards"`
+	}
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "listShards", Value: 1}}).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to list shards: %w", err)
+	}
+	if len(result.Shards) == 0 {
+		return nil, fmt.Errorf("failed to list shards: cluster has no shards")
+	}
+	shards := make([]string, len(result.Shards))
+	for i, shard := range result.Shards {
+		shards[i] = shard.ID
+	}
+	return shards, nil
+}
+
+// PauseBalancer stops the balancer if it is running. The returned function
+// starts it again; it does nothing if the balancer was already stopped.
+func PauseBalancer(ctx context.Context, client *mongo.Client) (func(ctx context.Context) error, error) {
+	admin := client.Database("admin")
+	var status struct {
+		Mode string `bson:"mode"`
+	}
+	if err := admin.RunCommand(ctx, bson.D{{Key: "balancerStatus", Value: 1}}).Decode(&status); err != nil {
+		return nil, fmt.Errorf("failed to read balancer status: %w", err)
+	}
+	if status.Mode == "off" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	if err := admin.RunCommand(ctx, bson.D{{Key: "balancerStop", Value: 1}}).Err(); err != nil {
+		return nil, fmt.Errorf("failed to stop balancer: %w", err)
+	}
+	return func(ctx context.Context) error {
+		if err := admin.RunCommand(ctx, bson.D{{Key: "balancerStart", Value: 1}}).Err(); err != nil {
+			return fmt.Errorf("failed to restart balancer: %w", err)
+		}
+		return nil
+	}, nil
+}
+
+// namespace returns the collection's database.collection name
+func namespace(collection *mongo.Collection) string {
+	return collection.Database().Name() + "." + collection.Name()
+}
@@ -0,0 +1,68 @@
+package mongo
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+func TestParseShardKey(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ShardKey
+	}{
+		{"", ShardKey{}},
+		{"customer_id", ShardKey{Field: "customer_id"}},
+		{"_id:hashed", ShardKey{Field: "_id", Hashed: true}},
+	}
+	for _, tt := range tests {
+		got, err := ParseShardKey(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseShardKey(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+		if got.String() != tt.in {
+			t.Errorf("String() = %q, want %q", got.String(), tt.in)
+		}
+	}
+	for _, invalid := range []string{":hashed", "customer_id:ranged"} {
+		if _, err := ParseShardKey(invalid); err == nil {
+			t.Errorf("Expected error for shard key %q", invalid)
+		}
+	}
+}
+
+func TestSplitPointsRanged(t *testing.T) {
+	points := splitPoints(ShardKey{Field: "customer_id"}, 4)
+	want := []string{"40000000", "80000000", "c0000000"}
+	if len(points) != len(want) {
+		t.Fatalf("Got %d split points, want %d", len(points), len(want))
+	}
+	for i, p := range points {
+		if p != want[i] {
+			t.Errorf("Split point %d = %v, want %s", i, p, want[i])
+		}
+	}
+	if len(splitPoints(ShardKey{Field: "customer_id"}, 1)) != 0 {
+		t.Error("A single chunk should have no split points")
+	}
+}
+
+func TestSplitPointsHashed(t *testing.T) {
+	points := splitPoints(ShardKey{Field: "_id", Hashed: true}, 4)
+	if len(points) != 3 {
+		t.Fatalf("Got %d split points, want 3", len(points))
+	}
+	values := make([]int64, len(points))
+	for i, p := range points {
+		values[i] = p.(int64)
+	}
+	if !sort.SliceIsSorted(values, func(i, j int) bool { return values[i] < values[j] }) {
+		t.Errorf("Split points are not ascending: %v", values)
+	}
+	if values[1] > 4 || values[1] < -4 {
+		t.Errorf("Middle split point = %d, want about 0", values[1])
+	}
+	if values[0] > math.MinInt64/2+4 || values[0] < math.MinInt64/2-4 {
+		t.Errorf("First split point = %d, want about %d", values[0], int64(math.MinInt64/2))
+	}
+}
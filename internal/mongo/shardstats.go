@@ -0,0 +1,105 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ShardStats is the share of the collection one shard holds
+type ShardStats struct {
+	Shard              string  `json:"shard"`
+	Documents          int64   `json:"documents"`
+	Bytes              int64   `json:"bytes"`                // Uncompressed data size
+	DocumentsPerSecond float64 `json:"documents_per_second"` // Since the previous poll
+}
+
+// ShardDistribution returns the documents and data size of the collection
+// on each shard, ordered by shard. It returns nil for unsharded collections.
+func (w *Writer) ShardDistribution(ctx context.Context) ([]ShardStats, error) {
+	cursor, err := w.collection.Aggregate(ctx, bson.A{
+		bson.D{{Key: "$collStats", Value: bson.D{{Key: "storageStats", Value: bson.D{}}}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read collection stats: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var shards []ShardStats
+	for cursor.Next(ctx) {
+		var doc struct {
+			Shard        string `bson:"shard"`
+			StorageStats struct {
+				Count float64 `bson:"count"`
+				Size  float64 `bson:"size"`
+			} `bson:"storageStats"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to decode collection stats: %w", err)
+		}
+		if doc.Shard == "" {
+			return nil, nil
+		}
+		shards = append(shards, ShardStats{
+			Shard:     doc.Shard,
+			Documents: int64(doc.StorageStats.Count),
+			Bytes:     int64(doc.StorageStats.Size),
+		})
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read collection stats: %w", err)
+	}
+	sort.Slice(shards, func(i, j int) bool { return shards[i].Shard < shards[j].Shard })
+	return shards, nil
+}
+
+// StartShardWatch polls the collection's distribution across shards every
+// interval and reports the last one, with insert rates, in Stats.Shards. It
+// stops after the first poll if the collection is not sharded.
+func (w *Writer) StartShardWatch(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		previous := make(map[string]int64)
+		last := time.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			statsCtx, cancel := context.WithTimeout(ctx, interval)
+			shards, err := w.ShardDistribution(statsCtx)
+			cancel()
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("Shard distribution poll failed: %v", err)
+				}
+				continue
+			}
+			if shards == nil {
+				return
+			}
+
+			now := time.Now()
+			elapsed := now.Sub(last).Seconds()
+			for i := range shards {
+				if before, ok := previous[shards[i].Shard]; ok && elapsed > 0 {
+					shards[i].DocumentsPerSecond = float64(shards[i].Documents-before) / elapsed
+				}
+				previous[shards[i].Shard] = shards[i].Documents
+			}
+			last = now
+
+			w.mu.Lock()
+			w.shards = shards
+			w.mu.Unlock()
+		}
+	}()
+}
@@ -0,0 +1,123 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Size metrics that decide when a load reached its target
+const (
+	// SizeBytes counts the marshaled BSON bytes the writers inserted
+	SizeBytes = "bytes"
+	// SizeData is the uncompressed data size reported by the server
+	SizeData = "data-size"
+	// SizeStorage is the on-disk (compressed) size of the collection
+	SizeStorage = "storage-size"
+	// SizeTotal is the on-disk size of the collection and its indexes
+	SizeTotal = "total-size"
+)
+
+// ValidSizeMetric reports whether metric is one of the size metrics
+func ValidSizeMetric(metric string) bool {
+	switch metric {
+	case SizeBytes, SizeData, SizeStorage, SizeTotal:
+		return true
+	}
+	return false
+}
+
+// storageStats holds the fields of $collStats storageStats the size metrics
+// use. Sharded collections report one document per shard.
+type storageStats struct {
+	Size           float64 `bson:"size"`
+	StorageSize    float64 `bson:"storageSize"`
+	TotalIndexSize float64 `bson:"totalIndexSize"`
+}
+
+// value returns the size of metric
+func (s storageStats) value(metric string) int64 {
+	switch metric {
+	case SizeData:
+		return int64(s.Size)
+	case SizeStorage:
+		return int64(s.StorageSize)
+	case SizeTotal:
+		return int64(s.StorageSize + s.TotalIndexSize)
+	}
+	return 0
+}
+
+// CollectionSize returns the server-reported size of the collection for a
+// server-side metric, summed over all shards
+func (w *Writer) CollectionSize(ctx context.Context, metric string) (int64, error) {
+	cursor, err := w.collection.Aggregate(ctx, bson.A{
+		bson.D{{Key: "$collStats", Value: bson.D{{Key: "storageStats", Value: bson.D{}}}}},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read collection stats: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var total int64
+	for cursor.Next(ctx) {
+		var doc struct {
+			StorageStats storageStats `bson:"storageStats"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return 0, fmt.Errorf("failed to decode collection stats: %w", err)
+		}
+		total += doc.StorageStats.value(metric)
+	}
+	if err := cursor.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read collection stats: %w", err)
+	}
+	return total, nil
+}
+
+// RefreshCollectionSize reads the collection size for metric and records it
+// in Stats.CollectionSize
+func (w *Writer) RefreshCollectionSize(ctx context.Context, metric string) (int64, error) {
+	size, err := w.CollectionSize(ctx, metric)
+	if err != nil {
+		return 0, err
+	}
+	atomic.StoreInt64(&w.collectionSize, size)
+	return size, nil
+}
+
+// StartSizeWatch polls the collection's server-reported size every interval
+// and calls reached once it is at least target bytes. The last observed size
+// is reported in Stats.CollectionSize.
+func (w *Writer) StartSizeWatch(ctx context.Context, metric string, target int64, interval time.Duration, reached func()) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			statsCtx, cancel := context.WithTimeout(ctx, interval)
+			size, err := w.RefreshCollectionSize(statsCtx, metric)
+			cancel()
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("Size poll failed: %v", err)
+				}
+				continue
+			}
+			if size >= target {
+				reached()
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,33 @@
+package mongo
+
+import "testing"
+
+func TestStorageStatsValue(t *testing.T) {
+	stats := storageStats{Size: 4000, StorageSize: 1500, TotalIndexSize: 500}
+
+	tests := []struct {
+		metric string
+		want   int64
+	}{
+		{SizeData, 4000},
+		{SizeStorage, 1500},
+		{SizeTotal, 2000},
+		{SizeBytes, 0},
+	}
+	for _, tt := range tests {
+		if got := stats.value(tt.metric); got != tt.want {
+			t.Errorf("value(%q) = %d, want %d", tt.metric, got, tt.want)
+		}
+	}
+}
+
+func TestValidSizeMetric(t *testing.T) {
+	for _, metric := range []string{SizeBytes, SizeData, SizeStorage, SizeTotal} {
+		if !ValidSizeMetric(metric) {
+			t.Errorf("ValidSizeMetric(%q) = false", metric)
+		}
+	}
+	if ValidSizeMetric("disk") {
+		t.Error("ValidSizeMetric(\"disk\") = true")
+	}
+}
@@ -0,0 +1,150 @@
+package mongo
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// writeGate holds back insert batches while the cluster needs to catch up.
+// Each watch pauses and resumes inserts, or delays every batch, under its own
+// name. Batches wait while any watch has paused and then for the longest delay.
+type writeGate struct {
+	mu      sync.Mutex
+	paused  map[string]bool
+	resumed chan struct{} // Closed once the last pause ends
+	delays  map[string]time.Duration
+
+	pauses    int64
+	backoffs  int64
+	waitNanos int64
+}
+
+// pause holds back batches for source. It reports whether source was not
+// paused already.
+func (g *writeGate) pause(source string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.paused[source] {
+		return false
+	}
+	if len(g.paused) == 0 {
+		if g.paused == nil {
+			g.paused = make(map[string]bool)
+		}
+		g.resumed = make(chan struct{})
+	}
+	g.paused[source] = true
+	atomic.AddInt64(&g.pauses, 1)
+	return true
+}
+
+// resume ends the pause of source. It reports whether source was paused.
+func (g *writeGate) resume(source string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.paused[source] {
+		return false
+	}
+	delete(g.paused, source)
+	if len(g.paused) == 0 {
+		close(g.resumed)
+	}
+	return true
+}
+
+// setDelay delays every batch by delay for source (0 = no delay)
+func (g *writeGate) setDelay(source string, delay time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if delay <= 0 {
+		delete(g.delays, source)
+		return
+	}
+	if g.delays == nil {
+		g.delays = make(map[string]time.Duration)
+	}
+	if g.delays[source] == 0 {
+		atomic.AddInt64(&g.backoffs, 1)
+	}
+	g.delays[source] = delay
+}
+
+// state returns whether batches are held back and the longest delay
+func (g *writeGate) state() (resumed <-chan struct{}, delay time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, d := range g.delays {
+		delay = max(delay, d)
+	}
+	if len(g.paused) > 0 {
+		return g.resumed, delay
+	}
+	return nil, delay
+}
+
+// wait blocks while batches are held back and then for the longest delay,
+// or until ctx is done
+func (g *writeGate) wait(ctx context.Context) error {
+	resumed, delay := g.state()
+	if resumed == nil && delay == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	defer func() { atomic.AddInt64(&g.waitNanos, int64(time.Since(start))) }()
+	if resumed != nil {
+		select {
+		case <-resumed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		// The delays may have changed during the pause
+		_, delay = g.state()
+	}
+	if delay > 0 && !sleepContext(ctx, delay) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// ThrottleStats describes how long inserts were held back by watches of the
+// cluster's health
+type ThrottleStats struct {
+	Paused   bool          // Inserts are held back right now
+	Delay    time.Duration // Current delay of every batch
+	Pauses   int64         // Times a watch paused inserts
+	Backoffs int64         // Times a watch started delaying batches
+	Wait     time.Duration // Writer time spent waiting, summed over writers
+}
+
+// stats returns the current throttling and the waiting time so far
+func (g *writeGate) stats() ThrottleStats {
+	resumed, delay := g.state()
+	return ThrottleStats{
+		Paused:   resumed != nil,
+		Delay:    delay,
+		Pauses:   atomic.LoadInt64(&g.pauses),
+		Backoffs: atomic.LoadInt64(&g.backoffs),
+		Wait:     time.Duration(atomic.LoadInt64(&g.waitNanos)),
+	}
+}
+
+// followProfile holds back a batch of n documents until the load profile has
+// its first document due, or until ctx is done
+func (w *Writer) followProfile(ctx context.Context, n int) error {
+	if w.profile == nil {
+		return nil
+	}
+	first := float64(atomic.AddInt64(&w.profiled, int64(n)) - int64(n))
+	for {
+		wait := w.profile.Delay(time.Since(w.profileStart), first)
+		if wait <= 0 {
+			return nil
+		}
+		if !sleepContext(ctx, wait) {
+			return ctx.Err()
+		}
+	}
+}
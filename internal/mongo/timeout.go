@@ -0,0 +1,22 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// isTimeout reports whether err means an operation deadline expired, either
+// client-side (context deadline) or server-side (maxTimeMS)
+func isTimeout(err error) bool {
+	return err != nil && mongo.IsTimeout(err)
+}
+
+// withTimeout returns a context bounded by timeout, or ctx itself when timeout is 0
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
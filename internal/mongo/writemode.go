@@ -0,0 +1,111 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Write modes: the driver API insert batches are sent with
+const (
+	// WriteInsertMany sends each batch with one insertMany call
+	WriteInsertMany = "insertMany"
+	// WriteBulkWrite sends each batch as a bulkWrite of insertOne models
+	WriteBulkWrite = "bulkWrite"
+	// WriteInsertOne sends the documents of each batch one insertOne at a time
+	WriteInsertOne = "insertOne"
+)
+
+// ValidWriteMode reports whether mode is one of the write modes
+func ValidWriteMode(mode string) bool {
+	switch mode {
+	case WriteInsertMany, WriteBulkWrite, WriteInsertOne:
+		return true
+	}
+	return false
+}
+
+// insertBatch inserts batch with the writer's write mode. Ordered inserts
+// stop at the first error, so when resuming after a failed attempt, duplicate
+// key errors mean that attempt already inserted the document and the rest of
+// the batch is sent again.
+func (w *Writer) insertBatch(ctx context.Context, collection *mongo.Collection, batch []interface{}, resume bool) error {
+	if !w.ordered || !resume {
+		return w.insertWithMode(ctx, collection, batch)
+	}
+	for offset := 0; ; {
+		err := w.insertWithMode(ctx, collection, batch[offset:])
+		indexes, ok := duplicateKeyIndexes(err)
+		if !ok || len(indexes) != 1 {
+			return rebaseWriteErrors(err, offset)
+		}
+		offset += indexes[0] + 1
+		if offset >= len(batch) {
+			return nil
+		}
+	}
+}
+
+// rebaseWriteErrors shifts the write error indexes of err, an error of the
+// documents from offset on, to index the whole batch
+func rebaseWriteErrors(err error, offset int) error {
+	var bwe mongo.BulkWriteException
+	if offset == 0 || !errors.As(err, &bwe) {
+		return err
+	}
+	writeErrors := make([]mongo.BulkWriteError, len(bwe.WriteErrors))
+	for i, we := range bwe.WriteErrors {
+		we.Index += offset
+		writeErrors[i] = we
+	}
+	bwe.WriteErrors = writeErrors
+	return bwe
+}
+
+// insertWithMode sends batch with one call of the writer's write mode
+func (w *Writer) insertWithMode(ctx context.Context, collection *mongo.Collection, batch []interface{}) error {
+	switch w.writeMode {
+	case WriteBulkWrite:
+		models := make([]mongo.WriteModel, len(batch))
+		for i, doc := range batch {
+			models[i] = mongo.NewInsertOneModel().SetDocument(doc)
+		}
+		_, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(w.ordered))
+		return err
+
+	case WriteInsertOne:
+		return w.insertEach(ctx, collection, batch)
+	}
+	_, err := collection.InsertMany(ctx, batch, options.InsertMany().SetOrdered(w.ordered))
+	return err
+}
+
+// insertEach inserts the documents of batch one at a time. Write errors are
+// collected into a BulkWriteException, as insertMany reports them, so the
+// batch's error handling does not depend on the write mode.
+func (w *Writer) insertEach(ctx context.Context, collection *mongo.Collection, batch []interface{}) error {
+	var bwe mongo.BulkWriteException
+	for i, doc := range batch {
+		_, err := collection.InsertOne(ctx, doc)
+		if err == nil {
+			continue
+		}
+		var we mongo.WriteException
+		if !errors.As(err, &we) || we.WriteConcernError != nil || len(we.WriteErrors) == 0 {
+			return err
+		}
+		for _, e := range we.WriteErrors {
+			e.Index = i
+			bwe.WriteErrors = append(bwe.WriteErrors, mongo.BulkWriteError{WriteError: e})
+		}
+		if w.ordered {
+			break
+		}
+	}
+	if len(bwe.WriteErrors) > 0 {
+		return bwe
+	}
+	return nil
+}
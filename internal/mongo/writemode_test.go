@@ -0,0 +1,29 @@
+package mongo
+
+import "testing"
+
+func TestValidWriteMode(t *testing.T) {
+	for _, mode := range []string{WriteInsertMany, WriteBulkWrite, WriteInsertOne} {
+		if !ValidWriteMode(mode) {
+			t.Errorf("Expected %q to be a valid write mode", mode)
+		}
+	}
+	for _, mode := range []string{"", "insertmany", "updateOne"} {
+		if ValidWriteMode(mode) {
+			t.Errorf("Expected %q to be an invalid write mode", mode)
+		}
+	}
+}
+
+func TestNewWriterRejectsWriteModes(t *testing.T) {
+	configs := []Config{
+		{WriteMode: "replaceOne"},
+		{Ordered: true, DuplicateRatio: 0.1},
+	}
+	// Settings are validated before connecting
+	for _, config := range configs {
+		if _, err := NewWriter(config); err == nil {
+			t.Errorf("Expected error for %+v", config)
+		}
+	}
+}
@@ -3,17 +3,20 @@ package mongo
 import (
 	"context"
 	"fmt"
-	"strings"
+	"log"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/meticulous-dft/mongodb-data-generator/internal/logger"
-	"github.com/meticulous-dft/mongodb-data-generator/internal/model"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/pacing"
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"github.com/meticulous-dft/mongodb-data-generator/sink"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
-	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -22,13 +25,103 @@ type Writer struct {
 	client       *mongo.Client
 	collection   *mongo.Collection
 	batchSize    int
+	batchBytes   int64 // Byte bound of a batch (0 = count only)
 	writerCount  int
-	targetBytes  int64
+	budget       *byteBudget
 	bytesWritten int64
 	docsWritten  int64
+	batches      int64 // Insert batches whose bytes were claimed
 	mu           sync.RWMutex
 	startTime    time.Time
 	ycsbLogger   *logger.YCSBLogger
+
+	// Simulated client application settings
+	connectionString string
+	databaseName     string
+	collectionName   string
+	clients          int
+	clientBatchSize  int
+	thinkTime        pacing.ThinkTime
+
+	// Referenced orders collection (empty = orders stay embedded only)
+	ordersCollectionName string
+	ordersWritten        int64
+
+	// Documents inserted small and grown by updates (0 steps = off)
+	growthSteps   int
+	growthFields  []string
+	growthUpdates int64
+
+	// Send times of inserts awaiting their change notification
+	tailChangeStream bool
+	pendingInserts   sync.Map
+
+	insertTimeout time.Duration
+	timeouts      int64
+
+	// Retry settings and overhead accounting
+	maxRetries       int
+	retryBackoffBase time.Duration
+	retries          int64
+	retryNanos       int64
+	productiveNanos  int64
+	exactlyOnce      bool
+	docsReconciled   int64
+
+	// Intentional duplicate _id collisions
+	duplicateRatio     float64
+	duplicateMode      string
+	duplicates         *duplicateTracker
+	duplicatesRejected int64
+	upserts            int64
+
+	chaos    *chaos            // Client-side fault injection, nil when disabled
+	oversize *oversizeInjector // Jumbo document injection, nil when disabled
+
+	namespaces *namespaceSpread // Collections batches are spread over, nil for the target collection only
+
+	keys *keySample // Business keys of written documents, nil when not sampled
+
+	pipeline *marshalPipeline // Marshal worker pool feeding the writers, nil to marshal on the insert path
+
+	// Calibration bursts before the load (nil = none) and their outcome
+	autoTune   *AutoTuneConfig
+	tuneResult *TuneResult
+
+	// Driver API and ordering of insert batches
+	writeMode string
+	ordered   bool
+
+	directShards map[string]string // Connection strings by shard ID, for direct shard writes
+
+	// Automatic encryption of inserts (nil = none). Inserts go through
+	// encryptedClient instead of client when it is enabled.
+	autoEncryption  *options.AutoEncryptionOptions
+	encryptedClient *mongo.Client
+	inserts         *mongo.Collection // Target collection on the client inserts go through
+
+	// Connection pooling of the firehose writers
+	connectionMode string
+	maxPoolSize    uint64
+	minPoolSize    uint64
+	pool           poolStats
+
+	gate  writeGate  // Holds back inserts while the cluster catches up
+	oplog oplogWatch // Last oplog window and replication lag (StartOplogWatch)
+
+	// Load profile the insert rate follows from profileStart
+	profile      *pacing.LoadProfile
+	profileStart time.Time
+	profiled     int64 // Documents released by the profile
+
+	collectionSize int64        // Last server-reported size polled by StartSizeWatch
+	shards         []ShardStats // Last distribution polled by StartShardWatch
+	docsDiscarded  int64        // Documents dropped because the target was claimed
+	docsAbandoned  int64        // Documents in batches or inserts cut off by cancellation
+
+	// Background churn deletes (StartChurn)
+	docsDeleted  int64
+	bytesDeleted int64 // Estimated from the average document size
 }
 
 // Config holds writer configuration
@@ -40,6 +133,109 @@ type Config struct {
 	WriterCount      int
 	TargetBytes      int64
 	YCSBLogger       *logger.YCSBLogger
+
+	// BatchBytes, when set, also ends a batch before its documents' BSON size
+	// would exceed this many bytes, at most MaxBatchBytes, so each batch fits
+	// one message instead of being split by the driver
+	BatchBytes int64
+
+	// Clients switches the writer to the simulated client application model:
+	// each logical client gets its own connection and session and issues
+	// small inserts separated by ThinkTime (zero = firehose writers)
+	Clients         int
+	ClientBatchSize int
+	ThinkTime       pacing.ThinkTime
+
+	// OrdersCollection, when set, also writes each customer's orders as
+	// standalone documents referencing customer_id, after the customer exists
+	OrdersCollection string
+
+	// GrowthSteps, when set, inserts each document with the arrays among
+	// GrowthFields empty and no padding, then grows it to full size with up to
+	// this many updates, see model.GrowthPlan. Byte targets count full sizes.
+	GrowthSteps  int
+	GrowthFields []string
+
+	// InsertTimeout bounds each insert batch (0 = no deadline); timeouts are
+	// counted separately from other errors
+	InsertTimeout time.Duration
+
+	// MaxRetries retries inserts that fail with network errors, timeouts, or
+	// retryable errors, backing off exponentially from RetryBackoff
+	MaxRetries   int
+	RetryBackoff time.Duration
+
+	// ExactlyOnce looks up which documents of a batch an attempt that failed
+	// ambiguously, such as by timing out, inserted, and retries only the rest
+	ExactlyOnce bool
+
+	// DuplicateRatio reuses the _id of an already written document for this
+	// fraction of writes, issued as plain inserts (DuplicateInsert, expecting
+	// duplicate key errors) or upserts (DuplicateUpsert)
+	DuplicateRatio float64
+	DuplicateMode  string
+
+	// Chaos injects client-side faults into insert batches for resilience testing
+	Chaos ChaosConfig
+
+	// Namespaces spreads insert batches over many collections created like
+	// the target collection
+	Namespaces NamespaceConfig
+
+	// AutoTune, when set, runs calibration bursts with different writer counts
+	// and batch sizes before the load and continues with the best of them,
+	// overriding WriterCount and BatchSize
+	AutoTune *AutoTuneConfig
+
+	// Profile, when set, holds back insert batches so that the documents
+	// inserted per second follow the load profile over the load
+	Profile *pacing.LoadProfile
+
+	// MarshalWorkers, when set, serializes documents to BSON in a pool of this
+	// many workers that feed the writers marshaled batches, so serialization
+	// overlaps with insert round trips (0 = each writer marshals its batches)
+	MarshalWorkers int
+
+	// OversizeRatio is the fraction (0-1) of documents written as jumbo
+	// documents instead: half just over model.MaxDocumentSize, which the
+	// server rejects, and half at exactly the limit
+	OversizeRatio float64
+
+	// WriteMode is the driver API insert batches are sent with: WriteInsertMany
+	// (default), WriteBulkWrite, or WriteInsertOne. Ordered stops each batch at
+	// its first error instead of attempting every document.
+	WriteMode string
+	Ordered   bool
+
+	// ConnectionMode is SharedPool (default) or PerWriterPool. MaxPoolSize and
+	// MinPoolSize size each client's pool (0 = 10x writers and writers for the
+	// shared pool, 2 and 0 for each writer's own pool).
+	ConnectionMode string
+	MaxPoolSize    int
+	MinPoolSize    int
+
+	// Clustered creates the collection clustered by _id (MongoDB 5.3+), if it
+	// does not exist yet
+	Clustered bool
+
+	// Collation is the default collation of the collection, if it does not
+	// exist yet (nil = simple binary comparison), see ParseCollation
+	Collation *options.Collation
+
+	// DirectShards, when set, writes every document directly to the replica
+	// set of the shard owning its chunk instead of through mongos. It maps
+	// shard IDs to their connection strings; WriterCount applies per shard.
+	// The balancer must stay stopped for the load, or chunks may move away.
+	DirectShards map[string]string
+
+	// Encryption, when set, encrypts fields of the inserted documents
+	// automatically, see EncryptionConfig
+	Encryption *EncryptionConfig
+
+	// KeySample, when set, keeps a random sample of this many KeyField values
+	// of the documents written, see WrittenKeys
+	KeyField  string
+	KeySample int
 }
 
 // NewWriter creates a new MongoDB writer
@@ -56,43 +252,155 @@ func NewWriter(config Config) (*Writer, error) {
 	if config.WriterCount <= 0 {
 		config.WriterCount = 5 // Multiple writers for better throughput
 	}
-
-	// Append compressors=disabled to connection string to disable compression
-	connectionString := config.ConnectionString
-	if !strings.Contains(connectionString, "compressors=") {
-		// Ensure connection string has / before adding query parameters
-		// MongoDB connection strings require / before ? (e.g., mongodb+srv://host/?options)
-		hasQueryParams := strings.Contains(connectionString, "?")
-
-		if !hasQueryParams {
-			// If no query params exist, ensure connection string ends with /
-			if !strings.HasSuffix(connectionString, "/") {
-				connectionString = connectionString + "/"
-			}
-			// Add ?compressors=disabled
-			connectionString = connectionString + "?compressors=disabled"
-		} else {
-			// Query params already exist, just append &compressors=disabled
-			connectionString = connectionString + "&compressors=disabled"
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 100 * time.Millisecond
+	}
+	if config.DuplicateRatio < 0 || config.DuplicateRatio > 1 {
+		return nil, fmt.Errorf("duplicate ratio must be between 0 and 1: %v", config.DuplicateRatio)
+	}
+	for _, ratio := range []float64{config.Chaos.DelayRatio, config.Chaos.DuplicateRatio, config.Chaos.FailRatio, config.Chaos.ResetRatio, config.Chaos.DropRatio} {
+		if ratio < 0 || ratio > 1 {
+			return nil, fmt.Errorf("chaos ratios must be between 0 and 1: %v", ratio)
+		}
+	}
+	if config.OversizeRatio < 0 || config.OversizeRatio > 1 {
+		return nil, fmt.Errorf("oversize ratio must be between 0 and 1: %v", config.OversizeRatio)
+	}
+	if err := config.Namespaces.validate(); err != nil {
+		return nil, err
+	}
+	if config.Namespaces.Enabled() && (config.Encryption != nil || config.DuplicateRatio > 0) {
+		return nil, fmt.Errorf("many collections do not support encryption or duplicate collisions")
+	}
+	if config.AutoTune != nil {
+		if err := config.AutoTune.validate(); err != nil {
+			return nil, err
+		}
+		if config.Clients > 0 || len(config.DirectShards) > 0 {
+			return nil, fmt.Errorf("auto-tuning does not support simulated clients or direct shard writes")
+		}
+	}
+	if config.Profile != nil && (config.AutoTune != nil || len(config.DirectShards) > 0) {
+		return nil, fmt.Errorf("load profiles do not support auto-tuning or direct shard writes")
+	}
+	if config.BatchBytes < 0 || config.BatchBytes > MaxBatchBytes {
+		return nil, fmt.Errorf("batch bytes must be between 0 and %d: %d", MaxBatchBytes, config.BatchBytes)
+	}
+	if config.BatchBytes > 0 && config.AutoTune != nil {
+		return nil, fmt.Errorf("batch bytes are not supported with auto-tuning, which picks batch sizes itself")
+	}
+	if config.MarshalWorkers < 0 {
+		return nil, fmt.Errorf("marshal workers must not be negative: %d", config.MarshalWorkers)
+	}
+	if config.MarshalWorkers > 0 {
+		switch {
+		case config.Clients > 0 || len(config.DirectShards) > 0 || config.AutoTune != nil:
+			return nil, fmt.Errorf("marshal workers do not support simulated clients, direct shard writes, or auto-tuning")
+		case config.DuplicateRatio > 0 || config.GrowthSteps > 0 || config.OversizeRatio > 0 || config.OrdersCollection != "":
+			return nil, fmt.Errorf("marshal workers do not support duplicate collisions, document growth, oversized documents, or a referenced orders collection")
+		}
+	}
+	if config.OversizeRatio > 0 && config.Encryption != nil {
+		return nil, fmt.Errorf("oversized documents do not support encryption")
+	}
+	if config.DuplicateMode == "" {
+		config.DuplicateMode = DuplicateInsert
+	}
+	if config.DuplicateMode != DuplicateInsert && config.DuplicateMode != DuplicateUpsert {
+		return nil, fmt.Errorf("invalid duplicate mode: %s", config.DuplicateMode)
+	}
+	if config.WriteMode == "" {
+		config.WriteMode = WriteInsertMany
+	}
+	if !ValidWriteMode(config.WriteMode) {
+		return nil, fmt.Errorf("invalid write mode: %s", config.WriteMode)
+	}
+	if config.GrowthSteps < 0 {
+		return nil, fmt.Errorf("growth steps must not be negative: %d", config.GrowthSteps)
+	}
+	if config.GrowthSteps > 0 && (config.DuplicateRatio > 0 || config.Encryption != nil) {
+		return nil, fmt.Errorf("document growth does not support duplicate collisions or encryption")
+	}
+	if config.Ordered && config.DuplicateRatio > 0 {
+		return nil, fmt.Errorf("ordered writes do not support duplicate collisions")
+	}
+	if config.ClientBatchSize <= 0 {
+		config.ClientBatchSize = 1
+	}
+	if config.ConnectionMode == "" {
+		config.ConnectionMode = SharedPool
+	}
+	if config.ConnectionMode != SharedPool && config.ConnectionMode != PerWriterPool {
+		return nil, fmt.Errorf("invalid connection mode: %s", config.ConnectionMode)
+	}
+	if config.MaxPoolSize < 0 || config.MinPoolSize < 0 {
+		return nil, fmt.Errorf("pool sizes must not be negative")
+	}
+	if config.Encryption != nil {
+		if config.Encryption.Mode == "" {
+			config.Encryption.Mode = EncryptionCSFLE
+		}
+		if config.Encryption.KMSProvider == "" {
+			config.Encryption.KMSProvider = KMSLocal
+		}
+		if err := config.Encryption.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if len(config.DirectShards) > 0 {
+		switch {
+		case config.Clients > 0:
+			return nil, fmt.Errorf("direct shard writes do not support simulated clients")
+		case config.OrdersCollection != "":
+			return nil, fmt.Errorf("direct shard writes do not support a referenced orders collection")
+		case config.DuplicateRatio > 0:
+			return nil, fmt.Errorf("direct shard writes do not support duplicate collisions")
+		case config.ConnectionMode == PerWriterPool:
+			return nil, fmt.Errorf("direct shard writes do not support per-writer connection pools")
+		case config.Encryption != nil:
+			return nil, fmt.Errorf("direct shard writes do not support encryption")
+		case config.GrowthSteps > 0:
+			return nil, fmt.Errorf("direct shard writes do not support document growth")
+		case config.OversizeRatio > 0:
+			return nil, fmt.Errorf("direct shard writes do not support oversized documents")
+		case config.Namespaces.Enabled():
+			return nil, fmt.Errorf("direct shard writes do not support many collections")
 		}
 	}
 
-	// Create MongoDB client with optimized settings
-	// Use W:1, J:false for maximum throughput
-	wc := writeconcern.New(writeconcern.W(1), writeconcern.J(false))
-
-	clientOptions := options.Client().
-		ApplyURI(connectionString).
-		SetMaxPoolSize(uint64(config.WriterCount * 10)).
-		SetMinPoolSize(uint64(config.WriterCount)).
-		SetWriteConcern(wc).
-		SetRetryWrites(false).
-		SetServerSelectionTimeout(30 * time.Second).
-		SetSocketTimeout(60 * time.Second)
+	// The shared client also serves metadata, churn, and verification, and
+	// is sized for the most writers auto-tuning tries
+	poolWriters := config.WriterCount
+	if config.AutoTune != nil {
+		poolWriters = max(poolWriters, slices.Max(config.AutoTune.Writers))
+	}
+	sharedMax, sharedMin := uint64(poolWriters*10), uint64(config.WriterCount)
+	writerMax, writerMin := uint64(perWriterPoolSize), uint64(0)
+	if config.ConnectionMode == SharedPool {
+		if config.MaxPoolSize > 0 {
+			sharedMax = uint64(config.MaxPoolSize)
+		}
+		if config.MinPoolSize > 0 {
+			sharedMin = uint64(config.MinPoolSize)
+		}
+		if sharedMin > sharedMax {
+			sharedMin = sharedMax
+		}
+	} else {
+		sharedMax, sharedMin = perWriterPoolSize, 0
+		if config.MaxPoolSize > 0 {
+			writerMax = uint64(config.MaxPoolSize)
+		}
+		writerMin = uint64(config.MinPoolSize)
+		if writerMin > writerMax {
+			writerMin = writerMax
+		}
+	}
 
-	client, err := mongo.Connect(context.Background(), clientOptions)
+	w := &Writer{}
+	client, err := connectMonitored(config.ConnectionString, sharedMax, sharedMin, w.pool.monitor(), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+		return nil, err
 	}
 
 	// Verify connection
@@ -113,129 +421,364 @@ func NewWriter(config Config) (*Writer, error) {
 				{Key: "configString", Value: "block_compressor=none"},
 			}},
 		})
+	if config.Clustered {
+		createOpts.SetClusteredIndex(bson.D{
+			{Key: "key", Value: bson.D{{Key: "_id", Value: 1}}},
+			{Key: "unique", Value: true},
+		})
+	}
+
+	if config.Collation != nil {
+		createOpts.SetCollation(config.Collation)
+	}
+
+	var autoEncryption *options.AutoEncryptionOptions
+	if config.Encryption != nil {
+		var encryptedFields bson.M
+		autoEncryption, encryptedFields, err = setupEncryption(ctx, client, config.DatabaseName, config.CollectionName, config.Encryption)
+		if err != nil {
+			return nil, err
+		}
+		if encryptedFields != nil {
+			createOpts.SetEncryptedFields(encryptedFields)
+		}
+	}
 
 	// Try to create collection (ignore error if it already exists)
 	err = database.CreateCollection(ctx, config.CollectionName, createOpts)
-	if err != nil && !strings.Contains(err.Error(), "already exists") && !strings.Contains(err.Error(), "NamespaceExists") {
+	if err != nil && !isNamespaceExists(err) {
 		// If collection creation fails for other reasons, log but continue
 		// The collection might already exist or we might not have permissions
 		// In that case, we'll use the existing collection
 	}
 
 	collection := database.Collection(config.CollectionName)
+	inserts := collection
+	var encryptedClient *mongo.Client
+	if autoEncryption != nil {
+		encryptedClient, err = connectMonitored(config.ConnectionString, sharedMax, sharedMin, w.pool.monitor(), autoEncryption)
+		if err != nil {
+			return nil, err
+		}
+		inserts = encryptedClient.Database(config.DatabaseName).Collection(config.CollectionName)
+	}
 
-	return &Writer{
+	namespaces := newNamespaceSpread(config.Namespaces)
+	if namespaces != nil {
+		if err := namespaces.create(database, createOpts); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.OrdersCollection != "" {
+		if err := ensureOrdersIndex(ctx, database.Collection(config.OrdersCollection)); err != nil {
+			return nil, err
+		}
+	}
+
+	*w = Writer{
 		client:      client,
 		collection:  collection,
 		batchSize:   config.BatchSize,
+		batchBytes:  config.BatchBytes,
 		writerCount: config.WriterCount,
-		targetBytes: config.TargetBytes,
+		budget:      newByteBudget(config.TargetBytes),
 		startTime:   time.Now(),
 		ycsbLogger:  config.YCSBLogger,
-	}, nil
+
+		connectionString: config.ConnectionString,
+		databaseName:     config.DatabaseName,
+		collectionName:   config.CollectionName,
+		clients:          config.Clients,
+		clientBatchSize:  config.ClientBatchSize,
+		thinkTime:        config.ThinkTime,
+
+		ordersCollectionName: config.OrdersCollection,
+		growthSteps:          config.GrowthSteps,
+		growthFields:         config.GrowthFields,
+		insertTimeout:        config.InsertTimeout,
+		maxRetries:           config.MaxRetries,
+		retryBackoffBase:     config.RetryBackoff,
+		exactlyOnce:          config.ExactlyOnce,
+		duplicateRatio:       config.DuplicateRatio,
+		duplicateMode:        config.DuplicateMode,
+		duplicates:           newDuplicateTracker(),
+		chaos:                newChaos(config.Chaos),
+		oversize:             newOversizeInjector(config.OversizeRatio),
+		namespaces:           namespaces,
+		keys:                 newKeySample(config.KeyField, config.KeySample),
+		pipeline:             newMarshalPipeline(config.MarshalWorkers),
+		autoTune:             config.AutoTune,
+		profile:              config.Profile,
+		writeMode:            config.WriteMode,
+		ordered:              config.Ordered,
+		directShards:         config.DirectShards,
+		autoEncryption:       autoEncryption,
+		encryptedClient:      encryptedClient,
+		inserts:              inserts,
+
+		connectionMode: config.ConnectionMode,
+		maxPoolSize:    writerMax,
+		minPoolSize:    writerMin,
+	}
+	return w, nil
 }
 
+// A Writer is the MongoDB sink, which batches for its own writers
+var _ sink.Streamer = (*Writer)(nil)
+
 // Write writes documents from the channel to MongoDB
-func (w *Writer) Write(ctx context.Context, docChan <-chan *model.CustomerDocument) error {
+func (w *Writer) Write(ctx context.Context, docChan <-chan model.Document) error {
+	w.profileStart = time.Now()
+	if w.clients > 0 {
+		return w.runClients(ctx, docChan)
+	}
+	if len(w.directShards) > 0 {
+		return w.writeDirect(ctx, docChan)
+	}
+
+	if w.pipeline != nil {
+		return w.writePipelined(ctx, docChan)
+	}
+
+	if w.autoTune != nil {
+		if err := w.calibrate(ctx, docChan, w.autoTune); err != nil {
+			return err
+		}
+	}
+
 	eg, ctx := errgroup.WithContext(ctx)
 
 	// Start multiple writer workers for parallel insertion
 	for i := 0; i < w.writerCount; i++ {
 		writerID := i
-		eg.Go(func() error {
-			return w.writeWorker(ctx, writerID, docChan)
+		eg.Go(func() (err error) {
+			defer recoverPanic(&err)
+			collection, release, err := w.writerCollection(writerID)
+			if err != nil {
+				return err
+			}
+			defer release()
+			return w.writeWorker(ctx, collection, docChan)
 		})
 	}
 
 	return eg.Wait()
 }
 
-// writeWorker is a worker that batches documents and writes them
-func (w *Writer) writeWorker(ctx context.Context, writerID int, docChan <-chan *model.CustomerDocument) error {
+// WriteBatch writes one batch of documents through the shared client
+func (w *Writer) WriteBatch(ctx context.Context, docs []model.Document) error {
+	batch := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		batch[i] = doc
+	}
+	return w.flushBatchTo(ctx, w.inserts, batch)
+}
+
+// writeWorker is a worker that batches documents and writes them to collection
+func (w *Writer) writeWorker(ctx context.Context, collection *mongo.Collection, docChan <-chan model.Document) error {
 	batch := make([]interface{}, 0, w.batchSize)
+	// Bytes of the batch, measured only with a byte bound
+	batchBytes := int64(0)
 	ticker := time.NewTicker(100 * time.Millisecond) // Flush batch every 100ms if not full
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
-			// Flush remaining batch before exiting
-			if len(batch) > 0 {
-				if err := w.flushBatch(ctx, batch); err != nil {
-					return err
-				}
-			}
+			// The batch can no longer be inserted once the load is cancelled
+			atomic.AddInt64(&w.docsAbandoned, int64(len(batch)))
 			return ctx.Err()
 
 		case doc, ok := <-docChan:
 			if !ok {
 				// Channel closed, flush and exit
 				if len(batch) > 0 {
-					if err := w.flushBatch(ctx, batch); err != nil {
+					if err := w.flushBatchTo(ctx, collection, batch); err != nil {
 						return err
 					}
 				}
 				return nil
 			}
 
-			batch = append(batch, doc)
-
-			// Check if we've reached target
-			if atomic.LoadInt64(&w.bytesWritten) >= w.targetBytes {
-				// Flush batch and exit
-				if len(batch) > 0 {
-					if err := w.flushBatch(ctx, batch); err != nil {
-						return err
-					}
+			// Send the batch first if the document would take it over the byte bound
+			size, err := w.measure(doc)
+			if err != nil {
+				return err
+			}
+			if w.overflows(len(batch), batchBytes, size) {
+				if err := w.flushBatchTo(ctx, collection, batch); err != nil {
+					return err
 				}
-				return nil
+				batch, batchBytes = batch[:0], 0
 			}
+			batch = append(batch, doc)
+			batchBytes += size
 
 			// Flush if batch is full
-			if len(batch) >= w.batchSize {
-				if err := w.flushBatch(ctx, batch); err != nil {
+			if w.full(len(batch), batchBytes) {
+				if err := w.flushBatchTo(ctx, collection, batch); err != nil {
 					return err
 				}
-				batch = batch[:0] // Reset batch
+				batch, batchBytes = batch[:0], 0 // Reset batch
 			}
 
 		case <-ticker.C:
 			// Periodic flush to avoid holding documents too long
 			if len(batch) > 0 {
-				if err := w.flushBatch(ctx, batch); err != nil {
+				if err := w.flushBatchTo(ctx, collection, batch); err != nil {
 					return err
 				}
-				batch = batch[:0]
+				batch, batchBytes = batch[:0], 0
 			}
 		}
+
+		// Stop once the other writers have claimed the rest of the target
+		if w.budget.exhausted() {
+			atomic.AddInt64(&w.docsDiscarded, int64(len(batch)))
+			return nil
+		}
 	}
 }
 
-// flushBatch writes a batch of documents to MongoDB
-func (w *Writer) flushBatch(ctx context.Context, batch []interface{}) error {
+// flushBatchTo writes a batch of documents to the given collection
+func (w *Writer) flushBatchTo(ctx context.Context, collection *mongo.Collection, batch []interface{}) error {
 	if len(batch) == 0 {
 		return nil
 	}
+	if err := w.gate.wait(ctx); err != nil {
+		atomic.AddInt64(&w.docsAbandoned, int64(len(batch)))
+		return fmt.Errorf("failed to insert batch: %w", err)
+	}
+	if err := w.followProfile(ctx, len(batch)); err != nil {
+		atomic.AddInt64(&w.docsAbandoned, int64(len(batch)))
+		return fmt.Errorf("failed to insert batch: %w", err)
+	}
+
+	// Spread batches over the collections of the namespace template
+	namespace := -1
+	if w.namespaces != nil {
+		collection, namespace = w.namespaces.collection(collection)
+	}
+
+	// Reuse existing _ids for a fraction of the batch
+	var upserts []interface{}
+	var collisions map[primitive.ObjectID]bool
+	if w.duplicateRatio > 0 {
+		batch, upserts, collisions = w.injectDuplicates(batch)
+		if len(upserts) > 0 {
+			if err := w.writeUpserts(ctx, collection, upserts); err != nil {
+				return err
+			}
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+	}
+
+	// Write a fraction of the batch as jumbo documents
+	if w.oversize != nil {
+		var err error
+		if batch, err = w.injectOversize(ctx, collection, batch); err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+	}
+
+	// Lose a fraction of the batches without a trace, before they count
+	if w.chaos.drop(len(batch)) {
+		return nil
+	}
 
 	// Calculate actual bytes written
 	var totalBytes int64
-	for _, doc := range batch {
-		bsonData, err := bson.Marshal(doc)
+	sizes := make([]int64, len(batch))
+	for i, doc := range batch {
+		size, err := documentSize(doc)
 		if err != nil {
-			return fmt.Errorf("failed to marshal document: %w", err)
+			return err
 		}
-		totalBytes += int64(len(bsonData))
+		sizes[i] = size
+		totalBytes += size
 	}
 
-	// Use InsertMany for better performance
-	opts := options.InsertMany().SetOrdered(false) // Unordered for better performance
+	// Claim the batch's bytes from the target, dropping it once spent
+	if !w.budget.claim(totalBytes) {
+		atomic.AddInt64(&w.docsDiscarded, int64(len(batch)))
+		return nil
+	}
+	claimedBytes := totalBytes
+	atomic.AddInt64(&w.batches, 1)
+
+	// Insert documents small when they are to grow afterwards
+	inserted := batch
+	var plans [][]bson.D
+	if w.growthSteps > 0 {
+		var err error
+		if inserted, plans, err = w.growthPlans(batch); err != nil {
+			return err
+		}
+	}
 
 	// Record operation start time for YCSB logging
 	startTime := time.Now()
-	_, err := w.collection.InsertMany(ctx, batch, opts)
+	if w.tailChangeStream {
+		w.trackPendingInserts(batch, startTime)
+	}
+	ledger := newBatchLedger(batch, collisions)
+	resume := false // Set once an attempt failed, possibly part way through an ordered batch
+	err := w.insertWithRetry(ctx, func(ctx context.Context) error {
+		// Resend only the documents an ambiguous attempt did not insert
+		sent, indexes := inserted, []int(nil)
+		if w.exactlyOnce && ledger.ambiguous {
+			if err := ledger.resolve(ctx, collection); err != nil {
+				return err
+			}
+			if sent, indexes = ledger.pending(inserted); len(sent) == 0 {
+				return nil
+			}
+		}
+		err := w.chaos.insert(ctx, func(ctx context.Context) error {
+			return w.insertBatch(ctx, collection, sent, resume)
+		})
+		ledger.settle(err, indexes, w.ordered)
+		resume = err != nil
+		return err
+	})
 	latency := time.Since(startTime)
+	if err != nil && w.exactlyOnce && ledger.ambiguous && ctx.Err() == nil {
+		lookupCtx, cancel := withTimeout(ctx, w.insertTimeout)
+		if lookupErr := ledger.resolve(lookupCtx, collection); lookupErr != nil {
+			log.Printf("Warning: %v", lookupErr)
+		}
+		cancel()
+	}
+	atomic.AddInt64(&w.docsReconciled, ledger.reconciled)
+
+	// Intentional collisions rejected with duplicate key errors are expected
+	if w.duplicateRatio > 0 && isDuplicateKeyOnly(err) {
+		err = nil
+	}
+
+	// Count exactly the documents some attempt inserted
+	rejected := ledger.count(docRejected)
+	atomic.AddInt64(&w.duplicatesRejected, int64(rejected))
+	written := make([]interface{}, 0, len(batch))
+	totalBytes = 0
+	for i, doc := range batch {
+		if ledger.state[i] == docInserted {
+			written = append(written, doc)
+			totalBytes += sizes[i]
+		}
+	}
 
 	success := err == nil
+	timedOut := isTimeout(err)
+	if timedOut {
+		atomic.AddInt64(&w.timeouts, int64(len(batch)))
+	}
 	if err != nil {
 		// Log error but continue - some documents might have succeeded
 		// In production, you might want more sophisticated error handling
@@ -246,14 +789,29 @@ func (w *Writer) flushBatch(ctx context.Context, batch []interface{}) error {
 		// Record each document in the batch as a separate operation
 		// Use average latency per document for more accurate metrics
 		avgLatencyPerDoc := latency / time.Duration(len(batch))
-		for i := 0; i < len(batch); i++ {
-			w.ycsbLogger.RecordOperation("INSERT", avgLatencyPerDoc, success)
+		for i := 0; i < rejected; i++ {
+			w.ycsbLogger.RecordOperation("INSERT_DUPLICATE", avgLatencyPerDoc, false)
 		}
+		for i := rejected; i < len(batch); i++ {
+			if timedOut {
+				w.ycsbLogger.RecordTimeout("INSERT", avgLatencyPerDoc)
+			} else {
+				w.ycsbLogger.RecordOperation("INSERT", avgLatencyPerDoc, success)
+			}
+		}
+	}
+
+	// Inserts cut off by cancellation are lost rather than written
+	if err != nil && ctx.Err() != nil {
+		atomic.AddInt64(&w.docsAbandoned, int64(ledger.count(docPending)))
 	}
 
 	// Update statistics
+	w.budget.release(claimedBytes - totalBytes)
 	atomic.AddInt64(&w.bytesWritten, totalBytes)
-	atomic.AddInt64(&w.docsWritten, int64(len(batch)))
+	atomic.AddInt64(&w.docsWritten, int64(len(written)))
+	w.namespaces.record(namespace, len(written))
+	w.keys.add(written)
 
 	// Update YCSB logger with bytes written
 	if w.ycsbLogger != nil {
@@ -264,6 +822,21 @@ func (w *Writer) flushBatch(ctx context.Context, batch []interface{}) error {
 		return fmt.Errorf("failed to insert batch: %w", err)
 	}
 
+	if w.duplicateRatio > 0 {
+		w.duplicates.remember(written)
+	}
+
+	if plans != nil {
+		if err := w.grow(ctx, collection, batch, plans); err != nil {
+			return err
+		}
+	}
+
+	// Write the batch's orders only after their customers exist
+	if w.ordersCollectionName != "" {
+		return w.writeOrders(ctx, collection.Database().Collection(w.ordersCollectionName), written)
+	}
+
 	return nil
 }
 
@@ -286,6 +859,29 @@ func (w *Writer) GetStats() Stats {
 	return Stats{
 		DocumentsWritten:   docs,
 		BytesWritten:       bytes,
+		Batches:            atomic.LoadInt64(&w.batches),
+		OrdersWritten:      atomic.LoadInt64(&w.ordersWritten),
+		GrowthUpdates:      atomic.LoadInt64(&w.growthUpdates),
+		Timeouts:           atomic.LoadInt64(&w.timeouts),
+		Retries:            atomic.LoadInt64(&w.retries),
+		Reconciled:         atomic.LoadInt64(&w.docsReconciled),
+		DuplicatesRejected: atomic.LoadInt64(&w.duplicatesRejected),
+		Upserts:            atomic.LoadInt64(&w.upserts),
+		DocumentsDeleted:   atomic.LoadInt64(&w.docsDeleted),
+		DocumentsDiscarded: atomic.LoadInt64(&w.docsDiscarded),
+		DocumentsAbandoned: atomic.LoadInt64(&w.docsAbandoned),
+		InjectedFaults:     w.chaos.stats(),
+		Oversize:           w.oversize.stats(),
+		Namespaces:         w.namespaces.stats(),
+		AutoTune:           w.tuneResult,
+		Marshal:            w.pipeline.stats(),
+		RetryTime:          time.Duration(atomic.LoadInt64(&w.retryNanos)),
+		ProductiveTime:     time.Duration(atomic.LoadInt64(&w.productiveNanos)),
+		Pool:               w.pool.stats(),
+		CollectionSize:     atomic.LoadInt64(&w.collectionSize),
+		Shards:             w.shards,
+		Oplog:              w.oplog.last(),
+		Throttle:           w.gate.stats(),
 		DocumentsPerSecond: docsPerSec,
 		BytesPerSecond:     bytesPerSec,
 		StartTime:          w.startTime,
@@ -293,21 +889,83 @@ func (w *Writer) GetStats() Stats {
 	}
 }
 
+// Stats returns the documents and bytes written, as a sink
+func (w *Writer) Stats() sink.Stats {
+	return sink.Stats{
+		DocumentsWritten: atomic.LoadInt64(&w.docsWritten),
+		BytesWritten:     atomic.LoadInt64(&w.bytesWritten),
+	}
+}
+
 // Stats represents write statistics
 type Stats struct {
 	DocumentsWritten   int64
 	BytesWritten       int64
+	Batches            int64 // Insert batches sent
+	OrdersWritten      int64 // Standalone order documents (OrdersCollection)
+	GrowthUpdates      int64 // Updates growing inserted documents (GrowthSteps)
+	Timeouts           int64 // Documents whose insert exceeded InsertTimeout
+	Retries            int64
+	Reconciled         int64         // Retried documents found inserted by an earlier, failed attempt
+	DuplicatesRejected int64         // Intentional collisions rejected with duplicate key errors
+	Upserts            int64         // Intentional collisions written as upserts
+	DocumentsDeleted   int64         // Oldest documents removed by churn
+	DocumentsDiscarded int64         // Generated documents dropped once the target bytes were claimed
+	DocumentsAbandoned int64         // Documents in writer batches or inserts cut off by cancellation
+	InjectedFaults     ChaosStats    // Batches affected by client-side fault injection
+	Oversize           OversizeStats // Injected jumbo documents (OversizeRatio)
+	Namespaces         SpreadStats   // Spread over collections (Namespaces only)
+	AutoTune           *TuneResult   // Calibration bursts (AutoTune only)
+	Marshal            *MarshalStats // Marshal and insert pools (MarshalWorkers only)
+	RetryTime          time.Duration // Time spent in failed attempts and backoff
+	ProductiveTime     time.Duration // Time spent in successful insert attempts
+	Pool               PoolStats     // Connection checkouts of the writer's clients
+	CollectionSize     int64         // Last server-reported size (StartSizeWatch only)
+	Shards             []ShardStats  // Last distribution across shards (StartShardWatch only)
+	Oplog              *OplogStats   // Last oplog window and replication lag (StartOplogWatch only)
+	Throttle           ThrottleStats // Inserts held back by watches of the cluster's health
 	DocumentsPerSecond float64
 	BytesPerSecond     float64
 	StartTime          time.Time
 	LastUpdate         time.Time
 }
 
+// Client returns the writer's MongoDB client
+func (w *Writer) Client() *mongo.Client {
+	return w.client
+}
+
+// Collection returns the target collection
+func (w *Writer) Collection() *mongo.Collection {
+	return w.collection
+}
+
+// WrittenKeys returns a random sample of the business keys of the documents
+// written, nil unless Config.KeySample is set
+func (w *Writer) WrittenKeys() []string {
+	return w.keys.sample()
+}
+
+// CountDocuments counts the documents in the target collection
+func (w *Writer) CountDocuments() (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	count, err := w.collection.CountDocuments(ctx, bson.D{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count documents: %w", err)
+	}
+	return count, nil
+}
+
 // Close closes the MongoDB connection
 func (w *Writer) Close() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	// Final stats will be written when the logger is closed
+	if w.encryptedClient != nil {
+		w.encryptedClient.Disconnect(ctx)
+	}
 	return w.client.Disconnect(ctx)
 }
@@ -0,0 +1,206 @@
+package pacing
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProfileShape selects how a load profile varies the target rate over time
+type ProfileShape string
+
+const (
+	// ProfileRamp rises linearly from the low to the high rate over the
+	// period, then holds the high rate
+	ProfileRamp ProfileShape = "ramp"
+	// ProfileStep rises from the low to the high rate in equal steps over
+	// the period, then holds the high rate
+	ProfileStep ProfileShape = "step"
+	// ProfileSine swings between the low and the high rate once per period,
+	// starting low
+	ProfileSine ProfileShape = "sine"
+	// ProfileSpike holds the low rate and jumps to the high rate for the last
+	// Width of every period
+	ProfileSpike ProfileShape = "spike"
+)
+
+const (
+	// defaultProfileSteps is the number of rates a step profile goes through
+	defaultProfileSteps = 5
+
+	// maxProfileWait bounds each wait of Delay, so that a rising rate is
+	// picked up soon after a slow stretch
+	maxProfileWait = 100 * time.Millisecond
+)
+
+// LoadProfile is a target rate that varies over the run, to show how a
+// cluster's autoscaling and flow control follow a changing load
+type LoadProfile struct {
+	Shape  ProfileShape
+	Low    float64 // Operations per second; ramps and steps go down if above High
+	High   float64 // Operations per second
+	Period time.Duration
+	Steps  int           // Rates of a step profile, including the low and the high rate
+	Width  time.Duration // Spike duration of a spike profile
+}
+
+// ParseLoadProfile parses "SHAPE:LOW-HIGHops/PERIOD[,OPTION]", such as
+// "ramp:0-10000ops/30m", "step:1000-5000ops/1h,5" (five rates),
+// "sine:2000-8000ops/10m", or "spike:1000-20000ops/15m,30s" (spike width,
+// default a tenth of the period). The "ops" unit may be left out.
+func ParseLoadProfile(s string) (LoadProfile, error) {
+	shape, spec, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return LoadProfile{}, fmt.Errorf("invalid load profile %q: want SHAPE:LOW-HIGHops/PERIOD", s)
+	}
+	p := LoadProfile{Shape: ProfileShape(strings.ToLower(shape))}
+	switch p.Shape {
+	case ProfileRamp, ProfileStep, ProfileSine, ProfileSpike:
+	default:
+		return LoadProfile{}, fmt.Errorf("unknown load profile shape %s (use ramp, step, sine, or spike)", shape)
+	}
+
+	spec, option, hasOption := strings.Cut(spec, ",")
+	rates, period, ok := strings.Cut(spec, "/")
+	if !ok {
+		return LoadProfile{}, fmt.Errorf("invalid load profile %q: want SHAPE:LOW-HIGHops/PERIOD", s)
+	}
+	low, high, ok := strings.Cut(strings.TrimSuffix(strings.TrimSpace(rates), "ops"), "-")
+	if !ok {
+		return LoadProfile{}, fmt.Errorf("invalid load profile rates %q: want LOW-HIGH", rates)
+	}
+	var err error
+	if p.Low, err = strconv.ParseFloat(strings.TrimSpace(low), 64); err != nil || p.Low < 0 {
+		return LoadProfile{}, fmt.Errorf("invalid load profile rate %q", low)
+	}
+	if p.High, err = strconv.ParseFloat(strings.TrimSpace(high), 64); err != nil || p.High < 0 {
+		return LoadProfile{}, fmt.Errorf("invalid load profile rate %q", high)
+	}
+	if p.Low == 0 && p.High == 0 {
+		return LoadProfile{}, fmt.Errorf("load profile %q never runs an operation", s)
+	}
+	if p.Period, err = time.ParseDuration(strings.TrimSpace(period)); err != nil || p.Period <= 0 {
+		return LoadProfile{}, fmt.Errorf("invalid load profile period %q", period)
+	}
+
+	option = strings.TrimSpace(option)
+	switch {
+	case p.Shape == ProfileStep:
+		p.Steps = defaultProfileSteps
+		if hasOption {
+			if p.Steps, err = strconv.Atoi(option); err != nil || p.Steps < 2 {
+				return LoadProfile{}, fmt.Errorf("invalid load profile steps %q: want at least 2", option)
+			}
+		}
+	case p.Shape == ProfileSpike:
+		p.Width = p.Period / 10
+		if hasOption {
+			if p.Width, err = time.ParseDuration(option); err != nil || p.Width <= 0 || p.Width > p.Period {
+				return LoadProfile{}, fmt.Errorf("invalid load profile spike width %q: want a duration up to the period", option)
+			}
+		}
+	case hasOption:
+		return LoadProfile{}, fmt.Errorf("the %s load profile takes no option", p.Shape)
+	}
+	return p, nil
+}
+
+// RateAt returns the target rate in operations per second at the elapsed
+// time of the run
+func (p LoadProfile) RateAt(elapsed time.Duration) float64 {
+	t := elapsed.Seconds()
+	period := p.Period.Seconds()
+	switch p.Shape {
+	case ProfileRamp:
+		if t >= period {
+			return p.High
+		}
+		return p.Low + (p.High-p.Low)*t/period
+	case ProfileStep:
+		step := int(t / (period / float64(p.Steps)))
+		if step >= p.Steps {
+			step = p.Steps - 1
+		}
+		return p.Low + (p.High-p.Low)*float64(step)/float64(p.Steps-1)
+	case ProfileSine:
+		return p.Low + (p.High-p.Low)*(1-math.Cos(2*math.Pi*t/period))/2
+	case ProfileSpike:
+		if math.Mod(t, period) >= period-p.Width.Seconds() {
+			return p.High
+		}
+		return p.Low
+	}
+	return p.High
+}
+
+// Operations returns how many operations the profile targets from the start
+// of the run until the elapsed time
+func (p LoadProfile) Operations(elapsed time.Duration) float64 {
+	t := elapsed.Seconds()
+	period := p.Period.Seconds()
+	switch p.Shape {
+	case ProfileRamp:
+		if t >= period {
+			return (p.Low+p.High)/2*period + p.High*(t-period)
+		}
+		return p.Low*t + (p.High-p.Low)*t*t/(2*period)
+	case ProfileStep:
+		stepLength := period / float64(p.Steps)
+		var ops float64
+		for step := 0; step < p.Steps && t > 0; step++ {
+			d := t // The last rate is held
+			if step < p.Steps-1 {
+				d = math.Min(t, stepLength)
+			}
+			ops += d * (p.Low + (p.High-p.Low)*float64(step)/float64(p.Steps-1))
+			t -= d
+		}
+		return ops
+	case ProfileSine:
+		return p.Low*t + (p.High-p.Low)/2*(t-period/(2*math.Pi)*math.Sin(2*math.Pi*t/period))
+	case ProfileSpike:
+		width := p.Width.Seconds()
+		periods := math.Floor(t / period)
+		ops := periods * (p.Low*(period-width) + p.High*width)
+		rest := t - periods*period
+		ops += p.Low * math.Min(rest, period-width)
+		ops += p.High * math.Max(rest-(period-width), 0)
+		return ops
+	}
+	return p.High * t
+}
+
+// Delay returns how long after the elapsed time the nth operation of the run
+// (counting from 0) is due, at most maxProfileWait: callers wait and ask again
+// until it returns 0, so that changes of the rate are followed
+func (p LoadProfile) Delay(elapsed time.Duration, n float64) time.Duration {
+	ahead := n - p.Operations(elapsed)
+	if ahead <= 0 {
+		return 0
+	}
+	rate := p.RateAt(elapsed)
+	if rate <= 0 {
+		return maxProfileWait
+	}
+	wait := time.Duration(ahead / rate * float64(time.Second))
+	return min(max(wait, time.Millisecond), maxProfileWait)
+}
+
+// String formats the profile as ParseLoadProfile accepts it
+func (p LoadProfile) String() string {
+	s := fmt.Sprintf("%s:%s-%sops/%v", p.Shape, formatRate(p.Low), formatRate(p.High), p.Period)
+	switch p.Shape {
+	case ProfileStep:
+		s += "," + strconv.Itoa(p.Steps)
+	case ProfileSpike:
+		s += "," + p.Width.String()
+	}
+	return s
+}
+
+// formatRate formats a rate without needless decimals
+func formatRate(rate float64) string {
+	return strconv.FormatFloat(rate, 'f', -1, 64)
+}
@@ -0,0 +1,89 @@
+package pacing
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParseLoadProfile(t *testing.T) {
+	for s, want := range map[string]string{
+		"ramp:0-10000ops/30m":         "ramp:0-10000ops/30m0s",
+		"step:1000-5000/1h":           "step:1000-5000ops/1h0m0s,5",
+		"step:1000-5000ops/1h,3":      "step:1000-5000ops/1h0m0s,3",
+		"sine:2000-8000ops/10m":       "sine:2000-8000ops/10m0s",
+		"Spike:1000-20000ops/15m":     "spike:1000-20000ops/15m0s,1m30s",
+		"spike:1000-20000ops/15m,30s": "spike:1000-20000ops/15m0s,30s",
+		"ramp:0.5-2.5ops/1s":          "ramp:0.5-2.5ops/1s",
+	} {
+		p, err := ParseLoadProfile(s)
+		if err != nil {
+			t.Fatalf("ParseLoadProfile(%s) failed: %v", s, err)
+		}
+		if p.String() != want {
+			t.Errorf("ParseLoadProfile(%s) = %s, want %s", s, p, want)
+		}
+		if again, err := ParseLoadProfile(p.String()); err != nil || again != p {
+			t.Errorf("ParseLoadProfile(%s) = %v, %v", p, again, err)
+		}
+	}
+	for _, s := range []string{"", "ramp", "flat:0-10/1m", "ramp:10/1m", "ramp:0-0ops/1m", "ramp:-1-10/1m", "ramp:0-10/0s",
+		"ramp:0-10/1m,5", "step:0-10/1m,1", "spike:0-10/1m,2m"} {
+		if _, err := ParseLoadProfile(s); err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+	}
+}
+
+func TestLoadProfileRate(t *testing.T) {
+	ramp, _ := ParseLoadProfile("ramp:0-1000ops/100s")
+	step, _ := ParseLoadProfile("step:1000-5000ops/100s,5")
+	sine, _ := ParseLoadProfile("sine:1000-3000ops/100s")
+	spike, _ := ParseLoadProfile("spike:100-1000ops/100s,10s")
+	for _, c := range []struct {
+		profile LoadProfile
+		elapsed time.Duration
+		want    float64
+	}{
+		{ramp, 0, 0}, {ramp, 50 * time.Second, 500}, {ramp, time.Hour, 1000},
+		{step, 0, 1000}, {step, 25 * time.Second, 2000}, {step, 99 * time.Second, 5000}, {step, time.Hour, 5000},
+		{sine, 0, 1000}, {sine, 50 * time.Second, 3000}, {sine, 100 * time.Second, 1000},
+		{spike, 0, 100}, {spike, 95 * time.Second, 1000}, {spike, 105 * time.Second, 100},
+	} {
+		if got := c.profile.RateAt(c.elapsed); math.Abs(got-c.want) > 1e-6 {
+			t.Errorf("%s at %v: rate %v, want %v", c.profile, c.elapsed, got, c.want)
+		}
+	}
+}
+
+func TestLoadProfileOperations(t *testing.T) {
+	// Operations is the integral of RateAt
+	for _, s := range []string{"ramp:0-1000ops/100s", "step:1000-5000ops/100s,5", "sine:1000-3000ops/100s", "spike:100-1000ops/100s,10s"} {
+		p, _ := ParseLoadProfile(s)
+		var sum float64
+		const dt = 10 * time.Millisecond
+		for elapsed := time.Duration(0); elapsed < 250*time.Second; elapsed += dt {
+			sum += p.RateAt(elapsed+dt/2) * dt.Seconds()
+		}
+		if ops := p.Operations(250 * time.Second); math.Abs(ops-sum) > sum*0.001 {
+			t.Errorf("%s: %v operations in 250s, want %v", s, ops, sum)
+		}
+	}
+}
+
+func TestLoadProfileDelay(t *testing.T) {
+	p, _ := ParseLoadProfile("ramp:0-1000ops/100s")
+	if d := p.Delay(0, 0); d != 0 {
+		t.Errorf("First operation delayed %v", d)
+	}
+	if d := p.Delay(0, 1); d != maxProfileWait {
+		t.Errorf("Operation at rate 0 delayed %v", d)
+	}
+	// 500 ops/sec after 50s, by when 12500 operations are due
+	if d := p.Delay(50*time.Second, 12510); d < 15*time.Millisecond || d > 25*time.Millisecond {
+		t.Errorf("Operation 10 ahead delayed %v", d)
+	}
+	if d := p.Delay(50*time.Second, 12000); d != 0 {
+		t.Errorf("Overdue operation delayed %v", d)
+	}
+}
@@ -0,0 +1,66 @@
+// Package pacing spaces out the operations of load and workload threads:
+// think times between the operations of a thread and load profiles that
+// vary the target rate over a run.
+package pacing
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// Jitter selects how think times vary around their mean
+type Jitter string
+
+const (
+	// JitterFixed pauses exactly the mean, so threads keep their start offsets
+	JitterFixed Jitter = "fixed"
+	// JitterUniform pauses uniformly between zero and twice the mean
+	JitterUniform Jitter = "uniform"
+	// JitterExponential pauses an exponentially distributed time with the
+	// mean, so each thread's operations arrive as a Poisson process
+	JitterExponential Jitter = "exponential"
+)
+
+// ParseJitter validates a jitter name ("" = fixed)
+func ParseJitter(jitter string) (Jitter, error) {
+	switch Jitter(strings.ToLower(jitter)) {
+	case "", JitterFixed:
+		return JitterFixed, nil
+	case JitterUniform:
+		return JitterUniform, nil
+	case JitterExponential:
+		return JitterExponential, nil
+	default:
+		return "", fmt.Errorf("invalid jitter: %s (use fixed, uniform, or exponential)", jitter)
+	}
+}
+
+// ThinkTime is the pause of an application thread between two operations
+type ThinkTime struct {
+	Mean   time.Duration // 0 = no pause
+	Jitter Jitter
+}
+
+// Next returns a random pause with the think time's mean and jitter
+func (t ThinkTime) Next(rng *rand.Rand) time.Duration {
+	if t.Mean <= 0 {
+		return 0
+	}
+	switch t.Jitter {
+	case JitterUniform:
+		return time.Duration(rng.Int63n(2*int64(t.Mean) + 1))
+	case JitterExponential:
+		return time.Duration(rng.ExpFloat64() * float64(t.Mean))
+	}
+	return t.Mean
+}
+
+// String formats the think time as its mean and jitter
+func (t ThinkTime) String() string {
+	if t.Jitter == "" || t.Jitter == JitterFixed {
+		return t.Mean.String()
+	}
+	return fmt.Sprintf("%v (%s)", t.Mean, t.Jitter)
+}
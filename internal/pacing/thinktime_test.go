@@ -0,0 +1,43 @@
+package pacing
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestParseJitter(t *testing.T) {
+	for s, want := range map[string]Jitter{"": JitterFixed, "fixed": JitterFixed, "Uniform": JitterUniform, "exponential": JitterExponential} {
+		if jitter, err := ParseJitter(s); err != nil || jitter != want {
+			t.Errorf("ParseJitter(%q) = %v, %v", s, jitter, err)
+		}
+	}
+	if _, err := ParseJitter("normal"); err == nil {
+		t.Error("Expected an error for an unknown jitter")
+	}
+}
+
+func TestThinkTimeNext(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	if pause := (ThinkTime{Jitter: JitterExponential}).Next(rng); pause != 0 {
+		t.Errorf("Zero think time paused %v", pause)
+	}
+	if pause := (ThinkTime{Mean: time.Second}).Next(rng); pause != time.Second {
+		t.Errorf("Fixed think time paused %v", pause)
+	}
+
+	for _, jitter := range []Jitter{JitterUniform, JitterExponential} {
+		think := ThinkTime{Mean: 100 * time.Millisecond, Jitter: jitter}
+		var total time.Duration
+		for i := 0; i < 10000; i++ {
+			pause := think.Next(rng)
+			if pause < 0 || (jitter == JitterUniform && pause > 2*think.Mean) {
+				t.Fatalf("%s think time paused %v", jitter, pause)
+			}
+			total += pause
+		}
+		if mean := total / 10000; mean < 95*time.Millisecond || mean > 105*time.Millisecond {
+			t.Errorf("%s think time has mean %v", jitter, mean)
+		}
+	}
+}
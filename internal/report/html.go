@@ -0,0 +1,262 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Chart layout, in SVG user units
+const (
+	chartWidth  = 760
+	chartHeight = 240
+	chartLeft   = 60
+	chartRight  = 20
+	chartTop    = 15
+	chartBottom = 30
+)
+
+// chartColors tell the operation types apart
+var chartColors = []string{"#1f77b4", "#d62728", "#2ca02c", "#ff7f0e", "#9467bd", "#8c564b", "#e377c2", "#17becf"}
+
+// chart is an SVG line chart with one line per operation type
+type chart struct {
+	Title  string
+	Lines  []chartLine
+	YTicks []chartTick
+	XTicks []chartTick
+}
+
+type chartLine struct {
+	Name   string
+	Color  string
+	Points string // SVG polyline points
+}
+
+type chartTick struct {
+	Pos   float64 // Position in SVG units along the axis
+	Label string
+}
+
+// newChart plots value for each operation type over intervals of width
+// seconds. With fill, an operation type missing from an interval is plotted
+// as zero rather than left out, as throughput is then zero.
+func newChart(title string, intervals []interval, width int64, fill bool, value func(interval) float64) chart {
+	c := chart{Title: title}
+	if len(intervals) == 0 {
+		return c
+	}
+	end := intervals[len(intervals)-1].Second + width
+
+	var operations []string
+	values := make(map[string]map[int64]float64)
+	yMax := 0.0
+	for _, i := range intervals {
+		if values[i.Operation] == nil {
+			values[i.Operation] = make(map[int64]float64)
+			operations = append(operations, i.Operation)
+		}
+		v := value(i)
+		values[i.Operation][i.Second] = v
+		yMax = math.Max(yMax, v)
+	}
+	yMax = niceCeil(yMax)
+
+	plotWidth := float64(chartWidth - chartLeft - chartRight)
+	plotHeight := float64(chartHeight - chartTop - chartBottom)
+	x := func(second int64) float64 {
+		if end <= width {
+			return chartLeft
+		}
+		return chartLeft + float64(second)/float64(end-width)*plotWidth
+	}
+	y := func(v float64) float64 {
+		return chartTop + plotHeight - v/yMax*plotHeight
+	}
+
+	// Sort for stable colors
+	sort.Strings(operations)
+	for n, operation := range operations {
+		var points []string
+		for second := int64(0); second < end; second += width {
+			v, ok := values[operation][second]
+			if !ok && !fill {
+				continue
+			}
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x(second), y(v)))
+		}
+		c.Lines = append(c.Lines, chartLine{
+			Name:   operation,
+			Color:  chartColors[n%len(chartColors)],
+			Points: strings.Join(points, " "),
+		})
+	}
+
+	for k := 0; k <= 4; k++ {
+		v := yMax * float64(k) / 4
+		c.YTicks = append(c.YTicks, chartTick{Pos: y(v), Label: formatTick(v)})
+		second := (end - width) * int64(k) / 4
+		c.XTicks = append(c.XTicks, chartTick{Pos: x(second), Label: formatSecond(second)})
+	}
+	return c
+}
+
+// niceCeil rounds v up to 1, 2, or 5 times a power of ten
+func niceCeil(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, step := range []float64{1, 2, 5, 10} {
+		if v <= step*magnitude {
+			return step * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// formatTick formats an axis value without trailing zeros
+func formatTick(v float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", v), "0"), ".")
+}
+
+// htmlTemplate is a self-contained page: inline styles and SVG charts, no
+// scripts or external resources
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.R.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 60em; color: #222; }
+h1 { border-bottom: 2px solid #ddd; padding-bottom: .3em; }
+h2 { margin-top: 1.8em; }
+table { border-collapse: collapse; margin: .5em 0; }
+th, td { border: 1px solid #ddd; padding: .3em .7em; text-align: left; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+th { background: #f4f4f4; }
+.ok { color: #2ca02c; font-weight: bold; }
+.failed { color: #d62728; font-weight: bold; }
+pre { background: #fbeaea; padding: .7em; white-space: pre-wrap; }
+svg text { font-size: 11px; fill: #555; }
+.legend span { margin-right: 1.2em; }
+.swatch { display: inline-block; width: 1em; height: .3em; vertical-align: middle; margin-right: .3em; }
+</style>
+</head>
+<body>
+<h1>{{.R.Title}}</h1>
+<table>
+{{- if .R.RunID}}
+<tr><th>Run ID</th><td>{{.R.RunID}}</td></tr>
+{{- end}}
+<tr><th>Started</th><td>{{.Started}}</td></tr>
+<tr><th>Duration</th><td>{{.Duration}}</td></tr>
+<tr><th>Status</th><td class="{{if .R.Success}}ok{{else}}failed{{end}}">{{.Status}}</td></tr>
+</table>
+{{range .R.Results}}
+<h2>{{.Title}}</h2>
+<table>
+{{- range .Fields}}
+<tr><th>{{.Name}}</th><td class="num">{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{end}}
+{{- if .Charts}}
+<h2>Throughput and Latency</h2>
+{{- if gt .Interval 1}}
+<p>Each point summarizes {{.IntervalText}}; latency points show the worst second.</p>
+{{- end}}
+{{- range .Charts}}
+<h3>{{.Title}}</h3>
+<svg viewBox="0 0 {{$.Width}} {{$.Height}}" width="100%" role="img" aria-label="{{.Title}}">
+{{- range .YTicks}}
+<line x1="{{$.Left}}" x2="{{$.Right}}" y1="{{printf "%.1f" .Pos}}" y2="{{printf "%.1f" .Pos}}" stroke="#eee"/>
+<text x="{{$.Left}}" y="{{printf "%.1f" .Pos}}" dx="-6" dy="4" text-anchor="end">{{.Label}}</text>
+{{- end}}
+{{- range .XTicks}}
+<text x="{{printf "%.1f" .Pos}}" y="{{$.Height}}" dy="-10" text-anchor="middle">{{.Label}}</text>
+{{- end}}
+{{- range .Lines}}
+<polyline fill="none" stroke="{{.Color}}" stroke-width="1.5" points="{{.Points}}"/>
+{{- end}}
+</svg>
+<div class="legend">
+{{- range .Lines}}<span><span class="swatch" style="background: {{.Color}}"></span>{{.Name}}</span>{{end -}}
+</div>
+{{- end}}
+{{- end}}
+{{- if .Operations}}
+<h2>Latency Percentiles (ms)</h2>
+<table>
+<tr><th>Operation</th><th>Count</th><th>Ops/sec</th><th>Avg</th><th>Min</th><th>p50</th><th>p95</th><th>p99</th><th>p99.9</th><th>Max</th></tr>
+{{- range .Operations}}
+<tr><td>{{index . 0}}</td>{{range slice . 1}}<td class="num">{{.}}</td>{{end}}</tr>
+{{- end}}
+</table>
+{{- end}}
+<h2>Errors</h2>
+{{- if .R.Error}}
+<p>The run failed:</p>
+<pre>{{.R.Error}}</pre>
+{{- end}}
+{{- if .Errors}}
+<table>
+<tr><th>Operation</th><th>Errors</th><th>Timeouts</th><th>Failed %</th><th>Peak errors/sec</th></tr>
+{{- range .Errors}}
+<tr><td>{{index . 0}}</td>{{range slice . 1}}<td class="num">{{.}}</td>{{end}}</tr>
+{{- end}}
+</table>
+{{- else if not .R.Error}}
+<p>No operation failed.</p>
+{{- end}}
+{{- if .R.Config}}
+<h2>Configuration</h2>
+<table>
+{{- range .R.Config}}
+<tr><th>--{{.Name}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// WriteHTML renders the report as a self-contained HTML page with SVG
+// charts of throughput and p99 latency over time
+func WriteHTML(w io.Writer, r *Report) error {
+	width := intervalSeconds(r.Series, maxChartIntervals)
+	intervals := timeline(r.Series, width)
+	data := struct {
+		R                          *Report
+		Started, Duration, Status  string
+		Interval                   int64
+		IntervalText               string
+		Charts                     []chart
+		Operations, Errors         [][]string
+		Width, Height, Left, Right int
+	}{
+		R:            r,
+		Started:      r.Started.Format(time.RFC3339),
+		Duration:     r.Duration.Round(time.Second).String(),
+		Status:       r.status(),
+		Interval:     width,
+		IntervalText: (time.Duration(width) * time.Second).String(),
+		Width:        chartWidth,
+		Height:       chartHeight,
+		Left:         chartLeft,
+		Right:        chartWidth - chartRight,
+	}
+	if len(intervals) > 0 {
+		data.Charts = []chart{
+			newChart("Throughput (ops/sec)", intervals, width, true, interval.opsPerSecond),
+			newChart("p99 latency (ms)", intervals, width, false, func(i interval) float64 { return float64(i.P99Us) / 1000 }),
+		}
+	}
+	data.Operations = r.percentileRows()
+	data.Errors = r.errorRows()
+	return htmlTemplate.Execute(w, data)
+}
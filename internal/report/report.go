@@ -0,0 +1,331 @@
+// Package report renders the final report of a run (results, throughput and
+// latency over time, latency percentiles, errors, and the configuration
+// used) as a self-contained HTML page or a Markdown document, so results can
+// be shared without post-processing the YCSB log or the time series.
+package report
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/internal/logger"
+)
+
+// The charts and the Markdown timeline show at most this many intervals;
+// longer runs are summarized over wider intervals
+const (
+	maxChartIntervals    = 300
+	maxTimelineIntervals = 20
+)
+
+// Field is a named value shown in a table
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Section is a titled group of results, e.g. the load or the verification
+type Section struct {
+	Title  string
+	Fields []Field
+}
+
+// Report is everything a report shows
+type Report struct {
+	Title      string
+	RunID      string
+	Started    time.Time
+	Duration   time.Duration
+	Success    bool
+	Error      string // Why the run failed, if it did
+	Results    []Section
+	Operations []logger.OperationStats
+	Series     []logger.TimeSeriesRow // Per-second samples, for the charts
+	Config     []Field                // Flags the run was configured with
+}
+
+// CheckPath fails unless path names a report format: .html or .htm for
+// HTML, .md or .markdown for Markdown
+func CheckPath(path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm", ".md", ".markdown":
+		return nil
+	}
+	return fmt.Errorf("unknown report format %q (use .html or .md)", filepath.Ext(path))
+}
+
+// Write renders the report to path in the format its extension names
+func Write(path string, r *Report) error {
+	if err := CheckPath(path); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		err = WriteMarkdown(file, r)
+	default:
+		err = WriteHTML(file, r)
+	}
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return file.Close()
+}
+
+// interval aggregates the samples of one operation type over consecutive
+// seconds. Its p50 is the ops-weighted mean of the per-second medians, and
+// its p99 and max are those of the worst second, so a spike is never
+// averaged away.
+type interval struct {
+	Second    int64 // First second of the interval
+	Seconds   int64
+	Operation string
+	Ops       int
+	Errors    int64
+	P50Us     float64
+	P99Us     int64
+	MaxUs     int64
+}
+
+// opsPerSecond returns the interval's throughput
+func (i interval) opsPerSecond() float64 {
+	return float64(i.Ops) / float64(i.Seconds)
+}
+
+// intervalSeconds returns the interval width that summarizes rows in at
+// most n intervals
+func intervalSeconds(rows []logger.TimeSeriesRow, n int) int64 {
+	end := endSecond(rows)
+	return max(1, (end+int64(n)-1)/int64(n))
+}
+
+// endSecond returns the second after the last one sampled
+func endSecond(rows []logger.TimeSeriesRow) int64 {
+	var end int64
+	for _, row := range rows {
+		end = max(end, row.Second+1)
+	}
+	return end
+}
+
+// timeline summarizes rows in intervals of width seconds, in order of time
+// then operation type
+func timeline(rows []logger.TimeSeriesRow, width int64) []interval {
+	end := endSecond(rows)
+
+	type key struct {
+		second    int64
+		operation string
+	}
+	byKey := make(map[key]*interval)
+	for _, row := range rows {
+		k := key{row.Second / width * width, row.Operation}
+		i, ok := byKey[k]
+		if !ok {
+			i = &interval{Second: k.second, Seconds: min(width, end-k.second), Operation: row.Operation}
+			byKey[k] = i
+		}
+		i.P50Us = (i.P50Us*float64(i.Ops) + float64(row.P50Us)*float64(row.Ops)) / float64(i.Ops+row.Ops)
+		i.Ops += row.Ops
+		i.Errors += row.Errors
+		i.P99Us = max(i.P99Us, row.P99Us)
+		i.MaxUs = max(i.MaxUs, row.MaxUs)
+	}
+
+	intervals := make([]interval, 0, len(byKey))
+	for _, i := range byKey {
+		intervals = append(intervals, *i)
+	}
+	sort.Slice(intervals, func(a, b int) bool {
+		if intervals[a].Second != intervals[b].Second {
+			return intervals[a].Second < intervals[b].Second
+		}
+		return intervals[a].Operation < intervals[b].Operation
+	})
+	return intervals
+}
+
+// peakErrors returns the most errors of one operation type in one second
+func peakErrors(rows []logger.TimeSeriesRow, operation string) (int64, int64) {
+	var peak, second int64
+	for _, row := range rows {
+		if row.Operation == operation && row.Errors > peak {
+			peak, second = row.Errors, row.Second
+		}
+	}
+	return peak, second
+}
+
+// status describes how the run ended
+func (r *Report) status() string {
+	if r.Success {
+		return "Succeeded"
+	}
+	return "Failed"
+}
+
+// throughput returns the average operations per second of op over the run
+func (r *Report) throughput(op logger.OperationStats) float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(op.Count) / r.Duration.Seconds()
+}
+
+// failedPercent returns the share of op's operations that failed or timed out
+func failedPercent(op logger.OperationStats) float64 {
+	if op.Count == 0 {
+		return 0
+	}
+	return float64(op.Errors+op.Timeouts) * 100 / float64(op.Count)
+}
+
+// percentileRows returns the latency percentile table, one row per
+// operation type
+func (r *Report) percentileRows() [][]string {
+	var rows [][]string
+	for _, op := range r.Operations {
+		rows = append(rows, []string{
+			op.Type, fmt.Sprint(op.Count), formatRate(r.throughput(op)),
+			ms(op.AvgUs), ms(float64(op.MinUs)), ms(float64(op.P50Us)), ms(float64(op.P95Us)),
+			ms(float64(op.P99Us)), ms(float64(op.P999Us)), ms(float64(op.MaxUs)),
+		})
+	}
+	return rows
+}
+
+// errorRows returns the error table, one row per operation type with
+// failed or timed out operations
+func (r *Report) errorRows() [][]string {
+	var rows [][]string
+	for _, op := range r.Operations {
+		if op.Errors == 0 && op.Timeouts == 0 {
+			continue
+		}
+		peak, second := peakErrors(r.Series, op.Type)
+		peakText := "-"
+		if peak > 0 {
+			peakText = fmt.Sprintf("%d at %s", peak, formatSecond(second))
+		}
+		rows = append(rows, []string{
+			op.Type, fmt.Sprint(op.Errors), fmt.Sprint(op.Timeouts),
+			fmt.Sprintf("%.3f", failedPercent(op)), peakText,
+		})
+	}
+	return rows
+}
+
+// ms formats microseconds as milliseconds
+func ms(us float64) string {
+	return fmt.Sprintf("%.2f", us/1000)
+}
+
+// formatSecond formats an offset from the start of the run, e.g. 1h02m05s
+func formatSecond(second int64) string {
+	return (time.Duration(second) * time.Second).String()
+}
+
+// formatRate formats a rate with a precision suiting its magnitude
+func formatRate(v float64) string {
+	if v >= 100 || v == math.Trunc(v) {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.1f", v)
+}
+
+// writeTable writes a Markdown table, escaping the cells
+func writeTable(w io.Writer, header []string, rows [][]string) {
+	escape := func(cells []string) string {
+		escaped := make([]string, len(cells))
+		for i, cell := range cells {
+			cell = strings.ReplaceAll(cell, "|", `\|`)
+			escaped[i] = strings.ReplaceAll(cell, "\n", " ")
+		}
+		return "| " + strings.Join(escaped, " | ") + " |\n"
+	}
+	io.WriteString(w, escape(header))
+	separator := make([]string, len(header))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	io.WriteString(w, "|"+strings.Join(separator, "|")+"|\n")
+	for _, row := range rows {
+		io.WriteString(w, escape(row))
+	}
+}
+
+// WriteMarkdown renders the report as Markdown. Instead of charts, it has a
+// timeline table over at most 20 intervals.
+func WriteMarkdown(w io.Writer, r *Report) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", r.Title)
+	if r.RunID != "" {
+		fmt.Fprintf(&b, "- **Run ID:** %s\n", r.RunID)
+	}
+	fmt.Fprintf(&b, "- **Started:** %s\n", r.Started.Format(time.RFC3339))
+	fmt.Fprintf(&b, "- **Duration:** %v\n", r.Duration.Round(time.Second))
+	fmt.Fprintf(&b, "- **Status:** %s\n", r.status())
+
+	for _, section := range r.Results {
+		fmt.Fprintf(&b, "\n## %s\n\n", section.Title)
+		rows := make([][]string, len(section.Fields))
+		for i, f := range section.Fields {
+			rows[i] = []string{f.Name, f.Value}
+		}
+		writeTable(&b, []string{"Metric", "Value"}, rows)
+	}
+
+	if len(r.Operations) > 0 {
+		b.WriteString("\n## Latency Percentiles (ms)\n\n")
+		writeTable(&b, []string{"Operation", "Count", "Ops/sec", "Avg", "Min", "p50", "p95", "p99", "p99.9", "Max"}, r.percentileRows())
+	}
+
+	if len(r.Series) > 0 {
+		width := intervalSeconds(r.Series, maxTimelineIntervals)
+		fmt.Fprintf(&b, "\n## Timeline (%v intervals)\n\n", time.Duration(width)*time.Second)
+		var rows [][]string
+		for _, i := range timeline(r.Series, width) {
+			rows = append(rows, []string{
+				formatSecond(i.Second), i.Operation, formatRate(i.opsPerSecond()), fmt.Sprint(i.Errors),
+				ms(i.P50Us), ms(float64(i.P99Us)), ms(float64(i.MaxUs)),
+			})
+		}
+		writeTable(&b, []string{"Time", "Operation", "Ops/sec", "Errors", "p50 ms", "p99 ms", "Max ms"}, rows)
+	}
+
+	b.WriteString("\n## Errors\n\n")
+	if r.Error != "" {
+		fmt.Fprintf(&b, "The run failed: `%s`\n", strings.ReplaceAll(r.Error, "`", "'"))
+	}
+	if rows := r.errorRows(); len(rows) > 0 {
+		if r.Error != "" {
+			b.WriteString("\n")
+		}
+		writeTable(&b, []string{"Operation", "Errors", "Timeouts", "Failed %", "Peak errors/sec"}, rows)
+	} else if r.Error == "" {
+		b.WriteString("No operation failed.\n")
+	}
+
+	if len(r.Config) > 0 {
+		b.WriteString("\n## Configuration\n\n")
+		rows := make([][]string, len(r.Config))
+		for i, f := range r.Config {
+			rows[i] = []string{"--" + f.Name, f.Value}
+		}
+		writeTable(&b, []string{"Flag", "Value"}, rows)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,134 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/internal/logger"
+)
+
+// testReport has an insert every second and reads failing in second 2
+func testReport() *Report {
+	var series []logger.TimeSeriesRow
+	for second := int64(0); second < 4; second++ {
+		series = append(series, logger.TimeSeriesRow{Second: second, Operation: "INSERT", Ops: 100, P50Us: 1000, P99Us: 2000 * (second + 1), MaxUs: 9000})
+	}
+	series = append(series, logger.TimeSeriesRow{Second: 2, Operation: "READ", Ops: 10, Errors: 4, P50Us: 500, P99Us: 700, MaxUs: 800})
+
+	return &Report{
+		Title:    "gendata load report",
+		RunID:    "run-1",
+		Started:  time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Duration: 4 * time.Second,
+		Success:  true,
+		Results: []Section{{Title: "Load", Fields: []Field{
+			{Name: "documents_written", Value: "400"},
+		}}},
+		Operations: []logger.OperationStats{
+			{Type: "INSERT", Count: 400, AvgUs: 1500, MinUs: 100, P50Us: 1000, P95Us: 5000, P99Us: 8000, P999Us: 8000, MaxUs: 9000},
+			{Type: "READ", Count: 10, Errors: 3, Timeouts: 1, AvgUs: 600, MinUs: 500, P50Us: 500, P95Us: 700, P99Us: 700, P999Us: 700, MaxUs: 800},
+		},
+		Series: series,
+		Config: []Field{{Name: "size", Value: "1GB"}, {Name: "tag-fields", Value: "a|<b>"}},
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	r := testReport()
+	intervals := timeline(r.Series, 2)
+	if len(intervals) != 3 {
+		t.Fatalf("expected 3 intervals, got %+v", intervals)
+	}
+
+	first := intervals[0]
+	if first.Second != 0 || first.Operation != "INSERT" || first.Ops != 200 || first.opsPerSecond() != 100 || first.P99Us != 4000 {
+		t.Errorf("unexpected first interval %+v", first)
+	}
+	read := intervals[2]
+	if read.Second != 2 || read.Operation != "READ" || read.Errors != 4 || read.opsPerSecond() != 5 {
+		t.Errorf("unexpected read interval %+v", read)
+	}
+
+	if width := intervalSeconds(r.Series, 3); width != 2 {
+		t.Errorf("expected 2-second intervals for 4 seconds in 3, got %d", width)
+	}
+}
+
+func TestNiceCeil(t *testing.T) {
+	for v, want := range map[float64]float64{0: 1, 0.3: 0.5, 1: 1, 1.2: 2, 3: 5, 7: 10, 4200: 5000} {
+		if got := niceCeil(v); got != want {
+			t.Errorf("niceCeil(%v) = %v, want %v", v, got, want)
+		}
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, testReport()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# gendata load report",
+		"- **Status:** Succeeded",
+		"| documents_written | 400 |",
+		"| INSERT | 400 | 100 | 1.50 | 0.10 | 1.00 | 5.00 | 8.00 | 8.00 | 9.00 |",
+		"| READ | 3 | 1 | 40.000 | 4 at 2s |",
+		`| --tag-fields | a\|<b> |`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	r := testReport()
+	r.Success = false
+	r.Error = "Write error: <boom>"
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`<td class="failed">Failed</td>`,
+		"<pre>Write error: &lt;boom&gt;</pre>",
+		"<h3>Throughput (ops/sec)</h3>",
+		"<polyline",
+		"<td>a|&lt;b&gt;</td>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<script") || strings.Contains(out, "http") {
+		t.Error("expected a self-contained page")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	for name, want := range map[string]string{"report.md": "# gendata", "report.html": "<!DOCTYPE html>"} {
+		path := filepath.Join(dir, name)
+		if err := Write(path, testReport()); err != nil {
+			t.Fatalf("Write(%s): %v", name, err)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(content), want) {
+			t.Errorf("%s: expected it to start with %q", name, want)
+		}
+	}
+
+	if err := CheckPath("report.txt"); err == nil {
+		t.Error("expected .txt to be rejected")
+	}
+}
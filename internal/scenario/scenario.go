@@ -0,0 +1,122 @@
+// Package scenario reads benchmark scenarios: phases such as a load, a
+// workload, a burst, and a verification, run one after another as gendata
+// commands so that a whole benchmark plan is reproducible from one file.
+package scenario
+
+import (
+	"fmt"
+
+	"github.com/meticulous-dft/mongodb-data-generator/internal/config"
+)
+
+// Scenario is a sequence of phases with the settings they share
+type Scenario struct {
+	// Settings are flag values applied to every phase whose command
+	// accepts them; the phase's own values override them
+	Settings map[string]interface{}
+	Phases   []Phase
+}
+
+// Phase is one command of a scenario with its flag values
+type Phase struct {
+	Name    string // Unique; "<n>-<command>" unless given
+	Command string
+	Values  map[string]interface{}
+}
+
+// Accepts reports whether command accepts the flag name, and whether
+// command exists at all
+type Accepts func(command, name string) (accepted, known bool)
+
+// Load reads the scenario file at path, YAML or TOML like config files:
+// top-level keys are settings, and a phases list holds one table per phase
+// with its command, optional name, and flag values
+func Load(path string, accepts Accepts) (*Scenario, error) {
+	values, err := config.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := Parse(values, accepts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Parse builds a scenario from decoded values and checks every phase's
+// command and flags with accepts, so that a typo fails before the first
+// phase runs rather than hours into the scenario
+func Parse(values map[string]interface{}, accepts Accepts) (*Scenario, error) {
+	tables, err := config.Tables(values["phases"])
+	if err != nil {
+		return nil, fmt.Errorf("phases: %w", err)
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("no phases")
+	}
+
+	s := &Scenario{Settings: make(map[string]interface{})}
+	for key, value := range values {
+		if key != "phases" {
+			s.Settings[key] = value
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i, table := range tables {
+		command, _ := table["command"].(string)
+		if _, known := accepts(command, ""); !known {
+			return nil, fmt.Errorf("phase %d: unknown command %q", i+1, command)
+		}
+		name, _ := table["name"].(string)
+		if name == "" {
+			name = fmt.Sprintf("%d-%s", i+1, command)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate phase name %q", name)
+		}
+		seen[name] = true
+
+		phase := Phase{Name: name, Command: command, Values: make(map[string]interface{})}
+		for key, value := range table {
+			if key == "command" || key == "name" {
+				continue
+			}
+			if accepted, _ := accepts(command, key); !accepted {
+				return nil, fmt.Errorf("phase %s: %s does not accept %q", name, command, key)
+			}
+			phase.Values[key] = value
+		}
+		s.Phases = append(s.Phases, phase)
+	}
+
+	// Every setting must apply to some phase
+	for key := range s.Settings {
+		used := false
+		for _, phase := range s.Phases {
+			if accepted, _ := accepts(phase.Command, key); accepted {
+				used = true
+				break
+			}
+		}
+		if !used {
+			return nil, fmt.Errorf("setting %q is accepted by none of the phases", key)
+		}
+	}
+	return s, nil
+}
+
+// Values returns the flag values of phase: the settings its command accepts,
+// overridden by the phase's own values
+func (s *Scenario) Values(phase Phase, accepts Accepts) map[string]interface{} {
+	values := make(map[string]interface{}, len(s.Settings)+len(phase.Values))
+	for key, value := range s.Settings {
+		if accepted, _ := accepts(phase.Command, key); accepted {
+			values[key] = value
+		}
+	}
+	for key, value := range phase.Values {
+		values[key] = value
+	}
+	return values
+}
@@ -0,0 +1,79 @@
+package scenario
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// testAccepts knows a load and a run-workload command
+func testAccepts(command, name string) (bool, bool) {
+	flags := map[string][]string{
+		"load":         {"connection", "size", "verify"},
+		"run-workload": {"connection", "duration", "rate", "workload-mix"},
+	}
+	accepted, known := flags[command]
+	return slices.Contains(accepted, name), known
+}
+
+func TestLoad(t *testing.T) {
+	content := `connection: mongodb://localhost
+size: 500GB
+phases:
+  - command: load
+    verify: true
+  - name: steady
+    command: run-workload
+    duration: 1h
+    rate: 5000
+  - name: burst
+    command: run-workload
+    duration: 10m
+    rate: 20000
+`
+	path := filepath.Join(t.TempDir(), "scenario.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Load(path, testAccepts)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(s.Phases) != 3 {
+		t.Fatalf("expected 3 phases, got %d", len(s.Phases))
+	}
+	if s.Phases[0].Name != "1-load" || s.Phases[1].Name != "steady" || s.Phases[2].Name != "burst" {
+		t.Errorf("got phase names %s, %s, %s", s.Phases[0].Name, s.Phases[1].Name, s.Phases[2].Name)
+	}
+
+	load := s.Values(s.Phases[0], testAccepts)
+	if load["size"] != "500GB" || load["connection"] != "mongodb://localhost" || load["verify"] != true {
+		t.Errorf("got load values %v", load)
+	}
+	burst := s.Values(s.Phases[2], testAccepts)
+	if _, ok := burst["size"]; ok {
+		t.Errorf("expected size to apply only to the load, got %v", burst)
+	}
+	if burst["rate"] != 20000 || burst["connection"] != "mongodb://localhost" {
+		t.Errorf("got burst values %v", burst)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	for name, values := range map[string]map[string]interface{}{
+		"no phases":       {"size": "1GB"},
+		"unknown command": {"phases": []interface{}{map[string]interface{}{"command": "explode"}}},
+		"unknown flag":    {"phases": []interface{}{map[string]interface{}{"command": "load", "rate": 10}}},
+		"unused setting":  {"rate": 10, "phases": []interface{}{map[string]interface{}{"command": "load"}}},
+		"duplicate name": {"phases": []interface{}{
+			map[string]interface{}{"command": "load", "name": "a"},
+			map[string]interface{}{"command": "load", "name": "a"},
+		}},
+	} {
+		if _, err := Parse(values, testAccepts); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+// Package spec describes an experiment (document shape, load rates, workload
+// phases, and injected faults) as a portable JSON document. Sizes are in
+// bytes and durations in seconds so that other tools can consume a spec
+// without knowing this tool's flag syntax.
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Version is the spec format version written by this package
+const Version = 1
+
+// Spec is the effective workload of a run
+type Spec struct {
+	Version   int       `json:"version"`
+	Tool      string    `json:"tool"`
+	Mode      string    `json:"mode"` // load or workload
+	Target    Target    `json:"target"`
+	Documents Documents `json:"documents"`
+	Load      *Load     `json:"load,omitempty"`
+	Workload  *Workload `json:"workload,omitempty"`
+	Faults    *Faults   `json:"faults,omitempty"`
+}
+
+// Target names the collections a run writes to or reads from
+type Target struct {
+	Database         string `json:"database"`
+	Collection       string `json:"collection"`
+	OrdersCollection string `json:"orders_collection,omitempty"`
+	Clustered        bool   `json:"clustered,omitempty"` // Clustered by _id
+	Collation        string `json:"collation,omitempty"` // locale[,option=value...]
+	ShardKey         string `json:"shard_key,omitempty"` // field or field:hashed
+	PresplitChunks   int    `json:"presplit_chunks,omitempty"`
+	PauseBalancer    bool   `json:"pause_balancer,omitempty"`
+}
+
+// Documents describes the generated documents and their key distribution
+type Documents struct {
+	Template           string           `json:"template"`
+	TimeRange          string           `json:"time_range,omitempty"`        // Window of all timestamps, START/END
+	TimeDistribution   string           `json:"time_distribution,omitempty"` // uniform or recent
+	OrderedTimes       bool             `json:"ordered_times,omitempty"`     // created_at in insertion order over TimeRange
+	Fields             []string         `json:"fields"`
+	SizeBytes          int              `json:"size_bytes"`
+	Padding            string           `json:"padding"` // random, base64, binary, or corpus
+	Checksum           bool             `json:"checksum"`
+	ExactSize          bool             `json:"exact_size,omitempty"`           // Padded to within 16 bytes of size_bytes
+	BSONTypes          bool             `json:"bson_types,omitempty"`           // bson_types subdocument with every BSON type
+	OrdersPerCustomer  string           `json:"orders_per_customer,omitempty"`  // N or MIN-MAX; scales with size when empty
+	LineItemsPerOrder  string           `json:"line_items_per_order,omitempty"` // N or MIN-MAX; scales with size when empty
+	NestingDepth       int              `json:"nesting_depth,omitempty"`
+	Cardinality        map[string]int64 `json:"cardinality,omitempty"`        // Distinct values per field path; 0 = unique
+	Sparsity           string           `json:"sparsity,omitempty"`           // field=P or field=P:null, comma-separated
+	FieldValues        string           `json:"field_values,omitempty"`       // YAML or JSON file of weighted field values
+	FieldTemplates     string           `json:"field_templates,omitempty"`    // YAML or JSON file of field templates
+	LegacyFraction     float64          `json:"legacy_fraction,omitempty"`    // Documents in the template's legacy schema
+	Locales            []string         `json:"locales,omitempty"`            // Of customer names, addresses, and notes, picked per document
+	CoherentAddresses  bool             `json:"coherent_addresses,omitempty"` // Cities, regions, postal codes, phones, and currencies of one country
+	NoPII              bool             `json:"no_pii,omitempty"`             // Synthetic tokens instead of realistic personal data
+	Tenants            []string         `json:"tenants,omitempty"`            // Assigned uniformly at random
+	RunTags            []string         `json:"run_tags,omitempty"`           // Stamps added to metadata: run_id, generated_at
+	KeySpaceFrom       string           `json:"key_space_from,omitempty"`
+	ProductCatalogSize int              `json:"product_catalog_size"` // Product keys are drawn uniformly from the catalog
+}
+
+// Load describes the bulk load phase
+type Load struct {
+	TargetBytes          int64   `json:"target_bytes"`
+	TargetMetric         string  `json:"target_metric,omitempty"` // "bytes" when empty
+	SizePollSeconds      float64 `json:"size_poll_seconds,omitempty"`
+	Workers              int     `json:"workers"`
+	Writers              int     `json:"writers"`
+	BatchSize            int     `json:"batch_size"`
+	BatchBytes           int64   `json:"batch_bytes,omitempty"` // Byte bound of a batch (0 = count only)
+	BufferDocs           int     `json:"buffer_docs"`
+	AdaptiveBuffer       bool    `json:"adaptive_buffer,omitempty"`
+	MarshalWorkers       int     `json:"marshal_workers,omitempty"`
+	ConnectionMode       string  `json:"connection_mode,omitempty"` // "shared" when empty
+	MaxPoolSize          int     `json:"max_pool_size,omitempty"`
+	MinPoolSize          int     `json:"min_pool_size,omitempty"`
+	WriteMode            string  `json:"write_mode,omitempty"` // "insertMany" when empty
+	Ordered              bool    `json:"ordered,omitempty"`
+	LoadProfile          string  `json:"load_profile,omitempty"`
+	Clients              int     `json:"clients,omitempty"`
+	ClientBatchSize      int     `json:"client_batch_size,omitempty"`
+	ThinkTimeSeconds     float64 `json:"think_time_seconds,omitempty"`
+	ThinkJitter          string  `json:"think_jitter,omitempty"`
+	ChurnRate            int     `json:"churn_rate,omitempty"` // Deletes per second
+	ChurnKeepBytes       int64   `json:"churn_keep_bytes,omitempty"`
+	DuplicateRatio       float64 `json:"duplicate_ratio,omitempty"`
+	DuplicateMode        string  `json:"duplicate_mode,omitempty"`
+	GrowSteps            int     `json:"grow_steps,omitempty"` // Updates growing each document; 0 = inserted full
+	MaxRetries           int     `json:"max_retries"`
+	RetryBackoffSeconds  float64 `json:"retry_backoff_seconds"`
+	InsertTimeoutSeconds float64 `json:"insert_timeout_seconds,omitempty"`
+	ExactlyOnce          bool    `json:"exactly_once,omitempty"`
+
+	MaxReplicationLagSeconds float64 `json:"max_replication_lag_seconds,omitempty"` // Inserts pause beyond this lag
+
+	Sympathetic *Sympathetic `json:"sympathetic,omitempty"`
+
+	AutoTune *AutoTune `json:"auto_tune,omitempty"`
+
+	Encryption *Encryption `json:"encryption,omitempty"`
+
+	SteadyState *SteadyState `json:"steady_state,omitempty"`
+
+	Maintain *Maintain `json:"maintain,omitempty"`
+
+	Collections *Collections `json:"collections,omitempty"`
+
+	Materialize *Materialize `json:"materialize,omitempty"`
+}
+
+// Materialize describes the views created over the loaded collection and the
+// summary collections refreshed once the load reached its target
+type Materialize struct {
+	Views           bool     `json:"views,omitempty"`
+	IntervalSeconds float64  `json:"interval_seconds,omitempty"` // 0 = no summary collections
+	Stage           string   `json:"stage,omitempty"`            // merge or out
+	Queries         []string `json:"queries,omitempty"`          // Aggregation benchmark queries; empty = all that apply
+}
+
+// AutoTune describes the calibration bursts the load starts with, whose best
+// writer count and batch size override Writers and BatchSize
+type AutoTune struct {
+	Writers           []int   `json:"writers"`
+	BatchSizes        []int   `json:"batch_sizes"`
+	BurstSeconds      float64 `json:"burst_seconds"`
+	MaxLatencySeconds float64 `json:"max_latency_seconds"` // Bound on the p95 batch latency; 0 = none
+}
+
+// Collections describes the many collections insert batches are spread over
+type Collections struct {
+	Count        int    `json:"count"`
+	Template     string `json:"template"`     // {n} is the collection number
+	Distribution string `json:"distribution"` // round-robin or zipfian
+}
+
+// Maintain describes how the collection is kept at its target size once the
+// load reached it
+type Maintain struct {
+	IntervalSeconds float64 `json:"interval_seconds"`
+	Tolerance       float64 `json:"tolerance"` // Fraction of the target
+}
+
+// SteadyState describes the mutations applied to the loaded documents once
+// the load reached its target
+type SteadyState struct {
+	DurationSeconds float64            `json:"duration_seconds"` // 0 = until interrupted
+	Rate            int                `json:"rate"`             // Operations per second; 0 = unlimited
+	Threads         int                `json:"threads"`
+	Mix             map[string]float64 `json:"mix"` // Operation type to percentage
+	UpdateFields    []string           `json:"update_fields,omitempty"`
+	PushCap         int                `json:"push_cap,omitempty"`
+	ScanLength      string             `json:"scan_length,omitempty"` // N or MIN-MAX; "1-100" when empty
+	OpThinkSeconds  float64            `json:"op_think_seconds,omitempty"`
+	ThinkJitter     string             `json:"think_jitter,omitempty"` // "fixed" when empty
+}
+
+// Sympathetic describes the server pressure at which inserts back off
+type Sympathetic struct {
+	PollSeconds    float64 `json:"poll_seconds"`
+	MaxCacheDirty  float64 `json:"max_cache_dirty"`  // Percent of the WiredTiger cache
+	MinFreeTickets float64 `json:"min_free_tickets"` // Fraction of write tickets available
+}
+
+// Encryption describes the automatic client-side encryption of inserted
+// fields. Key files and credentials are not part of the spec.
+type Encryption struct {
+	Mode        string   `json:"mode"` // csfle or qe
+	Fields      []string `json:"fields"`
+	Equality    bool     `json:"equality,omitempty"` // Deterministic or equality-indexed instead of random
+	KMSProvider string   `json:"kms_provider"`
+	MasterKey   string   `json:"master_key,omitempty"` // key=value pairs locating a cloud KMS master key
+}
+
+// Workload describes an operation mix run against loaded data
+type Workload struct {
+	ReadOnly                bool               `json:"read_only"`
+	DurationSeconds         float64            `json:"duration_seconds"`
+	Threads                 int                `json:"threads"`
+	Rate                    int                `json:"rate,omitempty"`   // Operations per second; 0 = unlimited
+	Mix                     map[string]float64 `json:"mix"`              // Operation type to percentage
+	Phases                  []Phase            `json:"phases,omitempty"` // Overrides Mix when present
+	LoadProfile             string             `json:"load_profile,omitempty"`
+	TouchRate               int                `json:"touch_rate,omitempty"`
+	OpThinkSeconds          float64            `json:"op_think_seconds,omitempty"`
+	ThinkJitter             string             `json:"think_jitter,omitempty"` // "fixed" when empty
+	UpdateFields            []string           `json:"update_fields,omitempty"`
+	PushCap                 int                `json:"push_cap,omitempty"`
+	ScanLength              string             `json:"scan_length,omitempty"`     // N or MIN-MAX; "1-100" when empty
+	ReadProjection          string             `json:"read_projection,omitempty"` // "full" when empty
+	WriteMode               string             `json:"write_mode,omitempty"`      // "insertMany" when empty
+	ReadPreference          string             `json:"read_preference,omitempty"` // "primary" when empty
+	CausalConsistency       bool               `json:"causal_consistency,omitempty"`
+	Sessions                int                `json:"sessions,omitempty"`
+	InsertTimeoutSeconds    float64            `json:"insert_timeout_seconds,omitempty"`
+	QueryTimeoutSeconds     float64            `json:"query_timeout_seconds,omitempty"`
+	AggregateTimeoutSeconds float64            `json:"aggregate_timeout_seconds,omitempty"`
+	RYOW                    *RYOW              `json:"ryow,omitempty"`
+}
+
+// RYOW describes the read-your-own-write checks of a workload's inserts
+type RYOW struct {
+	Sample              float64 `json:"sample"`                          // Fraction of inserts re-read from a secondary
+	MaxStalenessSeconds float64 `json:"max_staleness_seconds,omitempty"` // 0 = any secondary
+	TimeoutSeconds      float64 `json:"timeout_seconds"`
+}
+
+// Phase pins the operation mix at an offset from the start of the workload;
+// the mix is interpolated linearly between phases
+type Phase struct {
+	OffsetSeconds float64            `json:"offset_seconds"`
+	Mix           map[string]float64 `json:"mix"`
+}
+
+// Faults describes client-side fault injection for insert batches
+type Faults struct {
+	DelayRatio      float64 `json:"delay_ratio"`
+	MaxDelaySeconds float64 `json:"max_delay_seconds"`
+	DuplicateRatio  float64 `json:"duplicate_ratio"`
+	FailRatio       float64 `json:"fail_ratio"`
+	ResetRatio      float64 `json:"reset_ratio"`
+	DropRatio       float64 `json:"drop_ratio"`
+	OversizeRatio   float64 `json:"oversize_ratio,omitempty"`
+}
+
+// Write saves the spec as indented JSON
+func Write(path string, s *Spec) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode spec: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write spec: %w", err)
+	}
+	return nil
+}
+
+// Read loads a spec and rejects versions newer than this package understands
+func Read(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec: %w", err)
+	}
+	var s Spec
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse spec %s: %w", path, err)
+	}
+	if s.Version < 1 || s.Version > Version {
+		return nil, fmt.Errorf("unsupported spec version %d (supported: 1-%d)", s.Version, Version)
+	}
+	return &s, nil
+}
@@ -0,0 +1,55 @@
+package spec
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.json")
+	want := &Spec{
+		Version: Version,
+		Tool:    "gendata",
+		Mode:    "workload",
+		Target:  Target{Database: "testdb", Collection: "customers"},
+		Documents: Documents{
+			Template:           "customer",
+			Fields:             []string{"_id", "customer_id"},
+			SizeBytes:          4096,
+			Padding:            "random",
+			ProductCatalogSize: 10000,
+		},
+		Workload: &Workload{
+			DurationSeconds: 600,
+			Threads:         32,
+			Mix:             map[string]float64{"read": 95, "aggregate": 5},
+			Phases: []Phase{
+				{OffsetSeconds: 0, Mix: map[string]float64{"insert": 80, "read": 20}},
+				{OffsetSeconds: 7200, Mix: map[string]float64{"insert": 20, "read": 80}},
+			},
+		},
+	}
+
+	if err := Write(path, want); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	got, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip changed the spec:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestReadRejectsNewerVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.json")
+	if err := os.WriteFile(path, []byte(`{"version": 99, "mode": "load"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Read(path); err == nil {
+		t.Error("expected an error for an unsupported version")
+	}
+}
@@ -0,0 +1,78 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ArraySizes summarizes the element counts of an array field over sampled
+// documents, such as the orders that pushes grow
+type ArraySizes struct {
+	Field   string
+	Sampled int // Documents sampled, with or without the array
+	Min     int
+	Max     int
+	Mean    float64
+	AtCap   int // Documents whose array holds Cap elements (or more)
+	Cap     int // 0 = not checked
+}
+
+// NewArraySizes prepares a summary of field, counting the documents whose
+// array reached limit (0 = none)
+func NewArraySizes(field string, limit int) *ArraySizes {
+	return &ArraySizes{Field: field, Cap: limit}
+}
+
+// Add counts the elements of the array of doc; a missing array counts as empty
+func (a *ArraySizes) Add(doc bson.Raw) {
+	n := 0
+	if array, ok := doc.Lookup(a.Field).ArrayOK(); ok {
+		if values, err := array.Values(); err == nil {
+			n = len(values)
+		}
+	}
+	if a.Sampled == 0 || n < a.Min {
+		a.Min = n
+	}
+	a.Max = max(a.Max, n)
+	a.Mean += (float64(n) - a.Mean) / float64(a.Sampled+1)
+	a.Sampled++
+	if a.Cap > 0 && n >= a.Cap {
+		a.AtCap++
+	}
+}
+
+// String describes the sizes, like "orders: 5-20 elements, 12.4 on average
+// over 1000 sampled documents"
+func (a *ArraySizes) String() string {
+	s := fmt.Sprintf("%s: %d-%d elements, %.1f on average over %d sampled documents", a.Field, a.Min, a.Max, a.Mean, a.Sampled)
+	if a.Cap > 0 {
+		s += fmt.Sprintf(", %d at the cap of %d", a.AtCap, a.Cap)
+	}
+	return s
+}
+
+// SampleArraySizes summarizes the sizes of the field arrays of a random
+// sample of sampleSize documents
+func SampleArraySizes(ctx context.Context, collection *mongo.Collection, field string, limit, sampleSize int) (*ArraySizes, error) {
+	cursor, err := collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$sample", Value: bson.D{{Key: "size", Value: sampleSize}}}},
+		{{Key: "$project", Value: bson.D{{Key: field, Value: 1}}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample documents: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	sizes := NewArraySizes(field, limit)
+	for cursor.Next(ctx) {
+		sizes.Add(cursor.Current)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to sample documents: %w", err)
+	}
+	return sizes, nil
+}
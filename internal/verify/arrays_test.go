@@ -0,0 +1,29 @@
+package verify
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestArraySizes(t *testing.T) {
+	sizes := NewArraySizes("orders", 3)
+	for _, n := range []int{2, 3, 0, 5} {
+		doc := bson.D{{Key: "customer_id", Value: "c"}}
+		if n > 0 {
+			doc = append(doc, bson.E{Key: "orders", Value: make(bson.A, n)})
+		}
+		raw, err := bson.Marshal(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes.Add(raw)
+	}
+	if sizes.Sampled != 4 || sizes.Min != 0 || sizes.Max != 5 || sizes.Mean != 2.5 || sizes.AtCap != 2 {
+		t.Errorf("Unexpected sizes %+v", sizes)
+	}
+	want := "orders: 0-5 elements, 2.5 on average over 4 sampled documents, 2 at the cap of 3"
+	if got := sizes.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
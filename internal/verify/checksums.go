@@ -0,0 +1,112 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxReportedMismatches bounds the _ids listed in a checksum report
+const maxReportedMismatches = 20
+
+// ChecksumReport holds the results of a checksum verification
+type ChecksumReport struct {
+	Checked       int64
+	Mismatched    int64
+	Missing       int64         // Documents without a checksum field
+	Legacy        int64         // Legacy schema documents, which have no checksum
+	MismatchedIDs []interface{} // First mismatched _ids
+	Duration      time.Duration
+}
+
+// OK reports whether every checked document matched its checksum
+func (r *ChecksumReport) OK() bool {
+	return r.Mismatched == 0 && r.Missing == 0
+}
+
+// VerifyChecksums re-reads documents and recomputes their checksums. It scans
+// the whole collection, or a random sample of sampleSize documents if
+// sampleSize is positive. progress, if set, is called after every 100,000
+// documents.
+func VerifyChecksums(ctx context.Context, collection *mongo.Collection, sampleSize int, progress func(checked int64)) (*ChecksumReport, error) {
+	start := time.Now()
+
+	var cursor *mongo.Cursor
+	var err error
+	if sampleSize > 0 {
+		cursor, err = collection.Aggregate(ctx, mongo.Pipeline{
+			{{Key: "$sample", Value: bson.D{{Key: "size", Value: sampleSize}}}},
+		})
+	} else {
+		cursor, err = collection.Find(ctx, bson.D{}, options.Find().SetBatchSize(1000))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read documents: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	report := &ChecksumReport{}
+	for cursor.Next(ctx) {
+		report.Checked++
+		if progress != nil && report.Checked%100000 == 0 {
+			progress(report.Checked)
+		}
+
+		// Legacy schema documents have no checksum and may not decode as
+		// CustomerDocument
+		if _, err := cursor.Current.LookupErr("checksum"); err != nil {
+			if _, err := cursor.Current.LookupErr("schema_version"); err == nil {
+				report.Legacy++
+			} else {
+				report.Missing++
+			}
+			continue
+		}
+		var doc model.CustomerDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to decode document: %w", err)
+		}
+
+		sum, err := model.DocumentChecksum(&doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute checksum: %w", err)
+		}
+		if sum != doc.Checksum {
+			report.Mismatched++
+			if len(report.MismatchedIDs) < maxReportedMismatches {
+				report.MismatchedIDs = append(report.MismatchedIDs, doc.ID)
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read documents: %w", err)
+	}
+
+	report.Duration = time.Since(start)
+	return report, nil
+}
+
+// Print writes the report to out
+func (r *ChecksumReport) Print(out io.Writer) {
+	fmt.Fprintf(out, "\n=== Checksum Verification ===\n")
+	fmt.Fprintf(out, "Documents checked: %d\n", r.Checked)
+	fmt.Fprintf(out, "Checksum mismatches: %d\n", r.Mismatched)
+	fmt.Fprintf(out, "Documents without checksum: %d\n", r.Missing)
+	if r.Legacy > 0 {
+		fmt.Fprintf(out, "Legacy schema documents (not checksummed): %d\n", r.Legacy)
+	}
+	for _, id := range r.MismatchedIDs {
+		fmt.Fprintf(out, "  - mismatch: _id %v\n", id)
+	}
+	if r.OK() {
+		fmt.Fprintf(out, "Result: OK (%v)\n", r.Duration.Round(time.Millisecond))
+	} else {
+		fmt.Fprintf(out, "Result: FAILED (%v)\n", r.Duration.Round(time.Millisecond))
+	}
+}
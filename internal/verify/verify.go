@@ -0,0 +1,270 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DefaultKeyProbes is the number of written keys probed for existence
+const DefaultKeyProbes = 100
+
+// Config describes what a load is expected to have produced
+type Config struct {
+	Collection    *mongo.Collection
+	Schema        model.Schema
+	KeySpace      model.KeySpace
+	TargetDocSize int64   // Expected average BSON size in bytes, as written
+	SizeTolerance float64 // Allowed relative deviation of the average size (default 0.1)
+	ExpectedCount int64   // Documents the collection should hold
+	SampleSize    int     // Documents sampled for size and field checks (default 1000)
+	KeyProbes     int     // Keys probed for existence (default DefaultKeyProbes)
+	ArrayField    string  // Array whose sizes are reported, see model.PushField ("" = none)
+	ArrayCap      int     // Elements pushes keep in ArrayField (0 = not reported)
+
+	// RunID, if set, is the run tag (metadata.run_id) of which the collection
+	// should hold ExpectedRunCount documents
+	RunID            string
+	ExpectedRunCount int64
+
+	// Keys are business keys (Schema.KeyField) of documents the run wrote,
+	// such as a sample kept by the writer, of which KeyProbes are probed.
+	// Keys the run issued but never wrote must not be among them.
+	Keys []string
+}
+
+// Report holds the verification results
+type Report struct {
+	ExpectedCount    int64
+	ActualCount      int64
+	Instance         int // Partition of a distributed load, whose other instances add documents
+	Instances        int
+	RunID            string
+	ExpectedRunCount int64
+	RunCount         int64 // Documents tagged with RunID
+	SampledDocs      int
+	AverageSize      float64
+	TargetSize       int64
+	MissingFields    map[string]int // Field -> sampled documents missing it
+	ArraySizes       *ArraySizes    // Of Config.ArrayField in the sampled documents
+	KeysProbed       int
+	KeysMissing      int
+	KeyProbesSkipped bool // No index on the key field
+	Discrepancies    []string
+	Duration         time.Duration
+}
+
+// OK reports whether verification found no discrepancies
+func (r *Report) OK() bool {
+	return len(r.Discrepancies) == 0
+}
+
+// Run counts the collection, samples documents to check their average BSON
+// size and required fields, and probes keys of the run's key space, recording
+// every mismatch as a discrepancy
+func Run(ctx context.Context, config Config) (*Report, error) {
+	if config.SizeTolerance <= 0 {
+		config.SizeTolerance = 0.1
+	}
+	if config.SampleSize <= 0 {
+		config.SampleSize = 1000
+	}
+	if config.KeyProbes <= 0 {
+		config.KeyProbes = DefaultKeyProbes
+	}
+
+	start := time.Now()
+	report := &Report{
+		ExpectedCount: config.ExpectedCount,
+		Instance:      config.KeySpace.Instance,
+		Instances:     config.KeySpace.Instances,
+		TargetSize:    config.TargetDocSize,
+		MissingFields: make(map[string]int),
+	}
+
+	count, err := config.Collection.CountDocuments(ctx, bson.D{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count documents: %w", err)
+	}
+	report.ActualCount = count
+	if report.Instances > 1 {
+		// The other instances' documents are not known to this one
+		if count < config.ExpectedCount {
+			report.addf("document count %d, expected at least %d (%+d)", count, config.ExpectedCount, count-config.ExpectedCount)
+		}
+	} else if count != config.ExpectedCount {
+		report.addf("document count %d, expected %d (%+d)", count, config.ExpectedCount, count-config.ExpectedCount)
+	}
+
+	if config.RunID != "" {
+		report.RunID = config.RunID
+		report.ExpectedRunCount = config.ExpectedRunCount
+		report.RunCount, err = config.Collection.CountDocuments(ctx, bson.D{{Key: "metadata.run_id", Value: config.RunID}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to count documents of run %s: %w", config.RunID, err)
+		}
+		if report.RunCount != config.ExpectedRunCount {
+			report.addf("run %s has %d tagged documents, expected %d (%+d)", config.RunID,
+				report.RunCount, config.ExpectedRunCount, report.RunCount-config.ExpectedRunCount)
+		}
+	}
+
+	if err := sampleDocuments(ctx, config, report); err != nil {
+		return nil, err
+	}
+	if len(config.Keys) > 0 && config.Schema.KeyField != "" {
+		if err := probeKeys(ctx, config, report); err != nil {
+			return nil, err
+		}
+	}
+
+	report.Duration = time.Since(start)
+	return report, nil
+}
+
+// sampleDocuments checks the average BSON size and required fields of a random sample
+func sampleDocuments(ctx context.Context, config Config, report *Report) error {
+	cursor, err := config.Collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$sample", Value: bson.D{{Key: "size", Value: config.SampleSize}}}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sample documents: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	if config.ArrayField != "" {
+		report.ArraySizes = NewArraySizes(config.ArrayField, config.ArrayCap)
+	}
+	var totalSize int64
+	for cursor.Next(ctx) {
+		report.SampledDocs++
+		totalSize += int64(len(cursor.Current))
+		if report.ArraySizes != nil {
+			report.ArraySizes.Add(cursor.Current)
+		}
+		for _, field := range config.Schema.Fields {
+			if _, err := cursor.Current.LookupErr(field); err != nil {
+				report.MissingFields[field]++
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("failed to sample documents: %w", err)
+	}
+	if report.SampledDocs == 0 {
+		report.addf("no documents to sample")
+		return nil
+	}
+
+	report.AverageSize = float64(totalSize) / float64(report.SampledDocs)
+	if config.TargetDocSize > 0 {
+		deviation := (report.AverageSize - float64(config.TargetDocSize)) / float64(config.TargetDocSize)
+		if math.Abs(deviation) > config.SizeTolerance {
+			report.addf("average document size %.0f bytes deviates %.1f%% from the %d bytes written",
+				report.AverageSize, deviation*100, config.TargetDocSize)
+		}
+	}
+	for _, field := range config.Schema.Fields {
+		if missing := report.MissingFields[field]; missing > 0 {
+			report.addf("field %s missing in %d of %d sampled documents", field, missing, report.SampledDocs)
+		}
+	}
+	return nil
+}
+
+// probeKeys checks that keys the run wrote exist. Probing needs an index on
+// the key field; without one it is skipped rather than scanning the
+// collection per key.
+func probeKeys(ctx context.Context, config Config, report *Report) error {
+	indexed, err := hasIndexOn(ctx, config.Collection, config.Schema.KeyField)
+	if err != nil {
+		return err
+	}
+	if !indexed {
+		report.KeyProbesSkipped = true
+		return nil
+	}
+	keys := config.Keys
+	if len(keys) > config.KeyProbes {
+		keys = keys[:config.KeyProbes]
+	}
+	for _, key := range keys {
+		n, err := config.Collection.CountDocuments(ctx, bson.D{{Key: config.Schema.KeyField, Value: key}})
+		if err != nil {
+			return fmt.Errorf("failed to probe keys: %w", err)
+		}
+		report.KeysProbed++
+		if n == 0 {
+			report.KeysMissing++
+		}
+	}
+	if report.KeysMissing > 0 {
+		report.addf("%d of %d probed %s keys not found", report.KeysMissing, report.KeysProbed, config.Schema.KeyField)
+	}
+	return nil
+}
+
+// hasIndexOn reports whether an index has field as its first key
+func hasIndexOn(ctx context.Context, collection *mongo.Collection, field string) (bool, error) {
+	cursor, err := collection.Indexes().List(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to list indexes: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		keys, ok := cursor.Current.Lookup("key").DocumentOK()
+		if !ok {
+			continue
+		}
+		if elems, err := keys.Elements(); err == nil && len(elems) > 0 && elems[0].Key() == field {
+			return true, nil
+		}
+	}
+	return false, cursor.Err()
+}
+
+// addf records a discrepancy
+func (r *Report) addf(format string, args ...interface{}) {
+	r.Discrepancies = append(r.Discrepancies, fmt.Sprintf(format, args...))
+}
+
+// Print writes the report to out
+func (r *Report) Print(out io.Writer) {
+	fmt.Fprintf(out, "\n=== Verification ===\n")
+	if r.Instances > 1 {
+		fmt.Fprintf(out, "Documents: %d (expected at least %d, instance %d of %d)\n", r.ActualCount, r.ExpectedCount, r.Instance, r.Instances)
+	} else {
+		fmt.Fprintf(out, "Documents: %d (expected %d)\n", r.ActualCount, r.ExpectedCount)
+	}
+	if r.RunID != "" {
+		fmt.Fprintf(out, "Documents of run %s: %d (expected %d)\n", r.RunID, r.RunCount, r.ExpectedRunCount)
+	}
+	if r.SampledDocs > 0 {
+		fmt.Fprintf(out, "Average size: %.0f bytes over %d sampled documents (written %d)\n",
+			r.AverageSize, r.SampledDocs, r.TargetSize)
+	}
+	if r.ArraySizes != nil && r.ArraySizes.Sampled > 0 {
+		fmt.Fprintf(out, "Array sizes: %s\n", r.ArraySizes)
+	}
+	if r.KeysProbed > 0 {
+		fmt.Fprintf(out, "Keys found: %d of %d probed\n", r.KeysProbed-r.KeysMissing, r.KeysProbed)
+	}
+	if r.KeyProbesSkipped {
+		fmt.Fprintf(out, "Key probes skipped: no index on the key field\n")
+	}
+	if r.OK() {
+		fmt.Fprintf(out, "Result: OK (%v)\n", r.Duration.Round(time.Millisecond))
+		return
+	}
+	fmt.Fprintf(out, "Result: %d discrepancies (%v)\n", len(r.Discrepancies), r.Duration.Round(time.Millisecond))
+	for _, d := range r.Discrepancies {
+		fmt.Fprintf(out, "  - %s\n", d)
+	}
+}
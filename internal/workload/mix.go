@@ -0,0 +1,129 @@
+package workload
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Operation types recorded in the YCSB log
+const (
+	OpRead      = "READ"
+	OpScan      = "SCAN"
+	OpAggregate = "AGGREGATE"
+	OpInsert    = "INSERT"
+	OpUpdate    = "UPDATE"
+	OpPush      = "PUSH"
+	OpLookup    = "LOOKUP"
+	OpDelete    = "DELETE"
+	OpText      = "TEXT"
+	OpSearch    = "SEARCH"
+
+	// OpTouch is recorded by the background toucher, not part of the mix
+	OpTouch = "TOUCH"
+
+	// OpRYOW is recorded by the read-your-own-write checks of INSERTs, not
+	// part of the mix: the time until a secondary returned the document
+	OpRYOW = "RYOW"
+)
+
+// knownOps lists the operation types the runner can execute, and whether
+// each one writes
+var knownOps = map[string]bool{
+	OpRead:      false,
+	OpScan:      false,
+	OpAggregate: false,
+	OpLookup:    false,
+	OpText:      false,
+	OpSearch:    false,
+	OpInsert:    true,
+	OpUpdate:    true,
+	OpPush:      true,
+	OpDelete:    true,
+}
+
+// IsWrite reports whether the operation type modifies data
+func IsWrite(op string) bool {
+	return knownOps[op]
+}
+
+// Mix maps operation types to relative weights
+type Mix map[string]float64
+
+// ParseMix parses a mix like "read=95,aggregate=5"
+func ParseMix(mixStr string) (Mix, error) {
+	mix := make(Mix)
+	for _, part := range strings.Split(mixStr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid mix entry: %s", part)
+		}
+
+		op := strings.ToUpper(strings.TrimSpace(kv[0]))
+		if _, ok := knownOps[op]; !ok {
+			return nil, fmt.Errorf("unknown operation type: %s", kv[0])
+		}
+
+		weight, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid weight for %s: %s", kv[0], kv[1])
+		}
+		mix[op] = weight
+	}
+
+	if mix.total() <= 0 {
+		return nil, fmt.Errorf("operation mix has no positive weights: %s", mixStr)
+	}
+	return mix, nil
+}
+
+// total returns the sum of all weights
+func (m Mix) total() float64 {
+	var total float64
+	for _, w := range m {
+		total += w
+	}
+	return total
+}
+
+// Pick selects an operation type according to the weights
+func (m Mix) Pick(rng *rand.Rand) string {
+	ops := m.Ops()
+	r := rng.Float64() * m.total()
+	for _, op := range ops {
+		r -= m[op]
+		if r < 0 {
+			return op
+		}
+	}
+	return ops[len(ops)-1]
+}
+
+// Ops returns the operation types in a stable order
+func (m Mix) Ops() []string {
+	ops := make([]string, 0, len(m))
+	for op := range m {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	return ops
+}
+
+// String formats the mix as percentages in the syntax ParseMix accepts
+func (m Mix) String() string {
+	total := m.total()
+	parts := make([]string, 0, len(m))
+	for _, op := range m.Ops() {
+		pct := math.Round(m[op]/total*1000) / 10
+		parts = append(parts, strings.ToLower(op)+"="+strconv.FormatFloat(pct, 'f', -1, 64))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,68 @@
+package workload
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestParseMix(t *testing.T) {
+	mix, err := ParseMix("read=95, aggregate=5")
+	if err != nil {
+		t.Fatalf("Failed to parse mix: %v", err)
+	}
+
+	if mix[OpRead] != 95 || mix[OpAggregate] != 5 {
+		t.Errorf("Unexpected mix: %v", mix)
+	}
+	if mix, err := ParseMix("update=70,push=25,delete=5"); err != nil || !IsWrite(OpPush) || mix[OpPush] != 25 {
+		t.Errorf("Unexpected push mix: %v, %v", mix, err)
+	}
+	if mix, err := ParseMix("read=50,scan=50"); err != nil || IsWrite(OpScan) || mix[OpScan] != 50 {
+		t.Errorf("Unexpected scan mix: %v, %v", mix, err)
+	}
+
+	for _, invalid := range []string{"", "read", "read=abc", "write=10", "read=0"} {
+		if _, err := ParseMix(invalid); err == nil {
+			t.Errorf("Expected error for mix %q", invalid)
+		}
+	}
+}
+
+func TestMixPick(t *testing.T) {
+	mix := Mix{OpRead: 1, OpAggregate: 0}
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 100; i++ {
+		if op := mix.Pick(rng); op != OpRead {
+			t.Fatalf("Picked zero-weight operation %s", op)
+		}
+	}
+}
+
+func TestScheduleMixAt(t *testing.T) {
+	schedule, err := ParseSchedule("1h:insert=20,read=80; 0s:insert=80,read=20")
+	if err != nil {
+		t.Fatalf("Failed to parse schedule: %v", err)
+	}
+
+	start := schedule.MixAt(0)
+	if start[OpInsert] != 80 {
+		t.Errorf("Expected start mix insert=80, got %v", start)
+	}
+
+	mid := schedule.MixAt(30 * time.Minute)
+	if math.Abs(mid[OpInsert]-0.5) > 1e-9 || math.Abs(mid[OpRead]-0.5) > 1e-9 {
+		t.Errorf("Expected halfway mix to be even, got %v", mid)
+	}
+
+	end := schedule.MixAt(2 * time.Hour)
+	if end[OpRead] != 80 {
+		t.Errorf("Expected final mix held after last point, got %v", end)
+	}
+
+	if _, err := ParseSchedule("soon:read=1"); err == nil {
+		t.Error("Expected error for invalid offset")
+	}
+}
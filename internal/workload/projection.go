@@ -0,0 +1,105 @@
+package workload
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Projection selects what READ operations return, to measure the benefit of
+// projections and covered queries over fetching whole documents
+type Projection struct {
+	Fields  []string // Only these fields (and _id)
+	Slice   int      // Only the key field and this many elements of the schema's array (negative = the last ones)
+	Covered bool     // Only the field reads filter on, which its index covers
+}
+
+// ParseProjection parses "full" (or ""), "covered", "fields:a,b.c", or
+// "slice:N"
+func ParseProjection(s string) (Projection, error) {
+	kind, arg, _ := strings.Cut(strings.TrimSpace(s), ":")
+	switch kind {
+	case "", "full":
+		if arg != "" {
+			return Projection{}, fmt.Errorf("invalid projection %s", s)
+		}
+		return Projection{}, nil
+	case "covered":
+		if arg != "" {
+			return Projection{}, fmt.Errorf("invalid projection %s", s)
+		}
+		return Projection{Covered: true}, nil
+	case "fields":
+		var fields []string
+		for _, field := range strings.Split(arg, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				fields = append(fields, field)
+			}
+		}
+		if len(fields) == 0 {
+			return Projection{}, fmt.Errorf("projection %s lists no fields", s)
+		}
+		return Projection{Fields: fields}, nil
+	case "slice":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n == 0 {
+			return Projection{}, fmt.Errorf("invalid array slice %q (use a non-zero count, negative for the last elements)", arg)
+		}
+		return Projection{Slice: n}, nil
+	}
+	return Projection{}, fmt.Errorf("unknown projection %s (use full, covered, fields:a,b, or slice:N)", s)
+}
+
+// IsFull reports whether reads return whole documents
+func (p Projection) IsFull() bool {
+	return len(p.Fields) == 0 && p.Slice == 0 && !p.Covered
+}
+
+// Validate checks that the projection applies to documents of schema
+func (p Projection) Validate(schema model.Schema) error {
+	if p.Slice != 0 && schema.ArrayField == "" {
+		return fmt.Errorf("the %s template has no array to slice", schema.Template)
+	}
+	return model.ValidateFieldPaths(schema.Template, p.Fields)
+}
+
+// Document returns the projection of reads filtering on filterField (nil =
+// the full document)
+func (p Projection) Document(schema model.Schema, filterField string) bson.D {
+	switch {
+	case p.Covered:
+		projection := bson.D{{Key: filterField, Value: 1}}
+		if filterField != "_id" {
+			projection = append(projection, bson.E{Key: "_id", Value: 0})
+		}
+		return projection
+	case p.Slice != 0:
+		return bson.D{
+			{Key: schema.KeyField, Value: 1},
+			{Key: schema.ArrayField, Value: bson.D{{Key: "$slice", Value: p.Slice}}},
+		}
+	case len(p.Fields) > 0:
+		projection := make(bson.D, len(p.Fields))
+		for i, field := range p.Fields {
+			projection[i] = bson.E{Key: field, Value: 1}
+		}
+		return projection
+	}
+	return nil
+}
+
+// String formats the projection as ParseProjection accepts it
+func (p Projection) String() string {
+	switch {
+	case p.Covered:
+		return "covered"
+	case p.Slice != 0:
+		return "slice:" + strconv.Itoa(p.Slice)
+	case len(p.Fields) > 0:
+		return "fields:" + strings.Join(p.Fields, ",")
+	}
+	return "full"
+}
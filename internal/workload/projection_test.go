@@ -0,0 +1,68 @@
+package workload
+
+import (
+	"testing"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParseProjection(t *testing.T) {
+	for _, s := range []string{"full", "covered", "fields:name,orders.status", "slice:-5"} {
+		p, err := ParseProjection(s)
+		if err != nil {
+			t.Fatalf("ParseProjection(%s) failed: %v", s, err)
+		}
+		if p.String() != s {
+			t.Errorf("ParseProjection(%s).String() = %s", s, p)
+		}
+	}
+	if p, err := ParseProjection(""); err != nil || !p.IsFull() {
+		t.Errorf("ParseProjection() = %v, %v", p, err)
+	}
+	for _, s := range []string{"partial", "slice:0", "slice:x", "fields:", "covered:1"} {
+		if _, err := ParseProjection(s); err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+	}
+}
+
+func TestProjectionDocument(t *testing.T) {
+	schema := model.CustomerSchema
+	for s, want := range map[string]string{
+		"full":                "[]",
+		"covered":             `{"customer_id": {"$numberInt":"1"},"_id": {"$numberInt":"0"}}`,
+		"slice:3":             `{"customer_id": {"$numberInt":"1"},"orders": {"$slice": {"$numberInt":"3"}}}`,
+		"fields:name,address": `{"name": {"$numberInt":"1"},"address": {"$numberInt":"1"}}`,
+	} {
+		p, _ := ParseProjection(s)
+		doc := p.Document(schema, schema.KeyField)
+		if doc == nil {
+			if want != "[]" {
+				t.Errorf("%s: expected a projection", s)
+			}
+			continue
+		}
+		raw, err := bson.Marshal(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := bson.Raw(raw).String(); got != want {
+			t.Errorf("%s: projection %s, want %s", s, got, want)
+		}
+	}
+
+	covered, _ := ParseProjection("covered")
+	if doc := covered.Document(schema, "_id"); len(doc) != 1 || doc[0].Key != "_id" {
+		t.Errorf("Covered _id reads project %v", doc)
+	}
+	if err := (Projection{Fields: []string{"orders.status"}}).Validate(schema); err != nil {
+		t.Error(err)
+	}
+	if err := (Projection{Fields: []string{"nope"}}).Validate(schema); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+	if err := (Projection{Slice: 1}).Validate(model.EventsSchema); err == nil {
+		t.Error("Expected an error slicing events")
+	}
+}
@@ -0,0 +1,699 @@
+package workload
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/internal/logger"
+	"github.com/meticulous-dft/mongodb-data-generator/internal/pacing"
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/sync/errgroup"
+)
+
+// updateSampleSize is the number of generated documents supplying the
+// values of UpdateFields
+const updateSampleSize = 1000
+
+// Runner executes a weighted mix of operations against an existing collection
+type Runner struct {
+	collection    *mongo.Collection
+	schema        model.Schema
+	threads       int
+	duration      time.Duration
+	mix           Mix
+	schedule      Schedule
+	generator     *model.Generator
+	lookupFrom    string
+	textFields    []string
+	searchIndex   string
+	keySampleSize int
+	keySpace      *model.KeySpace
+	touchRate     int
+	rate          int
+	profile       *pacing.LoadProfile
+	thinkTime     pacing.ThinkTime
+	fieldUpdates  bool
+	updateFields  []string
+	pushCap       int
+	projection    bson.D // Of READ and SCAN (nil = full documents)
+	scanLength    model.CountRange
+	bulkWrite     bool
+	causal        bool
+	sessions      int
+	sessionPool   chan mongo.Session // Idle sessions shared by the threads
+	ycsbLogger    *logger.YCSBLogger
+
+	ryowSample     float64
+	ryowCollection *mongo.Collection
+	ryowTimeout    time.Duration
+	writeChecks    chan writeCheck
+	ryow           RYOWStats
+
+	insertTimeout    time.Duration
+	queryTimeout     time.Duration
+	aggregateTimeout time.Duration
+
+	currentMix    atomic.Value // Mix in effect, refreshed from the schedule
+	updater       *model.FieldUpdater
+	keys          []interface{}
+	terms         []string // Search terms of TEXT and SEARCH operations
+	opsDone       int64
+	opsFailed     int64
+	opsTimeout    int64
+	touched       int64
+	emptySearches int64 // SEARCH operations without results
+	sessionWaits  int64 // Operations that waited for a free session
+	slots         int64 // Operations started under the rate limit
+
+	// startTime holds the time.Time Run started the threads at, read by
+	// GetStats while Run is still setting up
+	startTime atomic.Value
+}
+
+// Config holds workload runner configuration
+type Config struct {
+	Collection    *mongo.Collection
+	Schema        model.Schema
+	Threads       int
+	Duration      time.Duration
+	Mix           Mix
+	Schedule      Schedule         // Optional; overrides Mix and drifts it over the run
+	Generator     *model.Generator // Required for INSERT operations
+	LookupFrom    string           // Referenced orders collection, required for LOOKUP operations
+	TextFields    []string         // Text-indexed fields, required for TEXT and SEARCH operations (see model.TextFields)
+	SearchIndex   string           // Atlas Search index, required for SEARCH operations
+	KeySampleSize int              // Number of existing _ids sampled for point reads
+	KeySpace      *model.KeySpace  // Optional; point operations target this run's keys by KeyField
+	TouchRate     int              // Documents per second whose updated_at is touched in the background (0 = none)
+	Rate          int              // Operations per second across all threads (0 = unlimited)
+	ThinkTime     pacing.ThinkTime // Pause of each thread after each of its operations (zero = none)
+	FieldUpdates  bool             // UPDATE changes template fields (Generator.FieldUpdate), not only updated_at
+	UpdateFields  []string         // Optional; UPDATE sets only these fields (see model.ValidateUpdateFields), overriding FieldUpdates
+	PushCap       int              // Elements PUSH keeps in the array, dropping the oldest (0 = model.PushCap)
+	Projection    Projection       // What READ and SCAN return (zero = full documents)
+	ScanLength    model.CountRange // Documents a SCAN returns, drawn uniformly (zero = DefaultScanLength)
+	BulkWrite     bool             // Send INSERT, UPDATE, PUSH, and DELETE as single-operation bulkWrites
+	Causal        bool             // Run each thread in its own causally consistent session
+	Sessions      int              // Causally consistent sessions shared by the threads, each used by one operation at a time (0 = per thread with Causal)
+	YCSBLogger    *logger.YCSBLogger
+
+	// Profile, when set, varies the rate of operations over the run like
+	// pacing.LoadProfile, overriding Rate
+	Profile *pacing.LoadProfile
+
+	// RYOWSample is the fraction of acknowledged INSERTs re-read from
+	// RYOWCollection, which reads from a secondary, until they are visible
+	// or RYOWTimeout (default 10s) passes (0 = none)
+	RYOWSample     float64
+	RYOWCollection *mongo.Collection
+	RYOWTimeout    time.Duration
+
+	// Per-operation deadlines (0 = none). Queries and aggregations also send
+	// maxTimeMS so the server abandons the work. Timeouts are counted separately.
+	InsertTimeout    time.Duration // INSERT, UPDATE, PUSH, DELETE, and TOUCH
+	QueryTimeout     time.Duration // READ, SCAN, and TEXT
+	AggregateTimeout time.Duration // AGGREGATE, LOOKUP, and SEARCH
+}
+
+// NewRunner creates a new workload runner
+func NewRunner(config Config) *Runner {
+	if config.Threads <= 0 {
+		config.Threads = 16
+	}
+	if config.KeySampleSize <= 0 {
+		config.KeySampleSize = 10000
+	}
+	if config.Mix == nil {
+		config.Mix = Mix{OpRead: 1}
+	}
+	if config.ScanLength.IsZero() {
+		config.ScanLength = DefaultScanLength
+	}
+	if config.RYOWTimeout <= 0 {
+		config.RYOWTimeout = defaultRYOWTimeout
+	}
+
+	r := &Runner{
+		collection:    config.Collection,
+		schema:        config.Schema,
+		threads:       config.Threads,
+		duration:      config.Duration,
+		mix:           config.Mix,
+		schedule:      config.Schedule,
+		generator:     config.Generator,
+		lookupFrom:    config.LookupFrom,
+		textFields:    config.TextFields,
+		searchIndex:   config.SearchIndex,
+		keySampleSize: config.KeySampleSize,
+		keySpace:      config.KeySpace,
+		touchRate:     config.TouchRate,
+		rate:          config.Rate,
+		profile:       config.Profile,
+		thinkTime:     config.ThinkTime,
+		fieldUpdates:  config.FieldUpdates,
+		updateFields:  config.UpdateFields,
+		pushCap:       config.PushCap,
+		scanLength:    config.ScanLength,
+		bulkWrite:     config.BulkWrite,
+		causal:        config.Causal,
+		sessions:      config.Sessions,
+		ycsbLogger:    config.YCSBLogger,
+
+		ryowSample:     config.RYOWSample,
+		ryowCollection: config.RYOWCollection,
+		ryowTimeout:    config.RYOWTimeout,
+		writeChecks:    make(chan writeCheck, ryowQueue),
+
+		insertTimeout:    config.InsertTimeout,
+		queryTimeout:     config.QueryTimeout,
+		aggregateTimeout: config.AggregateTimeout,
+	}
+	filterField := "_id"
+	if r.keySpace != nil {
+		filterField = r.schema.KeyField
+	}
+	r.projection = config.Projection.Document(r.schema, filterField)
+	r.currentMix.Store(r.mix)
+	if len(r.schedule) > 0 {
+		r.currentMix.Store(r.schedule.MixAt(0))
+	}
+	return r
+}
+
+// Run samples keys (and search terms, for TEXT and SEARCH operations) from
+// the collection and then runs the operation mix until the duration elapses
+// or the context is cancelled
+func (r *Runner) Run(ctx context.Context) error {
+	if r.ryowSample > 0 && r.ryowCollection == nil {
+		return fmt.Errorf("read-your-own-write checks require a collection to read from")
+	}
+	if err := r.sampleKeys(ctx); err != nil {
+		return err
+	}
+	ops := append(r.mix.Ops(), r.schedule.Ops()...)
+	if len(r.updateFields) > 0 && slices.Contains(ops, OpUpdate) {
+		if r.generator == nil {
+			return fmt.Errorf("update fields require a document generator")
+		}
+		updater, err := r.generator.NewFieldUpdater(r.updateFields, updateSampleSize)
+		if err != nil {
+			return err
+		}
+		r.updater = updater
+	}
+	if len(r.textFields) > 0 && (slices.Contains(ops, OpText) || slices.Contains(ops, OpSearch)) {
+		if err := r.sampleTerms(ctx); err != nil {
+			return err
+		}
+	}
+
+	if r.duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.duration)
+		defer cancel()
+	}
+
+	if r.sessions > 0 {
+		if err := r.startSessions(); err != nil {
+			return err
+		}
+		defer r.endSessions()
+	}
+
+	r.startTime.Store(time.Now())
+	eg, ctx := errgroup.WithContext(ctx)
+	if len(r.schedule) > 0 {
+		eg.Go(func() error {
+			r.followSchedule(ctx)
+			return nil
+		})
+	}
+	if r.touchRate > 0 {
+		eg.Go(func() error {
+			r.touch(ctx)
+			return nil
+		})
+	}
+	if r.ryowSample > 0 {
+		for i := 0; i < ryowCheckers; i++ {
+			eg.Go(func() error {
+				r.checkWrites(ctx)
+				return nil
+			})
+		}
+	}
+	for i := 0; i < r.threads; i++ {
+		threadID := i
+		eg.Go(func() error {
+			return r.thread(ctx, threadID)
+		})
+	}
+
+	err := eg.Wait()
+	if err == context.DeadlineExceeded {
+		return nil
+	}
+	return err
+}
+
+// followSchedule refreshes the operation mix from the schedule every second
+func (r *Runner) followSchedule(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.currentMix.Store(r.schedule.MixAt(time.Since(r.started())))
+		}
+	}
+}
+
+// CurrentMix returns the operation mix currently in effect
+func (r *Runner) CurrentMix() Mix {
+	return r.currentMix.Load().(Mix)
+}
+
+// sampleKeys loads a random sample of _ids to target point reads
+func (r *Runner) sampleKeys(ctx context.Context) error {
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$sample", Value: bson.D{{Key: "size", Value: r.keySampleSize}}}},
+		{{Key: "$project", Value: bson.D{{Key: "_id", Value: 1}}}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sample keys: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		r.keys = append(r.keys, cursor.Current.Lookup("_id"))
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("failed to sample keys: %w", err)
+	}
+	if len(r.keys) == 0 {
+		return fmt.Errorf("collection %s is empty", r.collection.Name())
+	}
+	return nil
+}
+
+// thread runs operations until the context ends
+func (r *Runner) thread(ctx context.Context, threadID int) error {
+	rng := rand.New(rand.NewSource(time.Now().UnixNano() + int64(threadID)))
+
+	// Reads of a causally consistent session observe the session's earlier
+	// writes, even from secondaries. A shared pool of sessions is used
+	// per operation instead.
+	if r.causal && r.sessions == 0 {
+		session, err := r.collection.Database().Client().StartSession(options.Session().SetCausalConsistency(true))
+		if err != nil {
+			return fmt.Errorf("thread %d: failed to start session: %w", threadID, err)
+		}
+		defer session.EndSession(context.Background())
+		ctx = mongo.NewSessionContext(ctx, session)
+	}
+
+	// Stagger thinking threads so they don't start in lockstep
+	if r.thinkTime.Mean > 0 && !sleepContext(ctx, time.Duration(rng.Int63n(int64(r.thinkTime.Mean)))) {
+		return nil
+	}
+
+	for ctx.Err() == nil {
+		if !r.pace(ctx) {
+			return nil
+		}
+		op := r.CurrentMix().Pick(rng)
+
+		var session mongo.Session
+		opCtx := ctx
+		if r.sessionPool != nil {
+			if session = r.acquireSession(ctx); session == nil {
+				return nil
+			}
+			opCtx = mongo.NewSessionContext(ctx, session)
+		}
+
+		start := time.Now()
+		err := r.execute(opCtx, rng, op)
+		latency := time.Since(start)
+		if session != nil {
+			r.releaseSession(session)
+		}
+
+		if ctx.Err() != nil {
+			// Operations interrupted by the end of the run are not recorded
+			return nil
+		}
+
+		switch {
+		case err == nil:
+			atomic.AddInt64(&r.opsDone, 1)
+		case mongo.IsTimeout(err):
+			atomic.AddInt64(&r.opsTimeout, 1)
+		default:
+			atomic.AddInt64(&r.opsFailed, 1)
+		}
+		if r.ycsbLogger != nil {
+			if err != nil && mongo.IsTimeout(err) {
+				r.ycsbLogger.RecordTimeout(op, latency)
+			} else {
+				r.ycsbLogger.RecordOperation(op, latency, err == nil)
+			}
+		}
+
+		if pause := r.thinkTime.Next(rng); pause > 0 && !sleepContext(ctx, pause) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// sleepContext sleeps for d and reports false if the context ended first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// pace blocks until the next operation may start so that all threads
+// together stay at the configured rate or follow the load profile. It
+// reports false if ctx ended first.
+func (r *Runner) pace(ctx context.Context) bool {
+	if r.profile != nil {
+		slot := float64(atomic.AddInt64(&r.slots, 1) - 1)
+		for {
+			wait := r.profile.Delay(time.Since(r.started()), slot)
+			if wait <= 0 {
+				return true
+			}
+			if !sleepContext(ctx, wait) {
+				return false
+			}
+		}
+	}
+	if r.rate <= 0 {
+		return true
+	}
+	slot := atomic.AddInt64(&r.slots, 1) - 1
+	wait := time.Until(r.started().Add(time.Duration(slot) * time.Second / time.Duration(r.rate)))
+	if wait <= 0 {
+		return true
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// timeoutFor returns the deadline configured for an operation type
+func (r *Runner) timeoutFor(op string) time.Duration {
+	switch op {
+	case OpInsert, OpUpdate, OpPush, OpDelete:
+		return r.insertTimeout
+	case OpRead, OpScan, OpText:
+		return r.queryTimeout
+	case OpAggregate, OpLookup, OpSearch:
+		return r.aggregateTimeout
+	}
+	return 0
+}
+
+// execute runs a single operation of the given type
+func (r *Runner) execute(ctx context.Context, rng *rand.Rand, op string) error {
+	if timeout := r.timeoutFor(op); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	switch op {
+	case OpRead:
+		return r.read(ctx, rng)
+	case OpScan:
+		return r.scan(ctx, rng)
+	case OpAggregate:
+		return r.aggregate(ctx, rng)
+	case OpInsert:
+		return r.insert(ctx, rng)
+	case OpUpdate:
+		return r.update(ctx, rng)
+	case OpPush:
+		return r.push(ctx, rng)
+	case OpLookup:
+		return r.lookup(ctx, rng)
+	case OpDelete:
+		return r.delete(ctx, rng)
+	case OpText:
+		return r.textSearch(ctx, rng)
+	case OpSearch:
+		return r.atlasSearch(ctx, rng)
+	default:
+		return fmt.Errorf("unsupported operation: %s", op)
+	}
+}
+
+// randomKey returns a random sampled _id
+func (r *Runner) randomKey(rng *rand.Rand) interface{} {
+	return r.keys[rng.Intn(len(r.keys))]
+}
+
+// keyFilter selects a random document for a point operation: by business
+// key from the key space if one is set, otherwise by a sampled _id
+func (r *Runner) keyFilter(rng *rand.Rand) bson.D {
+	if r.keySpace != nil {
+		return bson.D{{Key: r.schema.KeyField, Value: r.keySpace.RandomCustomerKey(rng)}}
+	}
+	return bson.D{{Key: "_id", Value: r.randomKey(rng)}}
+}
+
+// read performs a point read by _id, or by key with a key space, returning
+// the configured projection
+func (r *Runner) read(ctx context.Context, rng *rand.Rand) error {
+	opts := options.FindOne().SetMaxTime(r.queryTimeout)
+	if r.projection != nil {
+		opts.SetProjection(r.projection)
+	}
+	err := r.collection.FindOne(ctx, r.keyFilter(rng), opts).Err()
+	if err == mongo.ErrNoDocuments {
+		// Document removed since sampling; treat like YCSB NOT_FOUND, not an error
+		return nil
+	}
+	return err
+}
+
+// aggregate groups a small range of documents by the schema's group field
+func (r *Runner) aggregate(ctx context.Context, rng *rand.Rand) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "_id", Value: bson.D{{Key: "$gte", Value: r.randomKey(rng)}}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: 100}},
+	}
+	if r.schema.ArrayField != "" {
+		pipeline = append(pipeline, bson.D{{Key: "$unwind", Value: "$" + r.schema.ArrayField}})
+	}
+	if r.schema.GroupField != "" {
+		pipeline = append(pipeline, bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$" + r.schema.GroupField},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}})
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline,
+		options.Aggregate().SetBatchSize(1000).SetMaxTime(r.aggregateTimeout))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+	}
+	return cursor.Err()
+}
+
+// lookup joins a random customer with its orders from the referenced collection
+func (r *Runner) lookup(ctx context.Context, rng *rand.Rand) error {
+	if r.lookupFrom == "" {
+		return fmt.Errorf("LOOKUP requires a referenced orders collection")
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: r.keyFilter(rng)}},
+		{{Key: "$project", Value: bson.D{{Key: r.schema.KeyField, Value: 1}}}},
+		{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: r.lookupFrom},
+			{Key: "localField", Value: r.schema.KeyField},
+			{Key: "foreignField", Value: r.schema.KeyField},
+			{Key: "as", Value: "orders"},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline, options.Aggregate().SetMaxTime(r.aggregateTimeout))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+	}
+	return cursor.Err()
+}
+
+// insert writes one newly generated document
+func (r *Runner) insert(ctx context.Context, rng *rand.Rand) error {
+	if r.generator == nil {
+		return fmt.Errorf("INSERT requires a document generator")
+	}
+
+	doc, err := r.generator.GenerateDocument()
+	if err != nil {
+		return err
+	}
+	err = r.write(ctx, mongo.NewInsertOneModel().SetDocument(doc), func() error {
+		_, err := r.collection.InsertOne(ctx, doc)
+		return err
+	})
+	if err == nil {
+		r.sampleWrite(rng, doc.DocumentID())
+	}
+	return err
+}
+
+// update touches a random existing document. Conversations instead get a
+// new message pushed or all their messages marked read, half of the time each.
+// With field updates, template fields change as well (Generator.FieldUpdate).
+func (r *Runner) update(ctx context.Context, rng *rand.Rand) error {
+	update := bson.D{
+		{Key: "$set", Value: bson.D{{Key: "updated_at", Value: time.Now()}}},
+		{Key: "$inc", Value: bson.D{{Key: "revision", Value: 1}}},
+	}
+	switch {
+	case r.updater != nil:
+		update = r.updater.Update(rng)
+	case r.generator == nil:
+	case r.fieldUpdates:
+		update = r.generator.FieldUpdate()
+	case r.schema.Template == model.TemplateMessages:
+		update = r.generator.ConversationUpdate(rng.Intn(2) == 0)
+	}
+	return r.updateOne(ctx, r.keyFilter(rng), update)
+}
+
+// push appends a newly generated element to the main array of a random
+// existing document, see Generator.PushUpdate
+func (r *Runner) push(ctx context.Context, rng *rand.Rand) error {
+	if r.generator == nil {
+		return fmt.Errorf("PUSH requires a document generator")
+	}
+	limit := r.pushCap
+	if limit <= 0 {
+		limit = model.PushCap(r.schema.Template)
+	}
+	update, err := r.generator.CappedPushUpdate(limit)
+	if err != nil {
+		return err
+	}
+	return r.updateOne(ctx, r.keyFilter(rng), update)
+}
+
+// updateOne applies update to the document matching filter
+func (r *Runner) updateOne(ctx context.Context, filter, update bson.D) error {
+	return r.write(ctx, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update), func() error {
+		_, err := r.collection.UpdateOne(ctx, filter, update)
+		return err
+	})
+}
+
+// delete removes a random existing document. Deleting a document that is
+// already gone matches nothing and is not an error.
+func (r *Runner) delete(ctx context.Context, rng *rand.Rand) error {
+	filter := r.keyFilter(rng)
+	return r.write(ctx, mongo.NewDeleteOneModel().SetFilter(filter), func() error {
+		_, err := r.collection.DeleteOne(ctx, filter)
+		return err
+	})
+}
+
+// write sends one write operation as a bulkWrite of its model when
+// configured, or with its own command otherwise
+func (r *Runner) write(ctx context.Context, op mongo.WriteModel, single func() error) error {
+	if !r.bulkWrite {
+		return single()
+	}
+	_, err := r.collection.BulkWrite(ctx, []mongo.WriteModel{op})
+	return err
+}
+
+// started returns when Run started the threads, or the zero time before
+func (r *Runner) started() time.Time {
+	start, _ := r.startTime.Load().(time.Time)
+	return start
+}
+
+// GetStats returns current workload statistics
+func (r *Runner) GetStats() Stats {
+	done := atomic.LoadInt64(&r.opsDone)
+	failed := atomic.LoadInt64(&r.opsFailed)
+	timedOut := atomic.LoadInt64(&r.opsTimeout)
+
+	// Before the threads start, or if Run failed before starting them,
+	// nothing has run yet
+	start := r.started()
+	if start.IsZero() {
+		start = time.Now()
+	}
+	var opsPerSec float64
+	if elapsed := time.Since(start).Seconds(); elapsed > 0 {
+		opsPerSec = float64(done+failed+timedOut) / elapsed
+	}
+
+	return Stats{
+		Operations:       done,
+		FailedOperations: failed,
+		TimedOut:         timedOut,
+		Touched:          atomic.LoadInt64(&r.touched),
+		EmptySearches:    atomic.LoadInt64(&r.emptySearches),
+		SessionWaits:     atomic.LoadInt64(&r.sessionWaits),
+		OpsPerSecond:     opsPerSec,
+		StartTime:        start,
+
+		RYOW: RYOWStats{
+			Checks:    atomic.LoadInt64(&r.ryow.Checks),
+			Stale:     atomic.LoadInt64(&r.ryow.Stale),
+			Invisible: atomic.LoadInt64(&r.ryow.Invisible),
+			Failed:    atomic.LoadInt64(&r.ryow.Failed),
+			Skipped:   atomic.LoadInt64(&r.ryow.Skipped),
+		},
+	}
+}
+
+// Stats represents workload statistics
+type Stats struct {
+	Operations       int64
+	FailedOperations int64 // Errors other than timeouts
+	TimedOut         int64
+	Touched          int64 // Documents touched by the background toucher
+	EmptySearches    int64 // SEARCH operations that matched no documents
+	SessionWaits     int64 // Operations that waited for a free session of the pool
+	OpsPerSecond     float64
+	StartTime        time.Time
+	RYOW             RYOWStats // Read-your-own-write checks of sampled INSERTs
+}
@@ -0,0 +1,59 @@
+package workload
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/internal/pacing"
+)
+
+func TestRunnerPace(t *testing.T) {
+	r := &Runner{rate: 100}
+	r.startTime.Store(time.Now())
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if !r.pace(context.Background()) {
+			t.Fatal("pace returned false before the context ended")
+		}
+	}
+	// The sixth operation starts 50ms into the run at 100 ops/sec
+	if elapsed := time.Since(start); elapsed < 45*time.Millisecond {
+		t.Errorf("6 operations started within %v, want at least 50ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r.slots += 1000
+	if r.pace(ctx) {
+		t.Error("pace returned true after the context ended")
+	}
+}
+
+func TestRunnerPaceProfile(t *testing.T) {
+	profile, err := pacing.ParseLoadProfile("step:100-1000ops/1h,2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Runner{profile: &profile}
+	r.startTime.Store(time.Now())
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if !r.pace(context.Background()) {
+			t.Fatal("pace returned false before the context ended")
+		}
+	}
+	// The profile starts at 100 ops/sec
+	if elapsed := time.Since(start); elapsed < 45*time.Millisecond {
+		t.Errorf("6 operations started within %v, want at least 50ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r.slots += 1000
+	if r.pace(ctx) {
+		t.Error("pace returned true after the context ended")
+	}
+}
@@ -0,0 +1,132 @@
+package workload
+
+import (
+	"context"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// ryowCheckers is the number of concurrent re-readers of sampled INSERTs
+	ryowCheckers = 8
+
+	// ryowQueue bounds the sampled INSERTs waiting to be re-read; INSERTs
+	// sampled while it is full are skipped
+	ryowQueue = 1000
+
+	// ryowPollInterval is how often a secondary is asked again for a
+	// document it did not return yet
+	ryowPollInterval = 5 * time.Millisecond
+
+	// defaultRYOWTimeout is how long a check waits for a document by default
+	defaultRYOWTimeout = 10 * time.Second
+)
+
+// RYOWStats counts the read-your-own-write checks of sampled INSERTs
+type RYOWStats struct {
+	Checks    int64 // INSERTs re-read from a secondary
+	Stale     int64 // Not visible at the first read
+	Invisible int64 // Not visible within the timeout (included in Stale)
+	Failed    int64 // Checks whose reads failed
+	Skipped   int64 // Sampled while the checkers were behind
+}
+
+// StaleRatio returns the fraction of checks that did not see the write at once
+func (s RYOWStats) StaleRatio() float64 {
+	if s.Checks == 0 {
+		return 0
+	}
+	return float64(s.Stale) / float64(s.Checks)
+}
+
+// writeCheck is an acknowledged INSERT to re-read from a secondary
+type writeCheck struct {
+	id      interface{}
+	written time.Time
+}
+
+// sampleWrite queues a random RYOWSample of acknowledged INSERTs for a
+// read-your-own-write check, without delaying the writing thread
+func (r *Runner) sampleWrite(rng *rand.Rand, id interface{}) {
+	if r.ryowSample <= 0 || rng.Float64() >= r.ryowSample {
+		return
+	}
+	select {
+	case r.writeChecks <- writeCheck{id: id, written: time.Now()}:
+	default:
+		atomic.AddInt64(&r.ryow.Skipped, 1)
+	}
+}
+
+// checkWrites re-reads sampled INSERTs from a secondary until ctx ends
+func (r *Runner) checkWrites(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case check := <-r.writeChecks:
+			r.checkWrite(ctx, check)
+		}
+	}
+}
+
+// checkWrite reads the document of check from a secondary until it is
+// visible or the timeout passes, and records the time from the INSERT's
+// acknowledgment until then as a RYOW operation: the replication lag the
+// application would observe
+func (r *Runner) checkWrite(ctx context.Context, check writeCheck) {
+	readCtx, cancel := context.WithTimeout(ctx, r.ryowTimeout)
+	defer cancel()
+
+	filter := bson.D{{Key: "_id", Value: check.id}}
+	opts := options.FindOne().SetProjection(bson.D{{Key: "_id", Value: 1}})
+	for attempt := 0; ; attempt++ {
+		err := r.ryowCollection.FindOne(readCtx, filter, opts).Err()
+		lag := time.Since(check.written)
+		if ctx.Err() != nil {
+			// Checks interrupted by the end of the run are not recorded
+			return
+		}
+		switch {
+		case err == nil:
+			atomic.AddInt64(&r.ryow.Checks, 1)
+			if attempt > 0 {
+				atomic.AddInt64(&r.ryow.Stale, 1)
+			}
+			r.recordRYOW(lag, nil)
+			return
+		case err == mongo.ErrNoDocuments && readCtx.Err() == nil:
+		case readCtx.Err() != nil:
+			atomic.AddInt64(&r.ryow.Checks, 1)
+			atomic.AddInt64(&r.ryow.Stale, 1)
+			atomic.AddInt64(&r.ryow.Invisible, 1)
+			r.recordRYOW(lag, context.DeadlineExceeded)
+			return
+		default:
+			atomic.AddInt64(&r.ryow.Failed, 1)
+			r.recordRYOW(lag, err)
+			return
+		}
+
+		select {
+		case <-readCtx.Done():
+		case <-time.After(ryowPollInterval):
+		}
+	}
+}
+
+// recordRYOW records the outcome of a check in the YCSB log
+func (r *Runner) recordRYOW(lag time.Duration, err error) {
+	switch {
+	case r.ycsbLogger == nil:
+	case err == context.DeadlineExceeded:
+		r.ycsbLogger.RecordTimeout(OpRYOW, lag)
+	default:
+		r.ycsbLogger.RecordOperation(OpRYOW, lag, err == nil)
+	}
+}
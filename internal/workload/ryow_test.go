@@ -0,0 +1,36 @@
+package workload
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSampleWrite(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	if r := NewRunner(Config{}); r.ryowTimeout != defaultRYOWTimeout {
+		t.Errorf("Default timeout %v", r.ryowTimeout)
+	}
+
+	r := NewRunner(Config{RYOWSample: 1})
+	for i := 0; i < ryowQueue+5; i++ {
+		r.sampleWrite(rng, i)
+	}
+	if len(r.writeChecks) != ryowQueue || r.GetStats().RYOW.Skipped != 5 {
+		t.Errorf("Queued %d checks, skipped %d", len(r.writeChecks), r.GetStats().RYOW.Skipped)
+	}
+
+	r = NewRunner(Config{})
+	r.sampleWrite(rng, 1)
+	if len(r.writeChecks) != 0 {
+		t.Error("Checked a write without RYOWSample")
+	}
+}
+
+func TestRYOWStaleRatio(t *testing.T) {
+	if ratio := (RYOWStats{}).StaleRatio(); ratio != 0 {
+		t.Errorf("Stale ratio of no checks %v", ratio)
+	}
+	if ratio := (RYOWStats{Checks: 200, Stale: 3}).StaleRatio(); ratio != 0.015 {
+		t.Errorf("Stale ratio %v", ratio)
+	}
+}
@@ -0,0 +1,40 @@
+package workload
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DefaultScanLength is the range of documents a SCAN returns, as in YCSB
+// workload E
+var DefaultScanLength = model.CountRange{Min: 1, Max: 100}
+
+// scan reads a random number of consecutive documents in index order from a
+// random key, like the scans of YCSB workload E: by business key with a key
+// space, otherwise by _id. A scan near the end of the index returns fewer.
+func (r *Runner) scan(ctx context.Context, rng *rand.Rand) error {
+	start := r.keyFilter(rng)[0]
+	length := r.scanLength.Min + rng.Intn(r.scanLength.Max-r.scanLength.Min+1)
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: start.Key, Value: 1}}).
+		SetLimit(int64(length)).
+		SetBatchSize(int32(length)).
+		SetMaxTime(r.queryTimeout)
+	if r.projection != nil {
+		opts.SetProjection(r.projection)
+	}
+	cursor, err := r.collection.Find(ctx, bson.D{{Key: start.Key, Value: bson.D{{Key: "$gte", Value: start.Value}}}}, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+	}
+	return cursor.Err()
+}
@@ -0,0 +1,108 @@
+package workload
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchedulePoint pins the operation mix at an offset from the start of the run
+type SchedulePoint struct {
+	Offset time.Duration
+	Mix    Mix
+}
+
+// Schedule changes the operation mix gradually over a run. Between points the
+// weights are interpolated linearly; after the last point its mix is held.
+type Schedule []SchedulePoint
+
+// ParseSchedule parses a schedule like "0s:insert=80,read=20;2h:insert=20,read=80"
+func ParseSchedule(scheduleStr string) (Schedule, error) {
+	var schedule Schedule
+	for _, part := range strings.Split(scheduleStr, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		kv := strings.SplitN(part, ":", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid schedule point (want offset:mix): %s", part)
+		}
+
+		offset, err := time.ParseDuration(strings.TrimSpace(kv[0]))
+		if err != nil || offset < 0 {
+			return nil, fmt.Errorf("invalid schedule offset: %s", kv[0])
+		}
+
+		mix, err := ParseMix(kv[1])
+		if err != nil {
+			return nil, err
+		}
+		schedule = append(schedule, SchedulePoint{Offset: offset, Mix: mix})
+	}
+
+	if len(schedule) == 0 {
+		return nil, fmt.Errorf("empty mix schedule")
+	}
+
+	sort.Slice(schedule, func(i, j int) bool {
+		return schedule[i].Offset < schedule[j].Offset
+	})
+	return schedule, nil
+}
+
+// MixAt returns the interpolated operation mix at the given elapsed time
+func (s Schedule) MixAt(elapsed time.Duration) Mix {
+	if elapsed <= s[0].Offset {
+		return s[0].Mix
+	}
+
+	for i := 1; i < len(s); i++ {
+		if elapsed >= s[i].Offset {
+			continue
+		}
+
+		prev, next := s[i-1], s[i]
+		frac := float64(elapsed-prev.Offset) / float64(next.Offset-prev.Offset)
+
+		// Normalize both endpoints so mixes with different totals blend evenly
+		prevTotal, nextTotal := prev.Mix.total(), next.Mix.total()
+		mix := make(Mix)
+		for op, w := range prev.Mix {
+			mix[op] += w / prevTotal * (1 - frac)
+		}
+		for op, w := range next.Mix {
+			mix[op] += w / nextTotal * frac
+		}
+		return mix
+	}
+
+	return s[len(s)-1].Mix
+}
+
+// Ops returns every operation type that appears anywhere in the schedule
+func (s Schedule) Ops() []string {
+	seen := make(map[string]bool)
+	var ops []string
+	for _, point := range s {
+		for _, op := range point.Mix.Ops() {
+			if !seen[op] {
+				seen[op] = true
+				ops = append(ops, op)
+			}
+		}
+	}
+	sort.Strings(ops)
+	return ops
+}
+
+// String formats the schedule in the same syntax ParseSchedule accepts
+func (s Schedule) String() string {
+	parts := make([]string, len(s))
+	for i, point := range s {
+		parts[i] = fmt.Sprintf("%v:%s", point.Offset, point.Mix)
+	}
+	return strings.Join(parts, ";")
+}
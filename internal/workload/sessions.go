@@ -0,0 +1,59 @@
+package workload
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// startSessions starts the pool of causally consistent sessions the threads
+// share. Each session keeps its own server session (lsid) and causal tokens
+// until endSessions, so the server tracks all of them at once.
+func (r *Runner) startSessions() error {
+	client := r.collection.Database().Client()
+	r.sessionPool = make(chan mongo.Session, r.sessions)
+	for i := 0; i < r.sessions; i++ {
+		session, err := client.StartSession(options.Session().SetCausalConsistency(true))
+		if err != nil {
+			r.endSessions()
+			return fmt.Errorf("failed to start session %d: %w", i, err)
+		}
+		r.sessionPool <- session
+	}
+	return nil
+}
+
+// endSessions ends the sessions of the pool
+func (r *Runner) endSessions() {
+	close(r.sessionPool)
+	for session := range r.sessionPool {
+		session.EndSession(context.Background())
+	}
+}
+
+// acquireSession takes the least recently used session of the pool, waiting
+// if every session is in use by another thread. It returns nil if ctx ends
+// first.
+func (r *Runner) acquireSession(ctx context.Context) mongo.Session {
+	select {
+	case session := <-r.sessionPool:
+		return session
+	default:
+	}
+	atomic.AddInt64(&r.sessionWaits, 1)
+	select {
+	case <-ctx.Done():
+		return nil
+	case session := <-r.sessionPool:
+		return session
+	}
+}
+
+// releaseSession returns a session to the pool with its advanced causal
+// tokens, for the next operation of any thread to continue from
+func (r *Runner) releaseSession(session mongo.Session) {
+	r.sessionPool <- session
+}
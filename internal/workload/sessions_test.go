@@ -0,0 +1,42 @@
+package workload
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestSessionPool(t *testing.T) {
+	// Connecting does not contact the server until the first operation
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Disconnect(context.Background())
+
+	r := NewRunner(Config{Collection: client.Database("test").Collection("test"), Sessions: 2})
+	if err := r.startSessions(); err != nil {
+		t.Fatal(err)
+	}
+	first := r.acquireSession(context.Background())
+	second := r.acquireSession(context.Background())
+	if first == nil || second == nil || first == second {
+		t.Fatal("Expected two distinct sessions")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if r.acquireSession(ctx) != nil || r.GetStats().SessionWaits != 1 {
+		t.Errorf("Acquired a session from an empty pool, %d waits", r.GetStats().SessionWaits)
+	}
+
+	r.releaseSession(first)
+	if r.acquireSession(ctx) != first {
+		t.Error("Expected the released session")
+	}
+	r.releaseSession(first)
+	r.releaseSession(second)
+	r.endSessions()
+}
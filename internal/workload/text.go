@@ -0,0 +1,175 @@
+package workload
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// termSampleSize is the number of documents whose text fields supply
+	// the terms of TEXT and SEARCH operations
+	termSampleSize = 100
+
+	// maxTerms bounds the distinct terms kept from the sample
+	maxTerms = 1000
+
+	// minTermLength is the shortest word, in characters, used as a term,
+	// which skips most stop words
+	minTermLength = 4
+
+	// textResults is the number of best-scoring documents a search returns
+	textResults = 10
+)
+
+// sampleTerms collects the search terms of TEXT and SEARCH operations from
+// the text fields of a random sample of documents, so that searches match
+// the generated text in any locale
+func (r *Runner) sampleTerms(ctx context.Context) error {
+	projection := bson.D{{Key: "_id", Value: 0}}
+	for _, field := range r.textFields {
+		projection = append(projection, bson.E{Key: field, Value: 1})
+	}
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$sample", Value: bson.D{{Key: "size", Value: termSampleSize}}}},
+		{{Key: "$project", Value: projection}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sample search terms: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	seen := make(map[string]bool)
+	for cursor.Next(ctx) && len(r.terms) < maxTerms {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return fmt.Errorf("failed to sample search terms: %w", err)
+		}
+		for _, term := range textTerms(doc) {
+			if !seen[term] && len(r.terms) < maxTerms {
+				seen[term] = true
+				r.terms = append(r.terms, term)
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("failed to sample search terms: %w", err)
+	}
+	if len(r.terms) == 0 {
+		return fmt.Errorf("no search terms found in %s of %s", strings.Join(r.textFields, ", "), r.collection.Name())
+	}
+	return nil
+}
+
+// textTerms returns the lowercase words of at least minTermLength letters of
+// every string in value, descending into subdocuments and arrays
+func textTerms(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		var terms []string
+		for _, word := range strings.FieldsFunc(v, func(c rune) bool { return !unicode.IsLetter(c) }) {
+			if utf8.RuneCountInString(word) >= minTermLength {
+				terms = append(terms, strings.ToLower(word))
+			}
+		}
+		return terms
+	case bson.M:
+		var terms []string
+		for _, field := range v {
+			terms = append(terms, textTerms(field)...)
+		}
+		return terms
+	case bson.A:
+		var terms []string
+		for _, element := range v {
+			terms = append(terms, textTerms(element)...)
+		}
+		return terms
+	}
+	return nil
+}
+
+// textSearch runs a $text query for one or two random sampled terms and reads
+// the best-scoring documents
+func (r *Runner) textSearch(ctx context.Context, rng *rand.Rand) error {
+	if len(r.terms) == 0 {
+		return fmt.Errorf("TEXT requires text fields to search")
+	}
+	terms := r.terms[rng.Intn(len(r.terms))]
+	if rng.Intn(2) == 0 {
+		terms += " " + r.terms[rng.Intn(len(r.terms))]
+	}
+
+	score := bson.D{{Key: "score", Value: bson.D{{Key: "$meta", Value: "textScore"}}}}
+	cursor, err := r.collection.Find(ctx,
+		bson.D{{Key: "$text", Value: bson.D{{Key: "$search", Value: terms}}}},
+		options.Find().
+			SetProjection(append(bson.D{{Key: r.schema.KeyField, Value: 1}}, score...)).
+			SetSort(score).
+			SetLimit(textResults).
+			SetMaxTime(r.queryTimeout))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+	}
+	return cursor.Err()
+}
+
+// atlasSearch runs a compound Atlas Search query that must match one random
+// sampled term and should match another, and reads the best-scoring
+// documents. A search that matches nothing is counted as empty: its terms
+// come from the documents, so the index is missing or lags behind them.
+func (r *Runner) atlasSearch(ctx context.Context, rng *rand.Rand) error {
+	if r.searchIndex == "" || len(r.terms) == 0 {
+		return fmt.Errorf("SEARCH requires an Atlas Search index and text fields to search")
+	}
+	text := func(query string) bson.D {
+		return bson.D{{Key: "text", Value: bson.D{
+			{Key: "query", Value: query},
+			{Key: "path", Value: r.textFields},
+		}}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$search", Value: bson.D{
+			{Key: "index", Value: r.searchIndex},
+			{Key: "compound", Value: bson.D{
+				{Key: "must", Value: bson.A{text(r.terms[rng.Intn(len(r.terms))])}},
+				{Key: "should", Value: bson.A{text(r.terms[rng.Intn(len(r.terms))])}},
+			}},
+		}}},
+		{{Key: "$limit", Value: textResults}},
+		{{Key: "$project", Value: bson.D{
+			{Key: r.schema.KeyField, Value: 1},
+			{Key: "score", Value: bson.D{{Key: "$meta", Value: "searchScore"}}},
+		}}},
+	}
+	cursor, err := r.collection.Aggregate(ctx, pipeline, options.Aggregate().SetMaxTime(r.aggregateTimeout))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var results int
+	for cursor.Next(ctx) {
+		results++
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	if results == 0 {
+		atomic.AddInt64(&r.emptySearches, 1)
+	}
+	return nil
+}
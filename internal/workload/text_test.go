@@ -0,0 +1,24 @@
+package workload
+
+import (
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestTextTerms(t *testing.T) {
+	doc := bson.M{
+		"notes": bson.A{"Quick brown fox, jumps!", "Über straße"},
+		"orders": bson.A{
+			bson.M{"notes": "Delivered to the back door"},
+			bson.M{"notes": 42},
+		},
+	}
+	terms := textTerms(doc)
+	slices.Sort(terms)
+	want := []string{"back", "brown", "delivered", "door", "jumps", "quick", "straße", "über"}
+	if !slices.Equal(terms, want) {
+		t.Errorf("Terms %v, want %v", terms, want)
+	}
+}
@@ -0,0 +1,115 @@
+package workload
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// touchTicksPerSecond is how often the toucher issues an update batch
+const touchTicksPerSecond = 10
+
+// touch sweeps the whole collection in _id order, setting updated_at to the
+// server's current date on up to touchRate documents per second, and wraps
+// around at the end. Like a background job it keeps the dataset dirty and
+// pulls cold documents into the cache while the mix runs.
+func (r *Runner) touch(ctx context.Context) {
+	perTick := r.touchRate / touchTicksPerSecond
+	if perTick < 1 {
+		perTick = 1
+	}
+	interval := time.Second / touchTicksPerSecond
+	if r.touchRate < touchTicksPerSecond {
+		interval = time.Second / time.Duration(r.touchRate)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastID interface{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var err error
+		lastID, err = r.touchNext(ctx, lastID, perTick)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Touch failed: %v", err)
+		}
+	}
+}
+
+// touchNext touches up to n documents following lastID (nil = from the
+// start) and returns the last _id touched, or nil once the sweep wraps
+func (r *Runner) touchNext(ctx context.Context, lastID interface{}, n int) (interface{}, error) {
+	filter := bson.D{}
+	if lastID != nil {
+		filter = bson.D{{Key: "_id", Value: bson.D{{Key: "$gt", Value: lastID}}}}
+	}
+	cursor, err := r.collection.Find(ctx, filter,
+		options.Find().
+			SetSort(bson.D{{Key: "_id", Value: 1}}).
+			SetLimit(int64(n)).
+			SetProjection(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return lastID, err
+	}
+
+	var ids []interface{}
+	for cursor.Next(ctx) {
+		ids = append(ids, cursor.Current.Lookup("_id"))
+	}
+	cursor.Close(ctx)
+	if err := cursor.Err(); err != nil {
+		return lastID, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	updateCtx := ctx
+	if r.insertTimeout > 0 {
+		var cancel context.CancelFunc
+		updateCtx, cancel = context.WithTimeout(ctx, r.insertTimeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	result, err := r.collection.UpdateMany(updateCtx,
+		bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}},
+		bson.D{{Key: "$currentDate", Value: bson.D{{Key: "updated_at", Value: true}}}},
+	)
+	latency := time.Since(start)
+	if ctx.Err() != nil {
+		// Batches interrupted by the end of the run are not recorded
+		return lastID, nil
+	}
+
+	if r.ycsbLogger != nil {
+		perDoc := latency / time.Duration(len(ids))
+		for range ids {
+			if err != nil && mongo.IsTimeout(err) {
+				r.ycsbLogger.RecordTimeout(OpTouch, perDoc)
+			} else {
+				r.ycsbLogger.RecordOperation(OpTouch, perDoc, err == nil)
+			}
+		}
+	}
+	if err != nil {
+		return lastID, err
+	}
+	atomic.AddInt64(&r.touched, result.MatchedCount)
+
+	if len(ids) < n {
+		return nil, nil
+	}
+	return ids[len(ids)-1], nil
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"crypto/rand"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BSONTypes holds one value of every BSON type that is not deprecated
+// (Options.BSONTypes), so that datasets exercise how drivers and storage
+// engines handle each of them. Field names follow the type names.
+type BSONTypes struct {
+	Double     float64              `bson:"double"`
+	String     string               `bson:"string"`
+	Object     bson.D               `bson:"object"`
+	Array      []interface{}        `bson:"array"` // Mixed element types
+	Binary     primitive.Binary     `bson:"binary"`
+	UUID       primitive.Binary     `bson:"uuid"` // Binary subtype 4
+	ObjectID   primitive.ObjectID   `bson:"object_id"`
+	Bool       bool                 `bson:"bool"`
+	Date       primitive.DateTime   `bson:"date"`
+	Null       interface{}          `bson:"null"`
+	Regex      primitive.Regex      `bson:"regex"`
+	JavaScript primitive.JavaScript `bson:"javascript"`
+	Int32      int32                `bson:"int32"`
+	Timestamp  primitive.Timestamp  `bson:"timestamp"`
+	Int64      int64                `bson:"int64"` // Beyond the int32 range
+	Decimal128 primitive.Decimal128 `bson:"decimal128"`
+	MinKey     primitive.MinKey     `bson:"min_key"`
+	MaxKey     primitive.MaxKey     `bson:"max_key"`
+}
+
+// bsonTypes returns a sample of every BSON type, or nil unless
+// Options.BSONTypes is set
+func (g *Generator) bsonTypes() (*BSONTypes, error) {
+	if !g.options.BSONTypes {
+		return nil, nil
+	}
+
+	blob := make([]byte, g.faker.IntRange(64, 256))
+	if _, err := rand.Read(blob); err != nil {
+		return nil, err
+	}
+	uuid := make([]byte, 16)
+	if _, err := rand.Read(uuid); err != nil {
+		return nil, err
+	}
+	amount, err := minorUnitsDecimal(int64(g.faker.IntRange(1, 100000000)), 4)
+	if err != nil {
+		return nil, err
+	}
+	now := g.now()
+
+	return &BSONTypes{
+		Double: g.faker.Float64Range(-1e6, 1e6),
+		String: g.faker.Sentence(5),
+		Object: bson.D{
+			{Key: "name", Value: g.faker.Word()},
+			{Key: "count", Value: int32(g.faker.IntRange(0, 1000))},
+		},
+		Array:      []interface{}{int32(g.faker.IntRange(0, 100)), g.faker.Word(), g.faker.Bool(), nil},
+		Binary:     primitive.Binary{Subtype: bson.TypeBinaryGeneric, Data: blob},
+		UUID:       primitive.Binary{Subtype: bson.TypeBinaryUUID, Data: uuid},
+		ObjectID:   primitive.NewObjectID(),
+		Bool:       g.faker.Bool(),
+		Date:       primitive.NewDateTimeFromTime(g.dateRange(now.AddDate(-5, 0, 0), now)),
+		Regex:      primitive.Regex{Pattern: "^" + g.faker.Word() + "[0-9]+$", Options: "i"},
+		JavaScript: primitive.JavaScript("function() { return this.int32 * 2; }"),
+		Int32:      int32(g.faker.IntRange(-1<<31, 1<<31-1)),
+		Timestamp:  primitive.Timestamp{T: uint32(now.Unix()), I: uint32(g.faker.IntRange(1, 1000))},
+		Int64:      int64(1<<40) + int64(g.faker.IntRange(0, 1<<30)),
+		Decimal128: amount,
+	}, nil
+}
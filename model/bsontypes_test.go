@@ -0,0 +1,88 @@
+package model
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+func TestBSONTypesCoverEveryType(t *testing.T) {
+	templates := []string{TemplateCustomer, TemplateProduct, TemplateTelemetry, TemplateTransaction, TemplateMessages, TemplateEvents}
+
+	for _, template := range templates {
+		gen := NewGeneratorWithOptions(Size4KB, Options{Template: template, BSONTypes: true})
+		doc, err := gen.GenerateDocument()
+		if err != nil {
+			t.Fatalf("Failed to generate %s document: %v", template, err)
+		}
+		data, err := bson.Marshal(doc)
+		if err != nil {
+			t.Fatalf("Failed to marshal %s document: %v", template, err)
+		}
+
+		sample, err := bson.Raw(data).LookupErr("bson_types")
+		if err != nil {
+			t.Fatalf("%s document has no bson_types", template)
+		}
+		seen := make(map[bsontype.Type]bool)
+		elements, err := sample.Document().Elements()
+		if err != nil {
+			t.Fatalf("Invalid bson_types: %v", err)
+		}
+		for _, element := range elements {
+			seen[element.Value().Type] = true
+		}
+
+		want := []bsontype.Type{
+			bson.TypeDouble, bson.TypeString, bson.TypeEmbeddedDocument, bson.TypeArray,
+			bson.TypeBinary, bson.TypeObjectID, bson.TypeBoolean, bson.TypeDateTime,
+			bson.TypeNull, bson.TypeRegex, bson.TypeJavaScript, bson.TypeInt32,
+			bson.TypeTimestamp, bson.TypeInt64, bson.TypeDecimal128, bson.TypeMinKey, bson.TypeMaxKey,
+		}
+		for _, typ := range want {
+			if !seen[typ] {
+				t.Errorf("%s bson_types has no %s value", template, typ)
+			}
+		}
+	}
+}
+
+func TestBSONTypesChecksumRoundTrip(t *testing.T) {
+	gen := NewGeneratorWithOptions(Size4KB, Options{Checksum: true, BSONTypes: true})
+	doc, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Failed to generate document: %v", err)
+	}
+
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Failed to marshal document: %v", err)
+	}
+	var decoded CustomerDocument
+	if err := bson.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal document: %v", err)
+	}
+
+	checksum, err := DocumentChecksum(&decoded)
+	if err != nil {
+		t.Fatalf("Failed to checksum document: %v", err)
+	}
+	if checksum != doc.Checksum {
+		t.Errorf("Checksum changed after a round trip: %s, want %s", checksum, doc.Checksum)
+	}
+}
+
+func TestBSONTypesOffByDefault(t *testing.T) {
+	doc, err := NewGeneratorWithOptions(Size2KB, Options{Template: TemplateEvents}).GenerateDocument()
+	if err != nil {
+		t.Fatalf("Failed to generate document: %v", err)
+	}
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Failed to marshal document: %v", err)
+	}
+	if _, err := bson.Raw(data).LookupErr("bson_types"); err == nil {
+		t.Error("Expected no bson_types without Options.BSONTypes")
+	}
+}
@@ -0,0 +1,236 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Cardinality maps field paths (dotted, through arrays, such as
+// orders.status) to the number of distinct values the field takes across
+// generated documents. 0 means every value is unique.
+type Cardinality map[string]int64
+
+// ParseCardinality parses "field=N,field=unique,..." ("" = none)
+func ParseCardinality(s string) (Cardinality, error) {
+	c := make(Cardinality)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field, value, ok := strings.Cut(part, "=")
+		field, value = strings.TrimSpace(field), strings.TrimSpace(value)
+		if !ok || field == "" {
+			return nil, fmt.Errorf("invalid cardinality %q: want field=N or field=unique", part)
+		}
+		if value == "unique" {
+			c[field] = 0
+			continue
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid cardinality %q: want a positive count or unique", part)
+		}
+		c[field] = n
+	}
+	return c, nil
+}
+
+// String formats the cardinality as ParseCardinality accepts it
+func (c Cardinality) String() string {
+	parts := make([]string, 0, len(c))
+	for field, n := range c {
+		value := strconv.FormatInt(n, 10)
+		if n == 0 {
+			value = "unique"
+		}
+		parts = append(parts, field+"="+value)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// ValidateCardinality checks that every field of c is a string or number
+// field of the template's documents. The template's key field (always
+// unique) only accepts unique, and _id is not allowed.
+func ValidateCardinality(template string, c Cardinality) error {
+	schema := NewGeneratorWithOptions(Size2KB, Options{Template: template}).Schema()
+	docType := reflect.TypeOf(templateDocument(template)).Elem()
+
+	for field, n := range c {
+		if field == "_id" {
+			return fmt.Errorf("cardinality of _id cannot be changed")
+		}
+		if field == schema.KeyField {
+			if n != 0 {
+				return fmt.Errorf("%s is the key field and always unique", field)
+			}
+			continue
+		}
+		if err := checkFieldPath(docType, strings.Split(field, "."), settableKind); err != nil {
+			return fmt.Errorf("invalid cardinality field %s: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// ValidateFieldPaths checks that every field is a dotted path to a field of
+// the template's documents, through arrays without an index (orders.status
+// is the status of every order), as projections and queries address them
+func ValidateFieldPaths(template string, fields []string) error {
+	docType := reflect.TypeOf(templateDocument(template)).Elem()
+	for _, field := range fields {
+		if err := checkFieldPath(docType, strings.Split(field, "."), nil); err != nil {
+			return fmt.Errorf("invalid field %s: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// templateDocument returns an empty document of the template
+func templateDocument(template string) Document {
+	switch template {
+	case TemplateProduct:
+		return &ProductDocument{}
+	case TemplateTelemetry:
+		return &TelemetryDocument{}
+	case TemplateTransaction:
+		return &TransactionDocument{}
+	case TemplateMessages:
+		return &ConversationDocument{}
+	case TemplateEvents:
+		return &EventDocument{}
+	}
+	return &CustomerDocument{}
+}
+
+// checkFieldPath checks that path leads through structs and slices to a
+// field whose kind leaf accepts (nil = any field)
+func checkFieldPath(t reflect.Type, path []string, leaf func(reflect.Kind) bool) error {
+	if len(path) == 0 && leaf == nil {
+		return nil
+	}
+	for t.Kind() == reflect.Slice || t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if len(path) == 0 {
+		if !leaf(t.Kind()) {
+			return fmt.Errorf("not a string or number field")
+		}
+		return nil
+	}
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("%s is not a field of a subdocument", path[0])
+	}
+	index, ok := fieldIndex(t, path[0])
+	if !ok {
+		return fmt.Errorf("unknown field %s", path[0])
+	}
+	return checkFieldPath(t.Field(index).Type, path[1:], leaf)
+}
+
+// fieldIndex finds the struct field with the given bson name
+func fieldIndex(t reflect.Type, name string) (int, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("bson"), ",")
+		if tag == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// settableKind reports whether cardinality values can be assigned to a kind
+func settableKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.String, reflect.Int, reflect.Int32, reflect.Int64, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// cardinalityField draws the values of one field with a set cardinality
+type cardinalityField struct {
+	path     []string
+	distinct int64 // 0 = unique
+	next     int64 // Next unique value
+}
+
+// newCardinalityFields prepares the fields of c, except the key field, which
+// is already unique
+func newCardinalityFields(c Cardinality, keyField string) []*cardinalityField {
+	fields := make([]*cardinalityField, 0, len(c))
+	for field, n := range c {
+		if field != keyField {
+			fields = append(fields, &cardinalityField{path: strings.Split(field, "."), distinct: n})
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return strings.Join(fields[i].path, ".") < strings.Join(fields[j].path, ".")
+	})
+	return fields
+}
+
+// applyCardinality replaces the values of fields with a set cardinality in
+// doc, drawing each value (each array element's, for fields in arrays)
+// uniformly from the field's distinct values
+func (g *Generator) applyCardinality(doc Document) {
+	for _, field := range g.cardinality {
+		g.setField(reflect.ValueOf(doc), field, field.path)
+	}
+}
+
+// setField walks path from v and assigns a drawn value to every field it reaches
+func (g *Generator) setField(v reflect.Value, field *cardinalityField, path []string) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			g.setField(v.Elem(), field, path)
+		}
+		return
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			g.setField(v.Index(i), field, path)
+		}
+		return
+	}
+
+	if len(path) == 0 {
+		index := g.drawValue(field)
+		switch v.Kind() {
+		case reflect.String:
+			v.SetString(cardinalityValue(field.path[len(field.path)-1], index))
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			v.SetInt(index)
+		case reflect.Float64:
+			v.SetFloat(float64(index))
+		}
+		return
+	}
+	if v.Kind() == reflect.Struct {
+		if i, ok := fieldIndex(v.Type(), path[0]); ok {
+			g.setField(v.Field(i), field, path[1:])
+		}
+	}
+}
+
+// drawValue returns the index of the next value of a field
+func (g *Generator) drawValue(field *cardinalityField) int64 {
+	if field.distinct == 0 {
+		return atomic.AddInt64(&field.next, 1) - 1
+	}
+	return int64(g.faker.Uint64() % uint64(field.distinct))
+}
+
+// cardinalityValue formats the index-th distinct value of a string field,
+// shaped like an email address for email fields
+func cardinalityValue(name string, index int64) string {
+	if strings.Contains(name, "email") {
+		return fmt.Sprintf("user%d@example.com", index)
+	}
+	return fmt.Sprintf("%s_%d", name, index)
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCardinality(t *testing.T) {
+	c, err := ParseCardinality("email=1000, orders.status=50,customer_id=unique")
+	if err != nil {
+		t.Fatalf("Failed to parse cardinality: %v", err)
+	}
+	if c["email"] != 1000 || c["orders.status"] != 50 || c["customer_id"] != 0 || len(c) != 3 {
+		t.Errorf("Unexpected cardinality: %v", c)
+	}
+	if got := c.String(); got != "customer_id=unique,email=1000,orders.status=50" {
+		t.Errorf("String() = %s", got)
+	}
+
+	for _, invalid := range []string{"email", "email=0", "email=-5", "=5", "email=many"} {
+		if _, err := ParseCardinality(invalid); err == nil {
+			t.Errorf("Expected error for cardinality %q", invalid)
+		}
+	}
+}
+
+func TestValidateCardinality(t *testing.T) {
+	valid := []struct {
+		template string
+		spec     string
+	}{
+		{TemplateCustomer, "email=1000,orders.status=50,orders.line_items.quantity=3,customer_id=unique"},
+		{TemplateProduct, "brand=20,reviews.rating=5"},
+		{TemplateEvents, "host=10,status_code=4,duration_ms=100"},
+	}
+	for _, tt := range valid {
+		c, _ := ParseCardinality(tt.spec)
+		if err := ValidateCardinality(tt.template, c); err != nil {
+			t.Errorf("ValidateCardinality(%s, %s): %v", tt.template, tt.spec, err)
+		}
+	}
+
+	invalid := []string{"_id=unique", "customer_id=10", "nope=5", "orders=5", "created_at=5", "email.user=5"}
+	for _, spec := range invalid {
+		c, _ := ParseCardinality(spec)
+		if err := ValidateCardinality(TemplateCustomer, c); err == nil {
+			t.Errorf("Expected error for customer cardinality %s", spec)
+		}
+	}
+}
+
+func TestCardinalityLimitsDistinctValues(t *testing.T) {
+	c, _ := ParseCardinality("email=7,orders.status=3,phone=unique")
+	gen := NewGeneratorWithOptions(Size8KB, Options{Cardinality: c, Orders: CountRange{Min: 2, Max: 4}})
+
+	emails := make(map[string]bool)
+	statuses := make(map[string]bool)
+	phones := make(map[string]bool)
+	for i := 0; i < 500; i++ {
+		doc, err := gen.Generate()
+		if err != nil {
+			t.Fatalf("Failed to generate document: %v", err)
+		}
+		if !strings.HasSuffix(doc.Email, "@example.com") {
+			t.Fatalf("Email %s is not an email address", doc.Email)
+		}
+		emails[doc.Email] = true
+		phones[doc.Phone] = true
+		for _, order := range doc.Orders {
+			statuses[order.Status] = true
+		}
+	}
+
+	if len(emails) != 7 {
+		t.Errorf("Got %d distinct emails, want 7", len(emails))
+	}
+	if len(statuses) != 3 {
+		t.Errorf("Got %d distinct order statuses, want 3", len(statuses))
+	}
+	if len(phones) != 500 {
+		t.Errorf("Got %d distinct phones, want 500 (unique)", len(phones))
+	}
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// checksumPlaceholder reserves the checksum's size while padding is calculated
+var checksumPlaceholder = strings.Repeat("0", sha256.Size*2)
+
+// checksumFields are the canonical fields covered by a document checksum.
+// _id (reassigned by duplicate collisions), updated_at (touched by update
+// workloads), and metadata (edited by post-processors; map order is not
+// canonical) are excluded.
+type checksumFields struct {
+	CustomerID     string          `bson:"customer_id"`
+	Email          string          `bson:"email"`
+	FirstName      string          `bson:"first_name"`
+	LastName       string          `bson:"last_name"`
+	Phone          string          `bson:"phone"`
+	DateOfBirth    time.Time       `bson:"date_of_birth"`
+	CreatedAt      time.Time       `bson:"created_at"`
+	Addresses      []Address       `bson:"addresses"`
+	PaymentMethods []PaymentMethod `bson:"payment_methods"`
+	Orders         []Order         `bson:"orders"`
+	OrderStats     *OrderStats     `bson:"order_stats,omitempty"`
+	Notes          []string        `bson:"notes"`
+	Tags           []string        `bson:"tags"`
+	Padding        Padding         `bson:"padding"`
+	BSONTypes      *BSONTypes      `bson:"bson_types,omitempty"`
+	Nested         bson.D          `bson:"nested,omitempty"`
+}
+
+// DocumentChecksum returns the hex SHA-256 of the document's canonical
+// fields. It is computed over their BSON encoding, so a document read back
+// from the server yields the same checksum unless its content changed.
+func DocumentChecksum(doc *CustomerDocument) (string, error) {
+	// Documents of earlier versions have no order stats
+	var stats *OrderStats
+	if doc.OrderStats != (OrderStats{}) {
+		stats = &doc.OrderStats
+	}
+	data, err := bson.Marshal(checksumFields{
+		CustomerID:     doc.CustomerID,
+		Email:          doc.Email,
+		FirstName:      doc.FirstName,
+		LastName:       doc.LastName,
+		Phone:          doc.Phone,
+		DateOfBirth:    doc.DateOfBirth,
+		CreatedAt:      doc.CreatedAt,
+		Addresses:      doc.Addresses,
+		PaymentMethods: doc.PaymentMethods,
+		Orders:         doc.Orders,
+		OrderStats:     stats,
+		Notes:          doc.Notes,
+		Tags:           doc.Tags,
+		Padding:        doc.Padding,
+		BSONTypes:      doc.BSONTypes,
+		Nested:         doc.Nested,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
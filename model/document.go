@@ -1,7 +1,7 @@
 package model
 
 import (
-	"crypto/rand"
+	"fmt"
 	"time"
 
 	"github.com/brianvoe/gofakeit/v7"
@@ -13,14 +13,42 @@ import (
 type DocumentSize int
 
 const (
+	Size512B DocumentSize = 512  // Events template only
+	Size1KB  DocumentSize = 1024 // Events template only
 	Size2KB  DocumentSize = 2 * 1024
 	Size4KB  DocumentSize = 4 * 1024
 	Size8KB  DocumentSize = 8 * 1024
 	Size16KB DocumentSize = 16 * 1024
 	Size32KB DocumentSize = 32 * 1024
 	Size64KB DocumentSize = 64 * 1024
+
+	// Large documents, for testing large-document pathologies
+	Size256KB DocumentSize = 256 * 1024
+	Size1MB   DocumentSize = 1024 * 1024
+	Size4MB   DocumentSize = 4 * 1024 * 1024
+	Size16MB  DocumentSize = MaxDocumentSize // Generated MaxTargetSize bytes large
 )
 
+const (
+	// MaxDocumentSize is the largest BSON document MongoDB stores
+	MaxDocumentSize = 16 * 1024 * 1024
+
+	// MaxTargetSize is the largest size documents are generated at, which
+	// leaves room below MaxDocumentSize for fields added after generation
+	MaxTargetSize = MaxDocumentSize - 16*1024
+)
+
+// String formats the size the way --doc-size accepts it (512B, 2KB, ...)
+func (s DocumentSize) String() string {
+	if s%(1024*1024) == 0 {
+		return fmt.Sprintf("%dMB", int(s)/(1024*1024))
+	}
+	if s%1024 != 0 {
+		return fmt.Sprintf("%dB", int(s))
+	}
+	return fmt.Sprintf("%dKB", int(s)/1024)
+}
+
 // CustomerDocument represents a customer with nested orders and details
 type CustomerDocument struct {
 	ID          primitive.ObjectID `bson:"_id"`
@@ -37,13 +65,27 @@ type CustomerDocument struct {
 	PaymentMethods []PaymentMethod `bson:"payment_methods"`
 	Orders         []Order         `bson:"orders"`
 
+	// Aggregates of the orders, see OrderStats
+	OrderStats OrderStats `bson:"order_stats"`
+
 	// Metadata and padding fields
 	Metadata map[string]interface{} `bson:"metadata"`
 	Notes    []string               `bson:"notes"`
 	Tags     []string               `bson:"tags"`
 
 	// Padding field to control document size
-	Padding string `bson:"padding"`
+	Padding Padding `bson:"padding"`
+
+	// Sample of every BSON type (Options.BSONTypes)
+	BSONTypes *BSONTypes `bson:"bson_types,omitempty"`
+
+	// Chain of subdocuments Options.NestingDepth levels deep
+	Nested bson.D `bson:"nested,omitempty"`
+
+	docShape // Sparse fields and legacy schema (Options.Sparsity, Options.LegacyFraction)
+
+	// Checksum of the canonical fields (Options.Checksum), see DocumentChecksum
+	Checksum string `bson:"checksum,omitempty"`
 }
 
 // Address represents a customer address
@@ -75,8 +117,8 @@ type PaymentMethod struct {
 type Order struct {
 	ID            primitive.ObjectID `bson:"_id"`
 	OrderNumber   string             `bson:"order_number"`
-	Status        string             `bson:"status"` // pending, processing, shipped, delivered, cancelled
-	TotalAmount   float64            `bson:"total_amount"`
+	Status        string             `bson:"status"`       // pending, processing, shipped, delivered, cancelled
+	TotalAmount   float64            `bson:"total_amount"` // Line items minus discounts plus taxes
 	Currency      string             `bson:"currency"`
 	OrderDate     time.Time          `bson:"order_date"`
 	ShippedDate   *time.Time         `bson:"shipped_date,omitempty"`
@@ -132,10 +174,128 @@ type Generator struct {
 	faker            *gofakeit.Faker
 	targetSize       DocumentSize
 	paddingTemplates map[DocumentSize]string
+	options          Options
+	keys             *keySequence
+	timeline         timeline
+	cardinality      []*cardinalityField
+	sparsity         []sparseField
+	fieldValues      []valueField
+	fieldTemplates   *fieldTemplates
+	locales          []*localeData // Picked per document; nil = LocaleDefault
+	overhead         int           // Bytes added after generation, see Calibrate
+	oversized        int64         // Documents larger than the target before padding
+}
+
+// Options holds optional generator settings
+type Options struct {
+	PaddingMode PaddingMode
+
+	// PaddingSizing selects how padding is fitted, see PaddingSizing
+	PaddingSizing PaddingSizing
+
+	// ExactSize pads every document to within SizeTolerance bytes of the
+	// target size, without the limits that keep padding a minority of large
+	// documents. Documents whose content alone exceeds the target are
+	// counted (see Oversized), or fail with StrictSize.
+	ExactSize  bool
+	StrictSize bool
+
+	// KeySpace determines customer and product keys; a zero KeySpace starts
+	// a new one with a random seed. Pass KeySpace.Continue() of an earlier
+	// run to extend its keyspace without overlap.
+	KeySpace KeySpace
+
+	// Checksum embeds a checksum of the canonical fields in every document
+	Checksum bool
+
+	// BSONTypes adds a bson_types subdocument with a value of every BSON type
+	BSONTypes bool
+
+	// Orders and LineItems fix the number of orders per customer and line
+	// items per order (zero = scale with the document size)
+	Orders    CountRange
+	LineItems CountRange
+
+	// NestingDepth adds a nested chain of subdocuments this many levels deep
+	// (0 = none, at most MaxNestingDepth)
+	NestingDepth int
+
+	// Cardinality limits the distinct values of fields, see ValidateCardinality
+	Cardinality Cardinality
+
+	// Sparsity makes fields missing or null in some documents, see ValidateSparsity
+	Sparsity Sparsity
+
+	// FieldValues draw fields from weighted lists of values, see
+	// ValidateFieldValues. They are set before FieldTemplates.
+	FieldValues FieldValues
+
+	// FieldTemplates derive fields from other fields, see ValidateFieldTemplates
+	FieldTemplates FieldTemplates
+
+	// Locales generates the customer names, addresses, and notes of each
+	// document in one of these locales, picked at random (none =
+	// LocaleDefault), see ValidateLocale
+	Locales []string
+
+	// CoherentAddresses places all addresses of a customer in one country
+	// (of its locale, or the United States), each in a real city with its
+	// region and a postal code of the city, and matches the phone number and
+	// order currencies to the country
+	CoherentAddresses bool
+
+	// NoPII replaces names, emails, phones, and card numbers with obviously
+	// synthetic tokens of the same size and type
+	NoPII bool
+
+	// LegacyFraction is the fraction (0-1) of documents generated in the
+	// template's legacy schema, with fields missing, renamed, or of another
+	// type, and schema_version set to LegacySchemaVersion
+	LegacyFraction float64
+
+	// Template selects the document model: TemplateCustomer (default),
+	// TemplateProduct, TemplateTelemetry, TemplateTransaction,
+	// TemplateMessages, or TemplateEvents
+	Template string
+
+	// TimeRange and ExpectedDocuments spread the timestamps of time-series
+	// templates, and with OrderedTimes created_at, over a load; without
+	// ExpectedDocuments they use the wall clock. Random timestamps of the
+	// other templates fall within TimeRange when it is set.
+	TimeRange         TimeRange
+	ExpectedDocuments int64
+
+	// TimeDistribution spreads timestamps over their range (TimeUniform by
+	// default)
+	TimeDistribution TimeDistribution
+
+	// OrderedTimes spreads created_at (and the order dates of customers) of
+	// the customer, product, and messages templates over TimeRange in
+	// generation order, instead of at random
+	OrderedTimes bool
 }
 
 // NewGenerator creates a new document generator
 func NewGenerator(targetSize DocumentSize) *Generator {
+	return NewGeneratorWithOptions(targetSize, Options{})
+}
+
+// NewGeneratorWithOptions creates a new document generator with optional settings
+func NewGeneratorWithOptions(targetSize DocumentSize, options Options) *Generator {
+	if options.PaddingMode == "" {
+		options.PaddingMode = PaddingRandom
+	}
+	if options.PaddingSizing == "" {
+		options.PaddingSizing = PaddingSizingMarshal
+	}
+	if options.TimeDistribution == "" {
+		options.TimeDistribution = TimeUniform
+	}
+	if options.KeySpace.Seed == 0 {
+		options.KeySpace = NewKeySpace()
+	}
+	targetSize = min(targetSize, MaxTargetSize)
+
 	faker := gofakeit.New(uint64(time.Now().UnixNano()))
 
 	// Precompute padding templates for each size to avoid recomputation
@@ -148,11 +308,25 @@ func NewGenerator(targetSize DocumentSize) *Generator {
 		paddingTemplates[size] = ""
 	}
 
-	return &Generator{
+	g := &Generator{
 		faker:            faker,
 		targetSize:       targetSize,
 		paddingTemplates: paddingTemplates,
+		options:          options,
+		keys:             newKeySequence(options.KeySpace),
+		timeline:         newTimeline(options.TimeRange, options.ExpectedDocuments, options.TimeDistribution),
+		sparsity:         newSparseFields(options.Sparsity),
+		fieldValues:      newValueFields(options.FieldValues),
+		fieldTemplates:   newFieldTemplates(options.FieldTemplates),
+		locales:          newLocales(options.Locales),
 	}
+	g.cardinality = newCardinalityFields(options.Cardinality, g.Schema().KeyField)
+	return g
+}
+
+// KeySpace returns the key space with the customer keys issued so far
+func (g *Generator) KeySpace() KeySpace {
+	return g.keys.issued()
 }
 
 // TargetSize returns the target document size
@@ -162,18 +336,21 @@ func (g *Generator) TargetSize() DocumentSize {
 
 // Generate creates a new customer document with the target size
 func (g *Generator) Generate() (*CustomerDocument, error) {
-	now := time.Now()
+	seq, customerID := g.keys.issue()
+	now := g.now()
+	createdAt, step := g.createdAt(seq, now, 5)
+	now = notBefore(now, createdAt.Add(step))
 
 	// Generate base customer data
 	doc := &CustomerDocument{
 		ID:          primitive.NewObjectID(),
-		CustomerID:  g.faker.UUID(),
+		CustomerID:  customerID,
 		Email:       g.faker.Email(),
 		FirstName:   g.faker.FirstName(),
 		LastName:    g.faker.LastName(),
 		Phone:       g.faker.Phone(),
-		DateOfBirth: g.faker.DateRange(time.Now().AddDate(-80, 0, 0), time.Now().AddDate(-18, 0, 0)),
-		CreatedAt:   g.faker.DateRange(now.AddDate(-5, 0, 0), now),
+		DateOfBirth: g.faker.DateRange(now.AddDate(-80, 0, 0), now.AddDate(-18, 0, 0)),
+		CreatedAt:   createdAt,
 		UpdatedAt:   now,
 	}
 
@@ -234,15 +411,15 @@ func (g *Generator) Generate() (*CustomerDocument, error) {
 	
 	// Orders: scale aggressively with target size to fill most of the document
 	// For 64KB, we want ~50KB+ of meaningful data, so need many orders
-	if targetKB <= 2 {
+	if targetKB <= 2 && g.options.Orders.IsZero() {
 		// For 2KB, add 1 small order to increase base document size
 		doc.Orders = make([]Order, 1)
-		doc.Orders[0] = g.generateOrder(now, targetKB)
+		doc.Orders[0] = g.generateOrder(g.orderDate(createdAt, step, now, 0, 1), now, targetKB)
 	} else {
 		numOrders := g.calculateOrderCount()
 		doc.Orders = make([]Order, numOrders)
 		for i := 0; i < numOrders; i++ {
-			doc.Orders[i] = g.generateOrder(now, targetKB)
+			doc.Orders[i] = g.generateOrder(g.orderDate(createdAt, step, now, i, numOrders), now, targetKB)
 		}
 	}
 	
@@ -296,19 +473,54 @@ func (g *Generator) Generate() (*CustomerDocument, error) {
 		}
 	}
 
-	// Calculate and add padding to reach target size
+	g.applyLocale(doc)
+	g.applyNoPII(doc)
+	g.applyCardinality(doc)
+	if err := g.applyFieldValues(doc); err != nil {
+		return nil, err
+	}
+	if err := g.applyFieldTemplates(doc); err != nil {
+		return nil, err
+	}
+	doc.OrderStats = newOrderStats(doc.Orders)
+	g.applySparsity(doc)
+	g.applyLegacy(doc)
+
+	bsonTypes, err := g.bsonTypes()
+	if err != nil {
+		return nil, err
+	}
+	doc.BSONTypes = bsonTypes
+	doc.Nested = g.nestedChain()
+
+	// Calculate and add padding to reach target size. Legacy documents do
+	// not decode as CustomerDocument, so they are not checksummed.
+	checksum := g.options.Checksum && doc.legacy == nil
+	if checksum {
+		doc.Checksum = checksumPlaceholder
+	}
 	padding, err := g.calculatePadding(doc)
 	if err != nil {
 		return nil, err
 	}
 	doc.Padding = padding
 
+	if checksum {
+		if doc.Checksum, err = DocumentChecksum(doc); err != nil {
+			return nil, err
+		}
+		doc.patchString("checksum", doc.Checksum)
+	}
+
 	return doc, nil
 }
 
 // calculateOrderCount determines how many orders to generate based on target size
 // Goal: Fill 80%+ of document with meaningful data (orders are the main content)
 func (g *Generator) calculateOrderCount() int {
+	if !g.options.Orders.IsZero() {
+		return g.count(g.options.Orders, 0)
+	}
 	targetKB := int(g.targetSize) / 1024
 	
 	// For small documents (4KB), use 1-2 orders
@@ -324,8 +536,10 @@ func (g *Generator) calculateOrderCount() int {
 	if baseCount < 1 {
 		baseCount = 1
 	}
-	if baseCount > 30 {
-		baseCount = 30 // Cap at 30 orders for very large documents
+	if targetKB > 64 {
+		// Orders of large documents average about 6.5KB, with 8-15 line
+		// items, so one order per 8KB fills about 80%
+		baseCount = targetKB / 8
 	}
 	
 	// Add some variation (±1 order)
@@ -343,7 +557,7 @@ func (g *Generator) generateAddress(isDefault bool) Address {
 		ZipCode:   g.faker.Zip(),
 		Country:   g.faker.Country(),
 		IsDefault: isDefault,
-		CreatedAt: g.faker.DateRange(time.Now().AddDate(-3, 0, 0), time.Now()),
+		CreatedAt: g.dateRange(g.now().AddDate(-3, 0, 0), g.now()),
 	}
 }
 
@@ -357,14 +571,12 @@ func (g *Generator) generatePaymentMethod(isDefault bool) PaymentMethod {
 		ExpiryMonth: g.faker.IntRange(1, 12),
 		ExpiryYear:  g.faker.IntRange(2025, 2030),
 		IsDefault:   isDefault,
-		CreatedAt:   g.faker.DateRange(time.Now().AddDate(-2, 0, 0), time.Now()),
+		CreatedAt:   g.dateRange(g.now().AddDate(-2, 0, 0), g.now()),
 	}
 }
 
 // generateOrder creates a fake order with line items
-func (g *Generator) generateOrder(baseTime time.Time, targetKB int) Order {
-	orderDate := g.faker.DateRange(baseTime.AddDate(-2, 0, 0), baseTime)
-
+func (g *Generator) generateOrder(orderDate, baseTime time.Time, targetKB int) Order {
 	// Adjust line items based on target size
 	// Scale up line items for larger documents to fill more space with meaningful data
 	var numLineItems int
@@ -378,9 +590,10 @@ func (g *Generator) generateOrder(baseTime time.Time, targetKB int) Order {
 		// For 64KB documents, use more line items per order
 		numLineItems = g.faker.IntRange(8, 15)
 	}
+	numLineItems = g.count(g.options.LineItems, numLineItems)
 	lineItems := make([]LineItem, numLineItems)
 
-	var totalAmount float64
+	var subtotal float64
 	for i := 0; i < numLineItems; i++ {
 		quantity := g.faker.IntRange(1, 5)
 		unitPrice := g.faker.Price(10, 1000)
@@ -399,17 +612,17 @@ func (g *Generator) generateOrder(baseTime time.Time, targetKB int) Order {
 
 		lineItems[i] = LineItem{
 			ID:          primitive.NewObjectID(),
-			ProductID:   g.faker.UUID(),
+			ProductID:   g.keys.space.ProductKey(g.faker.IntN(g.keys.space.Products)),
 			ProductName: g.faker.Product().Name,
 			SKU:         g.faker.UUID(),
 			Quantity:    quantity,
 			UnitPrice:   unitPrice,
-			TotalPrice:  unitPrice * float64(quantity),
+			TotalPrice:  roundCents(unitPrice * float64(quantity)),
 			Category:    g.faker.Hobby(),
 			Brand:       g.faker.Company(),
 			Description: description,
 		}
-		totalAmount += lineItems[i].TotalPrice
+		subtotal += lineItems[i].TotalPrice
 	}
 
 	// Add discounts - fewer for smaller documents
@@ -420,12 +633,21 @@ func (g *Generator) generateOrder(baseTime time.Time, targetKB int) Order {
 		numDiscounts = g.faker.IntRange(0, 2)
 	}
 	discounts := make([]Discount, numDiscounts)
+	var discountTotal float64
 	for i := 0; i < numDiscounts; i++ {
+		discountType := g.faker.RandomString([]string{"percentage", "fixed"})
+		amount := g.faker.Float64Range(5, 50)
+		if discountType == "percentage" {
+			amount = subtotal * float64(g.faker.RandomInt([]int{5, 10, 15, 20, 25})) / 100
+		}
+		// Discounts never take the order below zero
+		amount = roundCents(min(amount, subtotal-discountTotal))
+		discountTotal += amount
 		discounts[i] = Discount{
 			ID:          primitive.NewObjectID(),
-			Type:        g.faker.RandomString([]string{"percentage", "fixed"}),
+			Type:        discountType,
 			Code:        g.faker.UUID(),
-			Amount:      g.faker.Float64Range(5, 50),
+			Amount:      amount,
 			Description: g.faker.Sentence(5),
 		}
 	}
@@ -437,16 +659,19 @@ func (g *Generator) generateOrder(baseTime time.Time, targetKB int) Order {
 	} else {
 		numTaxes = g.faker.IntRange(1, 3)
 	}
+	// Taxes apply to the discounted amount
 	taxes := make([]Tax, numTaxes)
+	var taxTotal float64
 	for i := 0; i < numTaxes; i++ {
 		taxRate := g.faker.Float64Range(0.05, 0.15)
 		taxes[i] = Tax{
 			ID:          primitive.NewObjectID(),
 			Type:        g.faker.RandomString([]string{"sales", "vat", "shipping"}),
 			Rate:        taxRate,
-			Amount:      totalAmount * taxRate,
+			Amount:      roundCents((subtotal - discountTotal) * taxRate),
 			Description: g.faker.Sentence(5),
 		}
+		taxTotal += taxes[i].Amount
 	}
 
 	status := g.faker.RandomString([]string{"pending", "processing", "shipped", "delivered", "cancelled"})
@@ -464,7 +689,7 @@ func (g *Generator) generateOrder(baseTime time.Time, targetKB int) Order {
 		ID:              primitive.NewObjectID(),
 		OrderNumber:     g.faker.UUID(),
 		Status:          status,
-		TotalAmount:     totalAmount,
+		TotalAmount:     roundCents(subtotal - discountTotal + taxTotal),
 		Currency:        g.faker.Currency().Short,
 		OrderDate:       orderDate,
 		ShippedDate:     shippedDate,
@@ -528,25 +753,44 @@ func (g *Generator) generateExtendedMetadata(targetKB int) map[string]interface{
 	return metadata
 }
 
-// calculatePadding calculates the padding needed to reach target size
-func (g *Generator) calculatePadding(doc *CustomerDocument) (string, error) {
-	// Serialize the document with empty padding to account for field metadata
-	doc.Padding = ""
+// calculatePadding calculates the padding needed to reach target size. doc
+// must still have empty padding, so the field's overhead is accounted for,
+// and get the returned padding with no other changes.
+func (g *Generator) calculatePadding(doc interface{}) (Padding, error) {
 	bsonData, err := bson.Marshal(doc)
 	if err != nil {
-		return "", err
+		return Padding{}, err
+	}
+	if len(bsonData) > MaxTargetSize {
+		return Padding{}, fmt.Errorf("document content of %d bytes exceeds the maximum document size of %d bytes", len(bsonData), MaxTargetSize)
 	}
+	if err := g.checkSize(len(bsonData)); err != nil {
+		return Padding{}, err
+	}
+	padding := g.padding(len(bsonData))
+	if g.options.PaddingSizing == PaddingSizingPatch {
+		reuseEncoding(doc, bsonData, padding)
+	}
+	return padding, nil
+}
 
-	currentSize := len(bsonData)
+// padding generates the padding of a document of currentSize bytes
+func (g *Generator) padding(currentSize int) Padding {
 	targetSize := int(g.targetSize)
+	binary := g.options.PaddingMode == PaddingBinary
 
 	// If already at or above target, no padding needed
 	if currentSize >= targetSize {
-		return "", nil
+		return Padding{Binary: binary}
 	}
 
 	// Calculate padding needed, accounting for BSON field overhead (~12 bytes)
 	paddingNeeded := targetSize - currentSize - 12
+	if g.options.ExactSize {
+		// The empty padding field is already encoded, so every padding byte
+		// adds one byte to the document
+		paddingNeeded = targetSize - g.overhead - currentSize
+	}
 
 	// Enforce padding limits based on document size
 	// For larger documents (>= 8KB), limit padding to 20% to ensure meaningful data is majority
@@ -562,40 +806,30 @@ func (g *Generator) calculatePadding(doc *CustomerDocument) (string, error) {
 	}
 	
 	maxPadding := int(float64(targetSize) * maxPaddingPercent)
-	if paddingNeeded > maxPadding {
+	if paddingNeeded > maxPadding && !g.options.ExactSize {
 		// If base document is too small, cap padding at the calculated percentage
 		paddingNeeded = maxPadding
 	}
 
 	if paddingNeeded <= 0 {
-		return "", nil
+		return Padding{Binary: binary}
 	}
 
-	// Generate high-entropy compression-resistant padding (fast)
-	padding := g.generateCompressionResistantPadding(paddingNeeded)
+	switch g.options.PaddingMode {
+	case PaddingCorpus:
+		return Padding{Data: generateCorpusPadding(g.faker, paddingNeeded)}
+	case PaddingBase64:
+		return Padding{Data: base64Padding(g.faker.Uint64(), paddingNeeded)}
+	}
 
-	return padding, nil
+	// Generate high-entropy compression-resistant padding (fast)
+	return Padding{Data: g.generateCompressionResistantPadding(paddingNeeded), Binary: binary}
 }
 
-// generateCompressionResistantPadding generates high-entropy padding using crypto/rand
-// This creates truly random data that resists compression algorithms
+// generateCompressionResistantPadding generates high-entropy padding that
+// compression algorithms cannot shrink, see randomPadding
 func (g *Generator) generateCompressionResistantPadding(size int) string {
-	padding := make([]byte, size)
-
-	// Use crypto/rand for true randomness - this is the best compression resistance
-	// While it's slightly slower than LFSR, it's still fast enough and provides
-	// maximum entropy that compression algorithms cannot compress
-	_, err := rand.Read(padding)
-	if err != nil {
-		// Fallback to LFSR if crypto/rand fails (shouldn't happen)
-		seed := uint32(uint64(time.Now().UnixNano()) ^ uint64(size))
-		for i := 0; i < size; i++ {
-			seed = (seed << 1) ^ ((seed >> 31) & 0xD0000001)
-			padding[i] = byte(seed ^ (seed >> 8) ^ (seed >> 16) ^ (seed >> 24))
-		}
-	}
-
-	return string(padding)
+	return randomPadding(g.faker.Uint64(), size)
 }
 
 // EstimateSize estimates the BSON size of a document without serializing
@@ -0,0 +1,183 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestDocumentGeneration(t *testing.T) {
+	gen := NewGenerator(Size8KB)
+	
+	doc, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Failed to generate document: %v", err)
+	}
+	
+	if doc == nil {
+		t.Fatal("Generated document is nil")
+	}
+	
+	if doc.CustomerID == "" {
+		t.Error("CustomerID is empty")
+	}
+	
+	if len(doc.Orders) == 0 {
+		t.Error("No orders generated")
+	}
+	
+	if len(doc.Addresses) == 0 {
+		t.Error("No addresses generated")
+	}
+}
+
+func TestDocumentSizes(t *testing.T) {
+	sizes := []DocumentSize{Size2KB, Size4KB, Size8KB, Size16KB, Size32KB, Size64KB}
+	
+	for _, size := range sizes {
+		t.Run(string(rune(size)), func(t *testing.T) {
+			gen := NewGenerator(size)
+			
+			if gen.TargetSize() != size {
+				t.Errorf("Expected target size %d, got %d", size, gen.TargetSize())
+			}
+			
+			doc, err := gen.Generate()
+			if err != nil {
+				t.Fatalf("Failed to generate document: %v", err)
+			}
+			
+			// Verify document has required fields
+			if doc.CustomerID == "" {
+				t.Error("CustomerID is empty")
+			}
+		})
+	}
+}
+
+
+func TestCorpusPadding(t *testing.T) {
+	gen := NewGeneratorWithOptions(Size8KB, Options{PaddingMode: PaddingCorpus})
+
+	doc, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Failed to generate document: %v", err)
+	}
+
+	if !utf8.ValidString(doc.Padding.Data) {
+		t.Error("Corpus padding is not valid UTF-8")
+	}
+	if doc.Padding.Data != "" && !strings.Contains(doc.Padding.Data, " ") {
+		t.Error("Corpus padding does not look like natural-language text")
+	}
+}
+
+func TestOrderDocumentsReferenceCustomer(t *testing.T) {
+	gen := NewGenerator(Size8KB)
+
+	doc, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Failed to generate document: %v", err)
+	}
+
+	orders := OrderDocuments(doc)
+	if len(orders) != len(doc.Orders) {
+		t.Fatalf("Expected %d orders, got %d", len(doc.Orders), len(orders))
+	}
+	for _, o := range orders {
+		order := o.(*OrderDocument)
+		if order.CustomerID != doc.CustomerID {
+			t.Errorf("Order references %s, expected %s", order.CustomerID, doc.CustomerID)
+		}
+	}
+}
+
+func TestKeySpaceCorrelatesRuns(t *testing.T) {
+	runA := NewGeneratorWithOptions(Size2KB, Options{KeySpace: KeySpace{Seed: 42}})
+
+	var keysA []string
+	for i := 0; i < 5; i++ {
+		doc, err := runA.Generate()
+		if err != nil {
+			t.Fatalf("Failed to generate document: %v", err)
+		}
+		keysA = append(keysA, doc.CustomerID)
+	}
+
+	space := runA.KeySpace()
+	if space.First != 0 || space.Count != 5 {
+		t.Fatalf("Expected keys [0, 5), got first %d count %d", space.First, space.Count)
+	}
+	for i, key := range keysA {
+		if got := space.CustomerKey(int64(i)); got != key {
+			t.Errorf("Key %d: expected %s, got %s", i, key, got)
+		}
+	}
+
+	// An incremental run continues the key space without overlap
+	runB := NewGeneratorWithOptions(Size2KB, Options{KeySpace: space.Continue()})
+	doc, err := runB.Generate()
+	if err != nil {
+		t.Fatalf("Failed to generate document: %v", err)
+	}
+	if doc.CustomerID != space.CustomerKey(5) {
+		t.Errorf("Expected continued key %s, got %s", space.CustomerKey(5), doc.CustomerID)
+	}
+}
+
+func TestChecksumSurvivesRoundTrip(t *testing.T) {
+	gen := NewGeneratorWithOptions(Size8KB, Options{Checksum: true})
+
+	doc, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Failed to generate document: %v", err)
+	}
+	if len(doc.Checksum) != 64 {
+		t.Fatalf("Expected a hex SHA-256 checksum, got %q", doc.Checksum)
+	}
+
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Failed to marshal document: %v", err)
+	}
+	var stored CustomerDocument
+	if err := bson.Unmarshal(data, &stored); err != nil {
+		t.Fatalf("Failed to unmarshal document: %v", err)
+	}
+
+	sum, err := DocumentChecksum(&stored)
+	if err != nil {
+		t.Fatalf("Failed to compute checksum: %v", err)
+	}
+	if sum != doc.Checksum {
+		t.Errorf("Checksum changed after round trip: %s != %s", sum, doc.Checksum)
+	}
+
+	stored.Email = "corrupted@example.com"
+	if sum, _ := DocumentChecksum(&stored); sum == doc.Checksum {
+		t.Error("Checksum did not detect a modified field")
+	}
+}
+
+func TestLargeDocuments(t *testing.T) {
+	if got := NewGenerator(Size16MB).TargetSize(); got != MaxTargetSize {
+		t.Errorf("16MB documents target %d bytes, want %d", got, MaxTargetSize)
+	}
+
+	doc, err := NewGenerator(Size1MB).Generate()
+	if err != nil {
+		t.Fatalf("Failed to generate document: %v", err)
+	}
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) < int(Size1MB)*9/10 || len(data) > int(Size1MB) {
+		t.Errorf("1MB document has %d bytes", len(data))
+	}
+	if len(doc.Orders) < 100 {
+		t.Errorf("1MB document has %d orders, want over 100", len(doc.Orders))
+	}
+}
@@ -0,0 +1,171 @@
+package model
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EventDocument represents an append-only application log event
+type EventDocument struct {
+	ID         primitive.ObjectID     `bson:"_id"` // Ordered like ts, for clustered collections
+	EventID    string                 `bson:"event_id"`
+	Seq        int64                  `bson:"seq"`
+	Timestamp  time.Time              `bson:"ts"`
+	Level      string                 `bson:"level"`
+	Service    string                 `bson:"service"`
+	Host       string                 `bson:"host"`
+	Message    string                 `bson:"message"`
+	TraceID    string                 `bson:"trace_id"`
+	SpanID     string                 `bson:"span_id"`
+	UserID     string                 `bson:"user_id"`
+	StatusCode int                    `bson:"status_code"`
+	DurationMS float64                `bson:"duration_ms"`
+	Attributes map[string]interface{} `bson:"attributes"`
+
+	Metadata map[string]interface{} `bson:"metadata"`
+	Tags     []string               `bson:"tags"`
+
+	// Padding field to control document size
+	Padding Padding `bson:"padding"`
+
+	// Sample of every BSON type (Options.BSONTypes)
+	BSONTypes *BSONTypes `bson:"bson_types,omitempty"`
+
+	// Chain of subdocuments Options.NestingDepth levels deep
+	Nested bson.D `bson:"nested,omitempty"`
+
+	docShape // Sparse fields and legacy schema (Options.Sparsity, Options.LegacyFraction)
+}
+
+// eventServices are the services events are logged by
+var eventServices = []string{"gateway", "auth", "checkout", "inventory", "search", "notify", "billing", "recs"}
+
+// DocumentID returns the document's _id
+func (d *EventDocument) DocumentID() primitive.ObjectID { return d.ID }
+
+// SetDocumentID replaces the document's _id
+func (d *EventDocument) SetDocumentID(id primitive.ObjectID) {
+	d.ID = id
+	d.encoded = nil
+}
+
+// SetMetadata sets metadata.key
+func (d *EventDocument) SetMetadata(key string, value interface{}) {
+	if d.Metadata == nil {
+		d.Metadata = make(map[string]interface{})
+	}
+	d.Metadata[key] = value
+	d.patchMetadata(key, value)
+}
+
+// MarshalBSON encodes the document in its shape, see docShape
+func (d *EventDocument) MarshalBSON() ([]byte, error) {
+	type plain EventDocument
+	return d.docShape.marshal((*plain)(d))
+}
+
+func (d *EventDocument) shape() *docShape { return &d.docShape }
+
+// GenerateEvent creates a new log event with the target size. ts increases
+// with the event sequence across all generation workers, and so does _id.
+func (g *Generator) GenerateEvent() (*EventDocument, error) {
+	n, eventID := g.keys.issue()
+	seq := g.keys.space.First + n
+	ts := g.timeline.at(n).Truncate(time.Millisecond)
+	service := eventServices[g.faker.IntRange(0, len(eventServices)-1)]
+	level, status := g.eventLevel()
+
+	doc := &EventDocument{
+		ID:         eventObjectID(ts, g.keys.space.Seed, seq),
+		EventID:    eventID,
+		Seq:        seq,
+		Timestamp:  ts,
+		Level:      level,
+		Service:    service,
+		Host:       fmt.Sprintf("%s-%d", service, g.faker.IntRange(1, 24)),
+		Message:    g.faker.Sentence(g.faker.IntRange(3, 6)),
+		TraceID:    fmt.Sprintf("%016x%016x", g.faker.Uint64(), g.faker.Uint64()),
+		SpanID:     fmt.Sprintf("%016x", g.faker.Uint64()),
+		UserID:     g.keys.space.UserKey(g.faker.IntRange(0, DefaultUserCount-1)),
+		StatusCode: status,
+		DurationMS: float64(g.faker.IntRange(1, 250000)) / 100,
+		Attributes: map[string]interface{}{
+			"http.method": g.faker.HTTPMethod(),
+			"http.path":   "/" + g.faker.Word(),
+		},
+		Metadata: map[string]interface{}{"created_by": "system"},
+		Tags:     []string{service, level},
+	}
+
+	// Larger events carry more attributes
+	for i := 0; i < g.attributeCount(); i++ {
+		doc.Attributes[fmt.Sprintf("attr.%s.%d", g.faker.Word(), i)] = g.faker.Sentence(3)
+	}
+
+	g.applyCardinality(doc)
+	if err := g.applyFieldValues(doc); err != nil {
+		return nil, err
+	}
+	if err := g.applyFieldTemplates(doc); err != nil {
+		return nil, err
+	}
+	g.applySparsity(doc)
+	g.applyLegacy(doc)
+
+	bsonTypes, err := g.bsonTypes()
+	if err != nil {
+		return nil, err
+	}
+	doc.BSONTypes = bsonTypes
+	doc.Nested = g.nestedChain()
+
+	padding, err := g.calculatePadding(doc)
+	if err != nil {
+		return nil, err
+	}
+	doc.Padding = padding
+
+	return doc, nil
+}
+
+// eventLevel picks a log level and a matching status code, mostly info
+func (g *Generator) eventLevel() (string, int) {
+	switch n := g.faker.IntRange(1, 100); {
+	case n <= 2:
+		return "error", g.faker.RandomInt([]int{500, 502, 503, 504})
+	case n <= 8:
+		return "warn", g.faker.RandomInt([]int{400, 401, 403, 404, 429})
+	case n <= 20:
+		return "debug", 200
+	}
+	return "info", g.faker.RandomInt([]int{200, 200, 200, 201, 204})
+}
+
+// attributeCount determines how many extra attributes (~55 bytes each) fill
+// most of an event
+func (g *Generator) attributeCount() int {
+	count := (int(g.targetSize)*85/100 - 520) / 55
+	if count < 0 {
+		count = 0
+	}
+	return count
+}
+
+// eventObjectID builds an ObjectID that sorts by ts (to the second) and then,
+// within a key space, by seq: the timestamp, three bytes of the key space seed
+// so that separate key spaces loaded into one collection don't collide, and
+// the sequence number
+func eventObjectID(ts time.Time, seed int64, seq int64) primitive.ObjectID {
+	var id primitive.ObjectID
+	binary.BigEndian.PutUint32(id[0:4], uint32(ts.Unix()))
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(seed))
+	copy(id[4:7], buf[5:8])
+	binary.BigEndian.PutUint64(buf[:], uint64(seq))
+	copy(id[7:12], buf[3:8])
+	return id
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestEventDocumentSizes(t *testing.T) {
+	sizes := []DocumentSize{Size512B, Size1KB, Size2KB, Size4KB, Size8KB}
+
+	for _, size := range sizes {
+		gen := NewGeneratorWithOptions(size, Options{Template: TemplateEvents})
+
+		doc, err := gen.GenerateDocument()
+		if err != nil {
+			t.Fatalf("Failed to generate event: %v", err)
+		}
+		event, ok := doc.(*EventDocument)
+		if !ok {
+			t.Fatalf("Expected an event document, got %T", doc)
+		}
+
+		data, err := bson.Marshal(event)
+		if err != nil {
+			t.Fatalf("Failed to marshal event: %v", err)
+		}
+		t.Logf("%s: %d bytes, %d padding, %d attributes", size, len(data), len(event.Padding.Data), len(event.Attributes))
+		if len(data) < int(size)*9/10 || len(data) > int(size)*11/10 {
+			t.Errorf("%s event is %d bytes, want within 10%%", size, len(data))
+		}
+	}
+}
+
+func TestEventOrder(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	gen := NewGeneratorWithOptions(Size512B, Options{
+		Template:          TemplateEvents,
+		TimeRange:         TimeRange{Start: start, End: start.Add(time.Hour)},
+		ExpectedDocuments: 10000,
+	})
+
+	var prev *EventDocument
+	for i := 0; i < 10000; i++ {
+		event, err := gen.GenerateEvent()
+		if err != nil {
+			t.Fatalf("Failed to generate event: %v", err)
+		}
+		if prev != nil {
+			if !event.Timestamp.After(prev.Timestamp) || event.Seq != prev.Seq+1 {
+				t.Fatalf("Event %d at %s (seq %d) does not follow %s (seq %d)", i, event.Timestamp, event.Seq, prev.Timestamp, prev.Seq)
+			}
+			if bytes.Compare(event.ID[:], prev.ID[:]) <= 0 {
+				t.Fatalf("Event %d _id %s does not sort after %s", i, event.ID.Hex(), prev.ID.Hex())
+			}
+		}
+		prev = event
+	}
+	if prev.Timestamp.After(start.Add(time.Hour)) {
+		t.Errorf("Last event at %s, after the end of the range", prev.Timestamp)
+	}
+}
+
+func TestDocumentSizeString(t *testing.T) {
+	for size, want := range map[DocumentSize]string{Size512B: "512B", Size1KB: "1KB", Size64KB: "64KB", Size256KB: "256KB", Size16MB: "16MB"} {
+		if got := size.String(); got != want {
+			t.Errorf("DocumentSize(%d).String() = %s, want %s", int(size), got, want)
+		}
+	}
+}
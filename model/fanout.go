@@ -0,0 +1,82 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// MaxNestingDepth is the deepest nested chain Options.NestingDepth allows;
+// MongoDB rejects documents nested more than 100 levels deep
+const MaxNestingDepth = 99
+
+// CountRange is an inclusive range of counts, such as the length of an
+// array. The zero range means the template's default.
+type CountRange struct {
+	Min int
+	Max int
+}
+
+// IsZero reports whether no range was set
+func (r CountRange) IsZero() bool {
+	return r.Min == 0 && r.Max == 0
+}
+
+// String formats the range as N or MIN-MAX, the format ParseCountRange accepts
+func (r CountRange) String() string {
+	if r.Min == r.Max {
+		return strconv.Itoa(r.Min)
+	}
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
+
+// ParseCountRange parses "N" or "MIN-MAX" ("" = the zero range)
+func ParseCountRange(s string) (CountRange, error) {
+	if s == "" {
+		return CountRange{}, nil
+	}
+	lo, hi, isRange := strings.Cut(s, "-")
+	if !isRange {
+		hi = lo
+	}
+
+	var r CountRange
+	var err error
+	if r.Min, err = strconv.Atoi(strings.TrimSpace(lo)); err != nil {
+		return CountRange{}, fmt.Errorf("invalid count %q: want N or MIN-MAX", s)
+	}
+	if r.Max, err = strconv.Atoi(strings.TrimSpace(hi)); err != nil {
+		return CountRange{}, fmt.Errorf("invalid count %q: want N or MIN-MAX", s)
+	}
+	if r.Min < 1 || r.Max < r.Min {
+		return CountRange{}, fmt.Errorf("invalid count %q: want 1 <= MIN <= MAX", s)
+	}
+	return r, nil
+}
+
+// count returns a random count in r, or def if r is the zero range
+func (g *Generator) count(r CountRange, def int) int {
+	if r.IsZero() {
+		return def
+	}
+	return g.faker.IntRange(r.Min, r.Max)
+}
+
+// nestedChain returns a chain of Options.NestingDepth subdocuments, each the
+// child of the previous one, or nil if no depth was set
+func (g *Generator) nestedChain() bson.D {
+	var chain bson.D
+	for depth := g.options.NestingDepth; depth > 0; depth-- {
+		level := bson.D{
+			{Key: "depth", Value: int32(depth)},
+			{Key: "name", Value: g.faker.Word()},
+		}
+		if chain != nil {
+			level = append(level, bson.E{Key: "child", Value: chain})
+		}
+		chain = level
+	}
+	return chain
+}
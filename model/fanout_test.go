@@ -0,0 +1,104 @@
+package model
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParseCountRange(t *testing.T) {
+	tests := []struct {
+		in   string
+		want CountRange
+	}{
+		{"", CountRange{}},
+		{"5", CountRange{5, 5}},
+		{"3-8", CountRange{3, 8}},
+	}
+	for _, tt := range tests {
+		got, err := ParseCountRange(tt.in)
+		if err != nil {
+			t.Fatalf("ParseCountRange(%q): %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseCountRange(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, invalid := range []string{"0", "-3", "8-3", "a", "3-b"} {
+		if _, err := ParseCountRange(invalid); err == nil {
+			t.Errorf("Expected error for count %q", invalid)
+		}
+	}
+}
+
+func TestArrayLengthsIndependentOfSize(t *testing.T) {
+	for _, size := range []DocumentSize{Size2KB, Size64KB} {
+		gen := NewGeneratorWithOptions(size, Options{
+			Orders:    CountRange{Min: 6, Max: 6},
+			LineItems: CountRange{Min: 1, Max: 2},
+		})
+
+		doc, err := gen.Generate()
+		if err != nil {
+			t.Fatalf("Failed to generate document: %v", err)
+		}
+		if len(doc.Orders) != 6 {
+			t.Errorf("%dKB document has %d orders, want 6", size/1024, len(doc.Orders))
+		}
+		for _, order := range doc.Orders {
+			if n := len(order.LineItems); n < 1 || n > 2 {
+				t.Errorf("%dKB order has %d line items, want 1-2", size/1024, n)
+			}
+		}
+	}
+}
+
+func TestNestingDepth(t *testing.T) {
+	for _, template := range []string{TemplateCustomer, TemplateEvents} {
+		gen := NewGeneratorWithOptions(Size2KB, Options{Template: template, NestingDepth: MaxNestingDepth})
+		doc, err := gen.GenerateDocument()
+		if err != nil {
+			t.Fatalf("Failed to generate %s document: %v", template, err)
+		}
+		data, err := bson.Marshal(doc)
+		if err != nil {
+			t.Fatalf("Failed to marshal %s document: %v", template, err)
+		}
+
+		// Follow nested.child.child... to the deepest level
+		depth := 0
+		level, err := bson.Raw(data).LookupErr("nested")
+		for err == nil {
+			depth++
+			level, err = level.Document().LookupErr("child")
+		}
+		if depth != MaxNestingDepth {
+			t.Errorf("%s document is nested %d levels deep, want %d", template, depth, MaxNestingDepth)
+		}
+	}
+}
+
+func TestNestedChecksumRoundTrip(t *testing.T) {
+	gen := NewGeneratorWithOptions(Size4KB, Options{Checksum: true, NestingDepth: 10})
+	doc, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Failed to generate document: %v", err)
+	}
+
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Failed to marshal document: %v", err)
+	}
+	var decoded CustomerDocument
+	if err := bson.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal document: %v", err)
+	}
+	checksum, err := DocumentChecksum(&decoded)
+	if err != nil {
+		t.Fatalf("Failed to checksum document: %v", err)
+	}
+	if checksum != doc.Checksum {
+		t.Errorf("Checksum changed after a round trip: %s, want %s", checksum, doc.Checksum)
+	}
+}
@@ -0,0 +1,325 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldTemplate derives a field of every document from other fields with a
+// Go text/template, such as "{{lower .FirstName}}@example.com" for email
+type FieldTemplate struct {
+	// Field is a dotted path, like those of Cardinality, to a string or
+	// number field. Fields inside arrays are set per array element.
+	Field string
+
+	// Template is executed on the subdocument holding the field, so that
+	// its fields are available by their Go names (.FirstName, .Quantity).
+	// The output is parsed as a number for number fields.
+	Template string
+}
+
+// FieldTemplates are applied in order, so a template sees the values set by
+// the templates before it
+type FieldTemplates []FieldTemplate
+
+// ParseFieldTemplates parses a YAML or JSON mapping of field paths to
+// templates, keeping the order of the mapping
+func ParseFieldTemplates(data []byte) (FieldTemplates, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse field templates: %w", err)
+	}
+	if len(root.Content) == 0 {
+		return nil, nil
+	}
+	mapping := root.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a mapping of fields to templates")
+	}
+
+	var ft FieldTemplates
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		field, text := mapping.Content[i], mapping.Content[i+1]
+		if text.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("template of %s is not a string", field.Value)
+		}
+		if seen[field.Value] {
+			return nil, fmt.Errorf("duplicate field %s", field.Value)
+		}
+		seen[field.Value] = true
+		ft = append(ft, FieldTemplate{Field: field.Value, Template: text.Value})
+	}
+	return ft, nil
+}
+
+// ValidateFieldTemplates checks that every template of ft parses, sets a
+// string or number field of the template's documents other than _id and
+// the key field, and executes on a generated document
+func ValidateFieldTemplates(template string, ft FieldTemplates) error {
+	if len(ft) == 0 {
+		return nil
+	}
+	schema := NewGeneratorWithOptions(Size2KB, Options{Template: template}).Schema()
+	docType := reflect.TypeOf(templateDocument(template)).Elem()
+
+	for _, f := range ft {
+		if f.Field == "_id" || f.Field == schema.KeyField {
+			return fmt.Errorf("%s cannot be set by a template", f.Field)
+		}
+		if err := checkFieldPath(docType, strings.Split(f.Field, "."), settableKind); err != nil {
+			return fmt.Errorf("invalid template field %s: %w", f.Field, err)
+		}
+	}
+
+	g := NewGeneratorWithOptions(Size2KB, Options{Template: template, FieldTemplates: ft})
+	_, err := g.GenerateDocument()
+	return err
+}
+
+// fieldTemplates are the parsed Options.FieldTemplates of a generator
+type fieldTemplates struct {
+	fields []FieldTemplate
+	paths  [][]string
+	sets   sync.Pool // *templateSet, one per concurrent document
+	err    error     // Parsing error, returned for every document
+}
+
+// templateSet holds the parsed templates for one document at a time, as
+// their root function returns the document being generated
+type templateSet struct {
+	root      Document
+	templates []*template.Template
+}
+
+// newFieldTemplates parses ft (nil if there are no templates)
+func newFieldTemplates(ft FieldTemplates) *fieldTemplates {
+	if len(ft) == 0 {
+		return nil
+	}
+	t := &fieldTemplates{fields: ft}
+	for _, f := range ft {
+		t.paths = append(t.paths, strings.Split(f.Field, "."))
+	}
+	set, err := t.newSet()
+	if err != nil {
+		t.err = err
+		return t
+	}
+	t.sets.Put(set)
+	return t
+}
+
+// newSet parses the templates
+func (t *fieldTemplates) newSet() (*templateSet, error) {
+	set := &templateSet{}
+	funcs := templateFuncs()
+	funcs["root"] = func() Document { return set.root }
+	for _, f := range t.fields {
+		tmpl, err := template.New(f.Field).Option("missingkey=error").Funcs(funcs).Parse(f.Template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template of %s: %w", f.Field, err)
+		}
+		set.templates = append(set.templates, tmpl)
+	}
+	return set, nil
+}
+
+// applyFieldTemplates sets the templated fields of doc in order
+func (g *Generator) applyFieldTemplates(doc Document) error {
+	t := g.fieldTemplates
+	if t == nil {
+		return nil
+	}
+	if t.err != nil {
+		return t.err
+	}
+	set, _ := t.sets.Get().(*templateSet)
+	if set == nil {
+		set, _ = t.newSet() // Parsed without errors before
+	}
+	set.root = doc
+	defer func() {
+		set.root = nil
+		t.sets.Put(set)
+	}()
+
+	for i, tmpl := range set.templates {
+		execute := func(data interface{}) (string, error) {
+			var out strings.Builder
+			if err := tmpl.Execute(&out, data); err != nil {
+				return "", err
+			}
+			return out.String(), nil
+		}
+		if err := setTemplateField(reflect.ValueOf(doc), t.paths[i], execute); err != nil {
+			return fmt.Errorf("failed to set %s: %w", t.fields[i].Field, err)
+		}
+	}
+	return nil
+}
+
+// setTemplateField walks path from v and sets every field it reaches to the
+// output of execute on the subdocument holding it
+func setTemplateField(v reflect.Value, path []string, execute func(data interface{}) (string, error)) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return setTemplateField(v.Elem(), path, execute)
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := setTemplateField(v.Index(i), path, execute); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+	default:
+		return nil
+	}
+
+	index, ok := fieldIndex(v.Type(), path[0])
+	if !ok {
+		return nil
+	}
+	if len(path) > 1 {
+		return setTemplateField(v.Field(index), path[1:], execute)
+	}
+
+	data := v.Interface()
+	if v.CanAddr() {
+		data = v.Addr().Interface()
+	}
+	out, err := execute(data)
+	if err != nil {
+		return err
+	}
+	return setTemplateValue(v.Field(index), out)
+}
+
+// setTemplateValue assigns the output of a template, or a listed value, to a
+// string or number field
+func setTemplateValue(v reflect.Value, out string) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.String {
+		v.SetString(out)
+		return nil
+	}
+
+	n, err := strconv.ParseFloat(strings.TrimSpace(out), 64)
+	if err != nil {
+		return fmt.Errorf("%q is not a number", out)
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		if n != math.Trunc(n) {
+			return fmt.Errorf("%q is not an integer", out)
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		v.SetFloat(n)
+	}
+	return nil
+}
+
+// templateFuncs returns the functions available to field templates, besides
+// the text/template builtins (printf, len, index, ...) and root, which
+// returns the whole document
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"lower":   strings.ToLower,
+		"upper":   strings.ToUpper,
+		"trim":    strings.TrimSpace,
+		"replace": func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"join":    func(sep string, elems []string) string { return strings.Join(elems, sep) },
+		"add":     arithmetic(func(x, y float64) (float64, error) { return x + y, nil }),
+		"sub":     arithmetic(func(x, y float64) (float64, error) { return x - y, nil }),
+		"mul":     arithmetic(func(x, y float64) (float64, error) { return x * y, nil }),
+		"div": arithmetic(func(x, y float64) (float64, error) {
+			if y == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			return x / y, nil
+		}),
+		"round": func(places int, x interface{}) (float64, error) {
+			f, err := toFloat(x)
+			if err != nil {
+				return 0, err
+			}
+			scale := math.Pow(10, float64(places))
+			return math.Round(f*scale) / scale, nil
+		},
+		"sum": sumField,
+	}
+}
+
+// arithmetic returns a template function applying op to two numbers of any
+// numeric type
+func arithmetic(op func(x, y float64) (float64, error)) func(a, b interface{}) (float64, error) {
+	return func(a, b interface{}) (float64, error) {
+		x, err := toFloat(a)
+		if err != nil {
+			return 0, err
+		}
+		y, err := toFloat(b)
+		if err != nil {
+			return 0, err
+		}
+		return op(x, y)
+	}
+}
+
+// toFloat converts a number of any numeric type to float64
+func toFloat(x interface{}) (float64, error) {
+	v := reflect.ValueOf(x)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return strconv.ParseFloat(v.String(), 64)
+	}
+	return 0, fmt.Errorf("%v is not a number", x)
+}
+
+// sumField adds up the numbers of a list, or with a field name, the field of
+// every subdocument in a list: sum .LineItems "TotalPrice"
+func sumField(list interface{}, field ...string) (float64, error) {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice {
+		return 0, fmt.Errorf("sum of %T, not a list", list)
+	}
+	var total float64
+	for i := 0; i < v.Len(); i++ {
+		item := reflect.Indirect(v.Index(i))
+		if len(field) > 0 {
+			if item.Kind() != reflect.Struct || !item.FieldByName(field[0]).IsValid() {
+				return 0, fmt.Errorf("sum of %s: no such field", field[0])
+			}
+			item = item.FieldByName(field[0])
+		}
+		f, err := toFloat(item.Interface())
+		if err != nil {
+			return 0, err
+		}
+		total += f
+	}
+	return total, nil
+}
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testFieldTemplates = `
+orders.line_items.total_price: "{{mul .Quantity .UnitPrice | round 2}}"
+orders.total_amount: '{{sum .LineItems "TotalPrice" | round 2}}'
+email: "{{lower .FirstName}}.{{lower .LastName}}@example.com"
+orders.notes: "{{.OrderNumber}} for {{root.CustomerID}}"
+`
+
+func TestParseFieldTemplates(t *testing.T) {
+	ft, err := ParseFieldTemplates([]byte(testFieldTemplates))
+	if err != nil {
+		t.Fatalf("Failed to parse field templates: %v", err)
+	}
+	var fields []string
+	for _, f := range ft {
+		fields = append(fields, f.Field)
+	}
+	if got := strings.Join(fields, ","); got != "orders.line_items.total_price,orders.total_amount,email,orders.notes" {
+		t.Errorf("Expected the order of the file, got %s", got)
+	}
+
+	if ft, err := ParseFieldTemplates([]byte(`{"email": "{{.FirstName}}@example.com"}`)); err != nil || len(ft) != 1 {
+		t.Errorf("Expected JSON to parse, got %v, %v", ft, err)
+	}
+	for _, invalid := range []string{"- email", "email: [a, b]", "email: a\nemail: b", "email: ["} {
+		if _, err := ParseFieldTemplates([]byte(invalid)); err == nil {
+			t.Errorf("Expected error for field templates %q", invalid)
+		}
+	}
+}
+
+func TestValidateFieldTemplates(t *testing.T) {
+	ft, _ := ParseFieldTemplates([]byte(testFieldTemplates))
+	if err := ValidateFieldTemplates(TemplateCustomer, ft); err != nil {
+		t.Errorf("ValidateFieldTemplates: %v", err)
+	}
+
+	invalid := []string{
+		"_id: x",
+		"customer_id: x",
+		"orders: x",
+		"nope: x",
+		"email: '{{.FirstName'",
+		"email: '{{.Nope}}'",
+		"phone: '{{nope}}'",
+		"orders.total_amount: '{{.Status}}'",
+		"orders.line_items.quantity: '{{.SKU}}'",
+	}
+	for _, text := range invalid {
+		ft, err := ParseFieldTemplates([]byte(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ValidateFieldTemplates(TemplateCustomer, ft); err == nil {
+			t.Errorf("Expected error for field template %s", text)
+		}
+	}
+}
+
+func TestFieldTemplatesDeriveFields(t *testing.T) {
+	ft, _ := ParseFieldTemplates([]byte(testFieldTemplates))
+	gen := NewGeneratorWithOptions(Size8KB, Options{FieldTemplates: ft, Orders: CountRange{Min: 2, Max: 4}})
+
+	// Documents are generated concurrently, each with its own root
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				doc, err := gen.Generate()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if want := strings.ToLower(doc.FirstName + "." + doc.LastName + "@example.com"); doc.Email != want {
+					t.Errorf("Expected email %s, got %s", want, doc.Email)
+				}
+				for _, order := range doc.Orders {
+					var total float64
+					for _, item := range order.LineItems {
+						if math.Abs(item.TotalPrice-float64(item.Quantity)*item.UnitPrice) > 0.005 {
+							t.Errorf("Line item total %v is not %d x %v", item.TotalPrice, item.Quantity, item.UnitPrice)
+						}
+						total += item.TotalPrice
+					}
+					if math.Abs(order.TotalAmount-total) > 0.005 {
+						t.Errorf("Order total %v is not the sum of its line items %v", order.TotalAmount, total)
+					}
+					if want := order.OrderNumber + " for " + doc.CustomerID; order.Notes != want {
+						t.Errorf("Expected notes %q, got %q", want, order.Notes)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
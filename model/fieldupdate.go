@@ -0,0 +1,125 @@
+package model
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// ValidateUpdateFields checks that every field is a dotted path into the
+// template's documents, with array elements addressed by index (like
+// orders.0.status), other than _id and the key field, and that no field is
+// within another
+func ValidateUpdateFields(template string, fields []string) error {
+	schema := NewGeneratorWithOptions(Size2KB, Options{Template: template}).Schema()
+	docType := reflect.TypeOf(templateDocument(template)).Elem()
+
+	for i, field := range fields {
+		path := strings.Split(field, ".")
+		if path[0] == "_id" || path[0] == schema.KeyField {
+			return fmt.Errorf("%s cannot be updated", path[0])
+		}
+		if err := checkUpdatePath(docType, path); err != nil {
+			return fmt.Errorf("invalid update field %s: %w", field, err)
+		}
+		for _, other := range fields[:i] {
+			if other == field || strings.HasPrefix(field, other+".") || strings.HasPrefix(other, field+".") {
+				return fmt.Errorf("update fields %s and %s overlap", other, field)
+			}
+		}
+	}
+	return nil
+}
+
+// checkUpdatePath checks that path leads through structs, and slices by
+// index, to a field. Maps and interfaces accept any path.
+func checkUpdatePath(t reflect.Type, path []string) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if len(path) == 0 {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Map, reflect.Interface:
+		return nil
+	case reflect.Slice, reflect.Array:
+		if n, err := strconv.Atoi(path[0]); err != nil || n < 0 {
+			return fmt.Errorf("%s is not an array index", path[0])
+		}
+		return checkUpdatePath(t.Elem(), path[1:])
+	case reflect.Struct:
+		index, ok := fieldIndex(t, path[0])
+		if !ok {
+			return fmt.Errorf("unknown field %s", path[0])
+		}
+		return checkUpdatePath(t.Field(index).Type, path[1:])
+	}
+	return fmt.Errorf("%s is not a field of a subdocument", path[0])
+}
+
+// FieldUpdater builds updates setting only chosen fields, to isolate the
+// maintenance cost of the indexes on them. Their new values are drawn from
+// the values of generated documents; dates are set to the current time.
+type FieldUpdater struct {
+	fields []string
+	values [][]bson.RawValue // Sampled values of each field
+}
+
+// NewFieldUpdater samples the values of fields (see ValidateUpdateFields)
+// from samples documents generated like those of g, without issuing keys of
+// g. A field missing from every sample, such as orders.5.status of
+// customers with fewer orders, is an error.
+func (g *Generator) NewFieldUpdater(fields []string, samples int) (*FieldUpdater, error) {
+	options := g.options
+	options.KeySpace = KeySpace{}
+	sampler := NewGeneratorWithOptions(g.targetSize, options)
+
+	u := &FieldUpdater{fields: fields, values: make([][]bson.RawValue, len(fields))}
+	for i := 0; i < samples; i++ {
+		doc, err := sampler.GenerateDocument()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate sample document: %w", err)
+		}
+		raw, err := bson.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal sample document: %w", err)
+		}
+		for j, field := range fields {
+			if value, err := bson.Raw(raw).LookupErr(strings.Split(field, ".")...); err == nil {
+				u.values[j] = append(u.values[j], value)
+			}
+		}
+	}
+	for j, field := range fields {
+		if len(u.values[j]) == 0 {
+			return nil, fmt.Errorf("no generated document has %s", field)
+		}
+	}
+	return u, nil
+}
+
+// Fields returns the fields the updates set
+func (u *FieldUpdater) Fields() []string {
+	return u.fields
+}
+
+// Update returns a $set of every field to a value drawn at random
+func (u *FieldUpdater) Update(rng *rand.Rand) bson.D {
+	set := make(bson.D, len(u.fields))
+	for i, field := range u.fields {
+		value := u.values[i][rng.Intn(len(u.values[i]))]
+		if value.Type == bsontype.DateTime {
+			set[i] = bson.E{Key: field, Value: time.Now()}
+		} else {
+			set[i] = bson.E{Key: field, Value: value}
+		}
+	}
+	return bson.D{{Key: "$set", Value: set}}
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestValidateUpdateFields(t *testing.T) {
+	if err := ValidateUpdateFields(TemplateCustomer, []string{"orders.0.status", "updated_at", "metadata.source"}); err != nil {
+		t.Fatal(err)
+	}
+	for fields, want := range map[string]string{
+		"_id":                      "cannot be updated",
+		"customer_id":              "cannot be updated",
+		"orders.status":            "not an array index",
+		"orders.0.nope":            "unknown field",
+		"email.domain":             "not a field of a subdocument",
+		"orders.0,orders.0.status": "overlap",
+	} {
+		if err := ValidateUpdateFields(TemplateCustomer, strings.Split(fields, ",")); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ValidateUpdateFields(%s) = %v, want an error containing %q", fields, err, want)
+		}
+	}
+}
+
+func TestFieldUpdaterSetsOnlyFields(t *testing.T) {
+	g := NewGeneratorWithOptions(Size2KB, Options{Template: TemplateCustomer})
+	u, err := g.NewFieldUpdater([]string{"orders.0.status", "updated_at"}, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issued := g.KeySpace(); issued.Count != 0 {
+		t.Errorf("Sampling issued %d keys", issued.Count)
+	}
+
+	update := u.Update(rand.New(rand.NewSource(1)))
+	if len(update) != 1 || update[0].Key != "$set" {
+		t.Fatalf("Unexpected update %v", update)
+	}
+	set := update[0].Value.(bson.D)
+	if len(set) != 2 || set[0].Key != "orders.0.status" || set[1].Key != "updated_at" {
+		t.Fatalf("Unexpected $set %v", set)
+	}
+	if status, ok := set[0].Value.(bson.RawValue); !ok || status.StringValue() == "" {
+		t.Errorf("orders.0.status = %v", set[0].Value)
+	}
+	if updated, ok := set[1].Value.(time.Time); !ok || time.Since(updated) > time.Minute {
+		t.Errorf("updated_at = %v", set[1].Value)
+	}
+	if _, err := bson.Marshal(update); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := g.NewFieldUpdater([]string{"orders.500.status"}, 5); err == nil {
+		t.Error("Expected an error for a field no document has")
+	}
+}
@@ -0,0 +1,214 @@
+package model
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldValues maps field paths (dotted, through arrays, like those of
+// Cardinality) to the values the field is drawn from
+type FieldValues map[string]ValueList
+
+// ValueList is a list of values drawn at random in proportion to their
+// weights. Values are strings, parsed as numbers for number fields.
+type ValueList struct {
+	Values  []string
+	Weights []float64
+}
+
+// ReadFieldValues reads a YAML or JSON file mapping fields to their values:
+// a mapping of values to weights (numbers or percentages, such as
+// "delivered: 70%"), a list of equally likely values, or the path of a CSV
+// file, relative to the file, with a value per row and an optional weight
+// in the second column
+func ReadFieldValues(path string) (FieldValues, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read field values: %w", err)
+	}
+	var fields map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to parse field values: %w", err)
+	}
+
+	fv := make(FieldValues, len(fields))
+	for field, node := range fields {
+		var list ValueList
+		var err error
+		switch node.Kind {
+		case yaml.MappingNode:
+			list, err = weightedValues(node.Content)
+		case yaml.SequenceNode:
+			for _, value := range node.Content {
+				if value.Kind != yaml.ScalarNode {
+					return nil, fmt.Errorf("values of %s must be strings or numbers", field)
+				}
+				list.Values = append(list.Values, value.Value)
+				list.Weights = append(list.Weights, 1)
+			}
+		case yaml.ScalarNode:
+			file := node.Value
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(filepath.Dir(path), file)
+			}
+			list, err = readValueCSV(file)
+		default:
+			err = fmt.Errorf("expected values with weights, a list of values, or a CSV file")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid values of %s: %w", field, err)
+		}
+		if len(list.Values) == 0 {
+			return nil, fmt.Errorf("no values for %s", field)
+		}
+		fv[field] = list
+	}
+	return fv, nil
+}
+
+// weightedValues reads the value: weight pairs of a YAML mapping
+func weightedValues(pairs []*yaml.Node) (ValueList, error) {
+	var list ValueList
+	for i := 0; i+1 < len(pairs); i += 2 {
+		weight, err := parseWeight(pairs[i+1].Value)
+		if err != nil {
+			return ValueList{}, fmt.Errorf("%s: %w", pairs[i].Value, err)
+		}
+		list.Values = append(list.Values, pairs[i].Value)
+		list.Weights = append(list.Weights, weight)
+	}
+	return list, nil
+}
+
+// readValueCSV reads a CSV file of values and optional weights. A first row
+// whose weight is not a number is a header.
+func readValueCSV(path string) (ValueList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ValueList{}, fmt.Errorf("failed to read values: %w", err)
+	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return ValueList{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	var list ValueList
+	for i, row := range rows {
+		weight := 1.0
+		if len(row) > 1 && strings.TrimSpace(row[1]) != "" {
+			if weight, err = parseWeight(row[1]); err != nil {
+				if i == 0 {
+					continue
+				}
+				return ValueList{}, fmt.Errorf("%s line %d: %w", path, i+1, err)
+			}
+		}
+		list.Values = append(list.Values, row[0])
+		list.Weights = append(list.Weights, weight)
+	}
+	return list, nil
+}
+
+// parseWeight parses a positive number, optionally followed by %
+func parseWeight(s string) (float64, error) {
+	weight, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64)
+	if err != nil || weight <= 0 || math.IsInf(weight, 0) {
+		return 0, fmt.Errorf("invalid weight %q: want a positive number or percentage", s)
+	}
+	return weight, nil
+}
+
+// ValidateFieldValues checks that every field of fv is a string or number
+// field of the template's documents other than _id and the key field, and
+// that the values of number fields are numbers
+func ValidateFieldValues(template string, fv FieldValues) error {
+	schema := NewGeneratorWithOptions(Size2KB, Options{Template: template}).Schema()
+	docType := reflect.TypeOf(templateDocument(template)).Elem()
+
+	for field, list := range fv {
+		if field == "_id" || field == schema.KeyField {
+			return fmt.Errorf("values of %s cannot be set", field)
+		}
+		var kind reflect.Kind
+		leaf := func(k reflect.Kind) bool {
+			kind = k
+			return settableKind(k)
+		}
+		if err := checkFieldPath(docType, strings.Split(field, "."), leaf); err != nil {
+			return fmt.Errorf("invalid values field %s: %w", field, err)
+		}
+		if len(list.Values) != len(list.Weights) {
+			return fmt.Errorf("values of %s do not all have a weight", field)
+		}
+		for _, value := range list.Values {
+			if err := setTemplateValue(reflect.New(kindType(kind)).Elem(), value); err != nil {
+				return fmt.Errorf("invalid value of %s: %w", field, err)
+			}
+		}
+	}
+	return nil
+}
+
+// kindType returns a type of a kind accepted by settableKind
+func kindType(kind reflect.Kind) reflect.Type {
+	switch kind {
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return reflect.TypeOf(int64(0))
+	case reflect.Float64:
+		return reflect.TypeOf(float64(0))
+	}
+	return reflect.TypeOf("")
+}
+
+// valueField draws the values of one field of Options.FieldValues
+type valueField struct {
+	path       []string
+	values     []string
+	cumulative []float64 // Running totals of the weights
+}
+
+// newValueFields prepares the fields of fv in a stable order
+func newValueFields(fv FieldValues) []valueField {
+	fields := make([]valueField, 0, len(fv))
+	for field, list := range fv {
+		f := valueField{path: strings.Split(field, "."), values: list.Values}
+		var total float64
+		for _, weight := range list.Weights {
+			total += weight
+			f.cumulative = append(f.cumulative, total)
+		}
+		fields = append(fields, f)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return strings.Join(fields[i].path, ".") < strings.Join(fields[j].path, ".")
+	})
+	return fields
+}
+
+// applyFieldValues sets the fields with value lists in doc (each array
+// element's, for fields in arrays) to values drawn by weight
+func (g *Generator) applyFieldValues(doc Document) error {
+	for _, field := range g.fieldValues {
+		draw := func(interface{}) (string, error) {
+			total := field.cumulative[len(field.cumulative)-1]
+			i := sort.SearchFloat64s(field.cumulative, g.faker.Float64()*total)
+			return field.values[min(i, len(field.values)-1)], nil
+		}
+		if err := setTemplateField(reflect.ValueOf(doc), field.path, draw); err != nil {
+			return fmt.Errorf("failed to set %s: %w", strings.Join(field.path, "."), err)
+		}
+	}
+	return nil
+}
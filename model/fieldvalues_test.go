@@ -0,0 +1,109 @@
+package model
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeFieldValues writes a field values file and a cities CSV next to it
+func writeFieldValues(t *testing.T, values string) string {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "cities.csv"), []byte("city,weight\nLondon,3\nParis,1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "values.yaml")
+	if err := os.WriteFile(path, []byte(values), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const testFieldValues = `
+orders.status: {delivered: 70%, shipped: 25%, cancelled: 5%}
+addresses.city: cities.csv
+orders.line_items.quantity: [1, 2, 3]
+`
+
+func TestReadFieldValues(t *testing.T) {
+	fv, err := ReadFieldValues(writeFieldValues(t, testFieldValues))
+	if err != nil {
+		t.Fatalf("Failed to read field values: %v", err)
+	}
+
+	status := fv["orders.status"]
+	if !slices.Equal(status.Values, []string{"delivered", "shipped", "cancelled"}) || !slices.Equal(status.Weights, []float64{70, 25, 5}) {
+		t.Errorf("Unexpected status values %+v", status)
+	}
+	if city := fv["addresses.city"]; !slices.Equal(city.Values, []string{"London", "Paris"}) || !slices.Equal(city.Weights, []float64{3, 1}) {
+		t.Errorf("Expected the CSV values without its header, got %+v", city)
+	}
+	if quantity := fv["orders.line_items.quantity"]; !slices.Equal(quantity.Values, []string{"1", "2", "3"}) || !slices.Equal(quantity.Weights, []float64{1, 1, 1}) {
+		t.Errorf("Expected equally likely values, got %+v", quantity)
+	}
+
+	for _, invalid := range []string{"status: {a: 0}", "status: {a: -1}", "status: {a: x}", "status: []", "status: missing.csv", "status: [[a]]", "- a"} {
+		if _, err := ReadFieldValues(writeFieldValues(t, invalid)); err == nil {
+			t.Errorf("Expected error for field values %q", invalid)
+		}
+	}
+}
+
+func TestValidateFieldValues(t *testing.T) {
+	fv, _ := ReadFieldValues(writeFieldValues(t, testFieldValues))
+	if err := ValidateFieldValues(TemplateCustomer, fv); err != nil {
+		t.Errorf("ValidateFieldValues: %v", err)
+	}
+
+	invalid := []FieldValues{
+		{"_id": {Values: []string{"a"}, Weights: []float64{1}}},
+		{"customer_id": {Values: []string{"a"}, Weights: []float64{1}}},
+		{"orders": {Values: []string{"a"}, Weights: []float64{1}}},
+		{"nope": {Values: []string{"a"}, Weights: []float64{1}}},
+		{"orders.line_items.quantity": {Values: []string{"many"}, Weights: []float64{1}}},
+		{"orders.line_items.quantity": {Values: []string{"1.5"}, Weights: []float64{1}}},
+		{"email": {Values: []string{"a", "b"}, Weights: []float64{1}}},
+	}
+	for _, fv := range invalid {
+		if err := ValidateFieldValues(TemplateCustomer, fv); err == nil {
+			t.Errorf("Expected error for field values %+v", fv)
+		}
+	}
+}
+
+func TestFieldValuesFollowWeights(t *testing.T) {
+	fv, _ := ReadFieldValues(writeFieldValues(t, testFieldValues))
+	gen := NewGeneratorWithOptions(Size8KB, Options{FieldValues: fv, Orders: CountRange{Min: 2, Max: 4}})
+
+	statuses := make(map[string]int)
+	var orders int
+	for i := 0; i < 1000; i++ {
+		doc, err := gen.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, address := range doc.Addresses {
+			if address.City != "London" && address.City != "Paris" {
+				t.Fatalf("Unexpected city %s", address.City)
+			}
+		}
+		for _, order := range doc.Orders {
+			statuses[order.Status]++
+			orders++
+			for _, item := range order.LineItems {
+				if item.Quantity < 1 || item.Quantity > 3 {
+					t.Fatalf("Unexpected quantity %d", item.Quantity)
+				}
+			}
+		}
+	}
+
+	if len(statuses) != 3 {
+		t.Errorf("Expected 3 statuses, got %v", statuses)
+	}
+	if share := float64(statuses["delivered"]) / float64(orders); math.Abs(share-0.7) > 0.05 {
+		t.Errorf("Expected 70%% delivered, got %.2f (%v)", share, statuses)
+	}
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GrowthFields returns the top-level arrays of schema's documents that
+// GrowthPlan grows: the main array, and notes where documents have them
+func GrowthFields(schema Schema) []string {
+	var fields []string
+	if schema.ArrayField != "" {
+		fields = append(fields, schema.ArrayField)
+	}
+	if slices.Contains(schema.Fields, "notes") {
+		fields = append(fields, "notes")
+	}
+	return fields
+}
+
+// GrowthPlan splits doc into a small initial document and up to steps
+// updates that grow it back into doc, to exercise document growth rather
+// than inserts. The initial document has every field of doc, with the arrays
+// among fields empty and no padding. Each update pushes the next share of
+// the arrays' elements, in order, and extends the padding, so that applied
+// in order the updates leave exactly doc, field order included.
+func GrowthPlan(doc Document, fields []string, steps int) (bson.D, []bson.D, error) {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal document: %w", err)
+	}
+	var full bson.D
+	if err := bson.Unmarshal(data, &full); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal document: %w", err)
+	}
+
+	initial := make(bson.D, len(full))
+	arrays := make(map[string]bson.A)
+	var padding Padding
+	for i, e := range full {
+		initial[i] = e
+		switch value := e.Value.(type) {
+		case bson.A:
+			if slices.Contains(fields, e.Key) {
+				arrays[e.Key] = value
+				initial[i].Value = bson.A{}
+			}
+		case string:
+			if e.Key == "padding" {
+				padding = Padding{Data: value}
+				initial[i].Value = Padding{}
+			}
+		case primitive.Binary:
+			if e.Key == "padding" {
+				padding = Padding{Data: string(value.Data), Binary: true}
+				initial[i].Value = Padding{Binary: true}
+			}
+		}
+	}
+
+	var updates []bson.D
+	for step := 1; step <= steps; step++ {
+		var push, set bson.D
+		for _, field := range fields {
+			elements := arrays[field]
+			if share := elements[len(elements)*(step-1)/steps : len(elements)*step/steps]; len(share) > 0 {
+				push = append(push, bson.E{Key: field, Value: bson.D{{Key: "$each", Value: share}}})
+			}
+		}
+		if from, to := len(padding.Data)*(step-1)/steps, len(padding.Data)*step/steps; to > from {
+			set = bson.D{{Key: "padding", Value: Padding{Data: padding.Data[:to], Binary: padding.Binary}}}
+		}
+
+		var update bson.D
+		if len(push) > 0 {
+			update = append(update, bson.E{Key: "$push", Value: push})
+		}
+		if len(set) > 0 {
+			update = append(update, bson.E{Key: "$set", Value: set})
+		}
+		if len(update) > 0 {
+			updates = append(updates, update)
+		}
+	}
+	return initial, updates, nil
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// applyGrowth applies GrowthPlan updates to doc in memory
+func applyGrowth(doc bson.D, update bson.D) {
+	for _, op := range update {
+		for _, e := range op.Value.(bson.D) {
+			for i := range doc {
+				if doc[i].Key != e.Key {
+					continue
+				}
+				if op.Key == "$push" {
+					doc[i].Value = append(doc[i].Value.(bson.A), e.Value.(bson.D).Map()["$each"].(bson.A)...)
+				} else {
+					doc[i].Value = e.Value
+				}
+			}
+		}
+	}
+}
+
+func TestGrowthPlan(t *testing.T) {
+	for _, template := range []string{TemplateCustomer, TemplateProduct, TemplateTelemetry, TemplateMessages} {
+		g := NewGeneratorWithOptions(Size16KB, Options{Template: template})
+		fields := GrowthFields(g.Schema())
+		doc, err := g.GenerateDocument()
+		if err != nil {
+			t.Fatalf("%s: failed to generate document: %v", template, err)
+		}
+		want, err := bson.Marshal(doc)
+		if err != nil {
+			t.Fatalf("%s: failed to marshal document: %v", template, err)
+		}
+
+		initial, updates, err := GrowthPlan(doc, fields, 4)
+		if err != nil {
+			t.Fatalf("%s: GrowthPlan failed: %v", template, err)
+		}
+		if len(updates) == 0 || len(updates) > 4 {
+			t.Fatalf("%s: expected 1-4 updates, got %d", template, len(updates))
+		}
+		small, err := bson.Marshal(initial)
+		if err != nil {
+			t.Fatalf("%s: failed to marshal initial document: %v", template, err)
+		}
+		if len(small) >= len(want)/2 {
+			t.Errorf("%s: expected a small initial document, got %d of %d bytes", template, len(small), len(want))
+		}
+
+		for _, update := range updates {
+			applyGrowth(initial, update)
+		}
+		got, err := bson.Marshal(initial)
+		if err != nil {
+			t.Fatalf("%s: failed to marshal grown document: %v", template, err)
+		}
+		// Compare decoded, as metadata maps encode in random order
+		var grown, generated bson.M
+		if err := bson.Unmarshal(got, &grown); err != nil {
+			t.Fatalf("%s: failed to decode grown document: %v", template, err)
+		}
+		if err := bson.Unmarshal(want, &generated); err != nil {
+			t.Fatalf("%s: failed to decode document: %v", template, err)
+		}
+		if len(got) != len(want) || !reflect.DeepEqual(grown, generated) {
+			t.Errorf("%s: grown document differs from the generated one (%d vs %d bytes)", template, len(got), len(want))
+		}
+		var order bson.D
+		if err := bson.Unmarshal(want, &order); err != nil {
+			t.Fatalf("%s: failed to decode document: %v", template, err)
+		}
+		for i, e := range order {
+			if initial[i].Key != e.Key {
+				t.Errorf("%s: expected field %d to be %s, got %s", template, i, e.Key, initial[i].Key)
+			}
+		}
+	}
+}
+
+func TestGrowthFields(t *testing.T) {
+	fields := GrowthFields(NewGenerator(Size4KB).Schema())
+	if len(fields) != 2 || fields[0] != "orders" || fields[1] != "notes" {
+		t.Errorf("Expected orders and notes, got %v", fields)
+	}
+	if fields := GrowthFields(NewGeneratorWithOptions(Size4KB, Options{Template: TemplateProduct}).Schema()); len(fields) != 1 || fields[0] != "reviews" {
+		t.Errorf("Expected reviews, got %v", fields)
+	}
+}
@@ -0,0 +1,182 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultProductCount is the size of the product catalog line items draw from
+const DefaultProductCount = 10000
+
+// DefaultDeviceCount is the size of the device fleet telemetry is reported by
+const DefaultDeviceCount = 1000
+
+// DefaultAccountCount is the number of accounts transactions move money between
+const DefaultAccountCount = 100000
+
+// DefaultUserCount is the number of users conversations are held between
+const DefaultUserCount = 100000
+
+// PartitionSize is the number of customer sequence numbers reserved for each
+// instance of a distributed load
+const PartitionSize int64 = 1 << 32
+
+// MaxInstances is the number of partitions that fit the 40-bit sequence
+// numbers of sequential _ids
+const MaxInstances = 256
+
+// KeySpace identifies the business keys a run generated. Customer keys are
+// derived from the seed and a sequence number, and product keys from the seed
+// and a catalog index, so a later run can regenerate exactly the same keys to
+// target (update, delete) or extend (incremental load) an earlier load.
+type KeySpace struct {
+	Seed      int64 `bson:"seed" json:"seed"`
+	First     int64 `bson:"first" json:"first"`                             // First customer sequence number
+	Count     int64 `bson:"count" json:"count"`                             // Customer sequence numbers issued
+	Products  int   `bson:"products" json:"products"`                       // Product catalog size
+	Instance  int   `bson:"instance,omitempty" json:"instance,omitempty"`   // Partition of a distributed load, from 1
+	Instances int   `bson:"instances,omitempty" json:"instances,omitempty"` // Instances of a distributed load
+}
+
+// ParseInstance parses the instance of a distributed load, "3/8" for the
+// third of eight ("" = not distributed)
+func ParseInstance(s string) (instance, instances int, err error) {
+	if s == "" {
+		return 0, 0, nil
+	}
+	i, n, ok := strings.Cut(s, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid instance %q: want I/N", s)
+	}
+	if instance, err = strconv.Atoi(strings.TrimSpace(i)); err != nil {
+		return 0, 0, fmt.Errorf("invalid instance %q: want I/N", s)
+	}
+	if instances, err = strconv.Atoi(strings.TrimSpace(n)); err != nil {
+		return 0, 0, fmt.Errorf("invalid instance %q: want I/N", s)
+	}
+	if instance < 1 || instance > instances || instances > MaxInstances {
+		return 0, 0, fmt.Errorf("invalid instance %q: want 1 <= I <= N <= %d", s, MaxInstances)
+	}
+	return instance, instances, nil
+}
+
+// NewKeySpace returns an empty key space with a random seed
+func NewKeySpace() KeySpace {
+	return KeySpace{
+		Seed:     rand.New(rand.NewSource(time.Now().UnixNano())).Int63(),
+		Products: DefaultProductCount,
+	}
+}
+
+// IsZero reports whether no key space was recorded (runs predating key spaces)
+func (k KeySpace) IsZero() bool {
+	return k.Seed == 0 && k.Count == 0
+}
+
+// Continue returns an empty key space that shares the seed and product
+// catalog and starts after the last customer key of k
+func (k KeySpace) Continue() KeySpace {
+	return KeySpace{Seed: k.Seed, First: k.First + k.Count, Products: k.Products}
+}
+
+// Partition returns the empty key space of one instance (from 1) of a
+// distributed load: k's seed, with sequence numbers in the instance's own
+// range of PartitionSize, from k's position in its range, so instances
+// continuing the same earlier run never overlap
+func (k KeySpace) Partition(instance, instances int) KeySpace {
+	return KeySpace{
+		Seed:      k.Seed,
+		First:     int64(instance-1)*PartitionSize + k.First%PartitionSize,
+		Products:  k.Products,
+		Instance:  instance,
+		Instances: instances,
+	}
+}
+
+// CustomerKey returns the customer_id for a sequence number
+func (k KeySpace) CustomerKey(seq int64) string {
+	return keyUUID(k.Seed, "customer", seq)
+}
+
+// ProductKey returns the product_id for a catalog index
+func (k KeySpace) ProductKey(index int) string {
+	return keyUUID(k.Seed, "product", int64(index))
+}
+
+// DeviceKey returns the device_id for a fleet index
+func (k KeySpace) DeviceKey(index int) string {
+	return keyUUID(k.Seed, "device", int64(index))
+}
+
+// AccountKey returns the account number for an account index
+func (k KeySpace) AccountKey(index int) string {
+	return keyUUID(k.Seed, "account", int64(index))
+}
+
+// UserKey returns the user_id for a user index
+func (k KeySpace) UserKey(index int) string {
+	return keyUUID(k.Seed, "user", int64(index))
+}
+
+// RandomCustomerKey returns the customer_id of a random sequence number in the
+// key space. An empty key space has no keys, so it returns the first key it
+// would issue, which no document has yet.
+func (k KeySpace) RandomCustomerKey(rng *rand.Rand) string {
+	if k.Count <= 0 {
+		return k.CustomerKey(k.First)
+	}
+	return k.CustomerKey(k.First + rng.Int63n(k.Count))
+}
+
+// keyUUID derives a UUID-formatted key from the seed, key kind, and number
+func keyUUID(seed int64, kind string, n int64) string {
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], uint64(seed))
+	binary.BigEndian.PutUint64(buf[8:], uint64(n))
+	sum := sha256.Sum256(append(buf[:], kind...))
+
+	b := sum[:16]
+	b[6] = (b[6] & 0x0f) | 0x40 // Version 4 layout
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// keySequence issues customer sequence numbers for a generator
+type keySequence struct {
+	space KeySpace
+	next  int64
+}
+
+// newKeySequence starts issuing sequence numbers at space.First
+func newKeySequence(space KeySpace) *keySequence {
+	if space.Products <= 0 {
+		space.Products = DefaultProductCount
+	}
+	return &keySequence{space: space, next: space.First}
+}
+
+// nextCustomerKey issues the next customer key
+func (s *keySequence) nextCustomerKey() string {
+	_, key := s.issue()
+	return key
+}
+
+// issue issues the next customer key along with its position in this
+// generator's sequence (from 0)
+func (s *keySequence) issue() (int64, string) {
+	seq := atomic.AddInt64(&s.next, 1) - 1
+	return seq - s.space.First, s.space.CustomerKey(seq)
+}
+
+// issued returns the key space with the sequence numbers issued so far
+func (s *keySequence) issued() KeySpace {
+	space := s.space
+	space.Count = atomic.LoadInt64(&s.next) - space.First
+	return space
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestParseInstance(t *testing.T) {
+	if i, n, err := ParseInstance("3/8"); err != nil || i != 3 || n != 8 {
+		t.Errorf("ParseInstance(3/8) = %d, %d, %v", i, n, err)
+	}
+	if i, n, err := ParseInstance(""); err != nil || i != 0 || n != 0 {
+		t.Errorf("ParseInstance() = %d, %d, %v", i, n, err)
+	}
+	for _, s := range []string{"3", "0/8", "9/8", "a/8", "1/257"} {
+		if _, _, err := ParseInstance(s); err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+	}
+}
+
+func TestKeySpacePartitions(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	seen := make(map[string]bool)
+	ids := make(map[string]bool)
+	for instance := 1; instance <= 3; instance++ {
+		// Every instance continues the same earlier run of 5 keys
+		space := KeySpace{Seed: 42, First: 0, Count: 5}.Continue().Partition(instance, 3)
+		if space.First != int64(instance-1)*PartitionSize+5 {
+			t.Errorf("Instance %d starts at %d", instance, space.First)
+		}
+		gen := NewGeneratorWithOptions(Size512B, Options{
+			Template:          TemplateEvents,
+			KeySpace:          space,
+			TimeRange:         TimeRange{Start: start, End: start.Add(time.Hour)},
+			ExpectedDocuments: 100,
+		})
+		for i := 0; i < 100; i++ {
+			event, err := gen.GenerateEvent()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if seen[event.EventID] || ids[event.ID.Hex()] {
+				t.Fatalf("Instance %d repeats event %s (_id %s)", instance, event.EventID, event.ID.Hex())
+			}
+			seen[event.EventID] = true
+			ids[event.ID.Hex()] = true
+		}
+
+		issued := gen.KeySpace()
+		if issued.Instance != instance || issued.Instances != 3 || issued.Count != 100 {
+			t.Errorf("Unexpected key space %+v", issued)
+		}
+	}
+}
+
+func TestRandomCustomerKey(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	space := KeySpace{Seed: 42, First: 10, Count: 3}
+	keys := map[string]bool{space.CustomerKey(10): true, space.CustomerKey(11): true, space.CustomerKey(12): true}
+	for i := 0; i < 100; i++ {
+		if key := space.RandomCustomerKey(rng); !keys[key] {
+			t.Fatalf("RandomCustomerKey() = %s, outside the key space", key)
+		}
+	}
+
+	// A seeded key space saved before the first batch has no keys
+	empty := KeySpace{Seed: 42, First: 10}
+	if key := empty.RandomCustomerKey(rng); key != empty.CustomerKey(10) {
+		t.Errorf("RandomCustomerKey() of an empty key space = %s", key)
+	}
+}
@@ -0,0 +1,204 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LegacySchemaVersion is the schema_version of documents in the legacy
+// schema. Documents in the current schema have no schema_version.
+const LegacySchemaVersion = 1
+
+// legacyChange is a difference of the legacy schema from the current one:
+// the field at path (dotted, through arrays) is missing, renamed, or holds
+// another type
+type legacyChange struct {
+	path    string
+	remove  bool
+	rename  string
+	convert func(v interface{}) interface{}
+}
+
+// legacySchemas are how the legacy schema of every template differs from
+// the current one, as in a collection that evolved over the years
+var legacySchemas = map[string][]legacyChange{
+	TemplateCustomer: {
+		{path: "phone", rename: "phone_number"},
+		{path: "date_of_birth", convert: dateString},
+		{path: "payment_methods", remove: true},
+		{path: "addresses.zip_code", rename: "zip"},
+		{path: "orders.total_amount", convert: amountString},
+		{path: "order_stats", remove: true},
+	},
+	TemplateProduct: {
+		{path: "price", convert: amountString},
+		{path: "attributes", rename: "specs"},
+		{path: "variants", remove: true},
+		{path: "reviews.verified_purchase", remove: true},
+	},
+	TemplateTelemetry: {
+		{path: "location", convert: coordinatePair},
+		{path: "firmware", remove: true},
+		{path: "summary", remove: true},
+	},
+	TemplateTransaction: {
+		{path: "amount", convert: decimalDouble},
+		{path: "postings.amount", convert: decimalDouble},
+		{path: "channel", rename: "source"},
+		{path: "value_date", remove: true},
+	},
+	TemplateMessages: {
+		{path: "participants", convert: participantIDs},
+		{path: "messages.reactions", remove: true},
+		{path: "unread_count", remove: true},
+	},
+	TemplateEvents: {
+		{path: "ts", convert: epochMillis},
+		{path: "status_code", convert: numberString},
+		{path: "attributes", rename: "attrs"},
+	},
+}
+
+// applyLegacy puts doc in the template's legacy schema with probability
+// Options.LegacyFraction
+func (g *Generator) applyLegacy(doc Document) {
+	sd, ok := doc.(shapedDocument)
+	if !ok || g.options.LegacyFraction <= 0 || g.faker.Float64() >= g.options.LegacyFraction {
+		return
+	}
+	template := g.options.Template
+	if template == "" {
+		template = TemplateCustomer
+	}
+	sd.shape().legacy = legacySchemas[template]
+}
+
+// toLegacySchema rewrites d from the current to the legacy schema
+func toLegacySchema(d bson.D, changes []legacyChange) bson.D {
+	for _, c := range changes {
+		d = legacyEdit(d, strings.Split(c.path, "."), c)
+	}
+	return append(d, bson.E{Key: "schema_version", Value: int32(LegacySchemaVersion)})
+}
+
+// legacyEdit applies c to the field at path in d, and in every element of
+// the arrays along the way
+func legacyEdit(d bson.D, path []string, c legacyChange) bson.D {
+	for i, e := range d {
+		if e.Key != path[0] {
+			continue
+		}
+		if len(path) > 1 {
+			d[i].Value = legacyEditValue(e.Value, path[1:], c)
+			return d
+		}
+		switch {
+		case c.remove:
+			return append(d[:i], d[i+1:]...)
+		case c.rename != "":
+			d[i].Key = c.rename
+		case c.convert != nil:
+			d[i].Value = c.convert(e.Value)
+		}
+		return d
+	}
+	return d
+}
+
+// legacyEditValue applies c within a subdocument or array value
+func legacyEditValue(v interface{}, path []string, c legacyChange) interface{} {
+	switch v := v.(type) {
+	case bson.D:
+		return legacyEdit(v, path, c)
+	case bson.A:
+		for i := range v {
+			v[i] = legacyEditValue(v[i], path, c)
+		}
+		return v
+	}
+	return v
+}
+
+// dateString stores a date as YYYY-MM-DD
+func dateString(v interface{}) interface{} {
+	if t, ok := v.(primitive.DateTime); ok {
+		return t.Time().UTC().Format("2006-01-02")
+	}
+	return v
+}
+
+// amountString stores an amount as a string with two decimals
+func amountString(v interface{}) interface{} {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', 2, 64)
+	}
+	return v
+}
+
+// numberString stores a number as a string
+func numberString(v interface{}) interface{} {
+	switch v.(type) {
+	case int32, int64, float64:
+		return fmt.Sprint(v)
+	}
+	return v
+}
+
+// decimalDouble stores a Decimal128 as a double
+func decimalDouble(v interface{}) interface{} {
+	if d, ok := v.(primitive.Decimal128); ok {
+		if f, err := strconv.ParseFloat(d.String(), 64); err == nil {
+			return f
+		}
+	}
+	return v
+}
+
+// epochMillis stores a date as milliseconds since the Unix epoch
+func epochMillis(v interface{}) interface{} {
+	if t, ok := v.(primitive.DateTime); ok {
+		return int64(t)
+	}
+	return v
+}
+
+// coordinatePair stores a location subdocument as a legacy [lon, lat] pair
+func coordinatePair(v interface{}) interface{} {
+	d, ok := v.(bson.D)
+	if !ok {
+		return v
+	}
+	var lon, lat interface{}
+	for _, e := range d {
+		switch e.Key {
+		case "lon":
+			lon = e.Value
+		case "lat":
+			lat = e.Value
+		}
+	}
+	return bson.A{lon, lat}
+}
+
+// participantIDs stores participant subdocuments as their user IDs
+func participantIDs(v interface{}) interface{} {
+	a, ok := v.(bson.A)
+	if !ok {
+		return v
+	}
+	ids := make(bson.A, 0, len(a))
+	for _, p := range a {
+		if d, ok := p.(bson.D); ok {
+			for _, e := range d {
+				if e.Key == "user_id" {
+					ids = append(ids, e.Value)
+				}
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+func TestLegacySchema(t *testing.T) {
+	tests := []struct {
+		template string
+		missing  []string        // Fields of the current schema only
+		present  []string        // Renamed fields
+		types    []bsontype.Type // Type of each changed field, by path below
+		paths    [][]string
+	}{
+		{TemplateCustomer, []string{"phone", "payment_methods"}, []string{"phone_number"},
+			[]bsontype.Type{bson.TypeString, bson.TypeString, bson.TypeString}, [][]string{{"date_of_birth"}, {"orders", "0", "total_amount"}, {"addresses", "0", "zip"}}},
+		{TemplateProduct, []string{"attributes", "variants"}, []string{"specs"},
+			[]bsontype.Type{bson.TypeString}, [][]string{{"price"}}},
+		{TemplateTelemetry, []string{"firmware", "summary"}, nil,
+			[]bsontype.Type{bson.TypeArray}, [][]string{{"location"}}},
+		{TemplateTransaction, []string{"channel", "value_date"}, []string{"source"},
+			[]bsontype.Type{bson.TypeDouble, bson.TypeDouble}, [][]string{{"amount"}, {"postings", "0", "amount"}}},
+		{TemplateMessages, []string{"unread_count"}, nil,
+			[]bsontype.Type{bson.TypeString}, [][]string{{"participants", "0"}}},
+		{TemplateEvents, []string{"attributes"}, []string{"attrs"},
+			[]bsontype.Type{bson.TypeInt64, bson.TypeString}, [][]string{{"ts"}, {"status_code"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			size := Size4KB
+			if tt.template == TemplateEvents {
+				size = Size512B
+			}
+			gen := NewGeneratorWithOptions(size, Options{Template: tt.template, LegacyFraction: 1})
+			doc, err := gen.GenerateDocument()
+			if err != nil {
+				t.Fatalf("Failed to generate document: %v", err)
+			}
+			data, err := bson.Marshal(doc)
+			if err != nil {
+				t.Fatalf("Failed to marshal document: %v", err)
+			}
+			raw := bson.Raw(data)
+
+			if v, err := raw.LookupErr("schema_version"); err != nil || v.Int32() != LegacySchemaVersion {
+				t.Errorf("Expected schema_version %d, got %v", LegacySchemaVersion, v)
+			}
+			for _, field := range tt.missing {
+				if _, err := raw.LookupErr(field); err == nil {
+					t.Errorf("Expected %s to be missing", field)
+				}
+			}
+			for _, field := range tt.present {
+				if _, err := raw.LookupErr(field); err != nil {
+					t.Errorf("Expected %s to be present", field)
+				}
+			}
+			for i, path := range tt.paths {
+				if v, err := raw.LookupErr(path...); err != nil || v.Type != tt.types[i] {
+					t.Errorf("Expected %v to be of type %s, got %v", path, tt.types[i], v.Type)
+				}
+			}
+			// Dropped and converted fields shift the size of a single document
+			if len(data) < int(size)*7/10 || len(data) > int(size)*14/10 {
+				t.Errorf("Legacy document is %d bytes, want about %d", len(data), size)
+			}
+		})
+	}
+}
+
+func TestLegacyFraction(t *testing.T) {
+	gen := NewGeneratorWithOptions(Size2KB, Options{LegacyFraction: 0.25, Checksum: true})
+
+	legacy := 0
+	for i := 0; i < 2000; i++ {
+		doc, err := gen.Generate()
+		if err != nil {
+			t.Fatalf("Failed to generate document: %v", err)
+		}
+		data, err := bson.Marshal(doc)
+		if err != nil {
+			t.Fatalf("Failed to marshal document: %v", err)
+		}
+		_, err = bson.Raw(data).LookupErr("schema_version")
+		if err == nil {
+			legacy++
+			if doc.Checksum != "" {
+				t.Fatal("Legacy documents should not be checksummed")
+			}
+		} else if doc.Checksum == "" {
+			t.Fatal("Current documents should be checksummed")
+		}
+	}
+	if legacy < 400 || legacy > 600 {
+		t.Errorf("%d of 2000 documents are legacy, want about 500", legacy)
+	}
+}
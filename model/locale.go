@@ -0,0 +1,496 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/brianvoe/gofakeit/v7"
+)
+
+const (
+	// LocaleDefault generates the English names and US addresses of gofakeit
+	LocaleDefault = "en"
+	// LocaleEmoji mixes emoji into English names and notes
+	LocaleEmoji = "emoji"
+)
+
+// localeData holds the names, address parts, and note vocabulary of a locale
+type localeData struct {
+	firstNames    []string
+	lastNames     []string
+	familyFirst   bool // Full names are the family name and given name, unspaced
+	streets       []string
+	streetFormat  string // Street name (%[1]s) and house number (%[2]d)
+	country       countryData
+	words         []string
+	wordSeparator string
+	sentenceEnd   string
+	emoji         bool // English names and words with emoji, see LocaleEmoji
+}
+
+// locales are the locales customer documents can be generated in besides
+// LocaleDefault. Latin-script locales favor letters whose collation differs
+// between locales: umlauts, accents, ñ, å, the Turkish dotless i, and ł.
+// The others cover multi-byte scripts, right-to-left text, and text without
+// spaces between words.
+var locales = map[string]*localeData{
+	"de": {
+		firstNames:   []string{"Jürgen", "Günther", "Jörg", "Ännchen", "Björn", "Bärbel", "Käthe", "Sören", "Anja", "Lukas", "Zoë", "Ömer", "Uwe", "Götz", "Grete"},
+		lastNames:    []string{"Müller", "Schäfer", "Groß", "Schröder", "Weiß", "Köhler", "Mueller", "Bäcker", "Fuß", "Öztürk", "Krüger", "Böhm", "Hofmann", "Zimmermann", "Strauß"},
+		streets:      []string{"Hauptstraße", "Schloßallee", "Mühlenweg", "Gartenstraße", "Königsplatz", "Bahnhofstraße", "Am Rübenfeld", "Lindenstraße", "Fährweg", "Schützenstraße"},
+		streetFormat: "%[1]s %[2]d",
+		country: countryData{
+			name:        "Deutschland",
+			phoneFormat: "+49 ### ########",
+			currency:    "EUR",
+			places: []place{
+				{"München", "Bayern", "80###"},
+				{"Köln", "Nordrhein-Westfalen", "50###"},
+				{"Düsseldorf", "Nordrhein-Westfalen", "40###"},
+				{"Nürnberg", "Bayern", "90###"},
+				{"Lübeck", "Schleswig-Holstein", "23###"},
+				{"Göttingen", "Niedersachsen", "37###"},
+				{"Würzburg", "Bayern", "97###"},
+				{"Berlin", "Berlin", "10###"},
+				{"Osnabrück", "Niedersachsen", "49###"},
+				{"Fürth", "Bayern", "907##"},
+				{"Erfurt", "Thüringen", "99###"},
+				{"Saarbrücken", "Saarland", "66###"},
+			},
+		},
+		words:         []string{"Bestellung", "Kunde", "Lieferung", "versandt", "danke", "Größe", "Rückgabe", "Zahlung", "Adresse", "morgen", "Lager", "Verpackung", "schön", "Grüße", "bitte", "Änderung", "Rechnung", "neu", "über", "fällig"},
+		wordSeparator: " ",
+		sentenceEnd:   ".",
+	},
+	"fr": {
+		firstNames:   []string{"Émilie", "Hélène", "Jérôme", "François", "Zoé", "Gaëlle", "Anaïs", "Noël", "Cécile", "Léa", "Théo", "Chloé", "Benoît", "Maëlys", "Loïc"},
+		lastNames:    []string{"Lefèvre", "Bélanger", "Côté", "Dupré", "Gagné", "Ménard", "Thébault", "Faure", "Rémy", "Lefebvre", "Leroy", "Bénard", "Pâris", "Cœur", "Moreau"},
+		streets:      []string{"rue de l'Église", "avenue des Champs-Élysées", "boulevard Saint-Honoré", "rue du Château", "place de la Liberté", "rue des Écoles", "impasse des Pêcheurs", "chemin du Moulin", "quai de la Tournelle", "rue Pré-aux-Clercs"},
+		streetFormat: "%[2]d %[1]s",
+		country: countryData{
+			name:        "France",
+			phoneFormat: "+33 # ## ## ## ##",
+			currency:    "EUR",
+			places: []place{
+				{"Orléans", "Centre-Val de Loire", "45###"},
+				{"Besançon", "Bourgogne-Franche-Comté", "25###"},
+				{"Nîmes", "Occitanie", "30###"},
+				{"Périgueux", "Nouvelle-Aquitaine", "24###"},
+				{"Évreux", "Normandie", "27###"},
+				{"Angoulême", "Nouvelle-Aquitaine", "16###"},
+				{"Saint-Étienne", "Auvergne-Rhône-Alpes", "42###"},
+				{"Paris", "Île-de-France", "75###"},
+				{"Béziers", "Occitanie", "34###"},
+				{"Créteil", "Île-de-France", "94###"},
+				{"Rennes", "Bretagne", "35###"},
+				{"Nice", "Provence-Alpes-Côte d'Azur", "06###"},
+			},
+		},
+		words:         []string{"commande", "client", "livraison", "expédiée", "merci", "colis", "retour", "paiement", "adresse", "demain", "entrepôt", "emballage", "très", "satisfait", "réglé", "reçu", "facture", "nouvelle", "déjà", "été"},
+		wordSeparator: " ",
+		sentenceEnd:   ".",
+	},
+	"es": {
+		firstNames:   []string{"José", "María", "Ángel", "Íñigo", "Begoña", "Jesús", "Lucía", "Martín", "Nuria", "Raúl", "Sofía", "Álvaro", "Inés", "Joaquín", "Ramón"},
+		lastNames:    []string{"Núñez", "Muñoz", "Ibáñez", "Peña", "Castañeda", "García", "Pérez", "López", "Martínez", "Gómez", "Ordóñez", "Sánchez", "Llorente", "Chávez", "Cañas"},
+		streets:      []string{"Calle Mayor", "Avenida de España", "Calle de Alcalá", "Paseo de la Castellana", "Calle Peñalver", "Plaza de la Constitución", "Calle Núñez de Balboa", "Camino Real", "Calle de la Montaña", "Ronda de Toledo"},
+		streetFormat: "%[1]s %[2]d",
+		country: countryData{
+			name:        "España",
+			phoneFormat: "+34 ### ### ###",
+			currency:    "EUR",
+			places: []place{
+				{"Málaga", "Andalucía", "29###"},
+				{"Córdoba", "Andalucía", "14###"},
+				{"León", "Castilla y León", "24###"},
+				{"Cádiz", "Andalucía", "11###"},
+				{"A Coruña", "Galicia", "15###"},
+				{"Logroño", "La Rioja", "26###"},
+				{"Ávila", "Castilla y León", "05###"},
+				{"Madrid", "Comunidad de Madrid", "28###"},
+				{"Cáceres", "Extremadura", "10###"},
+				{"Almería", "Andalucía", "04###"},
+				{"Zaragoza", "Aragón", "50###"},
+				{"Bilbao", "País Vasco", "48###"},
+			},
+		},
+		words:         []string{"pedido", "cliente", "envío", "enviado", "gracias", "paquete", "devolución", "pago", "dirección", "mañana", "almacén", "embalaje", "también", "recibido", "factura", "nuevo", "señor", "añadir", "rápido", "atención"},
+		wordSeparator: " ",
+		sentenceEnd:   ".",
+	},
+	"sv": {
+		firstNames:   []string{"Åsa", "Björn", "Märta", "Göran", "Östen", "Anders", "Åke", "Linnéa", "Sören", "Ylva", "Håkan", "Maja", "Örjan", "Elsa", "Kjell"},
+		lastNames:    []string{"Åberg", "Öberg", "Ängström", "Ström", "Sjögren", "Andersson", "Ekström", "Nordén", "Håkansson", "Zetterström", "Lindqvist", "Bäckström", "Aalto", "Öhman", "Wåhlin"},
+		streets:      []string{"Storgatan", "Drottninggatan", "Kungsgatan", "Skolgatan", "Åsögatan", "Götgatan", "Ringvägen", "Järnvägsgatan", "Björkvägen", "Östra Hamngatan"},
+		streetFormat: "%[1]s %[2]d",
+		country: countryData{
+			name:        "Sverige",
+			phoneFormat: "+46 ## ### ## ##",
+			currency:    "SEK",
+			places: []place{
+				{"Malmö", "Skåne", "21# ##"},
+				{"Göteborg", "Västra Götaland", "41# ##"},
+				{"Växjö", "Kronoberg", "35# ##"},
+				{"Umeå", "Västerbotten", "90# ##"},
+				{"Örebro", "Örebro län", "70# ##"},
+				{"Jönköping", "Jönköping", "55# ##"},
+				{"Luleå", "Norrbotten", "97# ##"},
+				{"Stockholm", "Stockholm", "11# ##"},
+				{"Norrköping", "Östergötland", "60# ##"},
+				{"Västerås", "Västmanland", "72# ##"},
+			},
+		},
+		words:         []string{"beställning", "kund", "leverans", "skickad", "tack", "paket", "retur", "betalning", "adress", "imorgon", "lager", "förpackning", "snabb", "mottagen", "faktura", "ny", "även", "kö", "fråga", "ändrad"},
+		wordSeparator: " ",
+		sentenceEnd:   ".",
+	},
+	"tr": {
+		firstNames:   []string{"İbrahim", "Işıl", "Çağla", "Gülşen", "Ömer", "Şükrü", "Ilgaz", "İrem", "Ayşe", "Özge", "Ümit", "Doğan", "Ebru", "Ilkay", "Süleyman"},
+		lastNames:    []string{"Yılmaz", "Işık", "Çelik", "Şahin", "Öztürk", "Aydın", "Doğan", "Kılıç", "Arslan", "Güneş", "İnce", "Kaya", "Yıldız", "Çakır", "Ilıcak"},
+		streets:      []string{"Atatürk Caddesi", "İstiklal Caddesi", "Cumhuriyet Sokağı", "Gül Sokak", "Işıklar Caddesi", "Çiçek Sokağı", "Bağdat Caddesi", "Şehit Mehmet Sokağı", "İnönü Bulvarı", "Ilıca Yolu"},
+		streetFormat: "%[1]s No: %[2]d",
+		country: countryData{
+			name:        "Türkiye",
+			phoneFormat: "+90 5## ### ## ##",
+			currency:    "TRY",
+			places: []place{
+				{"İstanbul", "İstanbul", "34###"},
+				{"İzmir", "İzmir", "35###"},
+				{"Ankara", "Ankara", "06###"},
+				{"Çanakkale", "Çanakkale", "17###"},
+				{"Şanlıurfa", "Şanlıurfa", "63###"},
+				{"Muğla", "Muğla", "48###"},
+				{"Eskişehir", "Eskişehir", "26###"},
+				{"Iğdır", "Iğdır", "76###"},
+				{"Diyarbakır", "Diyarbakır", "21###"},
+				{"Ağrı", "Ağrı", "04###"},
+			},
+		},
+		words:         []string{"sipariş", "müşteri", "teslimat", "gönderildi", "teşekkürler", "paket", "iade", "ödeme", "adres", "yarın", "depo", "ambalaj", "hızlı", "alındı", "fatura", "yeni", "değişiklik", "ürün", "iletişim", "lütfen"},
+		wordSeparator: " ",
+		sentenceEnd:   ".",
+	},
+	"pl": {
+		firstNames:   []string{"Łukasz", "Małgorzata", "Paweł", "Zofia", "Michał", "Agnieszka", "Józef", "Żaneta", "Wojciech", "Grażyna", "Stanisław", "Jędrzej", "Bożena", "Kazimierz", "Łucja"},
+		lastNames:    []string{"Nowak", "Wiśniewski", "Wójcik", "Kowalczyk", "Łukaszewicz", "Żak", "Dąbrowski", "Zieliński", "Szymański", "Woźniak", "Kozłowski", "Jankowski", "Mazur", "Krawczyk", "Ślusarczyk"},
+		streets:      []string{"ulica Długa", "ulica Łąkowa", "aleja Jerozolimskie", "ulica Źródlana", "ulica Żeromskiego", "plac Grunwaldzki", "ulica Mickiewicza", "ulica Świętokrzyska", "ulica Ogrodowa", "ulica Słoneczna"},
+		streetFormat: "%[1]s %[2]d",
+		country: countryData{
+			name:        "Polska",
+			phoneFormat: "+48 ### ### ###",
+			currency:    "PLN",
+			places: []place{
+				{"Łódź", "łódzkie", "9#-###"},
+				{"Kraków", "małopolskie", "3#-###"},
+				{"Gdańsk", "pomorskie", "80-###"},
+				{"Wrocław", "dolnośląskie", "5#-###"},
+				{"Poznań", "wielkopolskie", "6#-###"},
+				{"Białystok", "podlaskie", "15-###"},
+				{"Toruń", "kujawsko-pomorskie", "87-###"},
+				{"Rzeszów", "podkarpackie", "35-###"},
+				{"Częstochowa", "śląskie", "42-###"},
+				{"Zielona Góra", "lubuskie", "65-###"},
+				{"Warszawa", "mazowieckie", "0#-###"},
+				{"Kielce", "świętokrzyskie", "25-###"},
+			},
+		},
+		words:         []string{"zamówienie", "klient", "dostawa", "wysłane", "dziękuję", "paczka", "zwrot", "płatność", "adres", "jutro", "magazyn", "opakowanie", "szybko", "otrzymane", "faktura", "nowy", "zmiana", "produkt", "kontakt", "proszę"},
+		wordSeparator: " ",
+		sentenceEnd:   ".",
+	},
+	"ru": {
+		firstNames:   []string{"Александр", "Мария", "Дмитрий", "Анна", "Сергей", "Елена", "Иван", "Ольга", "Михаил", "Татьяна", "Алексей", "Наталья", "Юрий", "Ксения", "Фёдор"},
+		lastNames:    []string{"Иванов", "Смирнов", "Кузнецов", "Попов", "Васильев", "Петров", "Соколов", "Михайлов", "Новиков", "Фёдоров", "Морозов", "Волков", "Алексеев", "Лебедев", "Семёнов"},
+		streets:      []string{"улица Ленина", "Невский проспект", "улица Пушкина", "Садовая улица", "улица Гагарина", "Тверская улица", "проспект Мира", "Лесная улица", "Набережная улица", "улица Чехова"},
+		streetFormat: "%[1]s, д. %[2]d",
+		country: countryData{
+			name:        "Россия",
+			phoneFormat: "+7 9## ###-##-##",
+			currency:    "RUB",
+			places: []place{
+				{"Москва", "Москва", "1#####"},
+				{"Санкт-Петербург", "Санкт-Петербург", "19####"},
+				{"Новосибирск", "Новосибирская область", "630###"},
+				{"Екатеринбург", "Свердловская область", "620###"},
+				{"Казань", "Республика Татарстан", "420###"},
+				{"Нижний Новгород", "Нижегородская область", "603###"},
+				{"Самара", "Самарская область", "443###"},
+				{"Омск", "Омская область", "644###"},
+				{"Ростов-на-Дону", "Ростовская область", "344###"},
+				{"Уфа", "Республика Башкортостан", "450###"},
+			},
+		},
+		words:         []string{"заказ", "клиент", "доставка", "отправлен", "спасибо", "товар", "оплата", "адрес", "завтра", "склад", "возврат", "упаковка", "обслуживание", "отличное", "связаться", "пожалуйста", "обновлён", "счёт", "новый", "получен"},
+		wordSeparator: " ",
+		sentenceEnd:   ".",
+	},
+	"ar": {
+		firstNames:   []string{"محمد", "أحمد", "فاطمة", "علي", "مريم", "يوسف", "نور", "خالد", "سارة", "عمر", "ليلى", "حسن", "زينب", "إبراهيم", "هدى"},
+		lastNames:    []string{"العلي", "الحسن", "المنصور", "الخطيب", "السعيد", "القاسم", "النجار", "الحداد", "الشامي", "العمري", "الزهراني", "البكري", "الفارس", "الصالح", "الرشيد"},
+		streets:      []string{"شارع الملك فهد", "شارع التحلية", "شارع الجامعة", "طريق المطار", "شارع الأمير سلطان", "شارع العليا", "شارع الستين", "شارع السلام", "طريق الملك عبدالعزيز", "شارع البحر"},
+		streetFormat: "%[2]d %[1]s",
+		country: countryData{
+			name:        "المملكة العربية السعودية",
+			phoneFormat: "+966 5# ### ####",
+			currency:    "SAR",
+			places: []place{
+				{"الرياض", "منطقة الرياض", "1####"},
+				{"جدة", "منطقة مكة المكرمة", "2####"},
+				{"مكة المكرمة", "منطقة مكة المكرمة", "2####"},
+				{"المدينة المنورة", "منطقة المدينة المنورة", "4####"},
+				{"الدمام", "المنطقة الشرقية", "3####"},
+				{"الخبر", "المنطقة الشرقية", "3####"},
+				{"الطائف", "منطقة مكة المكرمة", "2####"},
+				{"تبوك", "منطقة تبوك", "7####"},
+				{"أبها", "منطقة عسير", "6####"},
+				{"بريدة", "منطقة القصيم", "5####"},
+			},
+		},
+		words:         []string{"الطلب", "تم", "شحن", "العميل", "شكرا", "المنتج", "التوصيل", "غدا", "العنوان", "الدفع", "بنجاح", "الرجاء", "التواصل", "المستودع", "الإرجاع", "الخدمة", "ممتازة", "تحديث", "الفاتورة", "جديد"},
+		wordSeparator: " ",
+		sentenceEnd:   ".",
+	},
+	"hi": {
+		firstNames:   []string{"आरव", "अनन्या", "विवान", "दिया", "अर्जुन", "प्रिया", "राहुल", "सुनीता", "रोहन", "कविता", "अमित", "नेहा", "विजय", "पूजा", "संजय"},
+		lastNames:    []string{"शर्मा", "वर्मा", "गुप्ता", "सिंह", "कुमार", "पटेल", "जोशी", "मिश्रा", "यादव", "अग्रवाल", "चौहान", "मेहता", "रेड्डी", "नायर", "त्रिपाठी"},
+		streets:      []string{"महात्मा गांधी मार्ग", "नेहरू रोड", "स्टेशन रोड", "राजपथ", "सुभाष मार्ग", "गांधी नगर", "शिवाजी मार्ग", "लाल बहादुर शास्त्री मार्ग", "पटेल चौक", "सरोजिनी नगर"},
+		streetFormat: "%[2]d, %[1]s",
+		country: countryData{
+			name:        "भारत",
+			phoneFormat: "+91 ##### #####",
+			currency:    "INR",
+			places: []place{
+				{"दिल्ली", "दिल्ली", "110###"},
+				{"मुंबई", "महाराष्ट्र", "400###"},
+				{"बेंगलुरु", "कर्नाटक", "560###"},
+				{"कोलकाता", "पश्चिम बंगाल", "700###"},
+				{"चेन्नई", "तमिलनाडु", "600###"},
+				{"हैदराबाद", "तेलंगाना", "500###"},
+				{"पुणे", "महाराष्ट्र", "411###"},
+				{"जयपुर", "राजस्थान", "302###"},
+				{"लखनऊ", "उत्तर प्रदेश", "226###"},
+				{"अहमदाबाद", "गुजरात", "380###"},
+			},
+		},
+		words:         []string{"ऑर्डर", "ग्राहक", "डिलीवरी", "भेजा", "गया", "धन्यवाद", "उत्पाद", "भुगतान", "पता", "कल", "गोदाम", "वापसी", "सेवा", "अच्छी", "कृपया", "संपर्क", "करें", "नया", "बिल", "प्राप्त"},
+		wordSeparator: " ",
+		sentenceEnd:   "।",
+	},
+	"zh": {
+		firstNames:   []string{"伟", "芳", "娜", "秀英", "敏", "静", "丽", "强", "磊", "军", "洋", "勇", "艳", "杰", "娟"},
+		lastNames:    []string{"王", "李", "张", "刘", "陈", "杨", "黄", "赵", "吴", "周", "徐", "孙", "马", "朱", "胡"},
+		familyFirst:  true,
+		streets:      []string{"建国路", "中山路", "人民大道", "解放路", "长安街", "南京东路", "淮海中路", "和平街", "滨江大道", "科技园路"},
+		streetFormat: "%[1]s%[2]d号",
+		country: countryData{
+			name:        "中国",
+			phoneFormat: "+86 1## #### ####",
+			currency:    "CNY",
+			places: []place{
+				{"北京", "北京市", "100###"},
+				{"上海", "上海市", "200###"},
+				{"广州", "广东省", "510###"},
+				{"深圳", "广东省", "518###"},
+				{"成都", "四川省", "610###"},
+				{"杭州", "浙江省", "310###"},
+				{"武汉", "湖北省", "430###"},
+				{"西安", "陕西省", "710###"},
+				{"南京", "江苏省", "210###"},
+				{"重庆", "重庆市", "400###"},
+			},
+		},
+		words:         []string{"我们", "订单", "客户", "已经", "发货", "请", "联系", "仓库", "明天", "送达", "包装", "完好", "谢谢", "支付", "成功", "地址", "更新", "退货", "服务", "满意"},
+		wordSeparator: "",
+		sentenceEnd:   "。",
+	},
+	"ja": {
+		firstNames:   []string{"太郎", "花子", "翔太", "陽菜", "蓮", "結衣", "大輔", "美咲", "健一", "さくら", "悠斗", "愛子", "拓也", "ゆい", "直樹"},
+		lastNames:    []string{"佐藤", "鈴木", "高橋", "田中", "伊藤", "渡辺", "山本", "中村", "小林", "加藤", "吉田", "山田", "佐々木", "山口", "松本"},
+		familyFirst:  true,
+		streets:      []string{"銀座", "本町", "栄町", "中央", "桜木町", "緑ヶ丘", "旭町", "新町", "元町", "日本橋"},
+		streetFormat: "%[1]s%[2]d丁目",
+		country: countryData{
+			name:        "日本",
+			phoneFormat: "+81 ##-####-####",
+			currency:    "JPY",
+			places: []place{
+				{"東京", "東京都", "1##-####"},
+				{"大阪", "大阪府", "53#-####"},
+				{"横浜", "神奈川県", "22#-####"},
+				{"名古屋", "愛知県", "45#-####"},
+				{"札幌", "北海道", "06#-####"},
+				{"福岡", "福岡県", "81#-####"},
+				{"神戸", "兵庫県", "65#-####"},
+				{"京都", "京都府", "60#-####"},
+				{"仙台", "宮城県", "98#-####"},
+				{"広島", "広島県", "73#-####"},
+			},
+		},
+		words:         []string{"ご注文", "ありがとう", "ございます", "配送", "予定", "商品", "お客様", "確認", "届きました", "梱包", "返品", "支払い", "住所", "変更", "連絡", "明日", "倉庫", "在庫", "満足", "サービス"},
+		wordSeparator: "",
+		sentenceEnd:   "。",
+	},
+	LocaleEmoji: {
+		emoji:         true,
+		wordSeparator: " ",
+		sentenceEnd:   ".",
+	},
+}
+
+// Locales returns the supported locales, LocaleDefault first
+func Locales() []string {
+	names := make([]string, 0, len(locales))
+	for name := range locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{LocaleDefault}, names...)
+}
+
+// ValidateLocale checks that documents can be generated in locale
+// ("" = LocaleDefault)
+func ValidateLocale(locale string) error {
+	if locale == "" || locale == LocaleDefault || locales[locale] != nil {
+		return nil
+	}
+	return fmt.Errorf("unsupported locale %q (supported: %s)", locale, strings.Join(Locales(), ", "))
+}
+
+// ParseLocales parses a comma-separated list of locales ("" = none)
+func ParseLocales(s string) ([]string, error) {
+	var list []string
+	for _, locale := range strings.Split(s, ",") {
+		locale = strings.TrimSpace(locale)
+		if locale == "" {
+			continue
+		}
+		if err := ValidateLocale(locale); err != nil {
+			return nil, err
+		}
+		list = append(list, locale)
+	}
+	return list, nil
+}
+
+// CollationLocale returns the language of an ICU collation locale, such as
+// de for de@collation=phonebook or fr for fr_CA, as a generation locale
+func CollationLocale(collation string) string {
+	language, _, _ := strings.Cut(collation, "@")
+	language, _, _ = strings.Cut(language, "_")
+	return strings.ToLower(language)
+}
+
+// newLocales looks up the data of each locale (nil = LocaleDefault)
+func newLocales(names []string) []*localeData {
+	data := make([]*localeData, len(names))
+	for i, name := range names {
+		data[i] = locales[name]
+	}
+	return data
+}
+
+// applyLocale rewrites the names, addresses, and notes of a customer in a
+// locale picked at random. Notes keep their size in bytes, so multi-byte
+// text does not push the document past its target size. With
+// Options.CoherentAddresses, the addresses then move to the locale's country.
+func (g *Generator) applyLocale(doc *CustomerDocument) {
+	var l *localeData
+	if len(g.locales) > 0 {
+		l = g.locales[g.faker.IntN(len(g.locales))]
+	}
+	if l != nil {
+		g.localize(doc, l)
+	}
+	if g.options.CoherentAddresses {
+		g.applyCountry(doc, l)
+	}
+}
+
+// localize rewrites the names, addresses, and notes of a customer in locale l
+func (g *Generator) localize(doc *CustomerDocument, l *localeData) {
+	doc.FirstName, doc.LastName = l.firstName(g.faker), l.lastName(g.faker)
+	for i := range doc.Addresses {
+		l.localizeAddress(g.faker, &doc.Addresses[i])
+	}
+	for i := range doc.PaymentMethods {
+		doc.PaymentMethods[i].CardHolder = l.fullName(g.faker)
+	}
+	for i := range doc.Notes {
+		doc.Notes[i] = l.text(g.faker, len(doc.Notes[i]))
+	}
+	for i := range doc.Orders {
+		order := &doc.Orders[i]
+		l.localizeAddress(g.faker, &order.ShippingAddress)
+		l.localizeAddress(g.faker, &order.BillingAddress)
+		order.Notes = l.text(g.faker, len(order.Notes))
+	}
+}
+
+// firstName returns a given name of the locale
+func (l *localeData) firstName(f *gofakeit.Faker) string {
+	if l.emoji {
+		return f.FirstName() + " " + f.Emoji()
+	}
+	return f.RandomString(l.firstNames)
+}
+
+// lastName returns a family name of the locale
+func (l *localeData) lastName(f *gofakeit.Faker) string {
+	if l.emoji {
+		return f.LastName()
+	}
+	return f.RandomString(l.lastNames)
+}
+
+// fullName returns a full name in the locale's order
+func (l *localeData) fullName(f *gofakeit.Faker) string {
+	if l.familyFirst {
+		return l.lastName(f) + l.firstName(f)
+	}
+	return l.firstName(f) + " " + l.lastName(f)
+}
+
+// localizeAddress replaces the parts of address with those of the locale,
+// each drawn independently, so the city, region, and postal code do not match
+// (see applyCountry)
+func (l *localeData) localizeAddress(f *gofakeit.Faker, address *Address) {
+	if len(l.streets) == 0 {
+		return
+	}
+	address.Street = fmt.Sprintf(l.streetFormat, f.RandomString(l.streets), f.IntRange(1, 199))
+	address.City = l.country.place(f).city
+	address.State = l.country.place(f).region
+	address.ZipCode = numerify(f, l.country.place(f).postalCode)
+	address.Country = l.country.name
+}
+
+// text returns sentences of the locale's words, cut to at most size bytes
+// at a character boundary
+func (l *localeData) text(f *gofakeit.Faker, size int) string {
+	var sb strings.Builder
+	for n := 0; sb.Len() < size; n++ {
+		if n > 0 {
+			if n%8 == 0 {
+				sb.WriteString(l.sentenceEnd)
+			}
+			sb.WriteString(l.wordSeparator)
+		}
+		switch {
+		case !l.emoji:
+			sb.WriteString(f.RandomString(l.words))
+		case n%2 == 0:
+			sb.WriteString(f.Word())
+		default:
+			sb.WriteString(f.Emoji())
+		}
+	}
+
+	text := sb.String()
+	for len(text) > size {
+		_, width := utf8.DecodeLastRuneInString(text)
+		text = text[:len(text)-width]
+	}
+	return text
+}
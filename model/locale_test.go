@@ -0,0 +1,90 @@
+package model
+
+import (
+	"slices"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestValidateLocale(t *testing.T) {
+	for _, locale := range append(Locales(), "") {
+		if err := ValidateLocale(locale); err != nil {
+			t.Errorf("ValidateLocale(%q): %v", locale, err)
+		}
+	}
+	if err := ValidateLocale("xx"); err == nil {
+		t.Error("Expected error for locale xx")
+	}
+}
+
+func TestCollationLocale(t *testing.T) {
+	tests := map[string]string{
+		"de":                     "de",
+		"de@collation=phonebook": "de",
+		"fr_CA":                  "fr",
+		"simple":                 "simple",
+	}
+	for in, want := range tests {
+		if got := CollationLocale(in); got != want {
+			t.Errorf("CollationLocale(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLocaleNamesAndAddresses(t *testing.T) {
+	g := NewGeneratorWithOptions(Size2KB, Options{Locales: []string{"sv"}})
+	sv := locales["sv"]
+	for i := 0; i < 20; i++ {
+		doc, err := g.Generate()
+		if err != nil {
+			t.Fatalf("Failed to generate document: %v", err)
+		}
+		if !slices.Contains(sv.firstNames, doc.FirstName) || !slices.Contains(sv.lastNames, doc.LastName) {
+			t.Errorf("Name %s %s is not Swedish", doc.FirstName, doc.LastName)
+		}
+		for _, address := range doc.Addresses {
+			if address.Country != sv.country.name || !slices.ContainsFunc(sv.country.places, func(p place) bool { return p.city == address.City }) || len(address.ZipCode) != len("### ##") {
+				t.Errorf("Address %+v is not Swedish", address)
+			}
+		}
+	}
+}
+
+func TestParseLocales(t *testing.T) {
+	got, err := ParseLocales("en, zh,ar,ru,emoji")
+	if err != nil || !slices.Equal(got, []string{"en", "zh", "ar", "ru", "emoji"}) {
+		t.Errorf("ParseLocales = %v, %v", got, err)
+	}
+	if _, err := ParseLocales("en,klingon"); err == nil {
+		t.Error("Expected error for locale klingon")
+	}
+}
+
+func TestLocaleNotesKeepSize(t *testing.T) {
+	plain := NewGeneratorWithOptions(Size16KB, Options{})
+	for _, locale := range []string{"zh", "ar", "hi", LocaleEmoji} {
+		doc, err := plain.Generate()
+		if err != nil {
+			t.Fatalf("Failed to generate document: %v", err)
+		}
+		if len(doc.Notes) == 0 || len(doc.Orders) == 0 {
+			t.Fatalf("No notes or orders generated")
+		}
+		notes, orderNotes := len(doc.Notes[0]), len(doc.Orders[0].Notes)
+
+		g := NewGeneratorWithOptions(Size16KB, Options{Locales: []string{locale}})
+		g.applyLocale(doc)
+		for _, note := range []string{doc.Notes[0], doc.Orders[0].Notes} {
+			if !utf8.ValidString(note) || note == "" {
+				t.Errorf("%s note is not valid text: %q", locale, note)
+			}
+			if utf8.RuneCountInString(note) == len(note) {
+				t.Errorf("%s note is single-byte text: %q", locale, note)
+			}
+		}
+		// Cut at a character boundary, so up to 3 bytes short
+		if len(doc.Notes[0]) > notes || len(doc.Notes[0]) < notes-3 || len(doc.Orders[0].Notes) > orderNotes || len(doc.Orders[0].Notes) < orderNotes-3 {
+			t.Errorf("%s notes are %d and %d bytes, want %d and %d", locale, len(doc.Notes[0]), len(doc.Orders[0].Notes), notes, orderNotes)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxConversationMessages caps the messages array of a conversation when
+// workload updates push new messages, keeping documents well below 16MB
+const MaxConversationMessages = 2000
+
+// ConversationDocument represents a direct or group conversation with its
+// messages embedded in order
+type ConversationDocument struct {
+	ID             primitive.ObjectID `bson:"_id"`
+	ConversationID string             `bson:"conversation_id"`
+	Type           string             `bson:"type"` // direct or group
+	Title          string             `bson:"title"`
+	Participants   []Participant      `bson:"participants"`
+	Messages       []Message          `bson:"messages"`
+	MessageCount   int                `bson:"message_count"`
+	UnreadCount    int                `bson:"unread_count"`
+	LastMessageAt  time.Time          `bson:"last_message_at"`
+	CreatedAt      time.Time          `bson:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at"`
+
+	Metadata map[string]interface{} `bson:"metadata"`
+	Tags     []string               `bson:"tags"`
+
+	// Padding field to control document size
+	Padding Padding `bson:"padding"`
+
+	// Sample of every BSON type (Options.BSONTypes)
+	BSONTypes *BSONTypes `bson:"bson_types,omitempty"`
+
+	// Chain of subdocuments Options.NestingDepth levels deep
+	Nested bson.D `bson:"nested,omitempty"`
+
+	docShape // Sparse fields and legacy schema (Options.Sparsity, Options.LegacyFraction)
+}
+
+// Participant is a member of a conversation
+type Participant struct {
+	UserID      string    `bson:"user_id"`
+	DisplayName string    `bson:"display_name"`
+	Role        string    `bson:"role"` // owner or member
+	JoinedAt    time.Time `bson:"joined_at"`
+	Muted       bool      `bson:"muted"`
+}
+
+// Message is a message sent to the other participants of a conversation
+type Message struct {
+	ID           primitive.ObjectID `bson:"_id"`
+	SenderID     string             `bson:"sender_id"`
+	RecipientIDs []string           `bson:"recipient_ids"`
+	Body         string             `bson:"body"`
+	SentAt       time.Time          `bson:"sent_at"`
+	Read         bool               `bson:"read"`
+	Edited       bool               `bson:"edited"`
+	Reactions    []string           `bson:"reactions"`
+}
+
+// DocumentID returns the document's _id
+func (d *ConversationDocument) DocumentID() primitive.ObjectID { return d.ID }
+
+// SetDocumentID replaces the document's _id
+func (d *ConversationDocument) SetDocumentID(id primitive.ObjectID) {
+	d.ID = id
+	d.encoded = nil
+}
+
+// SetMetadata sets metadata.key
+func (d *ConversationDocument) SetMetadata(key string, value interface{}) {
+	if d.Metadata == nil {
+		d.Metadata = make(map[string]interface{})
+	}
+	d.Metadata[key] = value
+	d.patchMetadata(key, value)
+}
+
+// MarshalBSON encodes the document in its shape, see docShape
+func (d *ConversationDocument) MarshalBSON() ([]byte, error) {
+	type plain ConversationDocument
+	return d.docShape.marshal((*plain)(d))
+}
+
+func (d *ConversationDocument) shape() *docShape { return &d.docShape }
+
+// GenerateConversation creates a new conversation with the target size. The
+// most recent messages are unread.
+func (g *Generator) GenerateConversation() (*ConversationDocument, error) {
+	seq, conversationID := g.keys.issue()
+	now := g.now()
+	created := g.dateRange(now.AddDate(-1, 0, 0), now.AddDate(0, 0, -1))
+	if g.options.OrderedTimes {
+		var step time.Duration
+		created, step = g.createdAt(seq, now, 1)
+		now = notBefore(now, created.Add(step))
+	}
+
+	convType, numParticipants := "direct", 2
+	if g.faker.IntRange(1, 100) <= 30 {
+		convType, numParticipants = "group", g.faker.IntRange(3, 8)
+	}
+
+	doc := &ConversationDocument{
+		ID:             primitive.NewObjectID(),
+		ConversationID: conversationID,
+		Type:           convType,
+		Participants:   make([]Participant, numParticipants),
+		CreatedAt:      created,
+		UpdatedAt:      now,
+		Metadata:       map[string]interface{}{"created_by": "system", "client": g.faker.RandomString([]string{"ios", "android", "web", "desktop"})},
+		Tags:           []string{convType},
+	}
+	if convType == "group" {
+		doc.Title = g.faker.HipsterWord() + " " + g.faker.Noun()
+	}
+
+	userIDs := make([]string, numParticipants)
+	for i := range doc.Participants {
+		userIDs[i] = g.keys.space.UserKey(g.faker.IntRange(0, DefaultUserCount-1))
+		role := "member"
+		if i == 0 {
+			role = "owner"
+		}
+		doc.Participants[i] = Participant{
+			UserID:      userIDs[i],
+			DisplayName: g.faker.Username(),
+			Role:        role,
+			JoinedAt:    created,
+			Muted:       g.faker.IntRange(1, 100) <= 10,
+		}
+	}
+
+	// Messages are spread in order between creation and now
+	numMessages := g.messageCount(numParticipants)
+	interval := now.Sub(created) / time.Duration(numMessages+1)
+	unread := g.faker.IntRange(0, min(5, numMessages))
+	doc.Messages = make([]Message, numMessages)
+	for i := range doc.Messages {
+		doc.Messages[i] = g.generateMessage(userIDs, created.Add(interval*time.Duration(i+1)))
+		doc.Messages[i].Read = i < numMessages-unread
+	}
+	doc.MessageCount = numMessages
+	doc.UnreadCount = unread
+	doc.LastMessageAt = doc.Messages[numMessages-1].SentAt
+
+	g.applyNoPII(doc)
+	g.applyCardinality(doc)
+	if err := g.applyFieldValues(doc); err != nil {
+		return nil, err
+	}
+	if err := g.applyFieldTemplates(doc); err != nil {
+		return nil, err
+	}
+	g.applySparsity(doc)
+	g.applyLegacy(doc)
+
+	bsonTypes, err := g.bsonTypes()
+	if err != nil {
+		return nil, err
+	}
+	doc.BSONTypes = bsonTypes
+	doc.Nested = g.nestedChain()
+
+	padding, err := g.calculatePadding(doc)
+	if err != nil {
+		return nil, err
+	}
+	doc.Padding = padding
+
+	return doc, nil
+}
+
+// generateMessage creates a message from a random participant to the others
+func (g *Generator) generateMessage(participants []string, sentAt time.Time) Message {
+	sender := g.faker.IntRange(0, len(participants)-1)
+	recipients := make([]string, 0, len(participants)-1)
+	for i, userID := range participants {
+		if i != sender {
+			recipients = append(recipients, userID)
+		}
+	}
+
+	var reactions []string
+	if g.faker.IntRange(1, 100) <= 20 {
+		reactions = []string{g.faker.RandomString([]string{"like", "love", "laugh", "wow", "sad"})}
+	}
+
+	return Message{
+		ID:           primitive.NewObjectID(),
+		SenderID:     participants[sender],
+		RecipientIDs: recipients,
+		Body:         g.faker.Sentence(g.faker.IntRange(4, 30)),
+		SentAt:       sentAt.Truncate(time.Millisecond),
+		Edited:       g.faker.IntRange(1, 100) <= 5,
+		Reactions:    reactions,
+	}
+}
+
+// messageCount determines how many messages fill most of a conversation
+// with numParticipants participants
+func (g *Generator) messageCount(numParticipants int) int {
+	messageBytes := 190 + 45*(numParticipants-1)
+	count := (int(g.targetSize)*90/100 - 500 - 130*numParticipants) / messageBytes
+	if count < 1 {
+		count = 1
+	}
+	return count
+}
+
+// ConversationUpdate returns an update for an existing conversation: either
+// a new message pushed onto the messages array (capped at
+// MaxConversationMessages), or every message marked read. The new message's
+// sender and recipient are random users, as the conversation is not read first.
+func (g *Generator) ConversationUpdate(markRead bool) bson.D {
+	return g.conversationUpdate(markRead, MaxConversationMessages)
+}
+
+// conversationUpdate is ConversationUpdate keeping the newest limit messages
+func (g *Generator) conversationUpdate(markRead bool, limit int) bson.D {
+	now := time.Now()
+	if markRead {
+		return bson.D{
+			{Key: "$set", Value: bson.D{
+				{Key: "messages.$[].read", Value: true},
+				{Key: "unread_count", Value: 0},
+				{Key: "updated_at", Value: now},
+			}},
+			{Key: "$inc", Value: bson.D{{Key: "revision", Value: 1}}},
+		}
+	}
+
+	participants := []string{
+		g.keys.space.UserKey(g.faker.IntRange(0, DefaultUserCount-1)),
+		g.keys.space.UserKey(g.faker.IntRange(0, DefaultUserCount-1)),
+	}
+	return bson.D{
+		{Key: "$push", Value: bson.D{{Key: "messages", Value: bson.D{
+			{Key: "$each", Value: []Message{g.generateMessage(participants, now)}},
+			{Key: "$slice", Value: -limit},
+		}}}},
+		{Key: "$set", Value: bson.D{
+			{Key: "last_message_at", Value: now},
+			{Key: "updated_at", Value: now},
+		}},
+		{Key: "$inc", Value: bson.D{
+			{Key: "message_count", Value: 1},
+			{Key: "unread_count", Value: 1},
+			{Key: "revision", Value: 1},
+		}},
+	}
+}
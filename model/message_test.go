@@ -0,0 +1,82 @@
+package model
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestConversationDocumentSizes(t *testing.T) {
+	sizes := []DocumentSize{Size2KB, Size4KB, Size8KB, Size16KB, Size32KB, Size64KB}
+
+	for _, size := range sizes {
+		gen := NewGeneratorWithOptions(size, Options{Template: TemplateMessages})
+
+		doc, err := gen.GenerateDocument()
+		if err != nil {
+			t.Fatalf("Failed to generate conversation: %v", err)
+		}
+		conv, ok := doc.(*ConversationDocument)
+		if !ok {
+			t.Fatalf("Expected a conversation document, got %T", doc)
+		}
+
+		data, err := bson.Marshal(conv)
+		if err != nil {
+			t.Fatalf("Failed to marshal conversation: %v", err)
+		}
+		t.Logf("%dKB %s: %d bytes, %d padding, %d messages", size/1024, conv.Type, len(data), len(conv.Padding.Data), len(conv.Messages))
+		if len(data) < int(size)*9/10 || len(data) > int(size)*11/10 {
+			t.Errorf("%dKB conversation is %d bytes, want within 10%%", size/1024, len(data))
+		}
+	}
+}
+
+func TestConversationMessages(t *testing.T) {
+	gen := NewGeneratorWithOptions(Size8KB, Options{Template: TemplateMessages})
+
+	for i := 0; i < 20; i++ {
+		conv, err := gen.GenerateConversation()
+		if err != nil {
+			t.Fatalf("Failed to generate conversation: %v", err)
+		}
+
+		participants := make(map[string]bool)
+		for _, p := range conv.Participants {
+			participants[p.UserID] = true
+		}
+		unread := 0
+		for m, msg := range conv.Messages {
+			if !participants[msg.SenderID] || len(msg.RecipientIDs) != len(conv.Participants)-1 {
+				t.Fatalf("Message %d is not between participants", m)
+			}
+			if m > 0 && msg.SentAt.Before(conv.Messages[m-1].SentAt) {
+				t.Fatalf("Message %d was sent before the previous message", m)
+			}
+			if !msg.Read {
+				unread++
+			}
+		}
+		if unread != conv.UnreadCount || conv.MessageCount != len(conv.Messages) {
+			t.Errorf("Counts %d unread of %d, want %d of %d", conv.UnreadCount, conv.MessageCount, unread, len(conv.Messages))
+		}
+	}
+}
+
+func TestConversationUpdate(t *testing.T) {
+	gen := NewGeneratorWithOptions(Size4KB, Options{Template: TemplateMessages})
+
+	push := gen.ConversationUpdate(false)
+	if push[0].Key != "$push" {
+		t.Errorf("Expected a $push update, got %s", push[0].Key)
+	}
+	if _, err := bson.Marshal(push); err != nil {
+		t.Errorf("Failed to marshal push update: %v", err)
+	}
+
+	markRead := gen.ConversationUpdate(true)
+	set := markRead[0].Value.(bson.D)
+	if markRead[0].Key != "$set" || set[0].Key != "messages.$[].read" {
+		t.Errorf("Expected every message to be marked read, got %v", markRead)
+	}
+}
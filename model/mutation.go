@@ -0,0 +1,128 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// MaxPushedItems caps the orders and reviews PushUpdate appends to, keeping
+// the newest, so documents stay well under the 16MB limit however long
+// mutations run
+const MaxPushedItems = 1000
+
+// PushCap returns the number of elements PushUpdate keeps in the template's
+// PushField
+func PushCap(template string) int {
+	if template == TemplateMessages {
+		return MaxConversationMessages
+	}
+	return MaxPushedItems
+}
+
+// PushField returns the array of template documents PushUpdate appends to
+// ("" = the template has none)
+func PushField(template string) string {
+	switch template {
+	case "", TemplateCustomer:
+		return "orders"
+	case TemplateProduct:
+		return "reviews"
+	case TemplateMessages:
+		return "messages"
+	}
+	return ""
+}
+
+// PushUpdate returns an update appending a newly generated element to an
+// existing document's PushField: a new order for customers, counted in their
+// OrderStats, a review for products, or a message for conversations. The
+// array keeps its newest PushCap elements.
+func (g *Generator) PushUpdate() (bson.D, error) {
+	return g.CappedPushUpdate(PushCap(g.options.Template))
+}
+
+// CappedPushUpdate is PushUpdate keeping the newest limit elements of the
+// array, to let documents grow further or settle sooner
+func (g *Generator) CappedPushUpdate(limit int) (bson.D, error) {
+	now := time.Now()
+	targetKB := int(g.targetSize) / 1024
+
+	var item interface{}
+	switch g.options.Template {
+	case "", TemplateCustomer:
+		item = g.generateOrder(now, now, targetKB)
+	case TemplateProduct:
+		review := g.generateReview(now, now, targetKB)
+		review.Author = g.personal(piiField{prefix: "User"}, review.Author)
+		item = review
+	case TemplateMessages:
+		return g.conversationUpdate(false, limit), nil
+	default:
+		return nil, fmt.Errorf("the %s template has no array to push to", g.options.Template)
+	}
+
+	update := bson.D{
+		{Key: "$push", Value: bson.D{{Key: PushField(g.options.Template), Value: bson.D{
+			{Key: "$each", Value: []interface{}{item}},
+			{Key: "$slice", Value: -limit},
+		}}}},
+		{Key: "$set", Value: bson.D{{Key: "updated_at", Value: now}}},
+		{Key: "$inc", Value: bson.D{{Key: "revision", Value: 1}}},
+	}
+	if order, ok := item.(Order); ok {
+		update = addOrderStats(update, order)
+	}
+	return update, nil
+}
+
+// FieldUpdate returns an update changing fields of an existing document the
+// way an application would: a customer's phone, email, or default address,
+// a product's price or stock, a device's status, a transaction's status, or
+// a conversation read. updated_at and revision change with every update.
+func (g *Generator) FieldUpdate() bson.D {
+	now := time.Now()
+	set := bson.D{{Key: "updated_at", Value: now}}
+	update := bson.D{{Key: "$inc", Value: bson.D{{Key: "revision", Value: 1}}}}
+
+	switch g.options.Template {
+	case "", TemplateCustomer:
+		switch g.faker.IntRange(0, 2) {
+		case 0:
+			set = append(set, bson.E{Key: "phone", Value: g.personal(piiField{prefix: "000"}, g.faker.Phone())})
+		case 1:
+			set = append(set, bson.E{Key: "email", Value: g.personal(piiField{email: true}, g.faker.Email())})
+		default:
+			set = append(set, bson.E{Key: "addresses.0", Value: g.generateAddress(true)})
+		}
+	case TemplateProduct:
+		if g.faker.Bool() {
+			set = append(set, bson.E{Key: "price", Value: g.faker.Price(5, 2000)})
+		} else {
+			set = append(set,
+				bson.E{Key: "inventory.0.quantity", Value: g.faker.IntRange(0, 1000)},
+				bson.E{Key: "inventory.0.updated_at", Value: now})
+		}
+	case TemplateTelemetry:
+		set = append(set, bson.E{Key: "status", Value: g.deviceStatus()})
+	case TemplateTransaction:
+		status := g.transactionStatus()
+		set = append(set, bson.E{Key: "status", Value: status})
+		update = append(update, bson.E{Key: "$push", Value: bson.D{
+			{Key: "status_history", Value: StatusChange{Status: status, At: now}},
+		}})
+	case TemplateMessages:
+		return g.ConversationUpdate(true)
+	}
+	return append(bson.D{{Key: "$set", Value: set}}, update...)
+}
+
+// personal returns value, or a synthetic token of the same size in place of
+// it under Options.NoPII
+func (g *Generator) personal(field piiField, value string) string {
+	if !g.options.NoPII {
+		return value
+	}
+	return g.piiToken(field, len(value))
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestPushUpdate(t *testing.T) {
+	for _, template := range []string{TemplateCustomer, TemplateProduct, TemplateMessages} {
+		update, err := NewGeneratorWithOptions(Size4KB, Options{Template: template}).PushUpdate()
+		if err != nil {
+			t.Fatalf("%s: PushUpdate failed: %v", template, err)
+		}
+		push, ok := update.Map()["$push"].(bson.D)
+		if !ok || len(push) != 1 || push[0].Key != PushField(template) {
+			t.Errorf("%s: expected a push to %s, got %v", template, PushField(template), update)
+		}
+		if _, err := bson.Marshal(update); err != nil {
+			t.Errorf("%s: failed to encode update: %v", template, err)
+		}
+	}
+
+	if _, err := NewGeneratorWithOptions(Size4KB, Options{Template: TemplateEvents}).PushUpdate(); err == nil {
+		t.Error("Expected an error pushing to events")
+	}
+}
+
+func TestCappedPushUpdate(t *testing.T) {
+	for _, template := range []string{TemplateCustomer, TemplateProduct, TemplateMessages} {
+		g := NewGeneratorWithOptions(Size4KB, Options{Template: template})
+		for _, limit := range []int{PushCap(template), 50} {
+			update, err := g.CappedPushUpdate(limit)
+			if err != nil {
+				t.Fatalf("%s: CappedPushUpdate failed: %v", template, err)
+			}
+			each := update.Map()["$push"].(bson.D)[0].Value.(bson.D)
+			if slice := each.Map()["$slice"]; slice != -limit {
+				t.Errorf("%s: expected $slice %d, got %v", template, -limit, slice)
+			}
+		}
+	}
+}
+
+func TestFieldUpdate(t *testing.T) {
+	for _, template := range []string{TemplateCustomer, TemplateProduct, TemplateTelemetry, TemplateTransaction, TemplateMessages, TemplateEvents} {
+		g := NewGeneratorWithOptions(Size4KB, Options{Template: template})
+		for i := 0; i < 10; i++ {
+			update := g.FieldUpdate()
+			set, ok := update.Map()["$set"].(bson.D)
+			if !ok || set.Map()["updated_at"] == nil {
+				t.Fatalf("%s: expected updated_at to be set, got %v", template, update)
+			}
+			if _, err := bson.Marshal(update); err != nil {
+				t.Fatalf("%s: failed to encode update: %v", template, err)
+			}
+		}
+	}
+}
+
+func TestFieldUpdateNoPII(t *testing.T) {
+	g := NewGeneratorWithOptions(Size4KB, Options{NoPII: true})
+	for i := 0; i < 30; i++ {
+		set := g.FieldUpdate().Map()["$set"].(bson.D).Map()
+		if phone, ok := set["phone"].(string); ok && !strings.HasPrefix(phone, "000") {
+			t.Errorf("Expected a synthetic phone, got %q", phone)
+		}
+		if email, ok := set["email"].(string); ok && !strings.HasSuffix(email, ".invalid") {
+			t.Errorf("Expected a synthetic email, got %q", email)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package model
+
+// OrderDocument is a standalone order that references its customer by
+// customer_id, for generating separate customers and orders collections
+type OrderDocument struct {
+	Order      `bson:",inline"`
+	CustomerID string `bson:"customer_id"`
+	RunID      string `bson:"run_id,omitempty"` // The customer's metadata.run_id, if tagged
+}
+
+// OrderDocuments extracts the customer's order history as standalone order
+// documents referencing the customer
+func OrderDocuments(customer *CustomerDocument) []interface{} {
+	runID, _ := customer.Metadata["run_id"].(string)
+	orders := make([]interface{}, len(customer.Orders))
+	for i, order := range customer.Orders {
+		orders[i] = &OrderDocument{
+			Order:      order,
+			CustomerID: customer.CustomerID,
+			RunID:      runID,
+		}
+	}
+	return orders
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// createdAt returns the created_at of the document with sequence number seq
+// and the time until the next document's: random in the years before now,
+// or under Options.OrderedTimes its place on the timeline, so created_at
+// advances with the insertion order
+func (g *Generator) createdAt(seq int64, now time.Time, years int) (time.Time, time.Duration) {
+	if !g.options.OrderedTimes {
+		return g.dateRange(now.AddDate(-years, 0, 0), now), 0
+	}
+	return g.timeline.at(seq), g.timeline.after(seq, 1)
+}
+
+// orderDate returns the date of order i of n in a customer document created
+// at created: random in the two years before now, or under
+// Options.OrderedTimes evenly spaced until the next document's created_at,
+// so order dates advance with the insertion order too
+func (g *Generator) orderDate(created time.Time, step time.Duration, now time.Time, i, n int) time.Time {
+	if !g.options.OrderedTimes {
+		return g.dateRange(now.AddDate(-2, 0, 0), now)
+	}
+	return created.Add(step * time.Duration(i) / time.Duration(n))
+}
+
+// notBefore returns t, or earliest if t is before it, so that dates derived
+// from an ordered created_at in the future don't precede it
+func notBefore(t, earliest time.Time) time.Time {
+	if t.Before(earliest) {
+		return earliest
+	}
+	return t
+}
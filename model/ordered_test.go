@@ -0,0 +1,59 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOrderedTimesFollowGenerationOrder(t *testing.T) {
+	r, _ := ParseTimeRange("2024-01-01/2024-01-02")
+	g := NewGeneratorWithOptions(Size16KB, Options{TimeRange: r, ExpectedDocuments: 24, OrderedTimes: true})
+
+	var last time.Time
+	for i := 0; i < 3; i++ {
+		doc, err := g.Generate()
+		if err != nil {
+			t.Fatalf("Failed to generate document: %v", err)
+		}
+		if want := r.Start.Add(time.Duration(i) * time.Hour); !doc.CreatedAt.Equal(want) {
+			t.Errorf("document %d created at %v, want %v", i, doc.CreatedAt, want)
+		}
+		if doc.CreatedAt.Before(last) {
+			t.Errorf("document %d created at %v, before the last order date %v", i, doc.CreatedAt, last)
+		}
+		last = doc.CreatedAt
+		for _, order := range doc.Orders {
+			if order.OrderDate.Before(last) || !order.OrderDate.Before(doc.CreatedAt.Add(time.Hour)) {
+				t.Errorf("document %d has order date %v out of order", i, order.OrderDate)
+			}
+			last = order.OrderDate
+		}
+	}
+}
+
+func TestOrderedTimesTemplates(t *testing.T) {
+	r, _ := ParseTimeRange("2030-01-01/2030-01-02")
+	options := Options{TimeRange: r, ExpectedDocuments: 24, OrderedTimes: true}
+
+	product, err := NewGeneratorWithOptions(Size4KB, options).GenerateProduct()
+	if err != nil {
+		t.Fatalf("Failed to generate product: %v", err)
+	}
+	if !product.CreatedAt.Equal(r.Start) || product.UpdatedAt.Before(product.CreatedAt) {
+		t.Errorf("product created at %v and updated at %v, want created at %v", product.CreatedAt, product.UpdatedAt, r.Start)
+	}
+
+	options.Template = TemplateMessages
+	conversation, err := NewGeneratorWithOptions(Size4KB, options).GenerateConversation()
+	if err != nil {
+		t.Fatalf("Failed to generate conversation: %v", err)
+	}
+	if !conversation.CreatedAt.Equal(r.Start) {
+		t.Errorf("conversation created at %v, want %v", conversation.CreatedAt, r.Start)
+	}
+	for _, m := range conversation.Messages {
+		if m.SentAt.Before(conversation.CreatedAt) {
+			t.Errorf("message sent at %v, before the conversation was created", m.SentAt)
+		}
+	}
+}
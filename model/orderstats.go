@@ -0,0 +1,62 @@
+package model
+
+import (
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// OrderStats aggregates the orders of a customer, as a store keeps them next
+// to the orders, so that they match what an aggregation over the orders
+// returns. Orders appended by PushUpdate update them too; orders it trims
+// from the array stay counted, as lifetime figures.
+type OrderStats struct {
+	OrderCount     int        `bson:"order_count"`
+	LifetimeValue  float64    `bson:"lifetime_value"` // Sum of total_amount of the orders not cancelled
+	FirstOrderDate *time.Time `bson:"first_order_date,omitempty"`
+	LastOrderDate  *time.Time `bson:"last_order_date,omitempty"`
+}
+
+// newOrderStats aggregates orders
+func newOrderStats(orders []Order) OrderStats {
+	var stats OrderStats
+	for _, order := range orders {
+		date := order.OrderDate
+		stats.OrderCount++
+		if order.Status != "cancelled" {
+			stats.LifetimeValue += order.TotalAmount
+		}
+		if stats.FirstOrderDate == nil || date.Before(*stats.FirstOrderDate) {
+			stats.FirstOrderDate = &date
+		}
+		if stats.LastOrderDate == nil || date.After(*stats.LastOrderDate) {
+			stats.LastOrderDate = &date
+		}
+	}
+	stats.LifetimeValue = roundCents(stats.LifetimeValue)
+	return stats
+}
+
+// addOrderStats adds order to the order stats of a customer in update, whose
+// $inc it extends
+func addOrderStats(update bson.D, order Order) bson.D {
+	for i, op := range update {
+		if op.Key != "$inc" {
+			continue
+		}
+		inc := append(op.Value.(bson.D), bson.E{Key: "order_stats.order_count", Value: 1})
+		if order.Status != "cancelled" {
+			inc = append(inc, bson.E{Key: "order_stats.lifetime_value", Value: order.TotalAmount})
+		}
+		update[i].Value = inc
+	}
+	return append(update,
+		bson.E{Key: "$min", Value: bson.D{{Key: "order_stats.first_order_date", Value: order.OrderDate}}},
+		bson.E{Key: "$max", Value: bson.D{{Key: "order_stats.last_order_date", Value: order.OrderDate}}})
+}
+
+// roundCents rounds an amount of money to two decimals
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"math"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestOrderTotalsAreConsistent(t *testing.T) {
+	g := NewGeneratorWithOptions(Size16KB, Options{Orders: CountRange{Min: 1, Max: 6}})
+	for i := 0; i < 50; i++ {
+		doc, err := g.Generate()
+		if err != nil {
+			t.Fatalf("Failed to generate document: %v", err)
+		}
+
+		var lifetimeValue float64
+		for _, order := range doc.Orders {
+			var subtotal, discounts, taxes float64
+			for _, item := range order.LineItems {
+				if math.Abs(item.TotalPrice-float64(item.Quantity)*item.UnitPrice) > 0.005 {
+					t.Errorf("Line item total %v is not %d x %v", item.TotalPrice, item.Quantity, item.UnitPrice)
+				}
+				subtotal += item.TotalPrice
+			}
+			for _, discount := range order.Discounts {
+				discounts += discount.Amount
+			}
+			if discounts > subtotal+0.005 {
+				t.Errorf("Discounts %v exceed the subtotal %v", discounts, subtotal)
+			}
+			for _, tax := range order.Taxes {
+				if math.Abs(tax.Amount-(subtotal-discounts)*tax.Rate) > 0.01 {
+					t.Errorf("Tax %v is not %v of %v", tax.Amount, tax.Rate, subtotal-discounts)
+				}
+				taxes += tax.Amount
+			}
+			if math.Abs(order.TotalAmount-(subtotal-discounts+taxes)) > 0.005 {
+				t.Errorf("Order total %v is not %v - %v + %v", order.TotalAmount, subtotal, discounts, taxes)
+			}
+			if order.Status != "cancelled" {
+				lifetimeValue += order.TotalAmount
+			}
+		}
+
+		stats := doc.OrderStats
+		if stats.OrderCount != len(doc.Orders) || math.Abs(stats.LifetimeValue-lifetimeValue) > 0.005 {
+			t.Errorf("Order stats %+v do not match %d orders worth %v", stats, len(doc.Orders), lifetimeValue)
+		}
+		for _, order := range doc.Orders {
+			if order.OrderDate.Before(*stats.FirstOrderDate) || order.OrderDate.After(*stats.LastOrderDate) {
+				t.Errorf("Order date %v is outside %v - %v", order.OrderDate, stats.FirstOrderDate, stats.LastOrderDate)
+			}
+		}
+	}
+}
+
+func TestPushUpdateCountsOrder(t *testing.T) {
+	update, err := NewGeneratorWithOptions(Size4KB, Options{}).PushUpdate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops := update.Map()
+	inc := ops["$inc"].(bson.D).Map()
+	if inc["revision"] != 1 || inc["order_stats.order_count"] != 1 {
+		t.Errorf("Expected revision and order count increments, got %v", inc)
+	}
+	if ops["$min"] == nil || ops["$max"] == nil {
+		t.Errorf("Expected first and last order dates, got %v", update)
+	}
+}
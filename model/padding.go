@@ -0,0 +1,144 @@
+package model
+
+import (
+	_ "embed"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// PaddingMode selects how padding bytes are produced
+type PaddingMode string
+
+const (
+	// PaddingRandom fills padding with high-entropy random bytes that resist
+	// compression. They are stored as a string, which is not valid UTF-8.
+	PaddingRandom PaddingMode = "random"
+	// PaddingBase64 fills padding with random characters of the base64
+	// alphabet, a valid UTF-8 string that compresses to about 75%
+	PaddingBase64 PaddingMode = "base64"
+	// PaddingBinary fills padding with random bytes stored as BSON binary
+	// data, which resists compression and needs no valid encoding
+	PaddingBinary PaddingMode = "binary"
+	// PaddingCorpus fills padding with natural-language sentences, for
+	// full-text index and compression benchmarks
+	PaddingCorpus PaddingMode = "corpus"
+)
+
+// base64Alphabet holds the characters of base64 padding
+const base64Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// Padding is the padding field of a document: a string, or BSON binary data
+// (subtype 0) if Binary is set
+type Padding struct {
+	Data   string
+	Binary bool
+}
+
+// MarshalBSONValue encodes the padding as a string or as binary data, which
+// take the same number of bytes
+func (p Padding) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	value := make([]byte, 0, len(p.Data)+5)
+	if !p.Binary {
+		return bsontype.String, bsoncore.AppendString(value, p.Data), nil
+	}
+	value = bsoncore.AppendInt32(value, int32(len(p.Data)))
+	value = append(value, bsontype.BinaryGeneric)
+	return bsontype.Binary, append(value, p.Data...), nil
+}
+
+// UnmarshalBSONValue decodes padding stored as a string or as binary data
+func (p *Padding) UnmarshalBSONValue(t bsontype.Type, value []byte) error {
+	switch t {
+	case bsontype.String:
+		data, _, ok := bsoncore.ReadString(value)
+		if !ok {
+			return fmt.Errorf("invalid padding string")
+		}
+		*p = Padding{Data: data}
+	case bsontype.Binary:
+		_, data, _, ok := bsoncore.ReadBinary(value)
+		if !ok {
+			return fmt.Errorf("invalid padding binary data")
+		}
+		*p = Padding{Data: string(data), Binary: true}
+	default:
+		return fmt.Errorf("cannot decode %s into padding", t)
+	}
+	return nil
+}
+
+// ParsePaddingMode validates a padding mode name
+func ParsePaddingMode(mode string) (PaddingMode, error) {
+	switch PaddingMode(strings.ToLower(mode)) {
+	case "", PaddingRandom:
+		return PaddingRandom, nil
+	case PaddingBase64:
+		return PaddingBase64, nil
+	case PaddingBinary:
+		return PaddingBinary, nil
+	case PaddingCorpus:
+		return PaddingCorpus, nil
+	default:
+		return "", fmt.Errorf("invalid padding mode: %s", mode)
+	}
+}
+
+// randomPadding fills size bytes with the output of a xorshift64* generator
+// started at seed, 8 bytes per step. Compressors find no redundancy in it,
+// and it costs a fraction of crypto/rand or a byte-at-a-time generator.
+func randomPadding(seed uint64, size int) string {
+	return string(randomBytes(seed, size))
+}
+
+// base64Padding fills size bytes with random characters of the base64
+// alphabet, 6 random bits each
+func base64Padding(seed uint64, size int) string {
+	padding := randomBytes(seed, size)
+	for i, b := range padding {
+		padding[i] = base64Alphabet[b&63]
+	}
+	return string(padding)
+}
+
+// randomBytes returns size bytes of xorshift64* output, see randomPadding
+func randomBytes(seed uint64, size int) []byte {
+	if seed == 0 {
+		seed = 0x9E3779B97F4A7C15 // xorshift never leaves a zero state
+	}
+	padding := make([]byte, (size+7)&^7)
+	x := seed
+	for i := 0; i < len(padding); i += 8 {
+		x ^= x >> 12
+		x ^= x << 25
+		x ^= x >> 27
+		binary.LittleEndian.PutUint64(padding[i:], x*0x2545F4914F6CDD1D)
+	}
+	return padding[:size]
+}
+
+//go:embed data/corpus.txt
+var corpusText string
+
+// corpusSentences holds the sentences of the embedded corpus
+var corpusSentences = strings.Split(strings.TrimSpace(corpusText), "\n")
+
+// generateCorpusPadding fills size bytes with randomly chosen corpus sentences.
+// The corpus is ASCII, so truncating the last sentence keeps valid UTF-8.
+func generateCorpusPadding(faker *gofakeit.Faker, size int) string {
+	var sb strings.Builder
+	sb.Grow(size + 256)
+
+	for sb.Len() < size {
+		if sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(corpusSentences[faker.IntN(len(corpusSentences))])
+	}
+
+	return sb.String()[:size]
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"bytes"
+	"compress/flate"
+	"testing"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestRandomPadding(t *testing.T) {
+	padding := randomPadding(42, 64*1024+3)
+	if len(padding) != 64*1024+3 {
+		t.Fatalf("Padding has %d bytes, want %d", len(padding), 64*1024+3)
+	}
+	if padding == randomPadding(43, len(padding)) {
+		t.Error("Different seeds produced the same padding")
+	}
+	if randomPadding(0, 16) == string(make([]byte, 16)) {
+		t.Error("Seed 0 produced zero padding")
+	}
+
+	var compressed bytes.Buffer
+	w, _ := flate.NewWriter(&compressed, flate.BestCompression)
+	w.Write([]byte(padding))
+	w.Close()
+	if compressed.Len() < len(padding) {
+		t.Errorf("Padding compressed from %d to %d bytes", len(padding), compressed.Len())
+	}
+}
+
+func TestPaddingModes(t *testing.T) {
+	for _, mode := range []PaddingMode{PaddingRandom, PaddingBase64, PaddingBinary} {
+		g := NewGeneratorWithOptions(Size8KB, Options{PaddingMode: mode, Checksum: true})
+		doc, err := g.GenerateDocument()
+		if err != nil {
+			t.Fatalf("%s: failed to generate document: %v", mode, err)
+		}
+		data, err := bson.Marshal(doc)
+		if err != nil {
+			t.Fatalf("%s: failed to marshal document: %v", mode, err)
+		}
+
+		padding := bson.Raw(data).Lookup("padding")
+		switch mode {
+		case PaddingBinary:
+			if padding.Type != bson.TypeBinary {
+				t.Errorf("%s: padding is %s, want binary data", mode, padding.Type)
+			}
+		case PaddingBase64:
+			if s, ok := padding.StringValueOK(); !ok || !utf8.ValidString(s) {
+				t.Errorf("%s: padding is not a valid UTF-8 string", mode)
+			}
+		}
+
+		// Documents read back keep their padding and checksum
+		var decoded CustomerDocument
+		if err := bson.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("%s: failed to decode document: %v", mode, err)
+		}
+		customer := doc.(*CustomerDocument)
+		if decoded.Padding != customer.Padding {
+			t.Errorf("%s: decoded padding differs", mode)
+		}
+		if sum, err := DocumentChecksum(&decoded); err != nil || sum != customer.Checksum {
+			t.Errorf("%s: decoded checksum %s, want %s (%v)", mode, sum, customer.Checksum, err)
+		}
+	}
+}
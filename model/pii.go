@@ -0,0 +1,105 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+)
+
+// piiEmailDomains are the domains of synthetic email addresses, longest
+// first, in a top-level domain reserved by RFC 2606 so they can never reach
+// a real mailbox
+var piiEmailDomains = []string{"@example.invalid", "@x.invalid"}
+
+// piiField is a field holding personal data, replaced under Options.NoPII by
+// a token of the same size: prefix followed by random digits, or a
+// user<digits> address at one of piiEmailDomains for emails
+type piiField struct {
+	path   string
+	prefix string
+	email  bool
+}
+
+// piiFields lists the fields of each template holding personal data. Phone
+// and card numbers keep only digits behind prefixes no real number has.
+var piiFields = map[string][]piiField{
+	TemplateCustomer: {
+		{path: "email", email: true},
+		{path: "first_name", prefix: "First"},
+		{path: "last_name", prefix: "Last"},
+		{path: "phone", prefix: "000"},
+		{path: "payment_methods.card_number", prefix: "0000"},
+		{path: "payment_methods.card_holder", prefix: "Holder"},
+	},
+	TemplateProduct: {
+		{path: "reviews.author", prefix: "User"},
+	},
+	TemplateMessages: {
+		{path: "participants.display_name", prefix: "User"},
+	},
+}
+
+// applyNoPII replaces the personal data of doc with synthetic tokens when
+// Options.NoPII is set
+func (g *Generator) applyNoPII(doc Document) {
+	if !g.options.NoPII {
+		return
+	}
+	template := g.options.Template
+	if template == "" {
+		template = TemplateCustomer
+	}
+	for _, field := range piiFields[template] {
+		g.replacePII(reflect.ValueOf(doc), field, strings.Split(field.path, "."))
+	}
+}
+
+// replacePII walks path from v and replaces every string it reaches
+func (g *Generator) replacePII(v reflect.Value, field piiField, path []string) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			g.replacePII(v.Elem(), field, path)
+		}
+		return
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			g.replacePII(v.Index(i), field, path)
+		}
+		return
+	}
+
+	if len(path) == 0 {
+		if v.Kind() == reflect.String {
+			v.SetString(g.piiToken(field, len(v.String())))
+		}
+		return
+	}
+	if v.Kind() == reflect.Struct {
+		if i, ok := fieldIndex(v.Type(), path[0]); ok {
+			g.replacePII(v.Field(i), field, path[1:])
+		}
+	}
+}
+
+// piiToken returns a synthetic value of field that is n bytes long
+func (g *Generator) piiToken(field piiField, n int) string {
+	if field.email {
+		for _, domain := range piiEmailDomains {
+			if n > len(domain)+len("user") {
+				return g.digitToken("user", n-len(domain)) + domain
+			}
+		}
+	}
+	return g.digitToken(field.prefix, n)
+}
+
+// digitToken returns prefix followed by random digits, cut to n bytes
+func (g *Generator) digitToken(prefix string, n int) string {
+	var b strings.Builder
+	b.Grow(n)
+	b.WriteString(prefix)
+	for b.Len() < n {
+		b.WriteByte(byte('0' + g.faker.IntN(10)))
+	}
+	return b.String()[:n]
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNoPIIKeepsSizes(t *testing.T) {
+	doc, err := NewGenerator(Size8KB).Generate()
+	if err != nil {
+		t.Fatalf("Failed to generate document: %v", err)
+	}
+	email, first, phone := len(doc.Email), len(doc.FirstName), len(doc.Phone)
+	card := len(doc.PaymentMethods[0].CardNumber)
+
+	g := NewGeneratorWithOptions(Size8KB, Options{NoPII: true})
+	g.applyNoPII(doc)
+	if len(doc.Email) != email || len(doc.FirstName) != first || len(doc.Phone) != phone || len(doc.PaymentMethods[0].CardNumber) != card {
+		t.Errorf("Expected sizes to be kept, got %q, %q, %q, %q", doc.Email, doc.FirstName, doc.Phone, doc.PaymentMethods[0].CardNumber)
+	}
+	if !strings.HasPrefix(doc.Email, "user") || !strings.HasSuffix(doc.Email, ".invalid") {
+		t.Errorf("Expected a synthetic email, got %q", doc.Email)
+	}
+	if !strings.HasPrefix(doc.Phone, "000") || !strings.HasPrefix(doc.PaymentMethods[0].CardNumber, "0000") {
+		t.Errorf("Expected synthetic numbers, got %q and %q", doc.Phone, doc.PaymentMethods[0].CardNumber)
+	}
+	for _, pm := range doc.PaymentMethods {
+		if !strings.HasPrefix("Holder", pm.CardHolder) && !strings.HasPrefix(pm.CardHolder, "Holder") {
+			t.Errorf("Expected a synthetic card holder, got %q", pm.CardHolder)
+		}
+	}
+}
+
+func TestNoPIITemplates(t *testing.T) {
+	g := NewGeneratorWithOptions(Size8KB, Options{Template: TemplateMessages, NoPII: true})
+	doc, err := g.GenerateConversation()
+	if err != nil {
+		t.Fatalf("Failed to generate conversation: %v", err)
+	}
+	for _, p := range doc.Participants {
+		if !strings.HasPrefix(p.DisplayName, "User") {
+			t.Errorf("Expected a synthetic display name, got %q", p.DisplayName)
+		}
+	}
+}
+
+func TestDigitToken(t *testing.T) {
+	g := NewGenerator(Size2KB)
+	for n := 0; n < 12; n++ {
+		token := g.digitToken("Last", n)
+		if len(token) != n || !strings.HasPrefix("Last", token) && !strings.HasPrefix(token, "Last") {
+			t.Errorf("Unexpected %d-byte token %q", n, token)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"github.com/brianvoe/gofakeit/v7"
+)
+
+// place is a city with its region and the pattern of its postal codes
+// (# = digit)
+type place struct {
+	city       string
+	region     string
+	postalCode string
+}
+
+// countryData holds the name, phone numbers, currency, and places of the
+// addresses of a country
+type countryData struct {
+	name        string
+	phoneFormat string // # = digit
+	currency    string // ISO 4217 code
+	places      []place
+}
+
+// unitedStates is the country of LocaleDefault and LocaleEmoji addresses
+var unitedStates = countryData{
+	name:        "United States",
+	phoneFormat: "+1 ###-###-####",
+	currency:    "USD",
+	places: []place{
+		{"New York", "New York", "100##"},
+		{"Los Angeles", "California", "900##"},
+		{"San Francisco", "California", "941##"},
+		{"San Diego", "California", "921##"},
+		{"Chicago", "Illinois", "606##"},
+		{"Houston", "Texas", "770##"},
+		{"Dallas", "Texas", "752##"},
+		{"Austin", "Texas", "787##"},
+		{"San Antonio", "Texas", "782##"},
+		{"Phoenix", "Arizona", "850##"},
+		{"Philadelphia", "Pennsylvania", "191##"},
+		{"Jacksonville", "Florida", "322##"},
+		{"Miami", "Florida", "331##"},
+		{"Columbus", "Ohio", "432##"},
+		{"Charlotte", "North Carolina", "282##"},
+		{"Indianapolis", "Indiana", "462##"},
+		{"Seattle", "Washington", "981##"},
+		{"Denver", "Colorado", "802##"},
+		{"Boston", "Massachusetts", "021##"},
+		{"Nashville", "Tennessee", "372##"},
+		{"Portland", "Oregon", "972##"},
+		{"Atlanta", "Georgia", "303##"},
+		{"Minneapolis", "Minnesota", "554##"},
+		{"Detroit", "Michigan", "482##"},
+	},
+}
+
+// place returns a place of the country at random
+func (c *countryData) place(f *gofakeit.Faker) place {
+	return c.places[f.IntN(len(c.places))]
+}
+
+// placeAddress moves address to a place of the country, with a postal code
+// of its city
+func (c *countryData) placeAddress(f *gofakeit.Faker, address *Address) {
+	p := c.place(f)
+	address.City = p.city
+	address.State = p.region
+	address.ZipCode = numerify(f, p.postalCode)
+	address.Country = c.name
+}
+
+// numerify replaces every # of pattern with a digit. Unlike Faker.Numerify,
+// it keeps leading zeros, as in the postal codes of Boston (021##).
+func numerify(f *gofakeit.Faker, pattern string) string {
+	b := []byte(pattern)
+	for i := range b {
+		if b[i] == '#' {
+			b[i] = '0' + byte(f.IntN(10))
+		}
+	}
+	return string(b)
+}
+
+// applyCountry places all addresses of a customer in the country of locale l
+// (nil = LocaleDefault), each in a city with its region and postal code, and
+// gives the customer a phone number and orders the currency of that country
+func (g *Generator) applyCountry(doc *CustomerDocument, l *localeData) {
+	c := &unitedStates
+	if l != nil && len(l.country.places) > 0 {
+		c = &l.country
+	}
+	place := func(address *Address) {
+		if c == &unitedStates {
+			address.Street = g.faker.Street()
+		}
+		c.placeAddress(g.faker, address)
+	}
+
+	doc.Phone = numerify(g.faker, c.phoneFormat)
+	for i := range doc.Addresses {
+		place(&doc.Addresses[i])
+	}
+	for i := range doc.Orders {
+		order := &doc.Orders[i]
+		place(&order.ShippingAddress)
+		place(&order.BillingAddress)
+		order.Currency = c.currency
+	}
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCoherentAddresses(t *testing.T) {
+	for _, locale := range []string{LocaleDefault, "de", "ja"} {
+		g := NewGeneratorWithOptions(Size4KB, Options{Locales: []string{locale}, CoherentAddresses: true, Orders: CountRange{Min: 1, Max: 3}})
+		country := &unitedStates
+		if l := locales[locale]; l != nil {
+			country = &l.country
+		}
+		regions := make(map[string]place)
+		for _, p := range country.places {
+			regions[p.city] = p
+		}
+
+		for i := 0; i < 20; i++ {
+			doc, err := g.Generate()
+			if err != nil {
+				t.Fatalf("Failed to generate document: %v", err)
+			}
+			if !strings.HasPrefix(doc.Phone, country.phoneFormat[:strings.Index(country.phoneFormat, " ")+1]) || len(doc.Phone) != len(country.phoneFormat) {
+				t.Errorf("%s phone %s does not match %s", locale, doc.Phone, country.phoneFormat)
+			}
+			addresses := doc.Addresses
+			for _, order := range doc.Orders {
+				if order.Currency != country.currency {
+					t.Errorf("%s order currency %s, want %s", locale, order.Currency, country.currency)
+				}
+				addresses = append(addresses, order.ShippingAddress, order.BillingAddress)
+			}
+			for _, address := range addresses {
+				p, ok := regions[address.City]
+				if !ok || address.State != p.region || address.Country != country.name || !matchesPostalCode(address.ZipCode, p.postalCode) {
+					t.Errorf("%s address %+v is not coherent", locale, address)
+				}
+			}
+		}
+	}
+}
+
+// matchesPostalCode reports whether code fits pattern (# = digit)
+func matchesPostalCode(code, pattern string) bool {
+	if len(code) != len(pattern) {
+		return false
+	}
+	for i := range pattern {
+		if pattern[i] == '#' && (code[i] < '0' || code[i] > '9') || pattern[i] != '#' && code[i] != pattern[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,247 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ProductDocument represents a catalog product with variants, per-warehouse
+// inventory, and customer reviews
+type ProductDocument struct {
+	ID          primitive.ObjectID     `bson:"_id"`
+	SKU         string                 `bson:"sku"`
+	Name        string                 `bson:"name"`
+	Brand       string                 `bson:"brand"`
+	Category    string                 `bson:"category"`
+	Description string                 `bson:"description"`
+	Price       float64                `bson:"price"`
+	Currency    string                 `bson:"currency"`
+	Attributes  map[string]interface{} `bson:"attributes"`
+
+	Variants  []Variant   `bson:"variants"`
+	Inventory []Inventory `bson:"inventory"`
+	Reviews   []Review    `bson:"reviews"`
+
+	CreatedAt time.Time `bson:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at"`
+
+	Metadata map[string]interface{} `bson:"metadata"`
+	Tags     []string               `bson:"tags"`
+
+	// Padding field to control document size
+	Padding Padding `bson:"padding"`
+
+	// Sample of every BSON type (Options.BSONTypes)
+	BSONTypes *BSONTypes `bson:"bson_types,omitempty"`
+
+	// Chain of subdocuments Options.NestingDepth levels deep
+	Nested bson.D `bson:"nested,omitempty"`
+
+	docShape // Sparse fields and legacy schema (Options.Sparsity, Options.LegacyFraction)
+}
+
+// Variant represents a purchasable variant of a product
+type Variant struct {
+	SKU     string  `bson:"sku"`
+	Color   string  `bson:"color"`
+	Size    string  `bson:"size"`
+	Price   float64 `bson:"price"`
+	Weight  float64 `bson:"weight_kg"`
+	Barcode string  `bson:"barcode"`
+}
+
+// Inventory represents the stock of a product in one warehouse
+type Inventory struct {
+	Warehouse    string    `bson:"warehouse"`
+	Region       string    `bson:"region"`
+	Quantity     int       `bson:"quantity"`
+	Reserved     int       `bson:"reserved"`
+	ReorderLevel int       `bson:"reorder_level"`
+	UpdatedAt    time.Time `bson:"updated_at"`
+}
+
+// Review represents a customer review of a product
+type Review struct {
+	ID           primitive.ObjectID `bson:"_id"`
+	Author       string             `bson:"author"`
+	Rating       int                `bson:"rating"` // 1-5
+	Title        string             `bson:"title"`
+	Body         string             `bson:"body"`
+	Verified     bool               `bson:"verified_purchase"`
+	HelpfulVotes int                `bson:"helpful_votes"`
+	CreatedAt    time.Time          `bson:"created_at"`
+}
+
+// DocumentID returns the document's _id
+func (d *ProductDocument) DocumentID() primitive.ObjectID { return d.ID }
+
+// SetDocumentID replaces the document's _id
+func (d *ProductDocument) SetDocumentID(id primitive.ObjectID) {
+	d.ID = id
+	d.encoded = nil
+}
+
+// SetMetadata sets metadata.key
+func (d *ProductDocument) SetMetadata(key string, value interface{}) {
+	if d.Metadata == nil {
+		d.Metadata = make(map[string]interface{})
+	}
+	d.Metadata[key] = value
+	d.patchMetadata(key, value)
+}
+
+// MarshalBSON encodes the document in its shape, see docShape
+func (d *ProductDocument) MarshalBSON() ([]byte, error) {
+	type plain ProductDocument
+	return d.docShape.marshal((*plain)(d))
+}
+
+func (d *ProductDocument) shape() *docShape { return &d.docShape }
+
+// GenerateProduct creates a new product document with the target size. SKUs
+// come from the key space's document sequence, so key-space targeting and
+// verification probes work as they do for customers.
+func (g *Generator) GenerateProduct() (*ProductDocument, error) {
+	seq, sku := g.keys.issue()
+	now := g.now()
+	createdAt, step := g.createdAt(seq, now, 5)
+	now = notBefore(now, createdAt.Add(step))
+
+	doc := &ProductDocument{
+		ID:        primitive.NewObjectID(),
+		SKU:       sku,
+		Name:      g.faker.ProductName(),
+		Brand:     g.faker.Company(),
+		Category:  g.faker.ProductCategory(),
+		Price:     g.faker.Price(5, 2000),
+		Currency:  g.faker.Currency().Short,
+		CreatedAt: createdAt,
+		UpdatedAt: now,
+		Metadata:  map[string]interface{}{"created_by": "system"},
+	}
+
+	// Reviews are the main content and scale with the target size, like
+	// orders do for customers, so padding stays a minority of the document
+	targetKB := int(g.targetSize) / 1024
+	var numVariants, numWarehouses, numReviews, numTags int
+	switch {
+	case targetKB <= 2:
+		numVariants, numWarehouses, numTags = 1, 1, 3
+		numReviews = g.reviewCount(targetKB, 1.5)
+		doc.Description = g.faker.Sentence(20)
+	case targetKB <= 4:
+		numVariants, numWarehouses, numTags = g.faker.IntRange(1, 3), g.faker.IntRange(1, 2), 5
+		numReviews = g.reviewCount(targetKB, 2.8)
+		doc.Description = g.faker.Paragraph(1, 3, 10, " ")
+	case targetKB <= 16:
+		numVariants, numWarehouses, numTags = g.faker.IntRange(3, 8), g.faker.IntRange(3, 6), g.faker.IntRange(5, 15)
+		numReviews = g.reviewCount(targetKB-3, 3.2)
+		doc.Description = g.faker.Paragraph(2, 4, 12, " ")
+	default:
+		numVariants, numWarehouses, numTags = g.faker.IntRange(8, 15), g.faker.IntRange(6, 12), g.faker.IntRange(15, 30)
+		numReviews = g.reviewCount(targetKB, 2.6)
+		doc.Description = g.faker.Paragraph(4, 6, 15, " ")
+		doc.Metadata = g.generateMetadata()
+	}
+
+	doc.Attributes = map[string]interface{}{
+		"material": g.faker.RandomString([]string{"cotton", "steel", "plastic", "wood", "glass", "leather"}),
+		"origin":   g.faker.Country(),
+		"warranty": g.faker.IntRange(0, 36),
+	}
+
+	doc.Variants = make([]Variant, numVariants)
+	for i := range doc.Variants {
+		doc.Variants[i] = Variant{
+			SKU:     doc.SKU + "-" + g.faker.DigitN(4),
+			Color:   g.faker.SafeColor(),
+			Size:    g.faker.RandomString([]string{"XS", "S", "M", "L", "XL", "one-size"}),
+			Price:   doc.Price * g.faker.Float64Range(0.9, 1.2),
+			Weight:  g.faker.Float64Range(0.05, 25),
+			Barcode: g.faker.DigitN(13),
+		}
+	}
+
+	doc.Inventory = make([]Inventory, numWarehouses)
+	for i := range doc.Inventory {
+		doc.Inventory[i] = Inventory{
+			Warehouse:    "WH-" + g.faker.LetterN(3),
+			Region:       g.faker.State(),
+			Quantity:     g.faker.IntRange(0, 5000),
+			Reserved:     g.faker.IntRange(0, 100),
+			ReorderLevel: g.faker.IntRange(10, 500),
+			UpdatedAt:    g.faker.DateRange(createdAt, now),
+		}
+	}
+
+	doc.Reviews = make([]Review, numReviews)
+	for i := range doc.Reviews {
+		doc.Reviews[i] = g.generateReview(createdAt, now, targetKB)
+	}
+
+	doc.Tags = make([]string, numTags)
+	for i := range doc.Tags {
+		doc.Tags[i] = g.faker.Word()
+	}
+
+	g.applyNoPII(doc)
+	g.applyCardinality(doc)
+	if err := g.applyFieldValues(doc); err != nil {
+		return nil, err
+	}
+	if err := g.applyFieldTemplates(doc); err != nil {
+		return nil, err
+	}
+	g.applySparsity(doc)
+	g.applyLegacy(doc)
+
+	bsonTypes, err := g.bsonTypes()
+	if err != nil {
+		return nil, err
+	}
+	doc.BSONTypes = bsonTypes
+	doc.Nested = g.nestedChain()
+
+	padding, err := g.calculatePadding(doc)
+	if err != nil {
+		return nil, err
+	}
+	doc.Padding = padding
+
+	return doc, nil
+}
+
+// reviewCount determines how many reviews fill most of a product document,
+// given the reviews per KB of target size for its review length
+func (g *Generator) reviewCount(targetKB int, perKB float64) int {
+	baseCount := int(float64(targetKB) * perKB)
+	if baseCount < 2 {
+		return 1
+	}
+	return g.faker.IntRange(baseCount-1, baseCount+1)
+}
+
+// generateReview creates a fake product review
+func (g *Generator) generateReview(from, to time.Time, targetKB int) Review {
+	var body string
+	if targetKB <= 4 {
+		body = g.faker.Sentence(15)
+	} else if targetKB <= 16 {
+		body = g.faker.Paragraph(1, 3, 12, " ")
+	} else {
+		body = g.faker.Paragraph(2, 4, 12, " ")
+	}
+
+	return Review{
+		ID:           primitive.NewObjectID(),
+		Author:       g.faker.Username(),
+		Rating:       g.faker.IntRange(1, 5),
+		Title:        g.faker.Sentence(5),
+		Body:         body,
+		Verified:     g.faker.Bool(),
+		HelpfulVotes: g.faker.IntRange(0, 250),
+		CreatedAt:    g.faker.DateRange(from, to),
+	}
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestProductDocumentSizes(t *testing.T) {
+	sizes := []DocumentSize{Size2KB, Size4KB, Size8KB, Size16KB, Size32KB, Size64KB}
+
+	for _, size := range sizes {
+		gen := NewGeneratorWithOptions(size, Options{Template: TemplateProduct})
+
+		doc, err := gen.GenerateDocument()
+		if err != nil {
+			t.Fatalf("Failed to generate product: %v", err)
+		}
+		product, ok := doc.(*ProductDocument)
+		if !ok {
+			t.Fatalf("Expected a product document, got %T", doc)
+		}
+		if product.SKU == "" || len(product.Reviews) == 0 || len(product.Inventory) == 0 {
+			t.Errorf("%dKB product is missing its SKU, reviews, or inventory", size/1024)
+		}
+
+		data, err := bson.Marshal(product)
+		if err != nil {
+			t.Fatalf("Failed to marshal product: %v", err)
+		}
+		t.Logf("%dKB: %d bytes, %d padding, %d reviews", size/1024, len(data), len(product.Padding.Data), len(product.Reviews))
+		if len(data) < int(size)*9/10 || len(data) > int(size)*11/10 {
+			t.Errorf("%dKB product is %d bytes, want within 10%%", size/1024, len(data))
+		}
+	}
+}
+
+func TestProductSchemaMatchesDocument(t *testing.T) {
+	gen := NewGeneratorWithOptions(Size8KB, Options{Template: TemplateProduct})
+	if gen.Schema().Template != TemplateProduct {
+		t.Fatalf("Expected product schema, got %s", gen.Schema().Template)
+	}
+
+	doc, err := gen.GenerateProduct()
+	if err != nil {
+		t.Fatalf("Failed to generate product: %v", err)
+	}
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Failed to marshal product: %v", err)
+	}
+	raw := bson.Raw(data)
+	for _, field := range gen.Schema().Fields {
+		if _, err := raw.LookupErr(field); err != nil {
+			t.Errorf("Schema field %s missing from product document", field)
+		}
+	}
+}
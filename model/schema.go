@@ -0,0 +1,169 @@
+package model
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// Schema describes the shape of generated documents so that later phases
+// (read workloads, verification) can query data they did not generate themselves
+type Schema struct {
+	Template   string   `bson:"template" json:"template"`
+	KeyField   string   `bson:"key_field" json:"key_field"`     // Business key, unique per document
+	GroupField string   `bson:"group_field" json:"group_field"` // Low-cardinality field for $group
+	ArrayField string   `bson:"array_field" json:"array_field"` // Main array for $unwind
+	Fields     []string `bson:"fields" json:"fields"`           // Top-level fields
+}
+
+// CustomerSchema describes documents produced by the customer template
+var CustomerSchema = Schema{
+	Template:   "customer",
+	KeyField:   "customer_id",
+	GroupField: "orders.status",
+	ArrayField: "orders",
+	Fields: []string{
+		"_id", "customer_id", "email", "first_name", "last_name", "phone",
+		"date_of_birth", "created_at", "updated_at", "addresses",
+		"payment_methods", "orders", "order_stats", "metadata", "notes", "tags",
+		"padding",
+	},
+}
+
+// ProductSchema describes documents produced by the product template
+var ProductSchema = Schema{
+	Template:   "product",
+	KeyField:   "sku",
+	GroupField: "reviews.rating",
+	ArrayField: "reviews",
+	Fields: []string{
+		"_id", "sku", "name", "brand", "category", "description", "price",
+		"currency", "attributes", "variants", "inventory", "reviews",
+		"created_at", "updated_at", "metadata", "tags", "padding",
+	},
+}
+
+// TelemetrySchema describes documents produced by the telemetry template
+var TelemetrySchema = Schema{
+	Template:   "telemetry",
+	KeyField:   "bucket_id",
+	GroupField: "device_type",
+	ArrayField: "readings",
+	Fields: []string{
+		"_id", "bucket_id", "device_id", "device_type", "firmware", "location",
+		"start_time", "end_time", "reading_count", "readings", "summary",
+		"status", "created_at", "updated_at", "metadata", "tags", "padding",
+	},
+}
+
+// TransactionSchema describes documents produced by the transactions template
+var TransactionSchema = Schema{
+	Template:   "transactions",
+	KeyField:   "transaction_id",
+	GroupField: "currency",
+	ArrayField: "postings",
+	Fields: []string{
+		"_id", "transaction_id", "ledger_seq", "type", "from_account", "to_account",
+		"amount", "currency", "status", "timestamp", "value_date", "description",
+		"channel", "postings", "status_history", "created_at", "updated_at",
+		"metadata", "tags", "padding",
+	},
+}
+
+// MessagesSchema describes documents produced by the messages template
+var MessagesSchema = Schema{
+	Template:   "messages",
+	KeyField:   "conversation_id",
+	GroupField: "messages.read",
+	ArrayField: "messages",
+	Fields: []string{
+		"_id", "conversation_id", "type", "title", "participants", "messages",
+		"message_count", "unread_count", "last_message_at", "created_at",
+		"updated_at", "metadata", "tags", "padding",
+	},
+}
+
+// EventsSchema describes documents produced by the events template
+var EventsSchema = Schema{
+	Template:   "events",
+	KeyField:   "event_id",
+	GroupField: "level",
+	Fields: []string{
+		"_id", "event_id", "seq", "ts", "level", "service", "host", "message",
+		"trace_id", "span_id", "user_id", "status_code", "duration_ms",
+		"attributes", "metadata", "tags", "padding",
+	},
+}
+
+// TextFields returns the natural-language fields of template documents,
+// which text indexes and text searches cover (nil = the template has none)
+func TextFields(template string) []string {
+	switch template {
+	case "", TemplateCustomer:
+		return []string{"notes", "orders.notes"}
+	case TemplateProduct:
+		return []string{"name", "description", "reviews.title", "reviews.body"}
+	case TemplateTransaction:
+		return []string{"description"}
+	case TemplateMessages:
+		return []string{"title", "messages.body"}
+	case TemplateEvents:
+		return []string{"message"}
+	}
+	return nil
+}
+
+// Schema returns the schema of documents produced by this generator
+func (g *Generator) Schema() Schema {
+	schema := CustomerSchema
+	switch g.options.Template {
+	case TemplateProduct:
+		schema = ProductSchema
+	case TemplateTelemetry:
+		schema = TelemetrySchema
+	case TemplateTransaction:
+		schema = TransactionSchema
+	case TemplateMessages:
+		schema = MessagesSchema
+	case TemplateEvents:
+		schema = EventsSchema
+	}
+	if g.options.Checksum {
+		schema.Fields = append(append([]string(nil), schema.Fields...), "checksum")
+	}
+	if g.options.BSONTypes {
+		schema.Fields = append(append([]string(nil), schema.Fields...), "bson_types")
+	}
+	if g.options.NestingDepth > 0 {
+		schema.Fields = append(append([]string(nil), schema.Fields...), "nested")
+	}
+	return schema
+}
+
+// FieldTypes returns the BSON type of each top-level field of template's
+// documents, read from a sample document of a separate generator so that no
+// keys of a load's key space are used
+func FieldTypes(template string) (map[string]bsontype.Type, error) {
+	size := Size2KB
+	if template == TemplateEvents {
+		size = Size512B
+	}
+	doc, err := NewGeneratorWithOptions(size, Options{Template: template}).GenerateDocument()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate sample document: %w", err)
+	}
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal sample document: %w", err)
+	}
+	elements, err := bson.Raw(data).Elements()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sample document: %w", err)
+	}
+	types := make(map[string]bsontype.Type, len(elements))
+	for _, element := range elements {
+		types[element.Key()] = element.Value().Type
+	}
+	return types, nil
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+func TestFieldTypes(t *testing.T) {
+	types, err := FieldTypes(TemplateCustomer)
+	if err != nil {
+		t.Fatalf("Failed to read field types: %v", err)
+	}
+	want := map[string]bsontype.Type{
+		"_id":             bsontype.ObjectID,
+		"email":           bsontype.String,
+		"date_of_birth":   bsontype.DateTime,
+		"payment_methods": bsontype.Array,
+		"metadata":        bsontype.EmbeddedDocument,
+	}
+	for field, typ := range want {
+		if types[field] != typ {
+			t.Errorf("Expected %s to be %v, got %v", field, typ, types[field])
+		}
+	}
+
+	types, err = FieldTypes(TemplateEvents)
+	if err != nil {
+		t.Fatalf("Failed to read field types: %v", err)
+	}
+	for _, field := range EventsSchema.Fields {
+		if _, ok := types[field]; !ok {
+			t.Errorf("Expected a type for events field %s", field)
+		}
+	}
+}
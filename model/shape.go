@@ -0,0 +1,101 @@
+package model
+
+import (
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// docShape is how one document deviates from its struct when marshalled:
+// sparse fields removed or set to null, as concrete paths with array indexes
+// (orders.2.notes), and the changes of the legacy schema. Documents embed it.
+type docShape struct {
+	missing [][]string
+	null    [][]string
+	legacy  []legacyChange // nil for documents in the current schema
+
+	// encoded is the document's encoding, kept with PaddingSizingPatch,
+	// patched by metadata stamps, and reset by other changes after
+	// generation (nil = encode the document)
+	encoded []byte
+}
+
+// shapedDocument is a document that records its shape
+type shapedDocument interface {
+	shape() *docShape
+}
+
+// marshal encodes doc, a document type without a MarshalBSON method, in
+// the shape s
+func (s *docShape) marshal(doc interface{}) ([]byte, error) {
+	if s.encoded != nil {
+		return s.encoded, nil
+	}
+	if len(s.missing) == 0 && len(s.null) == 0 && s.legacy == nil {
+		return bson.Marshal(doc)
+	}
+
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var d bson.D
+	if err := bson.Unmarshal(data, &d); err != nil {
+		return nil, err
+	}
+	for _, path := range s.null {
+		d = editPath(d, path, true)
+	}
+	for _, path := range s.missing {
+		d = editPath(d, path, false)
+	}
+	if s.legacy != nil {
+		d = toLegacySchema(d, s.legacy)
+	}
+	return bson.Marshal(d)
+}
+
+// editPath sets the field at path (through subdocuments and array indexes)
+// to null, or removes it
+func editPath(d bson.D, path []string, null bool) bson.D {
+	for i, e := range d {
+		if e.Key != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			if null {
+				d[i].Value = nil
+				return d
+			}
+			return append(d[:i], d[i+1:]...)
+		}
+		d[i].Value = editValue(e.Value, path[1:], null)
+		return d
+	}
+	if null && len(path) == 1 {
+		// Zeroed omitempty fields are not encoded at all
+		return append(d, bson.E{Key: path[0]})
+	}
+	return d
+}
+
+// editValue edits path within a subdocument or array value
+func editValue(v interface{}, path []string, null bool) interface{} {
+	switch v := v.(type) {
+	case bson.D:
+		return editPath(v, path, null)
+	case bson.A:
+		i, err := strconv.Atoi(path[0])
+		if err != nil || i >= len(v) {
+			return v
+		}
+		if len(path) == 1 {
+			// Array elements are only ever replaced by null, keeping indexes
+			v[i] = nil
+			return v
+		}
+		v[i] = editValue(v[i], path[1:], null)
+		return v
+	}
+	return v
+}
@@ -0,0 +1,239 @@
+package model
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// PaddingSizing selects how the padding of a document is fitted to the
+// target size
+type PaddingSizing string
+
+const (
+	// PaddingSizingMarshal sizes each document by marshalling it, and
+	// writers marshal it again with its padding
+	PaddingSizingMarshal PaddingSizing = "marshal"
+	// PaddingSizingPatch patches the padding into the encoding that sized
+	// the document, which writers reuse instead of marshalling it again
+	PaddingSizingPatch PaddingSizing = "patch"
+)
+
+const (
+	// SizeTolerance is how far, in bytes, documents of Options.ExactSize
+	// may land from the target size
+	SizeTolerance = 16
+
+	// calibrationSamples is the number of documents measured per round of
+	// Calibrate, and calibrationRounds bounds the rounds
+	calibrationSamples = 20
+	calibrationRounds  = 5
+)
+
+// ParsePaddingSizing validates a padding sizing name
+func ParsePaddingSizing(sizing string) (PaddingSizing, error) {
+	switch PaddingSizing(strings.ToLower(sizing)) {
+	case "", PaddingSizingMarshal:
+		return PaddingSizingMarshal, nil
+	case PaddingSizingPatch:
+		return PaddingSizingPatch, nil
+	default:
+		return "", fmt.Errorf("invalid padding sizing: %s", sizing)
+	}
+}
+
+// patchElement replaces the top-level field key of the BSON document data
+// with a value of type t encoded as value, adjusting the document's length
+// header. It reports false if data has no such field.
+func patchElement(data []byte, key string, t bsontype.Type, value []byte) ([]byte, bool) {
+	for start := 4; start < len(data)-1; {
+		elem, _, ok := bsoncore.ReadElement(data[start : len(data)-1])
+		if !ok {
+			return nil, false
+		}
+		end := start + len(elem)
+		if elem.Key() != key {
+			start = end
+			continue
+		}
+
+		patched := make([]byte, 0, len(data)-(end-start)+len(value)+len(key)+2)
+		patched = append(patched, data[:start]...)
+		patched = bsoncore.AppendHeader(patched, t, key)
+		patched = append(patched, value...)
+		patched = append(patched, data[end:]...)
+		binary.LittleEndian.PutUint32(patched, uint32(len(patched)))
+		return patched, true
+	}
+	return nil, false
+}
+
+// reuseEncoding keeps data, the encoding that sized doc with the padding
+// field still empty, with padding patched in, so that marshalling doc
+// returns it instead of encoding doc again
+func reuseEncoding(doc interface{}, data []byte, padding Padding) {
+	if sd, ok := doc.(shapedDocument); ok {
+		sd.shape().encoded = data
+		t, value, _ := padding.MarshalBSONValue()
+		sd.shape().patch("padding", t, value)
+	}
+}
+
+// patchString sets the string field key of the kept encoding to value
+func (s *docShape) patchString(key, value string) {
+	s.patch(key, bsontype.String, bsoncore.AppendString(nil, value))
+}
+
+// patchMetadata sets metadata.key of the kept encoding to value, adding the
+// field if it is new, or drops the encoding if its metadata is not a
+// subdocument
+func (s *docShape) patchMetadata(key string, value interface{}) {
+	if s.encoded == nil {
+		return
+	}
+	metadata, ok := bson.Raw(s.encoded).Lookup("metadata").DocumentOK()
+	if !ok {
+		s.encoded = nil
+		return
+	}
+	t, data, err := bson.MarshalValue(value)
+	if err != nil {
+		s.encoded = nil
+		return
+	}
+	patched, ok := patchElement(metadata, key, t, data)
+	if !ok {
+		patched = make([]byte, 0, len(metadata)+len(key)+len(data)+2)
+		patched = append(patched, metadata[:len(metadata)-1]...)
+		patched = bsoncore.AppendHeader(patched, t, key)
+		patched = append(patched, data...)
+		patched = append(patched, 0)
+		binary.LittleEndian.PutUint32(patched, uint32(len(patched)))
+	}
+	s.patch("metadata", bsontype.EmbeddedDocument, patched)
+}
+
+// patch sets the field key of the kept encoding to a value of type t encoded
+// as value, or drops the encoding if it has no such field
+func (s *docShape) patch(key string, t bsontype.Type, value []byte) {
+	if s.encoded == nil {
+		return
+	}
+	patched, ok := patchElement(s.encoded, key, t, value)
+	if !ok {
+		patched = nil
+	}
+	s.encoded = patched
+}
+
+// checkSize counts a document of Options.ExactSize whose encoding without
+// padding, size bytes, already exceeds the target, or fails it with
+// Options.StrictSize
+func (g *Generator) checkSize(size int) error {
+	if !g.options.ExactSize || size+g.overhead <= int(g.targetSize)+SizeTolerance {
+		return nil
+	}
+	if g.options.StrictSize {
+		return fmt.Errorf("document content of %d bytes exceeds the target size of %d bytes", size+g.overhead, g.targetSize)
+	}
+	atomic.AddInt64(&g.oversized, 1)
+	return nil
+}
+
+// Oversized returns the number of documents of Options.ExactSize whose
+// content alone exceeded the target size
+func (g *Generator) Oversized() int64 {
+	return atomic.LoadInt64(&g.oversized)
+}
+
+// Calibrate measures how many bytes process, such as post-processors that
+// set metadata, adds to documents after generation, and pads documents that
+// much less. It repeats on fresh samples until they land within
+// SizeTolerance of the target size. Samples come from a copy of the
+// generator with its own key space, so they use up no keys of g. Calibrate
+// must be called before g generates documents.
+func (g *Generator) Calibrate(process func(Document) error) error {
+	options := g.options
+	options.KeySpace = KeySpace{}
+	options.StrictSize = false
+	sample := NewGeneratorWithOptions(g.targetSize, options)
+
+	for round := 0; round < calibrationRounds; round++ {
+		sample.overhead = g.overhead
+		var total, measured int
+		exact := true
+		for i := 0; i < calibrationSamples; i++ {
+			oversized := sample.Oversized()
+			doc, err := sample.GenerateDocument()
+			if err != nil {
+				return err
+			}
+			if err := process(doc); err != nil {
+				return err
+			}
+			data, err := bson.Marshal(doc)
+			if err != nil {
+				return fmt.Errorf("failed to marshal document: %w", err)
+			}
+			if sample.Oversized() > oversized {
+				continue // Padding cannot shrink it
+			}
+			deviation := len(data) - int(g.targetSize)
+			if deviation < -SizeTolerance || deviation > SizeTolerance {
+				exact = false
+			}
+			total += deviation
+			measured++
+		}
+		if exact || measured == 0 {
+			return nil
+		}
+		g.overhead += total / measured
+	}
+	return nil
+}
+
+// Resize replaces the padding of doc so that it encodes to size bytes, e.g.
+// to write documents at or over MaxDocumentSize. The new padding keeps the
+// field's type: random bytes as binary data, base64 characters as a string.
+// Customers get a new checksum if they have one.
+func Resize(doc Document, size int) error {
+	field := reflect.ValueOf(doc).Elem().FieldByName("Padding")
+	if !field.IsValid() {
+		return fmt.Errorf("%T has no padding", doc)
+	}
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal document: %w", err)
+	}
+	padding := field.Interface().(Padding)
+	length := len(padding.Data) + size - len(data)
+	if length < 0 {
+		return fmt.Errorf("document of %d bytes is larger than %d bytes without padding", len(data), size)
+	}
+
+	seed := uint64(time.Now().UnixNano())
+	if padding.Binary {
+		padding.Data = randomPadding(seed, length)
+	} else {
+		padding.Data = base64Padding(seed, length)
+	}
+	field.Set(reflect.ValueOf(padding))
+	if sd, ok := doc.(shapedDocument); ok {
+		sd.shape().encoded = nil
+	}
+
+	if customer, ok := doc.(*CustomerDocument); ok && customer.Checksum != "" {
+		if customer.Checksum, err = DocumentChecksum(customer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package model
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+func TestPatchElement(t *testing.T) {
+	data, err := bson.Marshal(bson.D{{Key: "a", Value: 1}, {Key: "padding", Value: ""}, {Key: "b", Value: "x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	patched, ok := patchElement(data, "padding", bsontype.String, bsoncore.AppendString(nil, "0123456789"))
+	if !ok {
+		t.Fatal("padding field not found")
+	}
+	if err := bsoncore.Document(patched).Validate(); err != nil {
+		t.Fatalf("Patched document is invalid: %v", err)
+	}
+	want, _ := bson.Marshal(bson.D{{Key: "a", Value: 1}, {Key: "padding", Value: "0123456789"}, {Key: "b", Value: "x"}})
+	if !bytes.Equal(patched, want) {
+		t.Errorf("Patched %v, want %v", bson.Raw(patched), bson.Raw(want))
+	}
+
+	binary, ok := patchElement(data, "padding", bsontype.Binary, bsoncore.AppendBinary(nil, bsontype.BinaryGeneric, []byte{1, 2, 3}))
+	if !ok {
+		t.Fatal("padding field not found")
+	}
+	if value := bson.Raw(binary).Lookup("padding"); value.Type != bsontype.Binary {
+		t.Errorf("Patched padding has type %s, want binary", value.Type)
+	}
+
+	if _, ok := patchElement(data, "missing", bsontype.String, bsoncore.AppendString(nil, "x")); ok {
+		t.Error("Expected no missing field")
+	}
+}
+
+func TestPaddingSizingPatch(t *testing.T) {
+	sp, err := ParseSparsity("phone=0.5,notes=0.5:null")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, options := range []Options{
+		{Checksum: true},
+		{Sparsity: sp, LegacyFraction: 0.5},
+		{Template: TemplateProduct},
+		{Template: TemplateTelemetry},
+		{Template: TemplateTransaction},
+		{Template: TemplateMessages},
+		{Template: TemplateEvents},
+		{PaddingMode: PaddingBinary, Checksum: true},
+	} {
+		options.PaddingSizing = PaddingSizingPatch
+		g := NewGeneratorWithOptions(Size8KB, options)
+		for i := 0; i < 10; i++ {
+			doc, err := g.GenerateDocument()
+			if err != nil {
+				t.Fatalf("Failed to generate document: %v", err)
+			}
+			shape := doc.(shapedDocument).shape()
+			if shape.encoded == nil {
+				t.Fatalf("%s document encoding was not kept", options.Template)
+			}
+			patched, err := bson.Marshal(doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			shape.encoded = nil
+			marshalled, err := bson.Marshal(doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Metadata maps encode in random order, so compare decoded
+			var got, want bson.M
+			if err := bson.Unmarshal(patched, &got); err != nil {
+				t.Fatalf("Patched encoding is invalid: %v", err)
+			}
+			if err := bson.Unmarshal(marshalled, &want); err != nil {
+				t.Fatal(err)
+			}
+			if len(patched) != len(marshalled) || !reflect.DeepEqual(got, want) {
+				t.Fatalf("%s patched encoding differs from marshalling the document", options.Template)
+			}
+		}
+	}
+
+	doc, err := NewGeneratorWithOptions(Size4KB, Options{PaddingSizing: PaddingSizingPatch}).GenerateDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.SetMetadata("tenant_id", "t1")
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tenant, _ := bson.Raw(data).LookupErr("metadata", "tenant_id"); tenant.StringValue() != "t1" {
+		t.Errorf("Metadata set after generation is missing from the encoding")
+	}
+}
+
+func TestExactSize(t *testing.T) {
+	tenant := func(doc Document) error {
+		doc.SetMetadata("tenant_id", "tenant-0042")
+		return nil
+	}
+	for _, template := range []string{TemplateProduct, TemplateTelemetry, TemplateTransaction, TemplateMessages, TemplateEvents} {
+		g := NewGeneratorWithOptions(Size8KB, Options{Template: template, ExactSize: true})
+		if err := g.Calibrate(tenant); err != nil {
+			t.Fatalf("%s: calibration failed: %v", template, err)
+		}
+		for i := 0; i < 20; i++ {
+			doc, err := g.GenerateDocument()
+			if err != nil {
+				t.Fatalf("%s: failed to generate document: %v", template, err)
+			}
+			tenant(doc)
+			data, err := bson.Marshal(doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deviation := len(data) - int(Size8KB); deviation < -SizeTolerance || deviation > SizeTolerance {
+				t.Fatalf("%s: document of %d bytes, want %d±%d", template, len(data), Size8KB, SizeTolerance)
+			}
+		}
+	}
+
+	// Customers of 64KB have more content than fits
+	g := NewGeneratorWithOptions(Size64KB, Options{ExactSize: true})
+	if _, err := g.GenerateDocument(); err != nil {
+		t.Fatal(err)
+	}
+	if g.Oversized() != 1 {
+		t.Errorf("Oversized documents: %d, want 1", g.Oversized())
+	}
+	g = NewGeneratorWithOptions(Size64KB, Options{ExactSize: true, StrictSize: true})
+	if _, err := g.GenerateDocument(); err == nil {
+		t.Error("Expected an error for an oversized document with StrictSize")
+	}
+}
+
+func TestResize(t *testing.T) {
+	for _, options := range []Options{{Checksum: true}, {Template: TemplateEvents, PaddingMode: PaddingBinary}} {
+		doc, err := NewGeneratorWithOptions(Size4KB, options).GenerateDocument()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Resize(doc, MaxDocumentSize+1024); err != nil {
+			t.Fatalf("Resize failed: %v", err)
+		}
+		data, err := bson.Marshal(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != MaxDocumentSize+1024 {
+			t.Errorf("%s document resized to %d bytes, want %d", options.Template, len(data), MaxDocumentSize+1024)
+		}
+		if customer, ok := doc.(*CustomerDocument); ok {
+			if sum, _ := DocumentChecksum(customer); sum != customer.Checksum {
+				t.Error("Resized customer has a stale checksum")
+			}
+		}
+		if err := Resize(doc, 100); err == nil {
+			t.Error("Expected an error resizing below the document's content")
+		}
+	}
+}
+
+func TestPaddingSizingPatchMetadata(t *testing.T) {
+	for _, template := range []string{TemplateCustomer, TemplateProduct, TemplateTelemetry, TemplateTransaction, TemplateMessages, TemplateEvents} {
+		g := NewGeneratorWithOptions(Size8KB, Options{Template: template, PaddingSizing: PaddingSizingPatch})
+		doc, err := g.GenerateDocument()
+		if err != nil {
+			t.Fatalf("Failed to generate document: %v", err)
+		}
+
+		// --tag-run and --tenants stamps keep the encoding
+		doc.SetMetadata("run_id", "20250101-120000")
+		doc.SetMetadata("generated_at", time.Date(2025, 1, 1, 12, 0, 3, 0, time.UTC))
+		doc.SetMetadata("tenant_id", "acme")
+		doc.SetMetadata("created_by", "gendata")
+		shape := doc.(shapedDocument).shape()
+		if shape.encoded == nil {
+			t.Fatalf("%s document encoding was dropped by metadata stamps", template)
+		}
+		patched, err := bson.Marshal(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shape.encoded = nil
+		marshalled, err := bson.Marshal(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got, want bson.M
+		if err := bson.Unmarshal(patched, &got); err != nil {
+			t.Fatalf("Patched encoding is invalid: %v", err)
+		}
+		if err := bson.Unmarshal(marshalled, &want); err != nil {
+			t.Fatal(err)
+		}
+		if len(patched) != len(marshalled) || !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s patched metadata differs from marshalling the document", template)
+		}
+	}
+}
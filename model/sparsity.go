@@ -0,0 +1,153 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Sparsity maps field paths (dotted, through arrays) to how often the field
+// is null or missing from generated documents
+type Sparsity map[string]SparseField
+
+// SparseField is the probability (0-1) of a field being missing, or null
+// when Null is set
+type SparseField struct {
+	Probability float64
+	Null        bool
+}
+
+// ParseSparsity parses "field=P,field=P:null,..." where P is a probability
+// and the field is missing unless :null is given ("" = none)
+func ParseSparsity(s string) (Sparsity, error) {
+	sp := make(Sparsity)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field, value, ok := strings.Cut(part, "=")
+		field = strings.TrimSpace(field)
+		if !ok || field == "" {
+			return nil, fmt.Errorf("invalid sparsity %q: want field=P or field=P:null", part)
+		}
+
+		var f SparseField
+		value, mode, hasMode := strings.Cut(strings.TrimSpace(value), ":")
+		switch {
+		case !hasMode || mode == "missing":
+		case mode == "null":
+			f.Null = true
+		default:
+			return nil, fmt.Errorf("invalid sparsity %q: mode must be missing or null", part)
+		}
+		p, err := strconv.ParseFloat(value, 64)
+		if err != nil || p <= 0 || p > 1 {
+			return nil, fmt.Errorf("invalid sparsity %q: want a probability above 0 and at most 1", part)
+		}
+		f.Probability = p
+		sp[field] = f
+	}
+	return sp, nil
+}
+
+// String formats the sparsity as ParseSparsity accepts it
+func (sp Sparsity) String() string {
+	parts := make([]string, 0, len(sp))
+	for field, f := range sp {
+		part := field + "=" + strconv.FormatFloat(f.Probability, 'g', -1, 64)
+		if f.Null {
+			part += ":null"
+		}
+		parts = append(parts, part)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// ValidateSparsity checks that every field of sp is a field of the
+// template's documents other than _id and the key field
+func ValidateSparsity(template string, sp Sparsity) error {
+	schema := NewGeneratorWithOptions(Size2KB, Options{Template: template}).Schema()
+	docType := reflect.TypeOf(templateDocument(template)).Elem()
+
+	for field := range sp {
+		if field == "_id" || field == schema.KeyField {
+			return fmt.Errorf("%s cannot be sparse", field)
+		}
+		if err := checkFieldPath(docType, strings.Split(field, "."), nil); err != nil {
+			return fmt.Errorf("invalid sparsity field %s: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// sparseField is a field of Options.Sparsity
+type sparseField struct {
+	path []string
+	SparseField
+}
+
+// newSparseFields prepares the fields of sp in a stable order
+func newSparseFields(sp Sparsity) []sparseField {
+	fields := make([]sparseField, 0, len(sp))
+	for field, f := range sp {
+		fields = append(fields, sparseField{path: strings.Split(field, "."), SparseField: f})
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return strings.Join(fields[i].path, ".") < strings.Join(fields[j].path, ".")
+	})
+	return fields
+}
+
+// applySparsity drops or nulls the sparse fields of doc (of each array
+// element, for fields in arrays) with their probability. The fields are
+// zeroed in doc as well, so that it matches what is read back.
+func (g *Generator) applySparsity(doc Document) {
+	sd, ok := doc.(shapedDocument)
+	if !ok || len(g.sparsity) == 0 {
+		return
+	}
+	record := sd.shape()
+	for _, field := range g.sparsity {
+		g.sparsifyField(reflect.ValueOf(doc), field, field.path, nil, record)
+	}
+}
+
+// sparsifyField walks path from v, tracking the concrete path so far in at
+func (g *Generator) sparsifyField(v reflect.Value, field sparseField, path, at []string, record *docShape) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			g.sparsifyField(v.Elem(), field, path, at, record)
+		}
+		return
+	case reflect.Slice:
+		if len(path) > 0 {
+			for i := 0; i < v.Len(); i++ {
+				g.sparsifyField(v.Index(i), field, path, append(at[:len(at):len(at)], strconv.Itoa(i)), record)
+			}
+			return
+		}
+	}
+
+	if len(path) == 0 {
+		if g.faker.Float64() >= field.Probability {
+			return
+		}
+		v.Set(reflect.Zero(v.Type()))
+		if field.Null {
+			record.null = append(record.null, at)
+		} else {
+			record.missing = append(record.missing, at)
+		}
+		return
+	}
+	if v.Kind() == reflect.Struct {
+		if i, ok := fieldIndex(v.Type(), path[0]); ok {
+			g.sparsifyField(v.Field(i), field, path[1:], append(at[:len(at):len(at)], path[0]), record)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package model
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParseSparsity(t *testing.T) {
+	sp, err := ParseSparsity("phone=0.3, orders.notes=0.5:null,addresses=1:missing")
+	if err != nil {
+		t.Fatalf("Failed to parse sparsity: %v", err)
+	}
+	if sp["phone"] != (SparseField{Probability: 0.3}) || sp["orders.notes"] != (SparseField{Probability: 0.5, Null: true}) || !sp["addresses"].Null == false {
+		t.Errorf("Unexpected sparsity: %v", sp)
+	}
+	if got := sp.String(); got != "addresses=1,orders.notes=0.5:null,phone=0.3" {
+		t.Errorf("String() = %s", got)
+	}
+
+	for _, invalid := range []string{"phone", "phone=0", "phone=1.5", "phone=0.3:gone", "=0.3"} {
+		if _, err := ParseSparsity(invalid); err == nil {
+			t.Errorf("Expected error for sparsity %q", invalid)
+		}
+	}
+}
+
+func TestValidateSparsity(t *testing.T) {
+	valid, _ := ParseSparsity("phone=0.3,addresses=0.1,orders.shipped_date=0.5:null,date_of_birth=0.2")
+	if err := ValidateSparsity(TemplateCustomer, valid); err != nil {
+		t.Errorf("ValidateSparsity: %v", err)
+	}
+	for _, spec := range []string{"_id=0.5", "customer_id=0.5", "nope=0.5", "phone.number=0.5"} {
+		sp, _ := ParseSparsity(spec)
+		if err := ValidateSparsity(TemplateCustomer, sp); err == nil {
+			t.Errorf("Expected error for customer sparsity %s", spec)
+		}
+	}
+}
+
+func TestSparseFieldsMissingAndNull(t *testing.T) {
+	sp, _ := ParseSparsity("phone=1,orders.notes=1:null,tags=1:null")
+	gen := NewGeneratorWithOptions(Size8KB, Options{Sparsity: sp, Orders: CountRange{Min: 3, Max: 3}})
+	doc, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Failed to generate document: %v", err)
+	}
+
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Failed to marshal document: %v", err)
+	}
+	raw := bson.Raw(data)
+	if _, err := raw.LookupErr("phone"); err == nil {
+		t.Error("Expected phone to be missing")
+	}
+	if v, err := raw.LookupErr("tags"); err != nil || v.Type != bson.TypeNull {
+		t.Errorf("Expected tags to be null, got %v", v)
+	}
+	for _, i := range []string{"0", "1", "2"} {
+		if v, err := raw.LookupErr("orders", i, "notes"); err != nil || v.Type != bson.TypeNull {
+			t.Errorf("Expected orders.%s.notes to be null, got %v", i, v)
+		}
+		if _, err := raw.LookupErr("orders", i, "status"); err != nil {
+			t.Errorf("Expected orders.%s.status to be kept", i)
+		}
+	}
+}
+
+func TestSparsityProbability(t *testing.T) {
+	sp, _ := ParseSparsity("phone=0.3")
+	gen := NewGeneratorWithOptions(Size2KB, Options{Sparsity: sp})
+
+	missing := 0
+	for i := 0; i < 2000; i++ {
+		doc, err := gen.Generate()
+		if err != nil {
+			t.Fatalf("Failed to generate document: %v", err)
+		}
+		data, err := bson.Marshal(doc)
+		if err != nil {
+			t.Fatalf("Failed to marshal document: %v", err)
+		}
+		if _, err := bson.Raw(data).LookupErr("phone"); err != nil {
+			missing++
+		}
+	}
+	if missing < 500 || missing > 700 {
+		t.Errorf("%d of 2000 documents have no phone, want about 600", missing)
+	}
+}
+
+func TestSparseChecksumRoundTrip(t *testing.T) {
+	sp, _ := ParseSparsity("phone=1,addresses=1:null,orders.line_items.description=0.5")
+	gen := NewGeneratorWithOptions(Size8KB, Options{Checksum: true, Sparsity: sp})
+	doc, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Failed to generate document: %v", err)
+	}
+
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Failed to marshal document: %v", err)
+	}
+	var decoded CustomerDocument
+	if err := bson.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal document: %v", err)
+	}
+	checksum, err := DocumentChecksum(&decoded)
+	if err != nil {
+		t.Fatalf("Failed to checksum document: %v", err)
+	}
+	if checksum != doc.Checksum {
+		t.Errorf("Checksum changed after a round trip: %s, want %s", checksum, doc.Checksum)
+	}
+}
+
+func TestMarshalWithoutSparsity(t *testing.T) {
+	gen := NewGeneratorWithOptions(Size4KB, Options{Template: TemplateProduct})
+	doc, err := gen.GenerateDocument()
+	if err != nil {
+		t.Fatalf("Failed to generate document: %v", err)
+	}
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Failed to marshal document: %v", err)
+	}
+	elements, err := bson.Raw(data).Elements()
+	if err != nil {
+		t.Fatalf("Invalid document: %v", err)
+	}
+	if len(elements) != len(gen.Schema().Fields) {
+		t.Errorf("Document has %d fields, want the %d schema fields", len(elements), len(gen.Schema().Fields))
+	}
+}
@@ -0,0 +1,246 @@
+package model
+
+import (
+	"hash/fnv"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// liveReadingInterval is the reading interval of telemetry stamped with the
+// wall clock (workload inserts)
+const liveReadingInterval = 10 * time.Second
+
+// TelemetryDocument is a bucket of consecutive sensor readings reported by
+// one device, following the bucket pattern for time series
+type TelemetryDocument struct {
+	ID           primitive.ObjectID     `bson:"_id"`
+	BucketID     string                 `bson:"bucket_id"`
+	DeviceID     string                 `bson:"device_id"`
+	DeviceType   string                 `bson:"device_type"`
+	Firmware     string                 `bson:"firmware"`
+	Location     DeviceLocation         `bson:"location"`
+	StartTime    time.Time              `bson:"start_time"`
+	EndTime      time.Time              `bson:"end_time"`
+	ReadingCount int                    `bson:"reading_count"`
+	Readings     []Reading              `bson:"readings"`
+	Summary      map[string]SensorStats `bson:"summary"` // Per sensor over the bucket
+	Status       string                 `bson:"status"`  // ok, warning, fault
+	CreatedAt    time.Time              `bson:"created_at"`
+	UpdatedAt    time.Time              `bson:"updated_at"`
+
+	Metadata map[string]interface{} `bson:"metadata"`
+	Tags     []string               `bson:"tags"`
+
+	// Padding field to control document size
+	Padding Padding `bson:"padding"`
+
+	// Sample of every BSON type (Options.BSONTypes)
+	BSONTypes *BSONTypes `bson:"bson_types,omitempty"`
+
+	// Chain of subdocuments Options.NestingDepth levels deep
+	Nested bson.D `bson:"nested,omitempty"`
+
+	docShape // Sparse fields and legacy schema (Options.Sparsity, Options.LegacyFraction)
+}
+
+// DeviceLocation is where a device is installed
+type DeviceLocation struct {
+	Site      string  `bson:"site"`
+	Latitude  float64 `bson:"lat"`
+	Longitude float64 `bson:"lon"`
+}
+
+// Reading is one timestamped sample of all of a device's sensors
+type Reading struct {
+	Timestamp time.Time     `bson:"ts"`
+	Sensors   []SensorValue `bson:"sensors"`
+}
+
+// SensorValue is the value of one sensor in a reading
+type SensorValue struct {
+	Sensor string  `bson:"sensor"`
+	Value  float64 `bson:"value"`
+	Unit   string  `bson:"unit"`
+}
+
+// SensorStats summarizes one sensor over a bucket
+type SensorStats struct {
+	Min float64 `bson:"min"`
+	Max float64 `bson:"max"`
+	Avg float64 `bson:"avg"`
+}
+
+// sensorSpec describes a sensor's unit and the range its values drift in
+type sensorSpec struct {
+	name     string
+	unit     string
+	min, max float64
+}
+
+// deviceTypes maps each device type to the sensors it reports
+var deviceTypes = []struct {
+	name    string
+	sensors []sensorSpec
+}{
+	{"thermostat", []sensorSpec{{"temperature", "C", 15, 28}, {"humidity", "%", 20, 70}, {"battery", "%", 5, 100}}},
+	{"weather_station", []sensorSpec{{"temperature", "C", -20, 40}, {"humidity", "%", 10, 100}, {"pressure", "hPa", 960, 1050}, {"wind_speed", "m/s", 0, 30}}},
+	{"air_quality", []sensorSpec{{"co2", "ppm", 400, 2000}, {"pm25", "ug/m3", 0, 150}, {"temperature", "C", 15, 30}, {"battery", "%", 5, 100}}},
+	{"industrial", []sensorSpec{{"vibration", "mm/s", 0, 25}, {"temperature", "C", 20, 90}, {"pressure", "bar", 1, 10}, {"rpm", "rpm", 0, 3600}}},
+}
+
+// DocumentID returns the document's _id
+func (d *TelemetryDocument) DocumentID() primitive.ObjectID { return d.ID }
+
+// SetDocumentID replaces the document's _id
+func (d *TelemetryDocument) SetDocumentID(id primitive.ObjectID) {
+	d.ID = id
+	d.encoded = nil
+}
+
+// SetMetadata sets metadata.key
+func (d *TelemetryDocument) SetMetadata(key string, value interface{}) {
+	if d.Metadata == nil {
+		d.Metadata = make(map[string]interface{})
+	}
+	d.Metadata[key] = value
+	d.patchMetadata(key, value)
+}
+
+// MarshalBSON encodes the document in its shape, see docShape
+func (d *TelemetryDocument) MarshalBSON() ([]byte, error) {
+	type plain TelemetryDocument
+	return d.docShape.marshal((*plain)(d))
+}
+
+func (d *TelemetryDocument) shape() *docShape { return &d.docShape }
+
+// GenerateTelemetry creates a new telemetry bucket with the target size.
+// Buckets rotate through the device fleet, and each device's buckets follow
+// each other without gaps, so readings advance through the time range in
+// generation order.
+func (g *Generator) GenerateTelemetry() (*TelemetryDocument, error) {
+	seq, bucketID := g.keys.issue()
+	deviceIndex := int(seq % DefaultDeviceCount)
+	deviceID := g.keys.space.DeviceKey(deviceIndex)
+	deviceType := deviceTypes[deviceIndex%len(deviceTypes)]
+
+	numReadings := g.readingCount(len(deviceType.sensors))
+
+	// Each bucket covers the time until the device's next bucket starts
+	var start time.Time
+	var interval time.Duration
+	if g.timeline.live() {
+		interval = liveReadingInterval
+		start = time.Now().Add(-interval * time.Duration(numReadings))
+	} else {
+		start = g.timeline.at(seq)
+		interval = g.timeline.after(seq, DefaultDeviceCount) / time.Duration(numReadings)
+	}
+
+	doc := &TelemetryDocument{
+		ID:           primitive.NewObjectID(),
+		BucketID:     bucketID,
+		DeviceID:     deviceID,
+		DeviceType:   deviceType.name,
+		Firmware:     g.faker.AppVersion(),
+		Location:     deviceLocation(deviceID),
+		ReadingCount: numReadings,
+		Readings:     make([]Reading, numReadings),
+		Summary:      make(map[string]SensorStats, len(deviceType.sensors)),
+		Status:       g.deviceStatus(),
+		CreatedAt:    g.stamp(start.Add(interval * time.Duration(numReadings))),
+		UpdatedAt:    g.stamp(start.Add(interval * time.Duration(numReadings))),
+		Metadata:     map[string]interface{}{"created_by": "system", "protocol": g.faker.RandomString([]string{"mqtt", "coap", "http"})},
+		Tags:         []string{deviceType.name, g.faker.Word()},
+	}
+
+	// Sensor values drift in a random walk from a random starting point
+	values := make([]float64, len(deviceType.sensors))
+	for i, sensor := range deviceType.sensors {
+		values[i] = g.faker.Float64Range(sensor.min, sensor.max)
+		doc.Summary[sensor.name] = SensorStats{Min: math.Inf(1), Max: math.Inf(-1)}
+	}
+	for r := range doc.Readings {
+		reading := Reading{
+			Timestamp: start.Add(interval * time.Duration(r)).Truncate(time.Millisecond),
+			Sensors:   make([]SensorValue, len(deviceType.sensors)),
+		}
+		for i, sensor := range deviceType.sensors {
+			drift := (sensor.max - sensor.min) * 0.02
+			values[i] = math.Max(sensor.min, math.Min(sensor.max, values[i]+g.faker.Float64Range(-drift, drift)))
+			value := math.Round(values[i]*100) / 100
+			reading.Sensors[i] = SensorValue{Sensor: sensor.name, Value: value, Unit: sensor.unit}
+
+			stats := doc.Summary[sensor.name]
+			stats.Min = math.Min(stats.Min, value)
+			stats.Max = math.Max(stats.Max, value)
+			stats.Avg += value / float64(numReadings)
+			doc.Summary[sensor.name] = stats
+		}
+		doc.Readings[r] = reading
+	}
+	doc.StartTime = doc.Readings[0].Timestamp
+	doc.EndTime = doc.Readings[numReadings-1].Timestamp
+
+	g.applyCardinality(doc)
+	if err := g.applyFieldValues(doc); err != nil {
+		return nil, err
+	}
+	if err := g.applyFieldTemplates(doc); err != nil {
+		return nil, err
+	}
+	g.applySparsity(doc)
+	g.applyLegacy(doc)
+
+	bsonTypes, err := g.bsonTypes()
+	if err != nil {
+		return nil, err
+	}
+	doc.BSONTypes = bsonTypes
+	doc.Nested = g.nestedChain()
+
+	padding, err := g.calculatePadding(doc)
+	if err != nil {
+		return nil, err
+	}
+	doc.Padding = padding
+
+	return doc, nil
+}
+
+// readingCount determines how many readings fill most of a telemetry bucket
+// for a device with numSensors sensors (each sensor adds ~50 bytes to a reading)
+func (g *Generator) readingCount(numSensors int) int {
+	readingBytes := 40 + 50*numSensors
+	count := (int(g.targetSize)*85/100 - 900) / readingBytes
+	if count < 1 {
+		count = 1
+	}
+	return count
+}
+
+// deviceStatus picks a bucket status, mostly ok
+func (g *Generator) deviceStatus() string {
+	switch n := g.faker.IntRange(1, 100); {
+	case n <= 2:
+		return "fault"
+	case n <= 10:
+		return "warning"
+	}
+	return "ok"
+}
+
+// deviceLocation derives a stable location from the device ID
+func deviceLocation(deviceID string) DeviceLocation {
+	h := fnv.New64a()
+	h.Write([]byte(deviceID))
+	v := h.Sum64()
+	return DeviceLocation{
+		Site:      "site-" + deviceID[:4],
+		Latitude:  float64(v%140000)/1000 - 60,
+		Longitude: float64((v>>20)%360000)/1000 - 180,
+	}
+}
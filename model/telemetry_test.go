@@ -0,0 +1,96 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestTelemetryDocumentSizes(t *testing.T) {
+	sizes := []DocumentSize{Size2KB, Size4KB, Size8KB, Size16KB, Size32KB, Size64KB}
+
+	for _, size := range sizes {
+		gen := NewGeneratorWithOptions(size, Options{Template: TemplateTelemetry})
+
+		// Consecutive buckets come from different device types
+		for i := 0; i < len(deviceTypes); i++ {
+			doc, err := gen.GenerateDocument()
+			if err != nil {
+				t.Fatalf("Failed to generate telemetry: %v", err)
+			}
+			bucket, ok := doc.(*TelemetryDocument)
+			if !ok {
+				t.Fatalf("Expected a telemetry document, got %T", doc)
+			}
+			if bucket.DeviceID == "" || len(bucket.Readings) != bucket.ReadingCount {
+				t.Errorf("%dKB bucket has device %q and %d readings, want %d", size/1024, bucket.DeviceID, len(bucket.Readings), bucket.ReadingCount)
+			}
+
+			data, err := bson.Marshal(bucket)
+			if err != nil {
+				t.Fatalf("Failed to marshal telemetry: %v", err)
+			}
+			t.Logf("%dKB %s: %d bytes, %d padding, %d readings", size/1024, bucket.DeviceType, len(data), len(bucket.Padding.Data), len(bucket.Readings))
+			if len(data) < int(size)*9/10 || len(data) > int(size)*11/10 {
+				t.Errorf("%dKB %s bucket is %d bytes, want within 10%%", size/1024, bucket.DeviceType, len(data))
+			}
+		}
+	}
+}
+
+func TestTelemetrySchemaMatchesDocument(t *testing.T) {
+	gen := NewGeneratorWithOptions(Size8KB, Options{Template: TemplateTelemetry})
+	if gen.Schema().Template != TemplateTelemetry {
+		t.Fatalf("Expected telemetry schema, got %s", gen.Schema().Template)
+	}
+
+	doc, err := gen.GenerateTelemetry()
+	if err != nil {
+		t.Fatalf("Failed to generate telemetry: %v", err)
+	}
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Failed to marshal telemetry: %v", err)
+	}
+	raw := bson.Raw(data)
+	for _, field := range gen.Schema().Fields {
+		if _, err := raw.LookupErr(field); err != nil {
+			t.Errorf("Schema field %s missing from telemetry document", field)
+		}
+	}
+}
+
+func TestTelemetryTimestampsAdvance(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	gen := NewGeneratorWithOptions(Size4KB, Options{
+		Template:          TemplateTelemetry,
+		TimeRange:         TimeRange{Start: start, End: end},
+		ExpectedDocuments: 10 * DefaultDeviceCount,
+	})
+
+	// A device's next bucket starts after its previous bucket ends
+	lastEnd := make(map[string]time.Time)
+	for i := 0; i < 3*DefaultDeviceCount; i++ {
+		doc, err := gen.GenerateTelemetry()
+		if err != nil {
+			t.Fatalf("Failed to generate telemetry: %v", err)
+		}
+		if doc.StartTime.Before(start) || doc.EndTime.After(end) {
+			t.Fatalf("Bucket %s..%s outside %s", doc.StartTime, doc.EndTime, TimeRange{start, end})
+		}
+		for r := 1; r < len(doc.Readings); r++ {
+			if !doc.Readings[r].Timestamp.After(doc.Readings[r-1].Timestamp) {
+				t.Fatalf("Reading %d of %s does not advance", r, doc.BucketID)
+			}
+		}
+		if prev, ok := lastEnd[doc.DeviceID]; ok && !doc.StartTime.After(prev) {
+			t.Fatalf("Bucket of %s starts at %s, before previous end %s", doc.DeviceID, doc.StartTime, prev)
+		}
+		lastEnd[doc.DeviceID] = doc.EndTime
+	}
+	if len(lastEnd) != DefaultDeviceCount {
+		t.Errorf("Buckets cover %d devices, want %d", len(lastEnd), DefaultDeviceCount)
+	}
+}
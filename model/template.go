@@ -0,0 +1,88 @@
+// Package model generates realistic MongoDB documents: customers with
+// orders, products, telemetry buckets, ledger transactions, conversations,
+// and events, of a target size and shape. A Generator produces one Document
+// at a time; its Schema describes the fields later phases can query.
+package model
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Built-in document templates
+const (
+	TemplateCustomer    = "customer"
+	TemplateProduct     = "product"
+	TemplateTelemetry   = "telemetry"
+	TemplateTransaction = "transactions"
+	TemplateMessages    = "messages"
+	TemplateEvents      = "events"
+)
+
+// ParseTemplate validates a template name ("" = customer)
+func ParseTemplate(name string) (string, error) {
+	switch name {
+	case "", TemplateCustomer:
+		return TemplateCustomer, nil
+	case TemplateProduct, TemplateTelemetry, TemplateTransaction, TemplateMessages, TemplateEvents:
+		return name, nil
+	}
+	return "", fmt.Errorf("unknown template: %s (customer, product, telemetry, transactions, messages, or events)", name)
+}
+
+// Document is a generated document of any template, as handed from the
+// generator to post-processors and writers
+type Document interface {
+	// DocumentID returns the document's _id
+	DocumentID() primitive.ObjectID
+
+	// SetDocumentID replaces the _id, e.g. to collide with an existing document
+	SetDocumentID(id primitive.ObjectID)
+
+	// SetMetadata sets a field of the document's metadata subdocument
+	SetMetadata(key string, value interface{})
+}
+
+// DocumentID returns the document's _id
+func (d *CustomerDocument) DocumentID() primitive.ObjectID { return d.ID }
+
+// SetDocumentID replaces the document's _id
+func (d *CustomerDocument) SetDocumentID(id primitive.ObjectID) {
+	d.ID = id
+	d.encoded = nil
+}
+
+// SetMetadata sets metadata.key
+func (d *CustomerDocument) SetMetadata(key string, value interface{}) {
+	if d.Metadata == nil {
+		d.Metadata = make(map[string]interface{})
+	}
+	d.Metadata[key] = value
+	d.patchMetadata(key, value)
+}
+
+// MarshalBSON encodes the document in its shape, see docShape
+func (d *CustomerDocument) MarshalBSON() ([]byte, error) {
+	type plain CustomerDocument
+	return d.docShape.marshal((*plain)(d))
+}
+
+func (d *CustomerDocument) shape() *docShape { return &d.docShape }
+
+// GenerateDocument creates a document of the generator's template
+func (g *Generator) GenerateDocument() (Document, error) {
+	switch g.options.Template {
+	case TemplateProduct:
+		return g.GenerateProduct()
+	case TemplateTelemetry:
+		return g.GenerateTelemetry()
+	case TemplateTransaction:
+		return g.GenerateTransaction()
+	case TemplateMessages:
+		return g.GenerateConversation()
+	case TemplateEvents:
+		return g.GenerateEvent()
+	}
+	return g.Generate()
+}
@@ -0,0 +1,191 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// DefaultTimeSpan is the length of the default time range, which ends when
+// the generator is created
+const DefaultTimeSpan = 30 * 24 * time.Hour
+
+// TimeRange is the period the timestamps of time-series templates advance
+// through over a load, and the window all other timestamps fall in
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// IsZero reports whether no range was set
+func (r TimeRange) IsZero() bool {
+	return r.Start.IsZero() && r.End.IsZero()
+}
+
+// String formats the range as START/END, the format ParseTimeRange accepts
+func (r TimeRange) String() string {
+	return r.Start.UTC().Format(time.RFC3339) + "/" + r.End.UTC().Format(time.RFC3339)
+}
+
+// ParseTimeRange parses "START/END" or "START..END", where each bound is an
+// RFC 3339 timestamp or a date (2006-01-02, UTC). "" is the zero range.
+func ParseTimeRange(s string) (TimeRange, error) {
+	if s == "" {
+		return TimeRange{}, nil
+	}
+	start, end, ok := strings.Cut(s, "..")
+	if !ok {
+		start, end, ok = strings.Cut(s, "/")
+	}
+	if !ok {
+		return TimeRange{}, fmt.Errorf("invalid time range %q: want START/END or START..END", s)
+	}
+
+	var r TimeRange
+	var err error
+	if r.Start, err = parseTimeBound(start); err != nil {
+		return TimeRange{}, err
+	}
+	if r.End, err = parseTimeBound(end); err != nil {
+		return TimeRange{}, err
+	}
+	if !r.End.After(r.Start) {
+		return TimeRange{}, fmt.Errorf("invalid time range %q: end must be after start", s)
+	}
+	return r, nil
+}
+
+// parseTimeBound parses an RFC 3339 timestamp or a date
+func parseTimeBound(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: want RFC 3339 or YYYY-MM-DD", s)
+	}
+	return t, nil
+}
+
+// TimeDistribution selects how timestamps are spread over a time range
+type TimeDistribution string
+
+const (
+	// TimeUniform spreads timestamps evenly over the range
+	TimeUniform TimeDistribution = "uniform"
+	// TimeRecent makes timestamps denser towards the end of the range, in
+	// proportion to the time since its start, like a growing business
+	TimeRecent TimeDistribution = "recent"
+)
+
+// ParseTimeDistribution validates a time distribution name
+func ParseTimeDistribution(name string) (TimeDistribution, error) {
+	switch TimeDistribution(strings.ToLower(name)) {
+	case "", TimeUniform:
+		return TimeUniform, nil
+	case TimeRecent:
+		return TimeRecent, nil
+	default:
+		return "", fmt.Errorf("invalid time distribution: %s (use uniform or recent)", name)
+	}
+}
+
+// position maps f, a uniform fraction (0-1) of a range, to the fraction of
+// the range's duration at which the timestamp falls under d
+func (d TimeDistribution) position(f float64) float64 {
+	if d == TimeRecent {
+		return math.Sqrt(f)
+	}
+	return f
+}
+
+// timeline spreads the timestamps of an expected number of documents over a
+// time range by a TimeDistribution, in generation order, so that timestamps
+// increase monotonically with the document sequence. Without an expected
+// document count (e.g. workload inserts), documents are stamped with the
+// wall clock.
+type timeline struct {
+	start        time.Time
+	span         time.Duration
+	documents    int64
+	step         time.Duration // Average time between consecutive documents
+	distribution TimeDistribution
+}
+
+// newTimeline creates a timeline for documents documents over r (zero = the
+// DefaultTimeSpan up to now)
+func newTimeline(r TimeRange, documents int64, distribution TimeDistribution) timeline {
+	if documents <= 0 {
+		return timeline{}
+	}
+	if r.IsZero() {
+		r.End = time.Now()
+		r.Start = r.End.Add(-DefaultTimeSpan)
+	}
+	span := r.End.Sub(r.Start)
+	step := span / time.Duration(documents)
+	if step <= 0 {
+		step = time.Nanosecond
+	}
+	return timeline{start: r.Start, span: span, documents: documents, step: step, distribution: distribution}
+}
+
+// live reports whether documents are stamped with the wall clock
+func (t timeline) live() bool {
+	return t.step == 0
+}
+
+// at returns the timestamp of the document with sequence number seq (from 0)
+func (t timeline) at(seq int64) time.Time {
+	if t.live() {
+		return time.Now()
+	}
+	if t.distribution != TimeRecent {
+		return t.start.Add(t.step * time.Duration(seq))
+	}
+	f := t.distribution.position(float64(seq) / float64(t.documents))
+	return t.start.Add(time.Duration(f * float64(t.span)))
+}
+
+// after returns the time from the document with sequence number seq to the
+// one n documents later
+func (t timeline) after(seq, n int64) time.Duration {
+	if t.live() {
+		return 0
+	}
+	return t.at(seq + n).Sub(t.at(seq))
+}
+
+// now returns the time generated timestamps lead up to: the end of
+// Options.TimeRange, or the wall clock without one
+func (g *Generator) now() time.Time {
+	if r := g.options.TimeRange; !r.IsZero() {
+		return r.End
+	}
+	return time.Now()
+}
+
+// dateRange returns a random time between from and to by
+// Options.TimeDistribution, where from is moved up to the start of
+// Options.TimeRange so timestamps stay within it
+func (g *Generator) dateRange(from, to time.Time) time.Time {
+	if r := g.options.TimeRange; !r.IsZero() && from.Before(r.Start) {
+		from = r.Start
+	}
+	if !to.After(from) {
+		return from
+	}
+	f := g.options.TimeDistribution.position(g.faker.Float64())
+	return from.Add(time.Duration(f * float64(to.Sub(from))))
+}
+
+// stamp returns the created_at of a time-series document with timestamp ts:
+// the wall clock, or ts when Options.TimeRange is set so that all timestamps
+// fall within it
+func (g *Generator) stamp(ts time.Time) time.Time {
+	if g.options.TimeRange.IsZero() {
+		return time.Now()
+	}
+	return ts
+}
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeRange(t *testing.T) {
+	r, err := ParseTimeRange("2024-01-01/2024-01-31T12:00:00Z")
+	if err != nil {
+		t.Fatalf("ParseTimeRange failed: %v", err)
+	}
+	if want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); !r.Start.Equal(want) {
+		t.Errorf("start = %v, want %v", r.Start, want)
+	}
+	if want := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC); !r.End.Equal(want) {
+		t.Errorf("end = %v, want %v", r.End, want)
+	}
+
+	for _, invalid := range []string{"2024-01-01", "2024-02-01/2024-01-01", "yesterday/today"} {
+		if _, err := ParseTimeRange(invalid); err == nil {
+			t.Errorf("ParseTimeRange(%q) succeeded, want error", invalid)
+		}
+	}
+}
+
+func TestTimelineSpreadsDocumentsOverRange(t *testing.T) {
+	r, _ := ParseTimeRange("2024-01-01/2024-01-02")
+	tl := newTimeline(r, 24, TimeUniform)
+
+	if got := tl.at(0); !got.Equal(r.Start) {
+		t.Errorf("first document at %v, want %v", got, r.Start)
+	}
+	if got := tl.at(12); !got.Equal(r.Start.Add(12 * time.Hour)) {
+		t.Errorf("document 12 at %v, want noon", got)
+	}
+	if got := tl.at(24); !got.Equal(r.End) {
+		t.Errorf("document 24 at %v, want %v", got, r.End)
+	}
+}
+
+func TestParseTimeRangeDots(t *testing.T) {
+	r, err := ParseTimeRange("2015-01-01..2025-01-01")
+	if err != nil {
+		t.Fatalf("ParseTimeRange failed: %v", err)
+	}
+	if r.Start.Year() != 2015 || r.End.Year() != 2025 {
+		t.Errorf("got %v, want 2015 to 2025", r)
+	}
+}
+
+func TestRecentTimelineIsDenserAtTheEnd(t *testing.T) {
+	r, _ := ParseTimeRange("2024-01-01..2024-01-02")
+	tl := newTimeline(r, 100, TimeRecent)
+
+	if got := tl.at(0); !got.Equal(r.Start) {
+		t.Errorf("first document at %v, want %v", got, r.Start)
+	}
+	if got := tl.at(100); !got.Equal(r.End) {
+		t.Errorf("document 100 at %v, want %v", got, r.End)
+	}
+	if got := tl.at(25); !got.Equal(r.Start.Add(12 * time.Hour)) {
+		t.Errorf("document 25 at %v, want noon", got)
+	}
+	for seq := int64(1); seq < 100; seq++ {
+		if tl.after(seq, 1) > tl.after(seq-1, 1) {
+			t.Fatalf("documents %d and %d are further apart than the ones before", seq, seq+1)
+		}
+	}
+}
+
+func TestTimeRangeWindow(t *testing.T) {
+	r, _ := ParseTimeRange("2015-01-01..2016-01-01")
+	g := NewGeneratorWithOptions(Size4KB, Options{TimeRange: r, TimeDistribution: TimeRecent})
+
+	var recent int
+	for i := 0; i < 200; i++ {
+		doc, err := g.Generate()
+		if err != nil {
+			t.Fatalf("Failed to generate document: %v", err)
+		}
+		if doc.CreatedAt.Before(r.Start) || doc.CreatedAt.After(r.End) || !doc.UpdatedAt.Equal(r.End) {
+			t.Fatalf("created at %v and updated at %v, want within %v", doc.CreatedAt, doc.UpdatedAt, r)
+		}
+		for _, order := range doc.Orders {
+			if order.OrderDate.Before(r.Start) || order.OrderDate.After(r.End) {
+				t.Fatalf("order date %v outside %v", order.OrderDate, r)
+			}
+		}
+		if doc.CreatedAt.After(r.Start.Add(r.End.Sub(r.Start) / 2)) {
+			recent++
+		}
+	}
+	// Three quarters are expected in the second half
+	if recent < 120 {
+		t.Errorf("%d of 200 documents created in the second half, want most", recent)
+	}
+}
+
+func TestParseTimeDistribution(t *testing.T) {
+	if d, err := ParseTimeDistribution(""); err != nil || d != TimeUniform {
+		t.Errorf("ParseTimeDistribution(\"\") = %q, %v, want uniform", d, err)
+	}
+	if d, err := ParseTimeDistribution("Recent"); err != nil || d != TimeRecent {
+		t.Errorf("ParseTimeDistribution(\"Recent\") = %q, %v, want recent", d, err)
+	}
+	if _, err := ParseTimeDistribution("gaussian"); err == nil {
+		t.Error("ParseTimeDistribution(\"gaussian\") succeeded, want error")
+	}
+}
@@ -0,0 +1,239 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TransactionDocument represents a ledger transaction moving money between
+// accounts, with its balanced double-entry postings
+type TransactionDocument struct {
+	ID            primitive.ObjectID   `bson:"_id"`
+	TransactionID string               `bson:"transaction_id"`
+	LedgerSeq     int64                `bson:"ledger_seq"` // Increases with timestamp, for range sharding
+	Type          string               `bson:"type"`
+	FromAccount   string               `bson:"from_account"`
+	ToAccount     string               `bson:"to_account"` // First payee of batch transactions
+	Amount        primitive.Decimal128 `bson:"amount"`
+	Currency      string               `bson:"currency"`
+	Status        string               `bson:"status"`
+	Timestamp     time.Time            `bson:"timestamp"`
+	ValueDate     time.Time            `bson:"value_date"`
+	Description   string               `bson:"description"`
+	Channel       string               `bson:"channel"`
+
+	Postings      []Posting      `bson:"postings"`
+	StatusHistory []StatusChange `bson:"status_history"`
+
+	CreatedAt time.Time `bson:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at"`
+
+	Metadata map[string]interface{} `bson:"metadata"`
+	Tags     []string               `bson:"tags"`
+
+	// Padding field to control document size
+	Padding Padding `bson:"padding"`
+
+	// Sample of every BSON type (Options.BSONTypes)
+	BSONTypes *BSONTypes `bson:"bson_types,omitempty"`
+
+	// Chain of subdocuments Options.NestingDepth levels deep
+	Nested bson.D `bson:"nested,omitempty"`
+
+	docShape // Sparse fields and legacy schema (Options.Sparsity, Options.LegacyFraction)
+}
+
+// Posting is one side of a double-entry ledger line
+type Posting struct {
+	Account   string               `bson:"account"`
+	Direction string               `bson:"direction"` // debit or credit
+	Amount    primitive.Decimal128 `bson:"amount"`
+	Currency  string               `bson:"currency"`
+	Reference string               `bson:"reference"`
+	Memo      string               `bson:"memo"`
+}
+
+// StatusChange records when a transaction entered a status
+type StatusChange struct {
+	Status string    `bson:"status"`
+	At     time.Time `bson:"at"`
+}
+
+// currencies lists the transaction currencies with the number of minor unit
+// digits of their amounts, most common first
+var currencies = []struct {
+	code   string
+	digits int
+}{
+	{"USD", 2}, {"USD", 2}, {"USD", 2}, {"EUR", 2}, {"EUR", 2}, {"GBP", 2}, {"JPY", 0}, {"CHF", 2}, {"CAD", 2}, {"AUD", 2},
+}
+
+// DocumentID returns the document's _id
+func (d *TransactionDocument) DocumentID() primitive.ObjectID { return d.ID }
+
+// SetDocumentID replaces the document's _id
+func (d *TransactionDocument) SetDocumentID(id primitive.ObjectID) {
+	d.ID = id
+	d.encoded = nil
+}
+
+// SetMetadata sets metadata.key
+func (d *TransactionDocument) SetMetadata(key string, value interface{}) {
+	if d.Metadata == nil {
+		d.Metadata = make(map[string]interface{})
+	}
+	d.Metadata[key] = value
+	d.patchMetadata(key, value)
+}
+
+// MarshalBSON encodes the document in its shape, see docShape
+func (d *TransactionDocument) MarshalBSON() ([]byte, error) {
+	type plain TransactionDocument
+	return d.docShape.marshal((*plain)(d))
+}
+
+func (d *TransactionDocument) shape() *docShape { return &d.docShape }
+
+// GenerateTransaction creates a new transaction with the target size. Larger
+// documents are batch transactions (payroll, settlements) paying many
+// accounts from one. Timestamps and ledger sequence numbers increase in
+// generation order.
+func (g *Generator) GenerateTransaction() (*TransactionDocument, error) {
+	seq, transactionID := g.keys.issue()
+	timestamp := g.timeline.at(seq).Truncate(time.Millisecond)
+	currency := currencies[g.faker.IntRange(0, len(currencies)-1)]
+
+	payments := g.paymentCount()
+	txType := g.faker.RandomString([]string{"transfer", "payment", "refund", "withdrawal", "deposit"})
+	if payments > 1 {
+		txType = g.faker.RandomString([]string{"payroll", "settlement", "batch_payment"})
+	}
+
+	doc := &TransactionDocument{
+		ID:            primitive.NewObjectID(),
+		TransactionID: transactionID,
+		LedgerSeq:     g.keys.space.First + seq,
+		Type:          txType,
+		FromAccount:   g.keys.space.AccountKey(g.faker.IntRange(0, DefaultAccountCount-1)),
+		Currency:      currency.code,
+		Status:        g.transactionStatus(),
+		Timestamp:     timestamp,
+		ValueDate:     timestamp.Truncate(24 * time.Hour),
+		Description:   g.faker.Sentence(6),
+		Channel:       g.faker.RandomString([]string{"online", "mobile", "branch", "api", "card"}),
+		Postings:      make([]Posting, 0, 2*payments),
+		CreatedAt:     g.stamp(timestamp),
+		UpdatedAt:     g.stamp(timestamp),
+		Metadata:      map[string]interface{}{"created_by": "system", "source": g.faker.RandomString([]string{"core", "gateway", "batch"})},
+		Tags:          []string{txType, currency.code},
+	}
+
+	// Every payment debits the payer and credits one payee, so the postings
+	// of a transaction always balance
+	var total int64
+	for i := 0; i < payments; i++ {
+		payee := g.keys.space.AccountKey(g.faker.IntRange(0, DefaultAccountCount-1))
+		if i == 0 {
+			doc.ToAccount = payee
+		}
+		units := int64(g.faker.IntRange(100, 500000))
+		amount, err := minorUnitsDecimal(units, currency.digits)
+		if err != nil {
+			return nil, err
+		}
+		total += units
+
+		reference := fmt.Sprintf("%s-%04d", g.faker.LetterN(3), i)
+		memo := g.faker.Sentence(5)
+		doc.Postings = append(doc.Postings,
+			Posting{Account: doc.FromAccount, Direction: "debit", Amount: amount, Currency: currency.code, Reference: reference, Memo: memo},
+			Posting{Account: payee, Direction: "credit", Amount: amount, Currency: currency.code, Reference: reference, Memo: memo},
+		)
+	}
+	amount, err := minorUnitsDecimal(total, currency.digits)
+	if err != nil {
+		return nil, err
+	}
+	doc.Amount = amount
+
+	// Transactions pass through pending and posted on the way to their status
+	at := timestamp
+	for _, status := range []string{"pending", "posted", doc.Status} {
+		doc.StatusHistory = append(doc.StatusHistory, StatusChange{Status: status, At: at})
+		if status == doc.Status {
+			break
+		}
+		at = at.Add(time.Duration(g.faker.IntRange(1, 3600)) * time.Second)
+	}
+
+	g.applyCardinality(doc)
+	if err := g.applyFieldValues(doc); err != nil {
+		return nil, err
+	}
+	if err := g.applyFieldTemplates(doc); err != nil {
+		return nil, err
+	}
+	g.applySparsity(doc)
+	g.applyLegacy(doc)
+
+	bsonTypes, err := g.bsonTypes()
+	if err != nil {
+		return nil, err
+	}
+	doc.BSONTypes = bsonTypes
+	doc.Nested = g.nestedChain()
+
+	padding, err := g.calculatePadding(doc)
+	if err != nil {
+		return nil, err
+	}
+	doc.Padding = padding
+
+	return doc, nil
+}
+
+// paymentCount determines how many payments fill most of a transaction (each
+// adds two postings of ~190 bytes)
+func (g *Generator) paymentCount() int {
+	count := (int(g.targetSize)*85/100 - 700) / 380
+	if count < 1 {
+		count = 1
+	}
+	return count
+}
+
+// transactionStatus picks a transaction status, mostly settled
+func (g *Generator) transactionStatus() string {
+	switch n := g.faker.IntRange(1, 100); {
+	case n <= 2:
+		return "failed"
+	case n <= 3:
+		return "reversed"
+	case n <= 8:
+		return "pending"
+	case n <= 20:
+		return "posted"
+	}
+	return "settled"
+}
+
+// minorUnitsDecimal converts an amount in minor units (e.g. cents) to a
+// Decimal128 with the currency's number of digits
+func minorUnitsDecimal(units int64, digits int) (primitive.Decimal128, error) {
+	s := fmt.Sprintf("%d", units)
+	if digits > 0 {
+		var scale int64 = 1
+		for i := 0; i < digits; i++ {
+			scale *= 10
+		}
+		s = fmt.Sprintf("%d.%0*d", units/scale, digits, units%scale)
+	}
+	amount, err := primitive.ParseDecimal128(s)
+	if err != nil {
+		return primitive.Decimal128{}, fmt.Errorf("failed to convert amount %s: %w", s, err)
+	}
+	return amount, nil
+}
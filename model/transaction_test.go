@@ -0,0 +1,111 @@
+package model
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestTransactionDocumentSizes(t *testing.T) {
+	sizes := []DocumentSize{Size2KB, Size4KB, Size8KB, Size16KB, Size32KB, Size64KB}
+
+	for _, size := range sizes {
+		gen := NewGeneratorWithOptions(size, Options{Template: TemplateTransaction})
+
+		doc, err := gen.GenerateDocument()
+		if err != nil {
+			t.Fatalf("Failed to generate transaction: %v", err)
+		}
+		tx, ok := doc.(*TransactionDocument)
+		if !ok {
+			t.Fatalf("Expected a transaction document, got %T", doc)
+		}
+
+		data, err := bson.Marshal(tx)
+		if err != nil {
+			t.Fatalf("Failed to marshal transaction: %v", err)
+		}
+		t.Logf("%dKB: %d bytes, %d padding, %d postings", size/1024, len(data), len(tx.Padding.Data), len(tx.Postings))
+		if len(data) < int(size)*9/10 || len(data) > int(size)*11/10 {
+			t.Errorf("%dKB transaction is %d bytes, want within 10%%", size/1024, len(data))
+		}
+	}
+}
+
+func TestTransactionPostingsBalance(t *testing.T) {
+	gen := NewGeneratorWithOptions(Size16KB, Options{Template: TemplateTransaction})
+
+	for i := 0; i < 20; i++ {
+		tx, err := gen.GenerateTransaction()
+		if err != nil {
+			t.Fatalf("Failed to generate transaction: %v", err)
+		}
+
+		debits, credits := new(big.Int), new(big.Int)
+		for _, posting := range tx.Postings {
+			units, _, err := posting.Amount.BigInt()
+			if err != nil {
+				t.Fatalf("Invalid posting amount %s: %v", posting.Amount, err)
+			}
+			if posting.Direction == "debit" {
+				debits.Add(debits, units)
+			} else {
+				credits.Add(credits, units)
+			}
+		}
+		total, _, err := tx.Amount.BigInt()
+		if err != nil {
+			t.Fatalf("Invalid amount %s: %v", tx.Amount, err)
+		}
+		if debits.Cmp(credits) != 0 || debits.Cmp(total) != 0 {
+			t.Errorf("Transaction %s of %s has debits %s and credits %s", tx.TransactionID, tx.Amount, debits, credits)
+		}
+		if last := tx.StatusHistory[len(tx.StatusHistory)-1]; last.Status != tx.Status {
+			t.Errorf("Status history ends in %s, want %s", last.Status, tx.Status)
+		}
+	}
+}
+
+func TestTransactionTimestampsIncrease(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	gen := NewGeneratorWithOptions(Size2KB, Options{
+		Template:          TemplateTransaction,
+		TimeRange:         TimeRange{Start: start, End: start.Add(time.Hour)},
+		ExpectedDocuments: 1000,
+	})
+
+	var prev *TransactionDocument
+	for i := 0; i < 1000; i++ {
+		tx, err := gen.GenerateTransaction()
+		if err != nil {
+			t.Fatalf("Failed to generate transaction: %v", err)
+		}
+		if prev != nil && (!tx.Timestamp.After(prev.Timestamp) || tx.LedgerSeq != prev.LedgerSeq+1) {
+			t.Fatalf("Transaction %d at %s (seq %d) does not follow %s (seq %d)", i, tx.Timestamp, tx.LedgerSeq, prev.Timestamp, prev.LedgerSeq)
+		}
+		prev = tx
+	}
+}
+
+func TestMinorUnitsDecimal(t *testing.T) {
+	tests := []struct {
+		units  int64
+		digits int
+		want   string
+	}{
+		{12345, 2, "123.45"},
+		{5, 2, "0.05"},
+		{12345, 0, "12345"},
+	}
+	for _, tt := range tests {
+		amount, err := minorUnitsDecimal(tt.units, tt.digits)
+		if err != nil {
+			t.Fatalf("minorUnitsDecimal(%d, %d): %v", tt.units, tt.digits, err)
+		}
+		if amount.String() != tt.want {
+			t.Errorf("minorUnitsDecimal(%d, %d) = %s, want %s", tt.units, tt.digits, amount, tt.want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/linkedin/goavro/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// AvroSchema is the schema of the Avro messages of the kafka sink. Documents
+// of every template and shape vary too much for a schema per collection, so
+// it describes any BSON document: an ordered list of fields whose values are
+// Avro primitives, arrays, or subdocuments, and canonical Extended JSON for
+// the BSON types Avro has no equivalent of (ObjectId, dates, decimals, ...).
+const AvroSchema = `{
+  "type": "record",
+  "name": "Document",
+  "namespace": "gendata",
+  "fields": [{"name": "fields", "type": {"type": "array", "items": {
+    "type": "record",
+    "name": "Field",
+    "fields": [
+      {"name": "name", "type": "string"},
+      {"name": "value", "type": {
+        "type": "record",
+        "name": "Value",
+        "fields": [{"name": "value", "type": [
+          "null", "boolean", "int", "long", "double", "string", "bytes",
+          {"type": "array", "items": "Value"},
+          "Document",
+          {"type": "record", "name": "Extended", "fields": [{"name": "json", "type": "string"}]}
+        ]}]
+      }}
+    ]
+  }}}]
+}`
+
+var avroCodec = mustAvroCodec()
+
+func mustAvroCodec() *goavro.Codec {
+	codec, err := goavro.NewCodec(AvroSchema)
+	if err != nil {
+		panic(fmt.Sprintf("sink: invalid Avro schema: %v", err))
+	}
+	return codec
+}
+
+// avroBinary encodes a marshaled document as an AvroSchema record
+func avroBinary(data []byte) ([]byte, error) {
+	native, err := avroDocument(bson.Raw(data))
+	if err != nil {
+		return nil, err
+	}
+	out, err := avroCodec.BinaryFromNative(nil, native)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode document: %w", err)
+	}
+	return out, nil
+}
+
+// avroDocument converts a document to the goavro form of a gendata.Document
+func avroDocument(doc bson.Raw) (map[string]interface{}, error) {
+	elements, err := doc.Elements()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document: %w", err)
+	}
+	fields := make([]interface{}, 0, len(elements))
+	for _, element := range elements {
+		value, err := avroValue(element.Value())
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", element.Key(), err)
+		}
+		fields = append(fields, map[string]interface{}{"name": element.Key(), "value": value})
+	}
+	return map[string]interface{}{"fields": fields}, nil
+}
+
+// avroValue converts a BSON value to the goavro form of a gendata.Value
+func avroValue(v bson.RawValue) (map[string]interface{}, error) {
+	var union interface{}
+	switch v.Type {
+	case bsontype.Null:
+		union = nil
+	case bsontype.Boolean:
+		union = goavro.Union("boolean", v.Boolean())
+	case bsontype.Int32:
+		union = goavro.Union("int", v.Int32())
+	case bsontype.Int64:
+		union = goavro.Union("long", v.Int64())
+	case bsontype.Double:
+		union = goavro.Union("double", v.Double())
+	case bsontype.String:
+		union = goavro.Union("string", v.StringValue())
+	case bsontype.Binary:
+		_, data := v.Binary()
+		union = goavro.Union("bytes", data)
+	case bsontype.Array:
+		values, err := v.Array().Values()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read array: %w", err)
+		}
+		items := make([]interface{}, 0, len(values))
+		for _, value := range values {
+			item, err := avroValue(value)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		union = goavro.Union("array", items)
+	case bsontype.EmbeddedDocument:
+		doc, err := avroDocument(v.Document())
+		if err != nil {
+			return nil, err
+		}
+		union = goavro.Union("gendata.Document", doc)
+	default:
+		// {"v": <value>} in canonical Extended JSON, to keep the value's
+		// type, e.g. {"$oid": "..."}
+		wrapped, err := bson.MarshalExtJSON(bson.D{{Key: "v", Value: v}}, true, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", v.Type, err)
+		}
+		var value struct{ V json.RawMessage }
+		if err := json.Unmarshal(wrapped, &value); err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", v.Type, err)
+		}
+		union = goavro.Union("gendata.Extended", map[string]interface{}{"json": string(value.V)})
+	}
+	return map[string]interface{}{"value": union}, nil
+}
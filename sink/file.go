@@ -0,0 +1,116 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// File names the file sink
+const File = "file"
+
+func init() {
+	Register(File, NewFile)
+}
+
+// writerSink writes documents to a local file or a stream
+type writerSink struct {
+	mu     sync.Mutex
+	closer io.Closer // The file, nil for a stream the sink does not own
+	out    *bufio.Writer
+	bson   bool // Concatenated BSON instead of JSON Lines
+	stats  Stats
+}
+
+// newWriterSink creates a sink writing to w, closing closer on Close
+func newWriterSink(w io.Writer, closer io.Closer, bson bool) *writerSink {
+	return &writerSink{
+		closer: closer,
+		out:    bufio.NewWriterSize(w, 1<<20),
+		bson:   bson,
+	}
+}
+
+// NewFile creates a sink writing to the file at config.Target: canonical
+// Extended JSON, one document per line (for mongoimport), or concatenated
+// BSON (as mongodump writes, for mongorestore) if the path ends in .bson
+func NewFile(config Config) (Sink, error) {
+	if config.Target == "" {
+		return nil, fmt.Errorf("no target file")
+	}
+	file, err := os.Create(config.Target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	return newWriterSink(file, file, strings.ToLower(filepath.Ext(config.Target)) == ".bson"), nil
+}
+
+func (s *writerSink) WriteBatch(ctx context.Context, docs []model.Document) error {
+	// Encode outside the lock, so writers encode concurrently
+	var buf []byte
+	var bsonBytes int64
+	for _, doc := range docs {
+		data, err := bson.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("failed to marshal document: %w", err)
+		}
+		bsonBytes += int64(len(data))
+		if s.bson {
+			buf = append(buf, data...)
+			continue
+		}
+		line, err := extJSON(data)
+		if err != nil {
+			return err
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.out.Write(buf); err != nil {
+		return fmt.Errorf("failed to write documents: %w", err)
+	}
+	s.stats.DocumentsWritten += int64(len(docs))
+	s.stats.BytesWritten += bsonBytes
+	return nil
+}
+
+// extJSON encodes a marshaled document as canonical Extended JSON, which
+// keeps every BSON type for mongoimport and other MongoDB tools
+func extJSON(data []byte) ([]byte, error) {
+	line, err := bson.MarshalExtJSON(bson.Raw(data), true, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode document: %w", err)
+	}
+	return line, nil
+}
+
+func (s *writerSink) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+func (s *writerSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.out.Flush()
+	if err != nil {
+		err = fmt.Errorf("failed to write documents: %w", err)
+	}
+	if s.closer != nil {
+		if closeErr := s.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
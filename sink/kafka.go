@@ -0,0 +1,143 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"github.com/segmentio/kafka-go"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Kafka names the Kafka sink
+const Kafka = "kafka"
+
+func init() {
+	Register(Kafka, NewKafka)
+}
+
+// kafkaSink publishes documents as messages to a Kafka topic
+type kafkaSink struct {
+	writer       *kafka.Writer
+	encode       func(data []byte) ([]byte, error)
+	docsWritten  int64
+	bytesWritten int64
+}
+
+// kafkaTarget is a parsed Kafka sink target
+type kafkaTarget struct {
+	brokers []string
+	topic   string
+	format  string
+}
+
+// parseKafkaTarget parses a target of the form broker[,broker...]/topic,
+// optionally followed by ?format=json or ?format=avro
+func parseKafkaTarget(target string) (kafkaTarget, error) {
+	address, query, _ := strings.Cut(target, "?")
+	brokers, topic, ok := strings.Cut(address, "/")
+	if !ok || brokers == "" || topic == "" {
+		return kafkaTarget{}, fmt.Errorf("invalid target %q, expected broker[,broker...]/topic", target)
+	}
+	options, err := url.ParseQuery(query)
+	if err != nil {
+		return kafkaTarget{}, fmt.Errorf("invalid target options %q: %w", query, err)
+	}
+	for name := range options {
+		if name != "format" {
+			return kafkaTarget{}, fmt.Errorf("unknown target option: %s", name)
+		}
+	}
+
+	parsed := kafkaTarget{brokers: strings.Split(brokers, ","), topic: topic, format: options.Get("format")}
+	switch parsed.format {
+	case "":
+		parsed.format = "json"
+	case "json", "avro":
+	default:
+		return kafkaTarget{}, fmt.Errorf("unknown format: %s (json or avro)", parsed.format)
+	}
+	return parsed, nil
+}
+
+// NewKafka creates a sink publishing every document as a message to the
+// Kafka topic of config.Target (broker[,broker...]/topic[?format=avro]),
+// keyed by the document's _id so that a key always lands on the same
+// partition. Messages are canonical Extended JSON by default, or Avro
+// binary encoded with AvroSchema.
+func NewKafka(config Config) (Sink, error) {
+	if config.Target == "" {
+		return nil, fmt.Errorf("no target topic")
+	}
+	target, err := parseKafkaTarget(config.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &kafkaSink{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(target.brokers...),
+			Topic:                  target.topic,
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+			// Writers send whole batches, so waiting for more messages only
+			// adds latency
+			BatchSize:    10000,
+			BatchTimeout: 10 * time.Millisecond,
+		},
+		encode: extJSON,
+	}
+	if target.format == "avro" {
+		s.encode = avroBinary
+	}
+	return s, nil
+}
+
+func (s *kafkaSink) WriteBatch(ctx context.Context, docs []model.Document) error {
+	messages := make([]kafka.Message, 0, len(docs))
+	var bsonBytes int64
+	for _, doc := range docs {
+		data, err := bson.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("failed to marshal document: %w", err)
+		}
+		bsonBytes += int64(len(data))
+		value, err := s.encode(data)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, kafka.Message{Key: messageKey(doc), Value: value})
+	}
+
+	if err := s.writer.WriteMessages(ctx, messages...); err != nil {
+		return fmt.Errorf("failed to publish documents: %w", err)
+	}
+	atomic.AddInt64(&s.docsWritten, int64(len(docs)))
+	atomic.AddInt64(&s.bytesWritten, bsonBytes)
+	return nil
+}
+
+// messageKey returns the {_id: ...} document of doc in canonical Extended
+// JSON, like the keys the MongoDB Kafka source connector publishes
+func messageKey(doc model.Document) []byte {
+	return []byte(fmt.Sprintf(`{"_id":{"$oid":"%s"}}`, doc.DocumentID().Hex()))
+}
+
+func (s *kafkaSink) Stats() Stats {
+	return Stats{
+		DocumentsWritten: atomic.LoadInt64(&s.docsWritten),
+		BytesWritten:     atomic.LoadInt64(&s.bytesWritten),
+	}
+}
+
+func (s *kafkaSink) Close() error {
+	if err := s.writer.Close(); err != nil {
+		return fmt.Errorf("failed to close Kafka writer: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package sink
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseKafkaTarget(t *testing.T) {
+	target, err := parseKafkaTarget("a:9092,b:9092/customers?format=avro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(target.brokers, []string{"a:9092", "b:9092"}) || target.topic != "customers" || target.format != "avro" {
+		t.Errorf("unexpected target %+v", target)
+	}
+	if target, err := parseKafkaTarget("localhost:9092/events"); err != nil || target.format != "json" {
+		t.Errorf("expected JSON by default, got %+v, %v", target, err)
+	}
+
+	for _, invalid := range []string{"localhost:9092", "/events", "localhost:9092/", "localhost:9092/events?format=xml", "localhost:9092/events?acks=1"} {
+		if _, err := parseKafkaTarget(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+	if _, err := NewKafka(Config{}); err == nil {
+		t.Error("expected a missing target to be rejected")
+	}
+}
+
+func TestAvroBinary(t *testing.T) {
+	id := primitive.NewObjectID()
+	data, err := bson.Marshal(bson.D{
+		{Key: "_id", Value: id},
+		{Key: "name", Value: "Ada"},
+		{Key: "age", Value: int32(36)},
+		{Key: "address", Value: bson.D{{Key: "city", Value: "London"}, {Key: "zip", Value: nil}}},
+		{Key: "tags", Value: bson.A{"a", int64(2), 1.5, true}},
+		{Key: "created", Value: primitive.NewDateTimeFromTime(time.UnixMilli(0).UTC())},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := avroBinary(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	native, _, err := avroCodec.NativeFromBinary(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Fields keep their order, nest, and keep BSON types as Extended JSON
+	fields := native.(map[string]interface{})["fields"].([]interface{})
+	var names []string
+	values := make(map[string]interface{})
+	for _, f := range fields {
+		field := f.(map[string]interface{})
+		name := field["name"].(string)
+		names = append(names, name)
+		values[name] = field["value"].(map[string]interface{})["value"]
+	}
+	if !slices.Equal(names, []string{"_id", "name", "age", "address", "tags", "created"}) {
+		t.Fatalf("unexpected fields %v", names)
+	}
+
+	extended := func(name string) string {
+		return values[name].(map[string]interface{})["gendata.Extended"].(map[string]interface{})["json"].(string)
+	}
+	if got, want := extended("_id"), `{"$oid":"`+id.Hex()+`"}`; got != want {
+		t.Errorf("_id: got %s, want %s", got, want)
+	}
+	if got := extended("created"); got != `{"$date":{"$numberLong":"0"}}` {
+		t.Errorf("created: got %s", got)
+	}
+	if got := values["age"].(map[string]interface{})["int"]; got != int32(36) {
+		t.Errorf("age: got %v", got)
+	}
+	address := values["address"].(map[string]interface{})["gendata.Document"].(map[string]interface{})["fields"].([]interface{})
+	if len(address) != 2 || address[1].(map[string]interface{})["value"].(map[string]interface{})["value"] != nil {
+		t.Errorf("unexpected address %v", address)
+	}
+	if tags := values["tags"].(map[string]interface{})["array"].([]interface{}); len(tags) != 4 {
+		t.Errorf("unexpected tags %v", tags)
+	}
+
+	// Every generated document encodes
+	for _, doc := range testDocuments(t, 5) {
+		data, err := bson.Marshal(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := avroBinary(data); err != nil {
+			t.Errorf("failed to encode a generated document: %v", err)
+		}
+	}
+}
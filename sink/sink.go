@@ -0,0 +1,166 @@
+// Package sink defines where generated documents are written. A Sink takes
+// batches of documents; sinks other than MongoDB register a factory under a
+// name, so the gendata command can select them with --sink and programs
+// embedding the generator can add their own.
+package sink
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"golang.org/x/sync/errgroup"
+)
+
+// MongoDB names the built-in MongoDB writer, the default sink of the
+// gendata command
+const MongoDB = "mongodb"
+
+// Sink receives generated documents
+type Sink interface {
+	// WriteBatch writes a batch of documents. It is called concurrently by
+	// several writers and must not keep docs after returning.
+	WriteBatch(ctx context.Context, docs []model.Document) error
+
+	// Stats returns what has been written so far
+	Stats() Stats
+
+	// Close flushes buffered documents and releases the sink
+	Close() error
+}
+
+// Streamer is a Sink that reads the generated documents itself until the
+// channel is closed or ctx ends, e.g. to batch per connection. Run hands it
+// the channel instead of batching for WriteBatch.
+type Streamer interface {
+	Sink
+	Write(ctx context.Context, docs <-chan model.Document) error
+}
+
+// Stats counts the documents written by a sink
+type Stats struct {
+	DocumentsWritten int64
+	BytesWritten     int64 // BSON size of the written documents
+}
+
+// Config configures a sink created by name
+type Config struct {
+	// Target is where the sink writes, in a form the sink defines: a file
+	// path, a URL, or a list of brokers
+	Target string
+}
+
+// Factory creates a sink
+type Factory func(config Config) (Sink, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register makes a sink available by name. It panics if the name is taken,
+// as two sinks registering the same name is a programming error.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[name]; ok || name == MongoDB {
+		panic(fmt.Sprintf("sink: %s registered twice", name))
+	}
+	registry[name] = factory
+}
+
+// New creates the sink registered under name
+func New(name string, config Config) (Sink, error) {
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown sink: %s (%s)", name, Names())
+	}
+	s, err := factory(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s sink: %w", name, err)
+	}
+	return s, nil
+}
+
+// Names returns the names of the registered sinks, sorted
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run writes the documents from docs to s until the channel is closed, in
+// batches of up to batchSize documents from writers concurrent writers. A
+// Streamer reads the channel itself.
+func Run(ctx context.Context, s Sink, docs <-chan model.Document, writers, batchSize int) error {
+	if streamer, ok := s.(Streamer); ok {
+		return streamer.Write(ctx, docs)
+	}
+
+	batchSize = max(batchSize, 1)
+	eg, ctx := errgroup.WithContext(ctx)
+	for i := 0; i < max(writers, 1); i++ {
+		eg.Go(func() error {
+			batch := make([]model.Document, 0, batchSize)
+			for {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case doc, ok := <-docs:
+					if !ok {
+						if len(batch) == 0 {
+							return nil
+						}
+						return s.WriteBatch(ctx, batch)
+					}
+					batch = append(batch, doc)
+					if len(batch) == batchSize {
+						if err := s.WriteBatch(ctx, batch); err != nil {
+							return err
+						}
+						batch = make([]model.Document, 0, batchSize)
+					}
+				}
+			}
+		})
+	}
+	return eg.Wait()
+}
+
+// funcSink adapts a function to a Sink
+type funcSink struct {
+	write func(ctx context.Context, docs []model.Document) error
+	docs  int64
+}
+
+// Func returns a sink that passes every batch to write, e.g. to collect
+// documents in a test. Its stats count documents but not bytes.
+func Func(write func(ctx context.Context, docs []model.Document) error) Sink {
+	return &funcSink{write: write}
+}
+
+func (s *funcSink) WriteBatch(ctx context.Context, docs []model.Document) error {
+	if err := s.write(ctx, docs); err != nil {
+		return err
+	}
+	atomic.AddInt64(&s.docs, int64(len(docs)))
+	return nil
+}
+
+func (s *funcSink) Stats() Stats {
+	return Stats{DocumentsWritten: atomic.LoadInt64(&s.docs)}
+}
+
+func (s *funcSink) Close() error {
+	return nil
+}
@@ -0,0 +1,163 @@
+package sink
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/meticulous-dft/mongodb-data-generator/model"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// testDocuments generates n documents
+func testDocuments(t *testing.T, n int) []model.Document {
+	g := model.NewGenerator(model.Size2KB)
+	docs := make([]model.Document, n)
+	for i := range docs {
+		doc, err := g.GenerateDocument()
+		if err != nil {
+			t.Fatal(err)
+		}
+		docs[i] = doc
+	}
+	return docs
+}
+
+// feed returns a closed channel holding docs
+func feed(docs []model.Document) <-chan model.Document {
+	ch := make(chan model.Document, len(docs))
+	for _, doc := range docs {
+		ch <- doc
+	}
+	close(ch)
+	return ch
+}
+
+func TestRegistry(t *testing.T) {
+	if !slices.Contains(Names(), File) {
+		t.Errorf("expected the file sink to be registered, got %v", Names())
+	}
+	if _, err := New("nowhere", Config{}); err == nil {
+		t.Error("expected an unknown sink to be rejected")
+	}
+	if _, err := New(File, Config{}); err == nil {
+		t.Error("expected the file sink to require a target")
+	}
+
+	Register("test-registry", func(config Config) (Sink, error) {
+		return Func(func(context.Context, []model.Document) error { return nil }), nil
+	})
+	if _, err := New("test-registry", Config{}); err != nil {
+		t.Errorf("New: %v", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a name twice to panic")
+		}
+	}()
+	Register("test-registry", nil)
+}
+
+func TestRun(t *testing.T) {
+	var mu sync.Mutex
+	var sizes []int
+	s := Func(func(ctx context.Context, docs []model.Document) error {
+		mu.Lock()
+		sizes = append(sizes, len(docs))
+		mu.Unlock()
+		return nil
+	})
+
+	if err := Run(context.Background(), s, feed(testDocuments(t, 25)), 1, 10); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(sizes, []int{10, 10, 5}) {
+		t.Errorf("expected batches of 10, 10, and 5, got %v", sizes)
+	}
+	if s.Stats().DocumentsWritten != 25 {
+		t.Errorf("expected 25 documents written, got %d", s.Stats().DocumentsWritten)
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	docs := testDocuments(t, 5)
+	for _, name := range []string{"docs.jsonl", "docs.bson"} {
+		path := filepath.Join(t.TempDir(), name)
+		s, err := New(File, Config{Target: path})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Run(context.Background(), s, feed(docs), 2, 2); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if s.Stats().DocumentsWritten != 5 || s.Stats().BytesWritten == 0 {
+			t.Errorf("%s: unexpected stats %+v", name, s.Stats())
+		}
+
+		// Every document reads back with its _id
+		var ids []interface{}
+		if filepath.Ext(name) == ".bson" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for len(data) > 0 {
+				raw := bson.Raw(data[:binary.LittleEndian.Uint32(data)])
+				if err := raw.Validate(); err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				ids = append(ids, raw.Lookup("_id").ObjectID())
+				data = data[len(raw):]
+			}
+		} else {
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			scanner := bufio.NewScanner(f)
+			scanner.Buffer(nil, 1<<20)
+			for scanner.Scan() {
+				var doc bson.M
+				if err := bson.UnmarshalExtJSON(scanner.Bytes(), true, &doc); err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				ids = append(ids, doc["_id"])
+			}
+			f.Close()
+		}
+		if len(ids) != 5 {
+			t.Errorf("%s: expected 5 documents, read %d", name, len(ids))
+		}
+	}
+}
+
+func TestStdoutSink(t *testing.T) {
+	if _, err := New(Stdout, Config{Target: "xml"}); err == nil {
+		t.Error("expected an unknown format to be rejected")
+	}
+
+	// A stream is flushed but not closed
+	var out bytes.Buffer
+	s := newWriterSink(&out, nil, false)
+	if err := s.WriteBatch(context.Background(), testDocuments(t, 3)); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Error("expected documents to be buffered")
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if lines := bytes.Count(out.Bytes(), []byte("\n")); lines != 3 {
+		t.Errorf("expected 3 lines, got %d", lines)
+	}
+}
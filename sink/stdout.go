@@ -0,0 +1,27 @@
+package sink
+
+import (
+	"fmt"
+	"os"
+)
+
+// Stdout names the standard output sink
+const Stdout = "stdout"
+
+func init() {
+	Register(Stdout, NewStdout)
+}
+
+// NewStdout creates a sink writing documents to standard output, to pipe
+// them into mongoimport, mongosh, or any other program: canonical Extended
+// JSON, one document per line, or concatenated BSON (for mongorestore) if
+// config.Target is "bson"
+func NewStdout(config Config) (Sink, error) {
+	switch config.Target {
+	case "", "json":
+		return newWriterSink(os.Stdout, nil, false), nil
+	case "bson":
+		return newWriterSink(os.Stdout, nil, true), nil
+	}
+	return nil, fmt.Errorf("unknown format: %s (json or bson)", config.Target)
+}